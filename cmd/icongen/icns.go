@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/png"
+	"os"
+)
+
+// icnsTypes maps ICNS element types to the PNG size they hold
+var icnsTypes = []struct {
+	ostype string
+	size   int
+}{
+	{"icp4", 16},
+	{"icp5", 32},
+	{"icp6", 64},
+	{"ic07", 128},
+	{"ic08", 256},
+	{"ic09", 512},
+	{"ic10", 1024}, // 512@2x
+}
+
+// encodeICNS builds an Apple icon file from PNG-encoded renders
+func encodeICNS() ([]byte, error) {
+	var body bytes.Buffer
+	for _, t := range icnsTypes {
+		var data bytes.Buffer
+		if err := png.Encode(&data, renderIcon(t.size)); err != nil {
+			return nil, err
+		}
+		body.WriteString(t.ostype)
+		binary.Write(&body, binary.BigEndian, uint32(8+data.Len()))
+		body.Write(data.Bytes())
+	}
+
+	var out bytes.Buffer
+	out.WriteString("icns")
+	binary.Write(&out, binary.BigEndian, uint32(8+body.Len()))
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+func saveICNS(path string) {
+	data, err := encodeICNS()
+	if err != nil {
+		panic(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/png"
+	"os"
+)
+
+// icoSizes are embedded in app.ico; 256 is the largest size ICO supports
+var icoSizes = []int{16, 24, 32, 48, 64, 128, 256}
+
+// encodeICO builds a Windows icon with PNG-compressed entries (Vista+)
+func encodeICO() ([]byte, error) {
+	images := make([][]byte, 0, len(icoSizes))
+	for _, size := range icoSizes {
+		var data bytes.Buffer
+		if err := png.Encode(&data, renderIcon(size)); err != nil {
+			return nil, err
+		}
+		images = append(images, data.Bytes())
+	}
+
+	var out bytes.Buffer
+	// ICONDIR: reserved, type (1 = icon), count
+	binary.Write(&out, binary.LittleEndian, []uint16{0, 1, uint16(len(images))})
+
+	offset := 6 + 16*len(images)
+	for i, size := range icoSizes {
+		dim := byte(size)
+		if size >= 256 {
+			dim = 0 // 0 means 256
+		}
+		// ICONDIRENTRY: width, height, colors, reserved, planes, bpp, size, offset
+		out.Write([]byte{dim, dim, 0, 0})
+		binary.Write(&out, binary.LittleEndian, []uint16{1, 32})
+		binary.Write(&out, binary.LittleEndian, []uint32{uint32(len(images[i])), uint32(offset)})
+		offset += len(images[i])
+	}
+	for _, data := range images {
+		out.Write(data)
+	}
+	return out.Bytes(), nil
+}
+
+func saveICO(path string) {
+	data, err := encodeICO()
+	if err != nil {
+		panic(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		panic(err)
+	}
+}
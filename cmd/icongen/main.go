@@ -1,23 +1,68 @@
 package main
 
 import (
+	"fmt"
 	"image"
 	"image/color"
 	"image/png"
 	"math"
 	"os"
 	"path/filepath"
+
+	"claudebar/internal/palette"
 )
 
+// baseSize is the size the icon geometry is designed at; other sizes scale it
+const baseSize = 64
+
+// appSizes are the PNG sizes written to assets/icons/sizes
+var appSizes = []int{16, 24, 32, 48, 64, 128, 256, 512}
+
 func main() {
-	size := 64
+	img := renderIcon(baseSize)
+
+	// Save icon
+	dir := filepath.Join("assets", "icons")
+	os.MkdirAll(dir, 0755)
+
+	// Tray icon
+	savePNG(img, filepath.Join(dir, "tray.png"))
+
+	// App icon (same for now)
+	savePNG(img, filepath.Join(dir, "app.png"))
+
+	// Full-color size set
+	sizesDir := filepath.Join(dir, "sizes")
+	os.MkdirAll(sizesDir, 0755)
+	for _, size := range appSizes {
+		savePNG(renderIcon(size), filepath.Join(sizesDir, fmt.Sprintf("app-%d.png", size)))
+	}
+
+	// Windows and macOS bundle icons
+	saveICO(filepath.Join(dir, "app.ico"))
+	saveICNS(filepath.Join(dir, "app.icns"))
+
+	// Monochrome tray variants
+	saveVariants(dir)
+
+	// Percentage-badged tray frames
+	badgeDir := filepath.Join(dir, "badges")
+	os.MkdirAll(badgeDir, 0755)
+	for pct := 0; pct <= 100; pct += badgeStep {
+		savePNG(renderBadged(baseSize, pct), filepath.Join(badgeDir, fmt.Sprintf("tray-%03d.png", pct)))
+	}
+}
+
+// renderIcon draws the app icon at the given pixel size
+func renderIcon(size int) *image.RGBA {
 	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	s := float64(size) / baseSize
 
 	// Colors
-	bgColor := color.RGBA{32, 33, 35, 255}       // Dark bg
-	barBlue := color.RGBA{88, 140, 236, 255}      // Claude blue
-	barYellow := color.RGBA{234, 179, 8, 255}     // Warning yellow
-	barGreen := color.RGBA{74, 222, 128, 255}     // Low usage green
+	bgColor := opaque(palette.Background)
+	barBlue := palette.Accent
+	barYellow := palette.Warning
+	barGreen := palette.Low
 
 	// Fill with transparent
 	for y := 0; y < size; y++ {
@@ -27,42 +72,34 @@ func main() {
 	}
 
 	// Draw rounded rectangle background
-	radius := 12.0
-	for y := 0; y < size; y++ {
-		for x := 0; x < size; x++ {
-			if inRoundedRect(float64(x), float64(y), 2, 2, float64(size-4), float64(size-4), radius) {
-				img.Set(x, y, bgColor)
-			}
-		}
-	}
+	drawRoundedRect(img, 2*s, 2*s, 60*s, 60*s, 12*s, bgColor)
 
 	// Draw 3 vertical bars (usage chart icon)
 	// Bar 1 (left, tall - blue)
-	drawBar(img, 14, 16, 10, 32, barBlue)
+	drawBar(img, 14*s, 16*s, 10*s, 32*s, s, barBlue)
 	// Bar 2 (middle, medium - yellow)
-	drawBar(img, 28, 24, 10, 24, barYellow)
+	drawBar(img, 28*s, 24*s, 10*s, 24*s, s, barYellow)
 	// Bar 3 (right, short - green)
-	drawBar(img, 42, 32, 10, 16, barGreen)
+	drawBar(img, 42*s, 32*s, 10*s, 16*s, s, barGreen)
 
-	// Save icon
-	dir := filepath.Join("assets", "icons")
-	os.MkdirAll(dir, 0755)
+	return img
+}
 
-	// Tray icon
-	savePNG(img, filepath.Join(dir, "tray.png"))
+// opaque returns c without transparency
+func opaque(c color.RGBA) color.RGBA {
+	c.A = 255
+	return c
+}
 
-	// App icon (same for now)
-	savePNG(img, filepath.Join(dir, "app.png"))
+func drawBar(img *image.RGBA, x, y, w, h, scale float64, c color.Color) {
+	drawRoundedRect(img, x, y, w, h, 3*scale, c) // corner radius
 }
 
-func drawBar(img *image.RGBA, x, y, w, h int, c color.Color) {
-	r := 3.0 // corner radius
-	for dy := 0; dy < h; dy++ {
-		for dx := 0; dx < w; dx++ {
-			px := float64(x + dx)
-			py := float64(y + dy)
-			if inRoundedRect(px, py, float64(x), float64(y), float64(w), float64(h), r) {
-				img.Set(x+dx, y+dy, c)
+func drawRoundedRect(img *image.RGBA, x, y, w, h, r float64, c color.Color) {
+	for py := int(y); py < int(y+h); py++ {
+		for px := int(x); px < int(x+w); px++ {
+			if inRoundedRect(float64(px), float64(py), x, y, w, h, r) {
+				img.Set(px, py, c)
 			}
 		}
 	}
@@ -75,10 +112,10 @@ func inRoundedRect(px, py, rx, ry, rw, rh, radius float64) bool {
 
 	// Check corners
 	corners := [][2]float64{
-		{rx + radius, ry + radius},             // top-left
-		{rx + rw - radius, ry + radius},        // top-right
-		{rx + radius, ry + rh - radius},        // bottom-left
-		{rx + rw - radius, ry + rh - radius},   // bottom-right
+		{rx + radius, ry + radius},           // top-left
+		{rx + rw - radius, ry + radius},      // top-right
+		{rx + radius, ry + rh - radius},      // bottom-left
+		{rx + rw - radius, ry + rh - radius}, // bottom-right
 	}
 
 	for _, corner := range corners {
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"path/filepath"
+
+	"claudebar/internal/palette"
+)
+
+// badgeStep is the percentage step between badged tray frames
+const badgeStep = 10
+
+// saveVariants writes single-color tray icons:
+//   - trayTemplate.png / trayTemplate@2x.png: black + alpha, macOS tints
+//     template images to match the menu bar
+//   - tray-light.png: dark glyph for light Windows/Linux taskbars
+//   - tray-dark.png: light glyph for dark taskbars
+//   - tray-{light,dark}-{warning,critical}.png: the same with a severity dot
+func saveVariants(dir string) {
+	black := color.RGBA{0, 0, 0, 255}
+	darkGlyph := opaque(palette.Background)
+	lightGlyph := palette.GlyphLight
+	savePNG(renderMonochrome(22, black), filepath.Join(dir, "trayTemplate.png"))
+	savePNG(renderMonochrome(44, black), filepath.Join(dir, "trayTemplate@2x.png"))
+	savePNG(renderMonochrome(baseSize, darkGlyph), filepath.Join(dir, "tray-light.png"))
+	savePNG(renderMonochrome(baseSize, lightGlyph), filepath.Join(dir, "tray-dark.png"))
+
+	warning := palette.Warning
+	critical := palette.Critical
+	for _, v := range []struct {
+		name  string
+		glyph color.RGBA
+	}{
+		{"tray-light", darkGlyph},
+		{"tray-dark", lightGlyph},
+	} {
+		savePNG(renderSeverity(baseSize, v.glyph, warning), filepath.Join(dir, v.name+"-warning.png"))
+		savePNG(renderSeverity(baseSize, v.glyph, critical), filepath.Join(dir, v.name+"-critical.png"))
+	}
+}
+
+// renderSeverity draws the monochrome icon with a solid dot in the top-right
+// corner, cut out of the glyph so it reads on any taskbar
+func renderSeverity(size int, glyph, dot color.RGBA) *image.RGBA {
+	img := renderMonochrome(size, glyph)
+	s := float64(size) / baseSize
+
+	cx, cy := 50*s, 14*s
+	radius, gap := 11*s, 3*s
+	for py := 0; py < size; py++ {
+		for px := 0; px < size; px++ {
+			dist := math.Hypot(float64(px)+0.5-cx, float64(py)+0.5-cy)
+			switch {
+			case dist <= radius:
+				img.Set(px, py, dot)
+			case dist <= radius+gap:
+				img.Set(px, py, color.Transparent)
+			}
+		}
+	}
+	return img
+}
+
+// renderMonochrome draws the icon as an outlined frame with solid bars in a
+// single color on a transparent background
+func renderMonochrome(size int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	s := float64(size) / baseSize
+
+	// Frame outline: outer rounded rect minus the inner one
+	stroke := math.Max(1, 5*s)
+	for py := 0; py < size; py++ {
+		for px := 0; px < size; px++ {
+			x, y := float64(px), float64(py)
+			outer := inRoundedRect(x, y, 2*s, 2*s, 60*s, 60*s, 12*s)
+			inner := inRoundedRect(x, y, 2*s+stroke, 2*s+stroke, 60*s-2*stroke, 60*s-2*stroke, 12*s-stroke)
+			if outer && !inner {
+				img.Set(px, py, c)
+			}
+		}
+	}
+
+	drawBar(img, 14*s, 16*s, 10*s, 32*s, s, c)
+	drawBar(img, 28*s, 24*s, 10*s, 24*s, s, c)
+	drawBar(img, 42*s, 32*s, 10*s, 16*s, s, c)
+	return img
+}
+
+// renderBadged draws the icon with a pie badge in the bottom-right corner
+// filled to pct, colored like the overlay's usage bars
+func renderBadged(size, pct int) *image.RGBA {
+	img := renderIcon(size)
+	s := float64(size) / baseSize
+
+	fill := palette.Low
+	switch {
+	case pct >= 90:
+		fill = palette.Critical
+	case pct >= 75:
+		fill = palette.Warning
+	}
+	ring := color.RGBA{255, 255, 255, 255}
+	track := palette.BadgeTrack
+
+	cx, cy := 48*s, 48*s
+	outer, inner := 16*s, 13*s
+	sweep := 2 * math.Pi * float64(pct) / 100
+
+	for py := 0; py < size; py++ {
+		for px := 0; px < size; px++ {
+			dx, dy := float64(px)+0.5-cx, float64(py)+0.5-cy
+			dist := math.Hypot(dx, dy)
+			if dist > outer {
+				continue
+			}
+			if dist > inner {
+				img.Set(px, py, ring)
+				continue
+			}
+			// Angle clockwise from 12 o'clock
+			angle := math.Atan2(dx, -dy)
+			if angle < 0 {
+				angle += 2 * math.Pi
+			}
+			if angle < sweep {
+				img.Set(px, py, fill)
+			} else {
+				img.Set(px, py, track)
+			}
+		}
+	}
+	return img
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	bundleID   = "com.claudebar.app" // matches the Fyne app ID
+	bundleName = productName + ".app"
+	binaryName = "claudebar"
+)
+
+// bundleData is passed to the Info.plist template
+type bundleData struct {
+	Name         string
+	Identifier   string
+	Executable   string
+	Version      string
+	ShortVersion string
+}
+
+// packageApp builds a ClaudeBar.app bundle, signs it when an identity is
+// given and wraps it in a drag-to-Applications DMG when hdiutil is present.
+//
+// Layout follows what codesign expects: only the executable in MacOS/,
+// everything else in Resources/, so the seal covers every file.
+func packageApp(outDir, version, identity string) error {
+	bundle := filepath.Join(outDir, bundleName)
+	if err := os.RemoveAll(bundle); err != nil {
+		return err
+	}
+	macosDir := filepath.Join(bundle, "Contents", "MacOS")
+	resDir := filepath.Join(bundle, "Contents", "Resources")
+	if err := os.MkdirAll(resDir, 0755); err != nil {
+		return err
+	}
+
+	if _, err := buildBinary("darwin", macosDir, binaryName, version, ""); err != nil {
+		return err
+	}
+
+	// Generated by cmd/icongen
+	if err := copyFile(filepath.Join("assets", "icons", "app.icns"), filepath.Join(resDir, productName+".icns")); err != nil {
+		return fmt.Errorf("missing app.icns, run `go run ./cmd/icongen`: %w", err)
+	}
+
+	data := bundleData{
+		Name:         productName,
+		Identifier:   bundleID,
+		Executable:   binaryName,
+		Version:      fourPartVersion(version),
+		ShortVersion: shortVersion(version),
+	}
+	if err := renderTemplate(filepath.Join(bundle, "Contents", "Info.plist"), infoPlistTemplate, data); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(bundle, "Contents", "PkgInfo"), []byte("APPL????"), 0644); err != nil {
+		return err
+	}
+	log.Printf("Wrote %s", bundle)
+
+	if identity != "" {
+		if err := run("codesign", "--force", "--deep", "--options", "runtime", "--timestamp", "--sign", identity, bundle); err != nil {
+			return fmt.Errorf("codesign failed: %w", err)
+		}
+	} else {
+		log.Printf("Bundle is unsigned. Sign with:\n  codesign --force --deep --options runtime --timestamp --sign \"Developer ID Application: ...\" %s", bundle)
+	}
+
+	return packageDMG(outDir, bundle, data.ShortVersion)
+}
+
+// packageDMG creates a disk image holding the bundle and an Applications link
+func packageDMG(outDir, bundle, version string) error {
+	if _, err := exec.LookPath("hdiutil"); err != nil {
+		log.Printf("hdiutil not found, skipping DMG")
+		return nil
+	}
+
+	stage := filepath.Join(outDir, "dmg")
+	if err := os.RemoveAll(stage); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(stage, 0755); err != nil {
+		return err
+	}
+	if err := run("cp", "-R", bundle, stage); err != nil {
+		return err
+	}
+	if err := os.Symlink("/Applications", filepath.Join(stage, "Applications")); err != nil {
+		return err
+	}
+
+	dmgPath := filepath.Join(outDir, fmt.Sprintf("ClaudeBar-%s.dmg", version))
+	os.Remove(dmgPath)
+	return run("hdiutil", "create", "-volname", productName, "-srcfolder", stage, "-ov", "-format", "UDZO", dmgPath)
+}
+
+// shortVersion returns the three-part CFBundleShortVersionString
+func shortVersion(v string) string {
+	parts := strings.Split(fourPartVersion(v), ".")
+	return strings.Join(parts[:3], ".")
+}
+
+// infoPlistTemplate marks the app as an agent (LSUIElement) so it lives in the
+// menu bar only, without a Dock icon or app menu.
+const infoPlistTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleDevelopmentRegion</key>
+	<string>en</string>
+	<key>CFBundleExecutable</key>
+	<string>{{.Executable}}</string>
+	<key>CFBundleIconFile</key>
+	<string>{{.Name}}.icns</string>
+	<key>CFBundleIdentifier</key>
+	<string>{{.Identifier}}</string>
+	<key>CFBundleInfoDictionaryVersion</key>
+	<string>6.0</string>
+	<key>CFBundleName</key>
+	<string>{{.Name}}</string>
+	<key>CFBundleDisplayName</key>
+	<string>{{.Name}}</string>
+	<key>CFBundlePackageType</key>
+	<string>APPL</string>
+	<key>CFBundleShortVersionString</key>
+	<string>{{.ShortVersion}}</string>
+	<key>CFBundleVersion</key>
+	<string>{{.Version}}</string>
+	<key>LSMinimumSystemVersion</key>
+	<string>10.13</string>
+	<key>LSUIElement</key>
+	<true/>
+	<key>NSHighResolutionCapable</key>
+	<true/>
+	<key>NSHumanReadableCopyright</key>
+	<string>Copyright (c) 2025 Brandon Miller-Mumford. MIT License.</string>
+</dict>
+</plist>
+`
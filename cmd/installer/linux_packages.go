@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+const (
+	linuxAppID     = "com.claudebar.app"
+	flatpakPath    = "packaging/linux/" + linuxAppID + ".yml"
+	desktopPath    = "packaging/linux/" + linuxAppID + ".desktop"
+	metainfoPath   = "packaging/linux/" + linuxAppID + ".metainfo.xml"
+	appRunTemplate = `#!/bin/sh
+HERE="$(dirname "$(readlink -f "$0")")"
+exec "$HERE/usr/bin/claudebar" "$@"
+`
+)
+
+// packageAppImage builds an AppDir and turns it into an AppImage when
+// appimagetool is installed. APPIMAGE is set by the runtime, which is how the
+// app detects it and writes autostart entries pointing at the .AppImage file.
+func packageAppImage(outDir, version string) error {
+	appDir := filepath.Join(outDir, "ClaudeBar.AppDir")
+	if err := os.RemoveAll(appDir); err != nil {
+		return err
+	}
+
+	if _, err := buildBinary("linux", filepath.Join(appDir, "usr", "bin"), binaryName, version, ""); err != nil {
+		return err
+	}
+
+	files := []struct{ src, dst string }{
+		{desktopPath, filepath.Join(appDir, linuxAppID+".desktop")},
+		{desktopPath, filepath.Join(appDir, "usr", "share", "applications", linuxAppID+".desktop")},
+		{metainfoPath, filepath.Join(appDir, "usr", "share", "metainfo", linuxAppID+".appdata.xml")},
+		{filepath.Join("assets", "icons", "app.png"), filepath.Join(appDir, linuxAppID+".png")},
+		{filepath.Join("assets", "icons", "app.png"), filepath.Join(appDir, "usr", "share", "icons", "hicolor", "64x64", "apps", linuxAppID+".png")},
+	}
+	for _, f := range files {
+		if err := os.MkdirAll(filepath.Dir(f.dst), 0755); err != nil {
+			return err
+		}
+		if err := copyFile(f.src, f.dst); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(appDir, "AppRun"), []byte(appRunTemplate), 0755); err != nil {
+		return err
+	}
+	log.Printf("Wrote %s", appDir)
+
+	imagePath := filepath.Join(outDir, fmt.Sprintf("ClaudeBar-%s-x86_64.AppImage", shortVersion(version)))
+	if _, err := exec.LookPath("appimagetool"); err != nil {
+		log.Printf("appimagetool not found on PATH, skipping AppImage. Run:\n  appimagetool %s %s", appDir, imagePath)
+		return nil
+	}
+	return run("appimagetool", appDir, imagePath)
+}
+
+// packageFlatpak builds the Flatpak from the manifest and exports a bundle
+func packageFlatpak(outDir string) error {
+	if _, err := exec.LookPath("flatpak-builder"); err != nil {
+		log.Printf("flatpak-builder not found on PATH. Install it and run:\n  flatpak-builder --user --install --force-clean %s %s",
+			filepath.Join(outDir, "flatpak"), flatpakPath)
+		return nil
+	}
+
+	repo := filepath.Join(outDir, "flatpak-repo")
+	if err := run("flatpak-builder", "--force-clean", "--repo="+repo, filepath.Join(outDir, "flatpak"), flatpakPath); err != nil {
+		return err
+	}
+	return run("flatpak", "build-bundle", repo, filepath.Join(outDir, "ClaudeBar.flatpak"), linuxAppID)
+}
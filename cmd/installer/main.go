@@ -0,0 +1,354 @@
+// Command installer builds ClaudeBar and packages it for distribution: a WiX
+// MSI and/or MSIX package on Windows, a .app bundle (and DMG) on macOS, an
+// AppImage and/or Flatpak on Linux.
+//
+//	go run ./cmd/installer -version 1.2.0 -format wix,msix
+//	go run ./cmd/installer -version 1.2.0 -format app -sign "Developer ID Application: ..."
+//	go run ./cmd/installer -version 1.2.0 -format appimage,flatpak
+//
+// Packaging tools (`wix`, `makeappx`, `codesign`, `hdiutil`, `appimagetool`,
+// `flatpak-builder`) are used when
+// found on PATH; otherwise the generated sources are left in build/ so they
+// can be packaged by hand or in CI.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+const (
+	productName  = "ClaudeBar"
+	manufacturer = "ClaudeBar"
+	description  = "Claude AI Usage Tracker"
+	exeName      = "claudebar.exe"
+
+	// upgradeCode must never change, MSI uses it to find older installs
+	upgradeCode = "6F1C2B9E-4A53-4B8E-9D7A-3C2E5F1A8B40"
+)
+
+// packageData is passed to the WiX and MSIX templates
+type packageData struct {
+	Name         string
+	Manufacturer string
+	Description  string
+	Version      string // four-part, e.g. 1.2.0.0
+	UpgradeCode  string
+	ExeName      string
+	ExePath      string
+	IconPath     string
+	AutoStart    bool
+}
+
+func main() {
+	version := flag.String("version", "1.0.0", "version to stamp into the binary and packages")
+	format := flag.String("format", defaultFormat(), "comma-separated package formats: wix, msix, app, appimage, flatpak")
+	autoStart := flag.Bool("autostart", false, "enable start-on-login by default (Windows)")
+	sign := flag.String("sign", "", "codesign identity for the macOS bundle")
+	flag.Parse()
+
+	formats := make(map[string]bool)
+	for _, f := range strings.Split(*format, ",") {
+		f = strings.TrimSpace(f)
+		switch f {
+		case "wix", "msix", "app", "appimage", "flatpak":
+		default:
+			log.Fatalf("Unknown format %q (want wix, msix, app, appimage or flatpak)", f)
+		}
+		formats[f] = true
+	}
+
+	if formats["app"] {
+		if err := packageApp(filepath.Join("build", "darwin"), *version, *sign); err != nil {
+			log.Fatalf("macOS packaging failed: %v", err)
+		}
+	}
+	if formats["appimage"] {
+		if err := packageAppImage(filepath.Join("build", "linux"), *version); err != nil {
+			log.Fatalf("AppImage packaging failed: %v", err)
+		}
+	}
+	if formats["flatpak"] {
+		if err := packageFlatpak(filepath.Join("build", "linux")); err != nil {
+			log.Fatalf("Flatpak packaging failed: %v", err)
+		}
+	}
+	if !formats["wix"] && !formats["msix"] {
+		return
+	}
+
+	outDir := filepath.Join("build", "windows")
+	exePath, err := buildBinary("windows", outDir, exeName, *version, "-H windowsgui")
+	if err != nil {
+		log.Fatalf("Build failed: %v", err)
+	}
+
+	iconPath, err := filepath.Abs(filepath.Join("assets", "icons", "app.ico"))
+	if err != nil {
+		log.Fatalf("Failed to locate icon: %v", err)
+	}
+
+	data := packageData{
+		Name:         productName,
+		Manufacturer: manufacturer,
+		Description:  description,
+		Version:      fourPartVersion(*version),
+		UpgradeCode:  upgradeCode,
+		ExeName:      exeName,
+		ExePath:      exePath,
+		IconPath:     iconPath,
+		AutoStart:    *autoStart,
+	}
+
+	if formats["wix"] {
+		if err := packageWiX(outDir, data); err != nil {
+			log.Fatalf("WiX packaging failed: %v", err)
+		}
+	}
+	if formats["msix"] {
+		if err := packageMSIX(outDir, data); err != nil {
+			log.Fatalf("MSIX packaging failed: %v", err)
+		}
+	}
+}
+
+// defaultFormat picks the native package formats for the host OS
+func defaultFormat() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "app"
+	case "linux":
+		return "appimage"
+	}
+	return "wix,msix"
+}
+
+// buildBinary compiles the app for goos with the version stamped in
+func buildBinary(goos, outDir, name, version, extraLdflags string) (string, error) {
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return "", err
+	}
+	binPath, err := filepath.Abs(filepath.Join(outDir, name))
+	if err != nil {
+		return "", err
+	}
+
+	ldflags := strings.TrimSpace(fmt.Sprintf("%s -s -w -X claudebar/internal/version.Version=%s", extraLdflags, version))
+	cmd := exec.Command("go", "build", "-trimpath", "-ldflags", ldflags, "-o", binPath, ".")
+	// SQLite needs cgo, so cross-OS builds need a matching C toolchain
+	cmd.Env = append(os.Environ(), "GOOS="+goos, "CGO_ENABLED=1")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	log.Printf("Building %s (%s)", binPath, version)
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return binPath, nil
+}
+
+// packageWiX writes the .wxs source and builds the MSI if wix is installed
+func packageWiX(outDir string, data packageData) error {
+	wxsPath := filepath.Join(outDir, "claudebar.wxs")
+	if err := renderTemplate(wxsPath, wixTemplate, data); err != nil {
+		return err
+	}
+	log.Printf("Wrote %s", wxsPath)
+
+	msiPath := filepath.Join(outDir, fmt.Sprintf("ClaudeBar-%s.msi", data.Version))
+	if _, err := exec.LookPath("wix"); err != nil {
+		log.Printf("wix not found on PATH, skipping MSI build. Run:\n  wix build %s -o %s", wxsPath, msiPath)
+		return nil
+	}
+	return run("wix", "build", wxsPath, "-o", msiPath)
+}
+
+// packageMSIX stages the package layout and packs it if makeappx is installed
+func packageMSIX(outDir string, data packageData) error {
+	stage := filepath.Join(outDir, "msix")
+	assets := filepath.Join(stage, "Assets")
+	if err := os.MkdirAll(assets, 0755); err != nil {
+		return err
+	}
+
+	if err := copyFile(data.ExePath, filepath.Join(stage, exeName)); err != nil {
+		return err
+	}
+	// The manifest only needs the logo files to exist; Windows scales them
+	for _, name := range []string{"StoreLogo.png", "Square44x44Logo.png", "Square150x150Logo.png"} {
+		if err := copyFile(filepath.Join("assets", "icons", "app.png"), filepath.Join(assets, name)); err != nil {
+			return err
+		}
+	}
+
+	if err := renderTemplate(filepath.Join(stage, "AppxManifest.xml"), msixTemplate, data); err != nil {
+		return err
+	}
+	log.Printf("Staged MSIX layout in %s", stage)
+
+	msixPath := filepath.Join(outDir, fmt.Sprintf("ClaudeBar-%s.msix", data.Version))
+	if _, err := exec.LookPath("makeappx"); err != nil {
+		log.Printf("makeappx not found on PATH, skipping MSIX pack. Run:\n  makeappx pack /d %s /p %s", stage, msixPath)
+		return nil
+	}
+	return run("makeappx", "pack", "/o", "/d", stage, "/p", msixPath)
+}
+
+// fourPartVersion pads a semver-ish version to the a.b.c.d form MSI/MSIX want
+func fourPartVersion(v string) string {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	for len(parts) < 4 {
+		parts = append(parts, "0")
+	}
+	return strings.Join(parts[:4], ".")
+}
+
+func renderTemplate(path, text string, data any) error {
+	tmpl, err := template.New(filepath.Base(path)).Parse(text)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return tmpl.Execute(f, data)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// wixTemplate is a per-user WiX v4 package. AUTOSTART=1 adds the HKCU Run
+// entry; REMOVEDATA=1 on uninstall also deletes the config directory.
+const wixTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<Wix xmlns="http://wixtoolset.org/schemas/v4/wxs">
+  <Package Name="{{.Name}}" Manufacturer="{{.Manufacturer}}" Version="{{.Version}}"
+           UpgradeCode="{{.UpgradeCode}}" Scope="perUser" Compressed="yes">
+    <SummaryInformation Description="{{.Description}}" />
+    <MajorUpgrade DowngradeErrorMessage="A newer version of {{.Name}} is already installed." />
+    <MediaTemplate EmbedCab="yes" />
+
+    <Icon Id="AppIcon" SourceFile="{{.IconPath}}" />
+    <Property Id="ARPPRODUCTICON" Value="AppIcon" />
+
+    <Property Id="AUTOSTART" Value="{{if .AutoStart}}1{{else}}0{{end}}" />
+    <Property Id="REMOVEDATA" Value="0" />
+
+    <StandardDirectory Id="LocalAppDataFolder">
+      <Directory Id="ProgramsDir" Name="Programs">
+        <Directory Id="INSTALLFOLDER" Name="{{.Name}}" />
+      </Directory>
+    </StandardDirectory>
+    <StandardDirectory Id="ProgramMenuFolder" />
+
+    <ComponentGroup Id="AppComponents" Directory="INSTALLFOLDER">
+      <Component Id="MainExecutable">
+        <File Id="ClaudeBarExe" Source="{{.ExePath}}" Name="{{.ExeName}}" />
+        <RegistryValue Root="HKCU" Key="Software\{{.Name}}" Name="InstallDir" Type="string" Value="[INSTALLFOLDER]" KeyPath="yes" />
+        <RemoveFolder Id="RemoveInstallFolder" On="uninstall" />
+      </Component>
+    </ComponentGroup>
+
+    <Component Id="StartMenuShortcut" Directory="ProgramMenuFolder">
+      <Shortcut Id="ClaudeBarShortcut" Name="{{.Name}}" Description="{{.Description}}"
+                Target="[INSTALLFOLDER]{{.ExeName}}" WorkingDirectory="INSTALLFOLDER" Icon="AppIcon" />
+      <RegistryValue Root="HKCU" Key="Software\{{.Name}}" Name="StartMenuShortcut" Type="integer" Value="1" KeyPath="yes" />
+    </Component>
+
+    <Component Id="AutoStartEntry" Directory="INSTALLFOLDER" Condition="AUTOSTART = 1">
+      <RegistryValue Root="HKCU" Key="Software\Microsoft\Windows\CurrentVersion\Run" Name="{{.Name}}"
+                     Type="string" Value="&quot;[INSTALLFOLDER]{{.ExeName}}&quot;" KeyPath="yes" />
+    </Component>
+
+    <Feature Id="Main" Title="{{.Name}}" Level="1">
+      <ComponentGroupRef Id="AppComponents" />
+      <ComponentRef Id="StartMenuShortcut" />
+      <ComponentRef Id="AutoStartEntry" />
+    </Feature>
+
+    <!-- The app may have registered itself for auto-start from Settings -->
+    <CustomAction Id="UnregisterAutoStart" FileRef="ClaudeBarExe" ExeCommand="--uninstall"
+                  Execute="immediate" Return="ignore" />
+    <CustomAction Id="PurgeUserData" FileRef="ClaudeBarExe" ExeCommand="--uninstall --purge"
+                  Execute="immediate" Return="ignore" />
+
+    <InstallExecuteSequence>
+      <Custom Action="UnregisterAutoStart" Before="RemoveFiles" Condition="REMOVE = &quot;ALL&quot; AND NOT UPGRADINGPRODUCTCODE AND REMOVEDATA &lt;&gt; 1" />
+      <Custom Action="PurgeUserData" Before="RemoveFiles" Condition="REMOVE = &quot;ALL&quot; AND NOT UPGRADINGPRODUCTCODE AND REMOVEDATA = 1" />
+    </InstallExecuteSequence>
+  </Package>
+</Wix>
+`
+
+// msixTemplate declares a StartupTask so Windows lists ClaudeBar in the
+// Startup apps page; packaged apps can't write the Run key themselves.
+const msixTemplate = `<?xml version="1.0" encoding="utf-8"?>
+<Package xmlns="http://schemas.microsoft.com/appx/manifest/foundation/windows10"
+         xmlns:uap="http://schemas.microsoft.com/appx/manifest/uap/windows10"
+         xmlns:uap5="http://schemas.microsoft.com/appx/manifest/uap/windows10/5"
+         xmlns:rescap="http://schemas.microsoft.com/appx/manifest/foundation/windows10/restrictedcapabilities"
+         IgnorableNamespaces="uap uap5 rescap">
+  <Identity Name="ClaudeBar" Publisher="CN={{.Manufacturer}}" Version="{{.Version}}" ProcessorArchitecture="x64" />
+  <Properties>
+    <DisplayName>{{.Name}}</DisplayName>
+    <PublisherDisplayName>{{.Manufacturer}}</PublisherDisplayName>
+    <Description>{{.Description}}</Description>
+    <Logo>Assets\StoreLogo.png</Logo>
+  </Properties>
+  <Dependencies>
+    <TargetDeviceFamily Name="Windows.Desktop" MinVersion="10.0.17763.0" MaxVersionTested="10.0.22621.0" />
+  </Dependencies>
+  <Resources>
+    <Resource Language="en-us" />
+  </Resources>
+  <Applications>
+    <Application Id="ClaudeBar" Executable="{{.ExeName}}" EntryPoint="Windows.FullTrustApplication">
+      <uap:VisualElements DisplayName="{{.Name}}" Description="{{.Description}}" BackgroundColor="transparent"
+                          Square150x150Logo="Assets\Square150x150Logo.png" Square44x44Logo="Assets\Square44x44Logo.png" />
+      <Extensions>
+        <uap5:Extension Category="windows.startupTask">
+          <uap5:StartupTask TaskId="ClaudeBarStartup" Enabled="{{.AutoStart}}" DisplayName="{{.Name}}" />
+        </uap5:Extension>
+      </Extensions>
+    </Application>
+  </Applications>
+  <Capabilities>
+    <rescap:Capability Name="runFullTrust" />
+  </Capabilities>
+</Package>
+`
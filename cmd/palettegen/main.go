@@ -0,0 +1,90 @@
+// Command palettegen regenerates internal/palette/palette_gen.go from
+// internal/palette/palette.json. Run it through go generate ./internal/palette.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"strings"
+)
+
+var errInvalidColor = errors.New("color must be #RRGGBB or #RRGGBBAA")
+
+// entry is one color of palette.json
+type entry struct {
+	Name  string `json:"name"`  // snake_case, exported in CamelCase
+	Color string `json:"color"` // #RRGGBB or #RRGGBBAA
+	Use   string `json:"use"`   // what it's for, the doc comment
+}
+
+func main() {
+	// go generate runs in the package directory
+	data, err := os.ReadFile("palette.json")
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(data)
+	if err != nil {
+		log.Fatalf("palette.json: %v", err)
+	}
+	if err := os.WriteFile("palette_gen.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate renders the Go source for the palette definition in data
+func generate(data []byte) ([]byte, error) {
+	var entries []entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by palettegen from palette.json; DO NOT EDIT.\n\n")
+	b.WriteString("package palette\n\nimport \"image/color\"\n\nvar (\n")
+	for _, e := range entries {
+		r, g, bl, a, err := parseColor(e.Color)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name, err)
+		}
+		name := exportedName(e.Name)
+		if name == "" {
+			return nil, fmt.Errorf("entry with color %s has no name", e.Color)
+		}
+		fmt.Fprintf(&b, "\t// %s: %s\n\t%s = color.RGBA{%d, %d, %d, %d}\n", name, e.Use, name, r, g, bl, a)
+	}
+	b.WriteString(")\n")
+	return format.Source(b.Bytes())
+}
+
+// parseColor parses #RRGGBB or #RRGGBBAA
+func parseColor(s string) (r, g, b, a uint8, err error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return 0, 0, 0, 0, fmt.Errorf("%w: %q", errInvalidColor, s)
+	}
+	if _, err := fmt.Sscanf(hex, "%02x%02x%02x%02x", &r, &g, &b, &a); err != nil {
+		return 0, 0, 0, 0, fmt.Errorf("%w: %q", errInvalidColor, s)
+	}
+	return r, g, b, a, nil
+}
+
+// exportedName turns a snake_case name into CamelCase, e.g. "text_dark"
+// into "TextDark"
+func exportedName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestPaletteUpToDate fails when palette.json was edited without running
+// go generate ./internal/palette
+func TestPaletteUpToDate(t *testing.T) {
+	dir := filepath.Join("..", "..", "internal", "palette")
+	data, err := os.ReadFile(filepath.Join(dir, "palette.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := generate(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "palette_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("palette_gen.go is stale, run go generate ./internal/palette")
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	r, g, b, a, err := parseColor("#588cec")
+	if err != nil || r != 0x58 || g != 0x8c || b != 0xec || a != 0xff {
+		t.Errorf("parseColor(#588cec) = %d %d %d %d, %v", r, g, b, a, err)
+	}
+	for _, bad := range []string{"#58c", "#588cecz0", "blue"} {
+		if _, _, _, _, err := parseColor(bad); err == nil {
+			t.Errorf("parseColor(%q) succeeded", bad)
+		}
+	}
+}
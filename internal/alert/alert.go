@@ -0,0 +1,43 @@
+package alert
+
+// Severity ranks how urgent a usage level is
+type Severity int
+
+const (
+	None Severity = iota
+	Warning
+	Critical
+)
+
+// String returns the severity name used in logs
+func (s Severity) String() string {
+	switch s {
+	case Warning:
+		return "warning"
+	case Critical:
+		return "critical"
+	}
+	return "none"
+}
+
+// Levels are the usage percentages at which each severity starts
+type Levels struct {
+	Warning  float64
+	Critical float64
+}
+
+// DefaultLevels returns the built-in warning and critical levels
+func DefaultLevels() Levels {
+	return Levels{Warning: 75, Critical: 90}
+}
+
+// Classify returns the severity of a usage percentage
+func (l Levels) Classify(pct float64) Severity {
+	switch {
+	case pct >= l.Critical:
+		return Critical
+	case pct >= l.Warning:
+		return Warning
+	}
+	return None
+}
@@ -0,0 +1,128 @@
+package alert
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Profile is a named set of alert thresholds, e.g. "Crunch week" with higher
+// tolerance or "Conserve" with early alerts. Days and From/To make it apply
+// automatically at those times; a profile without them is only used when
+// picked by hand.
+type Profile struct {
+	Name       string    `json:"name"`
+	Thresholds []float64 `json:"thresholds"`         // usage percentages that notify
+	Warning    float64   `json:"warning,omitempty"`  // 0 keeps the global level
+	Critical   float64   `json:"critical,omitempty"` // 0 keeps the global level
+	Days       []string  `json:"days,omitempty"`     // weekday names, empty = every day
+	From       string    `json:"from,omitempty"`     // "HH:MM", empty = all day
+	To         string    `json:"to,omitempty"`       // "HH:MM", may be before From to span midnight
+}
+
+// Scheduled reports whether the profile can switch on by itself
+func (p *Profile) Scheduled() bool {
+	return len(p.Days) > 0 || p.From != "" || p.To != ""
+}
+
+// ActiveAt reports whether the profile's schedule covers t. Unscheduled
+// profiles are never active on their own. Days name the day a span starts
+// on, so a Friday 22:00-02:00 profile is still active early Saturday.
+func (p *Profile) ActiveAt(t time.Time) bool {
+	if !p.Scheduled() {
+		return false
+	}
+	day := t.Weekday()
+	if p.From != "" || p.To != "" {
+		from, err := parseClock(p.From, 0)
+		if err != nil {
+			return false
+		}
+		to, err := parseClock(p.To, 24*60)
+		if err != nil {
+			return false
+		}
+		now := t.Hour()*60 + t.Minute()
+		switch {
+		case from <= to:
+			if now < from || now >= to {
+				return false
+			}
+		case now < to:
+			// Past midnight in a span that started the day before
+			day = (day + 6) % 7
+		case now < from:
+			return false
+		}
+	}
+	return len(p.Days) == 0 || containsDay(p.Days, day)
+}
+
+// Levels returns the profile's warning and critical levels, falling back to
+// base for those it leaves unset
+func (p *Profile) Levels(base Levels) Levels {
+	if p.Warning > 0 {
+		base.Warning = p.Warning
+	}
+	if p.Critical > 0 {
+		base.Critical = p.Critical
+	}
+	return base
+}
+
+// Validate checks the schedule fields so mistakes show up when the config
+// is loaded rather than as a profile that silently never applies
+func (p *Profile) Validate() error {
+	for _, d := range p.Days {
+		if _, ok := parseDay(d); !ok {
+			return fmt.Errorf("profile %q: unknown day %q", p.Name, d)
+		}
+	}
+	from, err := parseClock(p.From, 0)
+	if err != nil {
+		return fmt.Errorf("profile %q: %w", p.Name, err)
+	}
+	to, err := parseClock(p.To, 24*60)
+	if err != nil {
+		return fmt.Errorf("profile %q: %w", p.Name, err)
+	}
+	if from == to {
+		return fmt.Errorf("profile %q: from and to are the same time, the window is empty", p.Name)
+	}
+	return nil
+}
+
+// parseClock converts "HH:MM" to minutes since midnight; empty gives def
+func parseClock(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// parseDay matches a weekday by name or a prefix of at least three letters
+func parseDay(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if len(name) < 3 {
+		return time.Sunday, false
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.HasPrefix(strings.ToLower(d.String()), name) {
+			return d, true
+		}
+	}
+	return time.Sunday, false
+}
+
+func containsDay(days []string, day time.Weekday) bool {
+	for _, name := range days {
+		if d, ok := parseDay(name); ok && d == day {
+			return true
+		}
+	}
+	return false
+}
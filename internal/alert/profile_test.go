@@ -0,0 +1,69 @@
+package alert
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProfileActiveAt(t *testing.T) {
+	// 2026-10-16 is a Friday
+	at := func(day int, clock string) time.Time {
+		c, err := time.Parse("15:04", clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return time.Date(2026, 10, day, c.Hour(), c.Minute(), 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name    string
+		profile Profile
+		t       time.Time
+		want    bool
+	}{
+		{"unscheduled", Profile{}, at(16, "12:00"), false},
+		{"day only", Profile{Days: []string{"fri"}}, at(16, "12:00"), true},
+		{"other day", Profile{Days: []string{"monday"}}, at(16, "12:00"), false},
+		{"inside window", Profile{From: "09:00", To: "17:00"}, at(16, "09:00"), true},
+		{"window end is exclusive", Profile{From: "09:00", To: "17:00"}, at(16, "17:00"), false},
+		{"before window", Profile{From: "09:00", To: "17:00"}, at(16, "08:59"), false},
+		{"from only", Profile{From: "18:00"}, at(16, "23:59"), true},
+		{"to only", Profile{To: "08:00"}, at(16, "07:00"), true},
+		{"overnight before midnight", Profile{From: "22:00", To: "02:00"}, at(16, "23:00"), true},
+		{"overnight after midnight", Profile{From: "22:00", To: "02:00"}, at(17, "01:00"), true},
+		{"overnight gap", Profile{From: "22:00", To: "02:00"}, at(16, "12:00"), false},
+		{"overnight start day", Profile{Days: []string{"fri"}, From: "22:00", To: "02:00"}, at(16, "23:00"), true},
+		{"overnight spill into next day", Profile{Days: []string{"fri"}, From: "22:00", To: "02:00"}, at(17, "01:00"), true},
+		{"overnight spill from previous day", Profile{Days: []string{"fri"}, From: "22:00", To: "02:00"}, at(16, "01:00"), false},
+		{"overnight spill on sunday", Profile{Days: []string{"sat"}, From: "22:00", To: "02:00"}, at(18, "01:00"), true},
+		{"invalid time", Profile{From: "9am"}, at(16, "10:00"), false},
+	}
+	for _, tt := range tests {
+		if got := tt.profile.ActiveAt(tt.t); got != tt.want {
+			t.Errorf("%s: ActiveAt(%s) = %v, want %v", tt.name, tt.t.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestProfileValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		ok      bool
+	}{
+		{"empty", Profile{}, true},
+		{"full", Profile{Days: []string{"Mon", "tuesday"}, From: "09:00", To: "17:00"}, true},
+		{"overnight", Profile{From: "22:00", To: "02:00"}, true},
+		{"unknown day", Profile{Days: []string{"funday"}}, false},
+		{"short day", Profile{Days: []string{"mo"}}, false},
+		{"bad from", Profile{From: "25:00"}, false},
+		{"bad to", Profile{To: "noon"}, false},
+		{"from equals to", Profile{From: "09:00", To: "09:00"}, false},
+		{"to midnight only", Profile{To: "00:00"}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.profile.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%s: Validate() = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+}
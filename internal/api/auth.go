@@ -3,15 +3,18 @@ package api
 import (
 	"claudebar/internal/browser"
 	"claudebar/internal/config"
+	"context"
 	"encoding/json"
 	"errors"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 var (
-	ErrAuthFailed = errors.New("authentication failed")
+	ErrAuthFailed   = errors.New("authentication failed")
+	ErrInvalidOrgID = errors.New("organization ID must be a UUID")
 )
 
 // claudeCodeCredentials represents the Claude Code CLI credentials file
@@ -27,19 +30,28 @@ type claudeOAuth struct {
 	SubscriptionType string `json:"subscriptionType"`
 }
 
+// sessionKeySource finds claude.ai session keys, normally in browser
+// cookies, best candidate first
+type sessionKeySource interface {
+	SessionKeys() ([]string, error)
+}
+
 // AuthManager handles authentication for the Claude API
 type AuthManager struct {
 	client          *Client
-	cookieExtractor *browser.CookieExtractor
+	cookieExtractor sessionKeySource
 	config          *config.Config
 }
 
 // NewAuthManager creates a new authentication manager
 func NewAuthManager(client *Client) *AuthManager {
+	cfg := config.Get()
+	extractor := browser.NewCookieExtractor()
+	extractor.AddDomain(cfg.Host())
 	return &AuthManager{
 		client:          client,
-		cookieExtractor: browser.NewCookieExtractor(),
-		config:          config.Get(),
+		cookieExtractor: extractor,
+		config:          cfg,
 	}
 }
 
@@ -68,7 +80,7 @@ func (a *AuthManager) Initialize() error {
 	}
 
 	// Try to extract session key from browser cookies (may fail on Chrome 127+ due to App-Bound Encryption)
-	sessionKey, err := a.cookieExtractor.ExtractSessionKey()
+	sessionKey, err := a.browserSessionKey()
 	if err != nil {
 		log.Printf("Failed to extract session key from browser: %v", err)
 		log.Println("Please set session key in Settings (copy from browser DevTools > Application > Cookies > claude.ai > sessionKey)")
@@ -95,42 +107,67 @@ func (a *AuthManager) Initialize() error {
 // Note: Claude Code OAuth tokens (sk-ant-oat01-*) are NOT valid web session keys.
 // We only extract the organization UUID to avoid an extra API call.
 func (a *AuthManager) tryClaudeCodeCredentials() error {
-	home, err := os.UserHomeDir()
+	orgID, err := ClaudeCodeOrgID()
 	if err != nil {
 		return err
 	}
 
+	a.client.SetOrganizationID(orgID)
+	if err := a.config.SetOrganizationID(orgID); err != nil {
+		log.Printf("Warning: failed to save organization ID: %v", err)
+	}
+	log.Printf("Found organization UUID from Claude Code: %s", orgID)
+	return nil
+}
+
+// ClaudeCodeOrgID returns the organization UUID saved by the Claude Code CLI
+// in ~/.claude/.credentials.json
+func ClaudeCodeOrgID() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
 	credPath := filepath.Join(home, ".claude", ".credentials.json")
 	data, err := os.ReadFile(credPath)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	var creds claudeCodeCredentials
 	if err := json.Unmarshal(data, &creds); err != nil {
-		return err
+		return "", err
 	}
 
 	// Only extract org UUID - OAuth tokens don't work with the web API
-	if creds.OrgUUID != "" {
-		a.client.SetOrganizationID(creds.OrgUUID)
-		if err := a.config.SetOrganizationID(creds.OrgUUID); err != nil {
-			log.Printf("Warning: failed to save organization ID: %v", err)
-		}
-		log.Printf("Found organization UUID from Claude Code: %s", creds.OrgUUID)
-		return nil
+	if creds.OrgUUID == "" {
+		return "", errors.New("no organization UUID in Claude Code credentials")
 	}
-
-	return errors.New("no organization UUID in Claude Code credentials")
+	return creds.OrgUUID, nil
 }
 
-// verifyAndFetchOrg verifies the session and fetches organization ID
+// verifyAndFetchOrg verifies the session and fetches organization ID. An
+// organization override skips the lookup and is verified with a usage fetch.
 func (a *AuthManager) verifyAndFetchOrg() error {
+	if id := a.config.OrganizationOverride; id != "" {
+		a.client.SetOrganizationID(id)
+		_, err := a.client.FetchUsage()
+		return err
+	}
+
+	host := a.client.Host()
 	orgs, err := a.client.FetchOrganizations()
 	if err != nil {
 		return err
 	}
 
+	// Remember a redirect to another hostname, so cookies are looked up there
+	if redirected := a.client.Host(); redirected != host {
+		if err := a.config.SetDomain(redirected); err != nil {
+			log.Printf("Warning: failed to save domain: %v", err)
+		}
+	}
+
 	if len(orgs) == 0 {
 		return errors.New("no organizations found")
 	}
@@ -163,9 +200,81 @@ func (a *AuthManager) SetManualSessionKey(key string) error {
 	return nil
 }
 
+// SetOrganizationOverride uses the organization id instead of the detected
+// one, after checking usage can be fetched for it with the current session.
+// An empty id returns to automatic detection.
+func (a *AuthManager) SetOrganizationOverride(id string) error {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if id != "" && !isUUID(id) {
+		return ErrInvalidOrgID
+	}
+
+	prevOrg, prevOverride := a.client.GetOrganizationID(), a.config.OrganizationOverride
+	a.config.OrganizationOverride = id
+	if a.client.GetSessionKey() != "" || id != "" {
+		if err := a.verifyAndFetchOrg(); err != nil {
+			a.config.OrganizationOverride = prevOverride
+			a.client.SetOrganizationID(prevOrg)
+			return err
+		}
+	}
+	return a.config.Save()
+}
+
+// isUUID reports whether s is a UUID in the usual 8-4-4-4-12 hex form
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, c := range s {
+		if i == 8 || i == 13 || i == 18 || i == 23 {
+			if c != '-' {
+				return false
+			}
+		} else if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// browserSessionKey returns a session key from the browsers. When several
+// profiles have one, they are checked against claude.ai at the same time and
+// the first that authenticates wins.
+func (a *AuthManager) browserSessionKey() (string, error) {
+	keys, err := a.cookieExtractor.SessionKeys()
+	if err != nil {
+		return "", err
+	}
+	if len(keys) == 1 {
+		return keys[0], nil
+	}
+
+	log.Printf("Found %d session keys in browsers, checking which one works", len(keys))
+	type result struct {
+		key string
+		err error
+	}
+	results := make(chan result, len(keys))
+	for _, key := range keys {
+		go func() {
+			results <- result{key, a.client.checkSessionKey(context.Background(), key)}
+		}()
+	}
+	var lastErr error
+	for range keys {
+		r := <-results
+		if r.err == nil {
+			return r.key, nil
+		}
+		lastErr = r.err
+	}
+	return "", lastErr
+}
+
 // RefreshFromBrowser attempts to refresh credentials from browser
 func (a *AuthManager) RefreshFromBrowser() error {
-	sessionKey, err := a.cookieExtractor.ExtractSessionKey()
+	sessionKey, err := a.browserSessionKey()
 	if err != nil {
 		return err
 	}
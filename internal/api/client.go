@@ -1,6 +1,8 @@
 package api
 
 import (
+	"context"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,29 +12,30 @@ import (
 	"sync"
 	"time"
 
+	"claudebar/internal/config"
+
 	http "github.com/bogdanfinn/fhttp"
 	tls_client "github.com/bogdanfinn/tls-client"
 	"github.com/bogdanfinn/tls-client/profiles"
 )
 
 const (
-	baseURL   = "https://claude.ai"
 	userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36"
 )
 
 var (
-	ErrNoSessionKey    = errors.New("no session key configured")
-	ErrNoOrgID         = errors.New("no organization ID configured")
-	ErrUnauthorized    = errors.New("unauthorized - session key may be invalid")
-	ErrSessionExpired  = errors.New("session expired - please update session key")
-	ErrRateLimited     = errors.New("rate limited - please wait before retrying")
-	ErrAPIUnavailable  = errors.New("claude API is unavailable")
+	ErrNoSessionKey   = errors.New("no session key configured")
+	ErrNoOrgID        = errors.New("no organization ID configured")
+	ErrUnauthorized   = errors.New("unauthorized - session key may be invalid")
+	ErrSessionExpired = errors.New("session expired - please update session key")
+	ErrRateLimited    = errors.New("rate limited - please wait before retrying")
+	ErrAPIUnavailable = errors.New("claude API is unavailable")
 )
 
 // APIError holds structured error details from the Claude API
 type APIError struct {
-	Type    string `json:"type"`
-	Error   struct {
+	Type  string `json:"type"`
+	Error struct {
 		Type    string `json:"type"`
 		Message string `json:"message"`
 		Details struct {
@@ -45,31 +48,132 @@ type APIError struct {
 // Uses tls-client with Chrome TLS fingerprint to bypass Cloudflare
 type Client struct {
 	httpClient     tls_client.HttpClient
+	baseURL        string
 	sessionKey     string
 	organizationID string
+	headers        map[string]string
+	noClientHints  bool
 	mu             sync.RWMutex
 	lastUsage      *UsageData
 	lastFetch      time.Time
 }
 
+// Options configures a Client. The zero value talks to claude.ai, trusting
+// the system's certificate roots, with a browser's headers.
+type Options struct {
+	Host string // API host, "" for claude.ai
+
+	// Pins are base64 SHA-256 hashes of public keys, one of which the
+	// server's certificate chain must contain. RootCAs replaces the
+	// system's certificate roots.
+	Pins    []string
+	RootCAs *x509.CertPool
+
+	// Headers override request headers, an empty value removing one, and
+	// NoClientHints drops the sec-ch-ua headers; see SetHeaders
+	Headers       map[string]string
+	NoClientHints bool
+}
+
+// ConfigOptions returns the options set in ClaudeBar's config: its host,
+// certificate pins, CA bundle and header overrides. An unreadable CA bundle
+// is logged and left out, so requests fail with a TLS error that points at
+// it.
+func ConfigOptions(cfg *config.Config) Options {
+	opts := Options{
+		Host:          cfg.Host(),
+		Pins:          cfg.CertificatePins,
+		Headers:       cfg.HTTPHeaders,
+		NoClientHints: cfg.DisableClientHints,
+	}
+	if cfg.CABundle != "" {
+		pool, err := loadCABundle(cfg.CABundle)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+		} else {
+			opts.RootCAs = pool
+		}
+	}
+	return opts
+}
+
 // NewClient creates a new API client with Chrome TLS fingerprint
-func NewClient() *Client {
-	options := []tls_client.HttpClientOption{
+func NewClient(opts Options) *Client {
+	if opts.Host == "" {
+		opts.Host = config.DefaultHost
+	}
+
+	// Pins and CA bundle apply to the fallback too, it must not trust more
+	trust := trustOptions(opts)
+	options := append([]tls_client.HttpClientOption{
 		tls_client.WithClientProfile(profiles.Chrome_131),
 		tls_client.WithRandomTLSExtensionOrder(),
 		tls_client.WithTimeoutSeconds(60),
-	}
+	}, trust...)
 
 	tlsClient, err := tls_client.NewHttpClient(tls_client.NewNoopLogger(), options...)
 	if err != nil {
 		log.Printf("Warning: failed to create TLS client, falling back: %v", err)
 		// Fallback - create with default profile
-		tlsClient, _ = tls_client.NewHttpClient(tls_client.NewNoopLogger())
+		tlsClient, _ = tls_client.NewHttpClient(tls_client.NewNoopLogger(), trust...)
 	}
 
 	return &Client{
-		httpClient: tlsClient,
+		httpClient:    tlsClient,
+		baseURL:       "https://" + opts.Host,
+		headers:       opts.Headers,
+		noClientHints: opts.NoClientHints,
+	}
+}
+
+// retryDelay is how long a failed fetch waits before its one retry
+var retryDelay = 2 * time.Second
+
+// RateLimitBackoff returns how long to hold off fetching after the nth
+// consecutive rate-limited request: 30s, doubling up to 4 minutes
+func RateLimitBackoff(n int) time.Duration {
+	if n < 1 {
+		n = 1
 	}
+	return time.Duration(30*(1<<min(n-1, 3))) * time.Second
+}
+
+// sleepContext waits for d, or returns the context's error if it is done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// SetBaseURL points the client at another server than claude.ai, e.g. a
+// test server
+func (c *Client) SetBaseURL(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.baseURL = strings.TrimSuffix(url, "/")
+}
+
+// Host returns the host name the client talks to, normally claude.ai
+func (c *Client) Host() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, host, _ := strings.Cut(c.baseURL, "://")
+	return host
+}
+
+// SetHeaders replaces the request header overrides: each one replaces the
+// header of that name or is added, an empty value removes it, and
+// noClientHints drops the sec-ch-ua headers. The session cookie can't be
+// overridden.
+func (c *Client) SetHeaders(headers map[string]string, noClientHints bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.headers, c.noClientHints = headers, noClientHints
 }
 
 // SetSessionKey updates the session key
@@ -86,6 +190,17 @@ func (c *Client) SetOrganizationID(id string) {
 	c.organizationID = id
 }
 
+// SwitchAccount replaces the session key and organization ID with another
+// account's, forgetting the usage cached for the previous one
+func (c *Client) SwitchAccount(sessionKey, organizationID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sessionKey = sessionKey
+	c.organizationID = organizationID
+	c.lastUsage = nil
+	c.lastFetch = time.Time{}
+}
+
 // GetSessionKey returns the current session key
 func (c *Client) GetSessionKey() string {
 	c.mu.RLock()
@@ -109,8 +224,15 @@ func (c *Client) HasCredentials() bool {
 
 // FetchOrganizations retrieves the user's organizations (retries once on timeout)
 func (c *Client) FetchOrganizations() ([]OrganizationInfo, error) {
+	return c.FetchOrganizationsContext(context.Background())
+}
+
+// FetchOrganizationsContext is FetchOrganizations with a context that cancels
+// the request and the wait before the retry
+func (c *Client) FetchOrganizationsContext(ctx context.Context) ([]OrganizationInfo, error) {
 	c.mu.RLock()
 	sessionKey := c.sessionKey
+	base := c.baseURL
 	c.mu.RUnlock()
 
 	if sessionKey == "" {
@@ -121,17 +243,19 @@ func (c *Client) FetchOrganizations() ([]OrganizationInfo, error) {
 	for attempt := 0; attempt < 2; attempt++ {
 		if attempt > 0 {
 			log.Printf("Retrying organizations fetch (attempt %d)...", attempt+1)
-			time.Sleep(2 * time.Second)
+			if err := sleepContext(ctx, retryDelay); err != nil {
+				return nil, err
+			}
 		}
 
-		orgs, err := c.fetchOrganizationsOnce(sessionKey)
+		orgs, err := c.fetchOrganizationsOnce(ctx, base, sessionKey)
 		if err == nil {
 			return orgs, nil
 		}
 		lastErr = err
 
 		// Don't retry auth errors
-		if err == ErrUnauthorized || err == ErrSessionExpired {
+		if errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrSessionExpired) || ctx.Err() != nil {
 			return nil, err
 		}
 		log.Printf("Organizations fetch attempt %d failed: %v", attempt+1, err)
@@ -139,23 +263,44 @@ func (c *Client) FetchOrganizations() ([]OrganizationInfo, error) {
 	return nil, lastErr
 }
 
-func (c *Client) fetchOrganizationsOnce(sessionKey string) ([]OrganizationInfo, error) {
-	req, err := http.NewRequest("GET", baseURL+"/api/organizations", nil)
+// checkSessionKey reports whether a session key authenticates, without
+// making it the client's key
+func (c *Client) checkSessionKey(ctx context.Context, sessionKey string) error {
+	c.mu.RLock()
+	base := c.baseURL
+	c.mu.RUnlock()
+
+	orgs, err := c.fetchOrganizationsOnce(ctx, base, sessionKey)
+	if err == nil && len(orgs) == 0 {
+		err = errors.New("no organizations found")
+	}
+	return err
+}
+
+func (c *Client) fetchOrganizationsOnce(ctx context.Context, base, sessionKey string) ([]OrganizationInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", base+"/api/organizations", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	c.setHeaders(req, sessionKey)
+	c.setHeaders(req, base, sessionKey)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, classifyTransportError(err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, classifyTransportError(err)
+	}
+
+	if isCloudflareBlock(resp, body) {
+		return nil, ErrCloudflare
 	}
 
 	if resp.StatusCode == 401 || resp.StatusCode == 403 {
@@ -173,7 +318,16 @@ func (c *Client) fetchOrganizationsOnce(sessionKey string) ([]OrganizationInfo,
 
 	var orgs []OrganizationInfo
 	if err := json.Unmarshal(body, &orgs); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: failed to parse organizations: %v", ErrDecode, err)
+	}
+
+	// Accounts on another hostname get redirected there; follow it for the
+	// usage requests too
+	if resp.Request != nil && resp.Request.URL != nil && resp.Request.URL.Host != "" {
+		if final := resp.Request.URL.Scheme + "://" + resp.Request.URL.Host; final != base {
+			log.Printf("Organizations request was redirected to %s, using it from now on", final)
+			c.SetBaseURL(final)
+		}
 	}
 
 	return orgs, nil
@@ -182,9 +336,16 @@ func (c *Client) fetchOrganizationsOnce(sessionKey string) ([]OrganizationInfo,
 // FetchUsage retrieves the current usage data (retries once on transient errors)
 // Uses endpoint: /api/organizations/{orgId}/usage
 func (c *Client) FetchUsage() (*UsageData, error) {
+	return c.FetchUsageContext(context.Background())
+}
+
+// FetchUsageContext is FetchUsage with a context that cancels the request
+// and the wait before the retry
+func (c *Client) FetchUsageContext(ctx context.Context) (*UsageData, error) {
 	c.mu.RLock()
 	sessionKey := c.sessionKey
 	orgID := c.organizationID
+	base := c.baseURL
 	c.mu.RUnlock()
 
 	if sessionKey == "" {
@@ -199,17 +360,21 @@ func (c *Client) FetchUsage() (*UsageData, error) {
 	for attempt := 0; attempt < 2; attempt++ {
 		if attempt > 0 {
 			log.Printf("Retrying usage fetch (attempt %d)...", attempt+1)
-			time.Sleep(2 * time.Second)
+			if err := sleepContext(ctx, retryDelay); err != nil {
+				return nil, err
+			}
 		}
 
-		usage, err := c.fetchUsageOnce(sessionKey, orgID)
+		usage, err := c.fetchUsageOnce(ctx, base, sessionKey, orgID)
 		if err == nil {
 			return usage, nil
 		}
 		lastErr = err
 
-		// Don't retry auth errors — they won't resolve on retry
-		if errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrSessionExpired) {
+		// Don't retry auth or Cloudflare errors — they won't resolve on retry —
+		// nor rate limits, which the caller backs off from
+		if errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrSessionExpired) || errors.Is(err, ErrCloudflare) ||
+			errors.Is(err, ErrRateLimited) || ctx.Err() != nil {
 			return nil, err
 		}
 
@@ -218,25 +383,32 @@ func (c *Client) FetchUsage() (*UsageData, error) {
 	return nil, lastErr
 }
 
-func (c *Client) fetchUsageOnce(sessionKey, orgID string) (*UsageData, error) {
-	url := fmt.Sprintf("%s/api/organizations/%s/usage", baseURL, orgID)
+func (c *Client) fetchUsageOnce(ctx context.Context, base, sessionKey, orgID string) (*UsageData, error) {
+	url := fmt.Sprintf("%s/api/organizations/%s/usage", base, orgID)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	c.setHeaders(req, sessionKey)
+	c.setHeaders(req, base, sessionKey)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, classifyTransportError(err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, classifyTransportError(err)
+	}
+
+	if isCloudflareBlock(resp, body) {
+		return nil, ErrCloudflare
 	}
 
 	if resp.StatusCode == 401 || resp.StatusCode == 403 {
@@ -265,7 +437,7 @@ func (c *Client) fetchUsageOnce(sessionKey, orgID string) (*UsageData, error) {
 	// Parse the response
 	var apiResponse UsageAPIResponse
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse usage response: %w", err)
+		return nil, fmt.Errorf("%w: failed to parse usage response: %v", ErrDecode, err)
 	}
 
 	usage := apiResponse.ToUsageData()
@@ -289,19 +461,19 @@ func parseAPIError(body []byte) string {
 }
 
 // setHeaders sets browser-like headers for API requests
-func (c *Client) setHeaders(req *http.Request, sessionKey string) {
+func (c *Client) setHeaders(req *http.Request, base, sessionKey string) {
 	req.Header = http.Header{
-		"User-Agent":        {userAgent},
-		"Accept":            {"application/json"},
-		"Accept-Language":   {"en-US,en;q=0.9"},
-		"Content-Type":      {"application/json"},
-		"Origin":            {baseURL},
-		"Referer":           {baseURL + "/"},
-		"Sec-Fetch-Dest":    {"empty"},
-		"Sec-Fetch-Mode":    {"cors"},
-		"Sec-Fetch-Site":    {"same-origin"},
-		"sec-ch-ua":         {`"Chromium";v="131", "Not_A Brand";v="24"`},
-		"sec-ch-ua-mobile":  {"?0"},
+		"User-Agent":         {userAgent},
+		"Accept":             {"application/json"},
+		"Accept-Language":    {"en-US,en;q=0.9"},
+		"Content-Type":       {"application/json"},
+		"Origin":             {base},
+		"Referer":            {base + "/"},
+		"Sec-Fetch-Dest":     {"empty"},
+		"Sec-Fetch-Mode":     {"cors"},
+		"Sec-Fetch-Site":     {"same-origin"},
+		"sec-ch-ua":          {`"Chromium";v="131", "Not_A Brand";v="24"`},
+		"sec-ch-ua-mobile":   {"?0"},
 		"sec-ch-ua-platform": {`"Windows"`},
 		http.HeaderOrderKey: {
 			"user-agent", "accept", "accept-language", "content-type",
@@ -310,6 +482,10 @@ func (c *Client) setHeaders(req *http.Request, sessionKey string) {
 			"sec-ch-ua", "sec-ch-ua-mobile", "sec-ch-ua-platform",
 		},
 	}
+	c.mu.RLock()
+	headers, noClientHints := c.headers, c.noClientHints
+	c.mu.RUnlock()
+	applyHeaderOverrides(req.Header, headers, noClientHints)
 
 	// Session key is sent as a cookie
 	if strings.HasPrefix(sessionKey, "sk-ant-") {
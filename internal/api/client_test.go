@@ -0,0 +1,226 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"claudebar/internal/api/testserver"
+	"claudebar/internal/config"
+	"claudebar/internal/config/configtest"
+)
+
+// fakeCookies stands in for the browser cookie extractor
+type fakeCookies struct {
+	key string
+	err error
+}
+
+func (f fakeCookies) SessionKeys() ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []string{f.key}, nil
+}
+
+// newTestClient returns a client of the test server, with a config of its own
+// and no delay before retries
+func newTestClient(t *testing.T) (*Client, *testserver.Server) {
+	t.Helper()
+	configtest.Use(t)
+	saved := retryDelay
+	retryDelay = 0
+	t.Cleanup(func() { retryDelay = saved })
+
+	srv := testserver.New()
+	t.Cleanup(srv.Close)
+
+	c := NewClient(Options{})
+	c.SetBaseURL(srv.URL)
+	c.SetSessionKey(testserver.DefaultSessionKey)
+	c.SetOrganizationID(testserver.DefaultOrgID)
+	return c, srv
+}
+
+func newTestAuthManager(c *Client, cookies sessionKeySource) *AuthManager {
+	a := NewAuthManager(c)
+	a.cookieExtractor = cookies
+	return a
+}
+
+func TestFetchUsage(t *testing.T) {
+	c, srv := newTestClient(t)
+	reset := time.Now().Add(90 * time.Minute).UTC().Truncate(time.Second)
+	srv.SetUsage(
+		testserver.Usage{Utilization: 63, ResetsAt: reset},
+		testserver.Usage{Utilization: 28, ResetsAt: reset.Add(72 * time.Hour)},
+	)
+
+	usage, err := c.FetchUsage()
+	if err != nil {
+		t.Fatalf("FetchUsage: %v", err)
+	}
+	if usage.FiveHour.Utilization != 63 || !usage.FiveHour.ResetsAt.Equal(reset) {
+		t.Errorf("session = %v%% resetting %v, want 63%% resetting %v",
+			usage.FiveHour.Utilization, usage.FiveHour.ResetsAt, reset)
+	}
+	if usage.SevenDay.Utilization != 28 {
+		t.Errorf("weekly = %v%%, want 28%%", usage.SevenDay.Utilization)
+	}
+	if c.GetLastUsage() != usage {
+		t.Error("GetLastUsage doesn't return the fetched usage")
+	}
+}
+
+func TestFetchUsageErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     testserver.Mode
+		want     error
+		requests int // usage requests per fetch, 2 when the error is retried
+	}{
+		{"session expired", testserver.SessionExpired, ErrSessionExpired, 1},
+		{"unauthorized", testserver.Unauthorized, ErrUnauthorized, 1},
+		{"rate limited", testserver.RateLimited, ErrRateLimited, 1},
+		{"cloudflare", testserver.Cloudflare, ErrCloudflare, 1},
+		{"unavailable", testserver.Unavailable, ErrAPIUnavailable, 2},
+		{"origin down", testserver.OriginDown, ErrAPIUnavailable, 2},
+		{"garbage", testserver.Garbage, ErrDecode, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, srv := newTestClient(t)
+			srv.SetMode(tt.mode)
+
+			_, err := c.FetchUsage()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("FetchUsage error = %v, want %v", err, tt.want)
+			}
+			if got := srv.Requests("usage"); got != tt.requests {
+				t.Errorf("usage requests = %d, want %d", got, tt.requests)
+			}
+		})
+	}
+}
+
+func TestFetchUsageRetriesTransientFailure(t *testing.T) {
+	c, srv := newTestClient(t)
+	srv.FailNext(testserver.Unavailable, 1)
+
+	if _, err := c.FetchUsage(); err != nil {
+		t.Fatalf("FetchUsage: %v", err)
+	}
+	if got := srv.Requests("usage"); got != 2 {
+		t.Errorf("usage requests = %d, want 2", got)
+	}
+}
+
+// A 429 storm must not be amplified by retries, and the app's backoff must
+// grow with each consecutive rate-limited fetch until it hits the cap
+func TestRateLimitStorm(t *testing.T) {
+	c, srv := newTestClient(t)
+	srv.SetMode(testserver.RateLimited)
+
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute}
+	for i, backoff := range want {
+		_, err := c.FetchUsage()
+		if !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("fetch %d: error = %v, want %v", i+1, err, ErrRateLimited)
+		}
+		if got := RateLimitBackoff(i + 1); got != backoff {
+			t.Errorf("backoff after %d rate limits = %v, want %v", i+1, got, backoff)
+		}
+	}
+	if got := srv.Requests("usage"); got != len(want) {
+		t.Errorf("usage requests = %d, want one per fetch (%d)", got, len(want))
+	}
+
+	srv.SetMode(testserver.Healthy)
+	if _, err := c.FetchUsage(); err != nil {
+		t.Errorf("FetchUsage after the storm: %v", err)
+	}
+}
+
+func TestFetchOrganizations(t *testing.T) {
+	c, _ := newTestClient(t)
+	orgs, err := c.FetchOrganizations()
+	if err != nil {
+		t.Fatalf("FetchOrganizations: %v", err)
+	}
+	if len(orgs) != 1 || orgs[0].ID != testserver.DefaultOrgID {
+		t.Errorf("organizations = %+v, want one with ID %s", orgs, testserver.DefaultOrgID)
+	}
+}
+
+func TestSetManualSessionKey(t *testing.T) {
+	c, _ := newTestClient(t)
+	c.SetOrganizationID("")
+	a := newTestAuthManager(c, fakeCookies{err: errors.New("no browser")})
+
+	if err := a.SetManualSessionKey("sk-ant-sid01-wrong"); !errors.Is(err, ErrSessionExpired) {
+		t.Fatalf("wrong key: error = %v, want %v", err, ErrSessionExpired)
+	}
+	if c.GetSessionKey() != "" {
+		t.Error("rejected key was kept")
+	}
+
+	if err := a.SetManualSessionKey(testserver.DefaultSessionKey); err != nil {
+		t.Fatalf("valid key: %v", err)
+	}
+	if got := c.GetOrganizationID(); got != testserver.DefaultOrgID {
+		t.Errorf("organization ID = %q, want %q", got, testserver.DefaultOrgID)
+	}
+	if got := config.Get().SessionKey; got != testserver.DefaultSessionKey {
+		t.Errorf("saved session key = %q, want %q", got, testserver.DefaultSessionKey)
+	}
+}
+
+// When the session expires, the app refreshes the key from the browser and
+// the next fetch goes through with it
+func TestSessionExpiryRecoversFromBrowser(t *testing.T) {
+	c, srv := newTestClient(t)
+	const newKey = "sk-ant-sid01-rotated"
+	a := newTestAuthManager(c, fakeCookies{key: newKey})
+
+	if _, err := c.FetchUsage(); err != nil {
+		t.Fatalf("FetchUsage before expiry: %v", err)
+	}
+
+	srv.SetSessionKey(newKey)
+	if _, err := c.FetchUsage(); !errors.Is(err, ErrSessionExpired) {
+		t.Fatalf("FetchUsage after expiry: error = %v, want %v", err, ErrSessionExpired)
+	}
+
+	if err := a.RefreshFromBrowser(); err != nil {
+		t.Fatalf("RefreshFromBrowser: %v", err)
+	}
+	if got := c.GetSessionKey(); got != newKey {
+		t.Errorf("session key = %q, want %q", got, newKey)
+	}
+	if _, err := c.FetchUsage(); err != nil {
+		t.Errorf("FetchUsage after refresh: %v", err)
+	}
+}
+
+func TestRefreshFromBrowserWithStaleCookie(t *testing.T) {
+	c, srv := newTestClient(t)
+	a := newTestAuthManager(c, fakeCookies{key: testserver.DefaultSessionKey})
+	srv.SetSessionKey("sk-ant-sid01-elsewhere")
+
+	if err := a.RefreshFromBrowser(); !errors.Is(err, ErrSessionExpired) {
+		t.Errorf("RefreshFromBrowser error = %v, want %v", err, ErrSessionExpired)
+	}
+}
+
+func TestCloudflareIsExplained(t *testing.T) {
+	c, srv := newTestClient(t)
+	srv.SetMode(testserver.Cloudflare)
+
+	_, err := c.FetchOrganizations()
+	if !errors.Is(err, ErrCloudflare) {
+		t.Fatalf("FetchOrganizations error = %v, want %v", err, ErrCloudflare)
+	}
+	if status, _ := Explain(err); status != "Blocked by Cloudflare" {
+		t.Errorf("Explain status = %q", status)
+	}
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// Network-level failures, wrapped around the underlying error so errors.Is
+// can classify them while the original message stays in the logs
+var (
+	ErrDNS          = errors.New("DNS lookup failed")
+	ErrTLSHandshake = errors.New("TLS handshake failed")
+	ErrTimeout      = errors.New("request timed out")
+	ErrCloudflare   = errors.New("blocked by Cloudflare")
+	ErrDecode       = errors.New("unexpected response format")
+)
+
+// classifyTransportError maps an HTTP client error to one of the typed errors
+func classifyTransportError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return fmt.Errorf("%w: %v", ErrTimeout, err)
+		}
+		return fmt.Errorf("%w: %v", ErrDNS, err)
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) ||
+		(errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+
+	if isPinMismatch(err) {
+		return fmt.Errorf("%w: %v", ErrPinMismatch, err)
+	}
+
+	var certErr *tls.CertificateVerificationError
+	var authErr x509.UnknownAuthorityError
+	var hostErr x509.HostnameError
+	var recordErr tls.RecordHeaderError
+	if errors.As(err, &certErr) || errors.As(err, &authErr) || errors.As(err, &hostErr) || errors.As(err, &recordErr) {
+		return fmt.Errorf("%w: %v", ErrTLSHandshake, err)
+	}
+
+	// tls-client flattens many errors into strings, so fall back to matching text
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "no such host") || strings.Contains(msg, "server misbehaving") ||
+		strings.Contains(msg, "temporary failure in name resolution"):
+		return fmt.Errorf("%w: %v", ErrDNS, err)
+	case strings.Contains(msg, "timeout") || strings.Contains(msg, "deadline exceeded"):
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	case strings.Contains(msg, "tls") || strings.Contains(msg, "handshake") || strings.Contains(msg, "x509") ||
+		strings.Contains(msg, "certificate"):
+		return fmt.Errorf("%w: %v", ErrTLSHandshake, err)
+	}
+	return err
+}
+
+// isCloudflareBlock detects Cloudflare challenge pages, which come back as
+// 403/503 HTML instead of API JSON. Cloudflare's own error pages for an
+// unreachable origin (502, 520-522...) mention Cloudflare too but mean the API
+// is down, so only challenge markers count.
+func isCloudflareBlock(resp *http.Response, body []byte) bool {
+	if resp.StatusCode != 403 && resp.StatusCode != 503 {
+		return false
+	}
+	if resp.Header.Get("Cf-Mitigated") == "challenge" {
+		return true
+	}
+	if !strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "text/html") {
+		return false
+	}
+	page := strings.ToLower(string(body[:min(len(body), 4096)]))
+	return strings.Contains(page, "cf-chl") || strings.Contains(page, "challenge-platform")
+}
+
+// Explain returns short status text for the overlay and a suggested fix for
+// an error returned by the client. Unknown errors get a generic message.
+func Explain(err error) (status, suggestion string) {
+	switch {
+	case errors.Is(err, ErrAuthFailed):
+		return "Not signed in", "No working session key was found. Log in to claude.ai in your browser or paste a session key in Settings."
+	case errors.Is(err, ErrSessionExpired):
+		return "Session expired", "Log in to claude.ai in your browser, then use Refresh from Browser or paste a new session key in Settings."
+	case errors.Is(err, ErrUnauthorized):
+		return "Auth failed", "The session key was rejected. Paste a fresh sessionKey cookie in Settings."
+	case errors.Is(err, ErrRateLimited):
+		return "Rate limited", "Claude is throttling requests. Increase the refresh interval in Settings."
+	case errors.Is(err, ErrAPIUnavailable):
+		return "Claude API unavailable", "Claude is having problems. Check status.anthropic.com and wait."
+	case errors.Is(err, ErrDNS):
+		return "DNS lookup failed", "claude.ai could not be resolved. Check your internet connection, DNS settings or VPN."
+	case errors.Is(err, ErrPinMismatch):
+		return "Certificate not trusted", "claude.ai presented a certificate that matches none of certificate_pins, so nothing was sent. Something may be intercepting HTTPS; if claude.ai changed its certificate, update or remove the pins."
+	case errors.Is(err, ErrTLSHandshake):
+		return "Secure connection failed", "The TLS handshake failed. A proxy, antivirus or firewall may be intercepting HTTPS (set ca_bundle to its CA certificate); check the system clock too."
+	case errors.Is(err, ErrTimeout):
+		return "Connection timed out", "claude.ai did not respond in time. Check your network or proxy; the request will be retried."
+	case errors.Is(err, ErrCloudflare):
+		return "Blocked by Cloudflare", "Cloudflare challenged the request. Open claude.ai in your browser once, then refresh the session key from the browser."
+	case errors.Is(err, ErrDecode):
+		return "Unexpected response", "Claude returned data ClaudeBar doesn't understand. The API may have changed; check for a ClaudeBar update."
+	default:
+		return "Connection error", "The request failed for an unknown reason. See the log for details."
+	}
+}
+
+// ErrorKind names the kind of an error returned by the client in a word,
+// for counting failures by kind: "auth", "rate_limited", "unavailable",
+// "dns", "tls", "timeout", "cloudflare", "decode" or "other"
+func ErrorKind(err error) string {
+	switch {
+	case errors.Is(err, ErrAuthFailed), errors.Is(err, ErrSessionExpired), errors.Is(err, ErrUnauthorized):
+		return "auth"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrAPIUnavailable):
+		return "unavailable"
+	case errors.Is(err, ErrDNS):
+		return "dns"
+	case errors.Is(err, ErrPinMismatch), errors.Is(err, ErrTLSHandshake):
+		return "tls"
+	case errors.Is(err, ErrTimeout):
+		return "timeout"
+	case errors.Is(err, ErrCloudflare):
+		return "cloudflare"
+	case errors.Is(err, ErrDecode):
+		return "decode"
+	default:
+		return "other"
+	}
+}
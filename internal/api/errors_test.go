@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+func TestIsCloudflareBlock(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		header    map[string]string
+		body      string
+		wantBlock bool
+	}{
+		{"challenge header", 403, map[string]string{"Cf-Mitigated": "challenge"}, "", true},
+		{"challenge page", 403, map[string]string{"Content-Type": "text/html"}, `<script src="/cdn-cgi/challenge-platform/x"></script>`, true},
+		{"challenge 503", 503, map[string]string{"Content-Type": "text/html; charset=UTF-8"}, `<form id="cf-chl-form">`, true},
+		{"origin error 502", 502, map[string]string{"Content-Type": "text/html"}, `Bad gateway, performance by Cloudflare`, false},
+		{"origin error 522", 522, map[string]string{"Content-Type": "text/html"}, `Connection timed out cloudflare`, false},
+		{"plain 503", 503, map[string]string{"Content-Type": "text/html"}, `Service Unavailable, Cloudflare`, false},
+		{"api 403", 403, map[string]string{"Content-Type": "application/json"}, `{"error":{}}`, false},
+		{"challenge header on 200", 200, map[string]string{"Cf-Mitigated": "challenge"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			for k, v := range tt.header {
+				resp.Header.Set(k, v)
+			}
+			if got := isCloudflareBlock(resp, []byte(tt.body)); got != tt.wantBlock {
+				t.Errorf("isCloudflareBlock = %v, want %v", got, tt.wantBlock)
+			}
+		})
+	}
+}
+
+func TestClassifyPinMismatch(t *testing.T) {
+	err := classifyTransportError(errors.New("Get \"https://claude.ai/api/organizations\": bad ssl pin detected for claude.ai"))
+	if !errors.Is(err, ErrPinMismatch) {
+		t.Errorf("classifyTransportError = %v, want ErrPinMismatch", err)
+	}
+	if status, _ := Explain(err); status != "Certificate not trusted" {
+		t.Errorf("Explain status = %q", status)
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{ErrSessionExpired, "auth"},
+		{fmt.Errorf("fetch failed: %w", ErrRateLimited), "rate_limited"},
+		{fmt.Errorf("%w: %w", ErrTLSHandshake, ErrPinMismatch), "tls"},
+		{ErrCloudflare, "cloudflare"},
+		{errors.New("connection reset"), "other"},
+	}
+	for _, tt := range tests {
+		if got := ErrorKind(tt.err); got != tt.want {
+			t.Errorf("ErrorKind(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// applyHeaderOverrides changes the request headers as configured for proxies
+// that need them: each override replaces the header of that name in its
+// place in the header order, or is added after the others, and an empty
+// value removes the header. The session cookie can't be overridden.
+// noClientHints drops the sec-ch-ua headers.
+func applyHeaderOverrides(h http.Header, overrides map[string]string, noClientHints bool) {
+	order := h[http.HeaderOrderKey]
+	remove := func(name string) {
+		for key := range h {
+			if key != http.HeaderOrderKey && strings.EqualFold(key, name) {
+				delete(h, key)
+			}
+		}
+		order = slices.DeleteFunc(order, func(k string) bool { return strings.EqualFold(k, name) })
+	}
+
+	if noClientHints {
+		for key := range h {
+			if strings.HasPrefix(strings.ToLower(key), "sec-ch-ua") {
+				remove(key)
+			}
+		}
+	}
+
+	// Sorted, so added headers come in the same order on every request
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := overrides[name]
+		if strings.EqualFold(name, "cookie") {
+			continue
+		}
+		if value == "" {
+			remove(name)
+			continue
+		}
+		key := name
+		for existing := range h {
+			if existing != http.HeaderOrderKey && strings.EqualFold(existing, name) {
+				key = existing
+			}
+		}
+		h[key] = []string{value}
+		if !slices.ContainsFunc(order, func(k string) bool { return strings.EqualFold(k, name) }) {
+			order = append(order, strings.ToLower(name))
+		}
+	}
+	h[http.HeaderOrderKey] = order
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"slices"
+	"testing"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+func TestApplyHeaderOverrides(t *testing.T) {
+	h := http.Header{
+		"User-Agent":       {"chrome"},
+		"Accept":           {"application/json"},
+		"sec-ch-ua":        {"chromium"},
+		"sec-ch-ua-mobile": {"?0"},
+		http.HeaderOrderKey: {
+			"user-agent", "accept", "cookie", "sec-ch-ua", "sec-ch-ua-mobile",
+		},
+	}
+	applyHeaderOverrides(h, map[string]string{
+		"user-agent":      "proxy-approved",
+		"X-Proxy-Tenant":  "acme",
+		"Accept":          "",
+		"Cookie":          "stolen",
+		"X-Another-Extra": "1",
+	}, true)
+
+	if got := h["User-Agent"]; len(got) != 1 || got[0] != "proxy-approved" {
+		t.Errorf("User-Agent = %q, want the override in place", got)
+	}
+	if got := h["X-Proxy-Tenant"]; len(got) != 1 || got[0] != "acme" {
+		t.Errorf("X-Proxy-Tenant = %q, want acme", got)
+	}
+	for _, name := range []string{"Accept", "sec-ch-ua", "sec-ch-ua-mobile", "Cookie"} {
+		if _, ok := h[name]; ok {
+			t.Errorf("%s still set", name)
+		}
+	}
+	want := []string{"user-agent", "cookie", "x-another-extra", "x-proxy-tenant"}
+	if got := h[http.HeaderOrderKey]; !slices.Equal(got, want) {
+		t.Errorf("header order = %q, want %q", got, want)
+	}
+}
@@ -7,11 +7,30 @@ import (
 
 // UsageData represents the complete usage information from Claude
 type UsageData struct {
-	FiveHour      UsageStat `json:"five_hour"`      // 5-hour session usage
-	SevenDay      UsageStat `json:"seven_day"`      // Weekly usage
-	SevenDayOpus  UsageStat `json:"seven_day_opus"` // Weekly Opus usage
+	FiveHour       UsageStat `json:"five_hour"`        // 5-hour session usage
+	SevenDay       UsageStat `json:"seven_day"`        // Weekly usage
+	SevenDayOpus   UsageStat `json:"seven_day_opus"`   // Weekly Opus usage
 	SevenDaySonnet UsageStat `json:"seven_day_sonnet"` // Weekly Sonnet usage
-	LastUpdated   time.Time `json:"last_updated"`
+	LastUpdated    time.Time `json:"last_updated"`
+}
+
+// MetricNames lists the usage metrics by the names used in config, the CLI
+// and the local API
+var MetricNames = []string{"session", "weekly", "opus", "sonnet"}
+
+// Metric returns the usage metric with the given name, or nil if there is none
+func (u *UsageData) Metric(name string) *UsageStat {
+	switch name {
+	case "session":
+		return &u.FiveHour
+	case "weekly":
+		return &u.SevenDay
+	case "opus":
+		return &u.SevenDayOpus
+	case "sonnet":
+		return &u.SevenDaySonnet
+	}
+	return nil
 }
 
 // UsageStat represents a single usage metric
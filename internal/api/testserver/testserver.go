@@ -0,0 +1,250 @@
+// Package testserver emulates the claude.ai endpoints ClaudeBar talks to,
+// for end-to-end tests of the API client and authentication. Besides the
+// happy path it can expire sessions, answer with 429 storms, serve
+// Cloudflare challenge and error pages and fail like an overloaded API.
+package testserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults the server accepts until changed
+const (
+	DefaultSessionKey = "sk-ant-sid01-testserver"
+	DefaultOrgID      = "00000000-0000-4000-8000-000000000001"
+)
+
+// Mode selects how the server answers requests
+type Mode int
+
+const (
+	Healthy        Mode = iota // serve organizations and usage
+	SessionExpired             // 401 with the account_session_invalid error code
+	Unauthorized               // 403 without an error code
+	RateLimited                // 429 on every request
+	Cloudflare                 // 403 HTML challenge page
+	Unavailable                // 503 overloaded
+	Garbage                    // 200 with a body that isn't the usage JSON
+	OriginDown                 // 502 Cloudflare error page, origin unreachable
+)
+
+// Usage is one metric returned by the usage endpoint
+type Usage struct {
+	Utilization float64
+	ResetsAt    time.Time
+}
+
+// Server is a fake claude.ai. All methods are safe to call while requests
+// are in flight.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	mode       Mode
+	failures   []Mode // served before mode, one per request
+	sessionKey string
+	orgID      string
+	fiveHour   Usage
+	sevenDay   Usage
+	requests   map[string]int
+}
+
+// New starts a healthy server accepting DefaultSessionKey for DefaultOrgID.
+// Close it when done.
+func New() *Server {
+	now := time.Now().UTC().Truncate(time.Second)
+	s := &Server{
+		sessionKey: DefaultSessionKey,
+		orgID:      DefaultOrgID,
+		fiveHour:   Usage{Utilization: 42, ResetsAt: now.Add(3 * time.Hour)},
+		sevenDay:   Usage{Utilization: 17, ResetsAt: now.Add(5 * 24 * time.Hour)},
+		requests:   map[string]int{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/organizations", s.handleOrganizations)
+	mux.HandleFunc("GET /api/organizations/{org}/usage", s.handleUsage)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// SetMode changes how every following request is answered
+func (s *Server) SetMode(m Mode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mode = m
+	s.failures = nil
+}
+
+// FailNext answers the next n requests in mode m, then returns to the
+// current mode, e.g. for a transient outage that a retry gets past
+func (s *Server) FailNext(m Mode, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < n; i++ {
+		s.failures = append(s.failures, m)
+	}
+}
+
+// SetSessionKey changes the session key the server accepts; requests with
+// the old one get the expired-session error, as after a logout
+func (s *Server) SetSessionKey(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessionKey = key
+}
+
+// SetUsage changes the session (five-hour) and weekly usage served
+func (s *Server) SetUsage(fiveHour, sevenDay Usage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fiveHour = fiveHour
+	s.sevenDay = sevenDay
+}
+
+// Requests returns how many requests reached an endpoint: "organizations"
+// or "usage"
+func (s *Server) Requests(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[endpoint]
+}
+
+// begin counts the request and returns the mode to answer it in
+func (s *Server) begin(endpoint string) Mode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[endpoint]++
+	if len(s.failures) > 0 {
+		m := s.failures[0]
+		s.failures = s.failures[1:]
+		return m
+	}
+	return s.mode
+}
+
+// authorized reports whether the request carries the current session key,
+// sent the way browsers do as a sessionKey cookie
+func (s *Server) authorized(r *http.Request) bool {
+	s.mu.Lock()
+	key := s.sessionKey
+	s.mu.Unlock()
+	c, err := r.Cookie("sessionKey")
+	return err == nil && c.Value == key
+}
+
+func (s *Server) handleOrganizations(w http.ResponseWriter, r *http.Request) {
+	if s.fail(w, s.begin("organizations")) {
+		return
+	}
+	if !s.authorized(r) {
+		writeSessionInvalid(w)
+		return
+	}
+
+	s.mu.Lock()
+	orgs := []map[string]string{{"uuid": s.orgID, "name": "Test Organization"}}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, orgs)
+}
+
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	if s.fail(w, s.begin("usage")) {
+		return
+	}
+	if !s.authorized(r) {
+		writeSessionInvalid(w)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.PathValue("org") != s.orgID {
+		writeJSON(w, http.StatusForbidden, apiError("permission_error", "Organization not found", ""))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"five_hour": metric(s.fiveHour),
+		"seven_day": metric(s.sevenDay),
+	})
+}
+
+// fail answers the request for any mode but Healthy and reports whether it did
+func (s *Server) fail(w http.ResponseWriter, m Mode) bool {
+	switch m {
+	case SessionExpired:
+		writeSessionInvalid(w)
+	case Unauthorized:
+		writeJSON(w, http.StatusForbidden, apiError("permission_error", "Invalid authorization", ""))
+	case RateLimited:
+		w.Header().Set("Retry-After", "30")
+		writeJSON(w, http.StatusTooManyRequests, apiError("rate_limit_error", "Rate limited", ""))
+	case Cloudflare:
+		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		w.Header().Set("Cf-Mitigated", "challenge")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, cloudflarePage)
+	case Unavailable:
+		writeJSON(w, http.StatusServiceUnavailable, apiError("overloaded_error", "Overloaded", ""))
+	case Garbage:
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"five_hour": "not an object"`)
+	case OriginDown:
+		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprint(w, originErrorPage)
+	default:
+		return false
+	}
+	return true
+}
+
+func writeSessionInvalid(w http.ResponseWriter) {
+	writeJSON(w, http.StatusUnauthorized,
+		apiError("authentication_error", "Invalid session", "account_session_invalid"))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// apiError builds an error body shaped like claude.ai's
+func apiError(typ, message, code string) map[string]any {
+	e := map[string]any{"type": typ, "message": message}
+	if code != "" {
+		e["details"] = map[string]string{"error_code": code}
+	}
+	return map[string]any{"type": "error", "error": e}
+}
+
+func metric(u Usage) map[string]any {
+	resetsAt := ""
+	if !u.ResetsAt.IsZero() {
+		resetsAt = u.ResetsAt.UTC().Format(time.RFC3339)
+	}
+	return map[string]any{"utilization": u.Utilization, "resets_at": resetsAt}
+}
+
+// cloudflarePage is a trimmed-down Cloudflare managed challenge
+var cloudflarePage = strings.TrimSpace(`
+<!DOCTYPE html><html lang="en-US"><head><title>Just a moment...</title>
+<meta http-equiv="Content-Type" content="text/html; charset=UTF-8"></head>
+<body><div class="main-wrapper" role="main"><div class="main-content">
+<noscript>Enable JavaScript and cookies to continue</noscript>
+<script src="/cdn-cgi/challenge-platform/h/g/orchestrate/chl_page/v1?ray=0"></script>
+</div></div></body></html>`)
+
+// originErrorPage is a trimmed-down Cloudflare "bad gateway" error page
+var originErrorPage = strings.TrimSpace(`
+<!DOCTYPE html><html lang="en-US"><head><title>claude.ai | 502: Bad gateway</title></head>
+<body><div id="cf-wrapper"><h1>Bad gateway <span class="cf-error-code">Error code 502</span></h1>
+<p>The web server reported a bad gateway error.</p>
+<span>Performance &amp; security by <a href="https://www.cloudflare.com">Cloudflare</a></span>
+</div></body></html>`)
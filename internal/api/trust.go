@@ -0,0 +1,56 @@
+package api
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	http "github.com/bogdanfinn/fhttp"
+	tls_client "github.com/bogdanfinn/tls-client"
+)
+
+// ErrPinMismatch means the server's certificate chain matched none of the
+// configured pins. The request is refused rather than sent unpinned.
+var ErrPinMismatch = errors.New("certificate doesn't match the pinned keys")
+
+// trustOptions returns the client options for the certificate trust in
+// opts: pins for the API host, and the roots replacing the system's, e.g.
+// with a corporate CA added for SSL-inspecting proxies
+func trustOptions(opts Options) []tls_client.HttpClientOption {
+	var options []tls_client.HttpClientOption
+	if len(opts.Pins) > 0 {
+		pins := map[string][]string{opts.Host: opts.Pins}
+		options = append(options, tls_client.WithCertificatePinning(pins, func(req *http.Request) {
+			log.Printf("Certificate of %s matches none of the %d pinned keys, refusing the request", req.URL.Host, len(opts.Pins))
+		}))
+	}
+	if opts.RootCAs != nil {
+		options = append(options, tls_client.WithTransportOptions(&tls_client.TransportOptions{RootCAs: opts.RootCAs}))
+	}
+	return options
+}
+
+// loadCABundle returns the system roots plus the PEM certificates in path
+func loadCABundle(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool() // Windows before Go 1.18 and some minimal systems
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", path)
+	}
+	return pool, nil
+}
+
+// isPinMismatch reports whether a client error is tls-client refusing a
+// certificate that failed the pin check
+func isPinMismatch(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "bad ssl pin")
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"log"
+	"slices"
+
+	"fyne.io/fyne/v2"
+
+	"claudebar/internal/api"
+	"claudebar/internal/config"
+	"claudebar/internal/ui"
+)
+
+// switchAccount makes another account the active one, as picked in the tray
+// or added in Settings: the API client gets its session, what was derived
+// from the previous account's usage is dropped, and it signs in. Call on the
+// UI thread.
+func (a *App) switchAccount(name string) {
+	if err := a.config.SwitchAccount(name); err != nil {
+		log.Printf("Failed to switch to account %q: %v", name, err)
+		return
+	}
+	log.Printf("Switched to account %q", name)
+	a.apiClient.SwitchAccount(a.config.SessionKey, accountOrganization(a.config.OrganizationID, a.config.OrganizationOverride))
+
+	a.mu.Lock()
+	a.lastUsage = nil
+	a.lastFetchErr = nil
+	a.consecutiveErrors = 0
+	a.lastSessionThreshold = 0
+	a.lastWeeklyThreshold = 0
+	a.budgetAlerted = false
+	a.spikes.reset()
+	a.mu.Unlock()
+	a.requests.Reset()
+	a.reauth.reset()
+	if a.cycles != nil {
+		a.cycles.SwitchAccount()
+	}
+
+	a.updateRedaction()
+	a.pushSync()
+	a.updateAccountStatus(false)
+	a.tray.Refresh()
+	go a.authenticate()
+}
+
+// accountOrganization is the organization an account's usage is fetched
+// for: the override entered in Settings, otherwise the detected one
+func accountOrganization(detected, override string) string {
+	if override != "" {
+		return override
+	}
+	return detected
+}
+
+// refreshOtherAccounts fetches the usage of the accounts besides the active
+// one and lists it in the overlay, while overlay_all_accounts is on
+func (a *App) refreshOtherAccounts() {
+	var others []ui.AccountUsage
+	if a.config.OverlayAllAccounts {
+		active := a.config.ActiveAccount
+		for _, acct := range slices.Clone(a.config.Accounts) {
+			if acct.Name != active {
+				others = append(others, ui.AccountUsage{Name: acct.Name, Usage: a.accountUsage(acct)})
+			}
+		}
+	}
+
+	a.mu.Lock()
+	shown := a.otherAccountsShown
+	a.otherAccountsShown = others != nil
+	usage := a.lastUsage
+	a.mu.Unlock()
+	if others == nil && !shown {
+		return
+	}
+	fyne.Do(func() {
+		a.overlay.SetOtherAccounts(others)
+		a.overlay.UpdateUsage(usage)
+	})
+}
+
+// accountUsage fetches the usage of an account that isn't active, with a
+// client of its own kept for the next poll. Returns nil when the account
+// never signed in or the fetch fails.
+func (a *App) accountUsage(acct config.Account) *api.UsageData {
+	orgID := accountOrganization(acct.OrganizationID, acct.OrganizationOverride)
+	if acct.SessionKey == "" || orgID == "" {
+		return nil
+	}
+
+	a.mu.Lock()
+	if a.accountClients == nil {
+		a.accountClients = map[string]*api.Client{}
+	}
+	client := a.accountClients[acct.Name]
+	if client == nil {
+		client = api.NewClient(api.ConfigOptions(a.config))
+		a.accountClients[acct.Name] = client
+	}
+	a.mu.Unlock()
+
+	if client.GetSessionKey() != acct.SessionKey || client.GetOrganizationID() != orgID {
+		client.SwitchAccount(acct.SessionKey, orgID)
+	}
+	usage, err := client.FetchUsage()
+	if err != nil {
+		log.Printf("Failed to fetch usage of account %q: %v", acct.Name, err)
+		return nil
+	}
+	return usage
+}
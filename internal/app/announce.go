@@ -0,0 +1,58 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"claudebar/internal/alert"
+	"claudebar/internal/api"
+	"claudebar/internal/notify"
+	"claudebar/internal/platform"
+)
+
+// announce speaks text through the running screen reader. Where the platform
+// can't reach the screen reader directly, a notification stands in, since
+// screen readers read those out, unless one was sent for the event already.
+// Runs platform tools, so call it off the UI thread.
+func (a *App) announce(text string, notified bool) {
+	err := platform.Features.Announce(a.overlay.WindowHandle(), text)
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, platform.ErrNotSupported) {
+		log.Printf("Screen reader announcement failed: %v", err)
+	}
+	if !notified {
+		notify.Send(a.fyneApp, "ClaudeBar", text, alert.None, false)
+	}
+}
+
+// announceResets tells a screen reader user when the session or weekly
+// window rolled over between two snapshots; sighted users see the bars empty
+func (a *App) announceResets(prev, usage *api.UsageData) {
+	a.mu.RLock()
+	reader := a.screenReader
+	a.mu.RUnlock()
+	if !reader || prev == nil {
+		return
+	}
+
+	if rolledOver(prev.FiveHour, usage.FiveHour) {
+		go a.announce(fmt.Sprintf("ClaudeBar: session usage reset, now %.0f%%", usage.FiveHour.Utilization), false)
+	}
+	if rolledOver(prev.SevenDay, usage.SevenDay) {
+		// trackCycle sends the weekly recap notification
+		notified := a.cycles != nil && a.config.NotificationsEnabled
+		go a.announce(fmt.Sprintf("ClaudeBar: weekly usage reset, now %.0f%%", usage.SevenDay.Utilization), notified)
+	}
+}
+
+// rolledOver reports whether a usage window reset between two readings: the
+// reset time moved on and utilization dropped
+func rolledOver(before, after api.UsageStat) bool {
+	return !before.ResetsAt.IsZero() &&
+		after.ResetsAt.Sub(before.ResetsAt) > time.Minute &&
+		after.Utilization < before.Utilization
+}
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+func TestRolledOver(t *testing.T) {
+	reset := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		before, after api.UsageStat
+		want          bool
+	}{
+		{"same window", api.UsageStat{Utilization: 40, ResetsAt: reset}, api.UsageStat{Utilization: 45, ResetsAt: reset}, false},
+		{"reset", api.UsageStat{Utilization: 80, ResetsAt: reset}, api.UsageStat{Utilization: 0, ResetsAt: reset.Add(5 * time.Hour)}, true},
+		{"jitter with drop", api.UsageStat{Utilization: 80, ResetsAt: reset}, api.UsageStat{Utilization: 70, ResetsAt: reset.Add(30 * time.Second)}, false},
+		{"new window without drop", api.UsageStat{Utilization: 0, ResetsAt: reset}, api.UsageStat{Utilization: 5, ResetsAt: reset.Add(5 * time.Hour)}, false},
+		{"no earlier reset time", api.UsageStat{Utilization: 80}, api.UsageStat{Utilization: 0, ResetsAt: reset}, false},
+	}
+	for _, tt := range tests {
+		if got := rolledOver(tt.before, tt.after); got != tt.want {
+			t.Errorf("%s: rolledOver = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
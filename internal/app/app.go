@@ -1,8 +1,10 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,21 +12,55 @@ import (
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/theme"
 
+	"claudebar/internal/alert"
 	"claudebar/internal/api"
 	"claudebar/internal/assets"
+	"claudebar/internal/autostart"
+	"claudebar/internal/budget"
+	"claudebar/internal/claudecode"
 	"claudebar/internal/config"
+	"claudebar/internal/demo"
+	"claudebar/internal/history"
 	"claudebar/internal/hotkeys"
+	"claudebar/internal/notify"
 	"claudebar/internal/platform"
+	"claudebar/internal/redact"
+	"claudebar/internal/sandbox"
+	"claudebar/internal/server"
+	"claudebar/internal/syncer"
+	"claudebar/internal/themes"
 	"claudebar/internal/ui"
+	"claudebar/internal/usagecache"
+	"claudebar/internal/version"
 )
 
+// syncInterval is how often the sync folder is checked for changes
+const syncInterval = time.Minute
+
+// demoInterval is the refresh interval in demo mode, fast enough to watch
+// sessions fill up and reset
+const demoInterval = 3 * time.Second
+
+// footerInterval is how often the overlay's "next in" countdown is updated
+const footerInterval = time.Second
+
+// backdropInterval is how often the desktop behind the overlay is sampled
+// for adaptive text colors
+const backdropInterval = 10 * time.Second
+
 // App is the main application
 type App struct {
-	fyneApp     fyne.App
-	config      *config.Config
-	apiClient   *api.Client
-	authManager *api.AuthManager
-	hotkeyMgr   *hotkeys.Manager
+	fyneApp      fyne.App
+	config       *config.Config
+	apiClient    *api.Client
+	authManager  *api.AuthManager
+	hotkeyMgr    *hotkeys.Manager
+	syncer       *syncer.Syncer
+	themeWatcher *themes.Watcher
+	server       *server.Server
+	serverAddr   string // scheme://host:port the server was started with, "" while stopped
+	usageSource  usageSource
+	demo         bool
 
 	// UI components
 	tray     *ui.TrayManager
@@ -32,22 +68,63 @@ type App struct {
 	settings *ui.SettingsDialog
 
 	// State
-	mu                sync.RWMutex
-	running           bool
-	refreshTimer      *time.Ticker
-	stopChan          chan struct{}
+	mu                   sync.RWMutex
+	running              bool
+	refreshTimer         *time.Ticker
+	pollInterval         time.Duration // current period of refreshTimer
+	nextPoll             time.Time     // when refreshTimer fires next
+	powerCheck           chan struct{}
+	pollCheck            chan struct{}
+	powerSaving          bool // on battery or battery saver; polls at the battery interval
+	screenReader         bool // a screen reader is running; alerts are also spoken
+	stopChan             chan struct{}
 	consecutiveErrors    int
 	rateLimitBackoff     time.Duration
+	lastFetchErr         error
+	lastFetchErrAt       time.Time
+	lastFetchOK          time.Time
+	fetchStats           server.FetchStats // fetches since startup, for /metrics
+	spikes               spikeFilter
+	displayDebounce      *time.Timer
 	lastSessionThreshold float64 // last threshold that triggered a session notification
 	lastWeeklyThreshold  float64 // last threshold that triggered a weekly notification
+	budgetAlerted        bool    // an over-plan notification was sent for the current overrun
+	alertProfile         string  // name of the alert profile last in effect, "" for none
+	lastUsage            *api.UsageData
+	cycles               *history.Tracker // weekly cycle stats, nil in demo mode
+	requests             budget.RequestCost
+	degraded             string // why degraded mode is on (remote session, VM), "" when off
+	reauth               reauthQueue
+	incident             incidentCheck
+	critical             criticalCrossings
+	accountClients       map[string]*api.Client // by account name, for the overlay's other accounts
+	otherAccountsShown   bool
+	claudeCode           *claudecode.Watcher // nil while the Claude Code logs aren't needed; UI thread only
+	dodgeStop            chan struct{}       // stops the dodge loop, nil while dodge_topmost is off; UI thread only
+	interactTimer        *time.Timer         // ends a click-through pause from the interact hotkey; UI thread only
+}
+
+// Options control how the application starts
+type Options struct {
+	Demo bool // feed the UI with generated data instead of the Claude API
+}
+
+// usageSource is where usage snapshots come from (the API client, or demo data)
+type usageSource interface {
+	FetchUsage() (*api.UsageData, error)
 }
 
 // Run starts the application
-func Run() error {
+func Run(opts Options) error {
 	a := &App{
-		stopChan: make(chan struct{}),
+		stopChan:   make(chan struct{}),
+		powerCheck: make(chan struct{}, 1),
+		pollCheck:  make(chan struct{}, 1),
+		demo:       opts.Demo,
 	}
 
+	log.Printf("ClaudeBar %s starting (%s)", version.Version, sandbox.Name())
+
 	// Initialize Fyne app
 	a.fyneApp = app.NewWithID("com.claudebar.app")
 	a.fyneApp.Settings().SetTheme(theme.DarkTheme())
@@ -55,32 +132,109 @@ func Run() error {
 
 	// Load config
 	a.config = config.Get()
+	a.updateRedaction()
+	for i := range a.config.AlertProfiles {
+		if err := a.config.AlertProfiles[i].Validate(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+
+	// Pull settings from other devices before anything reads the config
+	a.syncer = syncer.New(a.config)
+	if a.config.SyncEnabled && !a.demo {
+		if _, err := a.syncer.Pull(); err != nil {
+			log.Printf("Warning: settings sync failed: %v", err)
+		}
+	}
+	if !a.demo {
+		a.syncAutoStart()
+	}
 
 	// Initialize API client
-	a.apiClient = api.NewClient()
+	a.apiClient = api.NewClient(api.ConfigOptions(a.config))
 	a.authManager = api.NewAuthManager(a.apiClient)
+	a.usageSource = a.apiClient
+	if a.demo {
+		log.Println("Demo mode: using generated usage data")
+		a.usageSource = demo.NewSource()
+	} else if dir, err := config.Dir(); err == nil {
+		a.cycles = history.NewTracker(dir)
+	}
 
 	// Initialize hotkey manager
 	a.hotkeyMgr = hotkeys.NewManager()
+	log.Printf("Platform capabilities: %+v", platform.Features.Capabilities())
 
 	// Initialize UI
 	if err := a.initUI(); err != nil {
 		return err
 	}
+	a.checkWhatsNew()
 
 	// Authenticate
-	go a.authenticate()
+	if a.demo {
+		go a.fetchUsage()
+	} else {
+		go a.authenticate()
+	}
 
 	// Start hotkey listener
 	a.hotkeyMgr.SetSnapCallback(a.handleSnapHotkey)
 	a.hotkeyMgr.SetToggleCallback(a.handleToggleHotkey)
+	a.hotkeyMgr.SetPageCallback(a.handlePageHotkey)
+	a.hotkeyMgr.SetNudgeCallback(a.overlay.IsFloating, a.handleNudgeHotkey)
+	a.hotkeyMgr.SetInteractCallback(a.handleInteractHotkey)
+	a.applyDoublePress()
 	if err := a.hotkeyMgr.Start(); err != nil {
 		log.Printf("Warning: Failed to start hotkey listener: %v", err)
 	}
+	a.updateForegroundWatch()
 
 	// Start refresh loop
 	go a.refreshLoop()
 
+	// Watch for UI thread stalls
+	go a.watchdogLoop()
+
+	// Keep overlay text readable over the desktop behind it
+	go a.backdropLoop()
+
+	// Count down to the next poll in the overlay footer
+	go a.footerLoop()
+
+	// Keep the history within its retention, and show recent weeks
+	if a.cycles != nil {
+		go a.compactionLoop()
+		go a.refreshTrends()
+	}
+
+	// Re-place the overlay when monitors or resolution change
+	if err := platform.Features.SetupDisplayListener(a.onDisplayChange); err != nil {
+		log.Printf("Warning: Failed to start display listener: %v", err)
+	}
+
+	// Match the tray icon to the taskbar theme as it changes
+	if err := platform.Features.SetupThemeListener(a.onThemeChange); err != nil {
+		log.Printf("Warning: Failed to start theme listener: %v", err)
+	}
+
+	// Serve usage to launchers and other local tools
+	a.server = server.New(serverBackend{a})
+	a.updateServer()
+
+	// Follow the tokens Claude Code spends on this machine
+	a.applyClaudeCode()
+
+	// Keep the overlay clear of other always-on-top windows
+	a.applyDodge()
+	a.applyClickThrough()
+
+	// Watch for settings pushed by other devices
+	a.syncer.SetAppliedCallback(a.onSyncApplied)
+	if a.config.SyncEnabled && !a.demo {
+		a.syncer.Start(syncInterval)
+	}
+
 	a.running = true
 
 	// Run the app (blocking)
@@ -94,11 +248,17 @@ func Run() error {
 
 // initUI initializes all UI components
 func (a *App) initUI() error {
+	// Load the overlay theme before any widgets are created
+	a.themeWatcher = themes.NewWatcher(a.onThemeFileChange)
+	a.loadTheme()
+	ui.SetAlertLevels(a.config.AlertLevels())
+
 	// Create overlay window
 	a.overlay = ui.NewOverlayWindow(a.fyneApp)
 	if err := a.overlay.Setup(); err != nil {
 		return err
 	}
+	a.overlay.SetRefreshCallback(a.refreshNow)
 
 	// Create tray manager
 	a.tray = ui.NewTrayManager(a.fyneApp)
@@ -109,6 +269,23 @@ func (a *App) initUI() error {
 		a.refreshNow,
 		a.quit,
 	)
+	a.tray.SetResetPositionCallback(a.resetPosition)
+	a.tray.SetClickThroughCallback(a.toggleClickThrough)
+	a.tray.SetDiagnosticsCallback(a.showDiagnostics)
+	a.tray.SetHelpCallbacks(a.showWhatsNew, a.reportIssue)
+	a.tray.SetPauseHotkeysCallback(a.hotkeyMgr.SetSuspended)
+	a.tray.SetAlertProfileCallback(a.setAlertProfile)
+	a.tray.SetPositionCallback(a.snapOverlay)
+	a.tray.SetOpacityCallback(a.overlay.SetOpacity)
+	a.tray.SetOpenClaudeCallback(a.openClaude)
+	if !a.demo {
+		a.tray.SetReloadAccountCallback(a.reloadFromBrowser)
+		a.tray.SetAccountCallback(a.switchAccount)
+	}
+	if a.cycles != nil {
+		a.tray.SetHistoryCallback(a.showHistory)
+	}
+	a.tray.SetLightTheme(platform.Features.SystemUsesLightTheme())
 	if err := a.tray.Setup(); err != nil {
 		log.Printf("Warning: System tray setup failed: %v", err)
 	}
@@ -134,11 +311,13 @@ func (a *App) authenticate() {
 		log.Println("Please set session key in Settings")
 		fyne.Do(func() {
 			a.overlay.SetStatus("Set session key in Settings")
+			a.updateAccountStatus(false)
 		})
 		return
 	}
 
 	log.Println("Authentication successful")
+	a.reauth.reset()
 
 	fyne.Do(func() {
 		a.overlay.SetStatus("Fetching usage...")
@@ -148,31 +327,48 @@ func (a *App) authenticate() {
 	a.fetchUsage()
 }
 
-// refreshLoop periodically fetches usage data with idle detection
+// refreshLoop periodically fetches usage data with idle detection, polling
+// less often while idle or saving battery
 func (a *App) refreshLoop() {
 	const idleThreshold = 300 // 5 minutes in seconds
 	const idleInterval = 300  // poll every 5 min when idle
 
-	normalInterval := time.Duration(a.config.RefreshInterval) * time.Second
-	if normalInterval < 15*time.Second {
-		normalInterval = 15 * time.Second
-	}
-
-	a.refreshTimer = time.NewTicker(normalInterval)
+	a.updatePowerState()
+	interval := a.refreshInterval()
+	a.refreshTimer = time.NewTicker(interval)
 	defer a.refreshTimer.Stop()
+	a.mu.Lock()
+	a.pollInterval = interval
+	a.nextPoll = time.Now().Add(interval)
+	a.mu.Unlock()
+
+	powerTicker := time.NewTicker(powerCheckInterval)
+	defer powerTicker.Stop()
+
+	// Fires when polling should speed up around the next reset; burstUntil
+	// is when the current fast-polling window ends, zero outside one
+	resetTimer := time.NewTimer(0)
+	resetTimer.Stop()
+	defer resetTimer.Stop()
+	var burstUntil time.Time
+	a.scheduleResetWindow(resetTimer, time.Now())
 
 	wasIdle := false
 
 	for {
 		select {
 		case <-a.refreshTimer.C:
+			a.mu.Lock()
+			a.nextPoll = time.Now().Add(a.pollInterval)
+			a.mu.Unlock()
 			idleSec := platform.Features.GetIdleSeconds()
 
 			if idleSec > idleThreshold {
 				// User is idle — slow down
 				if !wasIdle {
 					wasIdle = true
-					a.refreshTimer.Reset(time.Duration(idleInterval) * time.Second)
+					burstUntil = time.Time{}
+					a.setPollInterval(time.Duration(idleInterval) * time.Second)
 					log.Printf("User idle (%ds), reducing refresh rate", idleSec)
 				}
 				a.fetchUsage()
@@ -180,22 +376,165 @@ func (a *App) refreshLoop() {
 				// User is active
 				if wasIdle {
 					wasIdle = false
-					a.refreshTimer.Reset(normalInterval)
+					a.setPollInterval(a.refreshInterval())
 					log.Println("User active, restoring normal refresh rate")
 					a.fetchUsage() // immediate refresh on wake
 				} else {
 					a.fetchUsage()
 				}
 			}
+
+			if !burstUntil.IsZero() && time.Now().After(burstUntil) {
+				burstUntil = time.Time{}
+				a.setPollInterval(a.refreshInterval())
+				log.Println("Reset passed, restoring normal refresh rate")
+			}
+			if burstUntil.IsZero() {
+				// The first fetch or a rollover may have changed the reset times
+				a.scheduleResetWindow(resetTimer, time.Now())
+			}
+			if !wasIdle && burstUntil.IsZero() {
+				// Usage may have come near an alert threshold
+				a.updateLightPolling()
+			}
+		case <-resetTimer.C:
+			a.mu.RLock()
+			_, end, ok := resetWindow(a.lastUsage, time.Now())
+			saving := a.powerSaving || a.degraded != ""
+			a.mu.RUnlock()
+			if !ok {
+				break
+			}
+			if wasIdle || saving {
+				// Not worth waking up for; look again after this reset
+				a.scheduleResetWindow(resetTimer, end)
+				break
+			}
+			burstUntil = end
+			a.setPollInterval(resetPollInterval)
+			log.Println("Reset due, polling faster")
+			a.fetchUsage()
+		case <-powerTicker.C:
+			if a.updatePowerState() && !wasIdle && burstUntil.IsZero() {
+				a.setPollInterval(a.refreshInterval())
+			}
+		case <-a.powerCheck:
+			if a.updatePowerState() && !wasIdle && burstUntil.IsZero() {
+				a.setPollInterval(a.refreshInterval())
+			}
+		case <-a.pollCheck:
+			if !wasIdle && burstUntil.IsZero() && a.updateLightPolling() {
+				a.fetchUsage() // the last fetch may be several intervals old
+			}
+		case <-a.stopChan:
+			return
+		}
+	}
+}
+
+// backdropLoop samples the desktop behind the overlay while adaptive text or
+// software opacity needs it. Sampling pauses while saving battery, and
+// adaptive text is left alone in degraded mode.
+func (a *App) backdropLoop() {
+	ticker := time.NewTicker(backdropInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.mu.RLock()
+			saving := a.powerSaving
+			degraded := a.degraded != ""
+			a.mu.RUnlock()
+			if ((a.config.AdaptiveText && !degraded) || a.overlay.SoftwareOpacity()) && !saving {
+				a.overlay.SampleBackdrop()
+			}
+		case <-a.stopChan:
+			return
+		}
+	}
+}
+
+// footerLoop keeps the overlay footer's "Updated ... · next in ..." line and
+// the countdown line current while the overlay is shown
+func (a *App) footerLoop() {
+	ticker := time.NewTicker(footerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !a.overlay.IsVisible() {
+				continue
+			}
+			updated := a.lastFetchTime()
+			next, interval := a.nextPollTime()
+			fyne.Do(func() {
+				a.overlay.SetSchedule(updated, next, interval)
+			})
 		case <-a.stopChan:
 			return
 		}
 	}
 }
 
+// lastFetchTime returns when usage was last fetched successfully
+func (a *App) lastFetchTime() time.Time {
+	if a.demo {
+		a.mu.RLock()
+		defer a.mu.RUnlock()
+		return a.lastFetchOK
+	}
+	return a.apiClient.GetLastFetchTime()
+}
+
+// nextPollTime returns when the refresh loop polls next and its current
+// interval, zero before it has started
+func (a *App) nextPollTime() (time.Time, time.Duration) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.nextPoll, a.pollInterval
+}
+
+// setPollInterval restarts the refresh ticker with period d
+func (a *App) setPollInterval(d time.Duration) {
+	a.refreshTimer.Reset(d)
+	a.mu.Lock()
+	a.pollInterval = d
+	a.nextPoll = time.Now().Add(d)
+	a.mu.Unlock()
+}
+
+// refreshInterval returns the configured polling interval (at least 15s),
+// stretched to the battery interval while saving battery or degraded, and
+// stretched further by light polling
+func (a *App) refreshInterval() time.Duration {
+	if a.demo {
+		return demoInterval
+	}
+	interval := time.Duration(a.config.RefreshInterval) * time.Second
+	if interval < 15*time.Second {
+		interval = 15 * time.Second
+	}
+	a.mu.RLock()
+	saving := a.powerSaving || a.degraded != ""
+	a.mu.RUnlock()
+	if battery := time.Duration(a.config.BatteryRefreshInterval) * time.Second; saving && interval < battery {
+		interval = battery
+	}
+	if a.lightPolling() {
+		interval *= lightPollingFactor
+	}
+	return interval
+}
+
 // fetchUsage retrieves and updates usage data
 func (a *App) fetchUsage() {
-	if !a.authManager.IsAuthenticated() {
+	if !a.demo && !a.authManager.IsAuthenticated() {
+		return
+	}
+	if !a.demo && a.reauth.waiting(time.Now()) {
+		// Signing in failed recently; the key would only be rejected again
 		return
 	}
 
@@ -211,61 +550,61 @@ func (a *App) fetchUsage() {
 		time.Sleep(backoff)
 	}
 
-	usage, err := a.apiClient.FetchUsage()
+	usage, err := a.usageSource.FetchUsage()
 	if err != nil {
 		log.Printf("Failed to fetch usage: %v", err)
 
 		a.mu.Lock()
 		a.consecutiveErrors++
 		errCount := a.consecutiveErrors
+		a.lastFetchErr = err
+		a.lastFetchErrAt = time.Now()
+		if a.fetchStats.Failures == nil {
+			a.fetchStats.Failures = map[string]int{}
+		}
+		a.fetchStats.Failures[api.ErrorKind(err)]++
 		a.mu.Unlock()
 
-		switch {
-		case err == api.ErrSessionExpired:
-			log.Println("Session key expired, attempting browser refresh...")
-			fyne.Do(func() {
-				a.overlay.SetStatus("Session expired - refreshing...")
-			})
-			if refreshErr := a.authManager.RefreshFromBrowser(); refreshErr != nil {
-				log.Printf("Re-authentication failed: %v", refreshErr)
-				fyne.Do(func() {
-					a.overlay.SetStatus("Session expired - update key in Settings")
-				})
-			}
+		status, suggestion := api.Explain(err)
 
-		case err == api.ErrUnauthorized:
-			log.Println("Unauthorized, attempting browser refresh...")
-			fyne.Do(func() {
-				a.overlay.SetStatus("Auth failed - refreshing...")
-			})
-			if refreshErr := a.authManager.RefreshFromBrowser(); refreshErr != nil {
-				log.Printf("Re-authentication failed: %v", refreshErr)
-				fyne.Do(func() {
-					a.overlay.SetStatus("Auth failed - update key in Settings")
-				})
-			}
+		switch {
+		case errors.Is(err, api.ErrSessionExpired), errors.Is(err, api.ErrUnauthorized):
+			a.reauthenticate(status)
 
-		case err == api.ErrRateLimited:
-			// Exponential backoff: 30s, 60s, 120s, capped at 5 min
-			backoffSec := 30 * (1 << min(errCount-1, 3))
+		case errors.Is(err, api.ErrRateLimited):
+			// Exponential backoff: 30s, 60s, 120s, capped at 4 min
+			backoff := api.RateLimitBackoff(errCount)
+			backoffSec := int(backoff.Seconds())
 			a.mu.Lock()
-			a.rateLimitBackoff = time.Duration(backoffSec) * time.Second
+			a.rateLimitBackoff = backoff
 			a.mu.Unlock()
 			log.Printf("Rate limited, backing off %ds", backoffSec)
+			if a.cycles != nil {
+				a.cycles.CountRateLimit()
+			}
 			fyne.Do(func() {
 				a.overlay.SetStatus(fmt.Sprintf("Rate limited - retry in %ds", backoffSec))
 			})
 
-		case err == api.ErrAPIUnavailable:
+		case errors.Is(err, api.ErrAPIUnavailable), errors.Is(err, api.ErrCloudflare),
+			errors.Is(err, api.ErrTLSHandshake), errors.Is(err, api.ErrDecode):
+			// Not going away on the next poll, so tell the user right away
+			if errors.Is(err, api.ErrAPIUnavailable) && errCount >= incidentAfter {
+				if incident := a.serviceIncident(); incident != "" {
+					status = incident
+				}
+			}
+			log.Printf("%s: %s", status, suggestion)
 			fyne.Do(func() {
-				a.overlay.SetStatus("Claude API unavailable")
+				a.overlay.SetStatus(status)
 			})
 
 		default:
-			// Transient error — show status only after multiple consecutive failures
+			// Transient error (DNS, timeout...) — show status only after multiple consecutive failures
 			if errCount >= 3 {
+				log.Printf("%s: %s", status, suggestion)
 				fyne.Do(func() {
-					a.overlay.SetStatus("Connection error - retrying...")
+					a.overlay.SetStatus(status + " - retrying...")
 				})
 			}
 		}
@@ -276,6 +615,14 @@ func (a *App) fetchUsage() {
 	a.mu.Lock()
 	a.consecutiveErrors = 0
 	a.rateLimitBackoff = 0
+	a.lastFetchErr = nil
+	a.lastFetchOK = time.Now()
+	a.fetchStats.Successes++
+	if a.config.SpikeFilterEnabled {
+		usage = a.spikes.apply(usage, a.config.SpikeThreshold)
+	}
+	prev := a.lastUsage
+	a.lastUsage = usage
 	a.mu.Unlock()
 
 	log.Printf("Usage fetched: 5h=%.0f%%, weekly=%.0f%%",
@@ -283,56 +630,176 @@ func (a *App) fetchUsage() {
 		usage.SevenDay.Utilization,
 	)
 
+	if !a.demo {
+		if err := usagecache.Write(usage, time.Now()); err != nil {
+			log.Printf("Failed to cache usage: %v", err)
+		}
+	}
+
+	a.checkResetDrift(usage)
+	plan := a.budgetPlan(usage)
+	a.requests.Observe(usage.FiveHour, time.Now())
+	requestsLeft, estimated := a.requests.RequestsLeft(usage.FiveHour, a.config.RequestEstimate)
+	if a.config.RequestEstimate == "" || !estimated {
+		requestsLeft = -1
+	}
+
 	// Update UI on the Fyne main thread
 	fyne.Do(func() {
+		a.overlay.SetBudget(plan)
+		a.overlay.SetRequestsLeft(requestsLeft, a.config.RequestEstimate)
+		a.applyCost(usage)
 		a.overlay.UpdateUsage(usage)
 		a.tray.UpdateUsage(usage)
+		a.updateAccountStatus(true)
 	})
 
 	// Check notification thresholds
+	a.announceResets(prev, usage)
 	a.checkAndNotify(usage)
+	a.cueAttention(usage)
+	a.checkBudget(plan)
+	a.trackCycle(usage)
+	a.refreshOtherAccounts()
 }
 
-// checkAndNotify sends OS notifications when usage crosses configured thresholds.
-// Only notifies once per threshold crossing; resets when usage drops below.
-func (a *App) checkAndNotify(usage *api.UsageData) {
-	if !a.config.NotificationsEnabled || len(a.config.AlertThresholds) == 0 {
+// budgetPlan returns the weekly plan for usage, or nil when the planner is off
+func (a *App) budgetPlan(usage *api.UsageData) *budget.Plan {
+	if !a.config.BudgetEnabled {
+		return nil
+	}
+	day, err := budget.ParseDay(a.config.BudgetDay)
+	if err != nil {
+		log.Printf("Budget planner: %v", err)
+		return nil
+	}
+	return budget.New(a.config.BudgetTarget, day, usage.SevenDay, a.limitWindow("weekly"), time.Now())
+}
+
+// checkBudget notifies once when weekly usage runs ahead of the plan, and
+// re-arms after usage falls back under it
+func (a *App) checkBudget(plan *budget.Plan) {
+	const alertMargin = 1 // percentage points over plan before alerting
+
+	if plan == nil || !a.config.NotificationsEnabled {
 		return
 	}
 
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	over := plan.Over()
+	if over >= alertMargin && !a.budgetAlerted {
+		a.budgetAlerted = true
+		notify.Send(a.fyneApp,
+			"ClaudeBar: Ahead of Weekly Plan",
+			fmt.Sprintf("Weekly usage at %.0f%%, plan was %.0f%% by now (%s)", plan.Actual, plan.Planned, plan),
+			alert.Warning, a.config.AlertSound,
+		)
+		log.Printf("Notification: weekly usage %.0f%% is %.0f points over plan", plan.Actual, over)
+	} else if over < 0 {
+		a.budgetAlerted = false
+	}
+}
+
+// checkAndNotify sends OS notifications when usage crosses configured thresholds,
+// those of the active alert profile if there is one, and announces them
+// while a screen reader runs.
+// Only notifies once per threshold crossing; resets when usage drops below.
+func (a *App) checkAndNotify(usage *api.UsageData) {
+	a.logAlertProfile()
+
+	thresholds := a.config.CurrentAlertThresholds()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if (!a.config.NotificationsEnabled && !a.screenReader) || len(thresholds) == 0 {
+		return
+	}
+
 	// Check session (5-hour) usage
-	sessionCrossed := highestCrossedThreshold(usage.FiveHour.Utilization, a.config.AlertThresholds)
+	sessionCrossed := highestCrossedThreshold(usage.FiveHour.Utilization, thresholds)
 	if sessionCrossed > a.lastSessionThreshold {
 		a.lastSessionThreshold = sessionCrossed
-		notif := fyne.NewNotification(
-			"ClaudeBar: High Session Usage",
-			fmt.Sprintf("Session usage at %.0f%% (threshold: %.0f%%)", usage.FiveHour.Utilization, sessionCrossed),
-		)
-		a.fyneApp.SendNotification(notif)
-		log.Printf("Notification: session usage %.0f%% crossed %.0f%% threshold", usage.FiveHour.Utilization, sessionCrossed)
+		a.notifyThreshold("Session", usage.FiveHour.Utilization, sessionCrossed)
 	} else if sessionCrossed < a.lastSessionThreshold {
 		// Usage dropped (e.g., after reset) — allow re-notification
 		a.lastSessionThreshold = sessionCrossed
 	}
 
 	// Check weekly (7-day) usage
-	weeklyCrossed := highestCrossedThreshold(usage.SevenDay.Utilization, a.config.AlertThresholds)
+	weeklyCrossed := highestCrossedThreshold(usage.SevenDay.Utilization, thresholds)
 	if weeklyCrossed > a.lastWeeklyThreshold {
 		a.lastWeeklyThreshold = weeklyCrossed
-		notif := fyne.NewNotification(
-			"ClaudeBar: High Weekly Usage",
-			fmt.Sprintf("Weekly usage at %.0f%% (threshold: %.0f%%)", usage.SevenDay.Utilization, weeklyCrossed),
-		)
-		a.fyneApp.SendNotification(notif)
-		log.Printf("Notification: weekly usage %.0f%% crossed %.0f%% threshold", usage.SevenDay.Utilization, weeklyCrossed)
+		a.notifyThreshold("Weekly", usage.SevenDay.Utilization, weeklyCrossed)
 	} else if weeklyCrossed < a.lastWeeklyThreshold {
 		a.lastWeeklyThreshold = weeklyCrossed
 	}
 }
 
+// setAlertProfile switches to the alert profile picked in the tray ("" for
+// automatic) and re-checks the thresholds against the latest usage
+func (a *App) setAlertProfile(name string) {
+	if err := a.config.SetAlertProfile(name); err != nil {
+		log.Printf("Failed to save alert profile: %v", err)
+	}
+
+	a.mu.RLock()
+	usage := a.lastUsage
+	a.mu.RUnlock()
+	if usage != nil {
+		a.tray.UpdateUsage(usage)
+		go a.checkAndNotify(usage)
+	}
+}
+
+// logAlertProfile logs when a different alert profile takes effect, e.g.
+// when a scheduled one starts
+func (a *App) logAlertProfile() {
+	name := ""
+	if p := a.config.ActiveAlertProfile(time.Now()); p != nil {
+		name = p.Name
+	}
+
+	a.mu.Lock()
+	changed := name != a.alertProfile
+	a.alertProfile = name
+	a.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if name == "" {
+		log.Println("Alert profile: default thresholds")
+	} else {
+		log.Printf("Alert profile: %s", name)
+	}
+}
+
+// notifyThreshold sends the alert for a crossed threshold. The threshold's
+// severity picks the wording, notification urgency and sound. Must be called
+// with a.mu held.
+func (a *App) notifyThreshold(metric string, utilization, threshold float64) {
+	severity := a.config.AlertLevels().Classify(threshold)
+
+	level := "High"
+	if severity == alert.Critical {
+		level = "Critical"
+	}
+	title := fmt.Sprintf("ClaudeBar: %s %s Usage", level, metric)
+	body := fmt.Sprintf("%s usage at %.0f%% (threshold: %.0f%%)", metric, utilization, threshold)
+	if a.config.NotificationsEnabled {
+		notify.Send(a.fyneApp, title, body, severity, a.config.AlertSound)
+	}
+	if a.screenReader {
+		go a.announce(title+". "+body, a.config.NotificationsEnabled)
+	}
+	log.Printf("Notification: %s usage %.0f%% crossed %.0f%% threshold (%s)",
+		strings.ToLower(metric), utilization, threshold, severity)
+	if a.cycles != nil {
+		a.cycles.CountAlert()
+	}
+}
+
 // highestCrossedThreshold returns the highest threshold value that utilization
 // meets or exceeds. Returns 0 if no threshold is crossed.
 func highestCrossedThreshold(utilization float64, thresholds []float64) float64 {
@@ -345,18 +812,110 @@ func highestCrossedThreshold(utilization float64, thresholds []float64) float64
 	return highest
 }
 
+// updateRedaction masks the secrets from the config in logs and diagnostics,
+// and turns redaction off in debug builds when full logging is asked for
+func (a *App) updateRedaction() {
+	redact.SetEnabled(!a.config.DebugLogging)
+	redact.Add(a.config.SessionKey, a.config.OrganizationID, a.config.ServerToken,
+		a.config.DashboardToken, a.config.SyncPassphrase)
+	for _, acct := range a.config.Accounts {
+		redact.Add(acct.SessionKey, acct.OrganizationID, acct.OrganizationOverride)
+	}
+	if !redact.Enabled() {
+		log.Println("Debug logging: secrets are NOT redacted")
+	}
+}
+
+// syncAutoStart reconciles the login registration with the auto_start
+// setting. When on, the current executable is registered again in case it
+// moved; an entry created outside the app (installer, OS settings) while the
+// setting is off is adopted into the setting rather than removed.
+func (a *App) syncAutoStart() {
+	switch {
+	case a.config.AutoStart:
+		if err := autostart.Enable(); err != nil && !errors.Is(err, autostart.ErrManagedByPackage) {
+			log.Printf("Warning: failed to register auto-start: %v", err)
+		}
+	case autostart.IsEnabled():
+		log.Println("Auto-start entry found, turning the setting on")
+		a.config.AutoStart = true
+		if err := a.config.Save(); err != nil {
+			log.Printf("Warning: failed to save config: %v", err)
+		}
+	}
+}
+
+// updateForegroundWatch watches the focused app while hotkeys are to be
+// paused for some apps, and stops watching otherwise
+func (a *App) updateForegroundWatch() {
+	if len(a.config.HotkeyExcludedApps) == 0 {
+		platform.Features.StopForegroundListener()
+		if err := a.hotkeyMgr.SetAppPaused(false); err != nil {
+			log.Printf("Failed to restore hotkeys: %v", err)
+		}
+		return
+	}
+	if err := platform.Features.SetupForegroundListener(a.onForegroundChange); err != nil {
+		log.Printf("Warning: Failed to start foreground listener: %v", err)
+		return
+	}
+	a.onForegroundChange()
+}
+
+// onForegroundChange releases the hotkeys while an excluded app is focused
+func (a *App) onForegroundChange() {
+	process := platform.Features.ForegroundProcess()
+	if err := a.hotkeyMgr.SetAppPaused(hotkeys.Excluded(process, a.config.HotkeyExcludedApps)); err != nil {
+		log.Printf("Failed to pause hotkeys for %s: %v", process, err)
+	}
+}
+
 // handleSnapHotkey handles snap hotkey events.
 // Called from the hotkey goroutine, so must dispatch UI work to the Fyne thread.
 func (a *App) handleSnapHotkey(pos platform.SnapPosition) {
 	log.Printf("Snap hotkey: %s", pos)
 	fyne.Do(func() {
-		a.overlay.SnapTo(pos)
+		a.snapOverlay(pos)
+	})
+}
+
+// snapOverlay snaps the overlay to pos, showing it if hidden
+func (a *App) snapOverlay(pos platform.SnapPosition) {
+	a.overlay.SnapTo(pos)
+	if !a.overlay.IsVisible() {
+		a.showOverlay()
+	}
+	a.tray.Refresh()
+}
 
-		// Show overlay if hidden
-		if !a.overlay.IsVisible() {
-			a.showOverlay()
+// reloadFromBrowser picks up a new session key from the browser cookies, as
+// offered in the tray's Accounts submenu
+func (a *App) reloadFromBrowser() {
+	go func() {
+		if err := a.authManager.RefreshFromBrowser(); err != nil {
+			log.Printf("Reload from browser failed: %v", err)
+			fyne.Do(func() {
+				a.overlay.SetStatus("No session found in browser")
+			})
+			return
 		}
-	})
+		a.reauth.reset()
+		a.pushSync()
+		a.fetchUsage()
+	}()
+}
+
+// updateAccountStatus shows the sign-in state in the tray's Accounts submenu.
+// Must be called on the UI thread.
+func (a *App) updateAccountStatus(signedIn bool) {
+	switch {
+	case a.demo:
+		a.tray.SetAccountStatus("Demo data", true)
+	case signedIn:
+		a.tray.SetAccountStatus("Signed in", true)
+	default:
+		a.tray.SetAccountStatus("Not signed in", false)
+	}
 }
 
 // handleToggleHotkey handles Ctrl+Alt+. — opens the settings/tray app UI for quick access
@@ -367,12 +926,133 @@ func (a *App) handleToggleHotkey() {
 	})
 }
 
+// handlePageHotkey shows the next overlay page
+func (a *App) handlePageHotkey() {
+	fyne.Do(a.overlay.NextPage)
+}
+
+// handleNudgeHotkey moves the floating overlay by dx, dy pixels
+func (a *App) handleNudgeHotkey(dx, dy int) {
+	fyne.Do(func() {
+		a.overlay.Nudge(dx, dy)
+	})
+}
+
+// doublePressAction returns the action a double press runs for its config
+// name, or nil for an unknown one
+func (a *App) doublePressAction(name string) func() {
+	switch name {
+	case "click_through":
+		return a.toggleClickThrough
+	case "overlay":
+		return func() { fyne.Do(a.toggleOverlay) }
+	case "settings":
+		return a.handleToggleHotkey
+	case "next_page":
+		return a.handlePageHotkey
+	case "refresh":
+		return a.refreshNow
+	}
+	return nil
+}
+
+// applyDoublePress sets the double-press hotkey actions from the config
+func (a *App) applyDoublePress() {
+	a.hotkeyMgr.ClearDoublePressActions()
+	a.hotkeyMgr.SetDoublePressInterval(a.config.DoublePressWindow())
+	for hotkey, name := range a.config.DoublePress {
+		id, ok := hotkeys.ID(hotkey)
+		action := a.doublePressAction(name)
+		if !ok || action == nil {
+			log.Printf("Ignoring double press %q: %q (unknown hotkey or action)", hotkey, name)
+			continue
+		}
+		a.hotkeyMgr.SetDoublePressAction(id, action)
+	}
+}
+
+// toggleClickThrough lets clicks pass through the overlay, or makes it take
+// them again, and saves the choice
+func (a *App) toggleClickThrough() {
+	if !platform.Features.Capabilities().ClickThrough {
+		log.Println("Click-through is not supported on this platform")
+		return
+	}
+	fyne.Do(func() {
+		a.config.ClickThrough = !a.config.ClickThrough
+		if err := a.config.Save(); err != nil {
+			log.Printf("Failed to save config: %v", err)
+		}
+		log.Printf("Overlay click-through: %v", a.config.ClickThrough)
+		a.applyClickThrough()
+		a.tray.Refresh()
+	})
+}
+
+// interactFor is how long the interact hotkey lets a click-through overlay
+// take clicks
+const interactFor = 10 * time.Second
+
+// applyClickThrough makes the overlay let clicks through or take them, as
+// click_through says, ending an interaction started with the hotkey. Must be
+// called on the UI thread.
+func (a *App) applyClickThrough() {
+	if a.interactTimer != nil {
+		a.interactTimer.Stop()
+		a.interactTimer = nil
+	}
+	if !platform.Features.Capabilities().ClickThrough || a.overlay.ClickThrough() == a.config.ClickThrough {
+		return
+	}
+	if err := a.overlay.SetClickThrough(a.config.ClickThrough); err != nil {
+		log.Printf("Failed to set click-through: %v", err)
+	}
+}
+
+// handleInteractHotkey lets a click-through overlay take clicks for
+// interactFor, or until the hotkey is pressed again
+func (a *App) handleInteractHotkey() {
+	fyne.Do(func() {
+		if !a.config.ClickThrough || !platform.Features.Capabilities().ClickThrough {
+			return
+		}
+		if a.interactTimer != nil {
+			a.applyClickThrough()
+			return
+		}
+		if err := a.overlay.SetClickThrough(false); err != nil {
+			log.Printf("Failed to turn click-through off: %v", err)
+			return
+		}
+		var timer *time.Timer
+		timer = time.AfterFunc(interactFor, func() {
+			fyne.Do(func() {
+				if a.interactTimer == timer {
+					a.applyClickThrough()
+				}
+			})
+		})
+		a.interactTimer = timer
+	})
+}
+
+// toggleOverlay shows the overlay if hidden and hides it otherwise. Must be
+// called on the UI thread.
+func (a *App) toggleOverlay() {
+	if a.overlay.IsVisible() {
+		a.hideOverlay()
+	} else {
+		a.showOverlay()
+	}
+}
+
 // showOverlay shows the overlay window
 func (a *App) showOverlay() {
 	a.overlay.Show()
 	a.config.OverlayEnabled = true
 	a.config.Save()
 	a.tray.SetOverlayState(true)
+	a.recheckLightPolling()
 }
 
 // hideOverlay hides the overlay window
@@ -381,6 +1061,18 @@ func (a *App) hideOverlay() {
 	a.config.OverlayEnabled = false
 	a.config.Save()
 	a.tray.SetOverlayState(false)
+	a.recheckLightPolling()
+}
+
+// resetPosition moves the overlay back to its default position
+func (a *App) resetPosition() {
+	log.Println("Resetting overlay position")
+	a.overlay.ResetPosition()
+	a.tray.SetOverlayState(true)
+	if !a.config.OverlayEnabled {
+		a.config.OverlayEnabled = true
+		a.config.Save()
+	}
 }
 
 // showSettings shows the settings dialog
@@ -388,23 +1080,237 @@ func (a *App) showSettings() {
 	if a.settings == nil {
 		a.settings = ui.NewSettingsDialog(a.fyneApp, a.overlay.GetWindow())
 		a.settings.SetCallbacks(
-			a.authManager.SetManualSessionKey,
-			a.authManager.RefreshFromBrowser,
+			func(key string) error {
+				if err := a.authManager.SetManualSessionKey(key); err != nil {
+					return err
+				}
+				a.reauth.reset()
+				a.pushSync()
+				return nil
+			},
+			func() error {
+				if err := a.authManager.RefreshFromBrowser(); err != nil {
+					return err
+				}
+				a.reauth.reset()
+				a.pushSync()
+				return nil
+			},
 			func() {
 				// Refresh UI after settings save
 				a.fetchUsage()
-				// Update refresh interval
+				// Update refresh interval and battery/motion overrides
 				if a.refreshTimer != nil {
-					a.refreshTimer.Reset(time.Duration(a.config.RefreshInterval) * time.Second)
+					a.setPollInterval(a.refreshInterval())
+				}
+				a.recheckPower()
+				go a.updateForegroundWatch()
+				a.applyDoublePress()
+				a.updateServer()
+				a.updateRedaction()
+				a.applyClaudeCode()
+				a.applyDodge()
+				a.applyClickThrough()
+				// Update opacity, text colors, alert levels and theme
+				a.overlay.ApplyOpacity()
+				if !a.config.AdaptiveText {
+					a.overlay.ResetBackdrop()
+				}
+				ui.SetAlertLevels(a.config.AlertLevels())
+				a.applyTheme()
+				a.tray.Refresh()
+				// Start/stop sync and share the new settings
+				if a.config.SyncEnabled {
+					a.syncer.Start(syncInterval)
+					a.pushSync()
+				} else {
+					a.syncer.Stop()
 				}
-				// Update opacity
-				a.overlay.SetOpacity(a.config.OverlayOpacity)
 			},
 		)
+		a.settings.SetImportCallback(a.onSettingsImported)
+		if a.cycles != nil {
+			a.settings.SetHistoryRestorer(a.cycles)
+		}
+		a.settings.SetResetCallback(a.resetEverything)
+		a.settings.SetAccountCallbacks(a.switchAccount, func() {
+			a.tray.Refresh()
+			a.pushSync()
+		})
+		a.settings.SetOrganizationCallback(func(id string) error {
+			if err := a.authManager.SetOrganizationOverride(id); err != nil {
+				return err
+			}
+			a.reauth.reset()
+			a.pushSync()
+			a.fetchUsage()
+			return nil
+		})
 	}
 	a.settings.Show()
 }
 
+// onSettingsImported re-applies everything derived from the config after a
+// settings bundle was restored
+func (a *App) onSettingsImported() {
+	log.Println("Settings imported, re-authenticating")
+	a.mu.Lock()
+	a.spikes.reset()
+	a.mu.Unlock()
+	a.reapplyConfig()
+	if a.cycles != nil {
+		go a.refreshTrends()
+	}
+	go a.authenticate()
+}
+
+// reapplyConfig re-applies everything derived from the config after it was
+// replaced by an import or a sync. Must run on the Fyne thread.
+func (a *App) reapplyConfig() {
+	a.apiClient.SetHeaders(a.config.HTTPHeaders, a.config.DisableClientHints)
+	a.overlay.ApplyOpacity()
+	ui.SetAlertLevels(a.config.AlertLevels())
+	a.applyTheme()
+	a.tray.Refresh()
+	if a.refreshTimer != nil {
+		a.setPollInterval(a.refreshInterval())
+	}
+	a.recheckPower()
+	go a.updateForegroundWatch()
+	a.applyDoublePress()
+	a.updateServer()
+	a.updateRedaction()
+	a.applyClaudeCode()
+	a.applyDodge()
+	a.applyClickThrough()
+}
+
+// pushSync writes the current settings to the sync folder in the background
+func (a *App) pushSync() {
+	if !a.config.SyncEnabled {
+		return
+	}
+	go func() {
+		if err := a.syncer.Push(); err != nil {
+			log.Printf("Settings sync push failed: %v", err)
+		}
+	}()
+}
+
+// onSyncApplied is called (from the syncer goroutine) after settings from
+// another device were applied. Picks up a changed session key and re-applies
+// the rest like an import.
+func (a *App) onSyncApplied() {
+	if a.config.SessionKey != "" && a.config.SessionKey != a.apiClient.GetSessionKey() {
+		log.Println("Sync: using session key from another device")
+		a.mu.Lock()
+		a.spikes.reset()
+		a.mu.Unlock()
+		a.apiClient.SetSessionKey(a.config.SessionKey)
+		if a.config.OrganizationID != "" {
+			a.apiClient.SetOrganizationID(a.config.OrganizationID)
+		}
+	}
+	if id := a.config.OrganizationOverride; id != "" && id != a.apiClient.GetOrganizationID() {
+		log.Println("Sync: using organization override from another device")
+		a.apiClient.SetOrganizationID(id)
+	}
+	fyne.Do(a.reapplyConfig)
+	a.fetchUsage()
+}
+
+// onDisplayChange is called (from a platform goroutine) when the monitor
+// layout changes. Changes arrive in bursts while a display settles, so the
+// overlay is only recreated once things have been quiet for a moment, a
+// longer one in degraded mode where remote sessions resize on every reconnect.
+func (a *App) onDisplayChange() {
+	const settleDelay = 2 * time.Second
+	const degradedSettleDelay = 10 * time.Second
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.displayDebounce != nil {
+		a.displayDebounce.Stop()
+	}
+	delay := settleDelay
+	if a.degraded != "" {
+		delay = degradedSettleDelay
+	}
+	a.displayDebounce = time.AfterFunc(delay, func() {
+		log.Println("Display configuration changed, re-placing overlay")
+		fyne.Do(a.recoverOverlay)
+	})
+}
+
+// onThemeChange swaps the tray icon when the OS switches light/dark taskbar
+func (a *App) onThemeChange() {
+	light := platform.Features.SystemUsesLightTheme()
+	log.Printf("Taskbar theme changed (light=%v), updating tray icon", light)
+	fyne.Do(func() {
+		a.tray.SetLightTheme(light)
+	})
+}
+
+// loadTheme starts watching the configured theme file and makes it the
+// active widget theme. A missing or broken file falls back to the built-in one.
+func (a *App) loadTheme() {
+	t, err := a.themeWatcher.Watch(a.config.Theme)
+	if err != nil {
+		log.Printf("Warning: failed to load theme %q: %v", a.config.Theme, err)
+		t = themes.Default()
+	}
+	ui.SetTheme(a.withMetricColors(t))
+}
+
+// withMetricColors applies the metric_colors config on top of a theme
+func (a *App) withMetricColors(t *themes.Theme) *themes.Theme {
+	t, err := t.WithMetricColors(a.config.MetricColors)
+	if err != nil {
+		log.Printf("Warning: ignoring metric_colors entries: %v", err)
+	}
+	return t
+}
+
+// applyTheme reloads the configured theme and restyles the overlay. Must run
+// on the Fyne thread.
+func (a *App) applyTheme() {
+	a.loadTheme()
+	a.overlay.Restyle()
+}
+
+// onThemeFileChange is called (from the theme watcher) after the active theme
+// file was edited
+func (a *App) onThemeFileChange(t *themes.Theme) {
+	fyne.Do(func() {
+		ui.SetTheme(a.withMetricColors(t))
+		a.overlay.Restyle()
+	})
+}
+
+// showDiagnostics opens a window describing the last fetch result
+func (a *App) showDiagnostics() {
+	ui.ShowDiagnostics(a.fyneApp, a.diagnostics())
+}
+
+// diagnostics describes the last fetch result
+func (a *App) diagnostics() ui.Diagnostics {
+	a.mu.RLock()
+	d := ui.Diagnostics{
+		LastSuccess:       a.lastFetchOK,
+		LastErrorAt:       a.lastFetchErrAt,
+		LastError:         a.lastFetchErr,
+		ConsecutiveErrors: a.consecutiveErrors,
+		Degraded:          a.degraded,
+		PollInterval:      a.pollInterval,
+	}
+	a.mu.RUnlock()
+
+	if d.LastError != nil {
+		d.Status, d.Suggestion = api.Explain(d.LastError)
+	}
+	return d
+}
+
 // refreshNow triggers an immediate usage refresh
 func (a *App) refreshNow() {
 	go a.fetchUsage()
@@ -418,13 +1324,15 @@ func (a *App) quit() {
 
 // shutdown cleans up resources
 func (a *App) shutdown() {
+	// Only the flag is guarded: server handlers and fetches still in flight
+	// take a.mu and must finish for their components to stop
 	a.mu.Lock()
-	defer a.mu.Unlock()
-
-	if !a.running {
+	running := a.running
+	a.running = false
+	a.mu.Unlock()
+	if !running {
 		return
 	}
-	a.running = false
 
 	log.Println("Shutting down...")
 
@@ -433,6 +1341,27 @@ func (a *App) shutdown() {
 
 	// Stop hotkey listener
 	a.hotkeyMgr.Stop()
+	platform.Features.StopForegroundListener()
+
+	// Stop watching the sync folder
+	a.syncer.Stop()
+
+	// Stop display change listener
+	platform.Features.StopDisplayListener()
+
+	// Stop theme change listener
+	platform.Features.StopThemeListener()
+
+	// Stop watching the theme file
+	a.themeWatcher.Stop()
+
+	// Stop the local server
+	a.server.Stop()
+
+	// Stop reading the Claude Code logs
+	if a.claudeCode != nil {
+		a.claudeCode.Stop()
+	}
 
 	log.Println("Shutdown complete")
 }
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"errors"
+	"log"
+
+	"fyne.io/fyne/v2"
+
+	"claudebar/internal/alert"
+	"claudebar/internal/api"
+	"claudebar/internal/platform"
+)
+
+// criticalCrossings remembers whether session and weekly usage were at the
+// critical level, so the attention cue plays once per crossing
+type criticalCrossings struct {
+	session, weekly bool
+}
+
+// update records usage and reports whether session or weekly usage has just
+// reached the critical level
+func (c *criticalCrossings) update(usage *api.UsageData, levels alert.Levels) bool {
+	session := levels.Classify(usage.FiveHour.Utilization) == alert.Critical
+	weekly := levels.Classify(usage.SevenDay.Utilization) == alert.Critical
+	crossed := (session && !c.session) || (weekly && !c.weekly)
+	c.session, c.weekly = session, weekly
+	return crossed
+}
+
+// cueAttention flashes the overlay border and the taskbar button, as
+// configured, when usage has just reached the critical level. Unlike
+// notifications, the cues don't depend on notifications being enabled.
+func (a *App) cueAttention(usage *api.UsageData) {
+	a.mu.Lock()
+	crossed := a.critical.update(usage, a.config.AlertLevels())
+	a.mu.Unlock()
+	if !crossed || (!a.config.FlashBorder && !a.config.FlashTaskbar) {
+		return
+	}
+
+	fyne.Do(func() {
+		if a.config.FlashBorder {
+			a.overlay.FlashBorder()
+		}
+		if a.config.FlashTaskbar {
+			err := platform.Features.FlashTaskbar(a.overlay.WindowHandle())
+			if err != nil && !errors.Is(err, platform.ErrNotSupported) {
+				log.Printf("Taskbar flash failed: %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"testing"
+
+	"claudebar/internal/alert"
+	"claudebar/internal/api"
+)
+
+func TestCriticalCrossings(t *testing.T) {
+	levels := alert.Levels{Warning: 75, Critical: 90}
+	usage := func(session, weekly float64) *api.UsageData {
+		return &api.UsageData{
+			FiveHour: api.UsageStat{Utilization: session},
+			SevenDay: api.UsageStat{Utilization: weekly},
+		}
+	}
+
+	var c criticalCrossings
+	steps := []struct {
+		session, weekly float64
+		want            bool
+	}{
+		{50, 50, false},
+		{80, 50, false}, // warning only
+		{92, 50, true},
+		{95, 50, false}, // still critical
+		{95, 91, true},  // weekly joins
+		{10, 91, false}, // session reset
+		{90, 91, true},
+	}
+	for i, s := range steps {
+		if got := c.update(usage(s.session, s.weekly), levels); got != s.want {
+			t.Errorf("step %d (%v/%v): crossed = %v, want %v", i, s.session, s.weekly, got, s.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"claudebar/internal/api"
+	"claudebar/internal/budget"
+	"claudebar/internal/ui"
+)
+
+// claudeURL is opened by "Open claude.ai" in the tray
+const claudeURL = "https://claude.ai"
+
+// budgetLockMargin is how many percentage points weekly usage must be over
+// plan before opening claude.ai asks first, as for the over-plan notification
+const budgetLockMargin = 1
+
+// openClaude opens claude.ai in the browser. With budget_lock on and weekly
+// usage over plan, it first shows the quota left and asks to confirm.
+func (a *App) openClaude() {
+	a.mu.RLock()
+	usage := a.lastUsage
+	a.mu.RUnlock()
+
+	if a.config.BudgetLock && usage != nil {
+		if plan := a.budgetPlan(usage); plan != nil && plan.Over() >= budgetLockMargin {
+			log.Printf("Budget lock: weekly usage %.0f%% is %.0f points over plan", plan.Actual, plan.Over())
+			fyne.Do(func() {
+				ui.ShowBudgetLock(a.fyneApp, budgetLockText(plan, usage), a.openClaudeNow)
+			})
+			return
+		}
+	}
+	a.openClaudeNow()
+}
+
+// openClaudeNow opens claude.ai without asking
+func (a *App) openClaudeNow() {
+	u, _ := url.Parse(claudeURL)
+	if err := a.fyneApp.OpenURL(u); err != nil {
+		log.Printf("Failed to open %s: %v", claudeURL, err)
+	}
+}
+
+// budgetLockText explains the budget lock prompt, e.g. "Weekly usage is at
+// 62%, 8% over your plan of 80% by Fri. 38% of the weekly limit is left
+// until it resets in 2d 4h, and 58% of the session limit for 1h 12m."
+func budgetLockText(plan *budget.Plan, usage *api.UsageData) string {
+	return fmt.Sprintf("Weekly usage is at %.0f%%, %.0f%% over your plan of %.0f%% by %s. "+
+		"%.0f%% of the weekly limit is left until it resets in %s, and %.0f%% of the session limit for %s.",
+		plan.Actual, plan.Over(), plan.Target, plan.Deadline.Add(-time.Minute).Format("Mon"),
+		max(100-usage.SevenDay.Utilization, 0), api.TimeUntilReset(usage.SevenDay.ResetsAt),
+		max(100-usage.FiveHour.Utilization, 0), api.TimeUntilReset(usage.FiveHour.ResetsAt))
+}
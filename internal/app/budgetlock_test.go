@@ -0,0 +1,30 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"claudebar/internal/api"
+	"claudebar/internal/budget"
+)
+
+func TestBudgetLockText(t *testing.T) {
+	now := time.Now()
+	usage := &api.UsageData{
+		FiveHour: api.UsageStat{Utilization: 42, ResetsAt: now.Add(90 * time.Minute)},
+		SevenDay: api.UsageStat{Utilization: 62, ResetsAt: now.Add(52 * time.Hour)},
+	}
+	plan := &budget.Plan{Target: 80, Deadline: now.Add(48 * time.Hour), Planned: 54, Actual: 62}
+
+	text := budgetLockText(plan, usage)
+	for _, want := range []string{
+		"Weekly usage is at 62%, 8% over your plan of 80% by " + plan.Deadline.Add(-time.Minute).Format("Mon"),
+		"38% of the weekly limit is left until it resets in 2d 3h",
+		"58% of the session limit for 1h 29m",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text lacks %q:\n%s", want, text)
+		}
+	}
+}
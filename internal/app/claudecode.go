@@ -0,0 +1,66 @@
+package app
+
+import (
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"claudebar/internal/budget"
+	"claudebar/internal/claudecode"
+)
+
+// applyClaudeCode starts or stops reading the Claude Code logs to follow
+// claude_code_enabled, claude_code_dir and a cost estimate from the logs.
+// Call on the UI thread.
+func (a *App) applyClaudeCode() {
+	dir := a.config.ClaudeCodeDir
+	if dir == "" {
+		var err error
+		if dir, err = claudecode.DefaultDir(); err != nil {
+			log.Printf("Claude Code: %v", err)
+		}
+	}
+	enabled := (a.config.ClaudeCodeEnabled || a.config.CostEstimate == budget.CostLogs) && dir != ""
+
+	if w := a.claudeCode; w != nil && (!enabled || w.Tracker().Dir() != dir) {
+		w.Stop()
+		a.claudeCode = nil
+	}
+	if enabled && a.claudeCode == nil {
+		log.Printf("Claude Code: reading logs in %s", dir)
+		var w *claudecode.Watcher
+		w = claudecode.NewWatcher(claudecode.NewTracker(dir), func() {
+			fyne.Do(func() {
+				if a.claudeCode == w { // else turned off meanwhile
+					a.updateClaudeCode()
+				}
+			})
+		})
+		a.claudeCode = w
+		w.Start()
+		return // shown once the first scan is done
+	}
+	a.updateClaudeCode()
+}
+
+// updateClaudeCode shows the Claude Code usage read so far and the cost
+// estimate based on it. Call on the UI thread.
+func (a *App) updateClaudeCode() {
+	var summary *claudecode.Summary
+	if a.claudeCode != nil && a.config.ClaudeCodeEnabled {
+		summary = a.claudeCode.Tracker().Summary(time.Now(), a.prices())
+	}
+	a.overlay.SetClaudeCode(summary)
+	a.mu.RLock()
+	usage := a.lastUsage
+	a.mu.RUnlock()
+	a.applyCost(usage)
+	a.overlay.UpdateUsage(usage)
+}
+
+// prices returns the model prices, the list prices with the model_prices
+// overrides
+func (a *App) prices() claudecode.PriceFunc {
+	return claudecode.Prices(a.config.ModelPrices)
+}
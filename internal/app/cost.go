@@ -0,0 +1,39 @@
+package app
+
+import (
+	"time"
+
+	"claudebar/internal/api"
+	"claudebar/internal/budget"
+)
+
+// costEstimate returns the estimated value of the session and week per
+// cost_estimate, or nil when it is off or has nothing to go on yet. Call on
+// the UI thread.
+func (a *App) costEstimate(usage *api.UsageData) *budget.Cost {
+	var c budget.Cost
+	switch a.config.CostEstimate {
+	case budget.CostLogs:
+		if a.claudeCode == nil {
+			return nil
+		}
+		c = budget.CostFromLogs(a.claudeCode.Tracker(), usage,
+			a.limitWindow("session"), a.limitWindow("weekly"), a.prices(), time.Now())
+	case budget.CostUtilization:
+		if usage == nil || a.config.CostSession <= 0 || a.config.CostWeekly <= 0 {
+			return nil
+		}
+		c = budget.CostFromUtilization(usage, a.config.CostSession, a.config.CostWeekly)
+	default:
+		return nil
+	}
+	return &c
+}
+
+// applyCost shows the cost estimate for usage in the overlay and tray menu.
+// The overlay picks it up with its next UpdateUsage. Call on the UI thread.
+func (a *App) applyCost(usage *api.UsageData) {
+	c := a.costEstimate(usage)
+	a.overlay.SetCost(c)
+	a.tray.SetCost(c)
+}
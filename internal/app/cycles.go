@@ -0,0 +1,222 @@
+package app
+
+import (
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"claudebar/internal/alert"
+	"claudebar/internal/api"
+	"claudebar/internal/history"
+	"claudebar/internal/notify"
+	"claudebar/internal/ui"
+)
+
+// cycleNotifyWindow is how recently a weekly cycle must have ended for its
+// summary to be announced; cycles that ended while the app was closed for
+// longer are only written to the history
+const cycleNotifyWindow = 24 * time.Hour
+
+// compactInterval is how often the history is trimmed to its retention
+const compactInterval = 24 * time.Hour
+
+// compactionLoop trims the history to the configured retention at startup
+// and then daily
+func (a *App) compactionLoop() {
+	ticker := time.NewTicker(compactInterval)
+	defer ticker.Stop()
+
+	for {
+		retention := time.Duration(a.config.HistoryRetentionDays) * 24 * time.Hour
+		sampleRetention := time.Duration(a.config.SampleRetentionDays) * 24 * time.Hour
+		if removed, err := a.cycles.Compact(time.Now(), retention, sampleRetention); err != nil {
+			log.Printf("History: compaction failed: %v", err)
+		} else if removed > 0 {
+			log.Printf("History: removed %d cycles older than %d days", removed, a.config.HistoryRetentionDays)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-a.stopChan:
+			return
+		}
+	}
+}
+
+// trackCycle records the fetch in the usage history, redraws the overlay's
+// trend lines, feeds the weekly reading to the cycle tracker and announces the summary of a cycle that just
+// ended
+func (a *App) trackCycle(usage *api.UsageData) {
+	if a.cycles == nil {
+		return
+	}
+	now := time.Now()
+	a.cycles.Record(now, usage)
+	a.refreshSparklines(now)
+	done := a.cycles.Observe(usage.SevenDay)
+	a.sendMonthlySummary()
+	a.sendDailySummary(now)
+	if done == nil {
+		return
+	}
+	a.refreshTrends()
+	log.Printf("Weekly cycle ended %s: %s", done.End.Local().Format("Mon 15:04"), done)
+
+	if !a.config.NotificationsEnabled || time.Since(done.End) > cycleNotifyWindow {
+		return
+	}
+	notify.Send(a.fyneApp, "ClaudeBar: Weekly Usage Reset",
+		"Last week: "+done.String(), alert.None, false)
+}
+
+// checkResetDrift records the reset times of usage and tells the user when
+// a limit window changed length. The budget plan and the weekly cycles use
+// the new length from then on.
+func (a *App) checkResetDrift(usage *api.UsageData) {
+	if a.cycles == nil {
+		return
+	}
+	for _, drift := range a.cycles.ObserveResets(time.Now(), usage) {
+		log.Printf("Limit window changed: %s", drift)
+		if a.config.NotificationsEnabled {
+			notify.Send(a.fyneApp, "ClaudeBar: Limit Window Changed",
+				"The "+drift.String()+". Plans and estimates now use the new length.", alert.None, false)
+		}
+	}
+}
+
+// limitWindow returns the length of the named metric's window as calibrated
+// from its reset history
+func (a *App) limitWindow(metric string) time.Duration {
+	if a.cycles == nil {
+		return history.DefaultWindows[metric]
+	}
+	return a.cycles.Window(metric)
+}
+
+// Resolution of the overlay's trend lines: 60 points over five hours, 84
+// over a week
+const (
+	sessionSparkStep = 5 * time.Minute
+	weeklySparkStep  = 2 * time.Hour
+)
+
+// refreshSparklines loads the overlay's trend lines, ending at now, from the
+// usage samples
+func (a *App) refreshSparklines(now time.Time) {
+	if !a.config.IsStatVisible("sparklines") {
+		return
+	}
+	samples, err := a.cycles.Samples()
+	if err != nil {
+		return // already logged when recording
+	}
+	// Samples are stored to the second, so the range ends a second later to
+	// include the one just taken
+	to := now.Add(time.Second)
+	session, err := samples.Downsample(now.Add(-ui.SessionSparkSpan), to, sessionSparkStep)
+	if err != nil {
+		log.Printf("History: failed to read samples: %v", err)
+		return
+	}
+	weekly, err := samples.Downsample(now.Add(-ui.WeeklySparkSpan), to, weeklySparkStep)
+	if err != nil {
+		log.Printf("History: failed to read samples: %v", err)
+		return
+	}
+	sessionPoints := sparkPoints(session, func(s history.Sample) float64 { return s.Session })
+	weeklyPoints := sparkPoints(weekly, func(s history.Sample) float64 { return s.Weekly })
+	fyne.Do(func() {
+		a.overlay.SetSparklines(sessionPoints, weeklyPoints, now)
+	})
+}
+
+// sparkPoints takes the utilization value picks from each sample
+func sparkPoints(samples []history.Sample, value func(history.Sample) float64) []ui.SparkPoint {
+	points := make([]ui.SparkPoint, len(samples))
+	for i, s := range samples {
+		points[i] = ui.SparkPoint{At: s.Time, Value: value(s)}
+	}
+	return points
+}
+
+// showHistory opens the usage history window, with the streaks if enabled
+func (a *App) showHistory() {
+	var stats *history.Stats
+	if a.config.StatsEnabled {
+		cycles, err := a.cycles.Cycles()
+		if err != nil {
+			log.Printf("Failed to read history: %v", err)
+		}
+		s := history.ComputeStats(cycles)
+		stats = &s
+	}
+	ui.ShowHistory(a.fyneApp, a.cycles.Heatmap(), stats, a.cycles)
+}
+
+// sendMonthlySummary notifies the stats of the month that just ended, once
+// per month when stats are enabled
+func (a *App) sendMonthlySummary() {
+	if !a.config.StatsEnabled || !a.config.NotificationsEnabled {
+		return
+	}
+	month, due := a.cycles.SummaryDue(time.Now())
+	if !due {
+		return
+	}
+	cycles, err := a.cycles.Cycles()
+	if err != nil {
+		log.Printf("Failed to read history: %v", err)
+		return
+	}
+	summary, ok := history.MonthSummary(cycles, month)
+	if !ok {
+		return
+	}
+	notify.Send(a.fyneApp, "ClaudeBar: "+month.Format("January")+" in Review",
+		summary, alert.None, false)
+}
+
+// sendDailySummary notifies how much of the weekly limit was used today and
+// how much is left, once a day from the configured summary time
+func (a *App) sendDailySummary(now time.Time) {
+	if a.config.DailySummaryTime == "" || !a.config.NotificationsEnabled {
+		return
+	}
+	hour, minute, err := history.ParseClock(a.config.DailySummaryTime)
+	if err != nil {
+		return // rejected by Settings; a hand-edited value just sends nothing
+	}
+	day, due := a.cycles.DailySummaryDue(now, hour, minute)
+	if !due {
+		return
+	}
+	samples, err := a.cycles.Samples()
+	if err != nil {
+		return // already logged when recording
+	}
+	today, err := samples.Range(day, now.Add(time.Second))
+	if err != nil {
+		log.Printf("History: failed to read samples: %v", err)
+		return
+	}
+	summary, ok := history.SummarizeDay(today)
+	if !ok {
+		return
+	}
+	notify.Send(a.fyneApp, "ClaudeBar: Today's Usage", summary.Text(now), alert.None, false)
+}
+
+// refreshTrends shows the latest weeks of the history on the overlay's
+// trends page
+func (a *App) refreshTrends() {
+	cycles, err := a.cycles.Cycles()
+	if err != nil {
+		log.Printf("Failed to read history: %v", err)
+		return
+	}
+	fyne.Do(func() {
+		a.overlay.SetTrends(cycles)
+	})
+}
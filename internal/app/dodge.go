@@ -0,0 +1,68 @@
+package app
+
+import (
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"claudebar/internal/platform"
+)
+
+// dodgeInterval is how often other apps' always-on-top windows are checked
+// for covering the overlay
+const dodgeInterval = 3 * time.Second
+
+// applyDodge starts or stops moving the overlay out of the way of other
+// always-on-top windows, following dodge_topmost. Call on the UI thread.
+func (a *App) applyDodge() {
+	enabled := a.config.DodgeTopmost && platform.Features.Capabilities().WindowList
+	if !enabled {
+		if a.dodgeStop != nil {
+			close(a.dodgeStop)
+			a.dodgeStop = nil
+			a.overlay.Dodge(nil) // back to its own position
+		}
+		return
+	}
+	if a.dodgeStop == nil {
+		a.dodgeStop = make(chan struct{})
+		go a.dodgeLoop(a.dodgeStop)
+	}
+}
+
+// dodgeLoop lists the other always-on-top windows every dodgeInterval while
+// the overlay is shown, and lets the overlay dodge them, until stop or the
+// app's stopChan is closed
+func (a *App) dodgeLoop(stop chan struct{}) {
+	ticker := time.NewTicker(dodgeInterval)
+	defer ticker.Stop()
+
+	failed := false // the last listing failed, so the next failure isn't logged again
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		case <-a.stopChan:
+			return
+		}
+		if !a.overlay.IsVisible() {
+			continue
+		}
+		others, err := platform.Features.TopmostWindows()
+		if err != nil {
+			if !failed {
+				log.Printf("Failed to list always-on-top windows: %v", err)
+			}
+			failed = true
+			continue
+		}
+		failed = false
+		fyne.Do(func() {
+			if a.dodgeStop == stop { // else turned off meanwhile
+				a.overlay.Dodge(others)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"fmt"
+	"log"
+
+	"claudebar/internal/platform"
+	"claudebar/internal/report"
+	"claudebar/internal/ui"
+	"claudebar/internal/version"
+)
+
+// checkWhatsNew shows the release notes since the last version run, once
+// after an update. Fresh installs only record the version.
+func (a *App) checkWhatsNew() {
+	last := a.config.LastSeenVersion
+	if a.demo || last == version.Version {
+		return
+	}
+	a.config.LastSeenVersion = version.Version
+	if err := a.config.Save(); err != nil {
+		log.Printf("Warning: failed to save config: %v", err)
+	}
+
+	// Versions before this was tracked left it empty, but had signed in
+	if last == "" && a.config.SessionKey == "" {
+		return
+	}
+	log.Printf("Updated from %s to %s", last, version.Version)
+	if notes := version.Notes(last); notes != "" {
+		ui.ShowWhatsNew(a.fyneApp, notes)
+	}
+}
+
+// showWhatsNew opens the whole changelog, as offered in the tray's Help menu
+func (a *App) showWhatsNew() {
+	ui.ShowWhatsNew(a.fyneApp, version.Notes(""))
+}
+
+// reportIssue opens a problem report with the version, OS, platform probes,
+// diagnostics and recent log, to file as a GitHub issue or save
+func (a *App) reportIssue() {
+	r := report.New()
+	a.mu.RLock()
+	degraded, saving, reader := a.degraded, a.powerSaving, a.screenReader
+	a.mu.RUnlock()
+	if degraded == "" {
+		degraded = "off"
+	}
+	r.Probes = []string{
+		fmt.Sprintf("Capabilities: %+v", platform.Features.Capabilities()),
+		fmt.Sprintf("Remote session: %v, virtual machine: %v, degraded mode: %s",
+			platform.Features.RemoteSession(), virtualMachine(), degraded),
+		fmt.Sprintf("Battery saver: %v, reduced motion: %v, screen reader: %v", saving, ui.ReducedMotion(), reader),
+		fmt.Sprintf("Software opacity: %v", a.overlay.SoftwareOpacity()),
+	}
+	r.Diagnostics = a.diagnostics().Report()
+	ui.ShowReport(a.fyneApp, r.Text(), r.IssueURL())
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"claudebar/internal/statuspage"
+)
+
+// The status page is checked once the API failed incidentAfter times in a
+// row, and at most every incidentCheckInterval while it keeps failing
+const (
+	incidentAfter         = 2
+	incidentCheckInterval = 5 * time.Minute
+)
+
+// incidentCheck caches the last status page check
+type incidentCheck struct {
+	mu      sync.Mutex
+	checked time.Time
+	text    string // overlay line for the incident, "" when none
+}
+
+// serviceIncident returns an overlay line describing the Claude incident
+// behind failing requests, or "" when the status page reports none or can't
+// be read
+func (a *App) serviceIncident() string {
+	a.incident.mu.Lock()
+	defer a.incident.mu.Unlock()
+	if time.Since(a.incident.checked) < incidentCheckInterval {
+		return a.incident.text
+	}
+	a.incident.checked = time.Now()
+	a.incident.text = ""
+
+	status, err := statuspage.Fetch(context.Background(), statuspage.SummaryURL)
+	if err != nil {
+		log.Printf("Status page: %v", err)
+		return ""
+	}
+	a.incident.text = status.Incident()
+	if a.incident.text != "" {
+		log.Printf("Status page: %s (%s)", a.incident.text, status.Description)
+	}
+	return a.incident.text
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"log"
+
+	"claudebar/internal/api"
+)
+
+// With light polling on, polling slows down lightPollingFactor times while
+// the overlay is hidden, unless usage is within lightPollingMargin points
+// below an alert threshold
+const (
+	lightPollingFactor = 4
+	lightPollingMargin = 10
+)
+
+// nearThreshold reports whether session or weekly usage is less than margin
+// points below one of thresholds. Unknown usage counts as near.
+func nearThreshold(usage *api.UsageData, thresholds []float64, margin float64) bool {
+	if usage == nil {
+		return true
+	}
+	for _, u := range []float64{usage.FiveHour.Utilization, usage.SevenDay.Utilization} {
+		for _, t := range thresholds {
+			if u < t && t-u < margin {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lightPolling reports whether polling is slowed down for a hidden overlay
+func (a *App) lightPolling() bool {
+	if !a.config.LightPolling || a.overlay == nil || a.overlay.IsVisible() {
+		return false
+	}
+	a.mu.RLock()
+	usage := a.lastUsage
+	a.mu.RUnlock()
+	return !nearThreshold(usage, a.config.CurrentAlertThresholds(), lightPollingMargin)
+}
+
+// updateLightPolling applies the refresh interval after the overlay was
+// shown or hidden or usage moved near a threshold, and reports whether
+// polling sped up. Call from the refresh loop while at the normal rate.
+func (a *App) updateLightPolling() bool {
+	interval := a.refreshInterval()
+	a.mu.RLock()
+	current := a.pollInterval
+	a.mu.RUnlock()
+	if interval == current {
+		return false
+	}
+	a.setPollInterval(interval)
+	if interval > current {
+		log.Printf("Overlay hidden and no alert near, polling every %s", interval)
+		return false
+	}
+	log.Printf("Polling every %s again", interval)
+	return true
+}
+
+// recheckLightPolling asks the refresh loop to re-apply light polling, e.g.
+// after the overlay was shown or hidden
+func (a *App) recheckLightPolling() {
+	select {
+	case a.pollCheck <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,30 @@
+package app
+
+import (
+	"testing"
+
+	"claudebar/internal/api"
+)
+
+func TestNearThreshold(t *testing.T) {
+	thresholds := []float64{50, 75, 90}
+	tests := []struct {
+		session, weekly float64
+		want            bool
+	}{
+		{10, 20, false},
+		{42, 20, true},  // 8 below 50
+		{50, 20, false}, // at 50, 75 is far
+		{10, 81, true},  // 9 below 90
+		{95, 95, false}, // past every threshold
+	}
+	for _, tt := range tests {
+		usage := &api.UsageData{FiveHour: api.UsageStat{Utilization: tt.session}, SevenDay: api.UsageStat{Utilization: tt.weekly}}
+		if got := nearThreshold(usage, thresholds, lightPollingMargin); got != tt.want {
+			t.Errorf("nearThreshold(session %v, weekly %v) = %v, want %v", tt.session, tt.weekly, got, tt.want)
+		}
+	}
+	if !nearThreshold(nil, thresholds, lightPollingMargin) {
+		t.Error("unknown usage should count as near")
+	}
+}
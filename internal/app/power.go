@@ -0,0 +1,105 @@
+package app
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"claudebar/internal/platform"
+	"claudebar/internal/ui"
+)
+
+// powerCheckInterval is how often battery, reduced-motion and screen reader
+// state are re-read
+const powerCheckInterval = 30 * time.Second
+
+// virtualMachine reports whether this is a virtual machine, which doesn't
+// change while running
+var virtualMachine = sync.OnceValue(func() bool {
+	return platform.Features.VirtualMachine()
+})
+
+// degradedReason applies the degraded_mode override to remote session and
+// virtual machine detection, returning why degraded mode is on or "" when
+// it's off
+func degradedReason(setting string, remote, vm func() bool) string {
+	switch setting {
+	case "on":
+		return "turned on in config"
+	case "off":
+		return ""
+	}
+	if remote() {
+		return "remote desktop session"
+	}
+	if vm() {
+		return "virtual machine"
+	}
+	return ""
+}
+
+// resolveOverride applies an "on"/"off" config override to a detected
+// state; anything else follows detect
+func resolveOverride(setting string, detect func() bool) bool {
+	switch setting {
+	case "on":
+		return true
+	case "off":
+		return false
+	}
+	return detect()
+}
+
+// updatePowerState re-reads the battery-saver, reduced-motion, degraded mode
+// and screen reader state, pushes reduced motion to the UI and reports
+// whether battery saving or degraded mode changed
+func (a *App) updatePowerState() bool {
+	saving := resolveOverride(a.config.BatterySaver, platform.Features.OnBatterySaver)
+	degraded := degradedReason(a.config.DegradedMode, platform.Features.RemoteSession, virtualMachine)
+	reduced := resolveOverride(a.config.ReduceMotion, platform.Features.PrefersReducedMotion) || degraded != ""
+	reader := platform.Features.ScreenReaderActive()
+
+	a.mu.Lock()
+	changed := saving != a.powerSaving
+	a.powerSaving = saving
+	degradedChanged := degraded != a.degraded
+	a.degraded = degraded
+	readerChanged := reader != a.screenReader
+	a.screenReader = reader
+	a.mu.Unlock()
+
+	if readerChanged {
+		log.Printf("Screen reader running: %v", reader)
+	}
+	if degradedChanged {
+		if degraded != "" {
+			log.Printf("Degraded mode on (%s): animations off, reducing refresh rate", degraded)
+		} else {
+			log.Println("Degraded mode off")
+		}
+	}
+
+	fyne.Do(func() {
+		ui.SetReducedMotion(reduced)
+	})
+
+	if changed {
+		if saving {
+			log.Println("Battery saver on, reducing refresh rate")
+		} else {
+			log.Println("Battery saver off, restoring normal refresh rate")
+		}
+	}
+	return changed || degradedChanged
+}
+
+// recheckPower asks the refresh loop to re-read the power state now, e.g.
+// after the overrides were changed in settings
+func (a *App) recheckPower() {
+	select {
+	case a.powerCheck <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,24 @@
+package app
+
+import "testing"
+
+func TestDegradedReason(t *testing.T) {
+	yes := func() bool { return true }
+	no := func() bool { return false }
+	tests := []struct {
+		setting    string
+		remote, vm func() bool
+		want       string
+	}{
+		{"", no, no, ""},
+		{"", yes, yes, "remote desktop session"},
+		{"", no, yes, "virtual machine"},
+		{"off", yes, yes, ""},
+		{"on", no, no, "turned on in config"},
+	}
+	for _, tt := range tests {
+		if got := degradedReason(tt.setting, tt.remote, tt.vm); got != tt.want {
+			t.Errorf("degradedReason(%q) = %q, want %q", tt.setting, got, tt.want)
+		}
+	}
+}
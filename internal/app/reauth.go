@@ -0,0 +1,88 @@
+package app
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"claudebar/internal/ui"
+)
+
+// Signing in again after a failed browser refresh backs off from a minute,
+// doubling up to half an hour, independently of the fetch retries
+const (
+	reauthBaseDelay = time.Minute
+	reauthMaxDelay  = 30 * time.Minute
+)
+
+// reauthQueue coordinates signing in again once the session stops working:
+// the browsers aren't rescanned on every poll, and the user is prompted once
+// per outage rather than on every failure
+type reauthQueue struct {
+	mu       sync.Mutex
+	failures int       // consecutive failed attempts
+	next     time.Time // no attempt before this
+	prompted bool      // the user was prompted during this outage
+}
+
+// reauthDelay returns how long to wait after the nth consecutive failed
+// attempt
+func reauthDelay(n int) time.Duration {
+	return min(reauthBaseDelay<<min(max(n-1, 0), 5), reauthMaxDelay)
+}
+
+// waiting reports whether an attempt failed recently enough that fetching
+// (and with it signing in again) should hold off
+func (q *reauthQueue) waiting(now time.Time) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.failures > 0 && now.Before(q.next)
+}
+
+// failed records a failed attempt. It returns the wait before the next one
+// and whether to prompt the user, which is true once per outage.
+func (q *reauthQueue) failed(now time.Time) (wait time.Duration, prompt bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.failures++
+	wait = reauthDelay(q.failures)
+	q.next = now.Add(wait)
+	prompt = !q.prompted
+	q.prompted = true
+	return wait, prompt
+}
+
+// reset ends the outage after a working session key was found
+func (q *reauthQueue) reset() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.failures = 0
+	q.next = time.Time{}
+	q.prompted = false
+}
+
+// reauthenticate looks for a new session key in the browsers after the API
+// rejected the current one. status is the short error for the overlay.
+func (a *App) reauthenticate(status string) {
+	log.Printf("%s, attempting browser refresh...", status)
+	fyne.Do(func() {
+		a.overlay.SetStatus(status + " - refreshing...")
+	})
+	err := a.authManager.RefreshFromBrowser()
+	if err == nil {
+		a.reauth.reset()
+		return
+	}
+
+	wait, prompt := a.reauth.failed(time.Now())
+	log.Printf("Re-authentication failed: %v; trying again in %s", err, wait)
+	fyne.Do(func() {
+		a.overlay.SetStatus(status + " - update key in Settings")
+		a.updateAccountStatus(false)
+		if prompt {
+			ui.ShowReauthPrompt(a.fyneApp, status, a.showSettings)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReauthQueue(t *testing.T) {
+	var q reauthQueue
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	if q.waiting(now) {
+		t.Fatal("waiting before any failure")
+	}
+
+	wait, prompt := q.failed(now)
+	if wait != time.Minute || !prompt {
+		t.Errorf("first failure = %v, prompt %v; want 1m, prompt", wait, prompt)
+	}
+	if !q.waiting(now.Add(30*time.Second)) || q.waiting(now.Add(wait)) {
+		t.Error("should wait until the backoff has passed")
+	}
+
+	// Later failures back off further without prompting again
+	var waits []time.Duration
+	for range 6 {
+		wait, prompt = q.failed(now)
+		if prompt {
+			t.Error("prompted twice in one outage")
+		}
+		waits = append(waits, wait)
+	}
+	want := []time.Duration{2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 16 * time.Minute, 30 * time.Minute, 30 * time.Minute}
+	for i := range want {
+		if waits[i] != want[i] {
+			t.Errorf("waits = %v, want %v", waits, want)
+			break
+		}
+	}
+
+	q.reset()
+	if q.waiting(now) {
+		t.Error("waiting after reset")
+	}
+	if _, prompt := q.failed(now); !prompt {
+		t.Error("a new outage should prompt again")
+	}
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"time"
+
+	"claudebar/internal/api"
+)
+
+// Polling speeds up from resetLead before a known reset until resetTrail
+// after it, so the rollover shows within seconds instead of up to a full
+// refresh interval late. The trail covers the API settling on new numbers.
+const (
+	resetLead         = time.Minute
+	resetTrail        = 2 * time.Minute
+	resetPollInterval = 10 * time.Second
+)
+
+// resetWindow returns the fast-polling window around the earliest reset in
+// usage that hasn't ended by after. ok is false when no reset is known.
+func resetWindow(usage *api.UsageData, after time.Time) (start, end time.Time, ok bool) {
+	if usage == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	for _, resetsAt := range []time.Time{usage.FiveHour.ResetsAt, usage.SevenDay.ResetsAt} {
+		if resetsAt.IsZero() || !resetsAt.Add(resetTrail).After(after) {
+			continue
+		}
+		if !ok || resetsAt.Add(-resetLead).Before(start) {
+			start, end, ok = resetsAt.Add(-resetLead), resetsAt.Add(resetTrail), true
+		}
+	}
+	return start, end, ok
+}
+
+// scheduleResetWindow sets timer to fire when the next reset window after
+// the given time opens, or stops it when no reset is known
+func (a *App) scheduleResetWindow(timer *time.Timer, after time.Time) {
+	a.mu.RLock()
+	start, _, ok := resetWindow(a.lastUsage, after)
+	a.mu.RUnlock()
+
+	timer.Stop()
+	if ok {
+		timer.Reset(max(time.Until(start), 0))
+	}
+}
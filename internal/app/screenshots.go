@@ -0,0 +1,27 @@
+package app
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/theme"
+
+	"claudebar/internal/demo"
+	"claudebar/internal/ui"
+)
+
+// RunScreenshots writes a PNG of every overlay layout into dir, rendered with
+// fixed demo data, and exits. Used to produce reproducible images for release
+// notes and theme previews.
+func RunScreenshots(dir string) error {
+	fyneApp := app.NewWithID("com.claudebar.app")
+	fyneApp.Settings().SetTheme(theme.DarkTheme())
+
+	var err error
+	fyneApp.Lifecycle().SetOnStarted(func() {
+		err = ui.RenderScreenshots(fyneApp, demo.Snapshot(time.Now()), dir)
+		fyneApp.Quit()
+	})
+	fyneApp.Run()
+	return err
+}
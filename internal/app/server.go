@@ -0,0 +1,129 @@
+package app
+
+import (
+	"crypto/tls"
+	"log"
+	"maps"
+	"net"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+
+	"claudebar/internal/alert"
+	"claudebar/internal/api"
+	"claudebar/internal/config"
+	"claudebar/internal/server"
+)
+
+// serverBackend exposes the app to the local server
+type serverBackend struct {
+	a *App
+}
+
+func (b serverBackend) Usage() *api.UsageData {
+	b.a.mu.RLock()
+	defer b.a.mu.RUnlock()
+	return b.a.lastUsage
+}
+
+func (b serverBackend) AlertLevels() alert.Levels {
+	return b.a.config.AlertLevels()
+}
+
+func (b serverBackend) APIToken() string {
+	return b.a.config.ServerToken
+}
+
+func (b serverBackend) DashboardToken() string {
+	if !b.a.config.DashboardEnabled {
+		return ""
+	}
+	return b.a.config.DashboardToken
+}
+
+func (b serverBackend) MetricsEnabled() bool {
+	return b.a.config.MetricsEnabled
+}
+
+func (b serverBackend) FetchStats() server.FetchStats {
+	b.a.mu.RLock()
+	defer b.a.mu.RUnlock()
+	stats := b.a.fetchStats
+	stats.Failures = maps.Clone(stats.Failures)
+	return stats
+}
+
+func (b serverBackend) Status() server.Status {
+	signedIn := b.a.demo || b.a.authManager.IsAuthenticated()
+	b.a.mu.RLock()
+	defer b.a.mu.RUnlock()
+	return server.Status{
+		SignedIn:  signedIn,
+		Account:   b.a.config.ActiveAccount,
+		LastFetch: b.a.lastFetchOK,
+		LastError: b.a.lastFetchErr,
+	}
+}
+
+func (b serverBackend) Refresh() {
+	b.a.refreshNow()
+}
+
+func (b serverBackend) ShowOverlay() {
+	fyne.Do(b.a.showOverlay)
+}
+
+// updateServer starts, stops or restarts the server to match the config.
+// Unless a bind address is set it only listens beyond localhost while the
+// LAN dashboard is on.
+func (a *App) updateServer() {
+	enabled := a.config.ServerEnabled || a.config.DashboardEnabled || a.config.MetricsEnabled
+	host := a.config.ServerBind
+	if host == "" {
+		host = "127.0.0.1"
+		if a.config.DashboardEnabled {
+			host = "0.0.0.0"
+		}
+	}
+	scheme := "http"
+	if a.config.ServerTLS {
+		scheme = "https"
+	}
+
+	// The listener's own address can't be compared: 0.0.0.0 listens as
+	// [::] and hostnames resolve
+	want := scheme + "://" + net.JoinHostPort(host, strconv.Itoa(a.config.ServerPort))
+	if a.serverAddr != "" && (!enabled || a.serverAddr != want) {
+		a.server.Stop()
+		a.serverAddr = ""
+	}
+	if !enabled || a.serverAddr != "" {
+		return
+	}
+
+	var cert *tls.Certificate
+	if a.config.ServerTLS {
+		c, err := serverCertificate()
+		if err != nil {
+			log.Printf("Warning: Failed to load server certificate: %v", err)
+			return
+		}
+		cert = &c
+		log.Printf("Server: certificate SHA-256 fingerprint %s", server.Fingerprint(c))
+	}
+	if err := a.server.Start(host, a.config.ServerPort, cert); err != nil {
+		log.Printf("Warning: Failed to start local server: %v", err)
+		return
+	}
+	a.serverAddr = want
+}
+
+// serverCertificate loads the self-signed HTTPS certificate from the config
+// directory, creating it on first use
+func serverCertificate() (tls.Certificate, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return server.LoadOrCreateCertificate(dir)
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+// spikeFilter holds back single-poll utilization jumps until the next poll
+// confirms them, so a transient bogus value doesn't move the bars or fire
+// notifications
+type spikeFilter struct {
+	accepted *api.UsageData
+	pending  map[string]float64 // unconfirmed value per metric
+}
+
+// usageStats returns the metrics of a usage snapshot by name
+func usageStats(u *api.UsageData) map[string]*api.UsageStat {
+	return map[string]*api.UsageStat{
+		"session": &u.FiveHour,
+		"weekly":  &u.SevenDay,
+		"opus":    &u.SevenDayOpus,
+		"sonnet":  &u.SevenDaySonnet,
+	}
+}
+
+// apply returns usage with unconfirmed spikes replaced by the last accepted
+// values. threshold is in percentage points.
+func (f *spikeFilter) apply(usage *api.UsageData, threshold float64) *api.UsageData {
+	if f.accepted == nil {
+		f.accepted = usage
+		f.pending = make(map[string]float64)
+		return usage
+	}
+
+	filtered := *usage
+	prevStats := usageStats(f.accepted)
+	for name, stat := range usageStats(&filtered) {
+		prev := prevStats[name]
+
+		// A moved reset time means the window rolled over; drops are expected
+		if stat.ResetsAt.Sub(prev.ResetsAt).Abs() > time.Minute && stat.Utilization < prev.Utilization {
+			delete(f.pending, name)
+			continue
+		}
+
+		if math.Abs(stat.Utilization-prev.Utilization) <= threshold {
+			delete(f.pending, name)
+			continue
+		}
+
+		// Second poll in a row near the new value confirms the jump
+		if pending, ok := f.pending[name]; ok && math.Abs(stat.Utilization-pending) <= threshold {
+			log.Printf("Spike filter: %s jump to %.0f%% confirmed", name, stat.Utilization)
+			delete(f.pending, name)
+			continue
+		}
+
+		log.Printf("Spike filter: holding %s at %.0f%% (got %.0f%%, waiting for confirmation)",
+			name, prev.Utilization, stat.Utilization)
+		f.pending[name] = stat.Utilization
+		*stat = *prev
+	}
+
+	f.accepted = &filtered
+	return &filtered
+}
+
+// reset forgets the accepted baseline, e.g. after switching accounts
+func (f *spikeFilter) reset() {
+	f.accepted = nil
+	f.pending = nil
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+func TestSpikeFilter(t *testing.T) {
+	resetA := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+	resetB := resetA.Add(5 * time.Hour)
+
+	type poll struct {
+		pct    float64
+		resets time.Time
+		want   float64
+	}
+	tests := []struct {
+		name  string
+		polls []poll
+	}{
+		{"first poll is accepted", []poll{
+			{80, resetA, 80},
+		}},
+		{"small moves pass", []poll{
+			{10, resetA, 10},
+			{25, resetA, 25},
+			{20, resetA, 20},
+		}},
+		{"single spike is held", []poll{
+			{10, resetA, 10},
+			{90, resetA, 10},
+			{12, resetA, 12},
+		}},
+		{"confirmed jump is accepted", []poll{
+			{10, resetA, 10},
+			{60, resetA, 10},
+			{62, resetA, 62},
+		}},
+		{"unconfirmed second value is held again", []poll{
+			{10, resetA, 10},
+			{60, resetA, 10},
+			{90, resetA, 10},
+			{90, resetA, 90},
+		}},
+		{"single drop is held", []poll{
+			{70, resetA, 70},
+			{0, resetA, 70},
+			{71, resetA, 71},
+		}},
+		{"drop after reset passes", []poll{
+			{70, resetA, 70},
+			{0, resetB, 0},
+		}},
+		{"rise with moved reset is still held", []poll{
+			{0, resetA, 0},
+			{90, resetB, 0},
+		}},
+	}
+	for _, tt := range tests {
+		var f spikeFilter
+		for i, p := range tt.polls {
+			usage := &api.UsageData{FiveHour: api.UsageStat{Utilization: p.pct, ResetsAt: p.resets}}
+			got := f.apply(usage, 20).FiveHour.Utilization
+			if got != p.want {
+				t.Errorf("%s: poll %d (%.0f%%) = %.0f%%, want %.0f%%", tt.name, i, p.pct, got, p.want)
+			}
+		}
+	}
+}
+
+func TestSpikeFilterReset(t *testing.T) {
+	var f spikeFilter
+	f.apply(&api.UsageData{FiveHour: api.UsageStat{Utilization: 10}}, 20)
+	f.reset()
+	if got := f.apply(&api.UsageData{FiveHour: api.UsageStat{Utilization: 90}}, 20).FiveHour.Utilization; got != 90 {
+		t.Errorf("after reset got %.0f%%, want the new account's 90%%", got)
+	}
+}
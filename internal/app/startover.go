@@ -0,0 +1,50 @@
+package app
+
+import (
+	"fmt"
+	"log"
+
+	"claudebar/internal/backup"
+)
+
+// resetEverything is the recovery action for a wedged state: it backs up the
+// config directory, signs out, forgets the organization, cached usage and
+// overlay position, optionally deletes the history, and then looks for a
+// session again as on first start. Returns the backup path. Call on the UI
+// thread.
+func (a *App) resetEverything(history bool) (string, error) {
+	path, err := backup.Snapshot()
+	if err != nil {
+		return "", fmt.Errorf("backup failed, nothing was reset: %w", err)
+	}
+	log.Printf("Resetting saved state (history: %v), backup at %s", history, path)
+
+	if history && a.cycles != nil {
+		if err := a.cycles.Clear(); err != nil {
+			return path, fmt.Errorf("failed to delete history: %w", err)
+		}
+		go a.refreshTrends()
+	}
+
+	a.mu.Lock()
+	a.lastUsage = nil
+	a.lastFetchErr = nil
+	a.consecutiveErrors = 0
+	a.lastSessionThreshold = 0
+	a.lastWeeklyThreshold = 0
+	a.budgetAlerted = false
+	a.spikes.reset()
+	a.mu.Unlock()
+	a.requests.Reset()
+
+	// Not pushed to sync: the other devices' sessions are fine
+	a.config.OrganizationOverride = ""
+	a.resetPosition()
+	if err := a.authManager.ClearCredentials(); err != nil {
+		return path, err
+	}
+	a.updateAccountStatus(false)
+
+	go a.authenticate()
+	return path, nil
+}
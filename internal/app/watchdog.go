@@ -0,0 +1,60 @@
+package app
+
+import (
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+const (
+	watchdogInterval = 10 * time.Second // how often the UI thread is pinged
+	watchdogTimeout  = 15 * time.Second // no response within this = stalled
+)
+
+// watchdogLoop pings the Fyne main thread and reports when it stops
+// responding. Once a stalled thread comes back, the overlay window is
+// recreated since whatever blocked it (e.g. a dialog on a hidden window)
+// usually leaves the window in a broken state.
+func (a *App) watchdogLoop() {
+	ticker := time.NewTicker(watchdogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			start := time.Now()
+			done := make(chan struct{})
+			fyne.Do(func() { close(done) })
+
+			select {
+			case <-done:
+				continue
+			case <-time.After(watchdogTimeout):
+			case <-a.stopChan:
+				return
+			}
+
+			log.Printf("Watchdog: UI thread has not responded for %v", watchdogTimeout)
+
+			// Wait for the thread to come back before touching any windows
+			select {
+			case <-done:
+			case <-a.stopChan:
+				return
+			}
+
+			log.Printf("Watchdog: UI thread recovered after %v, recreating overlay", time.Since(start).Round(time.Second))
+			fyne.Do(a.recoverOverlay)
+		case <-a.stopChan:
+			return
+		}
+	}
+}
+
+// recoverOverlay rebuilds the overlay window. Must run on the Fyne thread.
+func (a *App) recoverOverlay() {
+	a.overlay.Recreate()
+	// The settings window is parented to the old overlay window
+	a.settings = nil
+}
@@ -4,6 +4,8 @@ import (
 	_ "embed"
 
 	"fyne.io/fyne/v2"
+
+	"claudebar/internal/alert"
 )
 
 //go:embed tray.png
@@ -12,11 +14,54 @@ var trayIconData []byte
 //go:embed app.png
 var appIconData []byte
 
+//go:embed tray-light.png
+var trayLightIconData []byte
+
+//go:embed tray-dark.png
+var trayDarkIconData []byte
+
+//go:embed tray-light-warning.png
+var trayLightWarningIconData []byte
+
+//go:embed tray-light-critical.png
+var trayLightCriticalIconData []byte
+
+//go:embed tray-dark-warning.png
+var trayDarkWarningIconData []byte
+
+//go:embed tray-dark-critical.png
+var trayDarkCriticalIconData []byte
+
 // TrayIcon returns the system tray icon resource
 func TrayIcon() fyne.Resource {
 	return fyne.NewStaticResource("tray.png", trayIconData)
 }
 
+// TrayIconForTheme returns the monochrome tray icon that contrasts with a
+// light or dark taskbar
+func TrayIconForTheme(light bool) fyne.Resource {
+	if light {
+		return fyne.NewStaticResource("tray-light.png", trayLightIconData)
+	}
+	return fyne.NewStaticResource("tray-dark.png", trayDarkIconData)
+}
+
+// TrayIconForSeverity returns the themed tray icon, with a yellow or red dot
+// for warning and critical usage
+func TrayIconForSeverity(light bool, severity alert.Severity) fyne.Resource {
+	switch {
+	case severity == alert.Warning && light:
+		return fyne.NewStaticResource("tray-light-warning.png", trayLightWarningIconData)
+	case severity == alert.Warning:
+		return fyne.NewStaticResource("tray-dark-warning.png", trayDarkWarningIconData)
+	case severity == alert.Critical && light:
+		return fyne.NewStaticResource("tray-light-critical.png", trayLightCriticalIconData)
+	case severity == alert.Critical:
+		return fyne.NewStaticResource("tray-dark-critical.png", trayDarkCriticalIconData)
+	}
+	return TrayIconForTheme(light)
+}
+
 // AppIcon returns the application icon resource
 func AppIcon() fyne.Resource {
 	return fyne.NewStaticResource("app.png", appIconData)
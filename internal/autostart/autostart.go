@@ -0,0 +1,31 @@
+package autostart
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// appName is the name used for the login item / registry value / desktop file
+const appName = "ClaudeBar"
+
+// ErrManagedByPackage is returned when the OS package (e.g. MSIX) owns the
+// startup registration and it can't be changed from inside the app
+var ErrManagedByPackage = errors.New("auto-start is managed by the installed package; change it in the OS startup apps settings")
+
+// executablePath returns the absolute path of the running binary
+func executablePath() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exe)
+}
+
+// Apply makes the OS registration match the desired state
+func Apply(enabled bool) error {
+	if enabled {
+		return Enable()
+	}
+	return Disable()
+}
@@ -0,0 +1,74 @@
+//go:build darwin
+
+package autostart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const launchAgentLabel = "com.claudebar.app"
+
+// plistPath returns the per-user LaunchAgent location
+func plistPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", launchAgentLabel+".plist"), nil
+}
+
+// Enable installs a LaunchAgent that starts the app on login
+func Enable() error {
+	exe, err := executablePath()
+	if err != nil {
+		return err
+	}
+	path, err := plistPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	plist := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+</dict>
+</plist>
+`, launchAgentLabel, exe)
+	return os.WriteFile(path, []byte(plist), 0644)
+}
+
+// Disable removes the LaunchAgent
+func Disable() error {
+	path, err := plistPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// IsEnabled reports whether the LaunchAgent is installed
+func IsEnabled() bool {
+	path, err := plistPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
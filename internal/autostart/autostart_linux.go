@@ -0,0 +1,88 @@
+//go:build linux
+
+package autostart
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"claudebar/internal/portal"
+	"claudebar/internal/sandbox"
+)
+
+// flatpakAppID is the Flatpak application ID, also used by the portal for
+// the autostart entry it writes on the host
+const flatpakAppID = "com.claudebar.app"
+
+// desktopPath returns the XDG autostart entry location on the host
+func desktopPath() (string, error) {
+	name := "claudebar.desktop"
+	if sandbox.Flatpak() {
+		name = flatpakAppID + ".desktop"
+	}
+	return filepath.Join(sandbox.HostConfigDir(), "autostart", name), nil
+}
+
+// Enable writes an XDG autostart .desktop entry. Inside Flatpak the entry
+// can't be written directly, so the Background portal is asked instead.
+func Enable() error {
+	if sandbox.Flatpak() {
+		return portal.RequestBackground("Start ClaudeBar when you log in", true, []string{"claudebar"})
+	}
+
+	exe, err := executablePath()
+	if err != nil {
+		return err
+	}
+	// An AppImage is mounted at a random path on every launch
+	if sandbox.AppImage() {
+		exe = sandbox.AppImagePath()
+	}
+
+	path, err := desktopPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	entry := fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=%s
+Comment=Claude AI usage monitor
+Exec="%s"
+Icon=claudebar
+Terminal=false
+X-GNOME-Autostart-enabled=true
+`, appName, exe)
+	return os.WriteFile(path, []byte(entry), 0644)
+}
+
+// Disable removes the autostart entry
+func Disable() error {
+	if sandbox.Flatpak() {
+		return portal.RequestBackground("Stop starting ClaudeBar when you log in", false, []string{"claudebar"})
+	}
+
+	path, err := desktopPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// IsEnabled reports whether the autostart entry exists. Under Flatpak this
+// needs read access to the host's autostart directory (see the manifest).
+func IsEnabled() bool {
+	path, err := desktopPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
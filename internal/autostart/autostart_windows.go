@@ -0,0 +1,115 @@
+//go:build windows
+
+package autostart
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32                      = syscall.NewLazyDLL("advapi32.dll")
+	kernel32                      = syscall.NewLazyDLL("kernel32.dll")
+	procRegSetValueEx             = advapi32.NewProc("RegSetValueExW")
+	procRegDeleteValue            = advapi32.NewProc("RegDeleteValueW")
+	procRegQueryValueEx           = advapi32.NewProc("RegQueryValueExW")
+	procGetCurrentPackageFullName = kernel32.NewProc("GetCurrentPackageFullName")
+)
+
+const (
+	runKeyPath = `Software\Microsoft\Windows\CurrentVersion\Run`
+
+	regSZ = 1
+
+	errorFileNotFound    = 2
+	appmodelErrNoPackage = 15700
+)
+
+// isPackaged reports whether the app runs from an MSIX package, where the
+// StartupTask declared in the manifest controls auto-start
+func isPackaged() bool {
+	if err := procGetCurrentPackageFullName.Find(); err != nil {
+		return false // pre-Windows 8
+	}
+	var length uint32
+	ret, _, _ := procGetCurrentPackageFullName.Call(uintptr(unsafe.Pointer(&length)), 0)
+	return ret != appmodelErrNoPackage
+}
+
+// openRunKey opens HKCU\...\Run for reading and writing
+func openRunKey() (syscall.Handle, error) {
+	var key syscall.Handle
+	path, _ := syscall.UTF16PtrFromString(runKeyPath)
+	err := syscall.RegOpenKeyEx(syscall.HKEY_CURRENT_USER, path, 0, syscall.KEY_QUERY_VALUE|syscall.KEY_SET_VALUE, &key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open Run key: %w", err)
+	}
+	return key, nil
+}
+
+// Enable registers the app to start on login via the HKCU Run key
+func Enable() error {
+	if isPackaged() {
+		return ErrManagedByPackage
+	}
+
+	exe, err := executablePath()
+	if err != nil {
+		return err
+	}
+
+	key, err := openRunKey()
+	if err != nil {
+		return err
+	}
+	defer syscall.RegCloseKey(key)
+
+	name, _ := syscall.UTF16PtrFromString(appName)
+	value, _ := syscall.UTF16FromString(`"` + exe + `"`)
+	ret, _, _ := procRegSetValueEx.Call(
+		uintptr(key),
+		uintptr(unsafe.Pointer(name)),
+		0,
+		regSZ,
+		uintptr(unsafe.Pointer(&value[0])),
+		uintptr(len(value)*2),
+	)
+	if ret != 0 {
+		return fmt.Errorf("RegSetValueEx failed: %w", syscall.Errno(ret))
+	}
+	return nil
+}
+
+// Disable removes the Run key entry
+func Disable() error {
+	if isPackaged() {
+		return ErrManagedByPackage
+	}
+
+	key, err := openRunKey()
+	if err != nil {
+		return err
+	}
+	defer syscall.RegCloseKey(key)
+
+	name, _ := syscall.UTF16PtrFromString(appName)
+	ret, _, _ := procRegDeleteValue.Call(uintptr(key), uintptr(unsafe.Pointer(name)))
+	if ret != 0 && ret != errorFileNotFound {
+		return fmt.Errorf("RegDeleteValue failed: %w", syscall.Errno(ret))
+	}
+	return nil
+}
+
+// IsEnabled reports whether the Run key entry exists
+func IsEnabled() bool {
+	key, err := openRunKey()
+	if err != nil {
+		return false
+	}
+	defer syscall.RegCloseKey(key)
+
+	name, _ := syscall.UTF16PtrFromString(appName)
+	ret, _, _ := procRegQueryValueEx.Call(uintptr(key), uintptr(unsafe.Pointer(name)), 0, 0, 0, 0)
+	return ret == 0
+}
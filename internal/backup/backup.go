@@ -0,0 +1,247 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"claudebar/internal/config"
+	"claudebar/internal/history"
+	"claudebar/internal/vault"
+)
+
+// Extension is the file extension used for exported bundles
+const Extension = ".cbbackup"
+
+// maxEntrySize guards against decompression bombs in hostile archives
+const maxEntrySize = 256 << 20
+
+var ErrInvalidBundle = errors.New("not a valid ClaudeBar settings bundle")
+
+// Export builds an encrypted archive of everything in the config directory
+// (config, thresholds, themes). Usage history is only included on request
+// since it can be large.
+func Export(passphrase string, includeHistory bool) ([]byte, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	// Flush in-memory settings so the archive matches what the user sees
+	if err := config.Get().Save(); err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
+
+	data, err := archive(dir, includeHistory)
+	if err != nil {
+		return nil, err
+	}
+	return vault.Seal(passphrase, data)
+}
+
+// archive zips the files in dir, leaving out temp files, earlier automatic
+// backups and, unless includeHistory is set, the usage history
+func archive(dir string, includeHistory bool) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if isExcluded(name, includeHistory) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || isExcluded(name, includeHistory) {
+			return nil
+		}
+
+		var data []byte
+		if name == history.SamplesFile {
+			data, err = copySamples(dir)
+		} else {
+			data, err = os.ReadFile(p)
+		}
+		if err != nil {
+			return err
+		}
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// copySamples returns a consistent copy of the sample database in dir, which
+// the history tracker keeps open and writing to
+func copySamples(dir string) ([]byte, error) {
+	tmp, err := os.MkdirTemp("", "claudebar-samples")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	dest := filepath.Join(tmp, history.SamplesFile)
+	if err := history.CopySamples(dir, dest); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(dest)
+}
+
+// HistoryRestorer replaces the usage history while nothing writes to it, like
+// history.Tracker
+type HistoryRestorer interface {
+	Restore(write func() error) error
+}
+
+// Import decrypts a bundle produced by Export, writes its files into the
+// config directory and reloads the config. Usage history in the bundle is
+// written through restorer, which keeps the running app from overwriting it;
+// nil writes it directly.
+func Import(sealed []byte, passphrase string, restorer HistoryRestorer) error {
+	data, err := vault.Open(passphrase, sealed)
+	if err != nil {
+		return err
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return ErrInvalidBundle
+	}
+
+	dir, err := config.Dir()
+	if err != nil {
+		return err
+	}
+
+	var historyFiles []*zip.File
+	for _, f := range zr.File {
+		name, ok := entryPath(f.Name)
+		if !ok || f.FileInfo().IsDir() || isExcluded(filepath.ToSlash(name), true) {
+			log.Printf("Backup: skipped %q", f.Name)
+			continue
+		}
+		if isHistory(name) {
+			historyFiles = append(historyFiles, f)
+			continue
+		}
+		if err := restore(f, dir, name); err != nil {
+			return err
+		}
+	}
+
+	if len(historyFiles) > 0 {
+		write := func() error {
+			for _, f := range historyFiles {
+				name, _ := entryPath(f.Name)
+				if err := restore(f, dir, name); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if restorer != nil {
+			err = restorer.Restore(write)
+		} else {
+			err = write()
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return config.Get().Load()
+}
+
+// restore writes the archive entry f to name in dir
+func restore(f *zip.File, dir, name string) error {
+	if err := extractFile(f, filepath.Join(dir, name)); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", name, err)
+	}
+	log.Printf("Backup: restored %s", name)
+	return nil
+}
+
+// entryPath converts an archive entry name to a path relative to the config
+// directory. Names that could land outside it (zip slip) are rejected:
+// absolute paths, ".." segments and backslashes, which Windows would treat
+// as separators.
+func entryPath(name string) (string, bool) {
+	if name == "" || strings.Contains(name, `\`) {
+		return "", false
+	}
+	p := filepath.FromSlash(path.Clean(name))
+	if !filepath.IsLocal(p) {
+		return "", false
+	}
+	return p, true
+}
+
+// extractFile writes a single archive entry to dest via a temp file
+func extractFile(f *zip.File, dest string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxEntrySize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxEntrySize {
+		return errors.New("entry too large")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp := dest + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+// isExcluded reports whether a config-dir entry should be left out of the bundle
+func isExcluded(name string, includeHistory bool) bool {
+	if name == SnapshotDir {
+		return true
+	}
+	base := path.Base(name)
+	// SQLite's journal is only consistent with the database while it's open
+	if strings.HasSuffix(base, ".tmp") || strings.HasPrefix(base, history.SamplesFile+"-") {
+		return true
+	}
+	return !includeHistory && isHistory(name)
+}
+
+// isHistory reports whether a config-dir entry belongs to the usage history
+func isHistory(name string) bool {
+	return strings.HasPrefix(path.Base(filepath.ToSlash(name)), "history")
+}
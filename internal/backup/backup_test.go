@@ -0,0 +1,203 @@
+package backup
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"claudebar/internal/api"
+	"claudebar/internal/config/configtest"
+	"claudebar/internal/history"
+	"claudebar/internal/vault"
+)
+
+func TestEntryPath(t *testing.T) {
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"config.json", true},
+		{"themes/dark.yaml", true},
+		{"themes/../config.json", true},
+		{"", false},
+		{"..", false},
+		{"../evil", false},
+		{"themes/../../evil", false},
+		{"/etc/evil", false},
+		{`..\..\AppData\evil`, false},
+		{`themes\dark.yaml`, false},
+		{`C:\evil`, false},
+	}
+	for _, tt := range tests {
+		if _, ok := entryPath(tt.name); ok != tt.ok {
+			t.Errorf("entryPath(%q) ok = %v, want %v", tt.name, ok, tt.ok)
+		}
+	}
+}
+
+func TestImportSkipsEntriesOutsideConfigDir(t *testing.T) {
+	dir := configtest.Use(t)
+
+	entries := map[string]string{
+		"themes/test.yaml":        "name: test\n",
+		"../outside":              "evil",
+		`..\..\outside-backslash`: "evil",
+		"/abs-outside":            "evil",
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := vault.Seal("passphrase", buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Import(sealed, "passphrase", nil); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "themes", "test.yaml")); err != nil {
+		t.Errorf("valid entry not restored: %v", err)
+	}
+	parent := filepath.Dir(dir)
+	for _, name := range []string{"outside", "outside-backslash", `..\..\outside-backslash`} {
+		for _, d := range []string{parent, filepath.Dir(parent), dir} {
+			if _, err := os.Stat(filepath.Join(d, name)); err == nil {
+				t.Errorf("entry %q was written to %s", name, d)
+			}
+		}
+	}
+	if _, err := os.Stat("/abs-outside"); err == nil {
+		t.Error("absolute entry was written")
+	}
+}
+
+func TestImportWrongPassphrase(t *testing.T) {
+	sealed, err := vault.Seal("right", []byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Import(sealed, "wrong", nil); err == nil {
+		t.Error("Import with the wrong passphrase succeeded")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	dir := configtest.Use(t)
+	if err := os.WriteFile(filepath.Join(dir, "history.jsonl"), []byte("{}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Earlier snapshots are left out
+	if err := os.MkdirAll(filepath.Join(dir, SnapshotDir), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, SnapshotDir, "reset-old.zip"), []byte("zip"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	path, err := Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	names := map[string]bool{}
+	for _, f := range zr.File {
+		names[f.Name] = true
+		if strings.HasPrefix(f.Name, SnapshotDir+"/") {
+			t.Errorf("snapshot contains earlier backup %s", f.Name)
+		}
+	}
+	if !names["config.json"] || !names["history.jsonl"] {
+		t.Errorf("snapshot entries = %v, want config.json and history.jsonl", names)
+	}
+}
+
+func TestSnapshotCopiesOpenSamples(t *testing.T) {
+	dir := configtest.Use(t)
+	tracker := history.NewTracker(dir)
+	tracker.Record(time.Now(), &api.UsageData{FiveHour: api.UsageStat{Utilization: 42}})
+	samples, err := tracker.Samples()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer samples.Close()
+
+	path, err := Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		if f.Name != history.SamplesFile {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		header := make([]byte, 16)
+		_, err = io.ReadFull(rc, header)
+		rc.Close()
+		if err != nil || string(header) != "SQLite format 3\x00" {
+			t.Errorf("snapshot holds %q as the sample database, %v", header, err)
+		}
+		return
+	}
+	t.Errorf("snapshot has no %s", history.SamplesFile)
+}
+
+func TestImportRestoresHistoryThroughTracker(t *testing.T) {
+	dir := configtest.Use(t)
+	reset := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	tracker := history.NewTracker(dir)
+	tracker.Observe(api.UsageStat{Utilization: 20, ResetsAt: reset})
+
+	// The bundle's cycle in progress peaked higher; once restored, the
+	// tracker's next save must not put the old one back
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("history-cycle.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(`{"start":"2026-10-12T12:00:00Z","end":"2026-10-19T12:00:00Z","peak_utilization":80}`))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := vault.Seal("passphrase", buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Import(sealed, "passphrase", tracker); err != nil {
+		t.Fatal(err)
+	}
+
+	tracker.Observe(api.UsageStat{Utilization: 30, ResetsAt: reset})
+	done := tracker.Observe(api.UsageStat{Utilization: 1, ResetsAt: reset.Add(7 * 24 * time.Hour)})
+	if done == nil || done.PeakUtilization != 80 {
+		t.Errorf("cycle after import finished as %+v, want the restored 80%% peak", done)
+	}
+}
@@ -0,0 +1,40 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"claudebar/internal/config"
+)
+
+// SnapshotDir is the config subfolder holding the automatic backups taken
+// before a reset. Bundles and later snapshots leave it out.
+const SnapshotDir = "backups"
+
+// Snapshot saves an unencrypted zip of the whole config directory, history
+// included, to SnapshotDir and returns its path. It holds the session key
+// like config.json does, so it is only readable by the user.
+func Snapshot() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	if err := config.Get().Save(); err != nil {
+		return "", fmt.Errorf("failed to save config: %w", err)
+	}
+
+	data, err := archive(dir, true)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, SnapshotDir), 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, SnapshotDir, "reset-"+time.Now().Format("20060102-150405")+".zip")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write backup: %w", err)
+	}
+	return path, nil
+}
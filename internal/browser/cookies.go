@@ -1,16 +1,21 @@
 package browser
 
 import (
+	"bytes"
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"database/sql"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -19,8 +24,28 @@ var (
 	ErrNoCookieFound   = errors.New("claude.ai session cookie not found")
 	ErrDecryptFailed   = errors.New("failed to decrypt cookie value")
 	ErrBrowserNotFound = errors.New("no supported browser found")
+	ErrWrongUser       = errors.New("browser data belongs to another user")
 )
 
+// Chrome cookie encryption: a 3-byte version prefix, then for v10/v11 a
+// 12-byte AES-GCM nonce and the ciphertext ending in a 16-byte tag, under a
+// 256-bit key. The key in Local State is stored with a "DPAPI" prefix.
+const (
+	cookieVersionLen = 3
+	gcmNonceLen      = 12
+	gcmTagLen        = 16
+	aesKeyLen        = 32
+	hostHashLen      = 32 // SHA-256 of the host, prepended to plaintexts since Chrome 130
+	dpapiPrefix      = "DPAPI"
+)
+
+// minSessionKeyLen is the shortest token accepted after "sk-ant-"
+const minSessionKeyLen = 16
+
+// chromiumProfiles are the profile directories searched in each Chromium
+// browser
+var chromiumProfiles = []string{"Default", "Profile 1", "Profile 2", "Profile 3"}
+
 // browserInfo describes a browser path to search
 type browserInfo struct {
 	name string
@@ -31,93 +56,106 @@ type browserInfo struct {
 // Platform-specific fields are initialized by NewCookieExtractor (per-platform file).
 type CookieExtractor struct {
 	homeDir string
+	domains []string // cookie hosts searched, claude.ai and any added with AddDomain
 	// Platform-specific path roots
 	localAppData string // Windows: %LOCALAPPDATA%, Linux: ~/.config, macOS: ~/Library/Application Support
 	appData      string // Windows: %APPDATA%, Linux: ~/.local/share, macOS: ~/Library/Application Support
 }
 
-// ExtractSessionKey attempts to extract the Claude session key from browsers
-func (c *CookieExtractor) ExtractSessionKey() (string, error) {
-	browsers := c.chromiumBrowsers()
-
-	for _, browser := range browsers {
-		if _, err := os.Stat(browser.path); os.IsNotExist(err) {
-			continue
-		}
-		log.Printf("Trying %s at %s", browser.name, browser.path)
+// defaultDomain is the cookie host always searched
+const defaultDomain = "claude.ai"
 
-		key, err := c.extractFromChromium(browser.name, browser.path)
-		if err == nil && key != "" {
-			log.Printf("Found session key in %s", browser.name)
-			return key, nil
-		}
-		if err != nil {
-			log.Printf("%s: %v", browser.name, err)
-		}
+// AddDomain makes the extractor also look for session cookies set by domain,
+// for accounts served from another hostname than claude.ai
+func (c *CookieExtractor) AddDomain(domain string) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if domain == "" || domain == defaultDomain || slices.Contains(c.domains, domain) {
+		return
 	}
+	c.domains = append(c.domains, domain)
+}
 
-	// Try Firefox
-	key, err := c.extractFromFirefox()
-	if err == nil && key != "" {
-		log.Println("Found session key in Firefox")
-		return key, nil
+// hostFilter returns an SQL condition matching cookies of the searched
+// domains in column, with its arguments
+func (c *CookieExtractor) hostFilter(column string) (string, []any) {
+	conds := []string{column + " LIKE ?"}
+	args := []any{"%" + defaultDomain + "%"}
+	for _, d := range c.domains {
+		conds = append(conds, column+" LIKE ?")
+		args = append(args, "%"+d+"%")
 	}
-
-	return "", ErrNoCookieFound
+	return "(" + strings.Join(conds, " OR ") + ")", args
 }
 
-// extractFromChromium reads the session key from a Chromium-based browser
-func (c *CookieExtractor) extractFromChromium(browserName, userDataPath string) (string, error) {
-	profiles := []string{"Default", "Profile 1", "Profile 2", "Profile 3"}
+// chromiumCookiePath returns the cookie database of a Chromium profile, or ""
+// if the profile has none. Newer versions keep it in the Network directory.
+func chromiumCookiePath(userDataPath, profile string) string {
+	for _, path := range []string{
+		filepath.Join(userDataPath, profile, "Network", "Cookies"),
+		filepath.Join(userDataPath, profile, "Cookies"),
+	} {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
 
+// extractFromChromium reads the session keys of a Chromium-based browser's
+// profiles
+func (c *CookieExtractor) extractFromChromium(ctx context.Context, browserName, userDataPath string) ([]cookieCandidate, error) {
 	encKey, keyErr := c.getChromiumEncryptionKey(userDataPath)
 	if keyErr != nil {
 		log.Printf("%s: encryption key error: %v", browserName, keyErr)
 	}
 
-	for _, profile := range profiles {
-		cookiePath := filepath.Join(userDataPath, profile, "Network", "Cookies")
-		if _, err := os.Stat(cookiePath); os.IsNotExist(err) {
-			cookiePath = filepath.Join(userDataPath, profile, "Cookies")
-			if _, err := os.Stat(cookiePath); os.IsNotExist(err) {
-				continue
-			}
+	var found []cookieCandidate
+	for _, profile := range chromiumProfiles {
+		if ctx.Err() != nil {
+			break
+		}
+		cookiePath := chromiumCookiePath(userDataPath, profile)
+		if cookiePath == "" {
+			continue
 		}
 
-		log.Printf("  Trying profile: %s", profile)
+		log.Printf("  Trying profile: %s/%s", browserName, profile)
 
-		key, err := c.readCookieDB(cookiePath, encKey)
+		key, expires, err := c.readCookieDB(ctx, cookiePath, encKey)
 		if err != nil {
 			log.Printf("  %s/%s: %v", browserName, profile, err)
 			continue
 		}
-		if key != "" {
-			return key, nil
-		}
+		found = append(found, cookieCandidate{key, browserName + "/" + profile, expires})
 	}
 
-	return "", ErrNoCookieFound
+	if len(found) == 0 {
+		return nil, ErrNoCookieFound
+	}
+	return found, nil
 }
 
-// readCookieDB reads the claude.ai session key from a cookie database
-func (c *CookieExtractor) readCookieDB(cookiePath string, encKey []byte) (string, error) {
+// readCookieDB reads the unexpired claude.ai session key with the latest
+// expiry from a cookie database. expires is zero for a cookie without one.
+func (c *CookieExtractor) readCookieDB(ctx context.Context, cookiePath string, encKey []byte) (key string, expires time.Time, err error) {
 	db, cleanup, err := c.openCookieDB(cookiePath)
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 	defer cleanup()
 	defer db.Close()
 
+	filter, args := c.hostFilter("host_key")
 	query := `
-		SELECT name, encrypted_value, value
+		SELECT name, encrypted_value, value, expires_utc
 		FROM cookies
-		WHERE host_key LIKE '%claude.ai%'
-		ORDER BY last_access_utc DESC
+		WHERE ` + filter + `
+		ORDER BY expires_utc DESC, last_access_utc DESC
 	`
 
-	rows, err := db.Query(query)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return "", fmt.Errorf("query error: %w", err)
+		return "", time.Time{}, fmt.Errorf("query error: %w", err)
 	}
 	defer rows.Close()
 
@@ -126,8 +164,14 @@ func (c *CookieExtractor) readCookieDB(cookiePath string, encKey []byte) (string
 		var name string
 		var encryptedValue []byte
 		var plainValue string
+		var expiresUTC int64
 
-		if err := rows.Scan(&name, &encryptedValue, &plainValue); err != nil {
+		if err := rows.Scan(&name, &encryptedValue, &plainValue, &expiresUTC); err != nil {
+			continue
+		}
+		expires := chromeTime(expiresUTC)
+		if expired(expires, time.Now()) {
+			log.Printf("    Skipping %s, expired %s", name, expires.Format(time.DateTime))
 			continue
 		}
 
@@ -135,7 +179,7 @@ func (c *CookieExtractor) readCookieDB(cookiePath string, encKey []byte) (string
 			name, len(encryptedValue), len(plainValue))
 
 		if plainValue != "" && isValidSessionKey(plainValue) {
-			return plainValue, nil
+			return plainValue, expires, nil
 		}
 
 		if len(encryptedValue) > 0 && encKey != nil {
@@ -146,105 +190,191 @@ func (c *CookieExtractor) readCookieDB(cookiePath string, encKey []byte) (string
 				continue
 			}
 			if isValidSessionKey(decrypted) {
-				return decrypted, nil
+				return decrypted, expires, nil
 			}
 			if name == "sessionKey" && len(decrypted) > 20 {
-				return decrypted, nil
+				return decrypted, expires, nil
 			}
 		}
 	}
 
 	if lastErr != nil {
-		return "", lastErr
+		return "", time.Time{}, lastErr
 	}
-	return "", ErrNoCookieFound
+	return "", time.Time{}, ErrNoCookieFound
 }
 
-// extractFromFirefox reads the session key from Firefox cookies
-func (c *CookieExtractor) extractFromFirefox() (string, error) {
-	firefoxDir := c.firefoxProfilesDir()
-	entries, err := os.ReadDir(firefoxDir)
-	if err != nil {
-		return "", err
+// extractFromFirefox reads the session keys of a Firefox installation's
+// profiles
+func (c *CookieExtractor) extractFromFirefox(ctx context.Context, install browserInfo) ([]cookieCandidate, error) {
+	var found []cookieCandidate
+	for _, profile := range firefoxProfiles(install) {
+		if ctx.Err() != nil {
+			break
+		}
+		key, expires, err := c.readFirefoxDB(ctx, profile.path)
+		if err != nil {
+			continue
+		}
+		found = append(found, cookieCandidate{key, profile.name, expires})
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
+	if len(found) == 0 {
+		return nil, ErrNoCookieFound
+	}
+	return found, nil
+}
+
+// firefoxProfiles returns the cookie databases of the profiles in a Firefox
+// profiles directory, named like "Firefox/abcd.default-release": the
+// profiles listed in profiles.ini, which sandboxed installs may name freely,
+// and any directory named like a default profile
+func firefoxProfiles(install browserInfo) []browserInfo {
+	var dirs []string
+	// profiles.ini sits next to the profiles on Linux and one level up,
+	// beside the Profiles directory, on Windows and macOS
+	for _, iniDir := range []string{install.path, filepath.Dir(install.path)} {
+		dirs = append(dirs, iniProfileDirs(iniDir)...)
+	}
+	if entries, err := os.ReadDir(install.path); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() && (strings.Contains(entry.Name(), ".default") || strings.Contains(entry.Name(), "default-release")) {
+				dirs = append(dirs, filepath.Join(install.path, entry.Name()))
+			}
 		}
-		if !strings.Contains(entry.Name(), ".default") && !strings.Contains(entry.Name(), "default-release") {
+	}
+
+	var profiles []browserInfo
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		cookiePath := filepath.Join(dir, "cookies.sqlite")
+		if seen[cookiePath] {
 			continue
 		}
-
-		cookiePath := filepath.Join(firefoxDir, entry.Name(), "cookies.sqlite")
-		if _, err := os.Stat(cookiePath); os.IsNotExist(err) {
+		seen[cookiePath] = true
+		if _, err := os.Stat(cookiePath); err != nil {
 			continue
 		}
+		profiles = append(profiles, browserInfo{install.name + "/" + filepath.Base(dir), cookiePath})
+	}
+	return profiles
+}
 
-		dsn := fmt.Sprintf("file:%s?mode=ro&immutable=1", cookiePath)
-		db, err := sql.Open("sqlite3", dsn)
-		if err != nil {
+// iniProfileDirs returns the profile directories listed in dir/profiles.ini
+func iniProfileDirs(dir string) []string {
+	data, err := os.ReadFile(filepath.Join(dir, "profiles.ini"))
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, line := range strings.Split(string(data), "\n") {
+		path, ok := strings.CutPrefix(strings.TrimSpace(line), "Path=")
+		if !ok || path == "" {
 			continue
 		}
-		defer db.Close()
+		path = filepath.FromSlash(path)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		dirs = append(dirs, filepath.Clean(path))
+	}
+	return dirs
+}
 
-		rows, err := db.Query(`
-			SELECT name, value FROM moz_cookies
-			WHERE host LIKE '%claude.ai%'
-			AND (name = 'sessionKey' OR name LIKE '%session%')
-		`)
-		if err != nil {
+// readFirefoxDB reads the unexpired claude.ai session key with the latest
+// expiry from a Firefox cookie database
+func (c *CookieExtractor) readFirefoxDB(ctx context.Context, cookiePath string) (string, time.Time, error) {
+	dsn := fmt.Sprintf("file:%s?mode=ro&immutable=1", cookiePath)
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer db.Close()
+
+	filter, args := c.hostFilter("host")
+	rows, err := db.QueryContext(ctx, `
+		SELECT name, value, expiry FROM moz_cookies
+		WHERE `+filter+`
+		AND (name = 'sessionKey' OR name LIKE '%session%')
+		ORDER BY expiry DESC
+	`, args...)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, value string
+		var expiry int64
+		if err := rows.Scan(&name, &value, &expiry); err != nil {
 			continue
 		}
-		defer rows.Close()
-
-		for rows.Next() {
-			var name, value string
-			if err := rows.Scan(&name, &value); err != nil {
-				continue
-			}
-			if isValidSessionKey(value) {
-				return value, nil
-			}
+		if expires := firefoxTime(expiry); isValidSessionKey(value) && !expired(expires, time.Now()) {
+			return value, expires, nil
 		}
 	}
-
-	return "", ErrNoCookieFound
+	return "", time.Time{}, ErrNoCookieFound
 }
 
-// decryptChromeValue decrypts a Chrome cookie value
+// decryptChromeValue decrypts a Chrome cookie value. The value comes from a
+// browser profile we don't control, so every length is checked before
+// slicing and the plaintext must look like cookie text.
 func decryptChromeValue(encryptedValue, key []byte) (string, error) {
-	if len(encryptedValue) < 3 {
-		return "", errors.New("encrypted value too short")
+	if len(encryptedValue) < cookieVersionLen {
+		return "", fmt.Errorf("%w: encrypted value too short", ErrDecryptFailed)
 	}
 
-	prefix := string(encryptedValue[:3])
-
-	if prefix == "v20" {
+	var decrypted []byte
+	switch prefix := string(encryptedValue[:cookieVersionLen]); prefix {
+	case "v20":
 		return "", errors.New("cookie uses Chrome App-Bound Encryption (v20) - third-party decryption not possible. Use Claude Code CLI or paste session key manually")
-	}
 
-	if prefix != "v10" && prefix != "v11" {
-		decrypted, err := decryptPlatformKey(encryptedValue)
-		if err != nil {
+	case "v10", "v11":
+		if len(key) != aesKeyLen {
+			return "", fmt.Errorf("%w: %s cookie needs a %d-byte key, have %d bytes", ErrDecryptFailed, prefix, aesKeyLen, len(key))
+		}
+		if len(encryptedValue) < cookieVersionLen+gcmNonceLen+gcmTagLen {
+			return "", fmt.Errorf("%w: encrypted value too short for AES-GCM", ErrDecryptFailed)
+		}
+		nonce := encryptedValue[cookieVersionLen : cookieVersionLen+gcmNonceLen]
+		ciphertext := encryptedValue[cookieVersionLen+gcmNonceLen:]
+
+		var err error
+		if decrypted, err = decryptAESGCM(key, nonce, ciphertext); err != nil {
 			return "", err
 		}
-		return string(decrypted), nil
-	}
 
-	if len(encryptedValue) < 3+12+16 {
-		return "", errors.New("encrypted value too short for AES-GCM")
+	default:
+		var err error
+		if decrypted, err = decryptPlatformKey(encryptedValue); err != nil {
+			return "", err
+		}
 	}
 
-	nonce := encryptedValue[3:15]
-	ciphertext := encryptedValue[15:]
+	return cookieText(decrypted)
+}
 
-	decrypted, err := decryptAESGCM(key, nonce, ciphertext)
-	if err != nil {
-		return "", err
+// cookieText checks decrypted plaintext is a cookie value, dropping the
+// SHA-256 hash of the host that Chrome 130+ puts in front of it
+func cookieText(plain []byte) (string, error) {
+	if len(plain) >= hostHashLen && !isCookieValue(plain) && isCookieValue(plain[hostHashLen:]) {
+		plain = plain[hostHashLen:]
+	}
+	if !isCookieValue(plain) {
+		return "", fmt.Errorf("%w: decrypted value is not cookie text", ErrDecryptFailed)
 	}
+	return string(plain), nil
+}
 
-	return string(decrypted), nil
+// isCookieValue reports whether b only holds the characters allowed in a
+// cookie value (RFC 6265 cookie-octet)
+func isCookieValue(b []byte) bool {
+	for _, c := range b {
+		if c <= ' ' || c >= 0x7f || c == '"' || c == ',' || c == ';' || c == '\\' {
+			return false
+		}
+	}
+	return true
 }
 
 // getChromiumEncryptionKey retrieves the encryption key from Local State
@@ -256,49 +386,77 @@ func (c *CookieExtractor) getChromiumEncryptionKey(userDataPath string) ([]byte,
 		return nil, err
 	}
 
-	keyStr := extractJSONValue(string(data), "encrypted_key")
-	if keyStr == "" {
-		return nil, errors.New("encrypted_key not found in Local State")
+	keyStr, err := extractJSONValue(data, "os_crypt", "encrypted_key")
+	if err != nil {
+		return nil, fmt.Errorf("Local State: %w", err)
 	}
 
 	encryptedKey, err := base64.StdEncoding.DecodeString(keyStr)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("Local State: invalid encrypted_key: %w", err)
 	}
 
-	if len(encryptedKey) < 5 {
+	encryptedKey, ok := bytes.CutPrefix(encryptedKey, []byte(dpapiPrefix))
+	if !ok || len(encryptedKey) == 0 {
 		return nil, errors.New("invalid encrypted key format")
 	}
-	encryptedKey = encryptedKey[5:] // Remove "DPAPI" prefix
 
-	return decryptPlatformKey(encryptedKey)
+	key, err := decryptPlatformKey(encryptedKey)
+	if errors.Is(err, ErrWrongUser) {
+		err = explainOwner(localStatePath, err)
+	}
+	return key, err
 }
 
-// extractJSONValue is a simple JSON value extractor
-func extractJSONValue(json, key string) string {
-	search := `"` + key + `":"`
-	start := strings.Index(json, search)
-	if start == -1 {
-		return ""
+// extractJSONValue returns the string at path in a JSON document, e.g.
+// "os_crypt", "encrypted_key" in Chrome's Local State
+func extractJSONValue(data []byte, path ...string) (string, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return "", err
+	}
+	for i, key := range path {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("%s is not an object", strings.Join(path[:i], "."))
+		}
+		if v, ok = obj[key]; !ok {
+			return "", fmt.Errorf("%s not found", strings.Join(path[:i+1], "."))
+		}
 	}
-	start += len(search)
-	end := strings.Index(json[start:], `"`)
-	if end == -1 {
-		return ""
+	str, ok := v.(string)
+	if !ok || str == "" {
+		return "", fmt.Errorf("%s is not a non-empty string", strings.Join(path, "."))
 	}
-	return json[start : start+end]
+	return str, nil
 }
 
-// isValidSessionKey checks if a string looks like a valid Claude session key
+// isValidSessionKey checks if a string looks like a valid Claude session
+// key: "sk-ant-" followed by a base64url token, or a JWT
 func isValidSessionKey(key string) bool {
-	if strings.HasPrefix(key, "sk-ant-") {
-		return true
+	if rest, ok := strings.CutPrefix(key, "sk-ant-"); ok {
+		return len(rest) >= minSessionKeyLen && isBase64URL(rest)
 	}
 	parts := strings.Split(key, ".")
-	if len(parts) == 3 && len(key) > 100 {
-		return true
+	if len(parts) != 3 || len(key) <= 100 {
+		return false
+	}
+	for _, p := range parts {
+		if p == "" || !isBase64URL(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// isBase64URL reports whether s only uses the base64url alphabet
+func isBase64URL(s string) bool {
+	for _, c := range []byte(s) {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
 	}
-	return false
+	return true
 }
 
 // decryptAESGCM decrypts AES-GCM encrypted data
@@ -315,7 +473,7 @@ func decryptAESGCM(key, nonce, ciphertext []byte) ([]byte, error) {
 
 	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
 	if err != nil {
-		return nil, fmt.Errorf("AES-GCM decryption failed: %w", err)
+		return nil, fmt.Errorf("%w: AES-GCM: %v", ErrDecryptFailed, err)
 	}
 
 	return plaintext, nil
@@ -34,9 +34,9 @@ func (c *CookieExtractor) chromiumBrowsers() []browserInfo {
 	}
 }
 
-// firefoxProfilesDir returns the Firefox profiles directory on macOS
-func (c *CookieExtractor) firefoxProfilesDir() string {
-	return filepath.Join(c.localAppData, "Firefox", "Profiles")
+// firefoxInstalls returns the Firefox profiles directory on macOS
+func (c *CookieExtractor) firefoxInstalls() []browserInfo {
+	return []browserInfo{{"Firefox", filepath.Join(c.localAppData, "Firefox", "Profiles")}}
 }
 
 // openCookieDB tries to open a cookie database on macOS
@@ -96,3 +96,9 @@ func decryptPlatformKey(encrypted []byte) ([]byte, error) {
 	// The key is stored in Keychain under "Chrome Safe Storage"
 	return nil, errors.New("Chrome cookie decryption on macOS requires Keychain access (not yet implemented). Please paste session key manually in Settings")
 }
+
+// explainOwner returns keyErr as is; only Windows ties keys to the user
+// that owns the browser profile
+func explainOwner(path string, keyErr error) error {
+	return keyErr
+}
@@ -10,17 +10,16 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+
+	"claudebar/internal/sandbox"
 )
 
 // NewCookieExtractor creates a new cookie extractor with Linux paths
 func NewCookieExtractor() *CookieExtractor {
 	home, _ := os.UserHomeDir()
 
-	// Use XDG paths
-	configDir := os.Getenv("XDG_CONFIG_HOME")
-	if configDir == "" {
-		configDir = filepath.Join(home, ".config")
-	}
+	// Use XDG paths (the host's when sandboxed, that's where browsers live)
+	configDir := sandbox.HostConfigDir()
 	dataDir := os.Getenv("XDG_DATA_HOME")
 	if dataDir == "" {
 		dataDir = filepath.Join(home, ".local", "share")
@@ -33,19 +32,35 @@ func NewCookieExtractor() *CookieExtractor {
 	}
 }
 
-// chromiumBrowsers returns Chromium browser paths on Linux
+// chromiumBrowsers returns Chromium browser paths on Linux: native
+// packages, then Snap and Flatpak installs, which keep their profiles under
+// ~/snap and ~/.var/app
 func (c *CookieExtractor) chromiumBrowsers() []browserInfo {
+	snap := filepath.Join(c.homeDir, "snap")
+	flatpak := filepath.Join(c.homeDir, ".var", "app")
 	return []browserInfo{
 		{"Chrome", filepath.Join(c.localAppData, "google-chrome")},
 		{"Chromium", filepath.Join(c.localAppData, "chromium")},
 		{"Brave", filepath.Join(c.localAppData, "BraveSoftware", "Brave-Browser")},
 		{"Edge", filepath.Join(c.localAppData, "microsoft-edge")},
+		{"Chromium (Snap)", filepath.Join(snap, "chromium", "common", "chromium")},
+		{"Brave (Snap)", filepath.Join(snap, "brave", "current", ".config", "BraveSoftware", "Brave-Browser")},
+		{"Chrome (Flatpak)", filepath.Join(flatpak, "com.google.Chrome", "config", "google-chrome")},
+		{"Chromium (Flatpak)", filepath.Join(flatpak, "org.chromium.Chromium", "config", "chromium")},
+		{"Ungoogled Chromium (Flatpak)", filepath.Join(flatpak, "io.github.ungoogled_software.ungoogled_chromium", "config", "chromium")},
+		{"Brave (Flatpak)", filepath.Join(flatpak, "com.brave.Browser", "config", "BraveSoftware", "Brave-Browser")},
+		{"Edge (Flatpak)", filepath.Join(flatpak, "com.microsoft.Edge", "config", "microsoft-edge")},
 	}
 }
 
-// firefoxProfilesDir returns the Firefox profiles directory on Linux
-func (c *CookieExtractor) firefoxProfilesDir() string {
-	return filepath.Join(c.homeDir, ".mozilla", "firefox")
+// firefoxInstalls returns the Firefox profiles directories on Linux: the
+// native package, Snap and Flatpak
+func (c *CookieExtractor) firefoxInstalls() []browserInfo {
+	return []browserInfo{
+		{"Firefox", filepath.Join(c.homeDir, ".mozilla", "firefox")},
+		{"Firefox (Snap)", filepath.Join(c.homeDir, "snap", "firefox", "common", ".mozilla", "firefox")},
+		{"Firefox (Flatpak)", filepath.Join(c.homeDir, ".var", "app", "org.mozilla.firefox", ".mozilla", "firefox")},
+	}
 }
 
 // openCookieDB tries to open a cookie database on Linux
@@ -105,3 +120,9 @@ func decryptPlatformKey(encrypted []byte) ([]byte, error) {
 	// For now, on Linux Chrome cookies can't be decrypted without keyring access
 	return nil, errors.New("Chrome cookie decryption on Linux requires libsecret/GNOME Keyring (not yet implemented). Please paste session key manually in Settings")
 }
+
+// explainOwner returns keyErr as is; only Windows ties keys to the user
+// that owns the browser profile
+func explainOwner(path string, keyErr error) error {
+	return keyErr
+}
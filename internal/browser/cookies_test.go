@@ -0,0 +1,206 @@
+package browser
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+const testSessionKey = "sk-ant-REDACTED"
+
+// encryptChromeValue encrypts plaintext the way Chrome stores v10 cookies
+func encryptChromeValue(t testing.TB, plaintext []byte) []byte {
+	t.Helper()
+	block, err := aes.NewCipher(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := []byte("nonce-12byte")
+	return append([]byte("v10"+string(nonce)), gcm.Seal(nil, nonce, plaintext, nil)...)
+}
+
+func TestDecryptChromeValue(t *testing.T) {
+	hash := sha256.Sum256([]byte(".claude.ai"))
+	tests := []struct {
+		name    string
+		value   []byte
+		want    string
+		wantErr bool
+	}{
+		{"v10", encryptChromeValue(t, []byte(testSessionKey)), testSessionKey, false},
+		{"v10 with host hash", encryptChromeValue(t, append(hash[:], testSessionKey...)), testSessionKey, false},
+		{"binary plaintext", encryptChromeValue(t, []byte{0, 1, 2, 0xff}), "", true},
+		{"app-bound v20", append([]byte("v20"), make([]byte, 40)...), "", true},
+		{"truncated", []byte("v1"), "", true},
+		{"short AES-GCM", []byte("v10nonce"), "", true},
+		{"tampered", append(encryptChromeValue(t, []byte(testSessionKey)), 'x'), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decryptChromeValue(tt.value, testKey)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("decryptChromeValue = %q, %v; want %q, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := decryptChromeValue(encryptChromeValue(t, []byte(testSessionKey)), testKey[:16]); err == nil {
+		t.Error("decryptChromeValue accepted a key of the wrong size")
+	}
+}
+
+func TestExtractJSONValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{"local state", `{"browser":{},"os_crypt":{"audit_enabled":true,"encrypted_key":"RFBBUEl4eXo="}}`, "RFBBUEl4eXo=", false},
+		{"spaced", "{\n  \"os_crypt\" : { \"encrypted_key\" : \"abc\" }\n}", "abc", false},
+		{"escaped", `{"os_crypt":{"encrypted_key":"a\/b+c"}}`, "a/b+c", false},
+		{"key elsewhere", `{"profile":{"encrypted_key":"decoy"},"os_crypt":{}}`, "", true},
+		{"not a string", `{"os_crypt":{"encrypted_key":42}}`, "", true},
+		{"empty", `{"os_crypt":{"encrypted_key":""}}`, "", true},
+		{"not an object", `{"os_crypt":"encrypted_key"}`, "", true},
+		{"truncated", `{"os_crypt":{"encrypted_key":"abc`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractJSONValue([]byte(tt.data), "os_crypt", "encrypted_key")
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("extractJSONValue = %q, %v; want %q, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsValidSessionKey(t *testing.T) {
+	jwt := strings.Repeat("a", 40) + "." + strings.Repeat("b", 40) + "." + strings.Repeat("c", 40)
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{testSessionKey, true},
+		{jwt, true},
+		{"sk-ant-", false},
+		{"sk-ant-short", false},
+		{"sk-ant-sid01-" + strings.Repeat("x", 20) + "; Path=/", false},
+		{"sk-ant-sid01-" + strings.Repeat("x", 20) + "\n", false},
+		{strings.Repeat("a", 101) + "..", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isValidSessionKey(tt.key); got != tt.want {
+			t.Errorf("isValidSessionKey(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func FuzzDecryptChromeValue(f *testing.F) {
+	f.Add(encryptChromeValue(f, []byte(testSessionKey)))
+	f.Add([]byte("v20garbage-garbage-garbage-garbage"))
+	f.Add([]byte("v11"))
+	f.Add([]byte{})
+	f.Fuzz(func(t *testing.T, value []byte) {
+		got, err := decryptChromeValue(value, testKey)
+		if err == nil && !isCookieValue([]byte(got)) {
+			t.Errorf("decryptChromeValue returned non-cookie text %q", got)
+		}
+	})
+}
+
+// Whatever cookie text is encrypted must decrypt to itself
+func FuzzDecryptChromeValueRoundTrip(f *testing.F) {
+	f.Add([]byte(testSessionKey))
+	f.Add([]byte("plain"))
+	f.Fuzz(func(t *testing.T, plaintext []byte) {
+		if !isCookieValue(plaintext) {
+			t.Skip()
+		}
+		got, err := decryptChromeValue(encryptChromeValue(t, plaintext), testKey)
+		if err != nil || got != string(plaintext) {
+			t.Errorf("round trip of %q = %q, %v", plaintext, got, err)
+		}
+	})
+}
+
+func FuzzExtractJSONValue(f *testing.F) {
+	f.Add([]byte(`{"os_crypt":{"encrypted_key":"RFBBUEl4eXo="}}`))
+	f.Add([]byte(`{"os_crypt":{"encrypted_key":"\ud800"}}`))
+	f.Add([]byte(`[{"os_crypt":null}]`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		got, err := extractJSONValue(data, "os_crypt", "encrypted_key")
+		if err != nil {
+			return
+		}
+		// A value that was found must survive a trip through encoding/json
+		doc, _ := json.Marshal(map[string]any{"os_crypt": map[string]string{"encrypted_key": got}})
+		again, err := extractJSONValue(doc, "os_crypt", "encrypted_key")
+		if err != nil || again != got {
+			t.Errorf("re-extracting %q gave %q, %v", got, again, err)
+		}
+	})
+}
+
+func FuzzIsValidSessionKey(f *testing.F) {
+	f.Add(testSessionKey)
+	f.Add("sk-ant-")
+	f.Add("a.b.c")
+	f.Fuzz(func(t *testing.T, key string) {
+		if isValidSessionKey(key) && !isCookieValue([]byte(key)) {
+			t.Errorf("isValidSessionKey accepted %q, which can't be sent as a cookie", key)
+		}
+	})
+}
+
+func TestHostFilter(t *testing.T) {
+	c := &CookieExtractor{}
+	c.AddDomain("claude.ai")
+	c.AddDomain(" Claude.Example.com ")
+	c.AddDomain("claude.example.com")
+
+	filter, args := c.hostFilter("host_key")
+	if want := "(host_key LIKE ? OR host_key LIKE ?)"; filter != want {
+		t.Errorf("filter = %q, want %q", filter, want)
+	}
+	if len(args) != 2 || args[0] != "%claude.ai%" || args[1] != "%claude.example.com%" {
+		t.Errorf("args = %v, want claude.ai and claude.example.com", args)
+	}
+}
+
+func TestFirefoxProfiles(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"custom", "abcd.default-release", "unlisted", "nocookies.default"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if dir != "nocookies.default" {
+			os.WriteFile(filepath.Join(root, dir, "cookies.sqlite"), nil, 0o600)
+		}
+	}
+	ini := "[Profile1]\nName=work\nIsRelative=1\nPath=custom\n\n[Profile0]\nName=default\nIsRelative=1\nPath=abcd.default-release\nDefault=1\n"
+	if err := os.WriteFile(filepath.Join(root, "profiles.ini"), []byte(ini), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, p := range firefoxProfiles(browserInfo{"Firefox (Snap)", root}) {
+		names = append(names, p.name)
+	}
+	want := []string{"Firefox (Snap)/custom", "Firefox (Snap)/abcd.default-release"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("profiles = %v, want %v", names, want)
+	}
+}
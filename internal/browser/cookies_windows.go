@@ -3,6 +3,7 @@
 package browser
 
 import (
+	"bytes"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -14,12 +15,38 @@ import (
 	"unsafe"
 )
 
-// Windows DPAPI functions
+// Windows DPAPI, DPAPI-NG (CNG) and security functions
 var (
-	crypt32         = syscall.NewLazyDLL("crypt32.dll")
-	procDecryptData = crypt32.NewProc("CryptUnprotectData")
+	crypt32                   = syscall.NewLazyDLL("crypt32.dll")
+	kernel32                  = syscall.NewLazyDLL("kernel32.dll")
+	ncrypt                    = syscall.NewLazyDLL("ncrypt.dll")
+	advapi32                  = syscall.NewLazyDLL("advapi32.dll")
+	procDecryptData           = crypt32.NewProc("CryptUnprotectData")
+	procLocalFree             = kernel32.NewProc("LocalFree")
+	procNCryptUnprotectSecret = ncrypt.NewProc("NCryptUnprotectSecret")
+	procGetNamedSecurityInfo  = advapi32.NewProc("GetNamedSecurityInfoW")
 )
 
+// Error codes and flags used below
+const (
+	nteBadKeyState   = 0x8009000B // key not valid for use in specified state
+	nteBadData       = 0x80090005
+	errorInvalidData = 13
+	ncryptSilentFlag = 0x40
+
+	tokenElevation           = 20 // TOKEN_INFORMATION_CLASS
+	seFileObject             = 1
+	ownerSecurityInformation = 1
+)
+
+// dpapiBlobHeader starts every classic DPAPI blob: version 1 and the
+// provider GUID {df9d8cd0-1501-11d1-8c7a-00c04fc297eb}. Anything else may be
+// a DPAPI-NG (CNG) protected secret.
+var dpapiBlobHeader = []byte{
+	0x01, 0x00, 0x00, 0x00,
+	0xd0, 0x8c, 0x9d, 0xdf, 0x01, 0x15, 0xd1, 0x11, 0x8c, 0x7a, 0x00, 0xc0, 0x4f, 0xc2, 0x97, 0xeb,
+}
+
 type dataBlob struct {
 	cbData uint32
 	pbData *byte
@@ -44,9 +71,9 @@ func (c *CookieExtractor) chromiumBrowsers() []browserInfo {
 	}
 }
 
-// firefoxProfilesDir returns the Firefox profiles directory on Windows
-func (c *CookieExtractor) firefoxProfilesDir() string {
-	return filepath.Join(c.appData, "Mozilla", "Firefox", "Profiles")
+// firefoxInstalls returns the Firefox profiles directory on Windows
+func (c *CookieExtractor) firefoxInstalls() []browserInfo {
+	return []browserInfo{{"Firefox", filepath.Join(c.appData, "Mozilla", "Firefox", "Profiles")}}
 }
 
 // openCookieDB tries multiple methods to open a locked cookie database on Windows
@@ -100,12 +127,30 @@ func (c *CookieExtractor) openCookieDB(cookiePath string) (*sql.DB, func(), erro
 	return nil, noop, fmt.Errorf("all methods failed to open cookie DB at %s", cookiePath)
 }
 
-// decryptPlatformKey decrypts an encrypted key using Windows DPAPI
+// decryptPlatformKey decrypts an encrypted key using Windows DPAPI, or
+// DPAPI-NG for secrets protected with a CNG descriptor
 func decryptPlatformKey(encrypted []byte) ([]byte, error) {
 	if len(encrypted) == 0 {
 		return nil, errors.New("empty data")
 	}
 
+	if !bytes.HasPrefix(encrypted, dpapiBlobHeader) {
+		decrypted, err := ncryptUnprotect(encrypted)
+		if err == nil {
+			return decrypted, nil
+		}
+		log.Printf("    Not a DPAPI blob and DPAPI-NG failed: %v", err)
+	}
+
+	decrypted, err := cryptUnprotect(encrypted)
+	if err != nil {
+		return nil, dpapiError(err)
+	}
+	return decrypted, nil
+}
+
+// cryptUnprotect decrypts a classic DPAPI blob with CryptUnprotectData
+func cryptUnprotect(encrypted []byte) ([]byte, error) {
 	var inBlob dataBlob
 	inBlob.cbData = uint32(len(encrypted))
 	inBlob.pbData = &encrypted[0]
@@ -119,15 +164,142 @@ func decryptPlatformKey(encrypted []byte) ([]byte, error) {
 	)
 
 	if ret == 0 {
-		return nil, fmt.Errorf("DPAPI decryption failed: %w", err)
+		return nil, err
+	}
+	if outBlob.pbData == nil {
+		return nil, fmt.Errorf("%w: DPAPI returned no data", ErrDecryptFailed)
 	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(outBlob.pbData)))
 
 	decrypted := make([]byte, outBlob.cbData)
 	copy(decrypted, unsafe.Slice(outBlob.pbData, outBlob.cbData))
+	return decrypted, nil
+}
+
+// ncryptUnprotect decrypts a DPAPI-NG protected secret with
+// NCryptUnprotectSecret
+func ncryptUnprotect(encrypted []byte) ([]byte, error) {
+	if err := procNCryptUnprotectSecret.Find(); err != nil {
+		return nil, err
+	}
 
-	kernel32 := syscall.NewLazyDLL("kernel32.dll")
-	localFree := kernel32.NewProc("LocalFree")
-	localFree.Call(uintptr(unsafe.Pointer(outBlob.pbData)))
+	var out *byte
+	var outLen uint32
+	status, _, _ := procNCryptUnprotectSecret.Call(
+		0, // no descriptor handle needed back
+		ncryptSilentFlag,
+		uintptr(unsafe.Pointer(&encrypted[0])),
+		uintptr(len(encrypted)),
+		0, 0, // default allocator (LocalFree), no window
+		uintptr(unsafe.Pointer(&out)),
+		uintptr(unsafe.Pointer(&outLen)),
+	)
+	if status != 0 {
+		return nil, fmt.Errorf("%w: DPAPI-NG: %v", ErrDecryptFailed, syscall.Errno(status))
+	}
+	if out == nil {
+		return nil, fmt.Errorf("%w: DPAPI-NG returned no data", ErrDecryptFailed)
+	}
+	defer procLocalFree.Call(uintptr(unsafe.Pointer(out)))
 
+	decrypted := make([]byte, outLen)
+	copy(decrypted, unsafe.Slice(out, outLen))
 	return decrypted, nil
 }
+
+// dpapiError turns a CryptUnprotectData failure into an error saying what
+// to do about it
+func dpapiError(err error) error {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return fmt.Errorf("%w: DPAPI: %v", ErrDecryptFailed, err)
+	}
+
+	switch uint32(errno) {
+	case nteBadKeyState:
+		hint := "the browser data was encrypted by another Windows user, or before the password was reset"
+		if processElevated() {
+			hint += "; ClaudeBar is running as administrator, so start it normally as the user who runs the browser"
+		} else {
+			hint += "; run ClaudeBar as the same user as the browser"
+		}
+		return fmt.Errorf("%w: %s (%v)", ErrWrongUser, hint, err)
+	case nteBadData, errorInvalidData:
+		return fmt.Errorf("%w: DPAPI: the encrypted key is damaged or not DPAPI data; reopen the browser so it rewrites Local State (%v)", ErrDecryptFailed, err)
+	default:
+		return fmt.Errorf("%w: DPAPI: %v", ErrDecryptFailed, err)
+	}
+}
+
+// processElevated reports whether ClaudeBar runs with an elevated token
+func processElevated() bool {
+	token, err := syscall.OpenCurrentProcessToken()
+	if err != nil {
+		return false
+	}
+	defer token.Close()
+
+	var elevated, n uint32
+	err = syscall.GetTokenInformation(token, tokenElevation, (*byte)(unsafe.Pointer(&elevated)), uint32(unsafe.Sizeof(elevated)), &n)
+	return err == nil && elevated != 0
+}
+
+// explainOwner adds the owners of a file and of the ClaudeBar process to a
+// key error when they differ, e.g. because the browser runs as another user
+func explainOwner(path string, keyErr error) error {
+	owner, err := fileOwner(path)
+	if err != nil {
+		return keyErr
+	}
+	token, err := syscall.OpenCurrentProcessToken()
+	if err != nil {
+		return keyErr
+	}
+	defer token.Close()
+	user, err := token.GetTokenUser()
+	if err != nil {
+		return keyErr
+	}
+
+	ownerID, err1 := owner.String()
+	userID, err2 := user.User.Sid.String()
+	if err1 != nil || err2 != nil || ownerID == userID {
+		return keyErr
+	}
+	return fmt.Errorf("%w; %s belongs to %s but ClaudeBar runs as %s", keyErr, filepath.Base(path), accountName(owner), accountName(user.User.Sid))
+}
+
+// fileOwner returns the owner SID of a file
+func fileOwner(path string) (*syscall.SID, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	var owner *syscall.SID
+	var sd uintptr
+	ret, _, _ := procGetNamedSecurityInfo.Call(
+		uintptr(unsafe.Pointer(name)),
+		seFileObject,
+		ownerSecurityInformation,
+		uintptr(unsafe.Pointer(&owner)),
+		0, 0, 0,
+		uintptr(unsafe.Pointer(&sd)),
+	)
+	if ret != 0 {
+		return nil, syscall.Errno(ret)
+	}
+	defer procLocalFree.Call(sd)
+
+	// The SID points into the security descriptor, so copy it before freeing
+	return owner.Copy()
+}
+
+// accountName returns DOMAIN\user for a SID, or the SID string
+func accountName(sid *syscall.SID) string {
+	account, domain, _, err := sid.LookupAccount("")
+	if err != nil {
+		s, _ := sid.String()
+		return s
+	}
+	return domain + `\` + account
+}
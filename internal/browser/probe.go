@@ -0,0 +1,66 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ProbeResult is the outcome of looking for the session cookie in one place
+type ProbeResult struct {
+	Source  string    // browser and profile, e.g. "Chrome/Profile 1"
+	Path    string    // cookie database, or the browser directory if it has none
+	Key     string    // session key, when one was found
+	Expires time.Time // expiry of the key's cookie, zero if it has none
+	Err     error
+}
+
+// Probe looks for the session cookie in every browser profile, one after
+// another, without stopping at the first hit. Browsers that aren't installed
+// are reported with ErrBrowserNotFound.
+func (c *CookieExtractor) Probe() []ProbeResult {
+	ctx := context.Background()
+	var results []ProbeResult
+	for _, b := range c.chromiumBrowsers() {
+		if _, err := os.Stat(b.path); err != nil {
+			results = append(results, ProbeResult{Source: b.name, Path: b.path, Err: ErrBrowserNotFound})
+			continue
+		}
+
+		encKey, keyErr := c.getChromiumEncryptionKey(b.path)
+		found := false
+		for _, profile := range chromiumProfiles {
+			cookiePath := chromiumCookiePath(b.path, profile)
+			if cookiePath == "" {
+				continue
+			}
+			found = true
+			r := ProbeResult{Source: b.name + "/" + profile, Path: cookiePath}
+			if r.Key, r.Expires, r.Err = c.readCookieDB(ctx, cookiePath, encKey); r.Err != nil && keyErr != nil {
+				r.Err = fmt.Errorf("%w (encryption key: %v)", r.Err, keyErr)
+			}
+			results = append(results, r)
+		}
+		if !found {
+			results = append(results, ProbeResult{Source: b.name, Path: b.path, Err: fmt.Errorf("%w: no profile has a cookie database", ErrNoCookieFound)})
+		}
+	}
+
+	for _, install := range c.firefoxInstalls() {
+		if _, err := os.Stat(install.path); err != nil {
+			results = append(results, ProbeResult{Source: install.name, Path: install.path, Err: ErrBrowserNotFound})
+			continue
+		}
+		profiles := firefoxProfiles(install)
+		if len(profiles) == 0 {
+			results = append(results, ProbeResult{Source: install.name, Path: install.path, Err: fmt.Errorf("%w: no profile has a cookie database", ErrNoCookieFound)})
+		}
+		for _, profile := range profiles {
+			r := ProbeResult{Source: profile.name, Path: profile.path}
+			r.Key, r.Expires, r.Err = c.readFirefoxDB(ctx, profile.path)
+			results = append(results, r)
+		}
+	}
+	return results
+}
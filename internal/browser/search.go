@@ -0,0 +1,131 @@
+package browser
+
+import (
+	"context"
+	"log"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// freshFor is how long a found key must stay valid for the search to stop
+// without waiting for the remaining browsers
+const freshFor = 24 * time.Hour
+
+// cookieCandidate is a session key found in a browser profile
+type cookieCandidate struct {
+	key     string
+	source  string    // browser and profile, e.g. "Chrome/Default"
+	expires time.Time // zero for cookies without an expiry
+}
+
+// betterThan reports whether c should be used rather than other: any key
+// beats none, and a later expiry beats an earlier or unknown one
+func (c cookieCandidate) betterThan(other cookieCandidate) bool {
+	if c.key == "" {
+		return false
+	}
+	if other.key == "" {
+		return true
+	}
+	return c.expires.After(other.expires)
+}
+
+// fresh reports whether the key stays valid for at least freshFor after now
+func (c cookieCandidate) fresh(now time.Time) bool {
+	return !c.expires.IsZero() && c.expires.Sub(now) >= freshFor
+}
+
+// SessionKeys searches all browsers at once for unexpired Claude session
+// keys and returns them latest expiry first, at most one per profile. The
+// search stops early once a key is found that stays valid for at least a day.
+func (c *CookieExtractor) SessionKeys() ([]string, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	found := make(chan []cookieCandidate)
+	var wg sync.WaitGroup
+	search := func(name, path string, extract func() ([]cookieCandidate, error)) {
+		if _, err := os.Stat(path); err != nil {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Printf("Trying %s at %s", name, path)
+			cands, err := extract()
+			if err != nil {
+				log.Printf("%s: %v", name, err)
+				return
+			}
+			found <- cands
+		}()
+	}
+	for _, b := range c.chromiumBrowsers() {
+		search(b.name, b.path, func() ([]cookieCandidate, error) {
+			return c.extractFromChromium(ctx, b.name, b.path)
+		})
+	}
+	for _, install := range c.firefoxInstalls() {
+		search(install.name, install.path, func() ([]cookieCandidate, error) {
+			return c.extractFromFirefox(ctx, install)
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(found)
+	}()
+
+	var all []cookieCandidate
+	for cands := range found {
+		for _, cand := range cands {
+			all = append(all, cand)
+			if cand.fresh(time.Now()) {
+				cancel() // the remaining searches return what they have so far
+			}
+		}
+	}
+	if len(all) == 0 {
+		return nil, ErrNoCookieFound
+	}
+
+	sort.SliceStable(all, func(i, j int) bool { return all[i].betterThan(all[j]) })
+	keys := make([]string, 0, len(all))
+	for _, cand := range all {
+		if !slices.Contains(keys, cand.key) {
+			log.Printf("Found session key in %s", cand.source)
+			keys = append(keys, cand.key)
+		}
+	}
+	return keys, nil
+}
+
+// expired reports whether a cookie expiry lies before now; zero never expires
+func expired(expires, now time.Time) bool {
+	return !expires.IsZero() && expires.Before(now)
+}
+
+// chromeTime converts a Chrome cookie time, microseconds since 1601-01-01
+// UTC, to a time. Zero means the cookie has no expiry.
+func chromeTime(us int64) time.Time {
+	const epochDelta = 11644473600 // seconds from 1601 to 1970
+	if us <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(us/1e6-epochDelta, us%1e6*1e3)
+}
+
+// firefoxTime converts a Firefox cookie expiry to a time. It is in seconds
+// since 1970, or milliseconds in newer versions; zero means no expiry.
+func firefoxTime(v int64) time.Time {
+	switch {
+	case v <= 0:
+		return time.Time{}
+	case v > 1e11: // later than the year 5000 in seconds, so milliseconds
+		return time.UnixMilli(v)
+	default:
+		return time.Unix(v, 0)
+	}
+}
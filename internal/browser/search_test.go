@@ -0,0 +1,63 @@
+package browser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCookieTimes(t *testing.T) {
+	want := time.Date(2026, 3, 2, 14, 30, 0, 0, time.UTC)
+	if got := chromeTime((want.Unix() + 11644473600) * 1e6); !got.Equal(want) {
+		t.Errorf("chromeTime = %v, want %v", got, want)
+	}
+	if got := firefoxTime(want.Unix()); !got.Equal(want) {
+		t.Errorf("firefoxTime(seconds) = %v, want %v", got, want)
+	}
+	if got := firefoxTime(want.UnixMilli()); !got.Equal(want) {
+		t.Errorf("firefoxTime(milliseconds) = %v, want %v", got, want)
+	}
+	if !chromeTime(0).IsZero() || !firefoxTime(0).IsZero() {
+		t.Error("a zero expiry should convert to the zero time")
+	}
+}
+
+func TestCandidateBetterThan(t *testing.T) {
+	now := time.Now()
+	none := cookieCandidate{}
+	session := cookieCandidate{key: "a"}
+	soon := cookieCandidate{key: "b", expires: now.Add(time.Hour)}
+	later := cookieCandidate{key: "c", expires: now.Add(30 * 24 * time.Hour)}
+
+	tests := []struct {
+		name        string
+		c, other    cookieCandidate
+		want, fresh bool
+	}{
+		{"key beats none", session, none, true, false},
+		{"none never wins", none, session, false, false},
+		{"expiry beats session cookie", soon, session, true, false},
+		{"later expiry wins", later, soon, true, true},
+		{"earlier expiry loses", soon, later, false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.c.betterThan(tt.other); got != tt.want {
+			t.Errorf("%s: betterThan = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := tt.c.fresh(now); got != tt.fresh {
+			t.Errorf("%s: fresh = %v, want %v", tt.name, got, tt.fresh)
+		}
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Now()
+	if expired(time.Time{}, now) {
+		t.Error("a cookie without expiry counts as expired")
+	}
+	if !expired(now.Add(-time.Minute), now) {
+		t.Error("a cookie that expired a minute ago is not expired")
+	}
+	if expired(now.Add(time.Minute), now) {
+		t.Error("a cookie expiring in a minute counts as expired")
+	}
+}
@@ -0,0 +1,99 @@
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+var ErrInvalidDay = errors.New("unknown weekday")
+
+// Plan is a linear pace toward a weekly usage target, e.g. "no more than 80%
+// before Friday". Percentages are of the weekly limit.
+type Plan struct {
+	Target      float64   // usage allowed by Deadline
+	Deadline    time.Time // end of the target day, or the weekly reset if sooner
+	Planned     float64   // where usage should be now to hit Target exactly
+	Actual      float64   // current weekly usage
+	DailyBudget float64   // usage left per day until Deadline
+}
+
+// Over returns how far usage is ahead of plan in percentage points; negative
+// means under plan
+func (p *Plan) Over() float64 {
+	return p.Actual - p.Planned
+}
+
+// String summarises the plan for the overlay, e.g.
+// "Plan 80% by Fri: 9.5%/day, 4% under"
+func (p *Plan) String() string {
+	pace := "on plan"
+	if over := p.Over(); over >= 0.5 {
+		pace = fmt.Sprintf("%.0f%% over", over)
+	} else if over <= -0.5 {
+		pace = fmt.Sprintf("%.0f%% under", -over)
+	}
+	return fmt.Sprintf("Plan %.0f%% by %s: %.1f%%/day, %s",
+		p.Target, p.Deadline.Add(-time.Minute).Format("Mon"), p.DailyBudget, pace)
+}
+
+// ParseDay parses a weekday name such as "friday" or "Fri"
+func ParseDay(name string) (time.Weekday, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if len(name) >= 3 {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.HasPrefix(strings.ToLower(d.String()), name) {
+				return d, nil
+			}
+		}
+	}
+	return time.Sunday, fmt.Errorf("%w: %q", ErrInvalidDay, name)
+}
+
+// New computes the plan for reaching target by the end of day within the
+// weekly window of the given length that ends at weekly.ResetsAt. Returns nil
+// when there is no window to plan against.
+func New(target float64, day time.Weekday, weekly api.UsageStat, window time.Duration, now time.Time) *Plan {
+	if weekly.ResetsAt.IsZero() || !now.Before(weekly.ResetsAt) {
+		return nil
+	}
+
+	start := weekly.ResetsAt.Add(-window)
+	if start.After(now) {
+		start = now
+	}
+
+	// The next end of the target day; once it has passed for this window the
+	// target applies to the reset instead
+	y, m, d := now.Date()
+	deadline := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	for deadline.Weekday() != day || !deadline.AddDate(0, 0, 1).After(now) {
+		deadline = deadline.AddDate(0, 0, 1)
+	}
+	deadline = deadline.AddDate(0, 0, 1)
+	if deadline.After(weekly.ResetsAt) {
+		deadline = weekly.ResetsAt
+	}
+
+	elapsed := now.Sub(start).Hours()
+	total := deadline.Sub(start).Hours()
+	planned := target
+	if total > 0 {
+		planned = target * math.Min(elapsed/total, 1)
+	}
+
+	// Spread what's left over the remaining days, counting a partial day as one
+	days := math.Max(deadline.Sub(now).Hours()/24, 1)
+
+	return &Plan{
+		Target:      target,
+		Deadline:    deadline,
+		Planned:     planned,
+		Actual:      weekly.Utilization,
+		DailyBudget: math.Max(target-weekly.Utilization, 0) / days,
+	}
+}
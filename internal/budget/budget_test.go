@@ -0,0 +1,207 @@
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"claudebar/internal/api"
+	"claudebar/internal/claudecode"
+)
+
+const weeklyWindow = 7 * 24 * time.Hour
+
+func TestNew(t *testing.T) {
+	// Friday noon, four days into a window that resets Monday noon
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	reset := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	saturday := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	monday := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		day          time.Weekday
+		actual       float64
+		wantDeadline time.Time
+		wantPlanned  float64
+		wantDaily    float64
+	}{
+		// 96h of 108h elapsed; half a day left counts as one
+		{"target day today", time.Friday, 50, saturday, 80 * 96.0 / 108, 30},
+		// 96h of 156h elapsed, 2.5 days left
+		{"target day ahead", time.Sunday, 40, monday, 80 * 96.0 / 156, 40 / 2.5},
+		// Wednesday has passed in this window, so the reset is the deadline
+		{"target day passed", time.Wednesday, 50, reset, 80 * 96.0 / 168, 10},
+		{"over target", time.Friday, 90, saturday, 80 * 96.0 / 108, 0},
+	}
+	for _, tt := range tests {
+		p := New(80, tt.day, api.UsageStat{Utilization: tt.actual, ResetsAt: reset}, weeklyWindow, now)
+		if p == nil {
+			t.Errorf("%s: New returned nil", tt.name)
+			continue
+		}
+		if !p.Deadline.Equal(tt.wantDeadline) {
+			t.Errorf("%s: Deadline = %v, want %v", tt.name, p.Deadline, tt.wantDeadline)
+		}
+		if math.Abs(p.Planned-tt.wantPlanned) > 0.01 {
+			t.Errorf("%s: Planned = %.2f, want %.2f", tt.name, p.Planned, tt.wantPlanned)
+		}
+		if math.Abs(p.DailyBudget-tt.wantDaily) > 0.01 {
+			t.Errorf("%s: DailyBudget = %.2f, want %.2f", tt.name, p.DailyBudget, tt.wantDaily)
+		}
+		if p.Actual != tt.actual || p.Target != 80 {
+			t.Errorf("%s: Actual/Target = %v/%v, want %v/80", tt.name, p.Actual, p.Target, tt.actual)
+		}
+	}
+}
+
+func TestNewWithoutWindow(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	for _, resets := range []time.Time{{}, now, now.Add(-time.Hour)} {
+		if p := New(80, time.Friday, api.UsageStat{ResetsAt: resets}, weeklyWindow, now); p != nil {
+			t.Errorf("New with reset %v = %+v, want nil", resets, p)
+		}
+	}
+}
+
+func TestPlanString(t *testing.T) {
+	deadline := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		planned, actual float64
+		want            string
+	}{
+		{60, 64, "Plan 80% by Fri: 5.0%/day, 4% over"},
+		{60, 50, "Plan 80% by Fri: 5.0%/day, 10% under"},
+		{60, 60.3, "Plan 80% by Fri: 5.0%/day, on plan"},
+	}
+	for _, tt := range tests {
+		p := Plan{Target: 80, Deadline: deadline, Planned: tt.planned, Actual: tt.actual, DailyBudget: 5}
+		if got := p.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestParseDay(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Weekday
+		ok   bool
+	}{
+		{"friday", time.Friday, true},
+		{" Fri ", time.Friday, true},
+		{"THU", time.Thursday, true},
+		{"fr", 0, false},
+		{"someday", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseDay(tt.in)
+		if (err == nil) != tt.ok || (tt.ok && got != tt.want) {
+			t.Errorf("ParseDay(%q) = %v, %v; want %v, ok %v", tt.in, got, err, tt.want, tt.ok)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidDay) {
+			t.Errorf("ParseDay(%q) error = %v, want %v", tt.in, err, ErrInvalidDay)
+		}
+	}
+}
+
+func TestRequestCost(t *testing.T) {
+	var r RequestCost
+	reset := time.Date(2026, 10, 16, 17, 0, 0, 0, time.UTC)
+	now := reset.Add(-4 * time.Hour)
+	pct := 10.0
+	observe := func(after time.Duration, used float64) {
+		now = now.Add(after)
+		pct += used
+		r.Observe(api.UsageStat{Utilization: pct, ResetsAt: reset}, now)
+	}
+
+	observe(0, 0)
+	for _, used := range []float64{1, 2, 2, 0, 3} {
+		observe(time.Minute, used)
+	}
+	if _, ok := r.RequestsLeft(api.UsageStat{Utilization: pct}, RequestTypical); ok {
+		t.Fatal("estimate from 4 samples")
+	}
+	observe(time.Hour, 30) // too long since the last poll to count
+	observe(time.Minute, 8)
+
+	// Samples 1, 2, 2, 3, 8: median 2, upper quartile 3
+	session := api.UsageStat{Utilization: pct, ResetsAt: reset}
+	if n, ok := r.RequestsLeft(session, RequestTypical); !ok || n != 22 {
+		t.Errorf("typical requests left at %.0f%% = %d, %v, want 22, true", pct, n, ok)
+	}
+	if n, ok := r.RequestsLeft(session, RequestHeavy); !ok || n != 14 {
+		t.Errorf("heavy requests left at %.0f%% = %d, %v, want 14, true", pct, n, ok)
+	}
+}
+
+func TestNewShorterWindow(t *testing.T) {
+	// Friday noon, three days into a five-day window that resets Sunday noon
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	reset := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	p := New(80, time.Sunday, api.UsageStat{Utilization: 40, ResetsAt: reset}, 5*24*time.Hour, now)
+	if p == nil {
+		t.Fatal("New returned nil")
+	}
+	if math.Abs(p.Planned-48) > 0.01 {
+		t.Errorf("Planned = %.2f, want 48", p.Planned)
+	}
+}
+
+func TestCostFromUtilization(t *testing.T) {
+	usage := &api.UsageData{
+		FiveHour: api.UsageStat{Utilization: 50},
+		SevenDay: api.UsageStat{Utilization: 20},
+	}
+	c := CostFromUtilization(usage, 10, 200)
+	if c.Session != 5 || c.Week != 40 {
+		t.Errorf("cost = %+v, want $5 session and $40 week", c)
+	}
+	if got, want := c.String(), "≈ $5.00 session · $40.00 week"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestCostFromLogs(t *testing.T) {
+	now := time.Now()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "project"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// A million output tokens each an hour ago and three days ago
+	var log strings.Builder
+	for i, age := range []time.Duration{time.Hour, 3 * 24 * time.Hour} {
+		fmt.Fprintf(&log, `{"type":"assistant","timestamp":%q,"requestId":"req_%d","message":{"id":"msg_%d","model":"claude-sonnet-4-5","usage":{"output_tokens":1000000}}}`+"\n",
+			now.Add(-age).UTC().Format(time.RFC3339), i, i)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "project", "log.jsonl"), []byte(log.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tracker := claudecode.NewTracker(dir)
+	if _, err := tracker.Scan(now); err != nil {
+		t.Fatal(err)
+	}
+
+	usage := &api.UsageData{
+		FiveHour: api.UsageStat{ResetsAt: now.Add(3 * time.Hour)},
+		SevenDay: api.UsageStat{ResetsAt: now.Add(2 * 24 * time.Hour)},
+	}
+	price := claudecode.Prices(map[string]claudecode.Price{"sonnet": {Input: 1, Output: 10}})
+	c := CostFromLogs(tracker, usage, 5*time.Hour, weeklyWindow, price, now)
+	if c.Session != 10 || c.Week != 20 {
+		t.Errorf("cost = %+v, want $10 session and $20 week", c)
+	}
+
+	// The weekly window started after the older message
+	usage.SevenDay.ResetsAt = now.Add(5 * 24 * time.Hour)
+	if c = CostFromLogs(tracker, usage, 5*time.Hour, weeklyWindow, price, now); c.Week != 10 {
+		t.Errorf("week = %v, want $10", c.Week)
+	}
+}
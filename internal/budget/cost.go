@@ -0,0 +1,58 @@
+package budget
+
+import (
+	"fmt"
+	"time"
+
+	"claudebar/internal/api"
+	"claudebar/internal/claudecode"
+)
+
+// Where the cost estimate comes from
+const (
+	CostLogs        = "logs"        // the tokens Claude Code logged, at API prices
+	CostUtilization = "utilization" // utilization times the value of a full window
+)
+
+// Cost is the estimated dollar value of the usage in the current session
+// and week
+type Cost struct {
+	Session float64
+	Week    float64
+}
+
+// String formats the estimate for the overlay and tray menu
+func (c Cost) String() string {
+	return fmt.Sprintf("≈ $%.2f session · $%.2f week", c.Session, c.Week)
+}
+
+// CostFromUtilization values the session and weekly utilization as shares
+// of fullSession and fullWeek, the dollar values of a full session and week
+func CostFromUtilization(usage *api.UsageData, fullSession, fullWeek float64) Cost {
+	return Cost{
+		Session: usage.FiveHour.Utilization / 100 * fullSession,
+		Week:    usage.SevenDay.Utilization / 100 * fullWeek,
+	}
+}
+
+// CostFromLogs prices the tokens Claude Code logged since the session and
+// the weekly window started. The windows start session and week before
+// their reset in usage; before the first fetch, or without a reset time,
+// they are taken to end now.
+func CostFromLogs(t *claudecode.Tracker, usage *api.UsageData, session, week time.Duration, price claudecode.PriceFunc, now time.Time) Cost {
+	var u api.UsageData
+	if usage != nil {
+		u = *usage
+	}
+	start := func(stat api.UsageStat, window time.Duration) time.Time {
+		end := stat.ResetsAt
+		if end.IsZero() || end.Before(now) {
+			end = now
+		}
+		return end.Add(-window)
+	}
+	return Cost{
+		Session: t.Since(start(u.FiveHour, session), price).Cost,
+		Week:    t.Since(start(u.SevenDay, week), price).Cost,
+	}
+}
@@ -0,0 +1,85 @@
+package budget
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+// Request sizes the estimate can assume
+const (
+	RequestTypical = "typical" // the median increase between polls
+	RequestHeavy   = "heavy"   // the upper quartile
+)
+
+const (
+	// requestSamples is how many recent increases the estimate is based on
+	requestSamples = 100
+	// minRequestSamples is how many it needs before estimating at all
+	minRequestSamples = 5
+	// maxRequestGap is the longest time between two polls whose difference
+	// still counts as a sample; longer gaps span several requests
+	maxRequestGap = 5 * time.Minute
+)
+
+// RequestCost learns how much session utilization a request uses from the
+// increases between polls. A poll rarely covers exactly one request, so the
+// result is only a rough estimate. Methods are safe for concurrent use.
+type RequestCost struct {
+	mu      sync.Mutex
+	samples []float64
+	last    api.UsageStat
+	lastAt  time.Time
+}
+
+// Observe records a session reading taken at now
+func (r *RequestCost) Observe(session api.UsageStat, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sameWindow := session.ResetsAt.Sub(r.last.ResetsAt).Abs() < time.Minute
+	if used := session.Utilization - r.last.Utilization; used > 0 && sameWindow && now.Sub(r.lastAt) <= maxRequestGap {
+		r.samples = append(r.samples, used)
+		if len(r.samples) > requestSamples {
+			r.samples = r.samples[1:]
+		}
+	}
+	r.last, r.lastAt = session, now
+}
+
+// Reset forgets the recorded increases, e.g. after signing in again
+func (r *RequestCost) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.samples = nil
+	r.last, r.lastAt = api.UsageStat{}, time.Time{}
+}
+
+// PerRequest returns the session utilization a request of the given size
+// (RequestTypical or RequestHeavy) uses. ok is false until enough increases
+// were seen.
+func (r *RequestCost) PerRequest(size string) (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.samples) < minRequestSamples {
+		return 0, false
+	}
+	sorted := slices.Sorted(slices.Values(r.samples))
+	if size == RequestHeavy {
+		return sorted[len(sorted)*3/4], true
+	}
+	return sorted[len(sorted)/2], true
+}
+
+// RequestsLeft estimates how many more requests of the given size fit in the
+// session at its current utilization. ok is false while there's no estimate.
+func (r *RequestCost) RequestsLeft(session api.UsageStat, size string) (int, bool) {
+	cost, ok := r.PerRequest(size)
+	if !ok || cost <= 0 {
+		return 0, false
+	}
+	return int(max(100-session.Utilization, 0) / cost), true
+}
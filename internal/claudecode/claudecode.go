@@ -0,0 +1,133 @@
+// Package claudecode tracks the tokens Claude Code spends on this machine by
+// reading the conversation logs it keeps under ~/.claude/projects, and
+// estimates what they would cost at API prices
+package claudecode
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Tokens counts tokens by kind
+type Tokens struct {
+	Input      int64 `json:"input"`
+	Output     int64 `json:"output"`
+	CacheWrite int64 `json:"cache_write"`
+	CacheRead  int64 `json:"cache_read"`
+}
+
+// Total returns all tokens together
+func (t Tokens) Total() int64 {
+	return t.Input + t.Output + t.CacheWrite + t.CacheRead
+}
+
+// add adds u to t
+func (t *Tokens) add(u Tokens) {
+	t.Input += u.Input
+	t.Output += u.Output
+	t.CacheWrite += u.CacheWrite
+	t.CacheRead += u.CacheRead
+}
+
+// Usage is the tokens spent in some period and their estimated cost
+type Usage struct {
+	Tokens
+	Cost float64 // USD; models without a price count as free
+}
+
+// ProjectUsage is the usage of one project
+type ProjectUsage struct {
+	Project string
+	Usage
+}
+
+// Summary is what the overlay shows of the Claude Code usage
+type Summary struct {
+	Today    Usage
+	Week     Usage          // the last 7 days, today included
+	Projects []ProjectUsage // today's by project, most expensive first
+}
+
+// PriceFunc returns the price of a model, false for an unknown one
+type PriceFunc func(model string) (Price, bool)
+
+// DefaultDir returns where Claude Code keeps its logs: projects in
+// $CLAUDE_CONFIG_DIR, or else in ~/.claude
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "projects"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".claude", "projects"), nil
+}
+
+// Summary sums the usage recorded so far for today and the last 7 days,
+// pricing it with price (DefaultPrice when nil)
+func (t *Tracker) Summary(now time.Time, price PriceFunc) *Summary {
+	if price == nil {
+		price = DefaultPrice
+	}
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	week := today.AddDate(0, 0, -6)
+
+	s := &Summary{}
+	projects := map[string]*ProjectUsage{}
+	t.each(func(k bucket, tokens Tokens) {
+		if k.hour.Before(week) {
+			return
+		}
+		p, _ := price(k.model)
+		cost := p.Cost(tokens)
+		s.Week.add(tokens)
+		s.Week.Cost += cost
+		if k.hour.Before(today) {
+			return
+		}
+		s.Today.add(tokens)
+		s.Today.Cost += cost
+		pu := projects[k.project]
+		if pu == nil {
+			pu = &ProjectUsage{Project: k.project}
+			projects[k.project] = pu
+		}
+		pu.add(tokens)
+		pu.Cost += cost
+	})
+
+	for _, pu := range projects {
+		s.Projects = append(s.Projects, *pu)
+	}
+	slices.SortFunc(s.Projects, func(a, b ProjectUsage) int {
+		if c := cmp.Compare(b.Cost, a.Cost); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Project, b.Project)
+	})
+	return s
+}
+
+// Since sums the usage recorded from since on, at hour granularity, pricing
+// it with price (DefaultPrice when nil)
+func (t *Tracker) Since(since time.Time, price PriceFunc) Usage {
+	if price == nil {
+		price = DefaultPrice
+	}
+	since = since.Truncate(time.Hour)
+	var u Usage
+	t.each(func(k bucket, tokens Tokens) {
+		if k.hour.Before(since) {
+			return
+		}
+		p, _ := price(k.model)
+		u.add(tokens)
+		u.Cost += p.Cost(tokens)
+	})
+	return u
+}
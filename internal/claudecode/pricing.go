@@ -0,0 +1,77 @@
+package claudecode
+
+import "strings"
+
+// Price is what a model charges per million tokens, in USD
+type Price struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheWrite float64 `json:"cache_write"`
+	CacheRead  float64 `json:"cache_read"`
+}
+
+// Cost returns the cost of t at this price, in USD
+func (p Price) Cost(t Tokens) float64 {
+	return (float64(t.Input)*p.Input + float64(t.Output)*p.Output +
+		float64(t.CacheWrite)*p.CacheWrite + float64(t.CacheRead)*p.CacheRead) / 1e6
+}
+
+// listPrice builds a price from the input and output prices, with cache
+// writes at 1.25 and cache reads at 0.1 times the input price
+func listPrice(input, output float64) Price {
+	return Price{Input: input, Output: output, CacheWrite: input * 1.25, CacheRead: input / 10}
+}
+
+// defaultPrices are Anthropic's API list prices by model, matched in order
+// against the model name so specific versions come before their family
+var defaultPrices = []struct {
+	match string
+	price Price
+}{
+	{"opus-4-0", listPrice(15, 75)},
+	{"opus-4-1", listPrice(15, 75)},
+	{"opus-4-2", listPrice(15, 75)}, // claude-opus-4-20250514
+	{"3-opus", listPrice(15, 75)},
+	{"opus", listPrice(5, 25)},
+	{"sonnet", listPrice(3, 15)},
+	{"3-5-haiku", listPrice(0.8, 4)},
+	{"3-haiku", listPrice(0.25, 1.25)},
+	{"haiku", listPrice(1, 5)},
+}
+
+// DefaultPrice returns the list price of a model such as
+// "claude-sonnet-4-5-20250929", and false for models it doesn't know
+func DefaultPrice(model string) (Price, bool) {
+	for _, p := range defaultPrices {
+		if strings.Contains(model, p.match) {
+			return p.price, true
+		}
+	}
+	return Price{}, false
+}
+
+// Prices returns a PriceFunc that prices models whose name contains a key of
+// overrides at that price, the longest key winning, and others at
+// DefaultPrice. An override without cache prices derives them from its input
+// price the way the list prices do.
+func Prices(overrides map[string]Price) PriceFunc {
+	if len(overrides) == 0 {
+		return DefaultPrice
+	}
+	return func(model string) (Price, bool) {
+		match := ""
+		for key := range overrides {
+			if strings.Contains(model, key) && len(key) > len(match) {
+				match = key
+			}
+		}
+		if match == "" {
+			return DefaultPrice(model)
+		}
+		p := overrides[match]
+		if p.CacheWrite == 0 && p.CacheRead == 0 {
+			p = listPrice(p.Input, p.Output)
+		}
+		return p, true
+	}
+}
@@ -0,0 +1,265 @@
+package claudecode
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// keepFor is how far back usage is kept; older log entries are skipped, and
+// log files last written before then aren't read at all
+const keepFor = 8 * 24 * time.Hour
+
+// bucket is what usage is summed by: an hour, a project and a model
+type bucket struct {
+	hour    time.Time
+	project string
+	model   string
+}
+
+// fileState is what was read from one log file so far
+type fileState struct {
+	offset  int64 // end of the last complete line read
+	modTime time.Time
+	usage   map[bucket]Tokens
+	ids     []string // messages counted from this file, see Tracker.seen
+}
+
+// Tracker reads Claude Code logs incrementally: each Scan only parses the
+// lines appended since the last one. It is safe for concurrent use.
+type Tracker struct {
+	dir string
+
+	mu    sync.Mutex
+	files map[string]*fileState
+	// seen maps the messages already counted to the file they were counted
+	// from. Claude Code writes a message once per content block, and resumed
+	// conversations copy earlier messages into the new log.
+	seen map[string]string
+}
+
+// NewTracker creates a tracker for the logs in dir, see DefaultDir
+func NewTracker(dir string) *Tracker {
+	return &Tracker{dir: dir, files: map[string]*fileState{}, seen: map[string]string{}}
+}
+
+// Dir returns the directory the tracker reads
+func (t *Tracker) Dir() string {
+	return t.dir
+}
+
+// Scan reads what was appended to the logs since the last scan and forgets
+// usage older than keepFor. changed reports whether any usage was added or
+// dropped. A missing log directory is not an error, Claude Code may simply
+// not have run yet.
+func (t *Tracker) Scan(now time.Time) (changed bool, err error) {
+	cutoff := now.Add(-keepFor)
+
+	var paths []string
+	err = filepath.WalkDir(t.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == t.dir && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".jsonl") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// A file that can't be read doesn't stop the others from being counted
+	present := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		present[path] = true
+		c, fileErr := t.scanFile(path, cutoff)
+		if fileErr != nil && err == nil {
+			err = fileErr
+		}
+		changed = changed || c
+	}
+	for path, f := range t.files {
+		if !present[path] {
+			t.forget(path, f)
+			changed = changed || len(f.usage) > 0
+		}
+	}
+	return t.expire(cutoff) || changed, err
+}
+
+// scanFile parses the complete lines appended to a log file since it was
+// last read. A file that shrank was rewritten and is read again from the
+// start. Must be called with mu held.
+func (t *Tracker) scanFile(path string, cutoff time.Time) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, nil // removed since the walk
+	}
+	f := t.files[path]
+	if f == nil {
+		if info.ModTime().Before(cutoff) {
+			return false, nil
+		}
+		f = &fileState{usage: map[bucket]Tokens{}}
+		t.files[path] = f
+	}
+	if info.Size() == f.offset && info.ModTime().Equal(f.modTime) {
+		return false, nil
+	}
+	changed := false
+	if info.Size() < f.offset {
+		changed = len(f.usage) > 0
+		t.forget(path, f)
+		f = &fileState{usage: map[bucket]Tokens{}}
+		t.files[path] = f
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return changed, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(f.offset, io.SeekStart); err != nil {
+		return changed, err
+	}
+
+	project := projectName(t.dir, path)
+	r := bufio.NewReaderSize(file, 64<<10)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			// Without a newline the line may still be being written; it is
+			// read again on the next scan
+			break
+		}
+		f.offset += int64(len(line))
+		if t.parseLine(path, f, project, line, cutoff) {
+			changed = true
+		}
+	}
+	f.modTime = info.ModTime()
+	return changed, nil
+}
+
+// logEntry is the part of a Claude Code log line that matters here
+type logEntry struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	CWD       string    `json:"cwd"`
+	RequestID string    `json:"requestId"`
+	Message   struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Usage *struct {
+			InputTokens              int64 `json:"input_tokens"`
+			OutputTokens             int64 `json:"output_tokens"`
+			CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+}
+
+// parseLine counts the usage of an assistant message logged in line, once
+// per message. Returns whether anything was counted.
+func (t *Tracker) parseLine(path string, f *fileState, project string, line []byte, cutoff time.Time) bool {
+	// Most lines are tool results and user messages; skip them unparsed
+	if !bytes.Contains(line, []byte(`"usage"`)) {
+		return false
+	}
+	var e logEntry
+	if err := json.Unmarshal(line, &e); err != nil || e.Type != "assistant" || e.Message.Usage == nil {
+		return false
+	}
+	if e.Timestamp.Before(cutoff) || e.Message.Model == "<synthetic>" {
+		return false
+	}
+	if e.Message.ID != "" {
+		id := e.Message.ID + ":" + e.RequestID
+		if _, ok := t.seen[id]; ok {
+			return false
+		}
+		t.seen[id] = path
+		f.ids = append(f.ids, id)
+	}
+
+	if e.CWD != "" {
+		project = filepath.Base(e.CWD)
+	}
+	k := bucket{hour: e.Timestamp.Truncate(time.Hour), project: project, model: e.Message.Model}
+	u := e.Message.Usage
+	tokens := f.usage[k]
+	tokens.add(Tokens{
+		Input:      u.InputTokens,
+		Output:     u.OutputTokens,
+		CacheWrite: u.CacheCreationInputTokens,
+		CacheRead:  u.CacheReadInputTokens,
+	})
+	f.usage[k] = tokens
+	return true
+}
+
+// forget drops what was read from a file. Must be called with mu held.
+func (t *Tracker) forget(path string, f *fileState) {
+	for _, id := range f.ids {
+		if t.seen[id] == path {
+			delete(t.seen, id)
+		}
+	}
+	delete(t.files, path)
+}
+
+// expire drops usage from before cutoff, returning whether there was any,
+// and stops following files that weren't written since. Must be called with
+// mu held.
+func (t *Tracker) expire(cutoff time.Time) bool {
+	expired := false
+	for path, f := range t.files {
+		for k := range f.usage {
+			if k.hour.Before(cutoff.Truncate(time.Hour)) {
+				delete(f.usage, k)
+				expired = true
+			}
+		}
+		if len(f.usage) == 0 && f.modTime.Before(cutoff) {
+			t.forget(path, f)
+		}
+	}
+	return expired
+}
+
+// each calls fn for every bucket of usage, summed over the files
+func (t *Tracker) each(fn func(bucket, Tokens)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, f := range t.files {
+		for k, tokens := range f.usage {
+			fn(k, tokens)
+		}
+	}
+}
+
+// projectName names the project of a log file without a working directory
+// in its entries: the folder under dir, which Claude Code names after the
+// project path ("-home-me-src-claudebar")
+func projectName(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return filepath.Base(filepath.Dir(path))
+	}
+	return strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+}
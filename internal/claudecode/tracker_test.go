@@ -0,0 +1,121 @@
+package claudecode
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// logLine formats an assistant message as Claude Code logs it
+func logLine(id, model, cwd string, at time.Time, input, output int) string {
+	return fmt.Sprintf(`{"type":"assistant","timestamp":%q,"cwd":%q,"requestId":"req_%s","message":{"id":"msg_%s","model":%q,"usage":{"input_tokens":%d,"output_tokens":%d,"cache_creation_input_tokens":0,"cache_read_input_tokens":0}}}`+"\n",
+		at.UTC().Format(time.RFC3339Nano), cwd, id, id, model, input, output)
+}
+
+func appendLog(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "-src-claudebar"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "-src-claudebar", "session.jsonl")
+	now := time.Now()
+	sonnet := "claude-sonnet-4-5-20250929"
+
+	appendLog(t, path, `{"type":"user","message":{"content":"hi"}}`+"\n"+
+		logLine("1", sonnet, "/src/claudebar", now, 1000, 2000)+
+		logLine("1", sonnet, "/src/claudebar", now, 1000, 2000)+ // same message, another content block
+		logLine("2", sonnet, "", now.Add(-3*24*time.Hour), 500, 0)+
+		logLine("3", sonnet, "", now.Add(-30*24*time.Hour), 500, 0)+ // too old
+		`{"type":"assistant","timestamp":"`) // still being written
+
+	tr := NewTracker(dir)
+	if changed, err := tr.Scan(now); err != nil || !changed {
+		t.Fatalf("Scan = %v, %v", changed, err)
+	}
+	s := tr.Summary(now, nil)
+	if s.Today.Input != 1000 || s.Today.Output != 2000 || s.Week.Input != 1500 {
+		t.Errorf("summary = %+v, want 1000 in and 2000 out today, 1500 in this week", s)
+	}
+	if want := (1000*3 + 2000*15) / 1e6; math.Abs(s.Today.Cost-want) > 1e-9 {
+		t.Errorf("today's cost = %v, want %v", s.Today.Cost, want)
+	}
+	if len(s.Projects) != 1 || s.Projects[0].Project != "claudebar" {
+		t.Errorf("projects = %+v, want claudebar", s.Projects)
+	}
+
+	// Only what was appended is read; the half-written line counts once done
+	if changed, _ := tr.Scan(now); changed {
+		t.Error("second Scan without new lines reported a change")
+	}
+	appendLog(t, path, `"}`+"\n"+logLine("4", "claude-opus-4-1", "/src/other", now, 10, 0))
+	tr.Scan(now)
+	s = tr.Summary(now, nil)
+	if s.Today.Input != 1010 || len(s.Projects) != 2 || s.Projects[0].Project != "claudebar" {
+		t.Errorf("after appending: %+v", s)
+	}
+
+	// A rewritten, shorter file is read again from the start
+	if err := os.WriteFile(path, []byte(logLine("1", sonnet, "/src/claudebar", now, 1000, 2000)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tr.Scan(now)
+	if s = tr.Summary(now, nil); s.Today.Input != 1000 {
+		t.Errorf("after rewrite: today = %+v, want 1000 in", s.Today)
+	}
+}
+
+func TestTrackerMissingDir(t *testing.T) {
+	tr := NewTracker(filepath.Join(t.TempDir(), "projects"))
+	if changed, err := tr.Scan(time.Now()); err != nil || changed {
+		t.Errorf("Scan = %v, %v, want no change and no error", changed, err)
+	}
+}
+
+func TestDefaultPrice(t *testing.T) {
+	for model, want := range map[string]float64{
+		"claude-opus-4-20250514":     15,
+		"claude-opus-4-1-20250805":   15,
+		"claude-opus-4-5-20251101":   5,
+		"claude-sonnet-4-5-20250929": 3,
+		"claude-3-5-haiku-20241022":  0.8,
+		"claude-haiku-4-5-20251001":  1,
+	} {
+		if p, ok := DefaultPrice(model); !ok || p.Input != want {
+			t.Errorf("DefaultPrice(%s) = %+v, %v, want input %v", model, p, ok, want)
+		}
+	}
+	if _, ok := DefaultPrice("gpt-4"); ok {
+		t.Error("DefaultPrice knows gpt-4")
+	}
+}
+
+func TestPrices(t *testing.T) {
+	price := Prices(map[string]Price{
+		"opus":     {Input: 1, Output: 2},
+		"opus-4-5": {Input: 4, Output: 20, CacheWrite: 5, CacheRead: 0.5},
+	})
+	if p, _ := price("claude-opus-4-5-20251101"); p.Input != 4 || p.CacheRead != 0.5 {
+		t.Errorf("opus 4.5 = %+v, want the longest override as given", p)
+	}
+	if p, _ := price("claude-opus-4-1"); p.Input != 1 || p.CacheWrite != 1.25 || p.CacheRead != 0.1 {
+		t.Errorf("opus 4.1 = %+v, want the opus override with derived cache prices", p)
+	}
+	if p, ok := price("claude-sonnet-4-5"); !ok || p.Input != 3 {
+		t.Errorf("sonnet = %+v, %v, want the list price", p, ok)
+	}
+}
@@ -0,0 +1,93 @@
+package claudecode
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// watchInterval is how often the logs are checked for new lines
+const watchInterval = 10 * time.Second
+
+// Watcher scans the logs of a Tracker periodically and reports when the
+// usage changed, so the overlay follows Claude Code as it runs
+type Watcher struct {
+	tracker  *Tracker
+	onChange func()
+
+	mu       sync.Mutex
+	stopChan chan struct{}
+	running  bool
+	failed   bool // the last scan failed, so the next failure isn't logged again
+	day      int  // day of the year of the last scan, to roll "today" over
+}
+
+// NewWatcher creates a watcher for tracker that calls onChange (from its own
+// goroutine) after a scan found new usage, and after the first scan
+func NewWatcher(tracker *Tracker, onChange func()) *Watcher {
+	return &Watcher{tracker: tracker, onChange: onChange}
+}
+
+// Tracker returns the tracker the watcher scans
+func (w *Watcher) Tracker() *Tracker {
+	return w.tracker
+}
+
+// Start starts scanning, right away and then every watchInterval
+func (w *Watcher) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.running {
+		return
+	}
+	w.running = true
+	w.stopChan = make(chan struct{})
+	go w.loop(w.stopChan)
+}
+
+// Stop stops scanning
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.running {
+		return
+	}
+	close(w.stopChan)
+	w.running = false
+}
+
+func (w *Watcher) loop(stop chan struct{}) {
+	w.scan(true)
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.scan(false)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// scan reads the new log lines and calls onChange when they changed the
+// usage or a new day started, and always on the first scan
+func (w *Watcher) scan(first bool) {
+	now := time.Now()
+	changed, err := w.tracker.Scan(now)
+	w.mu.Lock()
+	if err != nil && !w.failed {
+		log.Printf("Claude Code: failed to read logs in %s: %v", w.tracker.Dir(), err)
+	}
+	w.failed = err != nil
+	if w.day != now.YearDay() {
+		w.day = now.YearDay()
+		changed = true
+	}
+	w.mu.Unlock()
+
+	if (changed || first) && w.onChange != nil {
+		w.onChange()
+	}
+}
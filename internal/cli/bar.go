@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"image/color"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"claudebar/internal/alert"
+	"claudebar/internal/api"
+	"claudebar/internal/config"
+	"claudebar/internal/palette"
+	"claudebar/internal/usagecache"
+)
+
+// barFormats are the status bar protocols the bar subcommand speaks
+var barFormats = []string{"waybar", "polybar", "i3blocks"}
+
+// waybarOutput is a line of a Waybar custom module with "return-type": "json"
+type waybarOutput struct {
+	Text       string `json:"text"`
+	Tooltip    string `json:"tooltip"`
+	Class      string `json:"class"`      // "none", "warning", "critical" or "error"
+	Percentage int    `json:"percentage"` // the higher of session and weekly
+}
+
+// runBar prints usage for a tiling WM status bar, a line per update: on
+// start, every interval and on SIGUSR1. Usage comes from the running app's
+// cache, or with -fetch from claude.ai directly.
+func runBar(args []string) int {
+	fs := flag.NewFlagSet("bar", flag.ContinueOnError)
+	format := fs.String("format", "waybar", "output format: "+strings.Join(barFormats, ", "))
+	text := fs.String("text", defaultPromptFormat, "bar text with {session}, {weekly}, {opus} and {sonnet} replaced by their percentages")
+	interval := fs.Duration("interval", 30*time.Second, "time between updates, 0 prints once and exits")
+	fetch := fs.Bool("fetch", false, "sign in and fetch usage instead of reading the running app's cache")
+	maxAge := fs.Duration("max-age", time.Hour, "treat cached usage older than this as unavailable (0 = no limit)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if !slices.Contains(barFormats, *format) {
+		fmt.Fprintf(os.Stderr, "claudebar bar: unsupported format %q\n", *format)
+		return 2
+	}
+
+	read := func() (*api.UsageData, error) { return cachedUsage(*maxAge) }
+	if *fetch {
+		read = reconnecting()
+		if *interval > 0 {
+			*interval = max(*interval, minWatchInterval)
+		}
+	}
+	levels := config.Get().AlertLevels()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	refresh := make(chan os.Signal, 1)
+	if sigs := barRefreshSignals(); len(sigs) > 0 {
+		signal.Notify(refresh, sigs...)
+	}
+
+	for {
+		usage, err := read()
+		fmt.Println(barLine(*format, *text, usage, err, levels))
+		if *interval == 0 {
+			if err != nil {
+				return 1
+			}
+			return 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-refresh:
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// barLine formats usage, or the error reading it, for the status bar. Text
+// turns the warning or critical color when the session or weekly limit
+// reaches that level; on errors it is empty, which hides the block.
+func barLine(format, text string, usage *api.UsageData, err error, levels alert.Levels) string {
+	if err != nil {
+		if format != "waybar" {
+			return ""
+		}
+		tooltip := err.Error()
+		if !errors.Is(err, usagecache.ErrNoCache) && !errors.Is(err, usagecache.ErrStale) {
+			tooltip, _ = api.Explain(err)
+		}
+		return waybarLine(waybarOutput{Tooltip: tooltip, Class: "error"})
+	}
+
+	text = promptSegment(text, usage)
+	pct := max(usage.FiveHour.Utilization, usage.SevenDay.Utilization)
+	severity := levels.Classify(pct)
+
+	switch format {
+	case "waybar":
+		var tooltip bytes.Buffer
+		writeStatus(&tooltip, usage)
+		return waybarLine(waybarOutput{
+			Text:       text,
+			Tooltip:    strings.TrimSuffix(tooltip.String(), "\n"),
+			Class:      severity.String(),
+			Percentage: int(pct + 0.5),
+		})
+	case "polybar":
+		if c, ok := severityColor(severity); ok {
+			return "%{F" + c + "}" + text + "%{F-}"
+		}
+		return text
+	default: // i3blocks: full text, short text and color
+		if c, ok := severityColor(severity); ok {
+			return text + "\n" + text + "\n" + c
+		}
+		return text
+	}
+}
+
+// waybarLine encodes out as a single JSON line
+func waybarLine(out waybarOutput) string {
+	data, _ := json.Marshal(out)
+	return string(data)
+}
+
+// severityColor returns the bar color of the overlay for a severity as
+// #RRGGBB, and false for none
+func severityColor(s alert.Severity) (string, bool) {
+	var c color.RGBA
+	switch s {
+	case alert.Warning:
+		c = palette.Warning
+	case alert.Critical:
+		c = palette.Critical
+	default:
+		return "", false
+	}
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B), true
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"claudebar/internal/alert"
+	"claudebar/internal/api"
+	"claudebar/internal/usagecache"
+)
+
+func TestBarLine(t *testing.T) {
+	levels := alert.DefaultLevels()
+	usage := &api.UsageData{
+		FiveHour: api.UsageStat{Utilization: 42},
+		SevenDay: api.UsageStat{Utilization: 77},
+	}
+
+	var out waybarOutput
+	if err := json.Unmarshal([]byte(barLine("waybar", defaultPromptFormat, usage, nil, levels)), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Text != "⚡42%|77%" || out.Class != "warning" || out.Percentage != 77 || out.Tooltip != "Session   42%\nWeekly    77%" {
+		t.Errorf("waybar = %+v", out)
+	}
+
+	if got, want := barLine("polybar", "{session}", usage, nil, levels), "%{F#eab308}42%%{F-}"; got != want {
+		t.Errorf("polybar = %q, want %q", got, want)
+	}
+	usage.SevenDay.Utilization = 10
+	if got := barLine("i3blocks", "{session}", usage, nil, levels); got != "42%" {
+		t.Errorf("i3blocks = %q, want 42%% without a color", got)
+	}
+
+	if got := barLine("polybar", defaultPromptFormat, nil, usagecache.ErrNoCache, levels); got != "" {
+		t.Errorf("polybar error = %q, want an empty line", got)
+	}
+	json.Unmarshal([]byte(barLine("waybar", defaultPromptFormat, nil, api.ErrRateLimited, levels)), &out)
+	if out.Text != "" || out.Class != "error" || out.Tooltip != "Rate limited" {
+		t.Errorf("waybar error = %+v", out)
+	}
+}
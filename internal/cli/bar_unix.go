@@ -0,0 +1,14 @@
+//go:build !windows
+
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+// barRefreshSignals are the signals that make the bar subcommand update
+// right away, e.g. pkill -USR1 -f "claudebar bar"
+func barRefreshSignals() []os.Signal {
+	return []os.Signal{syscall.SIGUSR1}
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package cli
+
+import "os"
+
+// barRefreshSignals is empty: Windows has no SIGUSR1, so the bar subcommand
+// only updates on its interval
+func barRefreshSignals() []os.Signal {
+	return nil
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"claudebar/internal/api"
+)
+
+// Monitoring plugin exit codes, as used by Nagios, Icinga and friends
+const (
+	checkOK       = 0
+	checkWarning  = 1
+	checkCritical = 2
+	checkUnknown  = 3
+)
+
+// checkStatus names the exit codes in the output line
+var checkStatus = []string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}
+
+// runCheck fetches usage once and prints a monitoring plugin line such as
+//
+//	CLAUDEBAR WARNING - session 78% (resets in 1h 12m) | session=78%;75;90;0;100
+//
+// exiting 0, 1 or 2 for OK, warning or critical, and 3 when usage couldn't be
+// fetched.
+func runCheck(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	metric := fs.String("metric", "session", "usage metric to check: "+strings.Join(api.MetricNames, ", "))
+	warn := fs.Float64("warn", 75, "warning threshold in percent")
+	crit := fs.Float64("crit", 90, "critical threshold in percent")
+	if err := fs.Parse(args); err != nil {
+		return checkUnknown
+	}
+
+	if !slices.Contains(api.MetricNames, *metric) {
+		return checkFailed(fmt.Errorf("unknown metric %q, want one of %s", *metric, strings.Join(api.MetricNames, ", ")))
+	}
+	if *warn > *crit {
+		return checkFailed(errors.New("--warn must not be above --crit"))
+	}
+
+	client, err := connect()
+	if err != nil {
+		return checkFailed(err)
+	}
+	usage, err := client.FetchUsage()
+	if err != nil {
+		return checkFailed(err)
+	}
+
+	code, line := checkLine(*metric, usage.Metric(*metric), *warn, *crit)
+	fmt.Println(line)
+	return code
+}
+
+// checkLine rates a metric against the thresholds and formats the plugin
+// output line with its performance data
+func checkLine(name string, stat *api.UsageStat, warn, crit float64) (int, string) {
+	pct := stat.GetPercentage()
+	code := checkOK
+	switch {
+	case pct >= crit:
+		code = checkCritical
+	case pct >= warn:
+		code = checkWarning
+	}
+
+	summary := fmt.Sprintf("%s %.0f%%", name, pct)
+	if !stat.ResetsAt.IsZero() {
+		summary += " (resets in " + api.TimeUntilReset(stat.ResetsAt) + ")"
+	}
+	perf := fmt.Sprintf("%s=%g%%;%g;%g;0;100", name, pct, warn, crit)
+	return code, fmt.Sprintf("CLAUDEBAR %s - %s | %s", checkStatus[code], summary, perf)
+}
+
+// checkFailed prints an UNKNOWN line for a check that couldn't run
+func checkFailed(err error) int {
+	fmt.Printf("CLAUDEBAR %s - %v\n", checkStatus[checkUnknown], err)
+	return checkUnknown
+}
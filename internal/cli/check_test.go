@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"testing"
+
+	"claudebar/internal/api"
+	"claudebar/internal/config/configtest"
+)
+
+func TestCheckLine(t *testing.T) {
+	tests := []struct {
+		pct      float64
+		wantCode int
+		wantLine string
+	}{
+		{42, checkOK, "CLAUDEBAR OK - session 42% | session=42%;75;90;0;100"},
+		{75, checkWarning, "CLAUDEBAR WARNING - session 75% | session=75%;75;90;0;100"},
+		{89.5, checkWarning, "CLAUDEBAR WARNING - session 90% | session=89.5%;75;90;0;100"},
+		{90, checkCritical, "CLAUDEBAR CRITICAL - session 90% | session=90%;75;90;0;100"},
+		{100, checkCritical, "CLAUDEBAR CRITICAL - session 100% | session=100%;75;90;0;100"},
+	}
+	for _, tt := range tests {
+		code, line := checkLine("session", &api.UsageStat{Utilization: tt.pct}, 75, 90)
+		if code != tt.wantCode || line != tt.wantLine {
+			t.Errorf("checkLine(%v) = %d, %q; want %d, %q", tt.pct, code, line, tt.wantCode, tt.wantLine)
+		}
+	}
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	configtest.Use(t)
+	for _, args := range [][]string{nil, {"-demo"}, {"nope"}} {
+		if _, ok := Run(args); ok {
+			t.Errorf("Run(%q) ran a subcommand", args)
+		}
+	}
+}
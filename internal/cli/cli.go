@@ -0,0 +1,47 @@
+// Package cli implements ClaudeBar's headless subcommands, for scripts and
+// monitoring systems that want usage numbers without the GUI.
+package cli
+
+import (
+	"claudebar/internal/api"
+	"claudebar/internal/config"
+)
+
+// command is a subcommand taking its own arguments and returning the process
+// exit code
+type command func(args []string) int
+
+// commands maps subcommand names to their implementations
+var commands = map[string]command{
+	"bar":    runBar,
+	"check":  runCheck,
+	"doctor": runDoctor,
+	"export": runExport,
+	"prompt": runPrompt,
+	"status": runStatus,
+	"watch":  runWatch,
+}
+
+// Run runs the subcommand named by args[0] with the remaining arguments.
+// ok is false when args don't start with a subcommand, so the caller should
+// start the GUI instead.
+func Run(args []string) (code int, ok bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return 0, false
+	}
+	return cmd(args[1:]), true
+}
+
+// connect authenticates the same way the GUI does at startup and returns a
+// client ready to fetch usage
+func connect() (*api.Client, error) {
+	client := api.NewClient(api.ConfigOptions(config.Get()))
+	if err := api.NewAuthManager(client).Initialize(); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
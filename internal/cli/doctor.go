@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"claudebar/internal/api"
+	"claudebar/internal/browser"
+	"claudebar/internal/config"
+	"claudebar/internal/redact"
+)
+
+// runDoctor walks the authentication chain the app uses at startup (saved
+// session key, Claude Code credentials, then every browser profile) and
+// reports what each step finds. Nothing is saved, and secrets are redacted.
+// Exits 0 when some source yields a working session key.
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	// The steps are reported below; the extractor's own logging would repeat them
+	log.SetOutput(io.Discard)
+	out := redact.Writer(os.Stdout)
+	cfg := config.Get()
+	extractor := browser.NewCookieExtractor()
+	extractor.AddDomain(cfg.Host())
+	works := doctor(out, cfg, extractor.Probe, verifySessionKey)
+	if !works {
+		return 1
+	}
+	return 0
+}
+
+// doctor prints the result of each authentication step to out and returns
+// whether any source has a working session key. probe and verify are the
+// browser search and the session check.
+func doctor(out io.Writer, cfg *config.Config, probe func() []browser.ProbeResult, verify func(key string) error) bool {
+	winner := ""
+	try := func(source, key string) {
+		redact.Add(key)
+		if err := verify(key); err != nil {
+			status, suggestion := api.Explain(err)
+			fmt.Fprintf(out, "   session key rejected: %s (%v)\n   %s\n", status, err, suggestion)
+			return
+		}
+		fmt.Fprintln(out, "   session key works")
+		if winner == "" {
+			winner = source
+		}
+	}
+
+	fmt.Fprintln(out, "1. Saved session key")
+	if cfg.SessionKey == "" {
+		fmt.Fprintln(out, "   none saved")
+	} else {
+		fmt.Fprintf(out, "   found %s\n", redact.String(cfg.SessionKey))
+		try("the saved session key", cfg.SessionKey)
+	}
+
+	fmt.Fprintln(out, "2. Claude Code credentials")
+	if orgID, err := api.ClaudeCodeOrgID(); err != nil {
+		fmt.Fprintf(out, "   not usable: %v\n", err)
+	} else {
+		fmt.Fprintf(out, "   organization %s (Claude Code tokens can't sign in to claude.ai, only the organization is used)\n", redact.String(orgID))
+	}
+
+	fmt.Fprintln(out, "3. Browser cookies")
+	for _, r := range probe() {
+		switch {
+		case errors.Is(r.Err, browser.ErrBrowserNotFound):
+			fmt.Fprintf(out, "   %s: not installed (%s)\n", r.Source, r.Path)
+		case r.Err != nil:
+			fmt.Fprintf(out, "   %s: %v (%s)\n", r.Source, r.Err, r.Path)
+		default:
+			expiry := "no expiry"
+			if !r.Expires.IsZero() {
+				expiry = "expires " + r.Expires.Local().Format(time.DateTime)
+			}
+			fmt.Fprintf(out, "   %s: found %s, %s (%s)\n", r.Source, redact.String(r.Key), expiry, r.Path)
+			try(r.Source, r.Key)
+		}
+	}
+
+	fmt.Fprintln(out)
+	if winner == "" {
+		fmt.Fprintln(out, "No working session key found. Log in to claude.ai in your browser, or copy the sessionKey cookie from DevTools (Application > Cookies > claude.ai) into Settings.")
+		return false
+	}
+	fmt.Fprintf(out, "ClaudeBar would sign in with %s.\n", winner)
+	return true
+}
+
+// verifySessionKey checks a session key against claude.ai by listing its
+// organizations
+func verifySessionKey(key string) error {
+	client := api.NewClient(api.ConfigOptions(config.Get()))
+	client.SetSessionKey(key)
+	orgs, err := client.FetchOrganizations()
+	if err != nil {
+		return err
+	}
+	if len(orgs) == 0 {
+		return errors.New("no organizations found")
+	}
+	return nil
+}
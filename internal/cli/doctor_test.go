@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"claudebar/internal/api"
+	"claudebar/internal/browser"
+	"claudebar/internal/config"
+	"claudebar/internal/config/configtest"
+)
+
+func TestDoctor(t *testing.T) {
+	configtest.Use(t)
+	const (
+		expired = "sk-ant-REDACTED"
+		working = "sk-ant-REDACTED"
+	)
+	cfg := config.Default()
+	cfg.SessionKey = expired
+	probe := func() []browser.ProbeResult {
+		return []browser.ProbeResult{
+			{Source: "Chrome", Path: "/chrome", Err: browser.ErrBrowserNotFound},
+			{Source: "Brave/Default", Path: "/brave/Cookies", Err: browser.ErrNoCookieFound},
+			{Source: "Firefox/abc.default", Path: "/firefox/cookies.sqlite", Key: working},
+		}
+	}
+	verify := func(key string) error {
+		if key != working {
+			return api.ErrSessionExpired
+		}
+		return nil
+	}
+
+	var out bytes.Buffer
+	if !doctor(&out, cfg, probe, verify) {
+		t.Error("doctor found no working source")
+	}
+	text := out.String()
+	for _, want := range []string{
+		"session key rejected: Session expired",
+		"Chrome: not installed",
+		"Brave/Default: " + browser.ErrNoCookieFound.Error(),
+		"session key works",
+		"would sign in with Firefox/abc.default",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("output lacks %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "expiredexpired") || strings.Contains(text, "workingworking") {
+		t.Errorf("output leaks a session key:\n%s", text)
+	}
+}
+
+func TestDoctorNothingWorks(t *testing.T) {
+	configtest.Use(t)
+	var out bytes.Buffer
+	probe := func() []browser.ProbeResult { return nil }
+	verify := func(string) error { return api.ErrUnauthorized }
+	if doctor(&out, config.Default(), probe, verify) {
+		t.Error("doctor reported a working source")
+	}
+	if !strings.Contains(out.String(), "No working session key found") {
+		t.Errorf("output lacks the failure summary:\n%s", out.String())
+	}
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"claudebar/internal/config"
+	"claudebar/internal/history"
+)
+
+// runExport writes the recorded weekly history in a format for time series
+// tools: InfluxDB line protocol to stdout or a file, or a SQLite database
+// with views for Grafana
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "influx", "output format: influx or sqlite")
+	output := fs.String("o", "", "output file (required for sqlite, stdout by default for influx)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if err := export(*format, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "claudebar export: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// export reads the history from the config directory and writes it out
+func export(format, output string) error {
+	if format != "influx" && format != "sqlite" {
+		return fmt.Errorf("unsupported format %q", format)
+	}
+	if format == "sqlite" && output == "" {
+		return errors.New("sqlite needs an output file (-o)")
+	}
+
+	dir, err := config.Dir()
+	if err != nil {
+		return err
+	}
+	cycles, err := history.Cycles(dir)
+	if err != nil {
+		return err
+	}
+
+	if format == "sqlite" {
+		heatmap, err := history.LoadHeatmap(dir)
+		if err != nil {
+			return err
+		}
+		return history.WriteSQLite(output, cycles, heatmap)
+	}
+	if output == "" {
+		return history.WriteInflux(os.Stdout, cycles)
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := history.WriteInflux(f, cycles); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"claudebar/internal/api"
+	"claudebar/internal/usagecache"
+)
+
+// defaultPromptFormat prints e.g. "⚡42%|77%"
+const defaultPromptFormat = "⚡{session}|{weekly}"
+
+// runPrompt prints a short usage segment for shell prompts and tmux status
+// lines from the usage the app cached, without touching the network. It
+// prints nothing and exits 1 when the cache is missing or too old.
+func runPrompt(args []string) int {
+	fs := flag.NewFlagSet("prompt", flag.ContinueOnError)
+	format := fs.String("format", defaultPromptFormat, "segment with {session}, {weekly}, {opus} and {sonnet} replaced by their percentages")
+	maxAge := fs.Duration("max-age", time.Hour, "print nothing when the cached usage is older (0 = no limit)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	usage, err := cachedUsage(*maxAge)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "claudebar prompt: %v\n", err)
+		return 1
+	}
+	fmt.Println(promptSegment(*format, usage))
+	return 0
+}
+
+// cachedUsage returns the usage the app cached, or an error when there is
+// none or it is older than maxAge (0 = no limit)
+func cachedUsage(maxAge time.Duration) (*api.UsageData, error) {
+	entry, err := usagecache.Read()
+	if err != nil {
+		return nil, err
+	}
+	if maxAge > 0 && time.Since(entry.Fetched) > maxAge {
+		return nil, fmt.Errorf("%w: fetched %s", usagecache.ErrStale, entry.Fetched.Format(time.DateTime))
+	}
+	return entry.Usage, nil
+}
+
+// promptSegment fills the metric placeholders of format with whole
+// percentages
+func promptSegment(format string, usage *api.UsageData) string {
+	var pairs []string
+	for _, name := range api.MetricNames {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprintf("%.0f%%", usage.Metric(name).Utilization))
+	}
+	return strings.NewReplacer(pairs...).Replace(format)
+}
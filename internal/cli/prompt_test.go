@@ -0,0 +1,21 @@
+package cli
+
+import (
+	"testing"
+
+	"claudebar/internal/api"
+)
+
+func TestPromptSegment(t *testing.T) {
+	usage := &api.UsageData{
+		FiveHour:       api.UsageStat{Utilization: 42.4},
+		SevenDay:       api.UsageStat{Utilization: 77},
+		SevenDaySonnet: api.UsageStat{Utilization: 3},
+	}
+	if got := promptSegment(defaultPromptFormat, usage); got != "⚡42%|77%" {
+		t.Errorf("default segment = %q, want ⚡42%%|77%%", got)
+	}
+	if got := promptSegment("S{sonnet} {unknown}", usage); got != "S3% {unknown}" {
+		t.Errorf("custom segment = %q", got)
+	}
+}
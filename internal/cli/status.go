@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+// Status exit codes. Failing to sign in is told apart from other failures so
+// scripts can ask for a new session key.
+const (
+	statusOK      = 0
+	statusNoAuth  = 1
+	statusBadArgs = 2
+	statusNoFetch = 3
+)
+
+// runStatus fetches usage once and prints each limit with its reset time, as
+// text or as a JSON record like those of watch
+func runStatus(args []string) int {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	format := fs.String("format", "text", "output format (text, json)")
+	if err := fs.Parse(args); err != nil {
+		return statusBadArgs
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "claudebar status: unsupported format %q\n", *format)
+		return statusBadArgs
+	}
+
+	client, err := connect()
+	var usage *api.UsageData
+	if err == nil {
+		usage, err = client.FetchUsage()
+	}
+
+	if *format == "json" {
+		json.NewEncoder(os.Stdout).Encode(newWatchRecord(time.Now(), usage, err))
+	} else if err != nil {
+		status, suggestion := api.Explain(err)
+		fmt.Fprintf(os.Stderr, "claudebar status: %s (%v)\n%s\n", status, err, suggestion)
+	} else {
+		writeStatus(os.Stdout, usage)
+	}
+
+	switch {
+	case err == nil:
+		return statusOK
+	case api.ErrorKind(err) == "auth":
+		return statusNoAuth
+	}
+	return statusNoFetch
+}
+
+// writeStatus writes a line per limit, e.g. "Session  42%  resets in 2h 14m".
+// The per-model limits are left out for accounts without them.
+func writeStatus(w io.Writer, usage *api.UsageData) {
+	for _, name := range api.MetricNames {
+		stat := usage.Metric(name)
+		if (name == "opus" || name == "sonnet") && stat.ResetsAt.IsZero() {
+			continue
+		}
+		line := fmt.Sprintf("%-8s %3.0f%%", strings.ToUpper(name[:1])+name[1:], stat.Utilization)
+		if !stat.ResetsAt.IsZero() {
+			line += "  resets in " + api.TimeUntilReset(stat.ResetsAt)
+		}
+		fmt.Fprintln(w, line)
+	}
+}
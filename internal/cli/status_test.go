@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+func TestWriteStatus(t *testing.T) {
+	usage := &api.UsageData{
+		FiveHour:       api.UsageStat{Utilization: 42, ResetsAt: time.Now().Add(2*time.Hour + 30*time.Second)},
+		SevenDay:       api.UsageStat{Utilization: 7},
+		SevenDaySonnet: api.UsageStat{Utilization: 3, ResetsAt: time.Now().Add(30 * time.Minute)},
+	}
+
+	var out bytes.Buffer
+	writeStatus(&out, usage)
+	want := "Session   42%  resets in 2h 0m\n" +
+		"Weekly     7%\n" +
+		"Sonnet     3%  resets in 29m\n"
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"claudebar/internal/api"
+	"claudebar/internal/config"
+)
+
+// minWatchInterval matches the GUI's shortest polling interval
+const minWatchInterval = 15 * time.Second
+
+// watchRecord is one line of watch output: the metrics of a successful fetch,
+// or the error of a failed one
+type watchRecord struct {
+	Time    time.Time            `json:"time"`
+	Metrics map[string]watchStat `json:"metrics,omitempty"`
+	Error   string               `json:"error,omitempty"`
+	Status  string               `json:"status,omitempty"` // short error category, e.g. "Rate limited"
+}
+
+// watchStat is a metric in a watch record
+type watchStat struct {
+	Utilization float64   `json:"utilization"`
+	ResetsAt    time.Time `json:"resets_at,omitzero"`
+}
+
+// runWatch polls usage until interrupted, writing one JSON line per fetch to
+// stdout
+func runWatch(args []string) int {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	format := fs.String("format", "jsonl", "output format (jsonl)")
+	seconds := fs.Int("interval", config.Get().RefreshInterval, "seconds between fetches (at least 15)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "jsonl" {
+		fmt.Fprintf(os.Stderr, "claudebar watch: unsupported format %q\n", *format)
+		return 2
+	}
+	interval := max(time.Duration(*seconds)*time.Second, minWatchInterval)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := watch(ctx, os.Stdout, reconnecting(), interval); err != nil {
+		fmt.Fprintf(os.Stderr, "claudebar watch: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// reconnecting returns a fetch function for polling: it signs in on the
+// first call, and again after the session was rejected
+func reconnecting() func() (*api.UsageData, error) {
+	var client *api.Client
+	return func() (*api.UsageData, error) {
+		if client == nil {
+			c, err := connect()
+			if err != nil {
+				return nil, err
+			}
+			client = c
+		}
+		usage, err := client.FetchUsage()
+		if errors.Is(err, api.ErrSessionExpired) || errors.Is(err, api.ErrUnauthorized) {
+			client = nil // authenticate again on the next fetch
+		}
+		return usage, err
+	}
+}
+
+// watch calls fetch every interval, backing off while rate limited, and
+// writes a record for each result to w until ctx is done
+func watch(ctx context.Context, w io.Writer, fetch func() (*api.UsageData, error), interval time.Duration) error {
+	enc := json.NewEncoder(w)
+	rateLimited := 0
+	for {
+		usage, err := fetch()
+		if err := enc.Encode(newWatchRecord(time.Now(), usage, err)); err != nil {
+			return err
+		}
+
+		wait := interval
+		if errors.Is(err, api.ErrRateLimited) {
+			rateLimited++
+			wait = max(wait, api.RateLimitBackoff(rateLimited))
+		} else {
+			rateLimited = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// newWatchRecord builds the record for a fetch result
+func newWatchRecord(now time.Time, usage *api.UsageData, err error) watchRecord {
+	rec := watchRecord{Time: now}
+	if err != nil {
+		rec.Error = err.Error()
+		rec.Status, _ = api.Explain(err)
+		return rec
+	}
+	rec.Metrics = make(map[string]watchStat, len(api.MetricNames))
+	for _, name := range api.MetricNames {
+		stat := usage.Metric(name)
+		rec.Metrics[name] = watchStat{Utilization: stat.Utilization, ResetsAt: stat.ResetsAt}
+	}
+	return rec
+}
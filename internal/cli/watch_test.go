@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results := []error{nil, api.ErrSessionExpired, nil}
+	fetch := func() (*api.UsageData, error) {
+		err := results[0]
+		results = results[1:]
+		if len(results) == 0 {
+			cancel()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &api.UsageData{FiveHour: api.UsageStat{Utilization: 42}}, nil
+	}
+
+	var out bytes.Buffer
+	if err := watch(ctx, &out, fetch, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	var records []watchRecord
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var rec watchRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+	if got := records[0].Metrics["session"].Utilization; got != 42 || records[0].Error != "" {
+		t.Errorf("first record = %+v, want session at 42%%", records[0])
+	}
+	if records[1].Error == "" || records[1].Status != "Session expired" || records[1].Metrics != nil {
+		t.Errorf("second record = %+v, want a session expired error", records[1])
+	}
+	if _, ok := records[2].Metrics["opus"]; !ok {
+		t.Errorf("third record = %+v, want every metric", records[2])
+	}
+}
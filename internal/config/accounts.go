@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// Account is a claude.ai account to switch to, see Config.Accounts
+type Account struct {
+	Name                 string `json:"name"`
+	SessionKey           string `json:"session_key,omitempty"`
+	OrganizationID       string `json:"organization_id,omitempty"`
+	OrganizationOverride string `json:"organization_override,omitempty"`
+}
+
+// DefaultAccountName names the account used so far when a second one is added
+const DefaultAccountName = "Default"
+
+var (
+	ErrAccountName     = errors.New("account name is empty")
+	ErrAccountExists   = errors.New("an account with that name already exists")
+	ErrAccountNotFound = errors.New("no account with that name")
+	ErrAccountActive   = errors.New("the active account can't be removed")
+)
+
+// AccountNames returns the names of the accounts in order, none while there
+// is only the one
+func (c *Config) AccountNames() []string {
+	names := make([]string, 0, len(c.Accounts))
+	for _, a := range c.Accounts {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+// account returns the index of the named account, or -1
+func (c *Config) account(name string) int {
+	return slices.IndexFunc(c.Accounts, func(a Account) bool { return a.Name == name })
+}
+
+// checkAccountName trims name and checks it is free
+func (c *Config) checkAccountName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", ErrAccountName
+	case c.account(name) >= 0, c.ActiveAccount == "" && name == DefaultAccountName:
+		return "", ErrAccountExists
+	}
+	return name, nil
+}
+
+// AddAccount adds an account without a session key, to switch to and sign
+// in, and saves. The account used so far is named DefaultAccountName.
+func (c *Config) AddAccount(name string) error {
+	name, err := c.checkAccountName(name)
+	if err != nil {
+		return err
+	}
+	if c.ActiveAccount == "" {
+		c.ActiveAccount = DefaultAccountName
+		c.Accounts = append(c.Accounts, Account{Name: DefaultAccountName})
+	}
+	c.Accounts = append(c.Accounts, Account{Name: name})
+	return c.Save()
+}
+
+// RenameAccount renames an account and saves
+func (c *Config) RenameAccount(name, newName string) error {
+	i := c.account(name)
+	if i < 0 {
+		return ErrAccountNotFound
+	}
+	newName, err := c.checkAccountName(newName)
+	if err != nil {
+		return err
+	}
+	c.Accounts[i].Name = newName
+	if c.ActiveAccount == name {
+		c.ActiveAccount = newName
+	}
+	return c.Save()
+}
+
+// RemoveAccount forgets an account other than the active one and saves.
+// Once only the active account is left, it loses its name again.
+func (c *Config) RemoveAccount(name string) error {
+	i := c.account(name)
+	switch {
+	case i < 0:
+		return ErrAccountNotFound
+	case name == c.ActiveAccount:
+		return ErrAccountActive
+	}
+	c.Accounts = slices.Delete(c.Accounts, i, i+1)
+	if len(c.Accounts) == 1 {
+		c.Accounts, c.ActiveAccount = nil, ""
+	}
+	return c.Save()
+}
+
+// SwitchAccount makes the named account the active one and saves: the
+// session key and organization of the account used so far go into its
+// entry, and the named account's take their place
+func (c *Config) SwitchAccount(name string) error {
+	i := c.account(name)
+	if i < 0 {
+		return ErrAccountNotFound
+	}
+	if name == c.ActiveAccount {
+		return nil
+	}
+	if j := c.account(c.ActiveAccount); j >= 0 {
+		c.Accounts[j] = Account{
+			Name:                 c.ActiveAccount,
+			SessionKey:           c.SessionKey,
+			OrganizationID:       c.OrganizationID,
+			OrganizationOverride: c.OrganizationOverride,
+		}
+	}
+	next := c.Accounts[i]
+	c.Accounts[i] = Account{Name: name}
+	c.SessionKey, c.OrganizationID, c.OrganizationOverride = next.SessionKey, next.OrganizationID, next.OrganizationOverride
+	c.ActiveAccount = name
+	return c.Save()
+}
@@ -1,27 +1,269 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strings"
 	"sync"
+	"time"
+
+	"claudebar/internal/alert"
+	"claudebar/internal/claudecode"
+	"claudebar/internal/secrets"
 )
 
 // Config holds all application settings
 type Config struct {
-	SessionKey      string         `json:"session_key,omitempty"`
-	OrganizationID  string         `json:"organization_id,omitempty"`
-	RefreshInterval int            `json:"refresh_interval"` // seconds
-	OverlayEnabled  bool           `json:"overlay_enabled"`
-	OverlayOpacity  float64        `json:"overlay_opacity"`
-	OverlayPosition string         `json:"overlay_position"` // "left", "right", "top", "floating"
-	OverlayX        int            `json:"overlay_x"`
-	OverlayY        int            `json:"overlay_y"`
+	SessionKey           string       `json:"session_key,omitempty"`
+	OrganizationID       string       `json:"organization_id,omitempty"`
+	RefreshInterval      int          `json:"refresh_interval"` // seconds
+	OverlayEnabled       bool         `json:"overlay_enabled"`
+	OverlayOpacity       float64      `json:"overlay_opacity"`
+	OverlayOpacityMode   string       `json:"overlay_opacity_mode,omitempty"` // "" fades the whole window, "background" only the background
+	AdaptiveText         bool         `json:"adaptive_text"`                  // switch to dark text over light desktops
+	ClickThrough         bool         `json:"click_through"`                  // mouse clicks pass through the overlay
+	OverlayPosition      string       `json:"overlay_position"`               // "left", "right", "top", "floating"
+	OverlayX             int          `json:"overlay_x"`
+	OverlayY             int          `json:"overlay_y"`
 	VisibleStats         VisibleStats `json:"visible_stats"`
 	AutoStart            bool         `json:"auto_start"`
 	NotificationsEnabled bool         `json:"notifications_enabled"`
 	AlertThresholds      []float64    `json:"alert_thresholds"`
+	WarningThreshold     float64      `json:"warning_threshold"`  // usage that counts as a warning
+	CriticalThreshold    float64      `json:"critical_threshold"` // usage that counts as critical
+	AlertSound           bool         `json:"alert_sound"`        // play a sound with warning/critical alerts
+	Theme                string       `json:"theme,omitempty"`    // theme file name in the themes folder, "" = built-in
+
+	// MetricColors overrides the theme's bar color per metric, as #RRGGBB by
+	// metric name, e.g. {"opus": "#a855f7", "sonnet": "#14b8a6"}
+	MetricColors map[string]string `json:"metric_colors,omitempty"`
+
+	// OrganizationOverride is an organization UUID entered in Settings,
+	// used instead of the detected organization when detection fails
+	OrganizationOverride string `json:"organization_override,omitempty"`
+
+	// Domain is the claude.ai host to use instead of claude.ai, for accounts
+	// served from another hostname, see Host
+	Domain string `json:"domain,omitempty"`
+
+	// Accounts are named accounts to switch between from the tray, e.g. a
+	// personal and a work account, or one session in two organizations.
+	// The active account's session key and organization are the fields
+	// above; its entry here only keeps its name. OverlayAllAccounts lists
+	// the other accounts' usage in the overlay too.
+	Accounts           []Account `json:"accounts,omitempty"`
+	ActiveAccount      string    `json:"active_account,omitempty"`
+	OverlayAllAccounts bool      `json:"overlay_all_accounts,omitempty"`
+
+	// PositionOpacity overrides OverlayOpacity at individual snap positions
+	// ("top", "floating", ...), see OpacityFor
+	PositionOpacity map[string]float64 `json:"position_opacity,omitempty"`
+
+	// DodgeTopmost moves the snapped overlay to the nearest free snap
+	// position while another app's always-on-top window, such as a video
+	// call's controls, covers it, and back once its position is free
+	DodgeTopmost bool `json:"dodge_topmost,omitempty"`
+
+	// AlertProfiles are named threshold sets that replace AlertThresholds and
+	// the warning/critical levels while active. AlertProfile is the one picked
+	// from the tray; "" picks automatically by each profile's schedule.
+	AlertProfiles []alert.Profile `json:"alert_profiles,omitempty"`
+	AlertProfile  string          `json:"alert_profile,omitempty"`
+
+	// Progress bar style per layout: "solid", "segmented", "line", and
+	// "radial" for the compact layout only
+	BarStyle        string `json:"bar_style,omitempty"`
+	CompactBarStyle string `json:"compact_bar_style,omitempty"`
+
+	// TrayTitle picks the metric shown as text next to the tray icon where
+	// the platform supports it: "session", "weekly", "highest" or "" (off)
+	TrayTitle string `json:"tray_title,omitempty"`
+
+	// TrayClick is what a left click on the tray icon does: "overlay" shows
+	// or hides the overlay, "settings" opens Settings, "refresh" fetches
+	// usage; "" opens the menu, which the right click always does
+	TrayClick string `json:"tray_click,omitempty"`
+
+	// Precision is how many decimals (0-2) utilization is shown with in the
+	// overlay's usage rows, the tray menu and the tray tooltip. The top bar
+	// and tray text keep whole percent so their width stays fixed.
+	Precision int `json:"precision,omitempty"`
+
+	// Labels overrides overlay texts by label ID ("session", "weekly",
+	// "weekly_header", "session_short", "weekly_short")
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// HotkeyExcludedApps are process names ("idea64.exe", "code") that get
+	// the hotkey combinations while focused; ClaudeBar releases them meanwhile
+	HotkeyExcludedApps []string `json:"hotkey_excluded_apps,omitempty"`
+
+	// DoublePress maps hotkeys ("toggle_overlay", "next_page", "snap_left",
+	// ...) to an action run when the hotkey is pressed twice within
+	// DoublePressInterval milliseconds (0 = 400): "click_through",
+	// "overlay", "settings", "next_page" or "refresh". Such a hotkey runs
+	// its usual action only once the interval passed without a second press.
+	DoublePress         map[string]string `json:"double_press,omitempty"`
+	DoublePressInterval int               `json:"double_press_interval,omitempty"`
+
+	// Spike filtering: a single-poll jump larger than SpikeThreshold
+	// percentage points is held back until the next poll confirms it
+	SpikeFilterEnabled bool    `json:"spike_filter_enabled"`
+	SpikeThreshold     float64 `json:"spike_threshold"`
+
+	// Weekly budget planner: keep weekly usage under BudgetTarget percent
+	// until the end of BudgetDay ("monday".."sunday"). With BudgetLock,
+	// opening claude.ai from ClaudeBar asks first while usage is over plan.
+	BudgetEnabled bool    `json:"budget_enabled"`
+	BudgetTarget  float64 `json:"budget_target"`
+	BudgetDay     string  `json:"budget_day"`
+	BudgetLock    bool    `json:"budget_lock"`
+
+	// StatsEnabled shows streaks in the History window and sends a summary
+	// of the past month at the start of each month
+	StatsEnabled bool `json:"stats_enabled"`
+
+	// DailySummaryTime ("18:00", local time) is when the end-of-day
+	// notification of today's usage and the weekly headroom left is sent;
+	// empty sends none
+	DailySummaryTime string `json:"daily_summary_time,omitempty"`
+
+	// RequestEstimate shows roughly how many more "typical" or "heavy"
+	// requests fit in the session; empty hides it
+	RequestEstimate string `json:"request_estimate,omitempty"`
+
+	// ClaudeCodeEnabled shows the tokens Claude Code spent on this machine
+	// and their estimated cost in the overlay, read from its logs in
+	// ClaudeCodeDir ("" = ~/.claude/projects)
+	ClaudeCodeEnabled bool   `json:"claude_code_enabled"`
+	ClaudeCodeDir     string `json:"claude_code_dir,omitempty"`
+
+	// CostEstimate shows the estimated dollar value of the session and week
+	// in the overlay and tray menu: "logs" prices the tokens Claude Code
+	// logged on this machine, "utilization" values the utilization as a
+	// share of CostSession and CostWeekly, the dollars a full session and
+	// week are worth; empty hides it. ModelPrices replaces the API list
+	// prices of models whose name contains a key ("opus-4-5"), in USD per
+	// million tokens.
+	CostEstimate string                      `json:"cost_estimate,omitempty"`
+	CostSession  float64                     `json:"cost_session,omitempty"`
+	CostWeekly   float64                     `json:"cost_weekly,omitempty"`
+	ModelPrices  map[string]claudecode.Price `json:"model_prices,omitempty"`
+
+	// HistoryRetentionDays is how long completed weekly cycles and usage
+	// samples stay in the history; 0 keeps them forever.
+	// SampleRetentionDays is how long samples are kept as fetched before
+	// they are rolled up into one per hour; 0 never rolls them up.
+	HistoryRetentionDays int `json:"history_retention_days"`
+	SampleRetentionDays  int `json:"sample_retention_days"`
+
+	// Reduced motion and battery saver follow the OS unless overridden with
+	// "on" or "off". While saving battery, polling slows to at least
+	// BatteryRefreshInterval seconds.
+	ReduceMotion           string `json:"reduce_motion,omitempty"`
+	BatterySaver           string `json:"battery_saver,omitempty"`
+	BatteryRefreshInterval int    `json:"battery_refresh_interval"`
+
+	// LightPolling polls four times less often while the overlay is hidden
+	// and usage isn't close to an alert threshold
+	LightPolling bool `json:"light_polling"`
+
+	// Attention cues when session or weekly usage reaches the critical
+	// level, which work with notifications off: a pulse of the overlay
+	// border and a flash of the taskbar button
+	FlashBorder  bool `json:"flash_border"`
+	FlashTaskbar bool `json:"flash_taskbar"`
+
+	// DegradedMode follows Remote Desktop, Citrix and virtual machine
+	// detection unless overridden with "on" or "off". Degraded mode turns
+	// animations off and polls like battery saver.
+	DegradedMode string `json:"degraded_mode,omitempty"`
+
+	// HTTPHeaders overrides request headers sent to claude.ai, for proxies
+	// that need extra ones; an empty value removes a header.
+	// DisableClientHints drops the sec-ch-ua headers.
+	HTTPHeaders        map[string]string `json:"http_headers,omitempty"`
+	DisableClientHints bool              `json:"disable_client_hints,omitempty"`
+
+	// CertificatePins are base64 SHA-256 hashes of public keys, one of which
+	// claude.ai's certificate chain must contain. CABundle is a PEM file of
+	// extra trusted CAs, for proxies that inspect HTTPS. Both apply at startup.
+	CertificatePins []string `json:"certificate_pins,omitempty"`
+	CABundle        string   `json:"ca_bundle,omitempty"`
+
+	// LastSeenVersion is the version that last ran, to show what's new
+	// once after an update
+	LastSeenVersion string `json:"last_seen_version,omitempty"`
+
+	// Local server for launchers and other tools, on 127.0.0.1:ServerPort.
+	// The dashboard serves the same port on all interfaces, behind a token.
+	// ServerBind overrides the listen address; ServerToken is the bearer
+	// token API clients must send, required for calls from other hosts.
+	// MetricsEnabled serves Prometheus metrics at /metrics like the API.
+	ServerEnabled    bool   `json:"server_enabled"`
+	ServerPort       int    `json:"server_port"`
+	ServerBind       string `json:"server_bind,omitempty"`
+	ServerToken      string `json:"server_token,omitempty"`
+	ServerTLS        bool   `json:"server_tls"`
+	DashboardEnabled bool   `json:"dashboard_enabled"`
+	DashboardToken   string `json:"dashboard_token,omitempty"`
+	MetricsEnabled   bool   `json:"metrics_enabled"`
+
+	// DebugLogging writes secrets to the log unmasked; only builds made with
+	// -tags debug honor it
+	DebugLogging bool `json:"debug_logging,omitempty"`
+
+	// SessionKeyStorage is where the session key is kept: "keychain" for the
+	// OS credential store, leaving it out of this file; "file" keeps it here.
+	// Empty moves the key to the keychain when it is next saved or loaded.
+	SessionKeyStorage string `json:"session_key_storage,omitempty"`
+
+	// Cross-device sync via an encrypted file in a shared folder. The
+	// passphrase is stored here in plain text, protected only by the file's
+	// permissions (0600).
+	SyncEnabled    bool      `json:"sync_enabled"`
+	SyncFolder     string    `json:"sync_folder,omitempty"`
+	SyncPassphrase string    `json:"sync_passphrase,omitempty"`
+	LastSyncAt     time.Time `json:"last_sync_at,omitempty"`
+
+	// storedKeys are the session keys last read from or written to the
+	// keychain by name (see sessionKeys), and keychainErr its first failure,
+	// after which the keys stay in the file until restart
+	storedKeys  map[string]string
+	keychainErr error
+}
+
+// Session key storage, see SessionKeyStorage
+const (
+	StorageKeychain = "keychain"
+	StorageFile     = "file"
+)
+
+// sessionKeyName names the session key in the credential store; the other
+// accounts' keys are named sessionKeyName + "/" + the account name. See
+// secretName for instances with their own config directory.
+const sessionKeyName = "session_key"
+
+// secretStore holds the session key when it isn't in the file; tests
+// replace it
+var secretStore secrets.Store = secrets.System()
+
+// SetSecretStore makes store hold the session keys instead of the OS
+// credential store and returns the one it replaces. Tests use it through
+// configtest.
+func SetSecretStore(store secrets.Store) secrets.Store {
+	mu.Lock()
+	defer mu.Unlock()
+	saved := secretStore
+	secretStore = store
+	return saved
 }
 
 // VisibleStats controls which stats are shown
@@ -30,12 +272,13 @@ type VisibleStats struct {
 	DailyUsage   bool `json:"daily_usage"`
 	WeeklyUsage  bool `json:"weekly_usage"`
 	ResetTime    bool `json:"reset_time"`
+	Sparklines   bool `json:"sparklines"` // trend lines under the session and weekly rows
 }
 
 var (
-	instance *Config
-	once     sync.Once
-	mu       sync.RWMutex
+	instance   *Config
+	once       sync.Once
+	mu         sync.RWMutex
 	configPath string
 )
 
@@ -53,10 +296,20 @@ func Default() *Config {
 			DailyUsage:   true,
 			WeeklyUsage:  true,
 			ResetTime:    true,
+			Sparklines:   true,
 		},
-		AutoStart:            false,
-		NotificationsEnabled: true,
-		AlertThresholds:      []float64{50, 75, 90},
+		AutoStart:              false,
+		NotificationsEnabled:   true,
+		AlertThresholds:        []float64{50, 75, 90},
+		WarningThreshold:       75,
+		CriticalThreshold:      90,
+		SpikeThreshold:         25,
+		BudgetTarget:           80,
+		BudgetDay:              "friday",
+		BatteryRefreshInterval: 180,
+		ServerPort:             47821,
+		HistoryRetentionDays:   365,
+		SampleRetentionDays:    30,
 	}
 }
 
@@ -69,8 +322,23 @@ func Get() *Config {
 	return instance
 }
 
-// getConfigPath returns the path to the config file.
-// Uses platform-appropriate directories:
+// EnvDir names the environment variable that overrides the config
+// directory, like the --config-dir flag
+const EnvDir = "CLAUDEBAR_CONFIG_DIR"
+
+// dirOverride replaces the platform config directory; see SetDir
+var dirOverride string
+
+// SetDir makes dir the config directory instead of the platform default, so
+// several isolated instances can run side by side. Call it before anything
+// reads the config; an empty dir restores the default.
+func SetDir(dir string) {
+	dirOverride = dir
+	configPath = ""
+}
+
+// getConfigPath returns the path to the config file, in the directory set
+// with SetDir or $CLAUDEBAR_CONFIG_DIR, or else the platform's:
 //   - Windows: %APPDATA%\ClaudeBar\config.json
 //   - macOS:   ~/Library/Application Support/ClaudeBar/config.json
 //   - Linux:   ~/.config/claudebar/config.json (XDG_CONFIG_HOME)
@@ -79,8 +347,34 @@ func getConfigPath() (string, error) {
 		return configPath, nil
 	}
 
-	var dir string
+	dir := dirOverride
+	if dir == "" {
+		dir = os.Getenv(EnvDir)
+	}
+	if dir != "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return "", err
+		}
+		dir = abs
+	} else {
+		def, err := defaultDir()
+		if err != nil {
+			return "", err
+		}
+		dir = def
+	}
 
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	configPath = filepath.Join(dir, "config.json")
+	return configPath, nil
+}
+
+// defaultDir returns the platform's config directory for ClaudeBar
+func defaultDir() (string, error) {
 	switch runtime.GOOS {
 	case "windows":
 		appData := os.Getenv("APPDATA")
@@ -91,14 +385,14 @@ func getConfigPath() (string, error) {
 			}
 			appData = filepath.Join(home, "AppData", "Roaming")
 		}
-		dir = filepath.Join(appData, "ClaudeBar")
+		return filepath.Join(appData, "ClaudeBar"), nil
 
 	case "darwin":
 		home, err := os.UserHomeDir()
 		if err != nil {
 			return "", err
 		}
-		dir = filepath.Join(home, "Library", "Application Support", "ClaudeBar")
+		return filepath.Join(home, "Library", "Application Support", "ClaudeBar"), nil
 
 	default: // linux and others
 		configHome := os.Getenv("XDG_CONFIG_HOME")
@@ -109,15 +403,17 @@ func getConfigPath() (string, error) {
 			}
 			configHome = filepath.Join(home, ".config")
 		}
-		dir = filepath.Join(configHome, "claudebar")
+		return filepath.Join(configHome, "claudebar"), nil
 	}
+}
 
-	if err := os.MkdirAll(dir, 0755); err != nil {
+// Dir returns the directory holding the config file and other app data
+func Dir() (string, error) {
+	path, err := getConfigPath()
+	if err != nil {
 		return "", err
 	}
-
-	configPath = filepath.Join(dir, "config.json")
-	return configPath, nil
+	return filepath.Dir(path), nil
 }
 
 // Load reads the config from disk
@@ -138,6 +434,10 @@ func (c *Config) Load() error {
 		return err
 	}
 
+	// A key kept in the keychain is missing from the file, and the decoded
+	// accounts would keep the fields of those already here
+	c.SessionKey = ""
+	c.Accounts = nil
 	if err := json.Unmarshal(data, c); err != nil {
 		return err
 	}
@@ -146,11 +446,157 @@ func (c *Config) Load() error {
 	if c.AlertThresholds == nil {
 		c.AlertThresholds = []float64{50, 75, 90}
 	}
+	if c.WarningThreshold <= 0 {
+		c.WarningThreshold = 75
+	}
+	if c.CriticalThreshold <= 0 {
+		c.CriticalThreshold = 90
+	}
+	if c.SpikeThreshold <= 0 {
+		c.SpikeThreshold = 25
+	}
+	if c.BudgetTarget <= 0 {
+		c.BudgetTarget = 80
+	}
+	if c.BudgetDay == "" {
+		c.BudgetDay = "friday"
+	}
+	if c.BatteryRefreshInterval <= 0 {
+		c.BatteryRefreshInterval = 180
+	}
+	if c.ServerPort <= 0 {
+		c.ServerPort = 47821
+	}
 
-	return nil
+	return c.loadSessionKeys(path)
+}
+
+// loadSessionKeys reads the session keys from the keychain, or moves keys
+// found in the file there. Must be called with mu held.
+func (c *Config) loadSessionKeys(path string) error {
+	keys := c.sessionKeys()
+	plaintext := false
+	for _, key := range keys {
+		plaintext = plaintext || *key != ""
+	}
+
+	if c.SessionKeyStorage == StorageKeychain {
+		c.storedKeys = map[string]string{}
+		for name, key := range keys {
+			if *key != "" {
+				continue // moved to the keychain below
+			}
+			stored, err := secretStore.Get(name)
+			if errors.Is(err, secrets.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				log.Printf("Config: failed to read the session key from the keychain: %v", err)
+				c.keychainErr = err
+				return nil
+			}
+			*key, c.storedKeys[name] = stored, stored
+		}
+	}
+
+	// Plaintext keys, from before the keychain or after it failed
+	if !plaintext {
+		return nil
+	}
+	c.storeSessionKeys()
+	if c.SessionKeyStorage != StorageKeychain {
+		return nil
+	}
+	log.Printf("Config: moved the session key to the keychain")
+	return c.write(path)
+}
+
+// sessionKeys returns the fields holding session keys by their name in the
+// keychain: the active account's, and the other accounts' in their entries
+func (c *Config) sessionKeys() map[string]*string {
+	keys := map[string]*string{secretName(sessionKeyName): &c.SessionKey}
+	for i := range c.Accounts {
+		if c.Accounts[i].Name != c.ActiveAccount {
+			keys[secretName(sessionKeyName+"/"+c.Accounts[i].Name)] = &c.Accounts[i].SessionKey
+		}
+	}
+	return keys
+}
+
+// secretName returns the credential store entry for name. Outside the
+// platform's config directory it ends in a hash of the directory, so
+// instances started with --config-dir keep their keys apart.
+func secretName(name string) string {
+	path, err := getConfigPath()
+	if err != nil {
+		return name
+	}
+	dir := filepath.Dir(path)
+	if def, err := defaultDir(); err == nil && dir == def {
+		return name
+	}
+	sum := sha256.Sum256([]byte(dir))
+	return name + "@" + hex.EncodeToString(sum[:6])
+}
+
+// storeSessionKeys writes the session keys that changed to the keychain and
+// deletes those of removed accounts, falling back to the file when the
+// keychain fails. Must be called with mu held.
+func (c *Config) storeSessionKeys() {
+	if c.SessionKeyStorage == StorageFile {
+		return
+	}
+	keys := c.sessionKeys()
+	changed := map[string]string{}
+	for name, key := range keys {
+		if *key != c.storedKeys[name] {
+			changed[name] = *key
+		}
+	}
+	for name := range c.storedKeys {
+		if _, ok := keys[name]; !ok {
+			changed[name] = ""
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	err := c.keychainErr
+	for name, key := range changed {
+		if err != nil {
+			break
+		}
+		if key == "" {
+			err = secretStore.Delete(name)
+		} else {
+			err = secretStore.Set(name, key)
+		}
+	}
+	if err != nil {
+		if c.keychainErr == nil {
+			log.Printf("Config: keeping the session key in config.json, the keychain failed: %v", err)
+			c.keychainErr = err
+		}
+		// Asking again on every start would repeat a prompt the user dismissed
+		if errors.Is(err, secrets.ErrDenied) {
+			c.SessionKeyStorage = StorageFile
+		} else {
+			c.SessionKeyStorage = ""
+		}
+		return
+	}
+
+	c.SessionKeyStorage = StorageKeychain
+	c.storedKeys = map[string]string{}
+	for name, key := range keys {
+		if *key != "" {
+			c.storedKeys[name] = *key
+		}
+	}
 }
 
-// Save writes the config to disk
+// Save writes the config to disk, and the session keys to the keychain
 func (c *Config) Save() error {
 	mu.Lock()
 	defer mu.Unlock()
@@ -160,7 +606,22 @@ func (c *Config) Save() error {
 		return err
 	}
 
-	data, err := json.MarshalIndent(c, "", "  ")
+	c.storeSessionKeys()
+	return c.write(path)
+}
+
+// write writes the config to path, without the session keys kept in the
+// keychain. Must be called with mu held.
+func (c *Config) write(path string) error {
+	file := *c
+	if file.SessionKeyStorage == StorageKeychain {
+		file.SessionKey = ""
+		file.Accounts = slices.Clone(c.Accounts)
+		for i := range file.Accounts {
+			file.Accounts[i].SessionKey = ""
+		}
+	}
+	data, err := json.MarshalIndent(&file, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -168,12 +629,81 @@ func (c *Config) Save() error {
 	return os.WriteFile(path, data, 0600)
 }
 
+// SyncSnapshot returns the config as JSON for writing to the sync file,
+// without the device-local settings (see copyLocal)
+func (c *Config) SyncSnapshot() ([]byte, error) {
+	mu.RLock()
+	snapshot := *c
+	mu.RUnlock()
+	copyLocal(&snapshot, &Config{})
+	return json.Marshal(&snapshot)
+}
+
+// ApplySynced replaces the config with a snapshot from another device and
+// saves, keeping the device-local settings (see copyLocal) as they are
+func (c *Config) ApplySynced(data []byte) error {
+	mu.Lock()
+	local := *c
+	// Maps and slices would be merged into rather than replaced, bringing
+	// back entries the other device deleted
+	c.Labels = nil
+	c.DoublePress = nil
+	c.PositionOpacity = nil
+	c.AlertThresholds = nil
+	c.AlertProfiles = nil
+	c.Accounts = nil
+
+	err := json.Unmarshal(data, c)
+	if err != nil {
+		*c = local
+	} else {
+		copyLocal(c, &local)
+	}
+	mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	return c.Save()
+}
+
+// copyLocal copies the settings that never sync from src to dst: sync
+// settings, window coordinates, the degraded mode override, the last version
+// run, the proxy headers, certificate trust and where the session key is
+// stored differ between machines, and autostart, hotkey exclusions, the local
+// server, dashboard and metrics, and debug logging are per-machine choices that would
+// open ports or leak secrets on every device if one of them changed
+func copyLocal(dst, src *Config) {
+	dst.SyncEnabled, dst.SyncFolder, dst.SyncPassphrase = src.SyncEnabled, src.SyncFolder, src.SyncPassphrase
+	dst.LastSyncAt = src.LastSyncAt
+	dst.OverlayX, dst.OverlayY = src.OverlayX, src.OverlayY
+	dst.DegradedMode = src.DegradedMode
+	dst.LastSeenVersion = src.LastSeenVersion
+	dst.HTTPHeaders, dst.DisableClientHints = src.HTTPHeaders, src.DisableClientHints
+	dst.CertificatePins, dst.CABundle = src.CertificatePins, src.CABundle
+	dst.AutoStart = src.AutoStart
+	dst.HotkeyExcludedApps = src.HotkeyExcludedApps
+	dst.ServerEnabled, dst.ServerPort, dst.ServerBind = src.ServerEnabled, src.ServerPort, src.ServerBind
+	dst.ServerToken, dst.ServerTLS = src.ServerToken, src.ServerTLS
+	dst.DashboardEnabled, dst.DashboardToken = src.DashboardEnabled, src.DashboardToken
+	dst.MetricsEnabled = src.MetricsEnabled
+	dst.ClaudeCodeEnabled, dst.ClaudeCodeDir = src.ClaudeCodeEnabled, src.ClaudeCodeDir
+	dst.DebugLogging = src.DebugLogging
+	dst.SessionKeyStorage = src.SessionKeyStorage
+}
+
 // SetSessionKey updates the session key and saves
 func (c *Config) SetSessionKey(key string) error {
 	c.SessionKey = key
 	return c.Save()
 }
 
+// DoublePressWindow returns how soon a second hotkey press must follow the
+// first to count as a double press, 0 for the default
+func (c *Config) DoublePressWindow() time.Duration {
+	return time.Duration(max(c.DoublePressInterval, 0)) * time.Millisecond
+}
+
 // SetOrganizationID updates the org ID and saves
 func (c *Config) SetOrganizationID(id string) error {
 	c.OrganizationID = id
@@ -186,6 +716,39 @@ func (c *Config) SetOverlayPosition(pos string) error {
 	return c.Save()
 }
 
+// DefaultHost is the Claude web host used when Domain is empty
+const DefaultHost = "claude.ai"
+
+// Host returns the Claude web host: Domain without any scheme, path or port,
+// or DefaultHost when it's empty
+func (c *Config) Host() string {
+	host := strings.TrimSpace(c.Domain)
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	host, _, _ = strings.Cut(host, "/")
+	host, _, _ = strings.Cut(host, ":")
+	if host == "" {
+		return DefaultHost
+	}
+	return strings.ToLower(host)
+}
+
+// SetDomain updates the Claude web host and saves
+func (c *Config) SetDomain(domain string) error {
+	c.Domain = domain
+	return c.Save()
+}
+
+// OpacityFor returns the overlay opacity at a snap position: its entry in
+// PositionOpacity when that is in (0, 1], OverlayOpacity otherwise
+func (c *Config) OpacityFor(pos string) float64 {
+	if o, ok := c.PositionOpacity[pos]; ok && o > 0 && o <= 1 {
+		return o
+	}
+	return c.OverlayOpacity
+}
+
 // SetOverlayCoords updates overlay coordinates
 func (c *Config) SetOverlayCoords(x, y int) error {
 	c.OverlayX = x
@@ -199,6 +762,65 @@ func (c *Config) ToggleOverlay() error {
 	return c.Save()
 }
 
+// MaxPrecision is the most decimals utilization can be shown with
+const MaxPrecision = 2
+
+// Decimals returns Precision limited to 0-MaxPrecision
+func (c *Config) Decimals() int {
+	return min(max(c.Precision, 0), MaxPrecision)
+}
+
+// FormatPercent formats a utilization with the configured decimals, e.g.
+// "42.37%"
+func (c *Config) FormatPercent(pct float64) string {
+	return fmt.Sprintf("%.*f%%", c.Decimals(), pct)
+}
+
+// AlertLevels returns the warning and critical usage levels in effect,
+// taking the active alert profile into account
+func (c *Config) AlertLevels() alert.Levels {
+	levels := alert.Levels{Warning: c.WarningThreshold, Critical: c.CriticalThreshold}
+	if p := c.ActiveAlertProfile(time.Now()); p != nil {
+		levels = p.Levels(levels)
+	}
+	return levels
+}
+
+// CurrentAlertThresholds returns the notification thresholds in effect:
+// the active profile's, or AlertThresholds when none is active
+func (c *Config) CurrentAlertThresholds() []float64 {
+	if p := c.ActiveAlertProfile(time.Now()); p != nil && p.Thresholds != nil {
+		return p.Thresholds
+	}
+	return c.AlertThresholds
+}
+
+// ActiveAlertProfile returns the profile in effect at t: the one picked by
+// name, otherwise the first whose schedule covers t. Returns nil when the
+// global thresholds apply.
+func (c *Config) ActiveAlertProfile(t time.Time) *alert.Profile {
+	for i := range c.AlertProfiles {
+		if c.AlertProfile != "" && c.AlertProfiles[i].Name == c.AlertProfile {
+			return &c.AlertProfiles[i]
+		}
+	}
+	if c.AlertProfile != "" {
+		return nil
+	}
+	for i := range c.AlertProfiles {
+		if c.AlertProfiles[i].ActiveAt(t) {
+			return &c.AlertProfiles[i]
+		}
+	}
+	return nil
+}
+
+// SetAlertProfile picks an alert profile by name, "" for automatic, and saves
+func (c *Config) SetAlertProfile(name string) error {
+	c.AlertProfile = name
+	return c.Save()
+}
+
 // IsStatVisible checks if a stat type should be shown
 func (c *Config) IsStatVisible(statType string) bool {
 	switch statType {
@@ -210,6 +832,8 @@ func (c *Config) IsStatVisible(statType string) bool {
 		return c.VisibleStats.WeeklyUsage
 	case "reset":
 		return c.VisibleStats.ResetTime
+	case "sparklines":
+		return c.VisibleStats.Sparklines
 	default:
 		return true
 	}
@@ -260,6 +884,8 @@ func (c *Config) ToggleStat(statType string) error {
 		c.VisibleStats.WeeklyUsage = !c.VisibleStats.WeeklyUsage
 	case "reset":
 		c.VisibleStats.ResetTime = !c.VisibleStats.ResetTime
+	case "sparklines":
+		c.VisibleStats.Sparklines = !c.VisibleStats.Sparklines
 	}
 	return c.Save()
 }
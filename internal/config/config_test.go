@@ -0,0 +1,396 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"claudebar/internal/secrets"
+)
+
+func TestSyncSnapshotOmitsLocalSettings(t *testing.T) {
+	c := Default()
+	c.SyncPassphrase = "secret"
+	c.ServerToken = "api-token"
+	c.DashboardToken = "dashboard-token"
+	c.ServerEnabled = true
+	c.ServerBind = "0.0.0.0"
+
+	data, err := c.SyncSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var synced Config
+	if err := json.Unmarshal(data, &synced); err != nil {
+		t.Fatal(err)
+	}
+	if synced.SyncPassphrase != "" || synced.ServerToken != "" || synced.DashboardToken != "" {
+		t.Errorf("snapshot carries secrets: %+v", synced)
+	}
+	if synced.ServerEnabled || synced.ServerBind != "" {
+		t.Errorf("snapshot carries server settings: enabled=%v bind=%q", synced.ServerEnabled, synced.ServerBind)
+	}
+}
+
+func TestApplySyncedKeepsLocalSettings(t *testing.T) {
+	useScratchConfig(t, secrets.NewMemory())
+	remote := Default()
+	remote.OverlayOpacity = 0.5
+	remote.ServerEnabled = true
+	remote.ServerBind = "0.0.0.0"
+	remote.DashboardEnabled = true
+	remote.DebugLogging = true
+	remote.AutoStart = true
+	remote.HotkeyExcludedApps = []string{"code"}
+	data, err := json.Marshal(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := Default()
+	c.OverlayX, c.OverlayY = 10, 20
+	c.SyncFolder = "/local/sync"
+	if err := c.ApplySynced(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.OverlayOpacity != 0.5 {
+		t.Errorf("OverlayOpacity = %v, want the synced 0.5", c.OverlayOpacity)
+	}
+	if c.ServerEnabled || c.ServerBind != "" || c.DashboardEnabled || c.DebugLogging || c.AutoStart {
+		t.Errorf("local settings were overwritten: %+v", c)
+	}
+	if len(c.HotkeyExcludedApps) != 0 {
+		t.Errorf("HotkeyExcludedApps = %v, want none", c.HotkeyExcludedApps)
+	}
+	if c.OverlayX != 10 || c.OverlayY != 20 || c.SyncFolder != "/local/sync" {
+		t.Errorf("window position or sync folder changed: x=%d y=%d folder=%q", c.OverlayX, c.OverlayY, c.SyncFolder)
+	}
+}
+
+func TestApplySyncedReplacesLabels(t *testing.T) {
+	useScratchConfig(t, secrets.NewMemory())
+	remote := Default()
+	remote.Labels = map[string]string{"weekly": "Week"}
+	data, err := json.Marshal(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := Default()
+	c.Labels = map[string]string{"session": "5h", "weekly": "7d"}
+	c.DoublePress = map[string]string{"toggle_overlay": "refresh"}
+	if err := c.ApplySynced(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Labels) != 1 || c.Labels["weekly"] != "Week" {
+		t.Errorf("Labels = %v, want only the synced weekly label", c.Labels)
+	}
+	if c.DoublePress != nil {
+		t.Errorf("DoublePress = %v, want none like the synced config", c.DoublePress)
+	}
+}
+
+func TestApplySyncedInvalid(t *testing.T) {
+	useScratchConfig(t, secrets.NewMemory())
+	c := Default()
+	c.OverlayOpacity = 0.7
+	if err := c.ApplySynced([]byte("{not json")); err == nil {
+		t.Fatal("ApplySynced accepted invalid JSON")
+	}
+	if c.OverlayOpacity != 0.7 {
+		t.Errorf("OverlayOpacity = %v after a failed apply, want 0.7", c.OverlayOpacity)
+	}
+}
+
+func TestOpacityFor(t *testing.T) {
+	c := Default()
+	c.OverlayOpacity = 0.8
+	c.PositionOpacity = map[string]float64{"top": 0.6, "floating": 0.95, "left": 0, "right": 1.5}
+
+	tests := []struct {
+		pos  string
+		want float64
+	}{
+		{"top", 0.6},
+		{"floating", 0.95},
+		{"bottom-left", 0.8},
+		{"left", 0.8},  // out of range falls back
+		{"right", 0.8}, // out of range falls back
+	}
+	for _, tt := range tests {
+		if got := c.OpacityFor(tt.pos); got != tt.want {
+			t.Errorf("OpacityFor(%q) = %v, want %v", tt.pos, got, tt.want)
+		}
+	}
+}
+
+func TestFormatPercent(t *testing.T) {
+	c := Default()
+	tests := []struct {
+		precision int
+		want      string
+	}{
+		{0, "42%"},
+		{1, "42.4%"},
+		{2, "42.37%"},
+		{5, "42.37%"}, // capped at MaxPrecision
+		{-1, "42%"},
+	}
+	for _, tt := range tests {
+		c.Precision = tt.precision
+		if got := c.FormatPercent(42.366); got != tt.want {
+			t.Errorf("FormatPercent with precision %d = %q, want %q", tt.precision, got, tt.want)
+		}
+	}
+}
+
+func TestHost(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   string
+	}{
+		{"", "claude.ai"},
+		{"  ", "claude.ai"},
+		{"claude.example.com", "claude.example.com"},
+		{"https://Claude.Example.com/", "claude.example.com"},
+		{"claude.example.com:8443/login", "claude.example.com"},
+	}
+	for _, tt := range tests {
+		c := Default()
+		c.Domain = tt.domain
+		if got := c.Host(); got != tt.want {
+			t.Errorf("Host() with Domain %q = %q, want %q", tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestDirOverride(t *testing.T) {
+	t.Cleanup(func() { SetDir("") })
+	env, flag := t.TempDir(), t.TempDir()
+
+	t.Setenv(EnvDir, env)
+	SetDir("")
+	if dir, err := Dir(); err != nil || dir != env {
+		t.Errorf("Dir() with $%s = %q, %v; want %q", EnvDir, dir, err, env)
+	}
+
+	SetDir(flag)
+	if dir, err := Dir(); err != nil || dir != flag {
+		t.Errorf("Dir() after SetDir = %q, %v; want %q", dir, err, flag)
+	}
+}
+
+// failingStore is a credential store that fails every call with err
+type failingStore struct{ err error }
+
+func (f failingStore) Get(string) (string, error) { return "", f.err }
+func (f failingStore) Set(string, string) error   { return f.err }
+func (f failingStore) Delete(string) error        { return f.err }
+
+// useScratchConfig points the config and home directories at new ones and
+// the keychain at store for one test, like configtest.Use, and returns the
+// config file's path
+func useScratchConfig(t *testing.T, store secrets.Store) string {
+	t.Helper()
+	home := t.TempDir()
+	for _, env := range []string{"HOME", "USERPROFILE", "APPDATA", "XDG_CONFIG_HOME"} {
+		t.Setenv(env, home)
+	}
+	t.Setenv(EnvDir, "")
+	saved := secretStore
+	secretStore = store
+	SetDir(t.TempDir())
+	t.Cleanup(func() {
+		secretStore = saved
+		SetDir("")
+	})
+	path, err := getConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSessionKeyMovesToKeychain(t *testing.T) {
+	store := secrets.NewMemory()
+	path := useScratchConfig(t, store)
+	if err := os.WriteFile(path, []byte(`{"session_key": "sk-ant-sid01-plain"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := Default()
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if c.SessionKey != "sk-ant-sid01-plain" || c.SessionKeyStorage != StorageKeychain {
+		t.Errorf("after migration key = %q, storage = %q", c.SessionKey, c.SessionKeyStorage)
+	}
+	if key, err := store.Get(secretName(sessionKeyName)); err != nil || key != "sk-ant-sid01-plain" {
+		t.Errorf("keychain holds %q, %v", key, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-ant-sid01-plain") {
+		t.Errorf("config file still holds the key:\n%s", data)
+	}
+
+	// A fresh load reads it back, and a new key replaces it
+	c = Default()
+	if err := c.Load(); err != nil || c.SessionKey != "sk-ant-sid01-plain" {
+		t.Fatalf("reload: key = %q, %v", c.SessionKey, err)
+	}
+	if err := c.SetSessionKey("sk-ant-sid01-new"); err != nil {
+		t.Fatal(err)
+	}
+	if key, _ := store.Get(secretName(sessionKeyName)); key != "sk-ant-sid01-new" {
+		t.Errorf("keychain holds %q after SetSessionKey", key)
+	}
+	if err := c.SetSessionKey(""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(secretName(sessionKeyName)); !errors.Is(err, secrets.ErrNotFound) {
+		t.Errorf("cleared key still in the keychain: %v", err)
+	}
+}
+
+func TestSessionKeyFallsBackToFile(t *testing.T) {
+	tests := []struct {
+		err         error
+		wantStorage string
+	}{
+		{secrets.ErrUnavailable, ""},
+		{secrets.ErrDenied, StorageFile},
+	}
+	for _, tt := range tests {
+		path := useScratchConfig(t, failingStore{tt.err})
+
+		c := Default()
+		if err := c.SetSessionKey("sk-ant-sid01-plain"); err != nil {
+			t.Fatal(err)
+		}
+		if c.SessionKeyStorage != tt.wantStorage {
+			t.Errorf("%v: storage = %q, want %q", tt.err, c.SessionKeyStorage, tt.wantStorage)
+		}
+		var saved Config
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &saved); err != nil || saved.SessionKey != "sk-ant-sid01-plain" {
+			t.Errorf("%v: file holds key %q, %v", tt.err, saved.SessionKey, err)
+		}
+	}
+}
+
+func TestSwitchAccount(t *testing.T) {
+	store := secrets.NewMemory()
+	path := useScratchConfig(t, store)
+
+	c := Default()
+	c.OrganizationID = "org-personal"
+	if err := c.SetSessionKey("sk-ant-sid01-personal"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddAccount("Work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddAccount(" Work "); !errors.Is(err, ErrAccountExists) {
+		t.Errorf("adding Work twice: %v", err)
+	}
+	if names := c.AccountNames(); strings.Join(names, ",") != DefaultAccountName+",Work" {
+		t.Errorf("AccountNames() = %q", names)
+	}
+
+	if err := c.SwitchAccount("Work"); err != nil {
+		t.Fatal(err)
+	}
+	if c.SessionKey != "" || c.OrganizationID != "" || c.ActiveAccount != "Work" {
+		t.Errorf("new account has key %q, org %q, active %q", c.SessionKey, c.OrganizationID, c.ActiveAccount)
+	}
+	if err := c.SetSessionKey("sk-ant-sid01-work"); err != nil {
+		t.Fatal(err)
+	}
+	if key, _ := store.Get(secretName(sessionKeyName)); key != "sk-ant-sid01-work" {
+		t.Errorf("keychain holds %q as the active key", key)
+	}
+	if key, _ := store.Get(secretName(sessionKeyName + "/" + DefaultAccountName)); key != "sk-ant-sid01-personal" {
+		t.Errorf("keychain holds %q for the other account", key)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-ant-sid01") {
+		t.Errorf("config file holds a session key:\n%s", data)
+	}
+
+	// Both keys come back from the keychain, and switching back restores
+	// the first account
+	c = Default()
+	if err := c.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SwitchAccount(DefaultAccountName); err != nil {
+		t.Fatal(err)
+	}
+	if c.SessionKey != "sk-ant-sid01-personal" || c.OrganizationID != "org-personal" {
+		t.Errorf("switched back to key %q, org %q", c.SessionKey, c.OrganizationID)
+	}
+	if key, _ := store.Get(secretName(sessionKeyName + "/Work")); key != "sk-ant-sid01-work" {
+		t.Errorf("keychain holds %q for Work after switching back", key)
+	}
+
+	if err := c.RenameAccount("Work", "Acme"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(secretName(sessionKeyName + "/Work")); !errors.Is(err, secrets.ErrNotFound) {
+		t.Errorf("key under the old name still in the keychain: %v", err)
+	}
+	if err := c.RemoveAccount(DefaultAccountName); !errors.Is(err, ErrAccountActive) {
+		t.Errorf("removing the active account: %v", err)
+	}
+	if err := c.RemoveAccount("Acme"); err != nil {
+		t.Fatal(err)
+	}
+	if c.Accounts != nil || c.ActiveAccount != "" || c.SessionKey != "sk-ant-sid01-personal" {
+		t.Errorf("after removing the other account: %+v, active %q, key %q", c.Accounts, c.ActiveAccount, c.SessionKey)
+	}
+	if _, err := store.Get(secretName(sessionKeyName + "/Acme")); !errors.Is(err, secrets.ErrNotFound) {
+		t.Errorf("removed account's key still in the keychain: %v", err)
+	}
+}
+
+func TestSessionKeyPerConfigDir(t *testing.T) {
+	store := secrets.NewMemory()
+	useScratchConfig(t, store)
+	first, second := t.TempDir(), t.TempDir()
+
+	for dir, key := range map[string]string{first: "sk-ant-sid01-first", second: "sk-ant-sid01-second"} {
+		SetDir(dir)
+		c := Default()
+		if err := c.SetSessionKey(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for dir, want := range map[string]string{first: "sk-ant-sid01-first", second: "sk-ant-sid01-second"} {
+		SetDir(dir)
+		c := Default()
+		if err := c.Load(); err != nil {
+			t.Fatal(err)
+		}
+		if c.SessionKey != want || c.SessionKeyStorage != StorageKeychain {
+			t.Errorf("%s: key = %q, storage = %q, want %q in the keychain", dir, c.SessionKey, c.SessionKeyStorage, want)
+		}
+	}
+
+	// The platform directory keeps the plain entry name
+	SetDir("")
+	if name := secretName(sessionKeyName); name != sessionKeyName {
+		t.Errorf("default directory names the key %q", name)
+	}
+}
@@ -0,0 +1,31 @@
+// Package configtest gives tests a config of their own: a scratch config
+// directory, home directory and credential store, so they never touch the
+// user's config, Claude Code credentials, browser profiles or keychain,
+// whatever $CLAUDEBAR_CONFIG_DIR is set to.
+package configtest
+
+import (
+	"testing"
+
+	"claudebar/internal/config"
+	"claudebar/internal/secrets"
+)
+
+// Use points the config directory and the home directory at new temporary
+// directories and keeps session keys in memory until the test ends. Returns
+// the config directory.
+func Use(t testing.TB) string {
+	t.Helper()
+	home := t.TempDir()
+	for _, env := range []string{"HOME", "USERPROFILE", "APPDATA", "XDG_CONFIG_HOME"} {
+		t.Setenv(env, home)
+	}
+	dir := t.TempDir()
+	config.SetDir(dir)
+	saved := config.SetSecretStore(secrets.NewMemory())
+	t.Cleanup(func() {
+		config.SetDir("")
+		config.SetSecretStore(saved)
+	})
+	return dir
+}
@@ -0,0 +1,109 @@
+package demo
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+// Every poll advances the simulated clock by simStep, so a full session
+// window passes in about 20 polls
+const (
+	simStep       = 15 * time.Minute
+	sessionWindow = 5 * time.Hour
+	weeklyWindow  = 7 * 24 * time.Hour
+)
+
+// Source generates synthetic usage data: sessions that fill up and reset, a
+// weekly total that climbs across sessions, and the occasional error, so the
+// UI can be exercised without a Claude account.
+type Source struct {
+	mu   sync.Mutex
+	tick int
+}
+
+// NewSource creates a demo data source
+func NewSource() *Source {
+	return &Source{}
+}
+
+// FetchUsage returns the next simulated snapshot. Matches api.Client.FetchUsage.
+func (s *Source) FetchUsage() (*api.UsageData, error) {
+	s.mu.Lock()
+	tick := s.tick
+	s.tick++
+	s.mu.Unlock()
+
+	// Cycle through the error states the overlay knows how to show
+	switch {
+	case tick%41 == 40:
+		return nil, api.ErrAPIUnavailable
+	case tick%29 == 28:
+		return nil, api.ErrRateLimited
+	case tick%17 == 16:
+		return nil, fmt.Errorf("%w: demo connection timeout", api.ErrTimeout)
+	}
+
+	elapsed := time.Duration(tick) * simStep
+	now := time.Now()
+
+	sessionPos := elapsed % sessionWindow
+	weeklyPos := elapsed % weeklyWindow
+
+	// Sessions vary in intensity so some hit the alert thresholds and some don't
+	session := int(elapsed / sessionWindow)
+	intensity := 0.55 + 0.45*math.Abs(math.Sin(float64(session)*1.7))
+	p := float64(sessionPos) / float64(sessionWindow)
+	sessionUtil := 100 * intensity * (1 - math.Cos(math.Pi*p)) / 2
+	// Small wobble so consecutive polls don't look perfectly smooth
+	sessionUtil = clamp(sessionUtil + 2*math.Sin(float64(tick)*2.3))
+
+	w := float64(weeklyPos) / float64(weeklyWindow)
+	weeklyUtil := clamp(100*w*0.9 + 5*math.Sin(float64(tick)/7))
+
+	return &api.UsageData{
+		FiveHour: api.UsageStat{
+			Utilization: sessionUtil,
+			ResetsAt:    now.Add(sessionWindow - sessionPos),
+			Label:       "5-Hour",
+		},
+		SevenDay: api.UsageStat{
+			Utilization: weeklyUtil,
+			ResetsAt:    now.Add(weeklyWindow - weeklyPos),
+			Label:       "Weekly",
+		},
+		SevenDayOpus: api.UsageStat{
+			Utilization: clamp(weeklyUtil * 0.4),
+			ResetsAt:    now.Add(weeklyWindow - weeklyPos),
+			Label:       "Opus",
+		},
+		SevenDaySonnet: api.UsageStat{
+			Utilization: clamp(weeklyUtil * 0.7),
+			ResetsAt:    now.Add(weeklyWindow - weeklyPos),
+			Label:       "Sonnet",
+		},
+		LastUpdated: now,
+	}, nil
+}
+
+// Snapshot returns a fixed mid-session reading relative to now, used for
+// reproducible screenshots. Reset times carry an extra 30s so the rendered
+// countdowns don't tick over while the images are written.
+func Snapshot(now time.Time) *api.UsageData {
+	sessionReset := now.Add(2*time.Hour + 14*time.Minute + 30*time.Second)
+	weeklyReset := now.Add(3*24*time.Hour + 5*time.Hour + 30*time.Second)
+	return &api.UsageData{
+		FiveHour:       api.UsageStat{Utilization: 42, ResetsAt: sessionReset, Label: "5-Hour"},
+		SevenDay:       api.UsageStat{Utilization: 67, ResetsAt: weeklyReset, Label: "Weekly"},
+		SevenDayOpus:   api.UsageStat{Utilization: 27, ResetsAt: weeklyReset, Label: "Opus"},
+		SevenDaySonnet: api.UsageStat{Utilization: 47, ResetsAt: weeklyReset, Label: "Sonnet"},
+		LastUpdated:    now,
+	}
+}
+
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(100, v))
+}
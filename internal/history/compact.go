@@ -0,0 +1,83 @@
+package history
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Compact drops completed cycles that ended more than retention before now
+// from the history file, returning how many were removed, and samples older
+// than that from the sample database. Samples older than sampleRetention are
+// first folded into hourly rollups. A retention of zero keeps everything.
+func (t *Tracker) Compact(now time.Time, retention, sampleRetention time.Duration) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if sampleRetention > 0 || retention > 0 {
+		t.compactSamples(now, retention, sampleRetention)
+	}
+	if retention <= 0 {
+		return 0, nil
+	}
+
+	cutoff := now.Add(-retention)
+
+	cycles, err := Cycles(t.dir)
+	if err != nil || len(cycles) == 0 {
+		return 0, err
+	}
+	var kept []Cycle
+	for _, c := range cycles {
+		if !c.End.Before(cutoff) {
+			kept = append(kept, c)
+		}
+	}
+	removed := len(cycles) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, t.rewrite(kept)
+}
+
+// compactSamples rolls up and prunes the sample database, logging failures
+// rather than failing the compaction of the cycles. Must be called with t.mu
+// held.
+func (t *Tracker) compactSamples(now time.Time, retention, sampleRetention time.Duration) {
+	samples, err := t.openSamples()
+	if err == nil && sampleRetention > 0 {
+		var rolled int64
+		if rolled, err = samples.Rollup(now.Add(-sampleRetention)); rolled > 0 {
+			log.Printf("History: rolled up %d samples into hours", rolled)
+		}
+	}
+	if err == nil && retention > 0 {
+		var pruned int64
+		if pruned, err = samples.Prune(now.Add(-retention)); pruned > 0 {
+			log.Printf("History: removed %d samples", pruned)
+		}
+	}
+	if err != nil {
+		log.Printf("History: failed to compact samples: %v", err)
+	}
+}
+
+// rewrite replaces the history file with cycles. Must be called with t.mu
+// held.
+func (t *Tracker) rewrite(cycles []Cycle) error {
+	var data []byte
+	for _, c := range cycles {
+		line, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	tmp := filepath.Join(t.dir, cyclesFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(t.dir, cyclesFile))
+}
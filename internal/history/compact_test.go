@@ -0,0 +1,33 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompact(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker(dir)
+	for _, age := range []int{400, 366, 300, 7} {
+		end := now.AddDate(0, 0, -age)
+		if err := tracker.append(Cycle{Start: end.Add(-weeklyWindow), End: end, PeakUtilization: float64(age)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if removed, err := tracker.Compact(now, 0, 0); removed != 0 || err != nil {
+		t.Errorf("Compact without retention = %d, %v, want 0, nil", removed, err)
+	}
+	removed, err := tracker.Compact(now, 365*24*time.Hour, 0)
+	if removed != 2 || err != nil {
+		t.Errorf("Compact = %d, %v, want 2, nil", removed, err)
+	}
+	cycles, err := Cycles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cycles) != 2 || cycles[0].PeakUtilization != 300 || cycles[1].PeakUtilization != 7 {
+		t.Errorf("Cycles after Compact = %+v, want the 300 and 7 day old ones", cycles)
+	}
+}
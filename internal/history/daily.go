@@ -0,0 +1,100 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// dailyFile records the last day an end-of-day summary was due for
+const dailyFile = "history-daily.json"
+
+// ParseClock parses a time of day like "18:00"
+func ParseClock(s string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time of day %q, want e.g. 18:00", s)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// DaySummary is the weekly usage of one day
+type DaySummary struct {
+	Used     float64   // weekly percentage points used that day
+	Weekly   float64   // weekly utilization at the last sample
+	ResetsAt time.Time // weekly reset after the last sample; zero if unknown
+}
+
+// SummarizeDay sums up the samples of a day, oldest first. Weekly
+// utilization only drops at a reset, so after a drop the new value counts
+// as used. ok is false without samples.
+func SummarizeDay(samples []Sample) (d DaySummary, ok bool) {
+	if len(samples) == 0 {
+		return d, false
+	}
+	for i := 1; i < len(samples); i++ {
+		if delta := samples[i].Weekly - samples[i-1].Weekly; delta >= 0 {
+			d.Used += delta
+		} else {
+			d.Used += samples[i].Weekly
+		}
+	}
+	last := samples[len(samples)-1]
+	d.Weekly, d.ResetsAt = last.Weekly, last.WeeklyResetsAt
+	return d, true
+}
+
+// Text describes the day as of now, e.g. "Used 12% of the weekly limit
+// today. 46% left until the reset Thu 09:00, about 15% a day."
+func (d DaySummary) Text(now time.Time) string {
+	used := fmt.Sprintf("Used %.0f%% of the weekly limit today.", d.Used)
+	left := max(100-d.Weekly, 0)
+	if !d.ResetsAt.After(now) {
+		return fmt.Sprintf("%s %.0f%% left this week.", used, left)
+	}
+	headroom := fmt.Sprintf("%.0f%% left until the reset %s", left, d.ResetsAt.In(now.Location()).Format("Mon 15:04"))
+	if days := math.Ceil(d.ResetsAt.Sub(now).Hours() / 24); days > 1 {
+		headroom += fmt.Sprintf(", about %.0f%% a day", left/days)
+	}
+	return used + " " + headroom + "."
+}
+
+// DailySummaryDue reports whether the end-of-day summary is still to be sent
+// at now, for a summary time of hour:minute, and returns the start of the
+// day to sum up. Each day is due once, from the summary time until midnight.
+func (t *Tracker) DailySummaryDue(now time.Time, hour, minute int) (day time.Time, due bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	day = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if now.Before(time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())) {
+		return time.Time{}, false
+	}
+
+	path := filepath.Join(t.dir, dailyFile)
+	var last time.Time
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &last); err != nil {
+			return time.Time{}, false
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return time.Time{}, false
+	}
+	if !day.After(last) {
+		return time.Time{}, false
+	}
+	if data, err := json.Marshal(day); err == nil {
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			log.Printf("History: failed to save summary day: %v", err)
+		}
+	}
+	return day, true
+}
@@ -0,0 +1,66 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeDay(t *testing.T) {
+	at := func(hour int, weekly float64) Sample {
+		return Sample{Time: time.Date(2026, 10, 16, hour, 0, 0, 0, time.UTC), Weekly: weekly}
+	}
+	// The weekly window resets at 12:00, between 40% and 3%
+	samples := []Sample{at(8, 30), at(10, 38), at(11, 40), at(13, 3), at(17, 10)}
+	reset := time.Date(2026, 10, 23, 12, 0, 0, 0, time.UTC)
+	samples[len(samples)-1].WeeklyResetsAt = reset
+
+	d, ok := SummarizeDay(samples)
+	if want := (DaySummary{Used: 20, Weekly: 10, ResetsAt: reset}); !ok || d != want {
+		t.Errorf("SummarizeDay = %+v, %v, want %+v", d, ok, want)
+	}
+	if _, ok := SummarizeDay(nil); ok {
+		t.Error("SummarizeDay summed up a day without samples")
+	}
+
+	now := time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC)
+	want := "Used 20% of the weekly limit today. 90% left until the reset Fri 12:00, about 13% a day."
+	if got := d.Text(now); got != want {
+		t.Errorf("Text = %q, want %q", got, want)
+	}
+	d.ResetsAt = now.Add(15 * time.Hour)
+	want = "Used 20% of the weekly limit today. 90% left until the reset Sat 09:00."
+	if got := d.Text(now); got != want {
+		t.Errorf("Text the day before the reset = %q, want %q", got, want)
+	}
+}
+
+func TestDailySummaryDue(t *testing.T) {
+	dir := t.TempDir()
+	morning := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	evening := morning.Add(10 * time.Hour)
+
+	if _, due := NewTracker(dir).DailySummaryDue(morning, 18, 0); due {
+		t.Error("due before the summary time")
+	}
+	day, due := NewTracker(dir).DailySummaryDue(evening, 18, 0)
+	if want := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC); !due || !day.Equal(want) {
+		t.Errorf("DailySummaryDue in the evening = %v, %v, want %v, true", day, due, want)
+	}
+	if _, due := NewTracker(dir).DailySummaryDue(evening.Add(time.Hour), 18, 0); due {
+		t.Error("due twice on the same day")
+	}
+	if _, due := NewTracker(dir).DailySummaryDue(evening.AddDate(0, 0, 1), 18, 0); !due {
+		t.Error("not due the next evening")
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	if h, m, err := ParseClock(" 18:30 "); err != nil || h != 18 || m != 30 {
+		t.Errorf("ParseClock(18:30) = %d, %d, %v", h, m, err)
+	}
+	for _, s := range []string{"", "6pm", "24:00", "18"} {
+		if _, _, err := ParseClock(s); err == nil {
+			t.Errorf("ParseClock(%q) accepted", s)
+		}
+	}
+}
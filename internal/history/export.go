@@ -0,0 +1,104 @@
+package history
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// influxMeasurement names the weekly cycles in line protocol
+const influxMeasurement = "claudebar_weekly"
+
+// WriteInflux writes the cycles to w in InfluxDB line protocol, one point per
+// week stamped with the reset that ended it, with tokens and cost for weeks
+// imported from token-based trackers, e.g.
+//
+//	claudebar_weekly peak_utilization=92,alerts=3i,rate_limits=1i 1792411200000000000
+func WriteInflux(w io.Writer, cycles []Cycle) error {
+	bw := bufio.NewWriter(w)
+	for _, c := range cycles {
+		fields := fmt.Sprintf("alerts=%di,rate_limits=%di", c.Alerts, c.RateLimits)
+		if c.Measured() {
+			fields = fmt.Sprintf("peak_utilization=%g,", c.PeakUtilization) + fields
+		}
+		if c.Tokens != 0 || c.CostUSD != 0 {
+			fields += fmt.Sprintf(",tokens=%di,cost_usd=%g", c.Tokens, c.CostUSD)
+		}
+		fmt.Fprintf(bw, "%s %s %d\n", influxMeasurement, fields, c.End.UnixNano())
+	}
+	return bw.Flush()
+}
+
+// sqliteSchema rebuilds the export tables and the views for Grafana's SQLite
+// datasource, which wants a time column in Unix seconds
+const sqliteSchema = `
+DROP VIEW IF EXISTS grafana_weekly;
+DROP VIEW IF EXISTS grafana_heatmap;
+DROP TABLE IF EXISTS cycles;
+DROP TABLE IF EXISTS heatmap;
+CREATE TABLE cycles (
+	start INTEGER NOT NULL,
+	end INTEGER NOT NULL,
+	peak_utilization REAL NOT NULL,
+	alerts INTEGER NOT NULL,
+	rate_limits INTEGER NOT NULL,
+	source TEXT NOT NULL,
+	tokens INTEGER NOT NULL,
+	cost_usd REAL NOT NULL
+);
+CREATE TABLE heatmap (
+	weekday INTEGER NOT NULL,
+	hour INTEGER NOT NULL,
+	points REAL NOT NULL
+);
+CREATE VIEW grafana_weekly AS
+	SELECT end AS time, CASE source WHEN 'ccusage' THEN NULL ELSE peak_utilization END AS peak_utilization,
+		alerts, rate_limits, tokens, cost_usd FROM cycles ORDER BY end;
+CREATE VIEW grafana_heatmap AS
+	SELECT CASE weekday WHEN 0 THEN 'Sun' WHEN 1 THEN 'Mon' WHEN 2 THEN 'Tue'
+		WHEN 3 THEN 'Wed' WHEN 4 THEN 'Thu' WHEN 5 THEN 'Fri' ELSE 'Sat' END AS day,
+		hour, points FROM heatmap ORDER BY weekday, hour;
+`
+
+// WriteSQLite writes the cycles and heatmap to the SQLite database at path,
+// replacing an earlier export, with the views grafana_weekly and
+// grafana_heatmap ready for Grafana's SQLite datasource. Re-running it, e.g.
+// from cron, keeps the database current.
+func WriteSQLite(path string, cycles []Cycle, heatmap Heatmap) error {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(sqliteSchema); err != nil {
+		return fmt.Errorf("creating tables: %w", err)
+	}
+	for _, c := range cycles {
+		if _, err := tx.Exec("INSERT INTO cycles VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			c.Start.Unix(), c.End.Unix(), c.PeakUtilization, c.Alerts, c.RateLimits,
+			c.Source, c.Tokens, c.CostUSD); err != nil {
+			return err
+		}
+	}
+	for day, row := range heatmap {
+		for hour, points := range row {
+			if points == 0 {
+				continue
+			}
+			if _, err := tx.Exec("INSERT INTO heatmap VALUES (?, ?, ?)", day, hour, points); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
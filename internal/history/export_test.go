@@ -0,0 +1,24 @@
+package history
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteInflux(t *testing.T) {
+	end := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	cycles := []Cycle{
+		{Start: end.Add(-weeklyWindow), End: end, PeakUtilization: 92.5, Alerts: 3, RateLimits: 1},
+		{Start: end, End: end.Add(weeklyWindow), PeakUtilization: 40},
+	}
+	var b strings.Builder
+	if err := WriteInflux(&b, cycles); err != nil {
+		t.Fatal(err)
+	}
+	want := "claudebar_weekly peak_utilization=92.5,alerts=3i,rate_limits=1i 1792411200000000000\n" +
+		"claudebar_weekly peak_utilization=40,alerts=0i,rate_limits=0i 1793016000000000000\n"
+	if b.String() != want {
+		t.Errorf("WriteInflux wrote\n%s\nwant\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,88 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// heatmapFile holds the Heatmap; it starts with "history" like the cycle
+// files so backups treat it as history
+const heatmapFile = "history-heatmap.json"
+
+// heatmapMaxGap is the longest time between two readings whose difference
+// is still credited to the hour of the second. Usage across a longer gap,
+// e.g. while the app was closed, can't be placed and is left out.
+const heatmapMaxGap = 15 * time.Minute
+
+// Heatmap is the weekly quota used per weekday and local hour of day, in
+// percentage points summed over all recorded weeks. Rows are indexed by
+// time.Weekday.
+type Heatmap [7][24]float64
+
+// add credits points of quota used to the weekday and hour of t
+func (h *Heatmap) add(t time.Time, points float64) {
+	t = t.Local()
+	h[t.Weekday()][t.Hour()] += points
+}
+
+// Max returns the largest cell, zero when nothing was recorded
+func (h *Heatmap) Max() float64 {
+	var highest float64
+	for _, row := range h {
+		for _, v := range row {
+			highest = max(highest, v)
+		}
+	}
+	return highest
+}
+
+// Busiest returns the weekday and hour with the most usage. ok is false when
+// nothing was recorded.
+func (h *Heatmap) Busiest() (day time.Weekday, hour int, ok bool) {
+	var highest float64
+	for d, row := range h {
+		for hr, v := range row {
+			if v > highest {
+				highest, day, hour, ok = v, time.Weekday(d), hr, true
+			}
+		}
+	}
+	return day, hour, ok
+}
+
+// LoadHeatmap reads the heatmap stored in dir; an empty one if none was saved
+func LoadHeatmap(dir string) (Heatmap, error) {
+	var h Heatmap
+	data, err := os.ReadFile(filepath.Join(dir, heatmapFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+	err = json.Unmarshal(data, &h)
+	return h, err
+}
+
+// saveHeatmap writes the heatmap. Must be called with t.mu held.
+func (t *Tracker) saveHeatmap() error {
+	data, err := json.Marshal(t.heatmap)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(t.dir, heatmapFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(t.dir, heatmapFile))
+}
+
+// Heatmap returns the usage heatmap recorded so far
+func (t *Tracker) Heatmap() Heatmap {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.heatmap
+}
@@ -0,0 +1,48 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+func TestTrackerHeatmap(t *testing.T) {
+	dir := t.TempDir()
+	reset := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	tuesday := time.Date(2026, 10, 13, 9, 55, 0, 0, time.Local)
+
+	tracker := NewTracker(dir)
+	clock := tuesday
+	tracker.now = func() time.Time { return clock }
+	observe := func(after time.Duration, pct float64) {
+		clock = clock.Add(after)
+		tracker.Observe(api.UsageStat{Utilization: pct, ResetsAt: reset})
+	}
+	observe(0, 10)             // first reading: nothing to compare with
+	observe(time.Minute, 13)   // 09:56, 3 points
+	observe(5*time.Minute, 17) // 10:01, 4 points
+	observe(time.Minute, 15)   // usage going down adds nothing
+	observe(time.Minute, 18)   // 10:03, 1 point above the peak
+	observe(2*time.Hour, 30)   // too long since the last reading
+	observe(time.Minute, 30)   // no change
+
+	h := NewTracker(dir).Heatmap()
+	if got := h[time.Tuesday][9]; got != 3 {
+		t.Errorf("Tuesday 09:00 = %g, want 3", got)
+	}
+	if got := h[time.Tuesday][10]; got != 5 {
+		t.Errorf("Tuesday 10:00 = %g, want 5", got)
+	}
+	if got := h.Max(); got != 5 {
+		t.Errorf("Max = %g, want 5", got)
+	}
+	day, hour, ok := h.Busiest()
+	if !ok || day != time.Tuesday || hour != 10 {
+		t.Errorf("Busiest = %s %d %v, want Tuesday 10 true", day, hour, ok)
+	}
+
+	if _, _, ok := (&Heatmap{}).Busiest(); ok {
+		t.Error("empty heatmap has a busiest hour")
+	}
+}
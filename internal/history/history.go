@@ -0,0 +1,280 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+// Files in the config directory. Both start with "history" so backups leave
+// them out unless history is requested.
+const (
+	cyclesFile  = "history.jsonl"      // one completed Cycle per line
+	currentFile = "history-cycle.json" // the cycle in progress
+)
+
+// weeklyWindow is the length of the weekly usage window
+const weeklyWindow = 7 * 24 * time.Hour
+
+// resetJitter is how far a reported reset time may move without counting as
+// a new window; the API's timestamps wobble by seconds between polls
+const resetJitter = time.Hour
+
+// Cycle summarizes one weekly usage window
+type Cycle struct {
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"` // the weekly reset that closed it
+	PeakUtilization float64   `json:"peak_utilization"`
+	Alerts          int       `json:"alerts"`      // threshold notifications sent
+	RateLimits      int       `json:"rate_limits"` // fetches refused as rate limited
+
+	// Imported weeks: the tracker they came from, and for token-based
+	// trackers like ccusage the tokens and cost instead of a peak
+	Source  string  `json:"source,omitempty"`
+	Tokens  int64   `json:"tokens,omitempty"`
+	CostUSD float64 `json:"cost_usd,omitempty"`
+}
+
+// Measured reports whether the cycle has a peak utilization, which weeks
+// imported from token-based trackers lack
+func (c Cycle) Measured() bool {
+	return c.Source != SourceCCUsage
+}
+
+// String summarizes the cycle for a notification, e.g.
+// "Peak 92%, 3 alerts, 1 rate limit"
+func (c Cycle) String() string {
+	return fmt.Sprintf("Peak %.0f%%, %s, %s", c.PeakUtilization,
+		plural(c.Alerts, "alert"), plural(c.RateLimits, "rate limit"))
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// Tracker accumulates the current weekly cycle and writes it to the history
+// when the weekly window resets. The cycle in progress is saved as it
+// changes so restarts don't lose it. Methods are safe for concurrent use.
+type Tracker struct {
+	mu       sync.Mutex
+	dir      string
+	current  Cycle
+	heatmap  Heatmap
+	lastSeen time.Time        // time of the previous reading, zero after a restart
+	now      func() time.Time // the clock, replaced in tests
+	samples  *Samples         // opened on first use, nil until then
+	resets   map[string]*ResetLog
+}
+
+// NewTracker creates a tracker keeping its files in dir, resuming the cycle
+// in progress if one was saved
+func NewTracker(dir string) *Tracker {
+	t := &Tracker{dir: dir, now: time.Now}
+	t.load()
+	return t
+}
+
+// load reads the cycle in progress, the heatmap and the reset times from
+// t.dir. Must be called with t.mu held, or before t is shared.
+func (t *Tracker) load() {
+	t.current = Cycle{}
+	data, err := os.ReadFile(filepath.Join(t.dir, currentFile))
+	if err == nil {
+		if err := json.Unmarshal(data, &t.current); err != nil {
+			log.Printf("History: ignoring unreadable cycle state: %v", err)
+			t.current = Cycle{}
+		}
+	}
+	if t.heatmap, err = LoadHeatmap(t.dir); err != nil {
+		log.Printf("History: ignoring unreadable heatmap: %v", err)
+		t.heatmap = Heatmap{}
+	}
+	if t.resets, err = loadResets(t.dir); err != nil {
+		log.Printf("History: ignoring unreadable reset times: %v", err)
+		t.resets = map[string]*ResetLog{}
+	}
+}
+
+// Restore runs write, which replaces the history files, with the sample
+// database closed and nothing else writing them, then reads them back, so
+// the state held from before doesn't overwrite what was restored
+func (t *Tracker) Restore(write func() error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.samples != nil {
+		t.samples.Close()
+		t.samples = nil
+	}
+	err := write()
+	t.load()
+	t.lastSeen = time.Time{}
+	return err
+}
+
+// Observe records a weekly usage reading. When it shows the weekly window
+// has reset, the finished cycle is appended to the history and returned.
+// Usage since the previous reading goes into the heatmap.
+func (t *Tracker) Observe(weekly api.UsageStat) *Cycle {
+	if weekly.ResetsAt.IsZero() {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var finished *Cycle
+	switch {
+	case t.current.End.IsZero():
+		t.current = Cycle{Start: weekly.ResetsAt.Add(-t.window("weekly")), End: weekly.ResetsAt}
+	case weekly.ResetsAt.Sub(t.current.End) > resetJitter:
+		done := t.current
+		finished = &done
+		if err := t.append(done); err != nil {
+			log.Printf("History: failed to save cycle: %v", err)
+		}
+		t.current = Cycle{Start: done.End, End: weekly.ResetsAt}
+	}
+
+	now := t.now()
+	if used := weekly.Utilization - t.current.PeakUtilization; used > 0 && !t.lastSeen.IsZero() && now.Sub(t.lastSeen) <= heatmapMaxGap {
+		t.heatmap.add(now, used)
+		if err := t.saveHeatmap(); err != nil {
+			log.Printf("History: failed to save heatmap: %v", err)
+		}
+	}
+	t.lastSeen = now
+
+	changed := finished != nil
+	if weekly.Utilization > t.current.PeakUtilization {
+		t.current.PeakUtilization = weekly.Utilization
+		changed = true
+	}
+	if changed {
+		t.save()
+	}
+	return finished
+}
+
+// CountAlert records a threshold notification in the current cycle
+func (t *Tracker) CountAlert() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.current.Alerts++
+	t.save()
+}
+
+// CountRateLimit records a rate-limited fetch in the current cycle
+func (t *Tracker) CountRateLimit() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.current.RateLimits++
+	t.save()
+}
+
+// SwitchAccount starts over after the active account changed, so the next
+// reading, which belongs to another account's window, neither closes the
+// cycle in progress nor counts as a window change: the cycle is dropped
+// unrecorded and the reset logs forget their times. Calibrated windows are
+// kept.
+func (t *Tracker) SwitchAccount() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.current = Cycle{}
+	t.lastSeen = time.Time{}
+	t.save()
+	for _, l := range t.resets {
+		l.Resets, l.Longer, l.Span = nil, 0, 0
+	}
+	if err := t.saveResets(); err != nil {
+		log.Printf("History: failed to save reset times: %v", err)
+	}
+}
+
+// Clear deletes the history, the cycle in progress, the heatmap, the samples,
+// the reset times and the summary marks, and starts over as if nothing had
+// been recorded
+func (t *Tracker) Clear() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.current = Cycle{}
+	t.heatmap = Heatmap{}
+	t.lastSeen = time.Time{}
+	t.resets = map[string]*ResetLog{}
+	if t.samples != nil {
+		t.samples.Close()
+		t.samples = nil
+	}
+	for _, name := range []string{cyclesFile, currentFile, heatmapFile, summaryFile, dailyFile, SamplesFile, resetsFile} {
+		if err := os.Remove(filepath.Join(t.dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// save writes the cycle in progress. Must be called with t.mu held.
+func (t *Tracker) save() {
+	data, err := json.Marshal(t.current)
+	if err != nil {
+		return
+	}
+	tmp := filepath.Join(t.dir, currentFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		log.Printf("History: failed to save cycle state: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, filepath.Join(t.dir, currentFile)); err != nil {
+		log.Printf("History: failed to save cycle state: %v", err)
+	}
+}
+
+// append adds a finished cycle to the history file
+func (t *Tracker) append(c Cycle) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(t.dir, cyclesFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Cycles returns the completed weekly cycles stored in dir, oldest first.
+// Lines that can't be parsed are skipped.
+func Cycles(dir string) ([]Cycle, error) {
+	f, err := os.Open(filepath.Join(dir, cyclesFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cycles []Cycle
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var c Cycle
+		if err := json.Unmarshal(scanner.Bytes(), &c); err == nil {
+			cycles = append(cycles, c)
+		}
+	}
+	return cycles, scanner.Err()
+}
@@ -0,0 +1,155 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+func TestTrackerObserve(t *testing.T) {
+	reset := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	next := reset.Add(weeklyWindow)
+
+	type reading struct {
+		pct      float64
+		resets   time.Time
+		wantPeak float64 // peak of the finished cycle, -1 when none finishes
+	}
+	tests := []struct {
+		name     string
+		readings []reading
+	}{
+		{"first reading starts a cycle", []reading{
+			{10, reset, -1},
+		}},
+		{"same window", []reading{
+			{10, reset, -1},
+			{40, reset, -1},
+			{30, reset, -1},
+		}},
+		{"jittering reset time", []reading{
+			{10, reset, -1},
+			{20, reset.Add(30 * time.Second), -1},
+			{30, reset.Add(-30 * time.Second), -1},
+			{40, reset.Add(resetJitter), -1},
+		}},
+		{"reset finishes the cycle", []reading{
+			{10, reset, -1},
+			{85, reset, -1},
+			{60, reset, -1},
+			{2, next, 85},
+			{5, next, -1},
+		}},
+		{"reset time moving back", []reading{
+			{10, reset, -1},
+			{20, reset.Add(-2 * time.Hour), -1},
+		}},
+		{"missing reset time is ignored", []reading{
+			{10, reset, -1},
+			{90, time.Time{}, -1},
+			{0, next, 10},
+		}},
+		{"consecutive resets", []reading{
+			{50, reset, -1},
+			{70, next, 50},
+			{20, next.Add(weeklyWindow), 70},
+		}},
+	}
+	for _, tt := range tests {
+		tracker := NewTracker(t.TempDir())
+		for i, r := range tt.readings {
+			finished := tracker.Observe(api.UsageStat{Utilization: r.pct, ResetsAt: r.resets})
+			switch {
+			case r.wantPeak < 0 && finished != nil:
+				t.Errorf("%s: reading %d finished a cycle: %+v", tt.name, i, finished)
+			case r.wantPeak >= 0 && finished == nil:
+				t.Errorf("%s: reading %d finished no cycle", tt.name, i)
+			case finished != nil && finished.PeakUtilization != r.wantPeak:
+				t.Errorf("%s: reading %d finished a cycle peaking at %.0f%%, want %.0f%%",
+					tt.name, i, finished.PeakUtilization, r.wantPeak)
+			}
+		}
+	}
+}
+
+func TestTrackerPersists(t *testing.T) {
+	dir := t.TempDir()
+	reset := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	tracker := NewTracker(dir)
+	tracker.Observe(api.UsageStat{Utilization: 40, ResetsAt: reset})
+	tracker.CountAlert()
+	tracker.CountRateLimit()
+
+	// A restart resumes the cycle in progress
+	tracker = NewTracker(dir)
+	finished := tracker.Observe(api.UsageStat{Utilization: 1, ResetsAt: reset.Add(weeklyWindow)})
+	if finished == nil {
+		t.Fatal("reset after restart finished no cycle")
+	}
+	want := Cycle{Start: reset.Add(-weeklyWindow), End: reset, PeakUtilization: 40, Alerts: 1, RateLimits: 1}
+	if !finished.Start.Equal(want.Start) || !finished.End.Equal(want.End) ||
+		finished.PeakUtilization != want.PeakUtilization || finished.Alerts != want.Alerts || finished.RateLimits != want.RateLimits {
+		t.Errorf("finished cycle = %+v, want %+v", *finished, want)
+	}
+
+	cycles, err := Cycles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cycles) != 1 || cycles[0].PeakUtilization != 40 {
+		t.Errorf("Cycles = %+v, want the one finished cycle", cycles)
+	}
+}
+
+func TestCycleString(t *testing.T) {
+	tests := []struct {
+		cycle Cycle
+		want  string
+	}{
+		{Cycle{PeakUtilization: 92, Alerts: 3, RateLimits: 1}, "Peak 92%, 3 alerts, 1 rate limit"},
+		{Cycle{PeakUtilization: 10}, "Peak 10%, 0 alerts, 0 rate limits"},
+	}
+	for _, tt := range tests {
+		if got := tt.cycle.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestTrackerSwitchAccount(t *testing.T) {
+	start := time.Date(2026, 10, 12, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	dir := t.TempDir()
+	tracker := NewTracker(dir)
+
+	// Two accounts whose weekly windows reset two days apart, read
+	// alternately for two weeks
+	resets := map[bool]time.Time{false: start.Add(3 * day), true: start.Add(5 * day)}
+	second := false
+	for now := start; now.Before(start.Add(14 * day)); now = now.Add(12 * time.Hour) {
+		for !resets[second].After(now) {
+			resets[second] = resets[second].Add(weeklyWindow)
+		}
+		usage := &api.UsageData{SevenDay: api.UsageStat{Utilization: 40, ResetsAt: resets[second]}}
+		if drifts := tracker.ObserveResets(now, usage); len(drifts) > 0 {
+			t.Errorf("%s: switching accounts changed the window: %v", now, drifts)
+		}
+		if done := tracker.Observe(usage.SevenDay); done != nil {
+			t.Errorf("%s: switching accounts finished a cycle: %+v", now, done)
+		}
+		tracker.SwitchAccount()
+		second = !second
+	}
+	if cycles, err := Cycles(dir); err != nil || len(cycles) > 0 {
+		t.Errorf("history holds %d cycles, %v", len(cycles), err)
+	}
+
+	// Readings of one account close its own cycle again
+	reset := resets[second]
+	tracker.Observe(api.UsageStat{Utilization: 60, ResetsAt: reset})
+	if done := tracker.Observe(api.UsageStat{Utilization: 5, ResetsAt: reset.Add(weeklyWindow)}); done == nil || done.PeakUtilization != 60 {
+		t.Errorf("after the last switch the reset finished %+v", done)
+	}
+}
@@ -0,0 +1,232 @@
+package history
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sources of imported cycles
+const (
+	SourceCCUsage = "ccusage" // ccusage daily or weekly JSON report
+	SourceCSV     = "csv"     // CSV of utilization readings
+)
+
+// ErrUnknownImport is returned for files that are neither a ccusage report
+// nor a CSV with time and utilization columns
+var ErrUnknownImport = errors.New("not a ccusage JSON report or a CSV with time and utilization columns")
+
+// ccusageReport is the part of `ccusage daily --json` and `ccusage weekly
+// --json` output that is imported
+type ccusageReport struct {
+	Daily []struct {
+		Date        string  `json:"date"`
+		TotalTokens int64   `json:"totalTokens"`
+		TotalCost   float64 `json:"totalCost"`
+	} `json:"daily"`
+	Weekly []struct {
+		Week        string  `json:"week"` // the day the week starts
+		TotalTokens int64   `json:"totalTokens"`
+		TotalCost   float64 `json:"totalCost"`
+	} `json:"weekly"`
+}
+
+// csvTimeLayouts are the time formats accepted in CSV imports
+var csvTimeLayouts = []string{time.RFC3339, time.DateTime, "2006-01-02 15:04", time.DateOnly}
+
+// ParseImport reads usage exported by another tracker, a ccusage JSON report
+// or a CSV with time and utilization columns, and groups it into weekly
+// cycles aligned with the weekly reset of the cycle in progress (Monday
+// midnight if none is known yet), oldest first
+func (t *Tracker) ParseImport(r io.Reader) ([]Cycle, error) {
+	t.mu.Lock()
+	phase := t.current.End
+	t.mu.Unlock()
+	if phase.IsZero() {
+		phase = time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local) // a Monday
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var cycles []Cycle
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		cycles, err = parseCCUsage(data, phase)
+	} else {
+		cycles, err = parseCSV(data, phase)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(cycles) == 0 {
+		return nil, errors.New("no usage found in the file")
+	}
+	return cycles, nil
+}
+
+// weekOf returns the cycle for the weekly window containing at, with windows
+// ending at phase plus whole weeks
+func weekOf(at, phase time.Time) Cycle {
+	weeks := at.Sub(phase) / weeklyWindow
+	end := phase.Add(weeks * weeklyWindow)
+	if !end.After(at) {
+		end = end.Add(weeklyWindow)
+	}
+	return Cycle{Start: end.Add(-weeklyWindow), End: end}
+}
+
+// grouper collects readings into weekly cycles
+type grouper struct {
+	phase  time.Time
+	cycles map[time.Time]*Cycle
+}
+
+func (g *grouper) week(at time.Time, source string) *Cycle {
+	w := weekOf(at, g.phase)
+	c, ok := g.cycles[w.End]
+	if !ok {
+		w.Source = source
+		c = &w
+		g.cycles[w.End] = c
+	}
+	return c
+}
+
+func (g *grouper) sorted() []Cycle {
+	cycles := make([]Cycle, 0, len(g.cycles))
+	for _, c := range g.cycles {
+		cycles = append(cycles, *c)
+	}
+	slices.SortFunc(cycles, func(a, b Cycle) int { return a.End.Compare(b.End) })
+	return cycles
+}
+
+// parseCCUsage groups the days or weeks of a ccusage report into cycles.
+// Days count at noon so they fall into the window holding most of them.
+func parseCCUsage(data []byte, phase time.Time) ([]Cycle, error) {
+	var report ccusageReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("reading ccusage report: %w", err)
+	}
+	if report.Daily == nil && report.Weekly == nil {
+		return nil, fmt.Errorf("%w (export with `ccusage daily --json`)", ErrUnknownImport)
+	}
+
+	g := grouper{phase: phase, cycles: map[time.Time]*Cycle{}}
+	add := func(date string, tokens int64, cost float64, offset time.Duration) error {
+		day, err := time.ParseInLocation(time.DateOnly, date, time.Local)
+		if err != nil {
+			return fmt.Errorf("reading ccusage report: %w", err)
+		}
+		c := g.week(day.Add(offset), SourceCCUsage)
+		c.Tokens += tokens
+		c.CostUSD += cost
+		return nil
+	}
+	for _, d := range report.Daily {
+		if err := add(d.Date, d.TotalTokens, d.TotalCost, 12*time.Hour); err != nil {
+			return nil, err
+		}
+	}
+	for _, w := range report.Weekly {
+		if err := add(w.Week, w.TotalTokens, w.TotalCost, weeklyWindow/2); err != nil {
+			return nil, err
+		}
+	}
+	return g.sorted(), nil
+}
+
+// parseCSV groups utilization readings into cycles peaking at the highest
+// reading of each week. The header names the columns: time (or timestamp,
+// date) and utilization (or weekly, percent).
+func parseCSV(data []byte, phase time.Time) ([]Cycle, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, ErrUnknownImport
+	}
+	timeCol, pctCol := -1, -1
+	for i, name := range records[0] {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "time", "timestamp", "date":
+			timeCol = i
+		case "utilization", "weekly", "percent":
+			pctCol = i
+		}
+	}
+	if timeCol < 0 || pctCol < 0 {
+		return nil, ErrUnknownImport
+	}
+
+	g := grouper{phase: phase, cycles: map[time.Time]*Cycle{}}
+	for n, rec := range records[1:] {
+		if len(rec) <= max(timeCol, pctCol) {
+			continue
+		}
+		at, err := parseCSVTime(strings.TrimSpace(rec[timeCol]))
+		if err != nil {
+			return nil, fmt.Errorf("CSV line %d: %w", n+2, err)
+		}
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(rec[pctCol]), "%"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("CSV line %d: %w", n+2, err)
+		}
+		c := g.week(at, SourceCSV)
+		c.PeakUtilization = max(c.PeakUtilization, pct)
+	}
+	return g.sorted(), nil
+}
+
+func parseCSVTime(s string) (time.Time, error) {
+	for _, layout := range csvTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", s)
+}
+
+// Import adds imported cycles to the history, skipping those that overlap a
+// week already recorded or the cycle in progress. Returns how many were added.
+func (t *Tracker) Import(cycles []Cycle) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	existing, err := Cycles(t.dir)
+	if err != nil {
+		return 0, err
+	}
+	taken := existing
+	if !t.current.End.IsZero() {
+		taken = append(slices.Clip(taken), t.current)
+	}
+	overlaps := func(c Cycle) bool {
+		return slices.ContainsFunc(taken, func(o Cycle) bool {
+			return c.Start.Before(o.End) && o.Start.Before(c.End)
+		})
+	}
+
+	merged := existing
+	added := 0
+	for _, c := range cycles {
+		if !overlaps(c) {
+			merged = append(merged, c)
+			added++
+		}
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	slices.SortStableFunc(merged, func(a, b Cycle) int { return a.End.Compare(b.End) })
+	return added, t.rewrite(merged)
+}
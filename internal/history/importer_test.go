@@ -0,0 +1,74 @@
+package history
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+func TestParseImport(t *testing.T) {
+	reset := time.Date(2026, 10, 19, 9, 0, 0, 0, time.Local) // a Monday
+	tracker := NewTracker(t.TempDir())
+	tracker.Observe(api.UsageStat{Utilization: 10, ResetsAt: reset})
+
+	ccusage := `{"daily": [
+		{"date": "2026-09-28", "totalTokens": 1000, "totalCost": 1.5},
+		{"date": "2026-10-04", "totalTokens": 500, "totalCost": 0.5},
+		{"date": "2026-10-05", "totalTokens": 200, "totalCost": 0.25}
+	], "totals": {}}`
+	cycles, err := tracker.ParseImport(strings.NewReader(ccusage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cycles) != 2 {
+		t.Fatalf("ccusage import gave %d weeks, want 2: %+v", len(cycles), cycles)
+	}
+	first := cycles[0]
+	if !first.End.Equal(time.Date(2026, 10, 5, 9, 0, 0, 0, time.Local)) || first.Tokens != 1500 ||
+		first.CostUSD != 2 || first.Source != SourceCCUsage || first.Measured() {
+		t.Errorf("first ccusage week = %+v", first)
+	}
+
+	csv := "timestamp,weekly\n2026-10-06 10:00,20\n2026-10-08T12:00:00+02:00,55%\n2026-10-12 08:00,30\n"
+	cycles, err = tracker.ParseImport(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cycles) != 1 || cycles[0].PeakUtilization != 55 || !cycles[0].Measured() {
+		t.Errorf("CSV import = %+v, want one week peaking at 55%%", cycles)
+	}
+
+	if _, err := tracker.ParseImport(strings.NewReader("a,b\n1,2\n")); !errors.Is(err, ErrUnknownImport) {
+		t.Errorf("unknown CSV: err = %v, want ErrUnknownImport", err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	reset := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	tracker := NewTracker(dir)
+	tracker.Observe(api.UsageStat{Utilization: 10, ResetsAt: reset})
+	if err := tracker.append(Cycle{Start: reset.Add(-2 * weeklyWindow), End: reset.Add(-weeklyWindow), PeakUtilization: 70}); err != nil {
+		t.Fatal(err)
+	}
+
+	week := func(weeksBack int) Cycle {
+		end := reset.Add(-time.Duration(weeksBack) * weeklyWindow)
+		return Cycle{Start: end.Add(-weeklyWindow), End: end, Source: SourceCSV, PeakUtilization: 50}
+	}
+	// The current week and the recorded one are skipped
+	added, err := tracker.Import([]Cycle{week(3), week(2), week(1), week(0)})
+	if err != nil || added != 2 {
+		t.Fatalf("Import = %d, %v, want 2, nil", added, err)
+	}
+	cycles, err := Cycles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cycles) != 3 || cycles[2].PeakUtilization != 70 || cycles[0].Source != SourceCSV {
+		t.Errorf("history after import = %+v", cycles)
+	}
+}
@@ -0,0 +1,217 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+// resetsFile holds the ResetLog of each metric; it starts with "history" like
+// the cycle files so backups treat it as history
+const resetsFile = "history-resets.json"
+
+const (
+	// maxResets is how many reset times are kept per metric
+	maxResets = 12
+	// driftConfirmations is how many readings must contradict a window
+	// before it is recalibrated, so a single odd response changes nothing
+	driftConfirmations = 2
+)
+
+// sessionWindow is the length of the session usage window
+const sessionWindow = 5 * time.Hour
+
+// DefaultWindows are the window lengths assumed per metric until the reset
+// history shows otherwise
+var DefaultWindows = map[string]time.Duration{
+	"session": sessionWindow,
+	"weekly":  weeklyWindow,
+	"opus":    weeklyWindow,
+	"sonnet":  weeklyWindow,
+}
+
+// ResetLog is the reset history of one metric
+type ResetLog struct {
+	Window time.Duration `json:"window"` // calibrated window length
+	Resets []time.Time   `json:"resets"` // distinct reset times, oldest first
+
+	// Readings in the latest window whose reset lay further ahead than
+	// Window allows, and the furthest of them
+	Longer int           `json:"longer,omitempty"`
+	Span   time.Duration `json:"span,omitempty"`
+}
+
+// Drift is a change in the length of a metric's window
+type Drift struct {
+	Metric string
+	Old    time.Duration
+	New    time.Duration
+}
+
+// String describes the change, e.g. "weekly window changed from 7d to 5d"
+func (d Drift) String() string {
+	return fmt.Sprintf("%s window changed from %s to %s", d.Metric, windowString(d.Old), windowString(d.New))
+}
+
+// windowString formats a window length in whole days or hours, e.g. "7d"
+func windowString(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return fmt.Sprintf("%dh", int(d.Round(time.Hour).Hours()))
+}
+
+// observe records a reading of the metric taken at now and recalibrates the
+// window when the readings no longer fit it. A reset later than the window
+// allows means it grew; consecutive resets closer together than the window
+// mean it shrank. Returns the drift, if any, and whether l changed.
+func (l *ResetLog) observe(stat api.UsageStat, now time.Time) (drift *Drift, changed bool) {
+	if stat.ResetsAt.IsZero() {
+		return nil, false
+	}
+
+	if n := len(l.Resets); n == 0 || stat.ResetsAt.Sub(l.Resets[n-1]) > resetJitter {
+		l.Resets = append(l.Resets, stat.ResetsAt)
+		if len(l.Resets) > maxResets {
+			l.Resets = l.Resets[len(l.Resets)-maxResets:]
+		}
+		l.Longer, l.Span = 0, 0
+		changed = true
+		if gap, ok := l.shorterGap(); ok {
+			return l.recalibrate(gap), true
+		}
+	}
+
+	// A reading only ever sees part of its window, so the time left is a
+	// lower bound of the window length
+	if left := stat.ResetsAt.Sub(now); left > l.Window+resetJitter {
+		l.Longer++
+		l.Span = max(l.Span, left)
+		changed = true
+		if l.Longer >= driftConfirmations {
+			return l.recalibrate(l.Span), true
+		}
+	}
+	return nil, changed
+}
+
+// shorterGap returns the gap between the latest resets when the last
+// driftConfirmations gaps are all shorter than the window and agree with
+// each other
+func (l *ResetLog) shorterGap() (time.Duration, bool) {
+	n := len(l.Resets)
+	if n <= driftConfirmations {
+		return 0, false
+	}
+	latest := l.Resets[n-1].Sub(l.Resets[n-2])
+	for i := n - driftConfirmations; i < n; i++ {
+		gap := l.Resets[i].Sub(l.Resets[i-1])
+		if gap >= l.Window-resetJitter || (gap-latest).Abs() > resetJitter {
+			return 0, false
+		}
+	}
+	return latest, true
+}
+
+// recalibrate sets the window to length, rounded to the hour
+func (l *ResetLog) recalibrate(length time.Duration) *Drift {
+	length = length.Round(time.Hour)
+	if length <= 0 || length == l.Window {
+		return nil
+	}
+	drift := &Drift{Old: l.Window, New: length}
+	l.Window = length
+	l.Longer, l.Span = 0, 0
+	return drift
+}
+
+// ObserveResets records the reset times of a fetch taken at now and returns
+// the windows that changed length because of it
+func (t *Tracker) ObserveResets(now time.Time, u *api.UsageData) []Drift {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var drifts []Drift
+	changed := false
+	for _, name := range api.MetricNames {
+		stat := u.Metric(name)
+		if stat == nil {
+			continue
+		}
+		l := t.resetLog(name)
+		drift, c := l.observe(*stat, now)
+		changed = changed || c
+		if drift != nil {
+			drift.Metric = name
+			drifts = append(drifts, *drift)
+		}
+	}
+	if changed {
+		if err := t.saveResets(); err != nil {
+			log.Printf("History: failed to save reset times: %v", err)
+		}
+	}
+	return drifts
+}
+
+// Window returns the calibrated window length of the named metric
+func (t *Tracker) Window(metric string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.window(metric)
+}
+
+// window returns the window length of metric. Must be called with t.mu held.
+func (t *Tracker) window(metric string) time.Duration {
+	if l, ok := t.resets[metric]; ok && l.Window > 0 {
+		return l.Window
+	}
+	return DefaultWindows[metric]
+}
+
+// resetLog returns the reset log of metric, creating it with the default
+// window. Must be called with t.mu held.
+func (t *Tracker) resetLog(metric string) *ResetLog {
+	l, ok := t.resets[metric]
+	if !ok {
+		l = &ResetLog{}
+		t.resets[metric] = l
+	}
+	if l.Window <= 0 {
+		l.Window = DefaultWindows[metric]
+	}
+	return l
+}
+
+// loadResets reads the reset logs stored in dir; none if nothing was saved
+func loadResets(dir string) (map[string]*ResetLog, error) {
+	resets := map[string]*ResetLog{}
+	data, err := os.ReadFile(filepath.Join(dir, resetsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return resets, nil
+	}
+	if err != nil {
+		return resets, err
+	}
+	err = json.Unmarshal(data, &resets)
+	return resets, err
+}
+
+// saveResets writes the reset logs. Must be called with t.mu held.
+func (t *Tracker) saveResets() error {
+	data, err := json.Marshal(t.resets)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(t.dir, resetsFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(t.dir, resetsFile))
+}
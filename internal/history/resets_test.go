@@ -0,0 +1,82 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+func TestObserveResets(t *testing.T) {
+	start := time.Date(2026, 10, 12, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	type reading struct {
+		at, resets time.Time
+		want       time.Duration // weekly window after the reading
+	}
+	tests := []struct {
+		name     string
+		readings []reading
+	}{
+		{"steady weekly cadence", []reading{
+			{start, start.Add(6 * day), weeklyWindow},
+			{start.Add(6 * day), start.Add(13 * day), weeklyWindow},
+			{start.Add(13 * day), start.Add(20 * day), weeklyWindow},
+		}},
+		{"single short gap is ignored", []reading{
+			{start, start.Add(day), weeklyWindow},
+			{start.Add(day), start.Add(6 * day), weeklyWindow},
+			{start.Add(6 * day), start.Add(13 * day), weeklyWindow},
+		}},
+		{"window shrinks to five days", []reading{
+			{start, start.Add(day), weeklyWindow},
+			{start.Add(day), start.Add(6 * day), weeklyWindow},
+			{start.Add(6 * day), start.Add(11 * day), 5 * day},
+		}},
+		{"single long reading is ignored", []reading{
+			{start, start.Add(10 * day), weeklyWindow},
+			{start.Add(time.Minute), start.Add(time.Minute + 5*day), weeklyWindow},
+		}},
+		{"window grows to ten days", []reading{
+			{start, start.Add(10 * day), weeklyWindow},
+			{start.Add(time.Hour), start.Add(10 * day), 10 * day},
+		}},
+	}
+	for _, tt := range tests {
+		tracker := NewTracker(t.TempDir())
+		drifts := 0
+		for i, r := range tt.readings {
+			drifts += len(tracker.ObserveResets(r.at, &api.UsageData{SevenDay: api.UsageStat{ResetsAt: r.resets}}))
+			if got := tracker.Window("weekly"); got != r.want {
+				t.Errorf("%s: reading %d: window = %v, want %v", tt.name, i, got, r.want)
+			}
+		}
+		if want := tracker.Window("weekly") != weeklyWindow; (drifts > 0) != want {
+			t.Errorf("%s: %d drifts reported", tt.name, drifts)
+		}
+		if got := tracker.Window("session"); got != sessionWindow {
+			t.Errorf("%s: session window = %v, want %v", tt.name, got, sessionWindow)
+		}
+	}
+}
+
+func TestResetsPersist(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 10, 12, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker(dir)
+	for i := range 2 {
+		tracker.ObserveResets(start.Add(time.Duration(i)*time.Hour), &api.UsageData{
+			SevenDay: api.UsageStat{ResetsAt: start.Add(10 * 24 * time.Hour)},
+		})
+	}
+	if got := NewTracker(dir).Window("weekly"); got != 10*24*time.Hour {
+		t.Errorf("window after reload = %v, want 240h", got)
+	}
+	if err := tracker.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if got := NewTracker(dir).Window("weekly"); got != weeklyWindow {
+		t.Errorf("window after Clear = %v, want %v", got, weeklyWindow)
+	}
+}
@@ -0,0 +1,263 @@
+package history
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"claudebar/internal/api"
+)
+
+// SamplesFile is the SQLite database holding every successful fetch. Like
+// the other history files it starts with "history", so backups leave it out
+// unless history is requested; they take it with CopySamples, as it is open
+// while the app runs.
+const SamplesFile = "history-samples.db"
+
+const samplesSchema = `
+CREATE TABLE IF NOT EXISTS samples (
+	time INTEGER NOT NULL,
+	session REAL NOT NULL,
+	weekly REAL NOT NULL,
+	opus REAL NOT NULL,
+	sonnet REAL NOT NULL,
+	session_resets_at INTEGER NOT NULL,
+	weekly_resets_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS samples_time ON samples (time);
+CREATE TABLE IF NOT EXISTS samples_hourly (
+	time INTEGER PRIMARY KEY,
+	session REAL NOT NULL,
+	weekly REAL NOT NULL,
+	opus REAL NOT NULL,
+	sonnet REAL NOT NULL,
+	session_resets_at INTEGER NOT NULL,
+	weekly_resets_at INTEGER NOT NULL
+);
+CREATE VIEW IF NOT EXISTS all_samples AS
+	SELECT * FROM samples UNION ALL SELECT * FROM samples_hourly;
+`
+
+// Sample is the usage of one successful fetch. Times are stored to the
+// second; a zero reset time means the API gave none.
+type Sample struct {
+	Time            time.Time
+	Session         float64 // five-hour utilization, 0-100
+	Weekly          float64
+	Opus            float64
+	Sonnet          float64
+	SessionResetsAt time.Time
+	WeeklyResetsAt  time.Time
+}
+
+// NewSample takes the utilizations and reset times of a fetch made at at
+func NewSample(at time.Time, u *api.UsageData) Sample {
+	return Sample{
+		Time:            at,
+		Session:         u.FiveHour.Utilization,
+		Weekly:          u.SevenDay.Utilization,
+		Opus:            u.SevenDayOpus.Utilization,
+		Sonnet:          u.SevenDaySonnet.Utilization,
+		SessionResetsAt: u.FiveHour.ResetsAt,
+		WeeklyResetsAt:  u.SevenDay.ResetsAt,
+	}
+}
+
+// Samples is the database of fetched usage. Methods are safe for concurrent
+// use.
+type Samples struct {
+	mu sync.Mutex
+	db *sql.DB
+}
+
+// OpenSamples opens the sample database in dir, creating it if needed
+func OpenSamples(dir string) (*Samples, error) {
+	db, err := sql.Open("sqlite3", filepath.Join(dir, SamplesFile))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(samplesSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating sample table: %w", err)
+	}
+	return &Samples{db: db}, nil
+}
+
+// CopySamples writes a consistent copy of the sample database in dir to
+// dest, which must not exist yet, even while the app is writing to it.
+// Returns an error wrapping os.ErrNotExist when there is no database.
+func CopySamples(dir, dest string) error {
+	src := filepath.Join(dir, SamplesFile)
+	if _, err := os.Stat(src); err != nil {
+		return err
+	}
+	db, err := sql.Open("sqlite3", src)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	_, err = db.Exec("VACUUM INTO ?", dest)
+	return err
+}
+
+// Add records a sample
+func (s *Samples) Add(smp Sample) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.db.Exec("INSERT INTO samples VALUES (?, ?, ?, ?, ?, ?, ?)",
+		smp.Time.Unix(), smp.Session, smp.Weekly, smp.Opus, smp.Sonnet,
+		unixOrZero(smp.SessionResetsAt), unixOrZero(smp.WeeklyResetsAt))
+	return err
+}
+
+// Range returns the samples taken from from up to but not including to,
+// oldest first. Past the raw retention these are the hourly rollups.
+func (s *Samples) Range(from, to time.Time) ([]Sample, error) {
+	return s.query(`SELECT time, session, weekly, opus, sonnet, session_resets_at, weekly_resets_at
+		FROM all_samples WHERE time >= ? AND time < ? ORDER BY time`, from.Unix(), to.Unix())
+}
+
+// Downsample returns one sample per step between from and to for charts
+// over long ranges: the highest utilization of each metric seen in the step
+// and the latest reset times, stamped with the start of the step. Steps
+// without samples are left out.
+func (s *Samples) Downsample(from, to time.Time, step time.Duration) ([]Sample, error) {
+	sec := int64(step / time.Second)
+	if sec < 1 {
+		return s.Range(from, to)
+	}
+	return s.query(`SELECT time - (time - ?1) % ?2 AS bucket, MAX(session), MAX(weekly), MAX(opus), MAX(sonnet),
+		MAX(session_resets_at), MAX(weekly_resets_at)
+		FROM all_samples WHERE time >= ?1 AND time < ?3 GROUP BY bucket ORDER BY bucket`,
+		from.Unix(), sec, to.Unix())
+}
+
+// Rollup folds the samples taken before the hour of cutoff into one per
+// hour, as Downsample would, and returns how many samples it replaced
+func (s *Samples) Rollup(cutoff time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Whole hours only, so a later rollup never meets an hour already rolled up
+	end := cutoff.Truncate(time.Hour).Unix()
+	if _, err := tx.Exec(`INSERT OR REPLACE INTO samples_hourly
+		SELECT time - time % 3600 AS hour, MAX(session), MAX(weekly), MAX(opus), MAX(sonnet),
+			MAX(session_resets_at), MAX(weekly_resets_at)
+		FROM samples WHERE time < ? GROUP BY hour`, end); err != nil {
+		return 0, err
+	}
+	res, err := tx.Exec("DELETE FROM samples WHERE time < ?", end)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Prune deletes the samples and hourly rollups from before cutoff and
+// returns how many
+func (s *Samples) Prune(cutoff time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var removed int64
+	for _, table := range []string{"samples", "samples_hourly"} {
+		res, err := s.db.Exec("DELETE FROM "+table+" WHERE time < ?", cutoff.Unix())
+		if err != nil {
+			return removed, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return removed, err
+		}
+		removed += n
+	}
+	return removed, nil
+}
+
+// Close closes the database
+func (s *Samples) Close() error {
+	return s.db.Close()
+}
+
+func (s *Samples) query(q string, args ...any) ([]Sample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rows, err := s.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []Sample
+	for rows.Next() {
+		var smp Sample
+		var at, sessionReset, weeklyReset int64
+		if err := rows.Scan(&at, &smp.Session, &smp.Weekly, &smp.Opus, &smp.Sonnet, &sessionReset, &weeklyReset); err != nil {
+			return nil, err
+		}
+		smp.Time = time.Unix(at, 0)
+		smp.SessionResetsAt = timeOrZero(sessionReset)
+		smp.WeeklyResetsAt = timeOrZero(weeklyReset)
+		samples = append(samples, smp)
+	}
+	return samples, rows.Err()
+}
+
+// unixOrZero stores a zero time as 0 rather than its negative Unix time
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func timeOrZero(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+// Record adds the usage of a successful fetch to the sample database
+func (t *Tracker) Record(at time.Time, u *api.UsageData) {
+	samples, err := t.Samples()
+	if err == nil {
+		err = samples.Add(NewSample(at, u))
+	}
+	if err != nil {
+		log.Printf("History: failed to record sample: %v", err)
+	}
+}
+
+// Samples returns the sample database, opening it on first use
+func (t *Tracker) Samples() (*Samples, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.openSamples()
+}
+
+// openSamples returns the sample database, opening it if needed. Must be
+// called with t.mu held.
+func (t *Tracker) openSamples() (*Samples, error) {
+	if t.samples == nil {
+		samples, err := OpenSamples(t.dir)
+		if err != nil {
+			return nil, err
+		}
+		t.samples = samples
+	}
+	return t.samples, nil
+}
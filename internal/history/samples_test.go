@@ -0,0 +1,113 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+func TestSamples(t *testing.T) {
+	tr := NewTracker(t.TempDir())
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	reset := start.Add(4 * time.Hour)
+	for i, pct := range []float64{10, 12, 11, 20, 25} {
+		u := &api.UsageData{
+			FiveHour: api.UsageStat{Utilization: pct, ResetsAt: reset},
+			SevenDay: api.UsageStat{Utilization: pct / 2},
+		}
+		tr.Record(start.Add(time.Duration(i)*10*time.Minute), u)
+	}
+	samples, err := tr.Samples()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := samples.Range(start.Add(10*time.Minute), start.Add(40*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].Session != 12 || got[2].Session != 20 {
+		t.Fatalf("Range = %+v, want the 12%%, 11%% and 20%% samples", got)
+	}
+	if !got[0].SessionResetsAt.Equal(reset) || !got[0].WeeklyResetsAt.IsZero() {
+		t.Errorf("reset times = %v, %v; want %v and zero", got[0].SessionResetsAt, got[0].WeeklyResetsAt, reset)
+	}
+
+	// Half-hour steps keep the highest reading of each
+	down, err := samples.Downsample(start, start.Add(time.Hour), 30*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(down) != 2 || down[0].Session != 12 || down[1].Session != 25 || !down[1].Time.Equal(start.Add(30*time.Minute)) {
+		t.Errorf("Downsample = %+v, want 12%% at the start and 25%% half an hour later", down)
+	}
+
+	// Compaction prunes samples past the retention
+	if _, err := tr.Compact(start.Add(time.Hour), 45*time.Minute, 0); err != nil {
+		t.Fatal(err)
+	}
+	all, err := samples.Range(time.Time{}, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("%d samples left after compaction, want 3", len(all))
+	}
+
+	if err := tr.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	samples, err = tr.Samples()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all, _ := samples.Range(time.Time{}, start.Add(time.Hour)); len(all) != 0 {
+		t.Errorf("%d samples left after Clear", len(all))
+	}
+}
+
+func TestSampleRollup(t *testing.T) {
+	tr := NewTracker(t.TempDir())
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	// Every 15 minutes for three hours: 10%, 11%, ... 21%
+	for i := 0; i < 12; i++ {
+		tr.Record(start.Add(time.Duration(i)*15*time.Minute), &api.UsageData{
+			FiveHour: api.UsageStat{Utilization: float64(10 + i)},
+		})
+	}
+
+	// Two and a half hours later the first two hours are rolled up, and
+	// the samples of the hour the cutoff falls in stay as they were
+	now := start.Add(5 * time.Hour)
+	if _, err := tr.Compact(now, 30*24*time.Hour, 150*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	samples, err := tr.Samples()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := samples.Range(start, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 6 || got[0].Session != 13 || !got[0].Time.Equal(start) ||
+		got[1].Session != 17 || !got[1].Time.Equal(start.Add(time.Hour)) || got[2].Session != 18 {
+		t.Fatalf("Range after rollup = %+v, want hourly peaks 13%% and 17%%, then 18%% to 21%%", got)
+	}
+
+	// Rolling up again changes nothing, and the long retention prunes the
+	// rollups too
+	if _, err := tr.Compact(now, 30*24*time.Hour, 150*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := samples.Range(start, now); len(again) != 6 {
+		t.Errorf("%d samples after a second rollup, want 6", len(again))
+	}
+	if _, err := tr.Compact(now, 4*time.Hour, 150*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if left, _ := samples.Range(start, now); len(left) != 5 {
+		t.Errorf("%d samples after pruning the first hour, want 5", len(left))
+	}
+}
@@ -0,0 +1,144 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// summaryFile records the last month a summary was due for
+const summaryFile = "history-summary.json"
+
+// Peak utilization thresholds for the streaks
+const (
+	limitPeak  = 100 // the weekly limit was hit
+	streakPeak = 80  // a comfortable week
+)
+
+// Stats are streaks and totals derived from completed weekly cycles
+type Stats struct {
+	Weeks          int
+	LimitWeeks     int     // weeks that reached the limit
+	AveragePeak    float64 // mean of the weekly peaks
+	UnderLimitRun  int     // weeks since the limit was last hit
+	Under80Run     int     // current run of weeks peaking under 80%
+	LongestUnder80 int     // longest such run
+}
+
+// ComputeStats derives the stats from cycles, oldest first. Cycles without a
+// measured peak are left out.
+func ComputeStats(cycles []Cycle) Stats {
+	var s Stats
+	var total float64
+	for _, c := range cycles {
+		if !c.Measured() {
+			continue
+		}
+		s.Weeks++
+		total += c.PeakUtilization
+		if c.PeakUtilization >= limitPeak {
+			s.LimitWeeks++
+			s.UnderLimitRun = 0
+		} else {
+			s.UnderLimitRun++
+		}
+		if c.PeakUtilization < streakPeak {
+			s.Under80Run++
+			s.LongestUnder80 = max(s.LongestUnder80, s.Under80Run)
+		} else {
+			s.Under80Run = 0
+		}
+	}
+	if s.Weeks > 0 {
+		s.AveragePeak = total / float64(s.Weeks)
+	}
+	return s
+}
+
+// Highlights describes the stats in short lines for the stats card, e.g.
+// "5 weeks without hitting the limit"
+func (s Stats) Highlights() []string {
+	if s.Weeks == 0 {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("%s without hitting the limit", plural(s.UnderLimitRun, "week"))}
+	if s.Under80Run > 0 {
+		lines = append(lines, fmt.Sprintf("%s in a row under 80%%", plural(s.Under80Run, "week")))
+	}
+	lines = append(lines,
+		fmt.Sprintf("Longest streak under 80%%: %s", plural(s.LongestUnder80, "week")),
+		fmt.Sprintf("Average weekly peak: %.0f%% over %s", s.AveragePeak, plural(s.Weeks, "week")),
+	)
+	return lines
+}
+
+// MonthSummary sums up the cycles that ended in the month starting at month,
+// e.g. "4 weeks, average peak 63%, limit hit once. 5 weeks without hitting
+// the limit". ok is false when no cycle ended that month.
+func MonthSummary(cycles []Cycle, month time.Time) (summary string, ok bool) {
+	var inMonth []Cycle
+	for _, c := range cycles {
+		end := c.End.In(month.Location())
+		if end.Year() == month.Year() && end.Month() == month.Month() {
+			inMonth = append(inMonth, c)
+		}
+	}
+	m := ComputeStats(inMonth)
+	if m.Weeks == 0 {
+		return "", false
+	}
+	limit := "limit never hit"
+	if m.LimitWeeks == 1 {
+		limit = "limit hit once"
+	} else if m.LimitWeeks > 1 {
+		limit = fmt.Sprintf("limit hit %d times", m.LimitWeeks)
+	}
+	all := ComputeStats(cycles)
+	return strings.Join([]string{
+		fmt.Sprintf("%s, average peak %.0f%%, %s.", plural(m.Weeks, "week"), m.AveragePeak, limit),
+		fmt.Sprintf("%s without hitting the limit.", plural(all.UnderLimitRun, "week")),
+	}, " "), true
+}
+
+// SummaryDue reports whether the monthly summary for the previous month is
+// still to be sent, and returns the start of that month. Each month is due
+// once; the first call only records the current month.
+func (t *Tracker) SummaryDue(now time.Time) (month time.Time, due bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	path := filepath.Join(t.dir, summaryFile)
+	var last time.Time
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &last); err != nil {
+			return time.Time{}, false
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return time.Time{}, false
+	}
+	if !last.IsZero() && !current.After(last) {
+		return time.Time{}, false
+	}
+	if data, err := json.Marshal(current); err == nil {
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			log.Printf("History: failed to save summary month: %v", err)
+		}
+	}
+	if last.IsZero() {
+		return time.Time{}, false
+	}
+	return current.AddDate(0, -1, 0), true
+}
+
+// Cycles returns the completed weekly cycles, oldest first
+func (t *Tracker) Cycles() ([]Cycle, error) {
+	return Cycles(t.dir)
+}
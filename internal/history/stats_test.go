@@ -0,0 +1,70 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeStats(t *testing.T) {
+	week := func(peak float64) Cycle { return Cycle{PeakUtilization: peak} }
+	tests := []struct {
+		name  string
+		peaks []float64
+		want  Stats
+	}{
+		{"no history", nil, Stats{}},
+		{"limit hit last week", []float64{50, 100}, Stats{Weeks: 2, LimitWeeks: 1, AveragePeak: 75, LongestUnder80: 1}},
+		{"streaks", []float64{60, 70, 90, 100, 40, 85, 30, 20}, Stats{
+			Weeks: 8, LimitWeeks: 1, AveragePeak: 61.875,
+			UnderLimitRun: 4, Under80Run: 2, LongestUnder80: 2,
+		}},
+	}
+	for _, tt := range tests {
+		var cycles []Cycle
+		for _, p := range tt.peaks {
+			cycles = append(cycles, week(p))
+		}
+		if got := ComputeStats(cycles); got != tt.want {
+			t.Errorf("%s: ComputeStats = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMonthSummary(t *testing.T) {
+	ending := func(day int, peak float64) Cycle {
+		return Cycle{End: time.Date(2026, 9, day, 12, 0, 0, 0, time.UTC), PeakUtilization: peak}
+	}
+	cycles := []Cycle{
+		{End: time.Date(2026, 8, 31, 12, 0, 0, 0, time.UTC), PeakUtilization: 100},
+		ending(7, 50), ending(14, 100), ending(21, 70), ending(28, 60),
+	}
+	september := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	got, ok := MonthSummary(cycles, september)
+	want := "4 weeks, average peak 70%, limit hit once. 2 weeks without hitting the limit."
+	if !ok || got != want {
+		t.Errorf("MonthSummary = %q, %v, want %q", got, ok, want)
+	}
+	if _, ok := MonthSummary(cycles, september.AddDate(0, 1, 0)); ok {
+		t.Error("MonthSummary summed up a month without cycles")
+	}
+}
+
+func TestSummaryDue(t *testing.T) {
+	dir := t.TempDir()
+	oct := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	if _, due := NewTracker(dir).SummaryDue(oct); due {
+		t.Error("first call is due")
+	}
+	if _, due := NewTracker(dir).SummaryDue(oct.AddDate(0, 0, 3)); due {
+		t.Error("due again in the same month")
+	}
+	month, due := NewTracker(dir).SummaryDue(oct.AddDate(0, 1, 0))
+	if want := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC); !due || !month.Equal(want) {
+		t.Errorf("SummaryDue in November = %v, %v, want %v, true", month, due, want)
+	}
+	if _, due := NewTracker(dir).SummaryDue(oct.AddDate(0, 1, 1)); due {
+		t.Error("November summary due twice")
+	}
+}
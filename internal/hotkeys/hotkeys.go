@@ -3,28 +3,97 @@ package hotkeys
 import (
 	"claudebar/internal/platform"
 	"log"
+	"strings"
 	"sync"
+	"time"
 )
 
+// NudgeStep is how many pixels a nudge hotkey moves the floating overlay
+const NudgeStep = 10
+
+// nudges are the moves of the Ctrl+Alt+Shift+Arrow hotkeys while the
+// overlay is floating; when it is snapped, all but up snap to a corner
+var nudges = map[int][2]int{
+	platform.HotkeySnapTopLeft:    {-NudgeStep, 0},
+	platform.HotkeySnapTopRight:   {NudgeStep, 0},
+	platform.HotkeySnapBottomLeft: {0, NudgeStep},
+	platform.HotkeyNudgeUp:        {0, -NudgeStep},
+}
+
+// DefaultDoublePressInterval is how soon a second press must follow the
+// first to count as a double press
+const DefaultDoublePressInterval = 400 * time.Millisecond
+
+// Names are the hotkeys' names in the config, by ID
+var Names = map[int]string{
+	platform.HotkeySnapLeft:        "snap_left",
+	platform.HotkeySnapRight:       "snap_right",
+	platform.HotkeySnapTop:         "snap_top",
+	platform.HotkeySnapTopLeft:     "snap_top_left",
+	platform.HotkeySnapTopRight:    "snap_top_right",
+	platform.HotkeySnapBottomLeft:  "snap_bottom_left",
+	platform.HotkeySnapBottomRight: "snap_bottom_right",
+	platform.HotkeyToggleOverlay:   "toggle_overlay",
+	platform.HotkeyNextPage:        "next_page",
+	platform.HotkeyNudgeUp:         "nudge_up",
+	platform.HotkeyInteract:        "interact",
+}
+
+// ID returns the ID of the hotkey with the config name name
+func ID(name string) (int, bool) {
+	for id, n := range Names {
+		if n == name {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
 // SnapCallback is called when a snap hotkey is pressed
 type SnapCallback func(position platform.SnapPosition)
 
 // ToggleCallback is called when the toggle overlay hotkey is pressed
 type ToggleCallback func()
 
+// PageCallback is called when the next page hotkey is pressed
+type PageCallback func()
+
+// InteractCallback is called when the interact hotkey is pressed
+type InteractCallback func()
+
+// NudgeCallback is called when a nudge hotkey is pressed while the overlay
+// is floating, with the move in pixels
+type NudgeCallback func(dx, dy int)
+
 // Manager handles global hotkey registration and events
 type Manager struct {
-	platform       platform.PlatformFeatures
-	snapCallback   SnapCallback
-	toggleCallback ToggleCallback
-	mu             sync.Mutex
-	running        bool
+	platform         platform.PlatformFeatures
+	snapCallback     SnapCallback
+	toggleCallback   ToggleCallback
+	pageCallback     PageCallback
+	nudgeCallback    NudgeCallback
+	interactCallback InteractCallback
+	floating         func() bool // whether the overlay is floating, for nudges
+	mu               sync.Mutex
+	running          bool
+	suspended        bool // paused from the tray
+	appPaused        bool // paused while an excluded app is focused
+	released         bool // hotkeys are currently handed back to other apps
+	applyMu          sync.Mutex
+
+	// Double presses: actions by hotkey ID, how soon the second press must
+	// follow, and the first presses still waiting for one
+	doublePress   map[int]func()
+	pressInterval time.Duration
+	pending       map[int]*time.Timer
 }
 
 // NewManager creates a new hotkey manager
 func NewManager() *Manager {
 	return &Manager{
-		platform: platform.Features,
+		platform:    platform.Features,
+		doublePress: make(map[int]func()),
+		pending:     make(map[int]*time.Timer),
 	}
 }
 
@@ -42,6 +111,58 @@ func (m *Manager) SetToggleCallback(callback ToggleCallback) {
 	m.toggleCallback = callback
 }
 
+// SetPageCallback sets the callback for the next page hotkey
+func (m *Manager) SetPageCallback(callback PageCallback) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pageCallback = callback
+}
+
+// SetInteractCallback sets the callback for the interact hotkey
+func (m *Manager) SetInteractCallback(callback InteractCallback) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.interactCallback = callback
+}
+
+// SetNudgeCallback sets the callback for nudge hotkeys, and floating, which
+// reports whether the overlay is floating. The nudge hotkeys snap to a corner
+// while floating returns false.
+func (m *Manager) SetNudgeCallback(floating func() bool, callback NudgeCallback) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.floating = floating
+	m.nudgeCallback = callback
+}
+
+// SetDoublePressAction runs action when hotkey id is pressed twice in quick
+// succession, instead of its usual action twice. The usual action then waits
+// until no second press came. A nil action removes the double press.
+func (m *Manager) SetDoublePressAction(id int, action func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if action == nil {
+		delete(m.doublePress, id)
+		return
+	}
+	m.doublePress[id] = action
+}
+
+// ClearDoublePressActions removes all double-press actions
+func (m *Manager) ClearDoublePressActions() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clear(m.doublePress)
+}
+
+// SetDoublePressInterval sets how soon the second press must follow the
+// first; 0 uses DefaultDoublePressInterval
+func (m *Manager) SetDoublePressInterval(interval time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pressInterval = interval
+}
+
 // Start begins listening for hotkeys
 func (m *Manager) Start() error {
 	m.mu.Lock()
@@ -66,7 +187,10 @@ func (m *Manager) Start() error {
 	log.Println("  Ctrl+Alt+Shift+Left    -> Snap top-left")
 	log.Println("  Ctrl+Alt+Shift+Right   -> Snap top-right")
 	log.Println("  Ctrl+Alt+Shift+Down    -> Snap bottom-left")
+	log.Println("  Ctrl+Alt+Shift+Arrow   -> Nudge by 10px while floating")
 	log.Println("  Ctrl+Alt+.             -> Toggle overlay")
+	log.Println("  Ctrl+Alt+PageDown      -> Next overlay page")
+	log.Println("  Ctrl+Alt+Home          -> Interact with a click-through overlay")
 
 	return nil
 }
@@ -82,14 +206,127 @@ func (m *Manager) Stop() {
 
 	m.platform.StopHotkeyListener()
 	m.running = false
+	for id, timer := range m.pending {
+		timer.Stop()
+		delete(m.pending, id)
+	}
 	log.Println("Hotkey listener stopped")
 }
 
-// handleHotkey processes hotkey events
+// SetSuspended releases the hotkeys so their key combinations reach the
+// focused app (true), or takes them back (false)
+func (m *Manager) SetSuspended(suspended bool) error {
+	m.mu.Lock()
+	m.suspended = suspended
+	m.mu.Unlock()
+	return m.applySuspended()
+}
+
+// SetAppPaused pauses the hotkeys while an excluded app is focused. They
+// stay released while also suspended from the tray.
+func (m *Manager) SetAppPaused(paused bool) error {
+	m.mu.Lock()
+	m.appPaused = paused
+	m.mu.Unlock()
+	return m.applySuspended()
+}
+
+// applySuspended tells the platform whether hotkeys should be released. The
+// platform is called without holding the manager lock, as hotkey callbacks
+// need it; applyMu keeps concurrent changes in order instead.
+func (m *Manager) applySuspended() error {
+	m.applyMu.Lock()
+	defer m.applyMu.Unlock()
+
+	m.mu.Lock()
+	release := m.suspended || m.appPaused
+	changed := release != m.released
+	m.released = release
+	m.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+	if release {
+		log.Println("Hotkeys released")
+	} else {
+		log.Println("Hotkeys restored")
+	}
+	return m.platform.SetHotkeysSuspended(release)
+}
+
+// IsSuspended returns whether the hotkeys are suspended
+func (m *Manager) IsSuspended() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.suspended
+}
+
+// Excluded reports whether process matches one of the app names, ignoring
+// case and a ".exe" suffix on either side
+func Excluded(process string, apps []string) bool {
+	process = normalizeApp(process)
+	if process == "" {
+		return false
+	}
+	for _, app := range apps {
+		if normalizeApp(app) == process {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeApp(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.TrimSuffix(name, ".exe")
+}
+
+// handleHotkey processes hotkey events. A hotkey with a double-press action
+// runs it on the second press within the interval, or its usual action once
+// the interval passes without one.
 func (m *Manager) handleHotkey(id int) {
+	m.mu.Lock()
+	double := m.doublePress[id]
+	if double == nil {
+		m.mu.Unlock()
+		m.runHotkey(id)
+		return
+	}
+	if timer, ok := m.pending[id]; ok && timer.Stop() {
+		delete(m.pending, id)
+		m.mu.Unlock()
+		log.Printf("Hotkey: double press of %s", Names[id])
+		double()
+		return
+	}
+
+	interval := m.pressInterval
+	if interval <= 0 {
+		interval = DefaultDoublePressInterval
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(interval, func() {
+		m.mu.Lock()
+		if m.pending[id] == timer {
+			delete(m.pending, id)
+		}
+		m.mu.Unlock()
+		m.runHotkey(id)
+	})
+	m.pending[id] = timer
+	m.mu.Unlock()
+}
+
+// runHotkey runs the usual action of a hotkey
+func (m *Manager) runHotkey(id int) {
 	m.mu.Lock()
 	snapCb := m.snapCallback
 	toggleCb := m.toggleCallback
+	pageCb := m.pageCallback
+	nudgeCb := m.nudgeCallback
+	floating := m.floating
+	interactCb := m.interactCallback
 	m.mu.Unlock()
 
 	// Handle toggle overlay
@@ -101,6 +338,32 @@ func (m *Manager) handleHotkey(id int) {
 		return
 	}
 
+	if id == platform.HotkeyNextPage {
+		log.Println("Hotkey: Next page")
+		if pageCb != nil {
+			pageCb()
+		}
+		return
+	}
+
+	if id == platform.HotkeyInteract {
+		log.Println("Hotkey: Interact with overlay")
+		if interactCb != nil {
+			interactCb()
+		}
+		return
+	}
+
+	// Shift+Arrow nudges a floating overlay instead of snapping it
+	if move, ok := nudges[id]; ok && nudgeCb != nil && floating != nil && floating() {
+		log.Printf("Hotkey: Nudge by (%d, %d)", move[0], move[1])
+		nudgeCb(move[0], move[1])
+		return
+	}
+	if id == platform.HotkeyNudgeUp {
+		return // nothing to nudge while snapped
+	}
+
 	// Handle snap hotkeys
 	if snapCb == nil {
 		return
@@ -0,0 +1,114 @@
+package hotkeys
+
+import (
+	"testing"
+	"time"
+
+	"claudebar/internal/platform"
+)
+
+func TestDoublePress(t *testing.T) {
+	const interval = 50 * time.Millisecond
+	m := NewManager()
+	m.SetDoublePressInterval(interval)
+	toggles, doubles, pages := make(chan struct{}, 4), make(chan struct{}, 4), make(chan struct{}, 4)
+	m.SetToggleCallback(func() { toggles <- struct{}{} })
+	m.SetPageCallback(func() { pages <- struct{}{} })
+	m.SetDoublePressAction(platform.HotkeyToggleOverlay, func() { doubles <- struct{}{} })
+
+	// Hotkeys without a double press act at once
+	m.handleHotkey(platform.HotkeyNextPage)
+	select {
+	case <-pages:
+	default:
+		t.Error("next page waited for a double press")
+	}
+
+	// Two presses in quick succession run only the double-press action
+	m.handleHotkey(platform.HotkeyToggleOverlay)
+	m.handleHotkey(platform.HotkeyToggleOverlay)
+	select {
+	case <-doubles:
+	default:
+		t.Error("double press didn't run its action")
+	}
+	time.Sleep(2 * interval)
+	if len(toggles) != 0 {
+		t.Error("double press also ran the single-press action")
+	}
+
+	// A single press runs the usual action once the interval passed
+	m.handleHotkey(platform.HotkeyToggleOverlay)
+	if len(toggles) != 0 {
+		t.Error("single press acted before the interval passed")
+	}
+	select {
+	case <-toggles:
+	case <-time.After(time.Second):
+		t.Fatal("single press never ran its action")
+	}
+	if len(doubles) != 0 {
+		t.Error("single press ran the double-press action")
+	}
+
+	// Without the double press the hotkey acts at once again
+	m.SetDoublePressAction(platform.HotkeyToggleOverlay, nil)
+	m.handleHotkey(platform.HotkeyToggleOverlay)
+	select {
+	case <-toggles:
+	default:
+		t.Error("toggle waited after its double press was removed")
+	}
+}
+
+func TestID(t *testing.T) {
+	for id, name := range Names {
+		if got, ok := ID(name); !ok || got != id {
+			t.Errorf("ID(%q) = %d, %v; want %d", name, got, ok, id)
+		}
+	}
+	if _, ok := ID("snap_middle"); ok {
+		t.Error("ID of an unknown hotkey succeeded")
+	}
+}
+
+func TestNudge(t *testing.T) {
+	m := NewManager()
+	floating := false
+	var snaps []platform.SnapPosition
+	var moves [][2]int
+	m.SetSnapCallback(func(pos platform.SnapPosition) { snaps = append(snaps, pos) })
+	m.SetNudgeCallback(func() bool { return floating }, func(dx, dy int) { moves = append(moves, [2]int{dx, dy}) })
+
+	// Snapped, Shift+Arrow snaps to a corner and Shift+Up does nothing
+	m.handleHotkey(platform.HotkeySnapTopLeft)
+	m.handleHotkey(platform.HotkeyNudgeUp)
+	if len(snaps) != 1 || snaps[0] != platform.SnapTopLeft || len(moves) != 0 {
+		t.Errorf("snapped: snaps %v, moves %v; want one top-left snap", snaps, moves)
+	}
+
+	// Floating, Shift+Arrow nudges; Ctrl+Alt+Arrow still snaps
+	floating = true
+	snaps = nil
+	m.handleHotkey(platform.HotkeySnapTopLeft)
+	m.handleHotkey(platform.HotkeyNudgeUp)
+	m.handleHotkey(platform.HotkeySnapLeft)
+	want := [][2]int{{-NudgeStep, 0}, {0, -NudgeStep}}
+	if len(moves) != 2 || moves[0] != want[0] || moves[1] != want[1] {
+		t.Errorf("floating: moves %v, want %v", moves, want)
+	}
+	if len(snaps) != 1 || snaps[0] != platform.SnapLeft {
+		t.Errorf("floating: snaps %v, want one left snap", snaps)
+	}
+}
+
+func TestInteract(t *testing.T) {
+	m := NewManager()
+	presses, snaps := 0, 0
+	m.SetInteractCallback(func() { presses++ })
+	m.SetSnapCallback(func(platform.SnapPosition) { snaps++ })
+	m.handleHotkey(platform.HotkeyInteract)
+	if presses != 1 || snaps != 0 {
+		t.Errorf("interact hotkey: %d interactions, %d snaps; want 1 and 0", presses, snaps)
+	}
+}
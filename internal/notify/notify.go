@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"errors"
+	"log"
+
+	"fyne.io/fyne/v2"
+
+	"claudebar/internal/alert"
+)
+
+// errUnsupported is returned by send on platforms where Fyne's own
+// notification is as good as it gets
+var errUnsupported = errors.New("no native notification support")
+
+// Send shows a desktop notification whose urgency follows severity, and plays
+// the platform alert sound for warnings and critical alerts when sound is set.
+// Falls back to a plain Fyne notification where the native path isn't
+// available.
+func Send(app fyne.App, title, body string, severity alert.Severity, sound bool) {
+	err := send(title, body, severity, sound)
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, errUnsupported) {
+		log.Printf("Native notification failed, using default: %v", err)
+	}
+	app.SendNotification(fyne.NewNotification(title, body))
+}
@@ -0,0 +1,36 @@
+//go:build darwin
+
+package notify
+
+import (
+	"os/exec"
+
+	"claudebar/internal/alert"
+)
+
+// send uses Notification Center via AppleScript so a sound can be attached.
+// macOS has no per-notification urgency; critical alerts get the more
+// insistent sound instead.
+func send(title, body string, severity alert.Severity, sound bool) error {
+	script := "display notification " + quote(body) + " with title " + quote(title)
+	if sound && severity > alert.None {
+		name := "Funk"
+		if severity == alert.Critical {
+			name = "Sosumi"
+		}
+		script += " sound name " + quote(name)
+	}
+	return exec.Command("osascript", "-e", script).Run()
+}
+
+// quote makes s an AppleScript string literal
+func quote(s string) string {
+	out := []byte{'"'}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' || s[i] == '\\' {
+			out = append(out, '\\')
+		}
+		out = append(out, s[i])
+	}
+	return string(append(out, '"'))
+}
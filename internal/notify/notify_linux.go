@@ -0,0 +1,48 @@
+//go:build linux
+
+package notify
+
+import (
+	"github.com/godbus/dbus/v5"
+
+	"claudebar/internal/alert"
+)
+
+const (
+	notifyDest = "org.freedesktop.Notifications"
+	notifyPath = "/org/freedesktop/Notifications"
+)
+
+// Desktop notification urgency hint values
+const (
+	urgencyNormal   byte = 1
+	urgencyCritical byte = 2
+)
+
+// send posts the notification over the freedesktop Notifications D-Bus API,
+// which (unlike Fyne's) takes an urgency hint and a sound theme name.
+// Critical notifications stay on screen until dismissed on most desktops.
+func send(title, body string, severity alert.Severity, sound bool) error {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return err
+	}
+
+	hints := map[string]dbus.Variant{
+		"urgency": dbus.MakeVariant(urgencyNormal),
+	}
+	if severity == alert.Critical {
+		hints["urgency"] = dbus.MakeVariant(urgencyCritical)
+	}
+	if sound && severity > alert.None {
+		name := "dialog-warning"
+		if severity == alert.Critical {
+			name = "dialog-error"
+		}
+		hints["sound-name"] = dbus.MakeVariant(name)
+	}
+
+	obj := conn.Object(notifyDest, notifyPath)
+	return obj.Call(notifyDest+".Notify", 0,
+		"ClaudeBar", uint32(0), "", title, body, []string{}, hints, int32(-1)).Err
+}
@@ -0,0 +1,33 @@
+//go:build windows
+
+package notify
+
+import (
+	"syscall"
+
+	"claudebar/internal/alert"
+)
+
+var (
+	user32          = syscall.NewLazyDLL("user32.dll")
+	procMessageBeep = user32.NewProc("MessageBeep")
+)
+
+// MessageBeep sound types
+const (
+	mbIconHand    = 0x10 // critical stop
+	mbIconWarning = 0x30 // exclamation
+)
+
+// send only plays the alert sound and leaves the toast to Fyne; Windows
+// toasts have no urgency setting, so the sound is what differs by severity
+func send(title, body string, severity alert.Severity, sound bool) error {
+	if sound && severity > alert.None {
+		beep := uintptr(mbIconWarning)
+		if severity == alert.Critical {
+			beep = mbIconHand
+		}
+		procMessageBeep.Call(beep)
+	}
+	return errUnsupported
+}
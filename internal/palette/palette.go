@@ -0,0 +1,7 @@
+// Package palette holds the built-in colors of the overlay, the usage widgets
+// and the icons, a snapshot of the claude.ai palette. They are defined in
+// palette.json; after editing it, run go generate ./internal/palette to
+// regenerate palette_gen.go, then go run ./cmd/icongen for the icons.
+package palette
+
+//go:generate go run ../../cmd/palettegen
@@ -0,0 +1,36 @@
+// Code generated by palettegen from palette.json; DO NOT EDIT.
+
+package palette
+
+import "image/color"
+
+var (
+	// Background: overlay background, opaque behind the app icon
+	Background = color.RGBA{32, 33, 35, 240}
+	// Track: empty part of a bar and divider lines
+	Track = color.RGBA{55, 57, 61, 255}
+	// Accent: Claude blue, for bars below the warning level
+	Accent = color.RGBA{88, 140, 236, 255}
+	// Warning: bars and tray dots at the warning level
+	Warning = color.RGBA{234, 179, 8, 255}
+	// Critical: bars and tray dots at the critical level
+	Critical = color.RGBA{239, 68, 68, 255}
+	// Low: low usage in the app icon and tray badges
+	Low = color.RGBA{74, 222, 128, 255}
+	// Text: headers and labels
+	Text = color.RGBA{237, 237, 237, 255}
+	// Subtext: reset times and status
+	Subtext = color.RGBA{156, 163, 175, 255}
+	// Percentage: "42% used"
+	Percentage = color.RGBA{180, 186, 194, 255}
+	// TextDark: text over a light desktop showing through the overlay
+	TextDark = color.RGBA{17, 24, 39, 255}
+	// SubtextDark: subtext over a light desktop
+	SubtextDark = color.RGBA{55, 65, 81, 255}
+	// PercentageDark: percentages over a light desktop
+	PercentageDark = color.RGBA{31, 41, 55, 255}
+	// GlyphLight: tray glyph on dark taskbars
+	GlyphLight = color.RGBA{240, 240, 240, 255}
+	// BadgeTrack: empty part of the tray badge
+	BadgeTrack = color.RGBA{64, 65, 70, 255}
+)
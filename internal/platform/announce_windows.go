@@ -0,0 +1,105 @@
+//go:build windows
+
+package platform
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	uiautomationcore              = syscall.NewLazyDLL("uiautomationcore.dll")
+	oleaut32                      = syscall.NewLazyDLL("oleaut32.dll")
+	procUiaHostProviderFromHwnd   = uiautomationcore.NewProc("UiaHostProviderFromHwnd")
+	procUiaRaiseNotificationEvent = uiautomationcore.NewProc("UiaRaiseNotificationEvent")
+	procSysAllocString            = oleaut32.NewProc("SysAllocString")
+	procSysFreeString             = oleaut32.NewProc("SysFreeString")
+)
+
+const (
+	SPI_GETSCREENREADER = 0x0046
+
+	NotificationKind_Other              = 4
+	NotificationProcessing_ImportantAll = 0
+)
+
+// announceActivity groups ClaudeBar's announcements so screen readers can
+// treat them alike
+const announceActivity = "ClaudeBarUsageAlert"
+
+// iUnknown is the start of every COM object: a pointer to its method table
+type iUnknown struct {
+	vtbl *struct {
+		QueryInterface uintptr
+		AddRef         uintptr
+		Release        uintptr
+	}
+}
+
+// ScreenReaderActive reports whether a screen reader (Narrator, NVDA, JAWS)
+// has announced itself through SPI_SETSCREENREADER
+func (w *WindowsFeatures) ScreenReaderActive() bool {
+	var on int32
+	ret, _, _ := procSystemParametersInfo.Call(
+		SPI_GETSCREENREADER,
+		0,
+		uintptr(unsafe.Pointer(&on)),
+		0,
+	)
+	return ret != 0 && on != 0
+}
+
+// Announce raises a UI Automation notification on the window, which screen
+// readers speak without the window having focus
+func (w *WindowsFeatures) Announce(handle WindowHandle, text string) error {
+	if err := procUiaRaiseNotificationEvent.Find(); err != nil {
+		return fmt.Errorf("%w: UI Automation notifications need Windows 10 1709 or later", ErrNotSupported)
+	}
+	if handle == 0 {
+		return fmt.Errorf("%w: no window to announce from", ErrNotSupported)
+	}
+
+	var provider *iUnknown
+	hr, _, _ := procUiaHostProviderFromHwnd.Call(uintptr(handle), uintptr(unsafe.Pointer(&provider)))
+	if hr != 0 || provider == nil {
+		return fmt.Errorf("UiaHostProviderFromHwnd failed: 0x%08x", uint32(hr))
+	}
+	defer syscall.SyscallN(provider.vtbl.Release, uintptr(unsafe.Pointer(provider)))
+
+	display, err := sysAllocString(text)
+	if err != nil {
+		return err
+	}
+	defer procSysFreeString.Call(display)
+	activity, err := sysAllocString(announceActivity)
+	if err != nil {
+		return err
+	}
+	defer procSysFreeString.Call(activity)
+
+	hr, _, _ = procUiaRaiseNotificationEvent.Call(
+		uintptr(unsafe.Pointer(provider)),
+		NotificationKind_Other,
+		NotificationProcessing_ImportantAll,
+		display,
+		activity,
+	)
+	if hr != 0 {
+		return fmt.Errorf("UiaRaiseNotificationEvent failed: 0x%08x", uint32(hr))
+	}
+	return nil
+}
+
+// sysAllocString copies s into a BSTR, to be freed with SysFreeString
+func sysAllocString(s string) (uintptr, error) {
+	p, err := syscall.UTF16PtrFromString(s)
+	if err != nil {
+		return 0, err
+	}
+	bstr, _, _ := procSysAllocString.Call(uintptr(unsafe.Pointer(p)))
+	if bstr == 0 {
+		return 0, fmt.Errorf("SysAllocString failed")
+	}
+	return bstr, nil
+}
@@ -0,0 +1,50 @@
+//go:build darwin && cgo
+
+#include <dispatch/dispatch.h>
+#include <pthread.h>
+
+#include "carbon_darwin.h"
+#include "_cgo_export.h"
+
+// carbonSignature tags ClaudeBar's hotkeys ('CBar')
+static const OSType carbonSignature = 0x43426172;
+
+static OSStatus carbonHotkeyPressed(EventHandlerCallRef next, EventRef event, void *data) {
+	EventHotKeyID hotkey;
+	OSStatus status = GetEventParameter(event, kEventParamDirectObject, typeEventHotKeyID,
+		NULL, sizeof(hotkey), NULL, &hotkey);
+	if (status == noErr && hotkey.signature == carbonSignature) {
+		goCarbonHotkey(hotkey.id);
+	}
+	return status;
+}
+
+static void carbonRun(void *ctx) {
+	carbonCall *call = ctx;
+	switch (call->op) {
+	case carbonInstall: {
+		EventTypeSpec spec = {kEventClassKeyboard, kEventHotKeyPressed};
+		call->status = InstallApplicationEventHandler(NewEventHandlerUPP(carbonHotkeyPressed), 1, &spec, NULL, NULL);
+		break;
+	}
+	case carbonRegister: {
+		EventHotKeyID hotkey = {carbonSignature, call->id};
+		call->status = RegisterEventHotKey(call->keyCode, call->modifiers, hotkey,
+			GetApplicationEventTarget(), 0, &call->ref);
+		break;
+	}
+	case carbonUnregister:
+		call->status = UnregisterEventHotKey(call->ref);
+		break;
+	}
+}
+
+void carbonOnMain(carbonCall *call) {
+	// The main queue is served by the event loop on the main thread, so a
+	// call made there runs directly instead of waiting on itself
+	if (pthread_main_np()) {
+		carbonRun(call);
+	} else {
+		dispatch_sync_f(dispatch_get_main_queue(), call, carbonRun);
+	}
+}
@@ -0,0 +1,136 @@
+//go:build darwin && cgo
+
+package platform
+
+/*
+#cgo LDFLAGS: -framework Carbon
+#include "carbon_darwin.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Global hotkeys through Carbon's RegisterEventHotKey, which unlike an
+// NSEvent monitor needs no accessibility permission. Carbon calls run on the
+// main thread, whose event loop (run by Fyne) delivers the key presses.
+
+// carbonHotkeysAvailable reports whether this build can register hotkeys
+const carbonHotkeysAvailable = true
+
+// carbonKeyCodes maps the virtual key codes of hotkey bindings to macOS key
+// codes (kVK_*)
+var carbonKeyCodes = map[uint]uint32{
+	VK_LEFT:       0x7B, // kVK_LeftArrow
+	VK_RIGHT:      0x7C, // kVK_RightArrow
+	VK_DOWN:       0x7D, // kVK_DownArrow
+	VK_UP:         0x7E, // kVK_UpArrow
+	VK_NEXT:       0x79, // kVK_PageDown
+	VK_HOME:       0x73, // kVK_Home
+	VK_OEM_PERIOD: 0x2F, // kVK_ANSI_Period
+}
+
+// Carbon modifier flags
+const (
+	carbonCmdKey     = 1 << 8
+	carbonShiftKey   = 1 << 9
+	carbonOptionKey  = 1 << 11
+	carbonControlKey = 1 << 12
+)
+
+// carbonModifiers converts hotkey modifiers to Carbon modifier flags; Alt is
+// Option and Win is Command
+func carbonModifiers(mods uint) uint32 {
+	var flags uint32
+	if mods&ModShift != 0 {
+		flags |= carbonShiftKey
+	}
+	if mods&ModCtrl != 0 {
+		flags |= carbonControlKey
+	}
+	if mods&ModAlt != 0 {
+		flags |= carbonOptionKey
+	}
+	if mods&ModWin != 0 {
+		flags |= carbonCmdKey
+	}
+	return flags
+}
+
+// carbonCallback receives hotkey presses. It is read on the main thread,
+// which a caller holding Features.mu may be waiting on, so it is atomic
+// instead of guarded by that lock.
+var carbonCallback atomic.Pointer[func(id int)]
+
+// Handler installation and the registered hotkeys by ID. Guarded by
+// Features.mu.
+var (
+	carbonInstalled bool
+	carbonRefs      = map[int]C.EventHotKeyRef{}
+)
+
+//export goCarbonHotkey
+func goCarbonHotkey(id C.UInt32) {
+	// Off the main thread, so a callback waiting on the UI can't stall it
+	if callback := carbonCallback.Load(); callback != nil {
+		go (*callback)(int(id))
+	}
+}
+
+// installCarbonHandler installs the hotkey event handler, once, and sets the
+// callback for hotkey presses
+func installCarbonHandler(callback func(id int)) error {
+	carbonCallback.Store(&callback)
+	if carbonInstalled {
+		return nil
+	}
+	call := C.carbonCall{op: C.int(C.carbonInstall)}
+	C.carbonOnMain(&call)
+	if call.status != 0 {
+		return fmt.Errorf("InstallEventHandler failed: OSStatus %d", call.status)
+	}
+	carbonInstalled = true
+	return nil
+}
+
+// registerCarbonHotkey registers hk for id, replacing any earlier
+// registration. Must be called with Features.mu held.
+func registerCarbonHotkey(id int, hk hotkeyBinding) error {
+	keyCode, ok := carbonKeyCodes[hk.key]
+	if !ok {
+		return fmt.Errorf("%w: key code %#x has no macOS key code", ErrNotSupported, hk.key)
+	}
+	if err := unregisterCarbonHotkey(id); err != nil {
+		return err
+	}
+	call := C.carbonCall{
+		op:        C.int(C.carbonRegister),
+		id:        C.UInt32(id),
+		keyCode:   C.UInt32(keyCode),
+		modifiers: C.UInt32(carbonModifiers(hk.mods)),
+	}
+	C.carbonOnMain(&call)
+	if call.status != 0 {
+		return fmt.Errorf("RegisterEventHotKey failed for id %d (combination already taken?): OSStatus %d", id, call.status)
+	}
+	carbonRefs[id] = call.ref
+	return nil
+}
+
+// unregisterCarbonHotkey removes the registration of id, if any. Must be
+// called with Features.mu held.
+func unregisterCarbonHotkey(id int) error {
+	ref, ok := carbonRefs[id]
+	if !ok {
+		return nil
+	}
+	delete(carbonRefs, id)
+	call := C.carbonCall{op: C.int(C.carbonUnregister), ref: ref}
+	C.carbonOnMain(&call)
+	if call.status != 0 {
+		return fmt.Errorf("UnregisterEventHotKey failed for id %d: OSStatus %d", id, call.status)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+// Carbon hotkey calls for carbon_darwin.go, run on the main thread
+
+#ifndef CLAUDEBAR_CARBON_DARWIN_H
+#define CLAUDEBAR_CARBON_DARWIN_H
+
+#include <Carbon/Carbon.h>
+
+enum { carbonInstall, carbonRegister, carbonUnregister };
+
+// carbonCall is one Carbon request and its result
+typedef struct {
+	int op;
+	UInt32 id;
+	UInt32 keyCode;
+	UInt32 modifiers;
+	EventHotKeyRef ref;
+	OSStatus status;
+} carbonCall;
+
+// carbonOnMain runs call on the main thread and waits for it
+void carbonOnMain(carbonCall *call);
+
+#endif
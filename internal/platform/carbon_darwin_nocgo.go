@@ -0,0 +1,21 @@
+//go:build darwin && !cgo
+
+package platform
+
+import "fmt"
+
+// Builds without cgo can't call Carbon and have no global hotkeys
+
+const carbonHotkeysAvailable = false
+
+func installCarbonHandler(callback func(id int)) error {
+	return fmt.Errorf("%w: global hotkeys need a cgo build on macOS", ErrNotSupported)
+}
+
+func registerCarbonHotkey(id int, hk hotkeyBinding) error {
+	return fmt.Errorf("%w: global hotkeys need a cgo build on macOS", ErrNotSupported)
+}
+
+func unregisterCarbonHotkey(id int) error {
+	return nil
+}
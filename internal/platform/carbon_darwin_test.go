@@ -0,0 +1,16 @@
+//go:build darwin && cgo
+
+package platform
+
+import "testing"
+
+func TestCarbonKeyCodesCoverDefaultHotkeys(t *testing.T) {
+	for _, hk := range defaultHotkeys {
+		if _, ok := carbonKeyCodes[hk.key]; !ok {
+			t.Errorf("%s: key code %#x has no macOS key code", hk.name, hk.key)
+		}
+	}
+	if got := carbonModifiers(ModCtrl | ModAlt | ModShift); got != carbonControlKey|carbonOptionKey|carbonShiftKey {
+		t.Errorf("carbonModifiers(Ctrl+Alt+Shift) = %#x", got)
+	}
+}
@@ -3,8 +3,12 @@
 package platform
 
 import (
+	"errors"
 	"fmt"
+	"image"
+	"image/png"
 	"log"
+	"os"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -13,18 +17,35 @@ import (
 
 // DarwinFeatures implements PlatformFeatures for macOS
 type DarwinFeatures struct {
-	mu            sync.Mutex
-	hotkeyRunning bool
-	stopHotkey    chan struct{}
+	mu                sync.Mutex
+	hotkeyRunning     bool
+	stopHotkey        chan struct{}
+	hotkeys           map[int]hotkeyBinding // wanted bindings, registered unless suspended
+	hotkeysSuspended  bool
+	hotkeyFailure     hotkeyFailure
+	displayRunning    bool
+	stopDisplay       chan struct{}
+	themeRunning      bool
+	stopTheme         chan struct{}
+	foregroundRunning bool
+	stopForeground    chan struct{}
 }
 
 // NewDarwinFeatures creates a new macOS platform features instance
 func NewDarwinFeatures() *DarwinFeatures {
 	return &DarwinFeatures{
 		stopHotkey: make(chan struct{}),
+		hotkeys:    make(map[int]hotkeyBinding),
 	}
 }
 
+// Capabilities reports idle detection and, in cgo builds, hotkeys: opacity
+// and click-through need Cocoa calls that aren't implemented yet
+func (d *DarwinFeatures) Capabilities() Capabilities {
+	_, err := exec.LookPath("ioreg")
+	return Capabilities{IdleDetection: err == nil, Hotkeys: carbonHotkeysAvailable}
+}
+
 // SetAlwaysOnTop uses AppleScript to set window level
 func (d *DarwinFeatures) SetAlwaysOnTop(handle WindowHandle, onTop bool) error {
 	// macOS doesn't have a simple CLI for this; Fyne windows can use NSWindow level
@@ -49,6 +70,11 @@ func (d *DarwinFeatures) SetTransparency(handle WindowHandle, opacity float64) e
 	return nil
 }
 
+// SetPerPixelAlpha is not supported: NSWindow.opaque can only be changed through CGO
+func (d *DarwinFeatures) SetPerPixelAlpha(handle WindowHandle, enabled bool) error {
+	return ErrNotSupported
+}
+
 // SetClickThrough is not easily supported without CGO
 func (d *DarwinFeatures) SetClickThrough(handle WindowHandle, clickThrough bool) error {
 	log.Println("SetClickThrough: not supported on macOS without CGO")
@@ -138,6 +164,13 @@ func (d *DarwinFeatures) GetWorkArea() (x, y, width, height int) {
 	return 0, 25, w, h - 25
 }
 
+// GetMonitorWorkAreas returns the usable area of each display.
+// NSScreen enumeration needs CGO, so only the main display is reported.
+func (d *DarwinFeatures) GetMonitorWorkAreas() []Rect {
+	x, y, w, h := d.GetWorkArea()
+	return []Rect{{x, y, w, h}}
+}
+
 // GetIdleSeconds returns seconds since last user input
 func (d *DarwinFeatures) GetIdleSeconds() int {
 	out, err := exec.Command("ioreg", "-c", "IOHIDSystem", "-d", "4").Output()
@@ -160,33 +193,98 @@ func (d *DarwinFeatures) GetIdleSeconds() int {
 	return 0
 }
 
-// RegisterHotkey - global hotkeys on macOS require Carbon API or CGO
+// RegisterHotkey binds a global hotkey, replacing any earlier binding for
+// id. Safe to call at any time: before the listener starts the binding is
+// kept and registered on start, and while suspended on resume.
 func (d *DarwinFeatures) RegisterHotkey(id int, modifiers uint, keyCode uint) error {
-	log.Printf("RegisterHotkey: global hotkeys require CGO on macOS (id=%d)", id)
-	return nil
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	hk := hotkeyBinding{mods: modifiers, key: keyCode, name: fmt.Sprintf("id %d", id)}
+	d.hotkeys[id] = hk
+	if !d.hotkeyRunning || d.hotkeysSuspended {
+		return nil
+	}
+	return registerCarbonHotkey(id, hk)
 }
 
-// UnregisterHotkey removes a registered hotkey
+// UnregisterHotkey removes a hotkey binding
 func (d *DarwinFeatures) UnregisterHotkey(id int) error {
-	return nil
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.hotkeys, id)
+	return unregisterCarbonHotkey(id)
 }
 
-// SetupHotkeyListener sets up hotkey listening (stub on macOS)
+// SetupHotkeyListener registers the default hotkeys through Carbon, which
+// needs a cgo build. Ctrl is Control and Alt is Option.
 func (d *DarwinFeatures) SetupHotkeyListener(callback func(id int)) error {
 	d.mu.Lock()
+	defer d.mu.Unlock()
 	if d.hotkeyRunning {
-		d.mu.Unlock()
 		return nil
 	}
+	if err := installCarbonHandler(callback); err != nil {
+		d.hotkeyFailure.record(err)
+		return err
+	}
 	d.hotkeyRunning = true
 	d.stopHotkey = make(chan struct{})
-	d.mu.Unlock()
 
-	log.Println("Global hotkeys not yet implemented on macOS (requires Carbon API)")
+	for _, hk := range defaultHotkeys {
+		d.hotkeys[hk.id] = hk.hotkeyBinding
+	}
+	if d.hotkeysSuspended {
+		return nil
+	}
+	for id, hk := range d.hotkeys {
+		if err := registerCarbonHotkey(id, hk); err != nil {
+			log.Printf("Failed to register %s: %v", hk.name, err)
+			d.hotkeyFailure.record(err)
+		} else {
+			log.Printf("Registered hotkey: %s", hk.name)
+		}
+	}
 	return nil
 }
 
-// StopHotkeyListener stops the hotkey listener
+// SetHotkeysSuspended releases all hotkeys (true) so the key combinations
+// reach the focused app, or registers them again (false). Bindings are kept
+// while suspended.
+func (d *DarwinFeatures) SetHotkeysSuspended(suspended bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if suspended == d.hotkeysSuspended {
+		return nil
+	}
+	d.hotkeysSuspended = suspended
+	if !d.hotkeyRunning {
+		return nil
+	}
+
+	var errs []error
+	for id, hk := range d.hotkeys {
+		if suspended {
+			errs = append(errs, unregisterCarbonHotkey(id))
+		} else {
+			errs = append(errs, registerCarbonHotkey(id, hk))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HotkeyHealth reports whether the Carbon hotkeys are registered
+func (d *DarwinFeatures) HotkeyHealth() HotkeyHealth {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return HotkeyHealth{
+		Running:     d.hotkeyRunning,
+		Suspended:   d.hotkeysSuspended,
+		LastError:   d.hotkeyFailure.err,
+		LastErrorAt: d.hotkeyFailure.at,
+	}
+}
+
+// StopHotkeyListener releases the hotkeys and stops the hotkey listener
 func (d *DarwinFeatures) StopHotkeyListener() {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -195,15 +293,233 @@ func (d *DarwinFeatures) StopHotkeyListener() {
 	}
 	close(d.stopHotkey)
 	d.hotkeyRunning = false
+	for id := range d.hotkeys {
+		if err := unregisterCarbonHotkey(id); err != nil {
+			log.Printf("Failed to release hotkey: %v", err)
+		}
+	}
+}
+
+// SetupDisplayListener watches for display changes.
+// CGDisplayRegisterReconfigurationCallback needs CGO, so this polls the work area.
+func (d *DarwinFeatures) SetupDisplayListener(callback func()) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.displayRunning {
+		return nil
+	}
+	d.displayRunning = true
+	d.stopDisplay = make(chan struct{})
+
+	go pollChanges(displayPollInterval, func() string {
+		x, y, w, h := d.GetWorkArea()
+		return fmt.Sprintf("%d,%d,%d,%d", x, y, w, h)
+	}, callback, d.stopDisplay)
+	return nil
+}
+
+// StopDisplayListener stops watching for display changes
+func (d *DarwinFeatures) StopDisplayListener() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.displayRunning {
+		return
+	}
+	close(d.stopDisplay)
+	d.displayRunning = false
+}
+
+// SystemUsesLightTheme reports whether the menu bar uses the light appearance.
+// AppleInterfaceStyle is only set (to "Dark") in dark mode.
+func (d *DarwinFeatures) SystemUsesLightTheme() bool {
+	out, err := exec.Command("defaults", "read", "-g", "AppleInterfaceStyle").Output()
+	if err != nil {
+		return true
+	}
+	return !strings.EqualFold(strings.TrimSpace(string(out)), "dark")
+}
+
+// SetupThemeListener polls the appearance and calls callback when it changes.
+// Distributed notifications (AppleInterfaceThemeChangedNotification) need CGO.
+func (d *DarwinFeatures) SetupThemeListener(callback func()) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.themeRunning {
+		return nil
+	}
+	d.themeRunning = true
+	d.stopTheme = make(chan struct{})
+
+	go pollChanges(themePollInterval, func() string {
+		return strconv.FormatBool(d.SystemUsesLightTheme())
+	}, callback, d.stopTheme)
+	return nil
+}
+
+// StopThemeListener stops watching the appearance
+func (d *DarwinFeatures) StopThemeListener() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.themeRunning {
+		return
+	}
+	close(d.stopTheme)
+	d.themeRunning = false
+}
+
+// CaptureScreen copies a region of the screen with screencapture. Needs the
+// Screen Recording permission; without it only the wallpaper is captured,
+// which is still what matters for picking a text color.
+func (d *DarwinFeatures) CaptureScreen(r Rect) (image.Image, error) {
+	f, err := os.CreateTemp("", "claudebar-capture-*.png")
+	if err != nil {
+		return nil, err
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	region := fmt.Sprintf("%d,%d,%d,%d", r.X, r.Y, r.Width, r.Height)
+	if err := exec.Command("screencapture", "-x", "-t", "png", "-R", region, path).Run(); err != nil {
+		return nil, fmt.Errorf("screencapture failed: %w", err)
+	}
+
+	f, err = os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// ForegroundProcess returns the name of the frontmost application's process,
+// e.g. "idea"
+func (d *DarwinFeatures) ForegroundProcess() string {
+	out, err := exec.Command("osascript", "-e",
+		`tell application "System Events" to get name of first application process whose frontmost is true`).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// SetupForegroundListener polls the frontmost application and calls
+// callback when it changes. NSWorkspace notifications need CGO.
+func (d *DarwinFeatures) SetupForegroundListener(callback func()) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.foregroundRunning {
+		return nil
+	}
+	d.foregroundRunning = true
+	d.stopForeground = make(chan struct{})
+
+	go pollChanges(foregroundPollInterval, d.ForegroundProcess, callback, d.stopForeground)
+	return nil
+}
+
+// StopForegroundListener stops watching the frontmost application
+func (d *DarwinFeatures) StopForegroundListener() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.foregroundRunning {
+		return
+	}
+	close(d.stopForeground)
+	d.foregroundRunning = false
+}
+
+// PrefersReducedMotion reports whether "Reduce motion" is on in the
+// Accessibility display settings
+func (d *DarwinFeatures) PrefersReducedMotion() bool {
+	out, err := exec.Command("defaults", "read", "com.apple.universalaccess", "reduceMotion").Output()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(out)) == "1"
+}
+
+// ScreenReaderActive reports whether VoiceOver is on
+func (d *DarwinFeatures) ScreenReaderActive() bool {
+	out, err := exec.Command("defaults", "read", "com.apple.universalaccess", "voiceOverOnOffKey").Output()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(out)) == "1"
+}
+
+// FlashTaskbar isn't available: bouncing the Dock icon needs
+// NSApplication, which is out of reach without CGO
+func (d *DarwinFeatures) FlashTaskbar(handle WindowHandle) error {
+	return fmt.Errorf("%w: Dock attention without CGO", ErrNotSupported)
+}
+
+// TopmostWindows is not supported: listing other apps' windows needs
+// CGWindowListCopyWindowInfo and the Screen Recording permission
+func (d *DarwinFeatures) TopmostWindows() ([]Rect, error) {
+	return nil, ErrNotSupported
+}
+
+// Announce has VoiceOver speak text. Needs "Allow VoiceOver to be controlled
+// with AppleScript" in VoiceOver Utility; the text is passed as an argument
+// so it needs no quoting.
+func (d *DarwinFeatures) Announce(handle WindowHandle, text string) error {
+	cmd := exec.Command("osascript",
+		"-e", "on run argv",
+		"-e", `tell application "VoiceOver" to output (item 1 of argv)`,
+		"-e", "end run",
+		text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("VoiceOver announcement failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// RemoteSession is always false: Screen Sharing shows the local console, so
+// window transparency works as usual
+func (d *DarwinFeatures) RemoteSession() bool {
+	return false
+}
+
+// OnBatterySaver reports whether the Mac runs on battery or has Low Power
+// Mode turned on
+func (d *DarwinFeatures) OnBatterySaver() bool {
+	if out, err := exec.Command("pmset", "-g", "batt").Output(); err == nil &&
+		strings.Contains(string(out), "'Battery Power'") {
+		return true
+	}
+	out, err := exec.Command("pmset", "-g").Output()
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "lowpowermode" {
+			return fields[1] == "1"
+		}
+	}
+	return false
 }
 
 // GetWindowHandle finds a window by title (stub on macOS)
 func GetWindowHandle(title string) (WindowHandle, error) {
 	// On macOS, we'd need CGWindowListCopyWindowInfo via CGO
 	// For now return 0 (window features will degrade gracefully)
-	log.Printf("GetWindowHandle: not implemented on macOS (title=%s)", title)
-	return 0, fmt.Errorf("GetWindowHandle not implemented on macOS")
+	return 0, fmt.Errorf("%w: GetWindowHandle needs CGO on macOS", ErrNotSupported)
+}
+
+// IsWindowValid reports whether handle still identifies an existing window.
+// No handles are ever acquired on macOS, so none is valid.
+func IsWindowValid(handle WindowHandle) bool {
+	return false
 }
 
 // Global instance
 var Features = NewDarwinFeatures()
+
+// VirtualMachine reports whether macOS runs under a hypervisor, such as
+// Parallels or Apple's Virtualization framework
+func (d *DarwinFeatures) VirtualMachine() bool {
+	out, err := exec.Command("sysctl", "-n", "kern.hv_vmm_present").Output()
+	return err == nil && strings.TrimSpace(string(out)) == "1"
+}
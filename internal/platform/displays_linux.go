@@ -0,0 +1,384 @@
+//go:build linux
+
+package platform
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Display change notifications without CGO: RandR events over a minimal X11
+// connection, or Mutter's MonitorsChanged signal on GNOME Wayland, where
+// RandR only sees XWayland's view of the outputs.
+
+// X11 protocol numbers used here
+const (
+	x11OpQueryExtension = 98
+	x11GenericEvent     = 35
+	randrOpQueryVersion = 0
+	randrOpSelectInput  = 4
+
+	randrScreenChangeMask = 1 << 0
+	randrCrtcChangeMask   = 1 << 1
+	randrOutputChangeMask = 1 << 2
+
+	x11SetupTimeout = 5 * time.Second
+)
+
+// Mutter's display configuration service
+const (
+	mutterDisplayConfig     = "org.gnome.Mutter.DisplayConfig"
+	mutterDisplayConfigPath = "/org/gnome/Mutter/DisplayConfig"
+)
+
+var x11Order = binary.LittleEndian // byte order announced in the connection setup
+
+// watchRandR calls callback for every RandR screen, CRTC or output change on
+// the X display until stop is closed. Returns an error when the display
+// can't be watched or the connection fails.
+func watchRandR(callback func(), stop <-chan struct{}) error {
+	c, root, err := dialX11(os.Getenv("DISPLAY"))
+	if err != nil {
+		return err
+	}
+
+	c.SetDeadline(time.Now().Add(x11SetupTimeout))
+	opcode, firstEvent, err := queryExtension(c, "RANDR")
+	if err == nil {
+		// RandR 1.2 is needed for CRTC and output events
+		_, err = roundTrip(c, randrRequest(opcode, randrOpQueryVersion, 1, 2))
+	}
+	if err == nil {
+		req := randrRequest(opcode, randrOpSelectInput, root, 0)
+		x11Order.PutUint16(req[8:], randrScreenChangeMask|randrCrtcChangeMask|randrOutputChangeMask)
+		_, err = c.Write(req)
+	}
+	if err != nil {
+		c.Close()
+		return err
+	}
+	c.SetDeadline(time.Time{})
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+		case <-done:
+		}
+		c.Close()
+	}()
+
+	err = readRandREvents(c, firstEvent, callback)
+	select {
+	case <-stop:
+		return nil
+	default:
+		return err
+	}
+}
+
+// readRandREvents reads events until the connection fails, calling callback
+// for RRScreenChangeNotify and RRNotify
+func readRandREvents(c net.Conn, firstEvent byte, callback func()) error {
+	buf := make([]byte, 32)
+	for {
+		if _, err := io.ReadFull(c, buf); err != nil {
+			return err
+		}
+		switch code := buf[0] & 0x7f; {
+		case code == 0:
+			return fmt.Errorf("X11 error %d", buf[1])
+		case code == 1 || code == x11GenericEvent:
+			// Replies and generic events carry extra data
+			if _, err := io.CopyN(io.Discard, c, int64(x11Order.Uint32(buf[4:]))*4); err != nil {
+				return err
+			}
+		case code == firstEvent || code == firstEvent+1:
+			callback()
+		}
+	}
+}
+
+// dialX11 connects to a local X display such as ":0" and returns the
+// connection with the root window of the display's screen
+func dialX11(display string) (net.Conn, uint32, error) {
+	c, setup, screen, err := connectX11(display)
+	if err != nil {
+		return nil, 0, err
+	}
+	root, err := rootWindow(setup, screen)
+	if err != nil {
+		c.Close()
+		return nil, 0, err
+	}
+	return c, root, nil
+}
+
+// connectX11 connects to a local X display and returns the connection with
+// the server's setup data and the screen number from the display name
+func connectX11(display string) (net.Conn, []byte, int, error) {
+	num, screen, err := parseDisplay(display)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	c, err := net.DialTimeout("unix", fmt.Sprintf("/tmp/.X11-unix/X%d", num), x11SetupTimeout)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	c.SetDeadline(time.Now().Add(x11SetupTimeout))
+
+	authName, authData := xauthCookie(num)
+	req := make([]byte, 12)
+	req[0] = 'l'
+	x11Order.PutUint16(req[2:], 11) // protocol 11.0
+	x11Order.PutUint16(req[6:], uint16(len(authName)))
+	x11Order.PutUint16(req[8:], uint16(len(authData)))
+	req = append(req, pad4(authName)...)
+	req = append(req, pad4(authData)...)
+	if _, err := c.Write(req); err != nil {
+		c.Close()
+		return nil, nil, 0, err
+	}
+
+	head := make([]byte, 8)
+	if _, err := io.ReadFull(c, head); err != nil {
+		c.Close()
+		return nil, nil, 0, err
+	}
+	setup := make([]byte, int(x11Order.Uint16(head[6:]))*4)
+	if _, err := io.ReadFull(c, setup); err != nil {
+		c.Close()
+		return nil, nil, 0, err
+	}
+	if head[0] != 1 {
+		c.Close()
+		reason := strings.TrimRight(string(setup[:min(int(head[1]), len(setup))]), "\x00")
+		return nil, nil, 0, fmt.Errorf("X11 connection refused: %s", reason)
+	}
+	c.SetDeadline(time.Time{})
+	return c, setup, screen, nil
+}
+
+// parseDisplay splits a local DISPLAY value like ":0" or "unix:1.0" into the
+// display and screen numbers. Remote displays aren't supported.
+func parseDisplay(display string) (num, screen int, err error) {
+	host, rest, ok := strings.Cut(display, ":")
+	if !ok || (host != "" && host != "unix") {
+		return 0, 0, fmt.Errorf("%w: DISPLAY %q is not a local X display", ErrNotSupported, display)
+	}
+	numText, screenText, hasScreen := strings.Cut(rest, ".")
+	if num, err = strconv.Atoi(numText); err != nil {
+		return 0, 0, fmt.Errorf("invalid DISPLAY %q", display)
+	}
+	if hasScreen {
+		if screen, err = strconv.Atoi(screenText); err != nil {
+			return 0, 0, fmt.Errorf("invalid DISPLAY %q", display)
+		}
+	}
+	return num, screen, nil
+}
+
+// rootWindow finds the root window of screen in the connection setup data
+func rootWindow(setup []byte, screen int) (uint32, error) {
+	errShort := errors.New("short X11 connection setup")
+	if len(setup) < 32 {
+		return 0, errShort
+	}
+	vendorLen := int(x11Order.Uint16(setup[16:]))
+	numScreens := int(setup[20])
+	numFormats := int(setup[21])
+
+	off := 32 + len(pad4(make([]byte, vendorLen))) + 8*numFormats
+	for i := 0; i < numScreens; i++ {
+		if off+40 > len(setup) {
+			return 0, errShort
+		}
+		if i == screen {
+			return x11Order.Uint32(setup[off:]), nil
+		}
+		numDepths := int(setup[off+39])
+		off += 40
+		for d := 0; d < numDepths; d++ {
+			if off+8 > len(setup) {
+				return 0, errShort
+			}
+			off += 8 + 24*int(x11Order.Uint16(setup[off+2:]))
+		}
+	}
+	return 0, fmt.Errorf("X11 screen %d not found", screen)
+}
+
+// queryExtension returns the major opcode and first event code of an X
+// extension
+func queryExtension(c net.Conn, name string) (opcode, firstEvent byte, err error) {
+	data := pad4([]byte(name))
+	req := make([]byte, 8, 8+len(data))
+	req[0] = x11OpQueryExtension
+	x11Order.PutUint16(req[2:], uint16((8+len(data))/4))
+	x11Order.PutUint16(req[4:], uint16(len(name)))
+	reply, err := roundTrip(c, append(req, data...))
+	if err != nil {
+		return 0, 0, err
+	}
+	if reply[8] == 0 {
+		return 0, 0, fmt.Errorf("%w: X server has no %s extension", ErrNotSupported, name)
+	}
+	return reply[9], reply[10], nil
+}
+
+// randrRequest builds a three-word RandR request with two 32-bit arguments
+func randrRequest(opcode, minor byte, arg1, arg2 uint32) []byte {
+	req := make([]byte, 12)
+	req[0] = opcode
+	req[1] = minor
+	x11Order.PutUint16(req[2:], 3)
+	x11Order.PutUint32(req[4:], arg1)
+	x11Order.PutUint32(req[8:], arg2)
+	return req
+}
+
+// roundTrip sends a request and returns the first 32 bytes of its reply
+func roundTrip(c net.Conn, req []byte) ([]byte, error) {
+	if _, err := c.Write(req); err != nil {
+		return nil, err
+	}
+	reply := make([]byte, 32)
+	for {
+		if _, err := io.ReadFull(c, reply); err != nil {
+			return nil, err
+		}
+		switch reply[0] {
+		case 0:
+			return nil, fmt.Errorf("X11 error %d", reply[1])
+		case 1:
+			if _, err := io.CopyN(io.Discard, c, int64(x11Order.Uint32(reply[4:]))*4); err != nil {
+				return nil, err
+			}
+			return reply, nil
+		}
+		// Nothing is selected yet, but skip stray events all the same
+	}
+}
+
+// xauthCookie finds the MIT-MAGIC-COOKIE-1 for a local display in the
+// Xauthority file. Without one the server may still accept the connection
+// (e.g. with xhost +si:localuser).
+func xauthCookie(display int) (name, data []byte) {
+	const (
+		familyLocal = 256
+		familyWild  = 65535
+	)
+
+	path := os.Getenv("XAUTHORITY")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil
+		}
+		path = filepath.Join(home, ".Xauthority")
+	}
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil
+	}
+	hostname, _ := os.Hostname()
+	number := strconv.Itoa(display)
+
+	r := bytes.NewReader(file)
+	field := func() []byte {
+		var n uint16
+		if binary.Read(r, binary.BigEndian, &n) != nil {
+			return nil
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil
+		}
+		return b
+	}
+	for {
+		var family uint16
+		if binary.Read(r, binary.BigEndian, &family) != nil {
+			return nil, nil
+		}
+		addr, num, authName, authData := field(), field(), field(), field()
+		if authName == nil {
+			return nil, nil
+		}
+		if family != familyLocal && family != familyWild {
+			continue
+		}
+		if family == familyLocal && string(addr) != hostname {
+			continue
+		}
+		if len(num) > 0 && string(num) != number {
+			continue
+		}
+		if string(authName) == "MIT-MAGIC-COOKIE-1" {
+			return authName, authData
+		}
+	}
+}
+
+// pad4 pads b with zeros to a multiple of four bytes
+func pad4(b []byte) []byte {
+	return append(b, make([]byte, (4-len(b)%4)%4)...)
+}
+
+// watchMutterMonitors calls callback whenever GNOME's compositor reports a
+// monitor change, until stop is closed
+func watchMutterMonitors(callback func(), stop <-chan struct{}) error {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return err
+	}
+	var owned bool
+	err = conn.Object("org.freedesktop.DBus", "/org/freedesktop/DBus").
+		Call("org.freedesktop.DBus.NameHasOwner", 0, mutterDisplayConfig).Store(&owned)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return fmt.Errorf("%w: %s is not running", ErrNotSupported, mutterDisplayConfig)
+	}
+
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(mutterDisplayConfigPath),
+		dbus.WithMatchInterface(mutterDisplayConfig),
+		dbus.WithMatchMember("MonitorsChanged"),
+	}
+	if err := conn.AddMatchSignal(match...); err != nil {
+		return err
+	}
+	defer conn.RemoveMatchSignal(match...)
+
+	signals := make(chan *dbus.Signal, 8)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	for {
+		select {
+		case sig, ok := <-signals:
+			if !ok {
+				return errors.New("session bus connection closed")
+			}
+			if sig.Name == mutterDisplayConfig+".MonitorsChanged" {
+				callback()
+			}
+		case <-stop:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,88 @@
+//go:build linux
+
+package platform
+
+import (
+	"net"
+	"testing"
+)
+
+func TestParseDisplay(t *testing.T) {
+	tests := []struct {
+		display     string
+		num, screen int
+		ok          bool
+	}{
+		{":0", 0, 0, true},
+		{":1.2", 1, 2, true},
+		{"unix:3", 3, 0, true},
+		{"localhost:10.0", 0, 0, false},
+		{"", 0, 0, false},
+		{":x", 0, 0, false},
+	}
+	for _, tt := range tests {
+		num, screen, err := parseDisplay(tt.display)
+		if (err == nil) != tt.ok || num != tt.num || screen != tt.screen {
+			t.Errorf("parseDisplay(%q) = %d, %d, %v", tt.display, num, screen, err)
+		}
+	}
+}
+
+func TestRootWindow(t *testing.T) {
+	// Setup data with a 3-byte vendor, one pixmap format and two screens,
+	// the first with one depth holding one visual
+	setup := make([]byte, 32)
+	x11Order.PutUint16(setup[16:], 3)
+	setup[20], setup[21] = 2, 1
+	setup = append(setup, "abc\x00"...)
+	setup = append(setup, make([]byte, 8)...)
+
+	screen0 := make([]byte, 40)
+	x11Order.PutUint32(screen0, 0x100)
+	screen0[39] = 1
+	depth := make([]byte, 8)
+	x11Order.PutUint16(depth[2:], 1)
+	screen1 := make([]byte, 40)
+	x11Order.PutUint32(screen1, 0x200)
+	setup = append(setup, screen0...)
+	setup = append(setup, depth...)
+	setup = append(setup, make([]byte, 24)...)
+	setup = append(setup, screen1...)
+
+	for screen, want := range []uint32{0x100, 0x200} {
+		if got, err := rootWindow(setup, screen); err != nil || got != want {
+			t.Errorf("rootWindow(screen %d) = %#x, %v, want %#x", screen, got, err, want)
+		}
+	}
+	if _, err := rootWindow(setup, 2); err == nil {
+		t.Error("rootWindow found a screen that doesn't exist")
+	}
+	if _, err := rootWindow(setup[:50], 1); err == nil {
+		t.Error("rootWindow accepted truncated setup data")
+	}
+}
+
+func TestReadRandREvents(t *testing.T) {
+	const firstEvent = 89
+	server, client := net.Pipe()
+	go func() {
+		event := func(code byte) []byte {
+			b := make([]byte, 32)
+			b[0] = code
+			return b
+		}
+		server.Write(event(firstEvent))        // RRScreenChangeNotify
+		server.Write(event(firstEvent + 1))    // RRNotify
+		server.Write(event(22))                // ConfigureNotify, ignored
+		server.Write(event(firstEvent | 0x80)) // sent by SendEvent
+		server.Close()
+	}()
+
+	changes := 0
+	if err := readRandREvents(client, firstEvent, func() { changes++ }); err == nil {
+		t.Error("readRandREvents returned no error for a closed connection")
+	}
+	if changes != 3 {
+		t.Errorf("changes = %d, want 3", changes)
+	}
+}
@@ -0,0 +1,392 @@
+//go:build linux
+
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// Global hotkeys without CGO or the GlobalShortcuts portal: passive key
+// grabs on the root window over a minimal X11 connection. Grabs made through
+// XWayland only fire while an X window has focus, so they are used on X11
+// sessions only.
+
+// X11 protocol numbers used for key grabs
+const (
+	x11OpGrabKey            = 33
+	x11OpUngrabKey          = 34
+	x11OpGetInputFocus      = 43
+	x11OpGetKeyboardMapping = 101
+	x11KeyPress             = 2
+	x11ErrBadAccess         = 10
+	x11GrabModeAsync        = 1
+)
+
+// X11 modifier masks
+const (
+	x11ShiftMask   = 1 << 0
+	x11LockMask    = 1 << 1 // Caps Lock
+	x11ControlMask = 1 << 2
+	x11Mod1Mask    = 1 << 3 // Alt
+	x11Mod2Mask    = 1 << 4 // Num Lock on common layouts
+	x11Mod4Mask    = 1 << 6 // Super
+
+	x11HotkeyMasks = x11ShiftMask | x11ControlMask | x11Mod1Mask | x11Mod4Mask
+)
+
+// x11LockStates are the lock key states each combination is grabbed with:
+// the server matches modifiers exactly, so a grab without them wouldn't
+// fire while Caps Lock or Num Lock is on
+var x11LockStates = []uint16{0, x11LockMask, x11Mod2Mask, x11LockMask | x11Mod2Mask}
+
+// x11Keysyms maps the virtual key codes of hotkey bindings to X keysyms
+var x11Keysyms = map[uint]uint32{
+	VK_LEFT:       0xff51, // Left
+	VK_UP:         0xff52, // Up
+	VK_RIGHT:      0xff53, // Right
+	VK_DOWN:       0xff54, // Down
+	VK_NEXT:       0xff56, // Page_Down
+	VK_HOME:       0xff50, // Home
+	VK_OEM_PERIOD: 0x002e, // period
+}
+
+// grabReplyTimeout bounds how long a grab waits for the X server to confirm it
+const grabReplyTimeout = time.Second
+
+// errGrabTaken is returned when another client already grabbed a combination
+var errGrabTaken = errors.New("combination already taken")
+
+// x11HotkeysAvailable reports whether hotkeys can be grabbed from the X
+// server: an X11 session rather than XWayland under a Wayland compositor
+func x11HotkeysAvailable() bool {
+	return os.Getenv("DISPLAY") != "" && os.Getenv("WAYLAND_DISPLAY") == ""
+}
+
+// x11Modifiers converts hotkey modifiers to an X modifier mask
+func x11Modifiers(mods uint) uint16 {
+	var mask uint16
+	if mods&ModShift != 0 {
+		mask |= x11ShiftMask
+	}
+	if mods&ModCtrl != 0 {
+		mask |= x11ControlMask
+	}
+	if mods&ModAlt != 0 {
+		mask |= x11Mod1Mask
+	}
+	if mods&ModWin != 0 {
+		mask |= x11Mod4Mask
+	}
+	return mask
+}
+
+// x11Grab is a key combination as the X server sees it
+type x11Grab struct {
+	keycode byte
+	mods    uint16
+}
+
+// grabRequests builds the GrabKey (x11OpGrabKey) or UngrabKey
+// (x11OpUngrabKey) requests for combo in every lock state
+func grabRequests(op byte, root uint32, combo x11Grab) []byte {
+	var reqs []byte
+	for _, lock := range x11LockStates {
+		var req []byte
+		if op == x11OpGrabKey {
+			req = make([]byte, 16)
+			x11Order.PutUint16(req[2:], 4)
+			req[10] = combo.keycode
+			req[11] = x11GrabModeAsync // pointer
+			req[12] = x11GrabModeAsync // keyboard
+		} else {
+			req = make([]byte, 12)
+			req[1] = combo.keycode
+			x11Order.PutUint16(req[2:], 3)
+		}
+		req[0] = op
+		x11Order.PutUint32(req[4:], root)
+		x11Order.PutUint16(req[8:], combo.mods|lock)
+		reqs = append(reqs, req...)
+	}
+	return reqs
+}
+
+// keysymKeycodes maps each keysym in a GetKeyboardMapping reply to the first
+// keycode producing it, preferring unshifted keysyms
+func keysymKeycodes(keysyms []byte, first byte, perKeycode int) map[uint32]byte {
+	keycodes := make(map[uint32]byte)
+	if perKeycode <= 0 {
+		return keycodes
+	}
+	count := len(keysyms) / 4 / perKeycode
+	for col := 0; col < perKeycode; col++ {
+		for i := 0; i < count; i++ {
+			sym := x11Order.Uint32(keysyms[(i*perKeycode+col)*4:])
+			if _, ok := keycodes[sym]; sym != 0 && !ok {
+				keycodes[sym] = first + byte(i)
+			}
+		}
+	}
+	return keycodes
+}
+
+// keyboardMapping returns the keycode of every keysym on the keyboard
+func keyboardMapping(c net.Conn, setup []byte) (map[uint32]byte, error) {
+	if len(setup) < 28 {
+		return nil, errors.New("short X11 connection setup")
+	}
+	first, last := setup[26], setup[27]
+	req := make([]byte, 8)
+	req[0] = x11OpGetKeyboardMapping
+	x11Order.PutUint16(req[2:], 2)
+	req[4] = first
+	req[5] = last - first + 1
+	if _, err := c.Write(req); err != nil {
+		return nil, err
+	}
+
+	reply := make([]byte, 32)
+	for {
+		if _, err := io.ReadFull(c, reply); err != nil {
+			return nil, err
+		}
+		switch reply[0] {
+		case 0:
+			return nil, fmt.Errorf("X11 error %d", reply[1])
+		case 1:
+			keysyms := make([]byte, int(x11Order.Uint32(reply[4:]))*4)
+			if _, err := io.ReadFull(c, keysyms); err != nil {
+				return nil, err
+			}
+			return keysymKeycodes(keysyms, first, int(reply[1])), nil
+		}
+	}
+}
+
+// keyGrabber holds hotkeys as passive key grabs on the root window of an X
+// display and calls callback with the hotkey ID when one is pressed
+type keyGrabber struct {
+	conn     net.Conn
+	root     uint32
+	keycodes map[uint32]byte // keysym → keycode
+	callback func(id int)
+
+	reqMu   sync.Mutex    // one batch of requests awaits confirmation at a time
+	replies chan error    // result of each batch, sent by run
+	done    chan struct{} // closed when run returns
+
+	mu    sync.Mutex
+	grabs map[x11Grab]int // grabbed combinations → hotkey ID
+}
+
+// newKeyGrabber connects to display and reads its keyboard mapping. Call
+// run to start receiving key presses.
+func newKeyGrabber(display string, callback func(id int)) (*keyGrabber, error) {
+	c, setup, screen, err := connectX11(display)
+	if err != nil {
+		return nil, err
+	}
+	root, err := rootWindow(setup, screen)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	c.SetDeadline(time.Now().Add(x11SetupTimeout))
+	keycodes, err := keyboardMapping(c, setup)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	c.SetDeadline(time.Time{})
+	return newGrabberConn(c, root, keycodes, callback), nil
+}
+
+// newGrabberConn creates a grabber on an established connection
+func newGrabberConn(c net.Conn, root uint32, keycodes map[uint32]byte, callback func(id int)) *keyGrabber {
+	return &keyGrabber{
+		conn:     c,
+		root:     root,
+		keycodes: keycodes,
+		callback: callback,
+		replies:  make(chan error, 1),
+		done:     make(chan struct{}),
+		grabs:    make(map[x11Grab]int),
+	}
+}
+
+// run reads events until the connection fails or is closed, calling the
+// callback for grabbed key presses and reporting the result of each request
+// batch to send
+func (g *keyGrabber) run() error {
+	defer close(g.done)
+	buf := make([]byte, 32)
+	var failed error
+	for {
+		if _, err := io.ReadFull(g.conn, buf); err != nil {
+			return err
+		}
+		switch buf[0] & 0x7f {
+		case 0:
+			if buf[1] == x11ErrBadAccess && buf[10] == x11OpGrabKey {
+				failed = errGrabTaken
+			} else if failed == nil {
+				failed = fmt.Errorf("X11 error %d", buf[1])
+			}
+		case 1:
+			if _, err := io.CopyN(io.Discard, g.conn, int64(x11Order.Uint32(buf[4:]))*4); err != nil {
+				return err
+			}
+			g.replies <- failed
+			failed = nil
+		case x11KeyPress:
+			g.mu.Lock()
+			id, ok := g.grabs[x11Grab{keycode: buf[1], mods: x11Order.Uint16(buf[28:]) & x11HotkeyMasks}]
+			g.mu.Unlock()
+			if ok && g.callback != nil {
+				g.callback(id)
+			}
+		}
+	}
+}
+
+// send writes a batch of requests followed by a GetInputFocus, whose reply
+// shows the server processed them, and returns the first error they caused
+func (g *keyGrabber) send(reqs []byte) error {
+	g.reqMu.Lock()
+	defer g.reqMu.Unlock()
+
+	// Drop the result of a batch that timed out
+	select {
+	case <-g.replies:
+	default:
+	}
+
+	focus := make([]byte, 4)
+	focus[0] = x11OpGetInputFocus
+	x11Order.PutUint16(focus[2:], 1)
+	if _, err := g.conn.Write(append(reqs, focus...)); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(grabReplyTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-g.replies:
+		return err
+	case <-g.done:
+		return errors.New("X11 connection closed")
+	case <-timer.C:
+		return errors.New("X server did not respond")
+	}
+}
+
+// grab grabs the combination of hk for the hotkey id
+func (g *keyGrabber) grab(id int, hk hotkeyBinding) error {
+	keysym, ok := x11Keysyms[hk.key]
+	if !ok {
+		return fmt.Errorf("%w: key code %#x has no X keysym", ErrNotSupported, hk.key)
+	}
+	keycode, ok := g.keycodes[keysym]
+	if !ok {
+		return fmt.Errorf("no key on the keyboard produces keysym %#x", keysym)
+	}
+	combo := x11Grab{keycode: keycode, mods: x11Modifiers(hk.mods)}
+
+	// Mapped first so a press right after the grab isn't missed
+	g.mu.Lock()
+	g.grabs[combo] = id
+	g.mu.Unlock()
+	if err := g.send(grabRequests(x11OpGrabKey, g.root, combo)); err != nil {
+		g.mu.Lock()
+		delete(g.grabs, combo)
+		g.mu.Unlock()
+		// Release the lock states that were grabbed before the failure
+		g.send(grabRequests(x11OpUngrabKey, g.root, combo))
+		return fmt.Errorf("GrabKey failed for id %d: %w", id, err)
+	}
+	return nil
+}
+
+// ungrab releases the combinations grabbed for the hotkey id
+func (g *keyGrabber) ungrab(id int) error {
+	g.mu.Lock()
+	var reqs []byte
+	for combo, grabbed := range g.grabs {
+		if grabbed == id {
+			reqs = append(reqs, grabRequests(x11OpUngrabKey, g.root, combo)...)
+			delete(g.grabs, combo)
+		}
+	}
+	g.mu.Unlock()
+	if reqs == nil {
+		return nil
+	}
+	if err := g.send(reqs); err != nil {
+		return fmt.Errorf("UngrabKey failed for id %d: %w", id, err)
+	}
+	return nil
+}
+
+// close drops the connection, which releases all grabs
+func (g *keyGrabber) close() {
+	g.conn.Close()
+}
+
+// grabHotkeys connects to the X display and grabs the default hotkeys. The
+// key combinations stay grabbed until StopHotkeyListener.
+func (l *LinuxFeatures) grabHotkeys(callback func(id int), stop <-chan struct{}) error {
+	g, err := newKeyGrabber(os.Getenv("DISPLAY"), callback)
+	if err != nil {
+		err = fmt.Errorf("X11 hotkeys: %w", err)
+		l.mu.Lock()
+		l.hotkeyRunning = false
+		l.hotkeyCallback = nil
+		l.hotkeyFailure.record(err)
+		l.mu.Unlock()
+		return err
+	}
+	go func() {
+		err := g.run()
+		select {
+		case <-stop:
+		default:
+			log.Printf("X11 hotkeys stopped: %v", err)
+			l.mu.Lock()
+			if l.grabber == g {
+				l.grabber = nil
+			}
+			l.hotkeyFailure.record(fmt.Errorf("X11 connection lost: %w", err))
+			l.mu.Unlock()
+		}
+	}()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.hotkeyRunning {
+		g.close()
+		return nil
+	}
+	l.grabber = g
+	for _, hk := range defaultHotkeys {
+		l.hotkeys[hk.id] = hk.hotkeyBinding
+	}
+	if l.hotkeysSuspended {
+		return nil
+	}
+	for id, hk := range l.hotkeys {
+		if err := g.grab(id, hk); err != nil {
+			log.Printf("Failed to grab %s: %v", hk.name, err)
+			l.hotkeyFailure.record(err)
+		} else {
+			log.Printf("Grabbed hotkey: %s", hk.name)
+		}
+	}
+	return nil
+}
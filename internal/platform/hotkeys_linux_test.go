@@ -0,0 +1,97 @@
+//go:build linux
+
+package platform
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestX11KeysymsCoverDefaultHotkeys(t *testing.T) {
+	for _, hk := range defaultHotkeys {
+		if _, ok := x11Keysyms[hk.key]; !ok {
+			t.Errorf("%s: key code %#x has no X keysym", hk.name, hk.key)
+		}
+	}
+	if got := x11Modifiers(ModCtrl | ModAlt | ModShift); got != x11ControlMask|x11Mod1Mask|x11ShiftMask {
+		t.Errorf("x11Modifiers(Ctrl+Alt+Shift) = %#x", got)
+	}
+}
+
+func TestKeysymKeycodes(t *testing.T) {
+	// Two keysyms per keycode from keycode 8: period/colon, Left, and a
+	// second key with colon unshifted
+	keysyms := make([]byte, 3*2*4)
+	x11Order.PutUint32(keysyms[0:], 0x2e)
+	x11Order.PutUint32(keysyms[4:], 0x3a)
+	x11Order.PutUint32(keysyms[8:], 0xff51)
+	x11Order.PutUint32(keysyms[16:], 0x3a)
+
+	keycodes := keysymKeycodes(keysyms, 8, 2)
+	want := map[uint32]byte{0x2e: 8, 0xff51: 9, 0x3a: 10}
+	if len(keycodes) != len(want) {
+		t.Errorf("keysymKeycodes = %v, want %v", keycodes, want)
+	}
+	for sym, code := range want {
+		if keycodes[sym] != code {
+			t.Errorf("keycode of %#x = %d, want %d", sym, keycodes[sym], code)
+		}
+	}
+}
+
+func TestKeyGrabber(t *testing.T) {
+	server, client := net.Pipe()
+	const keycode = 113
+	g := newGrabberConn(client, 0x1ab, map[uint32]byte{0xff51: keycode}, nil)
+	pressed := make(chan int, 1)
+	g.callback = func(id int) { pressed <- id }
+	go g.run()
+
+	// The server refuses the first grab, accepts the second and then sends
+	// a key press with Num Lock on
+	go func() {
+		packet := func(code byte) []byte {
+			b := make([]byte, 32)
+			b[0] = code
+			return b
+		}
+		for attempt := 0; attempt < 2; attempt++ {
+			if _, err := io.ReadFull(server, make([]byte, 4*16+4)); err != nil {
+				return
+			}
+			if attempt == 0 {
+				e := packet(0)
+				e[1], e[10] = x11ErrBadAccess, x11OpGrabKey
+				server.Write(e)
+			}
+			server.Write(packet(1)) // GetInputFocus reply
+			if attempt == 0 {
+				// Ungrab of the partly grabbed combination
+				io.ReadFull(server, make([]byte, 4*12+4))
+				server.Write(packet(1))
+			}
+		}
+		press := packet(x11KeyPress)
+		press[1] = keycode
+		x11Order.PutUint16(press[28:], x11ControlMask|x11Mod1Mask|x11Mod2Mask)
+		server.Write(press)
+	}()
+
+	hk := hotkeyBinding{mods: ModCtrl | ModAlt, key: VK_LEFT, name: "snap left"}
+	if err := g.grab(HotkeySnapLeft, hk); !errors.Is(err, errGrabTaken) {
+		t.Fatalf("first grab: err = %v, want errGrabTaken", err)
+	}
+	if err := g.grab(HotkeySnapLeft, hk); err != nil {
+		t.Fatalf("second grab: %v", err)
+	}
+	if id := <-pressed; id != HotkeySnapLeft {
+		t.Errorf("pressed id = %d, want %d", id, HotkeySnapLeft)
+	}
+	if err := g.grab(HotkeyNextPage, hotkeyBinding{mods: ModCtrl | ModAlt, key: VK_NEXT}); err == nil {
+		t.Error("grab of a key missing from the keyboard succeeded")
+	}
+	g.close()
+	server.Close()
+}
@@ -3,29 +3,70 @@
 package platform
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"image"
+	"image/png"
 	"log"
+	"os"
 	"os/exec"
-	"runtime"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+
+	"claudebar/internal/portal"
+	"claudebar/internal/sandbox"
 )
 
 // LinuxFeatures implements PlatformFeatures for Linux using xdotool/wmctrl
 type LinuxFeatures struct {
-	mu             sync.Mutex
-	hotkeyRunning  bool
-	stopHotkey     chan struct{}
+	mu                sync.Mutex
+	hotkeyRunning     bool
+	stopHotkey        chan struct{}
+	shortcuts         *portal.GlobalShortcuts
+	grabber           *keyGrabber           // X11 key grabs, without the portal
+	hotkeys           map[int]hotkeyBinding // wanted grabs, grabbed unless suspended
+	hotkeyCallback    func(id int)
+	hotkeysSuspended  bool
+	hotkeyFailure     hotkeyFailure
+	displayRunning    bool
+	stopDisplay       chan struct{}
+	themeRunning      bool
+	stopTheme         chan struct{}
+	foregroundRunning bool
+	stopForeground    chan struct{}
 }
 
 // NewLinuxFeatures creates a new Linux platform features instance
 func NewLinuxFeatures() *LinuxFeatures {
 	return &LinuxFeatures{
 		stopHotkey: make(chan struct{}),
+		hotkeys:    make(map[int]hotkeyBinding),
+	}
+}
+
+// Capabilities probes for the X11 tools and portals the features rely on.
+// Opacity needs xdotool to find the window and xprop to set it, idle time
+// comes from xprintidle and hotkeys from the GlobalShortcuts portal or, on
+// X11 sessions, key grabs.
+func (l *LinuxFeatures) Capabilities() Capabilities {
+	x11 := os.Getenv("DISPLAY") != ""
+	return Capabilities{
+		Transparency:  x11 && !sandbox.Flatpak() && hasCommand("xdotool") && hasCommand("xprop"),
+		Hotkeys:       portal.GlobalShortcutsAvailable() || x11HotkeysAvailable(),
+		IdleDetection: x11 && hasCommand("xprintidle"),
+		WindowList:    x11 && !sandbox.Flatpak() && hasCommand("wmctrl") && hasCommand("xprop"),
 	}
 }
 
+// hasCommand reports whether name is an executable on PATH
+func hasCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
 // SetAlwaysOnTop sets the window to always be on top using wmctrl
 func (l *LinuxFeatures) SetAlwaysOnTop(handle WindowHandle, onTop bool) error {
 	// Use wmctrl to toggle always-on-top by window title
@@ -65,6 +106,12 @@ func (l *LinuxFeatures) SetTransparency(handle WindowHandle, opacity float64) er
 	return nil
 }
 
+// SetPerPixelAlpha is not supported: X11 windows only get an alpha channel when created with an ARGB visual,
+// which GLFW doesn't pick for Fyne windows
+func (l *LinuxFeatures) SetPerPixelAlpha(handle WindowHandle, enabled bool) error {
+	return ErrNotSupported
+}
+
 // SetClickThrough is not easily supported on Linux without compositor-specific APIs
 func (l *LinuxFeatures) SetClickThrough(handle WindowHandle, clickThrough bool) error {
 	log.Println("SetClickThrough: not supported on Linux")
@@ -173,6 +220,41 @@ func (l *LinuxFeatures) GetWorkArea() (x, y, width, height int) {
 	return 0, 0, w, h
 }
 
+// GetMonitorWorkAreas returns the geometry of every connected monitor via xrandr.
+// Panels are only known for the whole desktop, so the primary work area is
+// used for the monitor that contains it.
+func (l *LinuxFeatures) GetMonitorWorkAreas() []Rect {
+	wx, wy, ww, wh := l.GetWorkArea()
+	work := Rect{wx, wy, ww, wh}
+
+	out, err := exec.Command("xrandr", "--listmonitors").Output()
+	if err != nil {
+		return []Rect{work}
+	}
+
+	// Format: " 0: +*DP-1 2560/597x1440/336+0+0  DP-1"
+	var rects []Rect
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || !strings.HasSuffix(fields[0], ":") {
+			continue
+		}
+		var w, h, x, y, mmW, mmH int
+		if _, err := fmt.Sscanf(fields[2], "%d/%dx%d/%d+%d+%d", &w, &mmW, &h, &mmH, &x, &y); err != nil {
+			continue
+		}
+		r := Rect{x, y, w, h}
+		if r.Contains(wx, wy) && ww <= w && wh <= h {
+			r = work
+		}
+		rects = append(rects, r)
+	}
+	if len(rects) == 0 {
+		return []Rect{work}
+	}
+	return rects
+}
+
 // GetIdleSeconds returns seconds since last user input via xprintidle
 func (l *LinuxFeatures) GetIdleSeconds() int {
 	out, err := exec.Command("xprintidle").Output()
@@ -186,20 +268,60 @@ func (l *LinuxFeatures) GetIdleSeconds() int {
 	return ms / 1000
 }
 
-// RegisterHotkey registers a global hotkey (stub - requires X11 keygrab)
+// RegisterHotkey binds a global hotkey, replacing any earlier binding for
+// id. Only X11 key grabs use it; portal shortcuts are fixed when bound.
+// Before the listener starts the binding is kept and grabbed on start, and
+// while suspended on resume.
 func (l *LinuxFeatures) RegisterHotkey(id int, modifiers uint, keyCode uint) error {
-	log.Printf("RegisterHotkey: global hotkeys require xdotool or custom X11 keygrab (id=%d)", id)
-	return nil
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	hk := hotkeyBinding{mods: modifiers, key: keyCode, name: fmt.Sprintf("id %d", id)}
+	l.hotkeys[id] = hk
+	if l.grabber == nil || l.hotkeysSuspended {
+		return nil
+	}
+	if err := l.grabber.ungrab(id); err != nil {
+		return err
+	}
+	return l.grabber.grab(id, hk)
 }
 
-// UnregisterHotkey removes a registered hotkey
+// UnregisterHotkey removes a hotkey binding
 func (l *LinuxFeatures) UnregisterHotkey(id int) error {
-	return nil
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.hotkeys[id]; !ok {
+		return nil
+	}
+	delete(l.hotkeys, id)
+	if l.grabber == nil {
+		return nil
+	}
+	return l.grabber.ungrab(id)
 }
 
-// SetupHotkeyListener sets up hotkey listening
-// On Linux, global hotkeys require either xbindkeys or X11 XGrabKey.
-// For now, this is a basic implementation that doesn't support global hotkeys.
+// portalShortcuts maps hotkey IDs to GlobalShortcuts portal bindings
+var portalShortcuts = []struct {
+	id       int
+	shortcut portal.Shortcut
+}{
+	{HotkeySnapLeft, portal.Shortcut{ID: "snap-left", Description: "Snap overlay left", Trigger: "CTRL+ALT+Left"}},
+	{HotkeySnapRight, portal.Shortcut{ID: "snap-right", Description: "Snap overlay right", Trigger: "CTRL+ALT+Right"}},
+	{HotkeySnapTop, portal.Shortcut{ID: "snap-top", Description: "Snap overlay top", Trigger: "CTRL+ALT+Up"}},
+	{HotkeySnapBottomRight, portal.Shortcut{ID: "snap-bottom-right", Description: "Snap overlay bottom-right", Trigger: "CTRL+ALT+Down"}},
+	{HotkeySnapTopLeft, portal.Shortcut{ID: "snap-top-left", Description: "Snap overlay top-left", Trigger: "CTRL+ALT+SHIFT+Left"}},
+	{HotkeySnapTopRight, portal.Shortcut{ID: "snap-top-right", Description: "Snap overlay top-right", Trigger: "CTRL+ALT+SHIFT+Right"}},
+	{HotkeySnapBottomLeft, portal.Shortcut{ID: "snap-bottom-left", Description: "Snap overlay bottom-left", Trigger: "CTRL+ALT+SHIFT+Down"}},
+	{HotkeyNudgeUp, portal.Shortcut{ID: "nudge-up", Description: "Nudge the floating overlay up", Trigger: "CTRL+ALT+SHIFT+Up"}},
+	{HotkeyToggleOverlay, portal.Shortcut{ID: "toggle-overlay", Description: "Show/hide overlay", Trigger: "CTRL+ALT+period"}},
+	{HotkeyNextPage, portal.Shortcut{ID: "next-page", Description: "Show the next overlay page", Trigger: "CTRL+ALT+Page_Down"}},
+	{HotkeyInteract, portal.Shortcut{ID: "interact", Description: "Let the click-through overlay take clicks for a while", Trigger: "CTRL+ALT+Home"}},
+}
+
+// SetupHotkeyListener sets up hotkey listening.
+// Global hotkeys go through the GlobalShortcuts desktop portal, which works
+// on Wayland and inside Flatpak. Without it, X11 sessions grab the same key
+// combinations as Windows from the X server.
 func (l *LinuxFeatures) SetupHotkeyListener(callback func(id int)) error {
 	l.mu.Lock()
 	if l.hotkeyRunning {
@@ -207,14 +329,109 @@ func (l *LinuxFeatures) SetupHotkeyListener(callback func(id int)) error {
 		return nil
 	}
 	l.hotkeyRunning = true
+	l.hotkeyCallback = callback
 	l.stopHotkey = make(chan struct{})
+	suspended := l.hotkeysSuspended
 	l.mu.Unlock()
 
-	log.Println("Global hotkeys not yet implemented on Linux (use xbindkeys for manual setup)")
-	_ = runtime.Version() // avoid unused import
+	if portal.GlobalShortcutsAvailable() {
+		if !suspended {
+			go l.bindShortcuts()
+		}
+		return nil
+	}
+	if x11HotkeysAvailable() {
+		return l.grabHotkeys(callback, l.stopHotkey)
+	}
+	log.Println("Global hotkeys need the GlobalShortcuts portal on Wayland (use your desktop's shortcut settings for manual setup)")
 	return nil
 }
 
+// bindShortcuts binds the portal shortcuts. Binding may wait on a
+// confirmation dialog, so it runs in the background.
+func (l *LinuxFeatures) bindShortcuts() {
+	l.mu.Lock()
+	callback := l.hotkeyCallback
+	l.mu.Unlock()
+
+	ids := make(map[string]int, len(portalShortcuts))
+	shortcuts := make([]portal.Shortcut, 0, len(portalShortcuts))
+	for _, ps := range portalShortcuts {
+		ids[ps.shortcut.ID] = ps.id
+		shortcuts = append(shortcuts, ps.shortcut)
+	}
+
+	bound, err := portal.BindShortcuts(shortcuts, func(name string) {
+		if id, ok := ids[name]; ok && callback != nil {
+			callback(id)
+		}
+	})
+	if err != nil {
+		log.Printf("Global shortcuts portal: %v", err)
+		l.mu.Lock()
+		l.hotkeyFailure.record(err)
+		l.mu.Unlock()
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.hotkeyRunning || l.hotkeysSuspended || l.shortcuts != nil {
+		bound.Close()
+		return
+	}
+	l.shortcuts = bound
+	log.Println("Global hotkeys bound via desktop portal")
+}
+
+// SetHotkeysSuspended closes the portal session or releases the key grabs
+// (true) so the key combinations reach the focused app, or binds them again
+// (false). Grab bindings are kept while suspended.
+func (l *LinuxFeatures) SetHotkeysSuspended(suspended bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if suspended == l.hotkeysSuspended {
+		return nil
+	}
+	l.hotkeysSuspended = suspended
+
+	var errs []error
+	if suspended {
+		if l.shortcuts != nil {
+			l.shortcuts.Close()
+			l.shortcuts = nil
+		}
+		if l.grabber != nil {
+			for id := range l.hotkeys {
+				errs = append(errs, l.grabber.ungrab(id))
+			}
+		}
+		return errors.Join(errs...)
+	}
+	if l.hotkeyRunning && portal.GlobalShortcutsAvailable() {
+		go l.bindShortcuts()
+	}
+	if l.grabber != nil {
+		for id, hk := range l.hotkeys {
+			errs = append(errs, l.grabber.grab(id, hk))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HotkeyHealth reports whether the portal shortcuts are bound or the X11
+// keys grabbed
+func (l *LinuxFeatures) HotkeyHealth() HotkeyHealth {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return HotkeyHealth{
+		Running:     l.hotkeyRunning && (l.shortcuts != nil || l.grabber != nil),
+		Suspended:   l.hotkeysSuspended,
+		LastError:   l.hotkeyFailure.err,
+		LastErrorAt: l.hotkeyFailure.at,
+	}
+}
+
 // StopHotkeyListener stops the hotkey listener
 func (l *LinuxFeatures) StopHotkeyListener() {
 	l.mu.Lock()
@@ -224,10 +441,80 @@ func (l *LinuxFeatures) StopHotkeyListener() {
 	}
 	close(l.stopHotkey)
 	l.hotkeyRunning = false
+	if l.shortcuts != nil {
+		l.shortcuts.Close()
+		l.shortcuts = nil
+	}
+	if l.grabber != nil {
+		l.grabber.close()
+		l.grabber = nil
+	}
+}
+
+// SetupDisplayListener watches for monitor/resolution changes
+func (l *LinuxFeatures) SetupDisplayListener(callback func()) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.displayRunning {
+		return nil
+	}
+	l.displayRunning = true
+	l.stopDisplay = make(chan struct{})
+
+	go l.watchDisplays(callback, l.stopDisplay)
+	return nil
+}
+
+// watchDisplays listens for Mutter's MonitorsChanged signal on Wayland or
+// RandR events on X11, and falls back to polling xrandr when neither can be
+// watched
+func (l *LinuxFeatures) watchDisplays(callback func(), stop chan struct{}) {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		err := watchMutterMonitors(callback, stop)
+		if err == nil {
+			return
+		}
+		log.Printf("Display listener: Mutter unavailable: %v", err)
+	}
+	if os.Getenv("DISPLAY") != "" {
+		err := watchRandR(callback, stop)
+		if err == nil {
+			return
+		}
+		log.Printf("Display listener: RandR unavailable: %v", err)
+	}
+
+	log.Println("Display listener: polling xrandr for monitor changes")
+	pollChanges(displayPollInterval, l.displaySignature, callback, stop)
+}
+
+// StopDisplayListener stops watching for display changes
+func (l *LinuxFeatures) StopDisplayListener() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.displayRunning {
+		return
+	}
+	close(l.stopDisplay)
+	l.displayRunning = false
+}
+
+// displaySignature describes the current monitor layout
+func (l *LinuxFeatures) displaySignature() string {
+	if out, err := exec.Command("xrandr", "--listmonitors").Output(); err == nil {
+		return string(out)
+	}
+	x, y, w, h := l.GetWorkArea()
+	return fmt.Sprintf("%d,%d,%d,%d", x, y, w, h)
 }
 
 // GetWindowHandle finds a window by title using xdotool
 func GetWindowHandle(title string) (WindowHandle, error) {
+	// No portal places windows and the sandbox ships no xdotool, so window
+	// features are left to the compositor
+	if sandbox.Flatpak() {
+		return 0, fmt.Errorf("%w: window placement inside Flatpak", ErrNotSupported)
+	}
 	out, err := exec.Command("xdotool", "search", "--name", title).Output()
 	if err != nil {
 		return 0, fmt.Errorf("xdotool search failed: %w", err)
@@ -243,5 +530,208 @@ func GetWindowHandle(title string) (WindowHandle, error) {
 	return WindowHandle(id), nil
 }
 
+// IsWindowValid reports whether handle still identifies an existing X window
+func IsWindowValid(handle WindowHandle) bool {
+	if handle == 0 {
+		return false
+	}
+	return exec.Command("xdotool", "getwindowname", fmt.Sprintf("%d", handle)).Run() == nil
+}
+
 // Global instance
 var Features = NewLinuxFeatures()
+
+// SystemUsesLightTheme reports whether the desktop prefers a light color
+// scheme, via the Settings portal with a gsettings fallback. Panels are
+// usually dark when there is no preference, so that counts as dark.
+func (l *LinuxFeatures) SystemUsesLightTheme() bool {
+	if scheme, err := portal.ColorScheme(); err == nil {
+		return scheme == portal.ColorSchemeLight
+	}
+
+	out, err := exec.Command("gsettings", "get", "org.gnome.desktop.interface", "color-scheme").Output()
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(out), "prefer-light")
+}
+
+// SetupThemeListener polls the color scheme and calls callback when it changes
+func (l *LinuxFeatures) SetupThemeListener(callback func()) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.themeRunning {
+		return nil
+	}
+	l.themeRunning = true
+	l.stopTheme = make(chan struct{})
+
+	go pollChanges(themePollInterval, func() string {
+		return strconv.FormatBool(l.SystemUsesLightTheme())
+	}, callback, l.stopTheme)
+	return nil
+}
+
+// StopThemeListener stops watching the color scheme
+func (l *LinuxFeatures) StopThemeListener() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.themeRunning {
+		return
+	}
+	close(l.stopTheme)
+	l.themeRunning = false
+}
+
+// CaptureScreen copies a region of the X11 root window with ImageMagick's
+// import. Wayland compositors only allow captures through the (interactive)
+// screenshot portal, so there it fails.
+func (l *LinuxFeatures) CaptureScreen(r Rect) (image.Image, error) {
+	if _, err := exec.LookPath("import"); err != nil {
+		return nil, ErrNotSupported
+	}
+	out, err := exec.Command("import", "-silent", "-window", "root",
+		"-crop", fmt.Sprintf("%dx%d+%d+%d", r.Width, r.Height, r.X, r.Y), "png:-").Output()
+	if err != nil {
+		return nil, fmt.Errorf("import failed: %w", err)
+	}
+	return png.Decode(bytes.NewReader(out))
+}
+
+// ForegroundProcess returns the process name of the active X11 window, e.g.
+// "idea", or "" if it can't be determined (Wayland doesn't expose it)
+func (l *LinuxFeatures) ForegroundProcess() string {
+	out, err := exec.Command("xdotool", "getactivewindow", "getwindowpid").Output()
+	if err != nil {
+		return ""
+	}
+	comm, err := os.ReadFile(filepath.Join("/proc", strings.TrimSpace(string(out)), "comm"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(comm))
+}
+
+// SetupForegroundListener polls the active window's process and calls
+// callback when it changes
+func (l *LinuxFeatures) SetupForegroundListener(callback func()) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.foregroundRunning {
+		return nil
+	}
+	l.foregroundRunning = true
+	l.stopForeground = make(chan struct{})
+
+	go pollChanges(foregroundPollInterval, l.ForegroundProcess, callback, l.stopForeground)
+	return nil
+}
+
+// StopForegroundListener stops watching the active window
+func (l *LinuxFeatures) StopForegroundListener() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.foregroundRunning {
+		return
+	}
+	close(l.stopForeground)
+	l.foregroundRunning = false
+}
+
+// PrefersReducedMotion reports whether desktop animations are turned off
+func (l *LinuxFeatures) PrefersReducedMotion() bool {
+	out, err := exec.Command("gsettings", "get", "org.gnome.desktop.interface", "enable-animations").Output()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(out)) == "false"
+}
+
+// ScreenReaderActive reports whether the desktop's screen reader setting is
+// on or Orca is running
+func (l *LinuxFeatures) ScreenReaderActive() bool {
+	out, err := exec.Command("gsettings", "get", "org.gnome.desktop.a11y.applications", "screen-reader-enabled").Output()
+	if err == nil && strings.TrimSpace(string(out)) == "true" {
+		return true
+	}
+	return exec.Command("pgrep", "-x", "orca").Run() == nil
+}
+
+// Announce isn't available: AT-SPI has no way to speak text without an
+// accessible object tree, so callers fall back to a notification, which Orca
+// reads out
+func (l *LinuxFeatures) Announce(handle WindowHandle, text string) error {
+	return fmt.Errorf("%w: screen reader announcements on Linux", ErrNotSupported)
+}
+
+// FlashTaskbar sets the window's urgency hint, which taskbars and docks show
+// until the window is focused
+func (l *LinuxFeatures) FlashTaskbar(handle WindowHandle) error {
+	if handle == 0 {
+		return fmt.Errorf("%w: no window to flash", ErrNotSupported)
+	}
+	if err := exec.Command("xdotool", "set_window", "--urgency", "1", fmt.Sprintf("%d", handle)).Run(); err != nil {
+		return fmt.Errorf("xdotool set_window failed: %w", err)
+	}
+	return nil
+}
+
+// OnBatterySaver reports whether the machine runs on battery, or
+// power-profiles-daemon is in its power-saver profile
+func (l *LinuxFeatures) OnBatterySaver() bool {
+	if onBattery() {
+		return true
+	}
+	out, err := exec.Command("powerprofilesctl", "get").Output()
+	return err == nil && strings.TrimSpace(string(out)) == "power-saver"
+}
+
+// RemoteSession reports whether the desktop runs under xrdp or is forwarded
+// over SSH, neither of which composites translucent windows reliably
+func (l *LinuxFeatures) RemoteSession() bool {
+	if os.Getenv("XRDP_SESSION") != "" {
+		return true
+	}
+	// A forwarded display names the host, e.g. "localhost:10.0"
+	display := os.Getenv("DISPLAY")
+	return os.Getenv("SSH_CONNECTION") != "" && display != "" && !strings.HasPrefix(display, ":")
+}
+
+// VirtualMachine reports whether the DMI vendor or product names a
+// hypervisor
+func (l *LinuxFeatures) VirtualMachine() bool {
+	for _, file := range []string{"sys_vendor", "product_name"} {
+		name, err := os.ReadFile(filepath.Join("/sys/class/dmi/id", file))
+		if err == nil && isVirtualHardware(string(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// onBattery reports whether there is a battery and no mains supply is online.
+// Desktops without a battery never count as on battery, nor do wireless
+// mice and other peripherals (scope "Device").
+func onBattery() bool {
+	supplies, _ := filepath.Glob("/sys/class/power_supply/*")
+	hasBattery := false
+	for _, dir := range supplies {
+		kind, err := os.ReadFile(filepath.Join(dir, "type"))
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSpace(string(kind)) {
+		case "Mains":
+			if online, err := os.ReadFile(filepath.Join(dir, "online")); err == nil &&
+				strings.TrimSpace(string(online)) == "1" {
+				return false
+			}
+		case "Battery":
+			scope, _ := os.ReadFile(filepath.Join(dir, "scope"))
+			if strings.TrimSpace(string(scope)) != "Device" {
+				hasBattery = true
+			}
+		}
+	}
+	return hasBattery
+}
@@ -1,8 +1,33 @@
 package platform
 
+import (
+	"errors"
+	"image"
+	"strings"
+	"time"
+)
+
+// ErrNotSupported is returned by features the platform can't provide
+var ErrNotSupported = errors.New("not supported on this platform")
+
 // WindowHandle represents a platform-specific window handle
 type WindowHandle uintptr
 
+// Rect is a screen rectangle in virtual-desktop coordinates
+type Rect struct {
+	X, Y, Width, Height int
+}
+
+// Contains reports whether the point (x, y) lies inside r
+func (r Rect) Contains(x, y int) bool {
+	return x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Height
+}
+
+// Intersects reports whether r and o share any area
+func (r Rect) Intersects(o Rect) bool {
+	return r.X < o.X+o.Width && o.X < r.X+r.Width && r.Y < o.Y+o.Height && o.Y < r.Y+r.Height
+}
+
 // SnapPosition represents where the window is snapped
 type SnapPosition string
 
@@ -17,12 +42,47 @@ const (
 	SnapBottomRight SnapPosition = "bottom-right"
 )
 
+// Capabilities reports which optional features actually work on this system,
+// so settings that would silently do nothing can be hidden
+type Capabilities struct {
+	Transparency  bool // whole-window opacity
+	PerPixelAlpha bool // background-only opacity (see SetPerPixelAlpha)
+	ClickThrough  bool // mouse events pass through the overlay
+	Hotkeys       bool // global hotkeys
+	IdleDetection bool // GetIdleSeconds reports real input idle time
+	WindowList    bool // TopmostWindows lists other apps' windows
+}
+
+// HotkeyHealth is the state of the global hotkey listener, shown in Settings
+type HotkeyHealth struct {
+	Running     bool      // the listener is up
+	Restarting  bool      // the listener died and is being restarted
+	Suspended   bool      // hotkeys are released to other apps
+	Restarts    int       // automatic restarts since the app started
+	LastError   error     // why the listener or a registration last failed
+	LastErrorAt time.Time // when LastError happened
+}
+
+// hotkeyFailure is the last failure reported in HotkeyHealth
+type hotkeyFailure struct {
+	err error
+	at  time.Time
+}
+
+func (f *hotkeyFailure) record(err error) {
+	f.err, f.at = err, time.Now()
+}
+
 // PlatformFeatures defines the interface for platform-specific features.
 // Each platform (Windows, Linux, macOS) must implement this interface.
 type PlatformFeatures interface {
+	// Capabilities reports which of the features below are functional
+	Capabilities() Capabilities
+
 	// Window management
 	SetAlwaysOnTop(handle WindowHandle, onTop bool) error
 	SetTransparency(handle WindowHandle, opacity float64) error
+	SetPerPixelAlpha(handle WindowHandle, enabled bool) error
 	SetClickThrough(handle WindowHandle, clickThrough bool) error
 	MoveWindowTo(handle WindowHandle, x, y int) error
 	MoveAndResizeWindow(handle WindowHandle, x, y, width, height int) error
@@ -31,15 +91,59 @@ type PlatformFeatures interface {
 	// Screen info
 	GetScreenSize() (width, height int)
 	GetWorkArea() (x, y, width, height int)
+	GetMonitorWorkAreas() []Rect
+	CaptureScreen(r Rect) (image.Image, error)
 
 	// Global hotkeys
 	RegisterHotkey(id int, modifiers uint, keyCode uint) error
 	UnregisterHotkey(id int) error
 	SetupHotkeyListener(callback func(id int)) error
 	StopHotkeyListener()
+	SetHotkeysSuspended(suspended bool) error
+	HotkeyHealth() HotkeyHealth
+
+	// Foreground app, used to pause hotkeys while excluded apps are focused
+	ForegroundProcess() string
+	SetupForegroundListener(callback func()) error
+	StopForegroundListener()
 
 	// Idle detection
 	GetIdleSeconds() int
+
+	// Display topology (monitors plugged/unplugged, resolution changes)
+	SetupDisplayListener(callback func()) error
+	StopDisplayListener()
+
+	// Taskbar/menu bar theme, used to pick a matching tray icon
+	SystemUsesLightTheme() bool
+	SetupThemeListener(callback func()) error
+	StopThemeListener()
+
+	// Accessibility and power state, used to drop animations and poll less
+	PrefersReducedMotion() bool
+	OnBatterySaver() bool
+
+	// RemoteSession reports whether the desktop is shown over Remote Desktop
+	// or a similar protocol, where layered window transparency often fails
+	RemoteSession() bool
+
+	// VirtualMachine reports whether the machine is a virtual machine, where
+	// drawing is usually unaccelerated and expensive
+	VirtualMachine() bool
+
+	// Screen readers, to speak alerts that are otherwise only shown.
+	// Announce returns ErrNotSupported where the screen reader can only be
+	// reached through notifications.
+	ScreenReaderActive() bool
+	Announce(handle WindowHandle, text string) error
+
+	// FlashTaskbar flashes the window's taskbar button, or marks the window
+	// urgent, to draw attention without a notification
+	FlashTaskbar(handle WindowHandle) error
+
+	// TopmostWindows returns the bounds of the visible always-on-top
+	// windows of other apps, e.g. a video call's control bar
+	TopmostWindows() ([]Rect, error)
 }
 
 // Hotkey modifiers
@@ -57,6 +161,8 @@ const (
 	VK_RIGHT      uint = 0x27
 	VK_DOWN       uint = 0x28
 	VK_OEM_PERIOD uint = 0xBE // '.' key
+	VK_NEXT       uint = 0x22 // Page Down
+	VK_HOME       uint = 0x24
 )
 
 // Hotkey IDs
@@ -69,4 +175,89 @@ const (
 	HotkeySnapBottomLeft  = 6
 	HotkeySnapBottomRight = 7
 	HotkeyToggleOverlay   = 8
+	HotkeyNextPage        = 9
+	HotkeyNudgeUp         = 10
+	HotkeyInteract        = 11
+)
+
+// hotkeyBinding is a key combination bound to a hotkey ID
+type hotkeyBinding struct {
+	mods uint
+	key  uint
+	name string
+}
+
+// defaultHotkeys are registered when the listener starts:
+//
+//	Ctrl+Alt+Arrow       = edge snaps (left, right, top)
+//	Ctrl+Alt+Shift+Arrow = corner snaps (top-left, top-right, bottom-left), or
+//	                       nudges while the overlay is floating
+//	Ctrl+Alt+.           = toggle overlay
+//	Ctrl+Alt+PageDown    = next overlay page
+//	Ctrl+Alt+Home        = let a click-through overlay take clicks for a while
+var defaultHotkeys = []struct {
+	id int
+	hotkeyBinding
+}{
+	// Edge snaps
+	{HotkeySnapLeft, hotkeyBinding{ModCtrl | ModAlt, VK_LEFT, "Ctrl+Alt+Left (snap left)"}},
+	{HotkeySnapRight, hotkeyBinding{ModCtrl | ModAlt, VK_RIGHT, "Ctrl+Alt+Right (snap right)"}},
+	{HotkeySnapTop, hotkeyBinding{ModCtrl | ModAlt, VK_UP, "Ctrl+Alt+Up (snap top)"}},
+	// Corner snaps (Shift = push to corner)
+	{HotkeySnapTopLeft, hotkeyBinding{ModCtrl | ModAlt | ModShift, VK_LEFT, "Ctrl+Alt+Shift+Left (top-left)"}},
+	{HotkeySnapTopRight, hotkeyBinding{ModCtrl | ModAlt | ModShift, VK_RIGHT, "Ctrl+Alt+Shift+Right (top-right)"}},
+	{HotkeySnapBottomLeft, hotkeyBinding{ModCtrl | ModAlt | ModShift, VK_DOWN, "Ctrl+Alt+Shift+Down (bottom-left)"}},
+	{HotkeySnapBottomRight, hotkeyBinding{ModCtrl | ModAlt, VK_DOWN, "Ctrl+Alt+Down (bottom-right)"}},
+	// Only nudges, there is no fourth corner for Shift+Up
+	{HotkeyNudgeUp, hotkeyBinding{ModCtrl | ModAlt | ModShift, VK_UP, "Ctrl+Alt+Shift+Up (nudge up)"}},
+	// Toggle overlay
+	{HotkeyToggleOverlay, hotkeyBinding{ModCtrl | ModAlt, VK_OEM_PERIOD, "Ctrl+Alt+. (toggle overlay)"}},
+	// Overlay pages
+	{HotkeyNextPage, hotkeyBinding{ModCtrl | ModAlt, VK_NEXT, "Ctrl+Alt+PageDown (next page)"}},
+	// Interacting with a click-through overlay
+	{HotkeyInteract, hotkeyBinding{ModCtrl | ModAlt, VK_HOME, "Ctrl+Alt+Home (interact with overlay)"}},
+}
+
+// Poll intervals used on platforms without change notifications
+const (
+	displayPollInterval    = 5 * time.Second
+	themePollInterval      = 5 * time.Second
+	foregroundPollInterval = time.Second
 )
+
+// pollChanges calls callback whenever signature changes, until stop is closed.
+// Used where the OS offers no change notification without CGO.
+func pollChanges(interval time.Duration, signature func() string, callback func(), stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := signature()
+	for {
+		select {
+		case <-ticker.C:
+			current := signature()
+			if current != last {
+				last = current
+				callback()
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// hypervisorVendors are substrings of the firmware vendor or product name of
+// common virtual machines, lower case
+var hypervisorVendors = []string{"vmware", "virtualbox", "qemu", "kvm", "xen", "parallels", "bochs", "virtual machine", "hyper-v"}
+
+// isVirtualHardware reports whether a firmware vendor or product name
+// belongs to a virtual machine
+func isVirtualHardware(name string) bool {
+	name = strings.ToLower(name)
+	for _, v := range hypervisorVendors {
+		if strings.Contains(name, v) {
+			return true
+		}
+	}
+	return false
+}
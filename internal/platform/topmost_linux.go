@@ -0,0 +1,99 @@
+//go:build linux
+
+package platform
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// wmctrlWindow is a window as listed by wmctrl -lpG
+type wmctrlWindow struct {
+	id      string
+	desktop int // -1 on all desktops
+	pid     int // 0 when the window doesn't say
+	bounds  Rect
+}
+
+// parseWmctrlWindows parses the output of wmctrl -lpG: the window ID,
+// desktop, PID and geometry, followed by the host and title
+func parseWmctrlWindows(out string) []wmctrlWindow {
+	var windows []wmctrlWindow
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 7 {
+			continue
+		}
+		var nums [6]int
+		ok := true
+		for i := range nums {
+			n, err := strconv.Atoi(fields[i+1])
+			if err != nil {
+				ok = false
+				break
+			}
+			nums[i] = n
+		}
+		if !ok {
+			continue
+		}
+		windows = append(windows, wmctrlWindow{
+			id:      fields[0],
+			desktop: nums[0],
+			pid:     nums[1],
+			bounds:  Rect{X: nums[2], Y: nums[3], Width: nums[4], Height: nums[5]},
+		})
+	}
+	return windows
+}
+
+// currentDesktop returns the desktop marked current in the output of
+// wmctrl -d, or -1 when none is
+func currentDesktop(out string) int {
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[1] == "*" {
+			if n, err := strconv.Atoi(fields[0]); err == nil {
+				return n
+			}
+		}
+	}
+	return -1
+}
+
+// keptAbove reports whether the output of xprop _NET_WM_STATE shows a
+// window that is kept above others and not minimized
+func keptAbove(out string) bool {
+	return strings.Contains(out, "_NET_WM_STATE_ABOVE") && !strings.Contains(out, "_NET_WM_STATE_HIDDEN")
+}
+
+// TopmostWindows lists the windows on the current desktop with wmctrl and
+// returns those of other processes that xprop shows kept above others
+func (l *LinuxFeatures) TopmostWindows() ([]Rect, error) {
+	out, err := exec.Command("wmctrl", "-lpG").Output()
+	if err != nil {
+		return nil, fmt.Errorf("wmctrl -lpG failed: %w", err)
+	}
+	desktop := -1
+	if d, err := exec.Command("wmctrl", "-d").Output(); err == nil {
+		desktop = currentDesktop(string(d))
+	}
+
+	var rects []Rect
+	for _, w := range parseWmctrlWindows(string(out)) {
+		if w.pid == os.Getpid() || w.bounds.Width <= 1 || w.bounds.Height <= 1 {
+			continue
+		}
+		if desktop >= 0 && w.desktop >= 0 && w.desktop != desktop {
+			continue
+		}
+		state, err := exec.Command("xprop", "-id", w.id, "_NET_WM_STATE").Output()
+		if err == nil && keptAbove(string(state)) {
+			rects = append(rects, w.bounds)
+		}
+	}
+	return rects, nil
+}
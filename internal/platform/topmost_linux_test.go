@@ -0,0 +1,44 @@
+//go:build linux
+
+package platform
+
+import "testing"
+
+func TestParseWmctrlWindows(t *testing.T) {
+	out := "0x03c00003  0 4242   0    0    1920 1080 host Editor - main.go\n" +
+		"0x04a00007 -1 977    760  8    400  60   host Meeting controls\n" +
+		"0x05000001  1 0      10   10   300  200  host\n" +
+		"garbage line\n"
+	windows := parseWmctrlWindows(out)
+	if len(windows) != 3 {
+		t.Fatalf("got %d windows, want 3: %+v", len(windows), windows)
+	}
+	want := wmctrlWindow{id: "0x04a00007", desktop: -1, pid: 977, bounds: Rect{760, 8, 400, 60}}
+	if windows[1] != want {
+		t.Errorf("second window = %+v, want %+v", windows[1], want)
+	}
+}
+
+func TestCurrentDesktop(t *testing.T) {
+	out := "0  - DG: 1920x1080  VP: N/A  WA: 0,32 1920x1048  Main\n" +
+		"1  * DG: 1920x1080  VP: 0,0  WA: 0,32 1920x1048  Chat\n"
+	if got := currentDesktop(out); got != 1 {
+		t.Errorf("currentDesktop = %d, want 1", got)
+	}
+	if got := currentDesktop(""); got != -1 {
+		t.Errorf("currentDesktop of nothing = %d, want -1", got)
+	}
+}
+
+func TestKeptAbove(t *testing.T) {
+	for out, want := range map[string]bool{
+		"_NET_WM_STATE(ATOM) = _NET_WM_STATE_ABOVE, _NET_WM_STATE_STICKY": true,
+		"_NET_WM_STATE(ATOM) = _NET_WM_STATE_ABOVE, _NET_WM_STATE_HIDDEN": false,
+		"_NET_WM_STATE(ATOM) = _NET_WM_STATE_MAXIMIZED_VERT":              false,
+		"_NET_WM_STATE:  not found.":                                      false,
+	} {
+		if got := keptAbove(out); got != want {
+			t.Errorf("keptAbove(%q) = %v, want %v", out, got, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+//go:build windows
+
+package platform
+
+import (
+	"fmt"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	procEnumWindows           = user32.NewProc("EnumWindows")
+	procIsWindowVisible       = user32.NewProc("IsWindowVisible")
+	procIsIconic              = user32.NewProc("IsIconic")
+	procDwmGetWindowAttribute = dwmapi.NewProc("DwmGetWindowAttribute")
+)
+
+// DWMWA_CLOAKED is set for windows that are "visible" but not shown, such
+// as suspended store apps and windows on other virtual desktops
+const DWMWA_CLOAKED = 14
+
+// topmostEnum collects results for the EnumWindows callback below
+var (
+	topmostEnumMu    sync.Mutex
+	topmostEnumRects []Rect
+)
+
+// topmostEnumProc is the EnumWindows callback (created once, see displayWndProc)
+var topmostEnumProc = syscall.NewCallback(func(hwnd, lParam uintptr) uintptr {
+	if visible, _, _ := procIsWindowVisible.Call(hwnd); visible == 0 {
+		return 1
+	}
+	if iconic, _, _ := procIsIconic.Call(hwnd); iconic != 0 {
+		return 1
+	}
+	if exStyle, _, _ := procGetWindowLong.Call(hwnd, gwlExStyle); exStyle&WS_EX_TOPMOST == 0 {
+		return 1
+	}
+	var pid uint32
+	procGetWindowThreadPID.Call(hwnd, uintptr(unsafe.Pointer(&pid)))
+	if pid == uint32(lParam) {
+		return 1 // ours
+	}
+	if procDwmGetWindowAttribute.Find() == nil {
+		var cloaked uint32
+		ret, _, _ := procDwmGetWindowAttribute.Call(hwnd, DWMWA_CLOAKED, uintptr(unsafe.Pointer(&cloaked)), unsafe.Sizeof(cloaked))
+		if ret == 0 && cloaked != 0 {
+			return 1
+		}
+	}
+	var rect RECT
+	if ret, _, _ := procGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&rect))); ret == 0 {
+		return 1
+	}
+	if r := (Rect{int(rect.Left), int(rect.Top), int(rect.Right - rect.Left), int(rect.Bottom - rect.Top)}); r.Width > 1 && r.Height > 1 {
+		topmostEnumRects = append(topmostEnumRects, r)
+	}
+	return 1 // continue enumeration
+})
+
+// TopmostWindows returns the bounds of the visible, uncloaked windows of
+// other processes that have WS_EX_TOPMOST
+func (w *WindowsFeatures) TopmostWindows() ([]Rect, error) {
+	topmostEnumMu.Lock()
+	defer topmostEnumMu.Unlock()
+
+	topmostEnumRects = nil
+	ret, _, err := procEnumWindows.Call(topmostEnumProc, uintptr(syscall.Getpid()))
+	rects := topmostEnumRects
+	topmostEnumRects = nil
+	if ret == 0 {
+		return nil, fmt.Errorf("EnumWindows failed: %w", err)
+	}
+	return rects, nil
+}
@@ -3,17 +3,25 @@
 package platform
 
 import (
+	"errors"
 	"fmt"
+	"image"
 	"log"
+	"os"
 	"runtime"
+	"strings"
 	"sync"
 	"syscall"
+	"time"
 	"unsafe"
 )
 
 var (
 	user32                   = syscall.NewLazyDLL("user32.dll")
 	kernel32                 = syscall.NewLazyDLL("kernel32.dll")
+	advapi32                 = syscall.NewLazyDLL("advapi32.dll")
+	dwmapi                   = syscall.NewLazyDLL("dwmapi.dll")
+	gdi32                    = syscall.NewLazyDLL("gdi32.dll")
 	procSetWindowPos         = user32.NewProc("SetWindowPos")
 	procMoveWindow           = user32.NewProc("MoveWindow")
 	procGetWindowRect        = user32.NewProc("GetWindowRect")
@@ -30,6 +38,39 @@ var (
 	procGetLastInputInfo     = user32.NewProc("GetLastInputInfo")
 	procGetTickCount         = kernel32.NewProc("GetTickCount")
 	procFindWindow           = user32.NewProc("FindWindowW")
+	procIsWindow             = user32.NewProc("IsWindow")
+	procRegisterClassEx      = user32.NewProc("RegisterClassExW")
+	procCreateWindowEx       = user32.NewProc("CreateWindowExW")
+	procDestroyWindow        = user32.NewProc("DestroyWindow")
+	procDefWindowProc        = user32.NewProc("DefWindowProcW")
+	procTranslateMessage     = user32.NewProc("TranslateMessage")
+	procDispatchMessage      = user32.NewProc("DispatchMessageW")
+	procGetModuleHandle      = kernel32.NewProc("GetModuleHandleW")
+	procEnumDisplayMonitors  = user32.NewProc("EnumDisplayMonitors")
+	procGetMonitorInfo       = user32.NewProc("GetMonitorInfoW")
+	procCreateEvent          = kernel32.NewProc("CreateEventW")
+	procWaitForSingleObject  = kernel32.NewProc("WaitForSingleObject")
+	procRegNotifyChangeKey   = advapi32.NewProc("RegNotifyChangeKeyValue")
+	procGetSystemPowerStatus = kernel32.NewProc("GetSystemPowerStatus")
+	procDwmEnableBlurBehind  = dwmapi.NewProc("DwmEnableBlurBehindWindow")
+	procCreateRectRgn        = gdi32.NewProc("CreateRectRgn")
+	procDeleteObject         = gdi32.NewProc("DeleteObject")
+	procGetDC                = user32.NewProc("GetDC")
+	procReleaseDC            = user32.NewProc("ReleaseDC")
+	procCreateCompatibleDC   = gdi32.NewProc("CreateCompatibleDC")
+	procCreateCompatibleBmp  = gdi32.NewProc("CreateCompatibleBitmap")
+	procSelectObject         = gdi32.NewProc("SelectObject")
+	procBitBlt               = gdi32.NewProc("BitBlt")
+	procGetDIBits            = gdi32.NewProc("GetDIBits")
+	procDeleteDC             = gdi32.NewProc("DeleteDC")
+	procSendMessageTimeout   = user32.NewProc("SendMessageTimeoutW")
+	procSetWinEventHook      = user32.NewProc("SetWinEventHook")
+	procUnhookWinEvent       = user32.NewProc("UnhookWinEvent")
+	procGetForegroundWindow  = user32.NewProc("GetForegroundWindow")
+	procGetWindowThreadPID   = user32.NewProc("GetWindowThreadProcessId")
+	procOpenProcess          = kernel32.NewProc("OpenProcess")
+	procQueryProcessImage    = kernel32.NewProc("QueryFullProcessImageNameW")
+	procFlashWindowEx        = user32.NewProc("FlashWindowEx")
 )
 
 // Windows constants
@@ -42,20 +83,45 @@ const (
 	SWP_SHOWWINDOW = 0x0040
 
 	WS_EX_LAYERED     = 0x00080000
-	WS_EX_TRANSPARENT  = 0x00000020
-	WS_EX_TOOLWINDOW   = 0x00000080
-	WS_EX_TOPMOST      = 0x00000008
+	WS_EX_TRANSPARENT = 0x00000020
+	WS_EX_TOOLWINDOW  = 0x00000080
+	WS_EX_TOPMOST     = 0x00000008
 
 	LWA_ALPHA    = 0x00000002
 	LWA_COLORKEY = 0x00000001
 
-	SM_CXSCREEN = 0
-	SM_CYSCREEN = 1
+	SM_CXSCREEN      = 0
+	SM_CYSCREEN      = 1
+	SM_REMOTESESSION = 0x1000
 
-	SPI_GETWORKAREA = 0x0030
+	SPI_GETWORKAREA            = 0x0030
+	SPI_GETCLIENTAREAANIMATION = 0x1042
 
-	WM_HOTKEY = 0x0312
-	WM_QUIT   = 0x0012
+	WM_HOTKEY        = 0x0312
+	WM_QUIT          = 0x0012
+	WM_DISPLAYCHANGE = 0x007E
+	WM_SETTINGCHANGE = 0x001A
+
+	SPI_SETWORKAREA = 0x002F
+
+	WS_POPUP = 0x80000000
+
+	DWM_BB_ENABLE     = 0x00000001
+	DWM_BB_BLURREGION = 0x00000002
+
+	WM_APP = 0x8000
+
+	SMTO_ABORTIFHUNG = 0x0002
+
+	EVENT_SYSTEM_FOREGROUND           = 0x0003
+	WINEVENT_OUTOFCONTEXT             = 0x0000
+	PROCESS_QUERY_LIMITED_INFORMATION = 0x1000
+
+	SRCCOPY        = 0x00CC0020
+	DIB_RGB_COLORS = 0
+	BI_RGB         = 0
+
+	FLASHW_TRAY = 0x00000002
 )
 
 // gwlExStyle is GWL_EXSTYLE (-20) as uintptr, computed at runtime to avoid overflow
@@ -83,24 +149,88 @@ type MSG struct {
 	Pt      struct{ X, Y int32 }
 }
 
+// MONITORINFO structure for GetMonitorInfo
+type MONITORINFO struct {
+	CbSize    uint32
+	RcMonitor RECT
+	RcWork    RECT
+	DwFlags   uint32
+}
+
+// WNDCLASSEX structure for registering the hidden listener window class
+type WNDCLASSEX struct {
+	CbSize        uint32
+	Style         uint32
+	LpfnWndProc   uintptr
+	CbClsExtra    int32
+	CbWndExtra    int32
+	HInstance     uintptr
+	HIcon         uintptr
+	HCursor       uintptr
+	HbrBackground uintptr
+	LpszMenuName  *uint16
+	LpszClassName *uint16
+	HIconSm       uintptr
+}
+
 // LASTINPUTINFO for idle detection
 type LASTINPUTINFO struct {
 	CbSize uint32
 	DwTime uint32
 }
 
+// FLASHWINFO for FlashWindowEx
+type FLASHWINFO struct {
+	CbSize    uint32
+	HWnd      uintptr
+	DwFlags   uint32
+	UCount    uint32
+	DwTimeout uint32
+}
+
 // WindowsFeatures implements PlatformFeatures for Windows
 type WindowsFeatures struct {
-	mu             sync.Mutex
-	hotkeyThreadID uint32
-	stopHotkey     chan struct{}
-	hotkeyRunning  bool
+	mu               sync.Mutex
+	hotkeyThreadID   uint32
+	stopHotkey       chan struct{}
+	hotkeyRunning    bool
+	hotkeyHwnd       uintptr               // hidden window that owns the hotkeys
+	hotkeyCallback   func(id int)          // called from the hotkey window's thread
+	hotkeys          map[int]hotkeyBinding // wanted bindings, registered unless suspended
+	hotkeysSuspended bool
+	hotkeyRestarts   int // watchdog restarts of the message loop
+	hotkeyFailure    hotkeyFailure
+
+	displayThreadID uint32
+	displayRunning  bool
+	displayCallback func()
+
+	themeRunning bool
+	stopTheme    chan struct{}
+
+	foregroundThreadID uint32
+	foregroundRunning  bool
+	foregroundCallback func()
 }
 
 // NewWindowsFeatures creates a new Windows platform features instance
 func NewWindowsFeatures() *WindowsFeatures {
 	return &WindowsFeatures{
 		stopHotkey: make(chan struct{}),
+		hotkeys:    make(map[int]hotkeyBinding),
+	}
+}
+
+// Capabilities reports every feature as functional; background-only opacity
+// additionally needs DWM blur-behind (Vista and later)
+func (w *WindowsFeatures) Capabilities() Capabilities {
+	return Capabilities{
+		Transparency:  true,
+		PerPixelAlpha: procDwmEnableBlurBehind.Find() == nil,
+		ClickThrough:  true,
+		Hotkeys:       true,
+		IdleDetection: true,
+		WindowList:    true,
 	}
 }
 
@@ -150,6 +280,44 @@ func (w *WindowsFeatures) SetTransparency(handle WindowHandle, opacity float64)
 	return nil
 }
 
+// dwmBlurBehind mirrors DWM_BLURBEHIND
+type dwmBlurBehind struct {
+	Flags                 uint32
+	Enable                int32
+	RgnBlur               uintptr
+	TransitionOnMaximized int32
+}
+
+// SetPerPixelAlpha makes DWM honor the alpha channel the window draws, so
+// translucent pixels show the desktop while opaque ones stay solid. Blur
+// behind is enabled with an empty region, which turns on alpha composition
+// without actually blurring anything. The window-wide alpha is reset to
+// opaque so it doesn't fade the content on top.
+func (w *WindowsFeatures) SetPerPixelAlpha(handle WindowHandle, enabled bool) error {
+	if err := procDwmEnableBlurBehind.Find(); err != nil {
+		return ErrNotSupported
+	}
+
+	bb := dwmBlurBehind{Flags: DWM_BB_ENABLE}
+	if enabled {
+		rgn, _, err := procCreateRectRgn.Call(0, 0, ^uintptr(0), ^uintptr(0)) // (0,0)-(-1,-1): empty
+		if rgn == 0 {
+			return fmt.Errorf("CreateRectRgn failed: %w", err)
+		}
+		defer procDeleteObject.Call(rgn)
+		bb = dwmBlurBehind{Flags: DWM_BB_ENABLE | DWM_BB_BLURREGION, Enable: 1, RgnBlur: rgn}
+	}
+
+	hr, _, _ := procDwmEnableBlurBehind.Call(uintptr(handle), uintptr(unsafe.Pointer(&bb)))
+	if hr != 0 {
+		return fmt.Errorf("DwmEnableBlurBehindWindow failed: HRESULT 0x%08x", uint32(hr))
+	}
+	if enabled {
+		return w.SetTransparency(handle, 1)
+	}
+	return nil
+}
+
 // MoveWindowTo moves a window to the specified position, keeping its current size
 func (w *WindowsFeatures) MoveWindowTo(handle WindowHandle, x, y int) error {
 	// Get current window size
@@ -227,6 +395,22 @@ func (w *WindowsFeatures) SetClickThrough(handle WindowHandle, clickThrough bool
 	return nil
 }
 
+// taskbarFlashes is how many times FlashTaskbar flashes the button
+const taskbarFlashes = 5
+
+// FlashTaskbar flashes the window's taskbar button a few times; it stays
+// highlighted afterwards until the window is activated
+func (w *WindowsFeatures) FlashTaskbar(handle WindowHandle) error {
+	if handle == 0 {
+		return fmt.Errorf("%w: no window to flash", ErrNotSupported)
+	}
+	info := FLASHWINFO{HWnd: uintptr(handle), DwFlags: FLASHW_TRAY, UCount: taskbarFlashes}
+	info.CbSize = uint32(unsafe.Sizeof(info))
+	// The return value is the previous flash state, not an error
+	procFlashWindowEx.Call(uintptr(unsafe.Pointer(&info)))
+	return nil
+}
+
 // GetScreenSize returns the primary screen dimensions
 func (w *WindowsFeatures) GetScreenSize() (width, height int) {
 	cx, _, _ := procGetSystemMetrics.Call(SM_CXSCREEN)
@@ -246,6 +430,44 @@ func (w *WindowsFeatures) GetWorkArea() (x, y, width, height int) {
 	return int(rect.Left), int(rect.Top), int(rect.Right - rect.Left), int(rect.Bottom - rect.Top)
 }
 
+// monitorEnum collects results for the EnumDisplayMonitors callback below
+var (
+	monitorEnumMu    sync.Mutex
+	monitorEnumRects []Rect
+)
+
+// monitorEnumProc is the EnumDisplayMonitors callback (created once, see displayWndProc)
+var monitorEnumProc = syscall.NewCallback(func(hMonitor, hdc, lprc, lParam uintptr) uintptr {
+	var info MONITORINFO
+	info.CbSize = uint32(unsafe.Sizeof(info))
+	ret, _, _ := procGetMonitorInfo.Call(hMonitor, uintptr(unsafe.Pointer(&info)))
+	if ret != 0 {
+		r := info.RcWork
+		monitorEnumRects = append(monitorEnumRects, Rect{
+			X: int(r.Left), Y: int(r.Top),
+			Width: int(r.Right - r.Left), Height: int(r.Bottom - r.Top),
+		})
+	}
+	return 1 // continue enumeration
+})
+
+// GetMonitorWorkAreas returns the work area (excluding taskbars) of every monitor
+func (w *WindowsFeatures) GetMonitorWorkAreas() []Rect {
+	monitorEnumMu.Lock()
+	defer monitorEnumMu.Unlock()
+
+	monitorEnumRects = nil
+	procEnumDisplayMonitors.Call(0, 0, monitorEnumProc, 0)
+	rects := monitorEnumRects
+	monitorEnumRects = nil
+
+	if len(rects) == 0 {
+		x, y, width, height := w.GetWorkArea()
+		return []Rect{{x, y, width, height}}
+	}
+	return rects
+}
+
 // GetIdleSeconds returns the number of seconds since the last keyboard/mouse input
 func (w *WindowsFeatures) GetIdleSeconds() int {
 	var info LASTINPUTINFO
@@ -261,35 +483,125 @@ func (w *WindowsFeatures) GetIdleSeconds() int {
 	return int(idleMs / 1000)
 }
 
-// RegisterHotkey registers a global hotkey
-func (w *WindowsFeatures) RegisterHotkey(id int, modifiers uint, keyCode uint) error {
-	ret, _, err := procRegisterHotKey.Call(
-		0,
-		uintptr(id),
-		uintptr(modifiers),
-		uintptr(keyCode),
-	)
+// Messages handled by the hotkey window. RegisterHotKey binds a hotkey to
+// the thread that owns the window, so other goroutines send these instead
+// of calling it directly.
+const (
+	wmRegisterHotkey   = WM_APP + 1 // wParam: id, lParam: modifiers<<16 | key
+	wmUnregisterHotkey = WM_APP + 2 // wParam: id
+)
 
-	if ret == 0 {
-		return fmt.Errorf("RegisterHotKey failed for id %d: %w", id, err)
+// hotkeyWndProc is the window procedure of the hidden hotkey window.
+// Created once: syscall.NewCallback slots are never freed.
+var hotkeyWndProc = syscall.NewCallback(func(hwnd, msg, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_HOTKEY:
+		// Only this thread changes hotkeyCallback while the window exists, so
+		// it is read without the lock a pending sendHotkeyMessage may hold
+		if callback := Features.hotkeyCallback; callback != nil {
+			callback(int(wParam))
+		}
+		return 0
+	case wmRegisterHotkey:
+		ret, _, _ := procRegisterHotKey.Call(hwnd, wParam, lParam>>16, lParam&0xFFFF)
+		return ret
+	case wmUnregisterHotkey:
+		ret, _, _ := procUnregisterHotKey.Call(hwnd, wParam)
+		return ret
 	}
-	return nil
+	ret, _, _ := procDefWindowProc.Call(hwnd, msg, wParam, lParam)
+	return ret
+})
+
+// RegisterHotkey binds a global hotkey, replacing any earlier binding for
+// id. Safe to call at any time: before the listener starts the binding is
+// kept and registered on start, and while suspended on resume.
+func (w *WindowsFeatures) RegisterHotkey(id int, modifiers uint, keyCode uint) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.hotkeys[id]; ok {
+		w.sendHotkeyMessage(wmUnregisterHotkey, id, 0, 0)
+	}
+	w.hotkeys[id] = hotkeyBinding{mods: modifiers, key: keyCode, name: fmt.Sprintf("id %d", id)}
+	return w.sendHotkeyMessage(wmRegisterHotkey, id, modifiers, keyCode)
 }
 
-// UnregisterHotkey removes a registered hotkey
+// UnregisterHotkey removes a hotkey binding
 func (w *WindowsFeatures) UnregisterHotkey(id int) error {
-	ret, _, err := procUnregisterHotKey.Call(
-		0,
-		uintptr(id),
-	)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.hotkeys[id]; !ok {
+		return nil
+	}
+	delete(w.hotkeys, id)
+	return w.sendHotkeyMessage(wmUnregisterHotkey, id, 0, 0)
+}
 
-	if ret == 0 {
-		return fmt.Errorf("UnregisterHotKey failed for id %d: %w", id, err)
+// SetHotkeysSuspended releases all hotkeys (true) so the key combinations
+// reach the focused app, or registers them again (false). Bindings are kept
+// while suspended.
+func (w *WindowsFeatures) SetHotkeysSuspended(suspended bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if suspended == w.hotkeysSuspended {
+		return nil
 	}
-	return nil
+
+	var errs []error
+	if suspended {
+		for id := range w.hotkeys {
+			if err := w.sendHotkeyMessage(wmUnregisterHotkey, id, 0, 0); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		w.hotkeysSuspended = true
+	} else {
+		w.hotkeysSuspended = false
+		for id, hk := range w.hotkeys {
+			if err := w.sendHotkeyMessage(wmRegisterHotkey, id, hk.mods, hk.key); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
 }
 
-// SetupHotkeyListener sets up the hotkey message loop
+// hotkeyMessageTimeout bounds how long a caller waits for the hotkey window,
+// e.g. while its thread is shutting down
+const hotkeyMessageTimeout = 1000 // ms
+
+// sendHotkeyMessage has the hotkey window register or unregister a hotkey.
+// It does nothing while the window doesn't exist or, for registrations,
+// while hotkeys are suspended. Must be called with w.mu held.
+func (w *WindowsFeatures) sendHotkeyMessage(msg uintptr, id int, modifiers, keyCode uint) error {
+	if w.hotkeyHwnd == 0 || (msg == wmRegisterHotkey && w.hotkeysSuspended) {
+		return nil
+	}
+	lParam := uintptr(modifiers)<<16 | uintptr(keyCode)
+	var result uintptr
+	ret, _, _ := procSendMessageTimeout.Call(w.hotkeyHwnd, msg, uintptr(id), lParam,
+		SMTO_ABORTIFHUNG, hotkeyMessageTimeout, uintptr(unsafe.Pointer(&result)))
+	if ret != 0 && result != 0 {
+		return nil
+	}
+	if msg == wmRegisterHotkey {
+		return fmt.Errorf("RegisterHotKey failed for id %d (combination already taken?)", id)
+	}
+	return fmt.Errorf("UnregisterHotKey failed for id %d", id)
+}
+
+// Restart backoff for a hotkey message loop that died: the delay doubles
+// with each failure in a row, and a loop that ran for hotkeyStableAfter
+// starts over from hotkeyRestartDelay
+const (
+	hotkeyRestartDelay    = time.Second
+	hotkeyRestartMaxDelay = time.Minute
+	hotkeyStableAfter     = time.Minute
+)
+
+// SetupHotkeyListener creates the hidden hotkey window, registers the default
+// hotkeys on it and runs its message loop. A watchdog restarts the loop
+// with the same bindings if it dies.
 func (w *WindowsFeatures) SetupHotkeyListener(callback func(id int)) error {
 	w.mu.Lock()
 	if w.hotkeyRunning {
@@ -297,83 +609,191 @@ func (w *WindowsFeatures) SetupHotkeyListener(callback func(id int)) error {
 		return nil
 	}
 	w.hotkeyRunning = true
+	w.hotkeyCallback = callback
 	w.stopHotkey = make(chan struct{})
+	stop := w.stopHotkey
+	for _, hk := range defaultHotkeys {
+		w.hotkeys[hk.id] = hk.hotkeyBinding
+	}
 	w.mu.Unlock()
 
-	go func() {
-		// CRITICAL: Lock this goroutine to the OS thread.
-		// RegisterHotKey and GetMessage must run on the same OS thread.
-		runtime.LockOSThread()
-		defer runtime.UnlockOSThread()
-
-		threadID, _, _ := procGetCurrentThreadId.Call()
+	ready := make(chan error, 1)
+	exited := make(chan error, 1)
+	go w.hotkeyThread(ready, exited)
+	if err := <-ready; err != nil {
 		w.mu.Lock()
-		w.hotkeyThreadID = uint32(threadID)
+		w.hotkeyRunning = false
+		w.hotkeyCallback = nil
+		w.hotkeyFailure.record(err)
 		w.mu.Unlock()
+		return err
+	}
+	go w.watchHotkeyThread(exited, stop)
+	return nil
+}
 
-		// Register hotkeys:
-		//   Ctrl+Alt+Arrow       = edge snaps (left, right, top)
-		//   Ctrl+Alt+Shift+Arrow = corner snaps (top-left, top-right, bottom-left, bottom-right)
-		//   Ctrl+Alt+.           = toggle overlay
-		hotkeys := []struct {
-			id   int
-			mods uint
-			key  uint
-			name string
-		}{
-			// Edge snaps
-			{HotkeySnapLeft, ModCtrl | ModAlt, VK_LEFT, "Ctrl+Alt+Left (snap left)"},
-			{HotkeySnapRight, ModCtrl | ModAlt, VK_RIGHT, "Ctrl+Alt+Right (snap right)"},
-			{HotkeySnapTop, ModCtrl | ModAlt, VK_UP, "Ctrl+Alt+Up (snap top)"},
-			// Corner snaps (Shift = push to corner)
-			{HotkeySnapTopLeft, ModCtrl | ModAlt | ModShift, VK_LEFT, "Ctrl+Alt+Shift+Left (top-left)"},
-			{HotkeySnapTopRight, ModCtrl | ModAlt | ModShift, VK_RIGHT, "Ctrl+Alt+Shift+Right (top-right)"},
-			{HotkeySnapBottomLeft, ModCtrl | ModAlt | ModShift, VK_DOWN, "Ctrl+Alt+Shift+Down (bottom-left)"},
-			{HotkeySnapBottomRight, ModCtrl | ModAlt, VK_DOWN, "Ctrl+Alt+Down (bottom-right)"},
-			// Toggle overlay
-			{HotkeyToggleOverlay, ModCtrl | ModAlt, VK_OEM_PERIOD, "Ctrl+Alt+. (toggle overlay)"},
+// hotkeyThread creates the hidden hotkey window on its own OS thread,
+// registers the bindings on it and runs its message loop. ready receives
+// whether the window was created; after that, exited receives why the loop
+// ended, nil after StopHotkeyListener.
+func (w *WindowsFeatures) hotkeyThread(ready, exited chan<- error) {
+	// CRITICAL: Lock this goroutine to the OS thread.
+	// The window, its hotkeys and its message loop share the thread.
+	runtime.LockOSThread()
+	var created bool
+	var loopErr error
+	defer func() {
+		// A thread whose message loop failed exits with the goroutine
+		// instead of going back to the scheduler
+		if loopErr == nil {
+			runtime.UnlockOSThread()
+		}
+		if created {
+			exited <- loopErr
 		}
+	}()
 
-		for _, hk := range hotkeys {
-			if err := w.RegisterHotkey(hk.id, hk.mods, hk.key); err != nil {
-				log.Printf("Failed to register %s: %v", hk.name, err)
-			} else {
-				log.Printf("Registered hotkey: %s", hk.name)
-			}
+	threadID, _, _ := procGetCurrentThreadId.Call()
+
+	hInstance, _, _ := procGetModuleHandle.Call(0)
+	className, _ := syscall.UTF16PtrFromString("ClaudeBarHotkeys")
+
+	wc := WNDCLASSEX{
+		LpfnWndProc:   hotkeyWndProc,
+		HInstance:     hInstance,
+		LpszClassName: className,
+	}
+	wc.CbSize = uint32(unsafe.Sizeof(wc))
+	// Registration fails harmlessly if the class already exists (listener restarted)
+	procRegisterClassEx.Call(uintptr(unsafe.Pointer(&wc)))
+
+	hwnd, _, err := procCreateWindowEx.Call(
+		WS_EX_TOOLWINDOW,
+		uintptr(unsafe.Pointer(className)),
+		uintptr(unsafe.Pointer(className)),
+		WS_POPUP,
+		0, 0, 0, 0,
+		0, 0, hInstance, 0,
+	)
+	if hwnd == 0 {
+		ready <- fmt.Errorf("CreateWindowEx failed: %w", err)
+		return
+	}
+	created = true
+
+	w.mu.Lock()
+	if !w.hotkeyRunning {
+		// Stopped while the watchdog was restarting the thread
+		w.mu.Unlock()
+		procDestroyWindow.Call(hwnd)
+		ready <- nil
+		return
+	}
+	w.hotkeyThreadID = uint32(threadID)
+	w.hotkeyHwnd = hwnd
+	for id, hk := range w.hotkeys {
+		if err := w.sendHotkeyMessage(wmRegisterHotkey, id, hk.mods, hk.key); err != nil {
+			log.Printf("Failed to register %s: %v", hk.name, err)
+			w.hotkeyFailure.record(err)
+		} else if !w.hotkeysSuspended {
+			log.Printf("Registered hotkey: %s", hk.name)
 		}
+	}
+	w.mu.Unlock()
+	ready <- nil
 
-		// Message loop — GetMessage blocks until a message arrives
-		var msg MSG
-		for {
-			ret, _, _ := procGetMessage.Call(
-				uintptr(unsafe.Pointer(&msg)),
-				0, 0, 0,
-			)
+	// Message loop — GetMessage blocks until a message arrives
+	var msg MSG
+	for {
+		ret, _, err := procGetMessage.Call(uintptr(unsafe.Pointer(&msg)), 0, 0, 0)
 
-			// ret == 0 means WM_QUIT, ret == -1 means error
-			if ret == 0 || int32(ret) == -1 {
-				break
-			}
+		// ret == 0 means WM_QUIT, ret == -1 means error
+		if int32(ret) == -1 {
+			loopErr = fmt.Errorf("GetMessage failed: %w", err)
+			break
+		}
+		if ret == 0 {
+			break
+		}
+		procTranslateMessage.Call(uintptr(unsafe.Pointer(&msg)))
+		procDispatchMessage.Call(uintptr(unsafe.Pointer(&msg)))
+	}
 
-			if msg.Message == WM_HOTKEY {
-				hotkeyID := int(msg.WParam)
-				callback(hotkeyID)
-			}
+	// Release the hotkeys but keep the bindings for a restart
+	w.mu.Lock()
+	for id := range w.hotkeys {
+		procUnregisterHotKey.Call(hwnd, uintptr(id))
+	}
+	w.hotkeyHwnd = 0
+	w.hotkeyThreadID = 0
+	if !w.hotkeyRunning {
+		w.hotkeyCallback = nil
+	}
+	w.mu.Unlock()
+	procDestroyWindow.Call(hwnd)
+	log.Println("Hotkey message loop exited")
+}
+
+// watchHotkeyThread restarts the hotkey thread each time its message loop
+// ends without StopHotkeyListener, until stop is closed
+func (w *WindowsFeatures) watchHotkeyThread(exited chan error, stop <-chan struct{}) {
+	delay := hotkeyRestartDelay
+	started := time.Now()
+	for {
+		err := <-exited
+		select {
+		case <-stop:
+			return
+		default:
 		}
 
-		// Cleanup hotkeys
-		for _, id := range []int{
-			HotkeySnapLeft, HotkeySnapRight, HotkeySnapTop,
-			HotkeySnapTopLeft, HotkeySnapTopRight,
-			HotkeySnapBottomLeft, HotkeySnapBottomRight,
-			HotkeyToggleOverlay,
-		} {
-			w.UnregisterHotkey(id)
+		if err == nil {
+			err = errors.New("message loop quit unexpectedly")
 		}
-		log.Println("Hotkey message loop exited")
-	}()
+		if time.Since(started) >= hotkeyStableAfter {
+			delay = hotkeyRestartDelay
+		}
+		log.Printf("Hotkeys stopped working: %v; restarting in %s", err, delay)
+		w.mu.Lock()
+		w.hotkeyFailure.record(err)
+		w.mu.Unlock()
 
-	return nil
+		select {
+		case <-stop:
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, hotkeyRestartMaxDelay)
+
+		ready := make(chan error, 1)
+		started = time.Now()
+		go w.hotkeyThread(ready, exited)
+		if err := <-ready; err != nil {
+			exited <- err
+			continue
+		}
+		w.mu.Lock()
+		if w.hotkeyHwnd != 0 {
+			w.hotkeyRestarts++
+			log.Println("Hotkey listener restarted")
+		}
+		w.mu.Unlock()
+	}
+}
+
+// HotkeyHealth reports whether the hotkey window is up, and the watchdog's
+// restarts
+func (w *WindowsFeatures) HotkeyHealth() HotkeyHealth {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return HotkeyHealth{
+		Running:     w.hotkeyHwnd != 0,
+		Restarting:  w.hotkeyRunning && w.hotkeyHwnd == 0,
+		Suspended:   w.hotkeysSuspended,
+		Restarts:    w.hotkeyRestarts,
+		LastError:   w.hotkeyFailure.err,
+		LastErrorAt: w.hotkeyFailure.at,
+	}
 }
 
 // StopHotkeyListener stops the hotkey message loop
@@ -387,6 +807,10 @@ func (w *WindowsFeatures) StopHotkeyListener() {
 
 	close(w.stopHotkey)
 	w.hotkeyRunning = false
+	if w.hotkeyHwnd == 0 {
+		// Between watchdog restarts there is no thread to clear it
+		w.hotkeyCallback = nil
+	}
 
 	// Post WM_QUIT to the hotkey thread to unblock GetMessage
 	if w.hotkeyThreadID != 0 {
@@ -399,6 +823,463 @@ func (w *WindowsFeatures) StopHotkeyListener() {
 	}
 }
 
+// displayWndProc is the window procedure of the hidden display listener window.
+// Created once: syscall.NewCallback slots are never freed.
+var displayWndProc = syscall.NewCallback(func(hwnd, msg, wParam, lParam uintptr) uintptr {
+	if msg == WM_DISPLAYCHANGE || (msg == WM_SETTINGCHANGE && wParam == SPI_SETWORKAREA) {
+		Features.mu.Lock()
+		callback := Features.displayCallback
+		Features.mu.Unlock()
+		if callback != nil {
+			go callback()
+		}
+	}
+	ret, _, _ := procDefWindowProc.Call(hwnd, msg, wParam, lParam)
+	return ret
+})
+
+// SetupDisplayListener creates a hidden top-level window to receive the
+// WM_DISPLAYCHANGE / WM_SETTINGCHANGE broadcasts (message-only windows
+// don't get broadcasts) and calls callback for each one.
+func (w *WindowsFeatures) SetupDisplayListener(callback func()) error {
+	w.mu.Lock()
+	if w.displayRunning {
+		w.mu.Unlock()
+		return nil
+	}
+	w.displayRunning = true
+	w.displayCallback = callback
+	w.mu.Unlock()
+
+	ready := make(chan error, 1)
+
+	go func() {
+		// The window and its message loop must live on the same OS thread
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		threadID, _, _ := procGetCurrentThreadId.Call()
+		w.mu.Lock()
+		w.displayThreadID = uint32(threadID)
+		w.mu.Unlock()
+
+		hInstance, _, _ := procGetModuleHandle.Call(0)
+		className, _ := syscall.UTF16PtrFromString("ClaudeBarDisplayListener")
+
+		wc := WNDCLASSEX{
+			LpfnWndProc:   displayWndProc,
+			HInstance:     hInstance,
+			LpszClassName: className,
+		}
+		wc.CbSize = uint32(unsafe.Sizeof(wc))
+		// Registration fails harmlessly if the class already exists (listener restarted)
+		procRegisterClassEx.Call(uintptr(unsafe.Pointer(&wc)))
+
+		hwnd, _, err := procCreateWindowEx.Call(
+			WS_EX_TOOLWINDOW,
+			uintptr(unsafe.Pointer(className)),
+			uintptr(unsafe.Pointer(className)),
+			WS_POPUP,
+			0, 0, 0, 0,
+			0, 0, hInstance, 0,
+		)
+		if hwnd == 0 {
+			w.mu.Lock()
+			w.displayRunning = false
+			w.mu.Unlock()
+			ready <- fmt.Errorf("CreateWindowEx failed: %w", err)
+			return
+		}
+		ready <- nil
+
+		var msg MSG
+		for {
+			ret, _, _ := procGetMessage.Call(uintptr(unsafe.Pointer(&msg)), 0, 0, 0)
+			if ret == 0 || int32(ret) == -1 {
+				break
+			}
+			procTranslateMessage.Call(uintptr(unsafe.Pointer(&msg)))
+			procDispatchMessage.Call(uintptr(unsafe.Pointer(&msg)))
+		}
+
+		procDestroyWindow.Call(hwnd)
+		log.Println("Display listener exited")
+	}()
+
+	return <-ready
+}
+
+// StopDisplayListener stops the display change message loop
+func (w *WindowsFeatures) StopDisplayListener() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.displayRunning {
+		return
+	}
+	w.displayRunning = false
+	w.displayCallback = nil
+
+	if w.displayThreadID != 0 {
+		procPostThreadMessage.Call(uintptr(w.displayThreadID), WM_QUIT, 0, 0)
+	}
+}
+
+// Taskbar theme registry location (Windows 10 1903+)
+const (
+	personalizeKey         = `Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`
+	regNotifyChangeLastSet = 0x00000004
+	themeWaitTimeoutMs     = 500
+	waitObject0            = 0
+)
+
+// SystemUsesLightTheme reports whether the taskbar uses the light theme.
+// Older Windows versions without the value always have a dark taskbar.
+func (w *WindowsFeatures) SystemUsesLightTheme() bool {
+	var key syscall.Handle
+	path, _ := syscall.UTF16PtrFromString(personalizeKey)
+	if err := syscall.RegOpenKeyEx(syscall.HKEY_CURRENT_USER, path, 0, syscall.KEY_QUERY_VALUE, &key); err != nil {
+		return false
+	}
+	defer syscall.RegCloseKey(key)
+
+	name, _ := syscall.UTF16PtrFromString("SystemUsesLightTheme")
+	var value, valueType uint32
+	size := uint32(unsafe.Sizeof(value))
+	if err := syscall.RegQueryValueEx(key, name, nil, &valueType, (*byte)(unsafe.Pointer(&value)), &size); err != nil {
+		return false
+	}
+	return valueType == syscall.REG_DWORD && value != 0
+}
+
+// SetupThemeListener waits for changes to the Personalize registry key and
+// calls callback when the taskbar theme flips
+func (w *WindowsFeatures) SetupThemeListener(callback func()) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.themeRunning {
+		return nil
+	}
+
+	var key syscall.Handle
+	path, _ := syscall.UTF16PtrFromString(personalizeKey)
+	if err := syscall.RegOpenKeyEx(syscall.HKEY_CURRENT_USER, path, 0, syscall.KEY_NOTIFY, &key); err != nil {
+		return fmt.Errorf("failed to open Personalize key: %w", err)
+	}
+	event, _, err := procCreateEvent.Call(0, 0, 0, 0)
+	if event == 0 {
+		syscall.RegCloseKey(key)
+		return fmt.Errorf("CreateEvent failed: %w", err)
+	}
+
+	w.themeRunning = true
+	w.stopTheme = make(chan struct{})
+	stop := w.stopTheme
+
+	go func() {
+		// Async registry notifications are cancelled when the registering thread exits
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		defer syscall.CloseHandle(syscall.Handle(event))
+		defer syscall.RegCloseKey(key)
+
+		last := w.SystemUsesLightTheme()
+		for {
+			ret, _, _ := procRegNotifyChangeKey.Call(uintptr(key), 0, regNotifyChangeLastSet, event, 1)
+			if ret != 0 {
+				log.Printf("RegNotifyChangeKeyValue failed: %v", syscall.Errno(ret))
+				return
+			}
+
+			for {
+				ret, _, _ := procWaitForSingleObject.Call(event, themeWaitTimeoutMs)
+				if ret == waitObject0 {
+					break
+				}
+				select {
+				case <-stop:
+					return
+				default:
+				}
+			}
+
+			if light := w.SystemUsesLightTheme(); light != last {
+				last = light
+				callback()
+			}
+		}
+	}()
+	return nil
+}
+
+// StopThemeListener stops watching the taskbar theme
+func (w *WindowsFeatures) StopThemeListener() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.themeRunning {
+		return
+	}
+	close(w.stopTheme)
+	w.themeRunning = false
+}
+
+// systemPowerStatus mirrors SYSTEM_POWER_STATUS
+type systemPowerStatus struct {
+	ACLineStatus        byte
+	BatteryFlag         byte
+	BatteryLifePercent  byte
+	SystemStatusFlag    byte
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+// PrefersReducedMotion reports whether "Show animations in Windows" is off
+func (w *WindowsFeatures) PrefersReducedMotion() bool {
+	var enabled int32
+	ret, _, _ := procSystemParametersInfo.Call(
+		SPI_GETCLIENTAREAANIMATION,
+		0,
+		uintptr(unsafe.Pointer(&enabled)),
+		0,
+	)
+	return ret != 0 && enabled == 0
+}
+
+// OnBatterySaver reports whether the machine runs on battery or has
+// Battery Saver turned on
+func (w *WindowsFeatures) OnBatterySaver() bool {
+	var status systemPowerStatus
+	ret, _, _ := procGetSystemPowerStatus.Call(uintptr(unsafe.Pointer(&status)))
+	if ret == 0 {
+		return false
+	}
+	return status.ACLineStatus == 0 || status.SystemStatusFlag == 1
+}
+
+// RemoteSession reports whether the session is shown over Remote Desktop or
+// Citrix. Citrix sessions don't always set SM_REMOTESESSION, but their
+// session names start with "ICA".
+func (w *WindowsFeatures) RemoteSession() bool {
+	if remote, _, _ := procGetSystemMetrics.Call(SM_REMOTESESSION); remote != 0 {
+		return true
+	}
+	return strings.HasPrefix(strings.ToUpper(os.Getenv("SESSIONNAME")), "ICA")
+}
+
+// biosKey holds the firmware vendor and product names
+const biosKey = `HARDWARE\DESCRIPTION\System\BIOS`
+
+// VirtualMachine reports whether the firmware names a hypervisor, e.g.
+// "VMware, Inc." or Hyper-V's "Virtual Machine"
+func (w *WindowsFeatures) VirtualMachine() bool {
+	var key syscall.Handle
+	path, _ := syscall.UTF16PtrFromString(biosKey)
+	if err := syscall.RegOpenKeyEx(syscall.HKEY_LOCAL_MACHINE, path, 0, syscall.KEY_QUERY_VALUE, &key); err != nil {
+		return false
+	}
+	defer syscall.RegCloseKey(key)
+
+	for _, value := range []string{"SystemManufacturer", "SystemProductName"} {
+		name, _ := syscall.UTF16PtrFromString(value)
+		var buf [256]uint16
+		size := uint32(len(buf) * 2)
+		var valueType uint32
+		if err := syscall.RegQueryValueEx(key, name, nil, &valueType, (*byte)(unsafe.Pointer(&buf[0])), &size); err != nil {
+			continue
+		}
+		if valueType == syscall.REG_SZ && isVirtualHardware(syscall.UTF16ToString(buf[:])) {
+			return true
+		}
+	}
+	return false
+}
+
+// bitmapInfoHeader mirrors BITMAPINFOHEADER
+type bitmapInfoHeader struct {
+	Size          uint32
+	Width         int32
+	Height        int32
+	Planes        uint16
+	BitCount      uint16
+	Compression   uint32
+	SizeImage     uint32
+	XPelsPerMeter int32
+	YPelsPerMeter int32
+	ClrUsed       uint32
+	ClrImportant  uint32
+}
+
+// CaptureScreen copies a region of the desktop with BitBlt
+func (w *WindowsFeatures) CaptureScreen(r Rect) (image.Image, error) {
+	if r.Width <= 0 || r.Height <= 0 {
+		return nil, fmt.Errorf("empty capture region %dx%d", r.Width, r.Height)
+	}
+
+	screenDC, _, _ := procGetDC.Call(0)
+	if screenDC == 0 {
+		return nil, fmt.Errorf("GetDC failed")
+	}
+	defer procReleaseDC.Call(0, screenDC)
+
+	memDC, _, _ := procCreateCompatibleDC.Call(screenDC)
+	if memDC == 0 {
+		return nil, fmt.Errorf("CreateCompatibleDC failed")
+	}
+	defer procDeleteDC.Call(memDC)
+
+	bmp, _, _ := procCreateCompatibleBmp.Call(screenDC, uintptr(r.Width), uintptr(r.Height))
+	if bmp == 0 {
+		return nil, fmt.Errorf("CreateCompatibleBitmap failed")
+	}
+	defer procDeleteObject.Call(bmp)
+
+	old, _, _ := procSelectObject.Call(memDC, bmp)
+	ret, _, err := procBitBlt.Call(memDC, 0, 0, uintptr(r.Width), uintptr(r.Height),
+		screenDC, uintptr(int32(r.X)), uintptr(int32(r.Y)), SRCCOPY)
+	procSelectObject.Call(memDC, old)
+	if ret == 0 {
+		return nil, fmt.Errorf("BitBlt failed: %w", err)
+	}
+
+	// Negative height asks for top-down rows
+	header := bitmapInfoHeader{
+		Width:       int32(r.Width),
+		Height:      -int32(r.Height),
+		Planes:      1,
+		BitCount:    32,
+		Compression: BI_RGB,
+	}
+	header.Size = uint32(unsafe.Sizeof(header))
+
+	img := image.NewRGBA(image.Rect(0, 0, r.Width, r.Height))
+	ret, _, _ = procGetDIBits.Call(memDC, bmp, 0, uintptr(r.Height),
+		uintptr(unsafe.Pointer(&img.Pix[0])), uintptr(unsafe.Pointer(&header)), DIB_RGB_COLORS)
+	if ret == 0 {
+		return nil, fmt.Errorf("GetDIBits failed")
+	}
+
+	// GDI returns BGRX; swap to RGBA
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+2] = img.Pix[i+2], img.Pix[i]
+		img.Pix[i+3] = 255
+	}
+	return img, nil
+}
+
+// ForegroundProcess returns the executable name of the focused window's
+// process, e.g. "idea64.exe", or "" if it can't be determined
+func (w *WindowsFeatures) ForegroundProcess() string {
+	hwnd, _, _ := procGetForegroundWindow.Call()
+	if hwnd == 0 {
+		return ""
+	}
+	var pid uint32
+	procGetWindowThreadPID.Call(hwnd, uintptr(unsafe.Pointer(&pid)))
+	if pid == 0 {
+		return ""
+	}
+
+	process, _, _ := procOpenProcess.Call(PROCESS_QUERY_LIMITED_INFORMATION, 0, uintptr(pid))
+	if process == 0 {
+		return ""
+	}
+	defer syscall.CloseHandle(syscall.Handle(process))
+
+	buf := make([]uint16, syscall.MAX_PATH)
+	size := uint32(len(buf))
+	ret, _, _ := procQueryProcessImage.Call(process, 0, uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size)))
+	if ret == 0 {
+		return ""
+	}
+	path := syscall.UTF16ToString(buf[:size])
+	return path[strings.LastIndex(path, `\`)+1:]
+}
+
+// foregroundWinEventProc receives EVENT_SYSTEM_FOREGROUND.
+// Created once: syscall.NewCallback slots are never freed.
+var foregroundWinEventProc = syscall.NewCallback(func(hook, event, hwnd, idObject, idChild, thread, time uintptr) uintptr {
+	Features.mu.Lock()
+	callback := Features.foregroundCallback
+	Features.mu.Unlock()
+	if callback != nil {
+		go callback()
+	}
+	return 0
+})
+
+// SetupForegroundListener calls callback whenever another window comes to
+// the foreground, using an out-of-context WinEvent hook
+func (w *WindowsFeatures) SetupForegroundListener(callback func()) error {
+	w.mu.Lock()
+	if w.foregroundRunning {
+		w.mu.Unlock()
+		return nil
+	}
+	w.foregroundRunning = true
+	w.foregroundCallback = callback
+	w.mu.Unlock()
+
+	ready := make(chan error, 1)
+
+	go func() {
+		// Out-of-context events are delivered through this thread's message loop
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		threadID, _, _ := procGetCurrentThreadId.Call()
+		w.mu.Lock()
+		w.foregroundThreadID = uint32(threadID)
+		w.mu.Unlock()
+
+		hook, _, err := procSetWinEventHook.Call(
+			EVENT_SYSTEM_FOREGROUND, EVENT_SYSTEM_FOREGROUND,
+			0, foregroundWinEventProc,
+			0, 0,
+			WINEVENT_OUTOFCONTEXT,
+		)
+		if hook == 0 {
+			w.mu.Lock()
+			w.foregroundRunning = false
+			w.foregroundCallback = nil
+			w.mu.Unlock()
+			ready <- fmt.Errorf("SetWinEventHook failed: %w", err)
+			return
+		}
+		ready <- nil
+
+		var msg MSG
+		for {
+			ret, _, _ := procGetMessage.Call(uintptr(unsafe.Pointer(&msg)), 0, 0, 0)
+			if ret == 0 || int32(ret) == -1 {
+				break
+			}
+			procTranslateMessage.Call(uintptr(unsafe.Pointer(&msg)))
+			procDispatchMessage.Call(uintptr(unsafe.Pointer(&msg)))
+		}
+
+		procUnhookWinEvent.Call(hook)
+		log.Println("Foreground listener exited")
+	}()
+
+	return <-ready
+}
+
+// StopForegroundListener stops watching the foreground window
+func (w *WindowsFeatures) StopForegroundListener() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.foregroundRunning {
+		return
+	}
+	w.foregroundRunning = false
+	w.foregroundCallback = nil
+
+	if w.foregroundThreadID != 0 {
+		procPostThreadMessage.Call(uintptr(w.foregroundThreadID), WM_QUIT, 0, 0)
+	}
+}
+
 // GetWindowHandle extracts the native window handle by title
 func GetWindowHandle(title string) (WindowHandle, error) {
 	titlePtr, _ := syscall.UTF16PtrFromString(title)
@@ -415,5 +1296,14 @@ func GetWindowHandle(title string) (WindowHandle, error) {
 	return WindowHandle(hwnd), nil
 }
 
+// IsWindowValid reports whether handle still identifies an existing window
+func IsWindowValid(handle WindowHandle) bool {
+	if handle == 0 {
+		return false
+	}
+	ret, _, _ := procIsWindow.Call(uintptr(handle))
+	return ret != 0
+}
+
 // Global instance
 var Features = NewWindowsFeatures()
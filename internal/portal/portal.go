@@ -0,0 +1,137 @@
+package portal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// XDG desktop portal endpoints (https://flatpak.github.io/xdg-desktop-portal/)
+const (
+	portalDest      = "org.freedesktop.portal.Desktop"
+	portalPath      = dbus.ObjectPath("/org/freedesktop/portal/desktop")
+	requestIface    = "org.freedesktop.portal.Request"
+	sessionIface    = "org.freedesktop.portal.Session"
+	shortcutsIface  = "org.freedesktop.portal.GlobalShortcuts"
+	backgroundIface = "org.freedesktop.portal.Background"
+)
+
+// requestTimeout is generous because portals may show a dialog to the user
+const requestTimeout = 2 * time.Minute
+
+var (
+	ErrUnavailable = errors.New("desktop portal not available")
+	ErrCancelled   = errors.New("request cancelled by user")
+	ErrTimeout     = errors.New("portal request timed out")
+)
+
+// Shortcut describes a global shortcut to bind
+type Shortcut struct {
+	ID          string
+	Description string
+	Trigger     string // preferred trigger, e.g. "CTRL+ALT+Left"
+}
+
+// interfaceVersion returns the version of a portal interface, or an error if
+// the portal backend doesn't implement it
+func interfaceVersion(conn *dbus.Conn, iface string) (uint32, error) {
+	v, err := conn.Object(portalDest, portalPath).GetProperty(iface + ".version")
+	if err != nil {
+		return 0, ErrUnavailable
+	}
+	version, _ := v.Value().(uint32)
+	return version, nil
+}
+
+// newToken returns a random handle token
+func newToken() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "claudebar_" + hex.EncodeToString(b)
+}
+
+// requestPath predicts the Request object path so the Response signal can be
+// subscribed to before the call is made
+func requestPath(conn *dbus.Conn, token string) dbus.ObjectPath {
+	sender := ""
+	if names := conn.Names(); len(names) > 0 {
+		sender = strings.ReplaceAll(strings.TrimPrefix(names[0], ":"), ".", "_")
+	}
+	return dbus.ObjectPath(fmt.Sprintf("/org/freedesktop/portal/desktop/request/%s/%s", sender, token))
+}
+
+// request calls a portal method that answers through a Request object and
+// waits for its Response. options must be the method's last argument.
+func request(conn *dbus.Conn, method string, options map[string]dbus.Variant, args ...interface{}) (map[string]dbus.Variant, error) {
+	token := newToken()
+	options["handle_token"] = dbus.MakeVariant(token)
+	path := requestPath(conn, token)
+
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(path),
+		dbus.WithMatchInterface(requestIface),
+		dbus.WithMatchMember("Response"),
+	}
+	if err := conn.AddMatchSignal(match...); err != nil {
+		return nil, err
+	}
+	defer conn.RemoveMatchSignal(match...)
+
+	signals := make(chan *dbus.Signal, 8)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	args = append(args, options)
+	if call := conn.Object(portalDest, portalPath).Call(method, 0, args...); call.Err != nil {
+		return nil, fmt.Errorf("%s failed: %w", method, call.Err)
+	}
+
+	timeout := time.After(requestTimeout)
+	for {
+		select {
+		case sig := <-signals:
+			if sig.Path != path || sig.Name != requestIface+".Response" || len(sig.Body) < 2 {
+				continue
+			}
+			if code, _ := sig.Body[0].(uint32); code != 0 {
+				return nil, ErrCancelled
+			}
+			results, _ := sig.Body[1].(map[string]dbus.Variant)
+			return results, nil
+		case <-timeout:
+			return nil, ErrTimeout
+		}
+	}
+}
+
+// RequestBackground asks the portal to allow running in the background and,
+// when autostart is true, to start commandline on login. Disabling is done by
+// requesting again with autostart false.
+func RequestBackground(reason string, autostart bool, commandline []string) error {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return ErrUnavailable
+	}
+	if _, err := interfaceVersion(conn, backgroundIface); err != nil {
+		return err
+	}
+
+	results, err := request(conn, backgroundIface+".RequestBackground", map[string]dbus.Variant{
+		"reason":      dbus.MakeVariant(reason),
+		"autostart":   dbus.MakeVariant(autostart),
+		"commandline": dbus.MakeVariant(commandline),
+	}, "")
+	if err != nil {
+		return err
+	}
+
+	if granted, ok := results["autostart"].Value().(bool); ok && granted != autostart {
+		return errors.New("autostart change was denied")
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package portal
+
+import "github.com/godbus/dbus/v5"
+
+const settingsIface = "org.freedesktop.portal.Settings"
+
+// ColorScheme values of org.freedesktop.appearance color-scheme
+const (
+	ColorSchemeDefault uint32 = iota // no preference
+	ColorSchemeDark
+	ColorSchemeLight
+)
+
+// ColorScheme returns the desktop's preferred color scheme
+func ColorScheme() (uint32, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return ColorSchemeDefault, ErrUnavailable
+	}
+	obj := conn.Object(portalDest, portalPath)
+
+	var v dbus.Variant
+	if err := obj.Call(settingsIface+".ReadOne", 0, "org.freedesktop.appearance", "color-scheme").Store(&v); err != nil {
+		// Portals before version 2 only have Read, which wraps the value twice
+		if err := obj.Call(settingsIface+".Read", 0, "org.freedesktop.appearance", "color-scheme").Store(&v); err != nil {
+			return ColorSchemeDefault, err
+		}
+		if inner, ok := v.Value().(dbus.Variant); ok {
+			v = inner
+		}
+	}
+
+	scheme, _ := v.Value().(uint32)
+	return scheme, nil
+}
@@ -0,0 +1,136 @@
+package portal
+
+import (
+	"log"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// GlobalShortcuts is a bound GlobalShortcuts portal session
+type GlobalShortcuts struct {
+	conn    *dbus.Conn
+	session dbus.ObjectPath
+	signals chan *dbus.Signal
+	match   []dbus.MatchOption
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// GlobalShortcutsAvailable reports whether the portal backend supports
+// global shortcuts (GNOME 48+, KDE Plasma 6, Hyprland...)
+func GlobalShortcutsAvailable() bool {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return false
+	}
+	_, err = interfaceVersion(conn, shortcutsIface)
+	return err == nil
+}
+
+// BindShortcuts opens a shortcuts session and binds the given shortcuts.
+// The desktop may let the user change the triggers, so Trigger is only a hint.
+// onActivated is called with the shortcut ID on every key press.
+func BindShortcuts(shortcuts []Shortcut, onActivated func(id string)) (*GlobalShortcuts, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, ErrUnavailable
+	}
+	if _, err := interfaceVersion(conn, shortcutsIface); err != nil {
+		return nil, err
+	}
+
+	results, err := request(conn, shortcutsIface+".CreateSession", map[string]dbus.Variant{
+		"session_handle_token": dbus.MakeVariant(newToken()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var session dbus.ObjectPath
+	switch v := results["session_handle"].Value().(type) {
+	case string:
+		session = dbus.ObjectPath(v)
+	case dbus.ObjectPath:
+		session = v
+	}
+
+	type shortcutSpec struct {
+		ID      string
+		Options map[string]dbus.Variant
+	}
+	specs := make([]shortcutSpec, 0, len(shortcuts))
+	for _, s := range shortcuts {
+		specs = append(specs, shortcutSpec{
+			ID: s.ID,
+			Options: map[string]dbus.Variant{
+				"description":       dbus.MakeVariant(s.Description),
+				"preferred_trigger": dbus.MakeVariant(s.Trigger),
+			},
+		})
+	}
+
+	g := &GlobalShortcuts{
+		conn:    conn,
+		session: session,
+		signals: make(chan *dbus.Signal, 16),
+		match: []dbus.MatchOption{
+			dbus.WithMatchInterface(shortcutsIface),
+			dbus.WithMatchMember("Activated"),
+		},
+		done: make(chan struct{}),
+	}
+	if err := conn.AddMatchSignal(g.match...); err != nil {
+		g.closeSession()
+		return nil, err
+	}
+	conn.Signal(g.signals)
+
+	if _, err := request(conn, shortcutsIface+".BindShortcuts", map[string]dbus.Variant{}, session, specs, ""); err != nil {
+		g.Close()
+		return nil, err
+	}
+
+	go g.dispatch(onActivated)
+	return g, nil
+}
+
+// dispatch forwards Activated signals for this session
+func (g *GlobalShortcuts) dispatch(onActivated func(id string)) {
+	for {
+		select {
+		case sig := <-g.signals:
+			if sig.Name != shortcutsIface+".Activated" || len(sig.Body) < 2 {
+				continue
+			}
+			if session, _ := sig.Body[0].(dbus.ObjectPath); session != g.session {
+				continue
+			}
+			if id, ok := sig.Body[1].(string); ok {
+				onActivated(id)
+			}
+		case <-g.done:
+			return
+		}
+	}
+}
+
+// Close unbinds the shortcuts by closing the portal session
+func (g *GlobalShortcuts) Close() {
+	g.closeOnce.Do(func() {
+		close(g.done)
+		g.conn.RemoveSignal(g.signals)
+		g.conn.RemoveMatchSignal(g.match...)
+		g.closeSession()
+	})
+}
+
+func (g *GlobalShortcuts) closeSession() {
+	if g.session == "" {
+		return
+	}
+	if call := g.conn.Object(portalDest, g.session).Call(sessionIface+".Close", 0); call.Err != nil {
+		log.Printf("Portal: failed to close shortcuts session: %v", call.Err)
+	}
+}
@@ -0,0 +1,125 @@
+// Package redact masks secrets in log and diagnostic output: session keys,
+// cookie values, bearer tokens, organization IDs and any value registered
+// with Add.
+package redact
+
+import (
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Mask replaces a redacted value
+const Mask = "[redacted]"
+
+// minSecretLen keeps short values like "on" from masking half the log
+const minSecretLen = 6
+
+// patterns match secrets by shape, with the replacement for each
+var patterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// Claude session keys and OAuth tokens
+	{regexp.MustCompile(`sk-ant-[A-Za-z0-9_-]+`), "sk-ant-" + Mask},
+	// Cookie headers and cookie values named in logs
+	{regexp.MustCompile(`(?i)\b(sessionKey|lastActiveOrg|__cf_bm|cf_clearance)=[^;\s"]+`), "$1=" + Mask},
+	{regexp.MustCompile(`(?i)\b(Bearer|Cookie:)\s+[^\s"]+`), "$1 " + Mask},
+	// Dashboard links carry their token in the query
+	{regexp.MustCompile(`([?&]token=)[^&\s"]+`), "${1}" + Mask},
+	// JWTs
+	{regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), Mask},
+	// Organization and account UUIDs
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "[uuid]"},
+}
+
+var (
+	enabled atomic.Bool
+
+	mu      sync.RWMutex
+	secrets []string // longest first, so a secret containing another is masked whole
+)
+
+func init() {
+	enabled.Store(true)
+}
+
+// SetEnabled turns redaction on or off. It stays on in release builds, where
+// full logging can't be turned on.
+func SetEnabled(on bool) {
+	enabled.Store(on || !DebugBuild)
+}
+
+// Enabled reports whether output is being redacted
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Add registers values to mask wherever they appear, e.g. API tokens and
+// passphrases from the config. Empty and very short values are ignored.
+func Add(values ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, v := range values {
+		if len(v) < minSecretLen || contains(secrets, v) {
+			continue
+		}
+		secrets = append(secrets, v)
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns s with secrets masked
+func String(s string) string {
+	if !enabled.Load() {
+		return s
+	}
+	return Always(s)
+}
+
+// Always masks secrets in s even while redaction is turned off, for output
+// that leaves the machine such as bug reports
+func Always(s string) string {
+	mu.RLock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+	mu.RUnlock()
+	for _, p := range patterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
+
+// writer redacts everything written through it
+type writer struct {
+	w io.Writer
+}
+
+// Writer wraps w so everything written to it is redacted. The log package
+// writes one whole line per call, so secrets are never split across writes.
+func Writer(w io.Writer) io.Writer {
+	return writer{w: w}
+}
+
+func (r writer) Write(p []byte) (int, error) {
+	if !enabled.Load() {
+		return r.w.Write(p)
+	}
+	if _, err := io.WriteString(r.w, String(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,61 @@
+package redact
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	Add("my-api-token-123", "short", "passphrase-secret")
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain text", "Fetched usage: 42%", "Fetched usage: 42%"},
+		{"session key", "key sk-ant-sid01-AbC_d-9 loaded", "key sk-ant-[redacted] loaded"},
+		{"cookie header", "Cookie: sessionKey=abc123; lastActiveOrg=org1", "Cookie: [redacted] lastActiveOrg=[redacted]"},
+		{"cookie value", `set sessionKey=abc123;other=1`, `set sessionKey=[redacted];other=1`},
+		{"cloudflare cookies", "__cf_bm=xyz cf_clearance=q.w-e", "__cf_bm=[redacted] cf_clearance=[redacted]"},
+		{"bearer token", "Authorization: Bearer abc.def", "Authorization: Bearer [redacted]"},
+		{"dashboard link", "open http://127.0.0.1:8080/?token=s3cret&view=week", "open http://127.0.0.1:8080/?token=[redacted]&view=week"},
+		{"jwt", "id eyJhbGciOi.eyJzdWIi.c2lnbmF0dXJl end", "id [redacted] end"},
+		{"uuid", "org 1b4e28ba-2fa1-11d2-883f-0016d3cca427 selected", "org [uuid] selected"},
+		{"registered secret", "token my-api-token-123 rejected", "token [redacted] rejected"},
+		{"longest secret first", "passphrase-secret", "[redacted]"},
+		{"short values are not registered", "short answer", "short answer"},
+	}
+	for _, tt := range tests {
+		if got := String(tt.in); got != tt.want {
+			t.Errorf("%s: String(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSetEnabled(t *testing.T) {
+	defer SetEnabled(true)
+
+	in := "key sk-ant-abc"
+	SetEnabled(false)
+	want := in
+	if !DebugBuild {
+		// Release builds always redact
+		want = "key sk-ant-[redacted]"
+	}
+	if got := String(in); got != want {
+		t.Errorf("String with redaction off = %q, want %q", got, want)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	line := "Bearer abc123\n"
+	n, err := Writer(&buf).Write([]byte(line))
+	if err != nil || n != len(line) {
+		t.Fatalf("Write = %d, %v; want %d, nil", n, err, len(line))
+	}
+	if got := buf.String(); got != "Bearer [redacted]\n" {
+		t.Errorf("wrote %q", got)
+	}
+}
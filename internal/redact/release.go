@@ -0,0 +1,7 @@
+//go:build !debug
+
+package redact
+
+// DebugBuild is set for builds made with -tags debug, the only ones that
+// honor the debug_logging setting
+const DebugBuild = false
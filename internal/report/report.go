@@ -0,0 +1,73 @@
+// Package report builds bug reports: the app version, OS, platform probe
+// results, diagnostics and recent log lines, with secrets masked, ready to
+// paste into a GitHub issue or save to a file.
+package report
+
+import (
+	"fmt"
+	"net/url"
+	"runtime"
+	"strings"
+
+	"claudebar/internal/redact"
+	"claudebar/internal/version"
+)
+
+// Log keeps the recent log output for reports; main adds it to the log
+// writers
+var Log = NewTail(200)
+
+// maxURLLength keeps issue links below what browsers and GitHub accept
+const maxURLLength = 8000
+
+// Report is what a bug report says about this installation
+type Report struct {
+	Version     string
+	OS          string
+	Probes      []string // "name: result" lines from the platform checks
+	Diagnostics string   // the Diagnostics window's report
+	Log         []string // recent log lines, oldest first
+}
+
+// New starts a report for this build and OS with the recent log lines
+func New() Report {
+	return Report{
+		Version: version.Version,
+		OS:      runtime.GOOS + "/" + runtime.GOARCH,
+		Log:     Log.Lines(),
+	}
+}
+
+// Text formats the report as Markdown, secrets masked even when debug
+// logging turned redaction off
+func (r Report) Text() string {
+	var b strings.Builder
+	b.WriteString("### What happened?\n\n<!-- Describe the problem and how to reproduce it -->\n\n")
+	b.WriteString("### Environment\n\n")
+	fmt.Fprintf(&b, "- ClaudeBar %s\n- OS: %s\n", r.Version, r.OS)
+	for _, p := range r.Probes {
+		fmt.Fprintf(&b, "- %s\n", p)
+	}
+	if r.Diagnostics != "" {
+		fmt.Fprintf(&b, "\n### Diagnostics\n\n```\n%s```\n", r.Diagnostics)
+	}
+	if len(r.Log) > 0 {
+		fmt.Fprintf(&b, "\n### Recent log\n\n```\n%s\n```\n", strings.Join(r.Log, "\n"))
+	}
+	return redact.Always(b.String())
+}
+
+// IssueURL returns a link opening a new GitHub issue pre-filled with the
+// report. The oldest log lines are dropped until the link is short enough.
+func (r Report) IssueURL() string {
+	for {
+		link := version.Homepage + "/issues/new?" + url.Values{
+			"title": {"Problem report (ClaudeBar " + r.Version + ", " + r.OS + ")"},
+			"body":  {r.Text()},
+		}.Encode()
+		if len(link) <= maxURLLength || len(r.Log) == 0 {
+			return link
+		}
+		r.Log = r.Log[max(1, len(r.Log)/4):]
+	}
+}
@@ -0,0 +1,47 @@
+package report
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestTail(t *testing.T) {
+	tail := NewTail(2)
+	fmt.Fprint(tail, "one\ntwo\nthr")
+	fmt.Fprint(tail, "ee\n")
+	if got := tail.Lines(); len(got) != 2 || got[0] != "two" || got[1] != "three" {
+		t.Errorf("Lines = %q, want [two three]", got)
+	}
+}
+
+func TestReportRedacts(t *testing.T) {
+	r := Report{Version: "1.2.0", OS: "windows/amd64", Log: []string{"Using key sk-ant-sid01-secret"}}
+	text := r.Text()
+	if strings.Contains(text, "secret") {
+		t.Errorf("report leaks the session key:\n%s", text)
+	}
+	if !strings.Contains(text, "ClaudeBar 1.2.0") {
+		t.Errorf("report lacks the version:\n%s", text)
+	}
+}
+
+func TestIssueURLShortensLog(t *testing.T) {
+	r := Report{Version: "1.2.0", OS: "linux/amd64"}
+	for i := range 500 {
+		r.Log = append(r.Log, fmt.Sprintf("log line %d with some padding to make it long", i))
+	}
+	link := r.IssueURL()
+	if len(link) > maxURLLength {
+		t.Fatalf("link is %d bytes, want at most %d", len(link), maxURLLength)
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := u.Query().Get("body")
+	if !strings.Contains(body, "log line 499") || strings.Contains(body, "log line 0 ") {
+		t.Error("shortened log should keep the newest lines and drop the oldest")
+	}
+}
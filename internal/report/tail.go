@@ -0,0 +1,45 @@
+package report
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Tail is an io.Writer keeping the last lines written to it, for putting
+// recent log output into a report. Safe for concurrent use.
+type Tail struct {
+	mu      sync.Mutex
+	max     int
+	lines   []string
+	partial []byte // a line written without its newline yet
+}
+
+// NewTail creates a tail keeping up to max lines
+func NewTail(max int) *Tail {
+	return &Tail{max: max}
+}
+
+func (t *Tail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.partial = append(t.partial, p...)
+	for {
+		i := bytes.IndexByte(t.partial, '\n')
+		if i < 0 {
+			break
+		}
+		t.lines = append(t.lines, string(t.partial[:i]))
+		t.partial = t.partial[i+1:]
+	}
+	if over := len(t.lines) - t.max; over > 0 {
+		t.lines = append([]string(nil), t.lines[over:]...)
+	}
+	return len(p), nil
+}
+
+// Lines returns the kept lines, oldest first
+func (t *Tail) Lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.lines...)
+}
@@ -0,0 +1,49 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Flatpak reports whether the app runs inside a Flatpak sandbox
+func Flatpak() bool {
+	_, err := os.Stat("/.flatpak-info")
+	return err == nil
+}
+
+// AppImage reports whether the app was started from an AppImage
+func AppImage() bool {
+	return os.Getenv("APPIMAGE") != ""
+}
+
+// AppImagePath returns the path of the .AppImage file (not the mounted binary)
+func AppImagePath() string {
+	return os.Getenv("APPIMAGE")
+}
+
+// Name describes the packaging environment for logs
+func Name() string {
+	switch {
+	case Flatpak():
+		return "flatpak"
+	case AppImage():
+		return "appimage"
+	default:
+		return "native"
+	}
+}
+
+// HostConfigDir returns the host's XDG config directory. Flatpak points
+// XDG_CONFIG_HOME into ~/.var/app, so browser profiles and autostart entries
+// have to be looked up via the HOST_ variable it exports instead.
+func HostConfigDir() string {
+	if Flatpak() {
+		if dir := os.Getenv("HOST_XDG_CONFIG_HOME"); dir != "" {
+			return dir
+		}
+	} else if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config")
+}
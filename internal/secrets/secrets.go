@@ -0,0 +1,73 @@
+// Package secrets keeps secrets in the operating system's credential store:
+// Windows Credential Manager, the macOS Keychain, and the Secret Service
+// (GNOME Keyring, KWallet) over D-Bus on Linux.
+package secrets
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	ErrNotFound    = errors.New("secret not found")
+	ErrUnavailable = errors.New("no credential store available")
+	ErrDenied      = errors.New("access to the credential store was denied")
+)
+
+// service names ClaudeBar's entries in the credential store
+const service = "ClaudeBar"
+
+// Store reads and writes secrets by name
+type Store interface {
+	// Get returns the secret, or ErrNotFound
+	Get(name string) (string, error)
+	// Set creates or replaces the secret
+	Set(name, value string) error
+	// Delete removes the secret; removing a missing one is not an error
+	Delete(name string) error
+}
+
+// System returns the operating system's credential store. Its methods fail
+// with ErrUnavailable where there is none, e.g. Linux without a keyring
+// daemon.
+func System() Store {
+	return systemStore{}
+}
+
+// Memory is a Store that keeps secrets in memory
+type Memory struct {
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+// NewMemory returns an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{secrets: map[string]string{}}
+}
+
+// Get returns the secret, or ErrNotFound
+func (m *Memory) Get(name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.secrets[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// Set creates or replaces the secret
+func (m *Memory) Set(name, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.secrets[name] = value
+	return nil
+}
+
+// Delete removes the secret
+func (m *Memory) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.secrets, name)
+	return nil
+}
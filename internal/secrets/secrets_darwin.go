@@ -0,0 +1,70 @@
+//go:build darwin
+
+package secrets
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// errSecItemNotFound is the exit status of security(1) for a missing item
+const errSecItemNotFound = 44
+
+// systemStore keeps secrets as generic passwords of service "ClaudeBar" in
+// the login keychain, through security(1)
+type systemStore struct{}
+
+// security runs security(1) with args, feeding it stdin
+func security(stdin string, args ...string) (string, error) {
+	path, err := exec.LookPath("security")
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && exit.ExitCode() == errSecItemNotFound {
+			return "", ErrNotFound
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("security %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("security %s: %w", args[0], err)
+	}
+	// Interactive mode exits 0 even when a command fails
+	if msg := strings.TrimSpace(stderr.String()); stdin != "" && msg != "" {
+		return "", fmt.Errorf("security: %s", msg)
+	}
+	return stdout.String(), nil
+}
+
+func (systemStore) Get(name string) (string, error) {
+	out, err := security("", "find-generic-password", "-s", service, "-a", name, "-w")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(out, "\n"), nil
+}
+
+func (systemStore) Set(name, value string) error {
+	// Interactive mode reads the command from stdin, keeping the secret out
+	// of the process list; hex needs no quoting
+	cmd := fmt.Sprintf("add-generic-password -U -s %s -a %s -l %s -X %s\n",
+		service, name, service, hex.EncodeToString([]byte(value)))
+	_, err := security(cmd, "-i")
+	return err
+}
+
+func (systemStore) Delete(name string) error {
+	if _, err := security("", "delete-generic-password", "-s", service, "-a", name); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
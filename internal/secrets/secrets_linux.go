@@ -0,0 +1,204 @@
+//go:build linux
+
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Secret Service API (GNOME Keyring, KWallet, KeePassXC)
+const (
+	secretsDest     = "org.freedesktop.secrets"
+	secretsPath     = "/org/freedesktop/secrets"
+	serviceIface    = "org.freedesktop.Secret.Service"
+	collectionIface = "org.freedesktop.Secret.Collection"
+	itemIface       = "org.freedesktop.Secret.Item"
+	sessionIface    = "org.freedesktop.Secret.Session"
+	promptIface     = "org.freedesktop.Secret.Prompt"
+
+	// loginCollection is used when no default collection is set
+	loginCollection = dbus.ObjectPath("/org/freedesktop/secrets/collection/login")
+	noPrompt        = dbus.ObjectPath("/")
+
+	// promptTimeout bounds how long an unlock prompt may stay unanswered
+	promptTimeout = 2 * time.Minute
+)
+
+// secret is the Secret Service's Secret struct (oayays)
+type secret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// systemStore keeps secrets as items with attributes service=ClaudeBar and
+// account=<name> in the default collection, usually the login keyring
+type systemStore struct{}
+
+func attributes(name string) map[string]string {
+	return map[string]string{"service": service, "account": name}
+}
+
+// session connects to the Secret Service and opens a session, which the
+// caller closes with the returned func
+func session() (*dbus.Conn, dbus.BusObject, dbus.ObjectPath, func(), error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, nil, "", nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	svc := conn.Object(secretsDest, secretsPath)
+
+	// The secret travels unencrypted over the session bus, like the
+	// user's other D-Bus traffic
+	var output dbus.Variant
+	var path dbus.ObjectPath
+	if err := svc.Call(serviceIface+".OpenSession", 0, "plain", dbus.MakeVariant("")).Store(&output, &path); err != nil {
+		return nil, nil, "", nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	closeSession := func() {
+		conn.Object(secretsDest, path).Call(sessionIface+".Close", 0)
+	}
+	return conn, svc, path, closeSession, nil
+}
+
+// search returns the items holding name, unlocking locked ones
+func search(conn *dbus.Conn, svc dbus.BusObject, name string) ([]dbus.ObjectPath, error) {
+	var unlocked, locked []dbus.ObjectPath
+	if err := svc.Call(serviceIface+".SearchItems", 0, attributes(name)).Store(&unlocked, &locked); err != nil {
+		return nil, fmt.Errorf("SearchItems failed: %w", err)
+	}
+	if len(locked) > 0 {
+		if err := unlock(conn, svc, locked); err != nil {
+			return nil, err
+		}
+	}
+	return append(unlocked, locked...), nil
+}
+
+// unlock unlocks items or collections, prompting the user if needed
+func unlock(conn *dbus.Conn, svc dbus.BusObject, paths []dbus.ObjectPath) error {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	if err := svc.Call(serviceIface+".Unlock", 0, paths).Store(&unlocked, &prompt); err != nil {
+		return fmt.Errorf("Unlock failed: %w", err)
+	}
+	return runPrompt(conn, prompt)
+}
+
+// runPrompt shows a prompt the service asked for, such as the keyring
+// password dialog, and waits for it to complete
+func runPrompt(conn *dbus.Conn, prompt dbus.ObjectPath) error {
+	if prompt == noPrompt || prompt == "" {
+		return nil
+	}
+
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(prompt),
+		dbus.WithMatchInterface(promptIface),
+		dbus.WithMatchMember("Completed"),
+	}
+	if err := conn.AddMatchSignal(match...); err != nil {
+		return err
+	}
+	defer conn.RemoveMatchSignal(match...)
+
+	signals := make(chan *dbus.Signal, 8)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	if call := conn.Object(secretsDest, prompt).Call(promptIface+".Prompt", 0, ""); call.Err != nil {
+		return fmt.Errorf("Prompt failed: %w", call.Err)
+	}
+
+	timeout := time.After(promptTimeout)
+	for {
+		select {
+		case sig := <-signals:
+			if sig.Path != prompt || sig.Name != promptIface+".Completed" || len(sig.Body) < 1 {
+				continue
+			}
+			if dismissed, _ := sig.Body[0].(bool); dismissed {
+				return ErrDenied
+			}
+			return nil
+		case <-timeout:
+			return fmt.Errorf("%w: the keyring prompt went unanswered", ErrDenied)
+		}
+	}
+}
+
+func (systemStore) Get(name string) (string, error) {
+	conn, svc, sess, closeSession, err := session()
+	if err != nil {
+		return "", err
+	}
+	defer closeSession()
+
+	items, err := search(conn, svc, name)
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		return "", ErrNotFound
+	}
+	var s secret
+	if err := conn.Object(secretsDest, items[0]).Call(itemIface+".GetSecret", 0, sess).Store(&s); err != nil {
+		return "", fmt.Errorf("GetSecret failed: %w", err)
+	}
+	return string(s.Value), nil
+}
+
+func (systemStore) Set(name, value string) error {
+	conn, svc, sess, closeSession, err := session()
+	if err != nil {
+		return err
+	}
+	defer closeSession()
+
+	var collection dbus.ObjectPath
+	if err := svc.Call(serviceIface+".ReadAlias", 0, "default").Store(&collection); err != nil || collection == noPrompt {
+		collection = loginCollection
+	}
+	if err := unlock(conn, svc, []dbus.ObjectPath{collection}); err != nil {
+		return err
+	}
+
+	props := map[string]dbus.Variant{
+		itemIface + ".Label":      dbus.MakeVariant(service + " " + name),
+		itemIface + ".Attributes": dbus.MakeVariant(attributes(name)),
+	}
+	s := secret{Session: sess, Value: []byte(value), ContentType: "text/plain"}
+	var item, prompt dbus.ObjectPath
+	if err := conn.Object(secretsDest, collection).Call(collectionIface+".CreateItem", 0, props, s, true).Store(&item, &prompt); err != nil {
+		return fmt.Errorf("CreateItem failed: %w", err)
+	}
+	return runPrompt(conn, prompt)
+}
+
+func (systemStore) Delete(name string) error {
+	conn, svc, _, closeSession, err := session()
+	if err != nil {
+		return err
+	}
+	defer closeSession()
+
+	items, err := search(conn, svc, name)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, item := range items {
+		var prompt dbus.ObjectPath
+		if err := conn.Object(secretsDest, item).Call(itemIface+".Delete", 0).Store(&prompt); err != nil {
+			errs = append(errs, fmt.Errorf("Delete failed: %w", err))
+			continue
+		}
+		errs = append(errs, runPrompt(conn, prompt))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,31 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMemory(t *testing.T) {
+	m := NewMemory()
+	if _, err := m.Get("key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of a missing secret: err = %v, want ErrNotFound", err)
+	}
+	if err := m.Set("key", "one"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Set("key", "two"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := m.Get("key"); err != nil || got != "two" {
+		t.Errorf("Get = %q, %v; want two", got, err)
+	}
+	if err := m.Delete("key"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Delete("key"); err != nil {
+		t.Errorf("Delete of a missing secret: %v", err)
+	}
+	if _, err := m.Get("key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,110 @@
+//go:build windows
+
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// Windows Credential Manager functions
+var (
+	advapi32       = syscall.NewLazyDLL("advapi32.dll")
+	procCredWrite  = advapi32.NewProc("CredWriteW")
+	procCredRead   = advapi32.NewProc("CredReadW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2 // this user on this machine; doesn't roam
+	errorNotFound           = 1168
+)
+
+// credential is CREDENTIALW
+type credential struct {
+	flags              uint32
+	typ                uint32
+	targetName         *uint16
+	comment            *uint16
+	lastWritten        syscall.Filetime
+	credentialBlobSize uint32
+	credentialBlob     *byte
+	persist            uint32
+	attributeCount     uint32
+	attributes         uintptr
+	targetAlias        *uint16
+	userName           *uint16
+}
+
+// systemStore keeps secrets as generic credentials named "ClaudeBar/<name>"
+type systemStore struct{}
+
+func target(name string) (*uint16, error) {
+	return syscall.UTF16PtrFromString(service + "/" + name)
+}
+
+// credError turns a failed Cred* call into ErrNotFound or a wrapped errno
+func credError(op string, err error) error {
+	var errno syscall.Errno
+	if errors.As(err, &errno) && errno == errorNotFound {
+		return ErrNotFound
+	}
+	return fmt.Errorf("%s failed: %w", op, err)
+}
+
+func (systemStore) Get(name string) (string, error) {
+	t, err := target(name)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	r, _, err := procCredRead.Call(uintptr(unsafe.Pointer(t)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		return "", credError("CredRead", err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	return string(unsafe.Slice(cred.credentialBlob, cred.credentialBlobSize)), nil
+}
+
+func (systemStore) Set(name, value string) error {
+	t, err := target(name)
+	if err != nil {
+		return err
+	}
+	user, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	blob := []byte(value)
+	cred := credential{
+		typ:                credTypeGeneric,
+		targetName:         t,
+		credentialBlobSize: uint32(len(blob)),
+		persist:            credPersistLocalMachine,
+		userName:           user,
+	}
+	if len(blob) > 0 {
+		cred.credentialBlob = &blob[0]
+	}
+	if r, _, err := procCredWrite.Call(uintptr(unsafe.Pointer(&cred)), 0); r == 0 {
+		return fmt.Errorf("CredWrite failed: %w", err)
+	}
+	return nil
+}
+
+func (systemStore) Delete(name string) error {
+	t, err := target(name)
+	if err != nil {
+		return err
+	}
+	if r, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(t)), credTypeGeneric, 0); r == 0 {
+		if err := credError("CredDelete", err); !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// bearerToken returns the token from an "Authorization: Bearer ..." header
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// tokenMatches compares tokens in constant time; an empty want never matches
+func tokenMatches(given, want string) bool {
+	return want != "" && subtle.ConstantTimeCompare([]byte(given), []byte(want)) == 1
+}
+
+// isLoopback reports whether the request comes from this machine
+func isLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// requireToken guards an API endpoint. Without an API token only this
+// machine may call it; with one, every caller must send it as a bearer
+// token, which also makes the endpoint usable from other hosts.
+func (s *Server) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := s.backend.APIToken()
+		if token == "" {
+			if !isLoopback(r) {
+				http.Error(w, "only available from this machine unless an API token is set", http.StatusForbidden)
+				return
+			}
+		} else if !tokenMatches(bearerToken(r), token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="claudebar"`)
+			http.Error(w, "missing or wrong bearer token", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"claudebar/internal/api"
+)
+
+func TestRequireToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string // configured API token
+		remote string
+		auth   string
+		want   int
+	}{
+		{"local without token", "", "127.0.0.1:5000", "", http.StatusOK},
+		{"local IPv6 without token", "", "[::1]:5000", "", http.StatusOK},
+		{"remote without token", "", "192.168.1.20:5000", "", http.StatusForbidden},
+		{"remote with bearer but none configured", "", "192.168.1.20:5000", "Bearer anything", http.StatusForbidden},
+		{"remote with token", "s3cret-token", "192.168.1.20:5000", "Bearer s3cret-token", http.StatusOK},
+		{"lowercase scheme", "s3cret-token", "192.168.1.20:5000", "bearer s3cret-token", http.StatusOK},
+		{"local with token missing", "s3cret-token", "127.0.0.1:5000", "", http.StatusUnauthorized},
+		{"wrong token", "s3cret-token", "192.168.1.20:5000", "Bearer s3cret-tokem", http.StatusUnauthorized},
+		{"basic auth", "s3cret-token", "192.168.1.20:5000", "Basic s3cret-token", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		backend := &fakeBackend{usage: &api.UsageData{}, apiToken: tt.token}
+		s := New(backend)
+
+		req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(usageCall))
+		req.RemoteAddr = tt.remote
+		if tt.auth != "" {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		rec := httptest.NewRecorder()
+		s.mux.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, rec.Code, tt.want)
+		}
+		if tt.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: 401 without WWW-Authenticate", tt.name)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"html/template"
+	"log"
+	"math"
+	"net"
+	"net/http"
+
+	"claudebar/internal/alert"
+	"claudebar/internal/api"
+)
+
+// dashboardRefresh is how often the dashboard page reloads itself, seconds
+const dashboardRefresh = 30
+
+//go:embed dashboard.html
+var dashboardHTML string
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(dashboardHTML))
+
+type dashboardStat struct {
+	Label       string
+	Utilization float64
+	Width       float64
+	Severity    string // "", "warning" or "critical"
+	ResetsIn    string // "" when the window hasn't started
+}
+
+type dashboardPage struct {
+	Stats          []dashboardStat
+	Updated        string
+	RefreshSeconds int
+}
+
+// NewToken returns a random token for the dashboard link
+func NewToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err) // crypto/rand doesn't fail on supported platforms
+	}
+	return hex.EncodeToString(b)
+}
+
+// handleDashboard serves the mobile dashboard to requests carrying the
+// token, e.g. http://192.168.1.20:47821/?token=..., or sending it as a
+// bearer token
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	given := r.URL.Query().Get("token")
+	if given == "" {
+		given = bearerToken(r)
+	}
+	if !tokenMatches(given, s.backend.DashboardToken()) {
+		http.Error(w, "missing or wrong token", http.StatusUnauthorized)
+		return
+	}
+
+	page := dashboardPage{RefreshSeconds: dashboardRefresh}
+	if usage := s.backend.Usage(); usage != nil {
+		levels := s.backend.AlertLevels()
+		page.Stats = []dashboardStat{
+			newDashboardStat("Current session", usage.FiveHour, levels),
+			newDashboardStat("Weekly", usage.SevenDay, levels),
+		}
+		page.Updated = usage.LastUpdated.Local().Format("15:04")
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := dashboardTemplate.Execute(w, page); err != nil {
+		log.Printf("Server: dashboard: %v", err)
+	}
+}
+
+func newDashboardStat(label string, stat api.UsageStat, levels alert.Levels) dashboardStat {
+	severity := ""
+	switch levels.Classify(stat.Utilization) {
+	case alert.Warning:
+		severity = "warning"
+	case alert.Critical:
+		severity = "critical"
+	}
+	resetsIn := ""
+	if !stat.ResetsAt.IsZero() {
+		resetsIn = api.TimeUntilReset(stat.ResetsAt)
+	}
+	return dashboardStat{
+		Label:       label,
+		Utilization: stat.Utilization,
+		Width:       math.Max(0, math.Min(stat.Utilization, 100)),
+		Severity:    severity,
+		ResetsIn:    resetsIn,
+	}
+}
+
+// LANAddresses returns the IPv4 addresses of the network interfaces that are
+// up, for building a dashboard link to open on another device
+func LANAddresses() []string {
+	var addrs []string
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		ifaddrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, a := range ifaddrs {
+			if ipnet, ok := a.(*net.IPNet); ok && ipnet.IP.To4() != nil {
+				addrs = append(addrs, ipnet.IP.String())
+			}
+		}
+	}
+	return addrs
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"claudebar/internal/api"
+)
+
+// FetchStats counts the usage fetches since the app started, for /metrics
+type FetchStats struct {
+	Successes int
+	Failures  map[string]int // by api.ErrorKind
+}
+
+// handleMetrics serves the latest usage and the fetch counters in the
+// Prometheus text format, for scraping into Grafana and the like
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if !s.backend.MetricsEnabled() {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetrics(w, s.backend.Usage(), s.backend.FetchStats())
+}
+
+// writeMetrics writes usage and stats as Prometheus metrics. Usage metrics
+// are left out before the first fetch, and the per-model ones for accounts
+// without per-model limits.
+func writeMetrics(w io.Writer, usage *api.UsageData, stats FetchStats) {
+	metric := func(name, typ, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+
+	if usage != nil {
+		metrics := []struct {
+			name  string
+			label string
+			stat  api.UsageStat
+			shown bool
+		}{
+			{"session", "5-hour session", usage.FiveHour, true},
+			{"weekly", "weekly", usage.SevenDay, true},
+			{"opus", "weekly Opus", usage.SevenDayOpus, !usage.SevenDayOpus.ResetsAt.IsZero()},
+			{"sonnet", "weekly Sonnet", usage.SevenDaySonnet, !usage.SevenDaySonnet.ResetsAt.IsZero()},
+		}
+		for _, m := range metrics {
+			if !m.shown {
+				continue
+			}
+			name := "claude_" + m.name + "_utilization"
+			metric(name, "gauge", "Percent of the "+m.label+" limit used.")
+			fmt.Fprintf(w, "%s %g\n", name, m.stat.Utilization)
+			if !m.stat.ResetsAt.IsZero() {
+				name = "claude_" + m.name + "_reset_timestamp_seconds"
+				metric(name, "gauge", "When the "+m.label+" limit resets, as a Unix time.")
+				fmt.Fprintf(w, "%s %d\n", name, m.stat.ResetsAt.Unix())
+			}
+		}
+		if !usage.LastUpdated.IsZero() {
+			metric("claude_last_update_timestamp_seconds", "gauge", "When usage was last fetched, as a Unix time.")
+			fmt.Fprintf(w, "claude_last_update_timestamp_seconds %d\n", usage.LastUpdated.Unix())
+		}
+	}
+
+	metric("claude_fetches_total", "counter", "Successful usage fetches since ClaudeBar started.")
+	fmt.Fprintf(w, "claude_fetches_total %d\n", stats.Successes)
+	metric("claude_fetch_errors_total", "counter", "Failed usage fetches since ClaudeBar started, by kind.")
+	kinds := make([]string, 0, len(stats.Failures))
+	for kind := range stats.Failures {
+		kinds = append(kinds, kind)
+	}
+	slices.Sort(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(w, "claude_fetch_errors_total{kind=%q} %d\n", kind, stats.Failures[kind])
+	}
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+func TestMetrics(t *testing.T) {
+	reset := time.Unix(1767225600, 0)
+	backend := &fakeBackend{
+		usage: &api.UsageData{
+			FiveHour: api.UsageStat{Utilization: 42.5, ResetsAt: reset},
+			SevenDay: api.UsageStat{Utilization: 17},
+		},
+		stats: FetchStats{Successes: 12, Failures: map[string]int{"timeout": 2, "auth": 1}},
+	}
+	s := New(backend)
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE claude_session_utilization gauge\nclaude_session_utilization 42.5\n",
+		"claude_session_reset_timestamp_seconds 1767225600\n",
+		"claude_weekly_utilization 17\n",
+		"claude_fetches_total 12\n",
+		"claude_fetch_errors_total{kind=\"auth\"} 1\nclaude_fetch_errors_total{kind=\"timeout\"} 2\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics lack %q:\n%s", want, body)
+		}
+	}
+	for _, unwanted := range []string{"claude_weekly_reset_timestamp_seconds", "claude_opus_utilization"} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("metrics have %s for a window that hasn't started:\n%s", unwanted, body)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+// maxRequestSize caps JSON-RPC request bodies
+const maxRequestSize = 64 << 10
+
+// JSON-RPC 2.0 error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeNoData         = -32000 // usage not fetched yet
+)
+
+// Launcher actions accepted by the "run" method
+const (
+	ActionRefresh     = "refresh"
+	ActionShowOverlay = "show_overlay"
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// StatResult is one usage metric in an RPC result
+type StatResult struct {
+	Utilization float64   `json:"utilization"`
+	ResetsAt    time.Time `json:"resets_at"`
+	ResetsIn    string    `json:"resets_in"` // e.g. "2h 14m"
+}
+
+// UsageResult is the result of the "usage" method
+type UsageResult struct {
+	Session     StatResult `json:"session"`
+	Weekly      StatResult `json:"weekly"`
+	LastUpdated time.Time  `json:"last_updated"`
+}
+
+// Item is a launcher result row, the result of the "query" method. Picking
+// an item with an Action should call "run" with it.
+type Item struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle,omitempty"`
+	Action   string `json:"action,omitempty"`
+}
+
+type queryParams struct {
+	Query string `json:"query"`
+}
+
+type runParams struct {
+	Action string `json:"action"`
+}
+
+// handleRPC serves JSON-RPC 2.0 calls over POST:
+//
+//	usage                    current percentages and reset times
+//	query {"query": "..."}   launcher items matching the query
+//	run   {"action": "..."}  "refresh" or "show_overlay"
+func (s *Server) handleRPC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "use POST", http.StatusMethodNotAllowed)
+		return
+	}
+	// Browsers attach an Origin to cross-site requests; launchers don't.
+	// Refusing those keeps web pages from poking the local server.
+	if r.Header.Get("Origin") != "" {
+		http.Error(w, "cross-origin requests are not allowed", http.StatusForbidden)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req rpcRequest
+	resp := rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null")}
+	switch {
+	case json.Unmarshal(body, &req) != nil:
+		resp.Error = &rpcError{codeParseError, "parse error"}
+	case req.JSONRPC != "2.0" || req.Method == "":
+		resp.Error = &rpcError{codeInvalidRequest, "invalid request"}
+	default:
+		result, err := s.call(req.Method, req.Params)
+		// Notifications (no id) are run but get no response
+		if req.ID == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		resp.ID = req.ID
+		if err != nil {
+			resp.Error = err
+		} else {
+			resp.Result = result
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// call dispatches one method
+func (s *Server) call(method string, params json.RawMessage) (any, *rpcError) {
+	switch method {
+	case "usage":
+		usage := s.backend.Usage()
+		if usage == nil {
+			return nil, &rpcError{codeNoData, "usage not fetched yet"}
+		}
+		return usageResult(usage), nil
+
+	case "query":
+		var p queryParams
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, &rpcError{codeInvalidParams, "params must be {\"query\": string}"}
+			}
+		}
+		return s.query(p.Query), nil
+
+	case "run":
+		var p runParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{codeInvalidParams, "params must be {\"action\": string}"}
+		}
+		switch p.Action {
+		case ActionRefresh:
+			s.backend.Refresh()
+		case ActionShowOverlay:
+			s.backend.ShowOverlay()
+		default:
+			return nil, &rpcError{codeInvalidParams, fmt.Sprintf("unknown action %q", p.Action)}
+		}
+		return true, nil
+	}
+	return nil, &rpcError{codeMethodNotFound, fmt.Sprintf("method %q not found", method)}
+}
+
+func usageResult(u *api.UsageData) UsageResult {
+	return UsageResult{
+		Session:     statResult(u.FiveHour),
+		Weekly:      statResult(u.SevenDay),
+		LastUpdated: u.LastUpdated,
+	}
+}
+
+func statResult(s api.UsageStat) StatResult {
+	return StatResult{
+		Utilization: s.Utilization,
+		ResetsAt:    s.ResetsAt,
+		ResetsIn:    api.TimeUntilReset(s.ResetsAt),
+	}
+}
+
+// query lists the launcher items whose title contains every word of q that
+// isn't a generic keyword, so "claude usage" lists everything and
+// "claude refresh" just the refresh action
+func (s *Server) query(q string) []Item {
+	var items []Item
+	if usage := s.backend.Usage(); usage != nil {
+		items = append(items,
+			Item{
+				Title:    fmt.Sprintf("Session %.0f%%", usage.FiveHour.Utilization),
+				Subtitle: "Resets in " + api.TimeUntilReset(usage.FiveHour.ResetsAt),
+				Action:   ActionShowOverlay,
+			},
+			Item{
+				Title:    fmt.Sprintf("Weekly %.0f%%", usage.SevenDay.Utilization),
+				Subtitle: "Resets in " + api.TimeUntilReset(usage.SevenDay.ResetsAt),
+				Action:   ActionShowOverlay,
+			},
+		)
+	}
+	items = append(items,
+		Item{Title: "Refresh usage", Subtitle: "Fetch the latest numbers now", Action: ActionRefresh},
+		Item{Title: "Show overlay", Subtitle: "Bring the ClaudeBar overlay back", Action: ActionShowOverlay},
+	)
+
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(q)) {
+		if word != "claude" && word != "claudebar" && word != "usage" {
+			words = append(words, word)
+		}
+	}
+
+	matched := items[:0]
+	for _, item := range items {
+		title := strings.ToLower(item.Title)
+		ok := true
+		for _, word := range words {
+			if !strings.Contains(title, word) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, item)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"claudebar/internal/alert"
+	"claudebar/internal/api"
+)
+
+// fakeBackend stands in for the running app
+type fakeBackend struct {
+	usage     *api.UsageData
+	apiToken  string
+	refreshed int
+	shown     int
+	stats     FetchStats
+	status    Status
+}
+
+func (b *fakeBackend) Usage() *api.UsageData     { return b.usage }
+func (b *fakeBackend) AlertLevels() alert.Levels { return alert.DefaultLevels() }
+func (b *fakeBackend) APIToken() string          { return b.apiToken }
+func (b *fakeBackend) DashboardToken() string    { return "" }
+func (b *fakeBackend) MetricsEnabled() bool      { return true }
+func (b *fakeBackend) FetchStats() FetchStats    { return b.stats }
+func (b *fakeBackend) Status() Status            { return b.status }
+func (b *fakeBackend) Refresh()                  { b.refreshed++ }
+func (b *fakeBackend) ShowOverlay()              { b.shown++ }
+
+const usageCall = `{"jsonrpc": "2.0", "id": 1, "method": "usage"}`
+
+func TestRPCMethods(t *testing.T) {
+	usage := &api.UsageData{
+		FiveHour: api.UsageStat{Utilization: 42, ResetsAt: time.Now().Add(2 * time.Hour)},
+		SevenDay: api.UsageStat{Utilization: 17, ResetsAt: time.Now().Add(72 * time.Hour)},
+	}
+	tests := []struct {
+		name      string
+		usage     *api.UsageData
+		body      string
+		wantCode  int // JSON-RPC error code, 0 for success
+		noContent bool
+	}{
+		{"usage", usage, usageCall, 0, false},
+		{"usage before first fetch", nil, usageCall, codeNoData, false},
+		{"query", usage, `{"jsonrpc": "2.0", "id": 2, "method": "query", "params": {"query": "refresh"}}`, 0, false},
+		{"run", usage, `{"jsonrpc": "2.0", "id": 3, "method": "run", "params": {"action": "refresh"}}`, 0, false},
+		{"unknown action", usage, `{"jsonrpc": "2.0", "id": 4, "method": "run", "params": {"action": "quit"}}`, codeInvalidParams, false},
+		{"unknown method", usage, `{"jsonrpc": "2.0", "id": 5, "method": "shutdown"}`, codeMethodNotFound, false},
+		{"wrong version", usage, `{"jsonrpc": "1.0", "id": 6, "method": "usage"}`, codeInvalidRequest, false},
+		{"not json", usage, `{"jsonrpc"`, codeParseError, false},
+		{"notification", usage, `{"jsonrpc": "2.0", "method": "run", "params": {"action": "show_overlay"}}`, 0, true},
+	}
+	for _, tt := range tests {
+		s := New(&fakeBackend{usage: tt.usage})
+		req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(tt.body))
+		req.RemoteAddr = "127.0.0.1:5000"
+		rec := httptest.NewRecorder()
+		s.mux.ServeHTTP(rec, req)
+
+		if tt.noContent {
+			if rec.Code != http.StatusNoContent {
+				t.Errorf("%s: status %d, want %d", tt.name, rec.Code, http.StatusNoContent)
+			}
+			continue
+		}
+		var resp struct {
+			Result json.RawMessage `json:"result"`
+			Error  *rpcError       `json:"error"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Errorf("%s: bad response %q: %v", tt.name, rec.Body.String(), err)
+			continue
+		}
+		switch {
+		case tt.wantCode == 0 && resp.Error != nil:
+			t.Errorf("%s: error %+v", tt.name, *resp.Error)
+		case tt.wantCode != 0 && (resp.Error == nil || resp.Error.Code != tt.wantCode):
+			t.Errorf("%s: error %+v, want code %d", tt.name, resp.Error, tt.wantCode)
+		}
+	}
+}
+
+func TestRPCRejectsBrowserOrigins(t *testing.T) {
+	for _, origin := range []string{"http://evil.example", "null"} {
+		s := New(&fakeBackend{usage: &api.UsageData{}})
+		req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(usageCall))
+		req.RemoteAddr = "127.0.0.1:5000"
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		s.mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("Origin %q: status %d, want %d", origin, rec.Code, http.StatusForbidden)
+		}
+	}
+}
+
+func TestRPCRequiresPost(t *testing.T) {
+	s := New(&fakeBackend{})
+	req := httptest.NewRequest(http.MethodGet, "/rpc", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /rpc status %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestRPCRunCallsBackend(t *testing.T) {
+	backend := &fakeBackend{}
+	s := New(backend)
+	for _, action := range []string{ActionRefresh, ActionShowOverlay} {
+		body := `{"jsonrpc": "2.0", "id": 1, "method": "run", "params": {"action": "` + action + `"}}`
+		req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(body))
+		req.RemoteAddr = "127.0.0.1:5000"
+		s.mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if backend.refreshed != 1 || backend.shown != 1 {
+		t.Errorf("refreshed %d, shown %d times; want once each", backend.refreshed, backend.shown)
+	}
+}
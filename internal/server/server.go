@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"claudebar/internal/alert"
+	"claudebar/internal/api"
+)
+
+// DefaultPort is where the local server listens unless configured otherwise
+const DefaultPort = 47821
+
+// shutdownTimeout bounds how long Stop waits for requests in flight
+const shutdownTimeout = 2 * time.Second
+
+var ErrAlreadyRunning = errors.New("server already running")
+
+// Backend is the running app as seen by the server. Methods are called from
+// request goroutines.
+type Backend interface {
+	Usage() *api.UsageData // latest snapshot, nil before the first fetch
+	AlertLevels() alert.Levels
+	APIToken() string       // bearer token for /rpc, "" for local clients only
+	DashboardToken() string // "" while the dashboard is off
+	MetricsEnabled() bool
+	FetchStats() FetchStats
+	Status() Status
+	Refresh()
+	ShowOverlay()
+}
+
+// Server is the optional HTTP server that lets other programs read usage
+// from the running instance and trigger actions: launcher plugins through
+// the JSON-RPC endpoint at /rpc, scripts through GET /usage, Prometheus
+// through /metrics, and phones on the same network through the
+// token-protected dashboard at /
+type Server struct {
+	backend Backend
+	mux     *http.ServeMux
+
+	mu   sync.Mutex
+	http *http.Server
+	url  string
+}
+
+// New creates a server for backend. Nothing listens until Start.
+func New(backend Backend) *Server {
+	s := &Server{backend: backend, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/rpc", s.requireToken(s.handleRPC))
+	s.mux.HandleFunc("/usage", s.requireToken(s.handleUsage))
+	s.mux.HandleFunc("/metrics", s.requireToken(s.handleMetrics))
+	s.mux.HandleFunc("/", s.handleDashboard)
+	return s
+}
+
+// Start listens on host:port and serves in the background, over HTTPS when
+// cert is set. Use "127.0.0.1" to keep the server local, or "0.0.0.0" to
+// reach it from the LAN.
+func (s *Server) Start(host string, port int, cert *tls.Certificate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.http != nil {
+		return ErrAlreadyRunning
+	}
+
+	addr := net.JoinHostPort(host, fmt.Sprint(port))
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	srv := &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	scheme := "http"
+	if cert != nil {
+		ln = tls.NewListener(ln, &tls.Config{
+			Certificates: []tls.Certificate{*cert},
+			MinVersion:   tls.VersionTLS12,
+		})
+		scheme = "https"
+	}
+	s.http = srv
+	s.url = scheme + "://" + ln.Addr().String()
+
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Server: %v", err)
+		}
+	}()
+	log.Printf("Server: listening on %s", s.url)
+	return nil
+}
+
+// Stop shuts the server down, waiting briefly for requests in flight
+func (s *Server) Stop() {
+	s.mu.Lock()
+	srv := s.http
+	s.http = nil
+	s.mu.Unlock()
+	if srv == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Server: shutdown: %v", err)
+	}
+	log.Println("Server: stopped")
+}
+
+// URL returns the scheme and address the server listens on, e.g.
+// "https://0.0.0.0:47821", or "" when stopped
+func (s *Server) URL() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.http == nil {
+		return ""
+	}
+	return s.url
+}
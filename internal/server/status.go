@@ -0,0 +1,68 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+// Status is the sign-in and fetch state of the app, see Backend.Status
+type Status struct {
+	SignedIn  bool
+	Account   string    // name of the active account, "" with only one
+	LastFetch time.Time // last successful fetch, zero before the first
+	LastError error     // why the latest fetch failed, nil after a success
+}
+
+// StatusResult is the response of GET /usage
+type StatusResult struct {
+	SignedIn  bool                  `json:"signed_in"`
+	Account   string                `json:"account,omitempty"`
+	LastFetch *time.Time            `json:"last_fetch"`           // null before the first fetch
+	LastError string                `json:"last_error,omitempty"` // e.g. "Rate limited"
+	Usage     map[string]StatResult `json:"usage"`                // by metric name, null before the first fetch
+}
+
+// handleUsage serves the latest usage with the sign-in and fetch state as
+// JSON over GET, for scripts and status bars that don't speak JSON-RPC
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "use GET", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.Header.Get("Origin") != "" {
+		http.Error(w, "cross-origin requests are not allowed", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statusResult(s.backend.Status(), s.backend.Usage()))
+}
+
+// statusResult builds the GET /usage response. The per-model metrics are
+// left out for accounts without per-model limits.
+func statusResult(st Status, usage *api.UsageData) StatusResult {
+	res := StatusResult{SignedIn: st.SignedIn, Account: st.Account}
+	if !st.LastFetch.IsZero() {
+		res.LastFetch = &st.LastFetch
+	}
+	if st.LastError != nil {
+		res.LastError, _ = api.Explain(st.LastError)
+	}
+	if usage == nil {
+		return res
+	}
+
+	res.Usage = map[string]StatResult{}
+	for _, name := range api.MetricNames {
+		stat := usage.Metric(name)
+		if (name == "opus" || name == "sonnet") && stat.ResetsAt.IsZero() {
+			continue
+		}
+		res.Usage[name] = statResult(*stat)
+	}
+	return res
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"claudebar/internal/api"
+)
+
+func getUsage(t *testing.T, backend *fakeBackend) StatusResult {
+	t.Helper()
+	s := New(backend)
+	req := httptest.NewRequest(http.MethodGet, "/usage", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+	var res StatusResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return res
+}
+
+func TestUsageEndpoint(t *testing.T) {
+	fetched := time.Unix(1767225600, 0)
+	res := getUsage(t, &fakeBackend{
+		usage: &api.UsageData{
+			FiveHour: api.UsageStat{Utilization: 42.5, ResetsAt: fetched.Add(time.Hour)},
+			SevenDay: api.UsageStat{Utilization: 17},
+		},
+		status: Status{SignedIn: true, Account: "Work", LastFetch: fetched, LastError: api.ErrRateLimited},
+	})
+
+	if !res.SignedIn || res.Account != "Work" || res.LastError != "Rate limited" {
+		t.Errorf("state = %+v", res)
+	}
+	if res.LastFetch == nil || !res.LastFetch.Equal(fetched) {
+		t.Errorf("last_fetch = %v, want %v", res.LastFetch, fetched)
+	}
+	if got := res.Usage["session"].Utilization; got != 42.5 {
+		t.Errorf("session utilization = %v, want 42.5", got)
+	}
+	if _, ok := res.Usage["opus"]; ok {
+		t.Error("usage has opus for an account without per-model limits")
+	}
+}
+
+func TestUsageEndpointBeforeFetch(t *testing.T) {
+	res := getUsage(t, &fakeBackend{})
+	if res.SignedIn || res.LastFetch != nil || res.Usage != nil {
+		t.Errorf("got %+v before the first fetch", res)
+	}
+}
@@ -0,0 +1,88 @@
+// Package statuspage reads Anthropic's public status page to tell a Claude
+// incident apart from a problem on the user's side.
+package statuspage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SummaryURL is the Statuspage summary of status.anthropic.com
+const SummaryURL = "https://status.anthropic.com/api/v2/summary.json"
+
+// timeout bounds a status check, which runs while usage can't be fetched
+const timeout = 10 * time.Second
+
+// Incident is an unresolved incident on the status page
+type Incident struct {
+	Name   string `json:"name"`   // e.g. "Elevated errors on Claude Opus"
+	Status string `json:"status"` // investigating, identified, monitoring
+	Impact string `json:"impact"` // none, minor, major, critical
+}
+
+// Status is the overall state of the status page
+type Status struct {
+	Indicator   string     // none, minor, major, critical or maintenance
+	Description string     // e.g. "Partially Degraded Service"
+	Incidents   []Incident // unresolved incidents, newest first
+}
+
+// summary is the part of summary.json that is used
+type summary struct {
+	Status struct {
+		Indicator   string `json:"indicator"`
+		Description string `json:"description"`
+	} `json:"status"`
+	Incidents []Incident `json:"incidents"`
+}
+
+// Fetch reads the status page summary at url, normally SummaryURL
+func Fetch(ctx context.Context, url string) (*Status, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status page returned %d", resp.StatusCode)
+	}
+
+	var s summary
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		return nil, fmt.Errorf("failed to parse status page: %w", err)
+	}
+	return &Status{
+		Indicator:   s.Status.Indicator,
+		Description: s.Status.Description,
+		Incidents:   s.Incidents,
+	}, nil
+}
+
+// Incident returns a line for the overlay describing the current incident,
+// e.g. "Claude is having an incident: degraded performance", or "" when
+// everything is operational
+func (s *Status) Incident() string {
+	var what string
+	switch {
+	case len(s.Incidents) > 0:
+		what = s.Incidents[0].Name
+	case s.Indicator != "" && s.Indicator != "none":
+		what = strings.ToLower(s.Description)
+	default:
+		return ""
+	}
+	if what == "" {
+		what = "service disruption"
+	}
+	return "Claude is having an incident: " + what
+}
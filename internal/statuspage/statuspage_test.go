@@ -0,0 +1,47 @@
+package statuspage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetch(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"operational", `{"status": {"indicator": "none", "description": "All Systems Operational"}, "incidents": []}`, ""},
+		{"degraded", `{"status": {"indicator": "minor", "description": "Degraded Performance"}, "incidents": []}`,
+			"Claude is having an incident: degraded performance"},
+		{"incident", `{"status": {"indicator": "major", "description": "Partial System Outage"},
+			"incidents": [{"name": "Elevated errors on claude.ai", "status": "investigating", "impact": "major"}]}`,
+			"Claude is having an incident: Elevated errors on claude.ai"},
+	}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(tt.body))
+		}))
+		s, err := Fetch(context.Background(), srv.URL)
+		srv.Close()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := s.Incident(); got != tt.want {
+			t.Errorf("%s: Incident() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFetchError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	if _, err := Fetch(context.Background(), srv.URL); err == nil {
+		t.Error("expected an error for a 502")
+	}
+}
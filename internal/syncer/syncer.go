@@ -0,0 +1,240 @@
+package syncer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"claudebar/internal/config"
+	"claudebar/internal/vault"
+)
+
+// FileName is the encrypted sync file written into the shared folder
+const FileName = "claudebar-sync.cbv"
+
+var (
+	ErrSyncDisabled = errors.New("sync is not enabled")
+	ErrNoSyncFolder = errors.New("no sync folder configured")
+)
+
+// payload is the decrypted content of the sync file
+type payload struct {
+	Version   int             `json:"version"`
+	Device    string          `json:"device"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Config    json.RawMessage `json:"config"`
+}
+
+// Syncer keeps credentials and settings identical across devices by writing an
+// encrypted snapshot into a user-chosen folder (Dropbox, OneDrive, Syncthing...)
+// and applying newer snapshots written by other devices.
+type Syncer struct {
+	config    *config.Config
+	onApplied func()
+
+	mu          sync.Mutex
+	lastModTime time.Time
+	stopChan    chan struct{}
+	running     bool
+}
+
+// New creates a syncer for the given config
+func New(cfg *config.Config) *Syncer {
+	return &Syncer{
+		config: cfg,
+	}
+}
+
+// SetAppliedCallback sets the function called after a remote snapshot was applied
+func (s *Syncer) SetAppliedCallback(callback func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onApplied = callback
+}
+
+// path returns the sync file location
+func (s *Syncer) path() (string, error) {
+	if !s.config.SyncEnabled {
+		return "", ErrSyncDisabled
+	}
+	if s.config.SyncFolder == "" {
+		return "", ErrNoSyncFolder
+	}
+	return filepath.Join(s.config.SyncFolder, FileName), nil
+}
+
+// Push encrypts the current config and writes it to the sync folder
+func (s *Syncer) Push() error {
+	path, err := s.path()
+	if err != nil {
+		return err
+	}
+
+	cfgData, err := s.config.SyncSnapshot()
+	if err != nil {
+		return err
+	}
+
+	device, _ := os.Hostname()
+	now := time.Now().UTC()
+	data, err := json.Marshal(payload{
+		Version:   1,
+		Device:    device,
+		UpdatedAt: now,
+		Config:    cfgData,
+	})
+	if err != nil {
+		return err
+	}
+
+	sealed, err := vault.Seal(s.config.SyncPassphrase, data)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file and rename so sync clients never see a partial file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, sealed, 0600); err != nil {
+		return fmt.Errorf("failed to write sync file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace sync file: %w", err)
+	}
+
+	s.config.LastSyncAt = now
+	if err := s.config.Save(); err != nil {
+		log.Printf("Warning: failed to save sync timestamp: %v", err)
+	}
+
+	s.mu.Lock()
+	if info, err := os.Stat(path); err == nil {
+		s.lastModTime = info.ModTime()
+	}
+	s.mu.Unlock()
+
+	log.Printf("Sync: pushed settings to %s", path)
+	return nil
+}
+
+// Pull reads the sync file and applies it if it is newer than the last sync.
+// Returns true if a snapshot was applied.
+func (s *Syncer) Pull() (bool, error) {
+	path, err := s.path()
+	if err != nil {
+		return false, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	sealed, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	s.lastModTime = info.ModTime()
+	s.mu.Unlock()
+
+	data, err := vault.Open(s.config.SyncPassphrase, sealed)
+	if err != nil {
+		return false, err
+	}
+
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return false, fmt.Errorf("failed to parse sync file: %w", err)
+	}
+
+	if !p.UpdatedAt.After(s.config.LastSyncAt) {
+		return false, nil
+	}
+
+	if err := s.config.ApplySynced(p.Config); err != nil {
+		return false, fmt.Errorf("failed to apply synced settings: %w", err)
+	}
+	s.config.LastSyncAt = p.UpdatedAt
+	if err := s.config.Save(); err != nil {
+		log.Printf("Warning: failed to save sync timestamp: %v", err)
+	}
+
+	log.Printf("Sync: applied settings from %s (%s)", p.Device, p.UpdatedAt.Local().Format("2006-01-02 15:04"))
+
+	s.mu.Lock()
+	callback := s.onApplied
+	s.mu.Unlock()
+	if callback != nil {
+		callback()
+	}
+	return true, nil
+}
+
+// Start watches the sync file and pulls whenever it changes
+func (s *Syncer) Start(interval time.Duration) {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = true
+	s.stopChan = make(chan struct{})
+	stop := s.stopChan
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if !s.changed() {
+					continue
+				}
+				if _, err := s.Pull(); err != nil {
+					log.Printf("Sync: pull failed: %v", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops watching the sync file
+func (s *Syncer) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running {
+		return
+	}
+	close(s.stopChan)
+	s.running = false
+}
+
+// changed reports whether the sync file was modified since it was last read or written
+func (s *Syncer) changed() bool {
+	path, err := s.path()
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !info.ModTime().Equal(s.lastModTime)
+}
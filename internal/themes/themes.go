@@ -0,0 +1,320 @@
+package themes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"claudebar/internal/api"
+	"claudebar/internal/config"
+	"claudebar/internal/palette"
+)
+
+// DirName is the folder inside the config directory that holds theme files
+const DirName = "themes"
+
+// extensions are the supported theme file formats, in lookup order
+var extensions = []string{".json", ".yaml", ".yml"}
+
+var (
+	ErrNotFound     = errors.New("theme not found")
+	ErrInvalidTheme = errors.New("invalid theme")
+)
+
+// Theme describes how the overlay looks. A theme file only needs the fields it
+// changes; everything else keeps the built-in value.
+//
+//	{
+//	  "name": "Solarized",
+//	  "colors": { "background": "#002b36e6", "bar_fill": "#268bd2",
+//	              "metrics": { "opus": "#a855f7" } },
+//	  "radii": { "window": 8 },
+//	  "text_sizes": { "header": 15 },
+//	  "bars": { "height": 12 }
+//	}
+type Theme struct {
+	Name      string    `json:"name" yaml:"name"`
+	Colors    Colors    `json:"colors" yaml:"colors"`
+	Radii     Radii     `json:"radii" yaml:"radii"`
+	TextSizes TextSizes `json:"text_sizes" yaml:"text_sizes"`
+	Bars      Bars      `json:"bars" yaml:"bars"`
+}
+
+// Colors are written as #RRGGBB or #RRGGBBAA
+type Colors struct {
+	Background  Color `json:"background" yaml:"background"`     // overlay background
+	BarTrack    Color `json:"bar_track" yaml:"bar_track"`       // empty part of a bar
+	BarFill     Color `json:"bar_fill" yaml:"bar_fill"`         // bar below the warning level
+	BarWarn     Color `json:"bar_warn" yaml:"bar_warn"`         // bar at 75% and above
+	BarCritical Color `json:"bar_critical" yaml:"bar_critical"` // bar at 90% and above
+	Text        Color `json:"text" yaml:"text"`                 // headers and labels
+	Subtext     Color `json:"subtext" yaml:"subtext"`           // reset times and status
+	Percentage  Color `json:"percentage" yaml:"percentage"`     // "42% used"
+	Separator   Color `json:"separator" yaml:"separator"`       // divider lines
+
+	// Metrics overrides BarFill per metric ("session", "weekly", "opus",
+	// "sonnet") so rows are told apart at a glance. Warning and critical
+	// colors still win.
+	Metrics map[string]Color `json:"metrics,omitempty" yaml:"metrics,omitempty"`
+}
+
+// Radii are corner radii in pixels
+type Radii struct {
+	Window float32 `json:"window" yaml:"window"`
+	Bar    float32 `json:"bar" yaml:"bar"`
+}
+
+// TextSizes are font sizes in points
+type TextSizes struct {
+	Section        float32 `json:"section" yaml:"section"`                 // "Weekly limits"
+	Header         float32 `json:"header" yaml:"header"`                   // row labels
+	Body           float32 `json:"body" yaml:"body"`                       // percentages, status
+	Caption        float32 `json:"caption" yaml:"caption"`                 // reset times
+	Compact        float32 `json:"compact" yaml:"compact"`                 // top-bar labels
+	CompactCaption float32 `json:"compact_caption" yaml:"compact_caption"` // top-bar reset times
+}
+
+// Bars are progress bar dimensions in pixels
+type Bars struct {
+	Height        float32 `json:"height" yaml:"height"`
+	Width         float32 `json:"width" yaml:"width"`
+	CompactHeight float32 `json:"compact_height" yaml:"compact_height"`
+	CompactWidth  float32 `json:"compact_width" yaml:"compact_width"`
+}
+
+// Default returns the built-in theme (Claude website palette)
+func Default() *Theme {
+	return &Theme{
+		Name: "Default",
+		Colors: Colors{
+			Background:  Color(palette.Background),
+			BarTrack:    Color(palette.Track),
+			BarFill:     Color(palette.Accent),
+			BarWarn:     Color(palette.Warning),
+			BarCritical: Color(palette.Critical),
+			Text:        Color(palette.Text),
+			Subtext:     Color(palette.Subtext),
+			Percentage:  Color(palette.Percentage),
+			Separator:   Color(palette.Track),
+		},
+		Radii: Radii{
+			Window: 0,
+			Bar:    5,
+		},
+		TextSizes: TextSizes{
+			Section:        15,
+			Header:         14,
+			Body:           13,
+			Caption:        12,
+			Compact:        11,
+			CompactCaption: 10,
+		},
+		Bars: Bars{
+			Height:        10,
+			Width:         180,
+			CompactHeight: 8,
+			CompactWidth:  60,
+		},
+	}
+}
+
+// Dir returns the theme folder, creating it if needed
+func Dir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, DirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// List returns the names of the theme files in the theme folder
+func List() ([]string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, e := range entries {
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if e.IsDir() || !supported(ext) {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Path returns the file for the named theme
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	for _, ext := range extensions {
+		p := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// Load reads the named theme from the theme folder. An empty name is the
+// built-in theme.
+func Load(name string) (*Theme, error) {
+	if name == "" {
+		return Default(), nil
+	}
+	path, err := Path(name)
+	if err != nil {
+		return nil, err
+	}
+	return LoadFile(path)
+}
+
+// LoadFile reads a theme file, filling in anything it leaves out from the
+// built-in theme
+func LoadFile(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	t := Default()
+	t.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, t)
+	default:
+		err = json.Unmarshal(data, t)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTheme, err)
+	}
+
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// validate rejects sizes that would collapse or break the layout
+func (t *Theme) validate() error {
+	positive := map[string]float32{
+		"text_sizes.section":         t.TextSizes.Section,
+		"text_sizes.header":          t.TextSizes.Header,
+		"text_sizes.body":            t.TextSizes.Body,
+		"text_sizes.caption":         t.TextSizes.Caption,
+		"text_sizes.compact":         t.TextSizes.Compact,
+		"text_sizes.compact_caption": t.TextSizes.CompactCaption,
+		"bars.height":                t.Bars.Height,
+		"bars.width":                 t.Bars.Width,
+		"bars.compact_height":        t.Bars.CompactHeight,
+		"bars.compact_width":         t.Bars.CompactWidth,
+	}
+	for field, v := range positive {
+		if v <= 0 {
+			return fmt.Errorf("%w: %s must be greater than 0", ErrInvalidTheme, field)
+		}
+	}
+	if t.Radii.Window < 0 || t.Radii.Bar < 0 {
+		return fmt.Errorf("%w: radii must not be negative", ErrInvalidTheme)
+	}
+	for metric := range t.Colors.Metrics {
+		if !slices.Contains(api.MetricNames, metric) {
+			return fmt.Errorf("%w: unknown metric %q in colors.metrics", ErrInvalidTheme, metric)
+		}
+	}
+	return nil
+}
+
+// WithMetricColors returns a copy of the theme with the given per-metric bar
+// colors (hex strings by metric name) on top of its own. Unknown metrics and
+// malformed colors are skipped and reported in the error.
+func (t *Theme) WithMetricColors(colors map[string]string) (*Theme, error) {
+	if len(colors) == 0 {
+		return t, nil
+	}
+	out := *t
+	out.Colors.Metrics = maps.Clone(t.Colors.Metrics)
+	if out.Colors.Metrics == nil {
+		out.Colors.Metrics = make(map[string]Color, len(colors))
+	}
+	var errs []error
+	for metric, text := range colors {
+		if !slices.Contains(api.MetricNames, metric) {
+			errs = append(errs, fmt.Errorf("unknown metric %q", metric))
+			continue
+		}
+		var c Color
+		if err := c.UnmarshalText([]byte(text)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", metric, err))
+			continue
+		}
+		out.Colors.Metrics[metric] = c
+	}
+	return &out, errors.Join(errs...)
+}
+
+func supported(ext string) bool {
+	for _, e := range extensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// Color is an RGBA color written as a hex string in theme files
+type Color color.RGBA
+
+// RGBA implements color.Color
+func (c Color) RGBA() (r, g, b, a uint32) {
+	return color.RGBA(c).RGBA()
+}
+
+// MarshalText writes the color as #RRGGBBAA
+func (c Color) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)), nil
+}
+
+// UnmarshalText parses #RRGGBB or #RRGGBBAA
+func (c *Color) UnmarshalText(text []byte) error {
+	s := strings.TrimPrefix(strings.TrimSpace(string(text)), "#")
+	if len(s) == 6 {
+		s += "ff"
+	}
+	var r, g, b, a uint8
+	if len(s) != 8 {
+		return fmt.Errorf("color %q must be #RRGGBB or #RRGGBBAA", text)
+	}
+	if _, err := fmt.Sscanf(s, "%02x%02x%02x%02x", &r, &g, &b, &a); err != nil {
+		return fmt.Errorf("color %q must be #RRGGBB or #RRGGBBAA", text)
+	}
+	*c = Color{r, g, b, a}
+	return nil
+}
@@ -0,0 +1,111 @@
+package themes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+	}{
+		{"empty object", `{}`, nil},
+		{"partial", `{"colors": {"bar_fill": "#268bd2"}, "radii": {"window": 8}}`, nil},
+		{"alpha color", `{"colors": {"background": "#002b36e6"}}`, nil},
+		{"not json", `{colors`, ErrInvalidTheme},
+		{"short color", `{"colors": {"text": "#fff"}}`, ErrInvalidTheme},
+		{"non-hex color", `{"colors": {"text": "#gggggg"}}`, ErrInvalidTheme},
+		{"zero text size", `{"text_sizes": {"body": 0}}`, ErrInvalidTheme},
+		{"negative bar height", `{"bars": {"height": -1}}`, ErrInvalidTheme},
+		{"negative radius", `{"radii": {"bar": -2}}`, ErrInvalidTheme},
+		{"metric color", `{"colors": {"metrics": {"opus": "#a855f7"}}}`, nil},
+		{"unknown metric", `{"colors": {"metrics": {"haiku": "#a855f7"}}}`, ErrInvalidTheme},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		path := filepath.Join(dir, "theme.json")
+		if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadFile(path)
+		if tt.wantErr == nil && err != nil {
+			t.Errorf("%s: LoadFile error = %v", tt.name, err)
+		} else if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: LoadFile error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadFileKeepsDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Solarized.json")
+	if err := os.WriteFile(path, []byte(`{"colors": {"bar_fill": "#268bd2"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	theme, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if theme.Name != "Solarized" {
+		t.Errorf("Name = %q, want the file name", theme.Name)
+	}
+	if want := (Color{0x26, 0x8b, 0xd2, 0xff}); theme.Colors.BarFill != want {
+		t.Errorf("BarFill = %v, want %v", theme.Colors.BarFill, want)
+	}
+	def := Default()
+	if theme.Colors.Text != def.Colors.Text || theme.Bars != def.Bars {
+		t.Error("fields missing from the file did not keep the built-in values")
+	}
+}
+
+func TestWithMetricColors(t *testing.T) {
+	base := Default()
+	base.Colors.Metrics = map[string]Color{"session": {1, 2, 3, 255}, "opus": {4, 5, 6, 255}}
+
+	theme, err := base.WithMetricColors(map[string]string{"opus": "#a855f7", "sonnet": "teal", "haiku": "#000000"})
+	if err == nil {
+		t.Error("expected an error for the bad entries")
+	}
+	want := map[string]Color{"session": {1, 2, 3, 255}, "opus": {0xa8, 0x55, 0xf7, 0xff}}
+	if len(theme.Colors.Metrics) != len(want) {
+		t.Errorf("Metrics = %v, want %v", theme.Colors.Metrics, want)
+	}
+	for metric, c := range want {
+		if theme.Colors.Metrics[metric] != c {
+			t.Errorf("Metrics[%s] = %v, want %v", metric, theme.Colors.Metrics[metric], c)
+		}
+	}
+	if base.Colors.Metrics["opus"] != (Color{4, 5, 6, 255}) {
+		t.Error("WithMetricColors modified the original theme")
+	}
+}
+
+func TestColorText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Color
+		ok   bool
+	}{
+		{"#268bd2", Color{0x26, 0x8b, 0xd2, 0xff}, true},
+		{"#002b36e6", Color{0x00, 0x2b, 0x36, 0xe6}, true},
+		{" 268BD2 ", Color{0x26, 0x8b, 0xd2, 0xff}, true},
+		{"#fff", Color{}, false},
+		{"#268bd2e", Color{}, false},
+		{"red", Color{}, false},
+	}
+	for _, tt := range tests {
+		var c Color
+		err := c.UnmarshalText([]byte(tt.in))
+		if (err == nil) != tt.ok || (tt.ok && c != tt.want) {
+			t.Errorf("UnmarshalText(%q) = %v, %v; want %v, ok %v", tt.in, c, err, tt.want, tt.ok)
+		}
+	}
+
+	text, _ := Color{1, 2, 3, 4}.MarshalText()
+	if string(text) != "#01020304" {
+		t.Errorf("MarshalText = %s, want #01020304", text)
+	}
+}
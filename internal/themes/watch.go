@@ -0,0 +1,121 @@
+package themes
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// watchInterval is how often the active theme file is checked for edits
+const watchInterval = 2 * time.Second
+
+// Watcher reloads the active theme whenever its file changes on disk, so
+// edits show up in the overlay without a restart
+type Watcher struct {
+	mu       sync.Mutex
+	name     string
+	modTime  time.Time
+	onChange func(*Theme)
+	stopChan chan struct{}
+	running  bool
+}
+
+// NewWatcher creates a watcher that calls onChange (from its own goroutine)
+// with every successfully reloaded theme
+func NewWatcher(onChange func(*Theme)) *Watcher {
+	return &Watcher{onChange: onChange}
+}
+
+// Watch loads the named theme, makes it the one being watched and starts
+// polling if needed. An empty name is the built-in theme, which never changes.
+func (w *Watcher) Watch(name string) (*Theme, error) {
+	t, err := Load(name)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.name = name
+	w.modTime = time.Time{}
+	if name != "" {
+		if path, err := Path(name); err == nil {
+			if info, err := os.Stat(path); err == nil {
+				w.modTime = info.ModTime()
+			}
+		}
+	}
+
+	if !w.running {
+		w.running = true
+		w.stopChan = make(chan struct{})
+		go w.loop(w.stopChan)
+	}
+	return t, nil
+}
+
+// Stop stops watching the theme file
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.running {
+		return
+	}
+	close(w.stopChan)
+	w.running = false
+}
+
+func (w *Watcher) loop(stop chan struct{}) {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.check()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// check reloads the theme if its file was modified since the last load.
+// A theme that fails to parse is logged and the current one stays in place,
+// so a half-saved file doesn't reset the overlay.
+func (w *Watcher) check() {
+	w.mu.Lock()
+	name := w.name
+	last := w.modTime
+	w.mu.Unlock()
+
+	if name == "" {
+		return
+	}
+	path, err := Path(name)
+	if err != nil {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.ModTime().Equal(last) {
+		return
+	}
+
+	w.mu.Lock()
+	if w.name != name {
+		w.mu.Unlock()
+		return
+	}
+	w.modTime = info.ModTime()
+	w.mu.Unlock()
+
+	t, err := LoadFile(path)
+	if err != nil {
+		log.Printf("Theme: failed to reload %s: %v", path, err)
+		return
+	}
+	log.Printf("Theme: reloaded %s", path)
+	if w.onChange != nil {
+		w.onChange(t)
+	}
+}
@@ -0,0 +1,190 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"sync/atomic"
+
+	"fyne.io/fyne/v2"
+
+	"claudebar/internal/palette"
+	"claudebar/internal/platform"
+	"claudebar/internal/themes"
+)
+
+// Backdrop sampling
+const (
+	backdropMargin = 16   // width of the strip sampled around the overlay, px
+	backdropStep   = 4    // sample every Nth pixel in each direction
+	lightAbove     = 0.55 // switch to dark text when the backdrop gets this bright
+	darkBelow      = 0.45 // and back to the theme's text below this
+)
+
+// Text colors used instead of the theme's when the overlay sits over a light
+// backdrop that shows through its background
+var (
+	darkText       = themes.Color(palette.TextDark)
+	darkSubtext    = themes.Color(palette.SubtextDark)
+	darkPercentage = themes.Color(palette.PercentageDark)
+)
+
+// baseTheme is the theme picked by the user; activeTheme is derived from it
+// with dark text while lightBackdrop is set
+var (
+	baseTheme     = activeTheme
+	lightBackdrop bool
+)
+
+// backdropFailed suppresses repeated capture errors in the log. Set from the
+// sampling goroutine, hence atomic.
+var backdropFailed atomic.Bool
+
+// styledTheme returns t, with dark text colors over a light backdrop
+func styledTheme(t *themes.Theme) *themes.Theme {
+	if !lightBackdrop {
+		return t
+	}
+	dark := *t
+	dark.Colors.Text = darkText
+	dark.Colors.Subtext = darkSubtext
+	dark.Colors.Percentage = darkPercentage
+	return &dark
+}
+
+// SampleBackdrop captures the screen around the overlay and switches between
+// the theme's text colors and dark ones to keep text readable over light
+// wallpapers, and with software opacity tints the background to match. The
+// overlay would capture itself, so only a strip just outside its edges is
+// sampled. Safe to call from any goroutine.
+func (o *OverlayWindow) SampleBackdrop() {
+	o.mu.RLock()
+	handle, visible := o.windowHandle, o.visible
+	o.mu.RUnlock()
+	if handle == 0 || !visible {
+		return
+	}
+
+	x, y, w, h, err := o.platform.GetWindowRect(handle)
+	if err != nil {
+		return
+	}
+	window := platform.Rect{X: x, Y: y, Width: w, Height: h}
+	region := clipToMonitor(platform.Rect{
+		X:      x - backdropMargin,
+		Y:      y - backdropMargin,
+		Width:  w + 2*backdropMargin,
+		Height: h + 2*backdropMargin,
+	}, o.platform.GetMonitorWorkAreas())
+
+	img, err := o.platform.CaptureScreen(region)
+	if err != nil {
+		if backdropFailed.CompareAndSwap(false, true) {
+			log.Printf("Backdrop: screen capture failed, keeping theme text colors: %v", err)
+		}
+		return
+	}
+	backdropFailed.Store(false)
+	behind, ok := averageColor(img, region, window)
+	if !ok {
+		return
+	}
+	fyne.Do(func() {
+		o.tintBackground(behind)
+		if o.config.AdaptiveText {
+			o.applyBackdrop(luminance(behind))
+		}
+	})
+}
+
+// applyBackdrop picks text colors for a backdrop of the given luminance,
+// restyling the overlay when they change. Call on the UI thread.
+func (o *OverlayWindow) applyBackdrop(behind float64) {
+	// What the eye sees behind the text: the overlay background blended over
+	// the backdrop at its effective opacity
+	bg := color.NRGBAModel.Convert(activeTheme.Colors.Background).(color.NRGBA)
+	alpha := o.opacity() * float64(bg.A) / 255
+	seen := alpha*luminance(bg) + (1-alpha)*behind
+
+	light := lightBackdrop
+	if seen > lightAbove {
+		light = true
+	} else if seen < darkBelow {
+		light = false
+	}
+	if light == lightBackdrop {
+		return
+	}
+	if light {
+		log.Printf("Backdrop: light (%.2f), switching to dark text", seen)
+	} else {
+		log.Printf("Backdrop: dark (%.2f), restoring theme text", seen)
+	}
+	setLightBackdrop(light)
+	o.Restyle()
+}
+
+// ResetBackdrop restores the theme's text colors, e.g. after adaptive text
+// was turned off. Call on the UI thread.
+func (o *OverlayWindow) ResetBackdrop() {
+	if lightBackdrop {
+		setLightBackdrop(false)
+		o.Restyle()
+	}
+}
+
+// setLightBackdrop switches text to dark colors (true) or the theme's own.
+// The overlay picks it up on the next Restyle.
+func setLightBackdrop(light bool) {
+	lightBackdrop = light
+	setActiveTheme(styledTheme(baseTheme))
+}
+
+// clipToMonitor limits r to the work area it overlaps most, so screen edges
+// and taskbars don't count as backdrop
+func clipToMonitor(r platform.Rect, areas []platform.Rect) platform.Rect {
+	best, bestArea := r, -1
+	for _, a := range areas {
+		c := intersect(r, a)
+		if area := c.Width * c.Height; area > bestArea {
+			best, bestArea = c, area
+		}
+	}
+	return best
+}
+
+func intersect(a, b platform.Rect) platform.Rect {
+	x0, y0 := max(a.X, b.X), max(a.Y, b.Y)
+	x1, y1 := min(a.X+a.Width, b.X+b.Width), min(a.Y+a.Height, b.Y+b.Height)
+	if x1 <= x0 || y1 <= y0 {
+		return platform.Rect{X: x0, Y: y0}
+	}
+	return platform.Rect{X: x0, Y: y0, Width: x1 - x0, Height: y1 - y0}
+}
+
+// averageColor averages the pixels of img (captured at region) that lie
+// outside window. Reports false if there were none.
+func averageColor(img image.Image, region, window platform.Rect) (color.NRGBA, bool) {
+	b := img.Bounds()
+	var r, g, bl float64
+	n := 0
+	for py := b.Min.Y; py < b.Max.Y; py += backdropStep {
+		for px := b.Min.X; px < b.Max.X; px += backdropStep {
+			if window.Contains(region.X+px-b.Min.X, region.Y+py-b.Min.Y) {
+				continue
+			}
+			c := color.NRGBAModel.Convert(img.At(px, py)).(color.NRGBA)
+			r, g, bl = r+float64(c.R), g+float64(c.G), bl+float64(c.B)
+			n++
+		}
+	}
+	if n == 0 {
+		return color.NRGBA{}, false
+	}
+	return color.NRGBA{R: uint8(r / float64(n)), G: uint8(g / float64(n)), B: uint8(bl / float64(n)), A: 255}, true
+}
+
+// luminance is the relative luminance of c, 0 (black) to 1 (white)
+func luminance(c color.NRGBA) float64 {
+	return (0.2126*float64(c.R) + 0.7152*float64(c.G) + 0.0722*float64(c.B)) / 255
+}
@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+)
+
+// Border flash at critical usage: flashPulses pulses of flashOn each, or
+// with reduced motion the outline held steady for flashHold
+const (
+	flashPulses  = 3
+	flashOn      = 300 * time.Millisecond
+	flashOff     = 200 * time.Millisecond
+	flashHold    = 2 * time.Second
+	outlineWidth = 2
+)
+
+// outlineLayer returns the outline drawn over the content while the border
+// flashes, hidden otherwise
+func (o *OverlayWindow) outlineLayer() fyne.CanvasObject {
+	if o.outline == nil {
+		o.outline = canvas.NewRectangle(color.Transparent)
+		o.outline.StrokeWidth = outlineWidth
+		o.outline.Hide()
+	}
+	o.outline.StrokeColor = activeTheme.Colors.BarCritical
+	o.outline.CornerRadius = activeTheme.Radii.Window
+	return o.outline
+}
+
+// FlashBorder pulses the overlay's border in the critical color so a
+// crossing is noticed without a notification. With reduced motion the
+// outline is shown steadily for a moment instead. Call on the UI thread.
+func (o *OverlayWindow) FlashBorder() {
+	if !o.initialized || !o.visible || o.outline == nil {
+		return
+	}
+	o.flashGen++
+	gen := o.flashGen
+	pulses, on := flashPulses, flashOn
+	if reducedMotion {
+		pulses, on = 1, flashHold
+	}
+	go func() {
+		for range pulses {
+			fyne.Do(func() { o.setOutline(gen, true) })
+			time.Sleep(on)
+			fyne.Do(func() { o.setOutline(gen, false) })
+			time.Sleep(flashOff)
+		}
+	}()
+}
+
+// setOutline shows or hides the outline unless a later flash took over
+func (o *OverlayWindow) setOutline(gen int, shown bool) {
+	if gen != o.flashGen {
+		return
+	}
+	if shown {
+		o.outline.Show()
+	} else {
+		o.outline.Hide()
+	}
+}
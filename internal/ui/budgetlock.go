@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ShowBudgetLock asks whether to open claude.ai although usage is over the
+// weekly plan, explaining why with text. Open anyway calls onOpen.
+func ShowBudgetLock(app fyne.App, text string, onOpen func()) {
+	window := app.NewWindow("ClaudeBar: Over Weekly Plan")
+	window.Resize(fyne.NewSize(380, 160))
+
+	label := widget.NewLabel(text)
+	label.Wrapping = fyne.TextWrapWord
+
+	waitBtn := widget.NewButton("Wait", func() {
+		window.Close()
+	})
+	waitBtn.Importance = widget.HighImportance
+	openBtn := widget.NewButton("Open anyway", func() {
+		window.Close()
+		onOpen()
+	})
+
+	buttons := container.NewHBox(layout.NewSpacer(), waitBtn, openBtn)
+	window.SetContent(withBackground(app, container.NewPadded(container.NewBorder(nil, buttons, nil, nil, label))))
+	window.Show()
+}
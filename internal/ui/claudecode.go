@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2/canvas"
+
+	"claudebar/internal/claudecode"
+)
+
+// claudeCodeProjects is how many of today's projects the overlay lists
+const claudeCodeProjects = 3
+
+// SetClaudeCode shows the Claude Code usage read from the local logs in its
+// own section; nil hides it. Takes effect with the next UpdateUsage.
+func (o *OverlayWindow) SetClaudeCode(s *claudecode.Summary) {
+	o.claudeCode = s
+	o.showClaudeCode()
+}
+
+// showClaudeCode pushes the Claude Code usage into the widgets of both layouts
+func (o *OverlayWindow) showClaudeCode() {
+	lines := claudeCodeLines(o.claudeCode)
+	if o.sessionRow != nil {
+		if len(o.claudeCodeTexts) != len(lines) {
+			o.claudeCodeTexts = make([]*canvas.Text, len(lines))
+			for i := range o.claudeCodeTexts {
+				o.claudeCodeTexts[i] = SectionSubtext("")
+			}
+		}
+		for i, line := range lines {
+			o.claudeCodeTexts[i].Text = line
+			o.claudeCodeTexts[i].Refresh()
+		}
+	}
+	if o.compactClaudeCode != nil {
+		o.compactClaudeCode.Text = ""
+		if s := o.claudeCode; s != nil {
+			o.compactClaudeCode.Text = fmt.Sprintf("Code %s · $%.2f", formatTokens(s.Today.Total()), s.Today.Cost)
+		}
+		o.compactClaudeCode.Refresh()
+	}
+}
+
+// claudeCodeLines describes the Claude Code usage for the vertical layout:
+// today, the last 7 days and today's busiest projects, e.g.
+// "Today: 1.2M tokens · $3.40"; none without usage
+func claudeCodeLines(s *claudecode.Summary) []string {
+	if s == nil {
+		return nil
+	}
+	lines := []string{
+		fmt.Sprintf("Today: %s tokens · $%.2f", formatTokens(s.Today.Total()), s.Today.Cost),
+		fmt.Sprintf("7 days: %s tokens · $%.2f", formatTokens(s.Week.Total()), s.Week.Cost),
+	}
+	for _, p := range s.Projects[:min(len(s.Projects), claudeCodeProjects)] {
+		lines = append(lines, fmt.Sprintf("  %s: %s · $%.2f", p.Project, formatTokens(p.Total()), p.Cost))
+	}
+	return lines
+}
+
+// formatTokens shortens a token count, e.g. "950", "12K" or "1.2M"
+func formatTokens(n int64) string {
+	switch {
+	case n >= 1e9:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1e9), ".0") + "B"
+	case n >= 1e6:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1e6), ".0") + "M"
+	case n >= 1e3:
+		return fmt.Sprintf("%.0fK", float64(n)/1e3)
+	}
+	return fmt.Sprint(n)
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+
+	"claudebar/internal/api"
+)
+
+// resetTemplate is as wide as the longest countdown api.TimeUntilReset
+// returns in a monospaced font
+const resetTemplate = "00d 00h"
+
+// resetSegment is a labelled reset countdown in the top bar, e.g.
+// "Session  2h 14m". The countdown is monospaced and right-aligned in a box
+// as wide as resetTemplate, so the bar keeps its width as the numbers change.
+type resetSegment struct {
+	label *canvas.Text
+	value *canvas.Text
+	box   *fyne.Container
+}
+
+// newResetSegment creates a segment with an empty countdown
+func newResetSegment(label string) *resetSegment {
+	size := activeTheme.TextSizes.CompactCaption
+	s := &resetSegment{}
+	s.label = canvas.NewText(label, activeTheme.Colors.Subtext)
+	s.label.TextSize = size
+	s.value = canvas.NewText("--", activeTheme.Colors.Subtext)
+	s.value.TextSize = size
+	s.value.TextStyle = fyne.TextStyle{Monospace: true}
+	s.value.Alignment = fyne.TextAlignTrailing
+
+	template := canvas.NewText(resetTemplate, nil)
+	template.TextSize = size
+	template.TextStyle = s.value.TextStyle
+	s.box = container.NewHBox(s.label, container.NewGridWrap(template.MinSize(), s.value))
+	return s
+}
+
+// Set shows the countdown to resetAt, "--" when there is none
+func (s *resetSegment) Set(label string, resetAt time.Time) {
+	value := "--"
+	if !resetAt.IsZero() {
+		value = api.TimeUntilReset(resetAt)
+	}
+	if s.label.Text != label {
+		s.label.Text = label
+		s.label.Refresh()
+	}
+	if s.value.Text != value {
+		s.value.Text = value
+		s.value.Refresh()
+	}
+}
+
+// compactResets returns the session and weekly reset segments of the top
+// bar side by side
+func (o *OverlayWindow) compactResets() fyne.CanvasObject {
+	sep := canvas.NewText("|", activeTheme.Colors.Subtext)
+	sep.TextSize = activeTheme.TextSizes.CompactCaption
+	return container.NewHBox(o.compactSessionReset.box, sep, o.compactWeeklyReset.box)
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+)
+
+// countdownHeight is the thickness of the poll countdown line, px
+const countdownHeight = 2
+
+// CountdownLine is a thin line along the bottom of the overlay that fills up
+// as the next poll approaches and empties again on each fetch
+type CountdownLine struct {
+	widget.BaseWidget
+	fraction float64 // 0 just after a poll, 1 when the next one is due
+}
+
+// NewCountdownLine creates an empty countdown line
+func NewCountdownLine() *CountdownLine {
+	c := &CountdownLine{}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+// SetFraction sets how much of the polling interval has passed (0-1)
+func (c *CountdownLine) SetFraction(f float64) {
+	if f == c.fraction {
+		return
+	}
+	c.fraction = f
+	c.Refresh()
+}
+
+func (c *CountdownLine) CreateRenderer() fyne.WidgetRenderer {
+	fill := canvas.NewRectangle(fadeColor(activeTheme.Colors.Subtext, 0.5))
+	return &countdownRenderer{line: c, fill: fill}
+}
+
+type countdownRenderer struct {
+	line *CountdownLine
+	fill *canvas.Rectangle
+	size fyne.Size
+}
+
+func (r *countdownRenderer) Layout(size fyne.Size) {
+	r.size = size
+	r.fill.Move(fyne.NewPos(0, 0))
+	r.fill.Resize(fyne.NewSize(size.Width*float32(min(max(r.line.fraction, 0), 1)), size.Height))
+}
+
+func (r *countdownRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(0, countdownHeight)
+}
+
+func (r *countdownRenderer) Refresh() {
+	r.Layout(r.size)
+	r.fill.Refresh()
+}
+
+func (r *countdownRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{r.fill}
+}
+
+func (r *countdownRenderer) Destroy() {}
+
+// pollFraction returns how much of interval has passed when the next poll is
+// due at next, clamped to 0-1
+func pollFraction(next time.Time, interval time.Duration, now time.Time) float64 {
+	if next.IsZero() || interval <= 0 {
+		return 0
+	}
+	f := 1 - float64(next.Sub(now))/float64(interval)
+	switch {
+	case f < 0:
+		return 0
+	case f > 1:
+		return 1
+	}
+	return f
+}
+
+// countdownFooter returns the countdown line for the bottom edge, or nil
+// while animations are off
+func (o *OverlayWindow) countdownFooter() fyne.CanvasObject {
+	if reducedMotion || o.countdown == nil {
+		return nil
+	}
+	return o.countdown
+}
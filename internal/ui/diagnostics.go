@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"claudebar/internal/redact"
+)
+
+// Diagnostics is a snapshot of the connection state shown to the user
+type Diagnostics struct {
+	LastSuccess       time.Time
+	LastErrorAt       time.Time
+	LastError         error
+	Status            string // short description, as shown in the overlay
+	Suggestion        string // what the user can do about it
+	ConsecutiveErrors int
+	Degraded          string        // why degraded mode is on, "" when off
+	PollInterval      time.Duration // current polling interval
+}
+
+// Report formats the diagnostics as plain text for copying into bug reports,
+// with secrets masked
+func (d Diagnostics) Report() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Last successful fetch: %s\n", formatTime(d.LastSuccess))
+	if d.Degraded != "" {
+		fmt.Fprintf(&b, "Degraded mode: %s, polling every %s\n", d.Degraded, d.PollInterval)
+	}
+	if d.LastError == nil {
+		b.WriteString("Last error: none\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "Last error: %s (%s)\n", d.Status, formatTime(d.LastErrorAt))
+	fmt.Fprintf(&b, "Details: %s\n", redact.String(d.LastError.Error()))
+	fmt.Fprintf(&b, "Consecutive failures: %d\n", d.ConsecutiveErrors)
+	fmt.Fprintf(&b, "Suggestion: %s\n", d.Suggestion)
+	return b.String()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// ShowDiagnostics opens a window describing the last fetch result
+func ShowDiagnostics(app fyne.App, d Diagnostics) {
+	window := app.NewWindow("ClaudeBar Diagnostics")
+	window.Resize(fyne.NewSize(420, 260))
+
+	statusLabel := widget.NewLabel("Connected")
+	statusLabel.TextStyle = fyne.TextStyle{Bold: true}
+	if d.LastError != nil {
+		statusLabel.SetText(d.Status)
+	}
+
+	suggestionLabel := widget.NewLabel(d.Suggestion)
+	suggestionLabel.Wrapping = fyne.TextWrapWord
+
+	report := d.Report()
+	reportLabel := widget.NewLabel(report)
+	reportLabel.Wrapping = fyne.TextWrapWord
+
+	copyBtn := widget.NewButton("Copy", func() {
+		app.Clipboard().SetContent(report)
+	})
+	closeBtn := widget.NewButton("Close", func() {
+		window.Close()
+	})
+
+	content := container.NewVBox(statusLabel)
+	if d.LastError != nil {
+		content.Add(suggestionLabel)
+	}
+	if d.Degraded != "" {
+		note := widget.NewLabel("Degraded mode (" + d.Degraded + "): animations are off and usage is polled less often, " +
+			"since drawing is slow here. Set degraded_mode to \"off\" in config.json to turn it off.")
+		note.Wrapping = fyne.TextWrapWord
+		content.Add(note)
+	}
+	content.Add(widget.NewSeparator())
+	content.Add(reportLabel)
+	content.Add(container.NewHBox(layout.NewSpacer(), copyBtn, closeBtn, layout.NewSpacer()))
+
+	window.SetContent(withBackground(app, container.NewPadded(content)))
+	window.Show()
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"claudebar/internal/api"
+)
+
+const (
+	diffMinHidden   = time.Minute     // shorter absences don't get a summary
+	diffDisplayTime = 8 * time.Second // how long the summary stays up
+)
+
+// usageDiff describes how usage moved between the snapshot seen before the
+// overlay was hidden and the current one, e.g.
+// "+12% Session, +3% Weekly since 14:20"
+func (o *OverlayWindow) usageDiff(before, after *api.UsageData, since time.Time) string {
+	parts := []string{
+		statDiff(o.label(labelSessionShort), before.FiveHour, after.FiveHour),
+		statDiff(o.label(labelWeeklyShort), before.SevenDay, after.SevenDay),
+	}
+	return strings.Join(parts, ", ") + " since " + since.Format("15:04")
+}
+
+// statDiff formats the change of one stat. A window that reset in between is
+// reported as such rather than as a negative change.
+func statDiff(name string, before, after api.UsageStat) string {
+	if after.ResetsAt.Sub(before.ResetsAt) > time.Minute && after.Utilization < before.Utilization {
+		return fmt.Sprintf("%s reset, now %.0f%%", name, after.Utilization)
+	}
+	return fmt.Sprintf("%+.0f%% %s", after.Utilization-before.Utilization, name)
+}
+
+// prepareDiff fills in the "since you last looked" line when the overlay is
+// shown again after being hidden for a while. Returns true if there is one.
+func (o *OverlayWindow) prepareDiff() bool {
+	if o.seenUsage == nil || o.lastUsage == nil || time.Since(o.seenAt) < diffMinHidden {
+		return false
+	}
+
+	o.diffText.Text = o.usageDiff(o.seenUsage, o.lastUsage, o.seenAt)
+	o.diffText.Refresh()
+	o.seenUsage = nil
+
+	time.AfterFunc(diffDisplayTime, func() {
+		fyne.Do(o.clearDiff)
+	})
+	return true
+}
+
+// clearDiff removes the "since you last looked" line
+func (o *OverlayWindow) clearDiff() {
+	if o.diffText == nil || o.diffText.Text == "" {
+		return
+	}
+	o.diffText.Text = ""
+	o.diffText.Refresh()
+	if o.initialized {
+		o.applyLayout()
+		o.snapToPosition(o.position)
+	}
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"log"
+
+	"claudebar/internal/platform"
+)
+
+// dodgePositions are the snap positions the overlay may move to when its own
+// is covered by another always-on-top window
+var dodgePositions = []platform.SnapPosition{
+	platform.SnapTop,
+	platform.SnapTopLeft,
+	platform.SnapTopRight,
+	platform.SnapLeft,
+	platform.SnapRight,
+	platform.SnapBottomLeft,
+	platform.SnapBottomRight,
+}
+
+// Dodge moves the overlay off others, the bounds of other apps' always-on-top
+// windows, to the nearest snap position none of them covers, and back to its
+// own position once that is free again. The dodged position isn't saved. A
+// floating overlay stays where it was put, and so does one with nowhere free
+// to go.
+func (o *OverlayWindow) Dodge(others []platform.Rect) {
+	home := platform.SnapPosition(o.config.OverlayPosition)
+	if home == platform.SnapNone || o.windowHandle == 0 {
+		return
+	}
+	o.mu.RLock()
+	current := o.position
+	o.mu.RUnlock()
+
+	work := o.workArea()
+	w, h := o.windowSize()
+	bounds := func(pos platform.SnapPosition) platform.Rect {
+		return o.snapBounds(pos, work, w, h)
+	}
+	pos := dodgePosition(home, current, bounds, others)
+	if pos == current {
+		return
+	}
+	if pos == home {
+		log.Printf("Overlay: %s is free again, moving back", home)
+	} else {
+		log.Printf("Overlay: another always-on-top window covers %s, moving to %s", current, pos)
+	}
+	o.moveTo(pos)
+}
+
+// snapBounds returns the bounds the overlay, now w×h, would have snapped to
+// pos in work. The size of the other layout is estimated from its last use.
+func (o *OverlayWindow) snapBounds(pos platform.SnapPosition, work platform.Rect, w, h int) platform.Rect {
+	if vertical := pos != platform.SnapTop; vertical != o.isVertical {
+		w, h = horizontalWidth, horizontalHeight
+		if vertical {
+			w, h = verticalWidth, o.verticalHeight
+			if h == 0 {
+				h = 200
+			}
+		}
+	}
+	x, y, _ := snapOrigin(pos, work, w, h)
+	return platform.Rect{X: x, Y: y, Width: w, Height: h}
+}
+
+// dodgePosition picks where the overlay goes: home when none of others
+// covers it, else current when that is a free dodge already, else the free
+// position nearest to home. With nothing free it stays at current.
+func dodgePosition(home, current platform.SnapPosition, bounds func(platform.SnapPosition) platform.Rect, others []platform.Rect) platform.SnapPosition {
+	free := func(pos platform.SnapPosition) bool {
+		r := bounds(pos)
+		for _, other := range others {
+			if r.Intersects(other) {
+				return false
+			}
+		}
+		return true
+	}
+	if free(home) {
+		return home
+	}
+	if current != home && free(current) {
+		return current
+	}
+
+	center := func(r platform.Rect) (int, int) { return r.X + r.Width/2, r.Y + r.Height/2 }
+	hx, hy := center(bounds(home))
+	best, bestDist := current, -1
+	for _, pos := range dodgePositions {
+		if pos == home || !free(pos) {
+			continue
+		}
+		x, y := center(bounds(pos))
+		if d := (x-hx)*(x-hx) + (y-hy)*(y-hy); bestDist < 0 || d < bestDist {
+			best, bestDist = pos, d
+		}
+	}
+	return best
+}
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// tappableText is a line of text that runs a function when clicked
+type tappableText struct {
+	widget.BaseWidget
+	text  *canvas.Text
+	onTap func()
+}
+
+// newTappableText creates a clickable text line in the subtext color
+func newTappableText(size float32, onTap func()) *tappableText {
+	t := &tappableText{
+		text:  canvas.NewText("", activeTheme.Colors.Subtext),
+		onTap: onTap,
+	}
+	t.text.TextSize = size
+	t.ExtendBaseWidget(t)
+	return t
+}
+
+func (t *tappableText) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(t.text)
+}
+
+// Tapped implements fyne.Tappable
+func (t *tappableText) Tapped(*fyne.PointEvent) {
+	if t.onTap != nil {
+		t.onTap()
+	}
+}
+
+// Cursor implements desktop.Cursorable, hinting that the text is clickable
+func (t *tappableText) Cursor() desktop.Cursor {
+	return desktop.PointerCursor
+}
+
+// SetText replaces the text
+func (t *tappableText) SetText(s string) {
+	if t.text.Text == s {
+		return
+	}
+	t.text.Text = s
+	t.text.Refresh()
+}
+
+// footerText describes how current the numbers are, e.g.
+// "Updated 14:32 · next in 45s"
+func footerText(updated, next, now time.Time) string {
+	if updated.IsZero() {
+		return "Not updated yet · click to refresh"
+	}
+	text := "Updated " + updated.Format("15:04")
+	if next.IsZero() {
+		return text
+	}
+	if !next.After(now) {
+		return text + " · refreshing..."
+	}
+	return text + " · next in " + shortDuration(next.Sub(now))
+}
+
+// compactFooterText is footerText for the top bar, e.g. "14:32 · 45s"
+func compactFooterText(updated, next, now time.Time) string {
+	if updated.IsZero() {
+		return "--:--"
+	}
+	text := updated.Format("15:04")
+	if next.After(now) {
+		text += " · " + shortDuration(next.Sub(now))
+	}
+	return text
+}
+
+// shortDuration formats a wait as seconds under a minute, else whole minutes
+// rounded up so the countdown never reads "0m"
+func shortDuration(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(math.Ceil(d.Seconds())))
+	}
+	return fmt.Sprintf("%dm", int(math.Ceil(d.Minutes())))
+}
+
+// SetRefreshCallback sets what clicking the footer does
+func (o *OverlayWindow) SetRefreshCallback(onRefresh func()) {
+	o.onRefresh = onRefresh
+}
+
+// SetSchedule updates the footer and countdown line with when usage was last
+// fetched, when the next poll is due (zero if unknown) and the polling
+// interval. Called every second while visible.
+func (o *OverlayWindow) SetSchedule(updated, next time.Time, interval time.Duration) {
+	o.fetchedAt = updated
+	o.nextFetch = next
+	o.pollInterval = interval
+	o.updateFooter(time.Now())
+}
+
+// updateFooter redraws both footers and the countdown line for now
+func (o *OverlayWindow) updateFooter(now time.Time) {
+	if o.countdown != nil {
+		o.countdown.SetFraction(pollFraction(o.nextFetch, o.pollInterval, now))
+	}
+	if o.footer != nil {
+		o.footer.SetText(footerText(o.fetchedAt, o.nextFetch, now))
+	}
+	if o.compactFooter != nil {
+		o.compactFooter.SetText(compactFooterText(o.fetchedAt, o.nextFetch, now))
+	}
+}
+
+// refreshClicked runs the refresh callback for a click on the footer
+func (o *OverlayWindow) refreshClicked() {
+	if o.onRefresh != nil {
+		o.onRefresh()
+	}
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"claudebar/internal/platform"
+)
+
+// Native window handle acquisition. The window manager may take a while to
+// map a freshly shown window, so lookups back off (about 12s in total) before
+// giving up.
+const (
+	handleAttempts      = 6
+	handleRetryDelay    = 200 * time.Millisecond // doubled after each miss
+	handleCheckInterval = 5 * time.Second
+)
+
+// findHandle looks up the native overlay window, retrying with backoff while
+// it is still being created
+func findHandle() (platform.WindowHandle, error) {
+	delay := handleRetryDelay
+	var err error
+	for i := 0; i < handleAttempts; i++ {
+		time.Sleep(delay)
+		var handle platform.WindowHandle
+		if handle, err = platform.GetWindowHandle(overlayTitle); err == nil {
+			return handle, nil
+		}
+		if errors.Is(err, platform.ErrNotSupported) {
+			return 0, err
+		}
+		delay *= 2
+	}
+	return 0, err
+}
+
+// acquireHandle finds the native window for the show identified by gen,
+// applies the platform features to it, then keeps re-validating the handle
+// until the overlay is hidden, shown again or recreated. A handle that stops
+// identifying a window is looked up again, so topmost and opacity come back
+// after the native window was replaced.
+func (o *OverlayWindow) acquireHandle(gen uint64) {
+	handle, err := findHandle()
+	if errors.Is(err, platform.ErrNotSupported) {
+		log.Printf("Window features unavailable: %v", err)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to get window handle after %d attempts: %v", handleAttempts, err)
+		return
+	}
+	o.adoptHandle(gen, handle)
+
+	ticker := time.NewTicker(handleCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		o.mu.RLock()
+		current := o.handleGen == gen
+		handle = o.windowHandle
+		o.mu.RUnlock()
+		if !current {
+			return
+		}
+		if handle != 0 && platform.IsWindowValid(handle) {
+			continue
+		}
+
+		log.Printf("Overlay window handle %v is no longer valid, reacquiring", handle)
+		if handle, err = findHandle(); err != nil {
+			log.Printf("Failed to reacquire window handle: %v", err)
+			return
+		}
+		o.adoptHandle(gen, handle)
+	}
+}
+
+// adoptHandle makes handle the overlay's window handle and applies the window
+// features on the UI thread, unless gen is no longer the current show
+func (o *OverlayWindow) adoptHandle(gen uint64, handle platform.WindowHandle) {
+	fyne.Do(func() {
+		o.mu.Lock()
+		current := o.handleGen == gen
+		if current {
+			o.windowHandle = handle
+		}
+		o.mu.Unlock()
+		if !current {
+			return
+		}
+
+		o.applyWindowFeatures(handle)
+		o.snapToPosition(o.position)
+	})
+}
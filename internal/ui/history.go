@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"claudebar/internal/history"
+)
+
+// heatmapCell is the side length of one hour in the heatmap
+const heatmapCell = 14
+
+// heatmapDays lists the heatmap rows, starting the week on Monday
+var heatmapDays = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday,
+	time.Friday, time.Saturday, time.Sunday,
+}
+
+// HistoryImporter reads usage exported by another tracker and adds it to the
+// history
+type HistoryImporter interface {
+	ParseImport(r io.Reader) ([]history.Cycle, error)
+	Import(cycles []history.Cycle) (int, error)
+}
+
+// ShowHistory opens a window with the recorded usage history: a heatmap of
+// the weekly quota used by weekday and hour, and a card of streaks unless
+// stats is nil. With an importer, an Import button brings in the history of
+// other trackers.
+func ShowHistory(app fyne.App, heatmap history.Heatmap, stats *history.Stats, importer HistoryImporter) {
+	window := app.NewWindow("ClaudeBar History")
+	window.Resize(fyne.NewSize(560, 420))
+
+	title := widget.NewLabel("When you use Claude")
+	title.TextStyle = fyne.TextStyle{Bold: true}
+
+	summary := widget.NewLabel("No usage recorded yet. The heatmap fills in while ClaudeBar is running.")
+	summary.Wrapping = fyne.TextWrapWord
+	if day, hour, ok := heatmap.Busiest(); ok {
+		summary.SetText(fmt.Sprintf("Busiest hour: %s %02d:00-%02d:00", day, hour, (hour+1)%24))
+	}
+
+	closeBtn := widget.NewButton("Close", func() {
+		window.Close()
+	})
+
+	content := container.NewVBox(
+		title,
+		heatmapGrid(heatmap),
+		summary,
+	)
+	if stats != nil {
+		content.Add(statsCard(*stats))
+	}
+	buttons := container.NewHBox(layout.NewSpacer())
+	if importer != nil {
+		buttons.Add(widget.NewButton("Import...", func() {
+			importHistory(importer, window)
+		}))
+	}
+	buttons.Add(closeBtn)
+	buttons.Add(layout.NewSpacer())
+	content.Add(buttons)
+	window.SetContent(withBackground(app, container.NewPadded(content)))
+	window.Show()
+}
+
+// heatmapGrid lays out one row per weekday and one column per hour, each
+// cell filled in proportion to its share of the busiest hour
+func heatmapGrid(heatmap history.Heatmap) fyne.CanvasObject {
+	highest := heatmap.Max()
+	grid := container.NewGridWithColumns(25)
+
+	grid.Add(layout.NewSpacer())
+	for hour := range 24 {
+		label := ""
+		if hour%6 == 0 {
+			label = fmt.Sprintf("%02d", hour)
+		}
+		grid.Add(canvas.NewText(label, activeTheme.Colors.Subtext))
+	}
+
+	for _, day := range heatmapDays {
+		grid.Add(canvas.NewText(day.String()[:3], activeTheme.Colors.Subtext))
+		for hour := range 24 {
+			track := canvas.NewRectangle(activeTheme.Colors.BarTrack)
+			track.SetMinSize(fyne.NewSize(heatmapCell, heatmapCell))
+			cell := container.NewStack(track)
+			if v := heatmap[day][hour]; v > 0 && highest > 0 {
+				cell.Add(canvas.NewRectangle(fadeColor(activeTheme.Colors.BarFill, v/highest)))
+			}
+			grid.Add(cell)
+		}
+	}
+	return grid
+}
+
+// statsCard lists the streak highlights under a heading
+func statsCard(stats history.Stats) fyne.CanvasObject {
+	heading := widget.NewLabel("Streaks")
+	heading.TextStyle = fyne.TextStyle{Bold: true}
+	card := container.NewVBox(widget.NewSeparator(), heading)
+
+	lines := stats.Highlights()
+	if len(lines) == 0 {
+		lines = []string{"Streaks start after the first full week."}
+	}
+	for _, line := range lines {
+		card.Add(widget.NewLabel(line))
+	}
+	return card
+}
+
+// importHistory walks through an import: pick a ccusage report or CSV, review
+// what was found, then add it to the history
+func importHistory(importer HistoryImporter, window fyne.Window) {
+	dialog.ShowFileOpen(func(file fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		if file == nil {
+			return // cancelled
+		}
+		cycles, err := importer.ParseImport(file)
+		file.Close()
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("can't import %s: %w", file.URI().Name(), err), window)
+			return
+		}
+
+		dialog.ShowConfirm("Import History", importSummary(cycles), func(ok bool) {
+			if !ok {
+				return
+			}
+			added, err := importer.Import(cycles)
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			dialog.ShowInformation("Import History",
+				fmt.Sprintf("Added %d of %d weeks. Reopen History to see them.", added, len(cycles)), window)
+		}, window)
+	}, window)
+}
+
+// importSummary describes the weeks found in an import for confirmation
+func importSummary(cycles []history.Cycle) string {
+	first, last := cycles[0], cycles[len(cycles)-1]
+	text := fmt.Sprintf("Found %d weeks from %s, %s to %s.", len(cycles), first.Source,
+		first.Start.Local().Format(time.DateOnly), last.End.Local().Format(time.DateOnly))
+	var tokens int64
+	var cost float64
+	for _, c := range cycles {
+		tokens += c.Tokens
+		cost += c.CostUSD
+	}
+	if tokens > 0 {
+		text += fmt.Sprintf(" %d tokens, $%.2f.", tokens, cost)
+	}
+	return text + "\n\nWeeks ClaudeBar has already recorded are kept and the imported ones skipped. Import?"
+}
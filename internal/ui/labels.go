@@ -0,0 +1,34 @@
+package ui
+
+import (
+	"strings"
+
+	"claudebar/internal/config"
+)
+
+// Overlay texts that can be renamed through the "labels" map in config.json,
+// e.g. {"session": "5h", "weekly": "Week"}
+const (
+	labelSession      = "session"       // vertical session row
+	labelWeekly       = "weekly"        // vertical weekly row
+	labelWeeklyHeader = "weekly_header" // section header above the weekly row
+	labelSessionShort = "session_short" // compact row and session reset timer
+	labelWeeklyShort  = "weekly_short"  // compact row and weekly reset timer
+)
+
+var defaultLabels = map[string]string{
+	labelSession:      "Current session",
+	labelWeekly:       "All models",
+	labelWeeklyHeader: "Weekly limits",
+	labelSessionShort: "Session",
+	labelWeeklyShort:  "Weekly",
+}
+
+// resolveLabel returns the configured text for a label, or the built-in one
+// when it isn't overridden
+func resolveLabel(cfg *config.Config, id string) string {
+	if text := strings.TrimSpace(cfg.Labels[id]); text != "" {
+		return text
+	}
+	return defaultLabels[id]
+}
@@ -0,0 +1,16 @@
+package ui
+
+// reducedMotion turns off overlay animations, following the OS preference
+// or the reduce_motion setting
+var reducedMotion bool
+
+// SetReducedMotion turns overlay animations off (true) or back on. Call on
+// the UI thread.
+func SetReducedMotion(on bool) {
+	reducedMotion = on
+}
+
+// ReducedMotion reports whether animations are turned off
+func ReducedMotion() bool {
+	return reducedMotion
+}
@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+)
+
+// Overlay opacity modes (config OverlayOpacityMode)
+const (
+	OpacityWindow     = ""           // fade the whole window, text included
+	OpacityBackground = "background" // fade only the background; text and bars stay opaque
+	OpacitySoftware   = "software"   // draw the fade in the overlay itself; see softwareBackground
+)
+
+// clearTheme wraps the app theme with a transparent window background, so
+// the translucent background rectangle of the overlay is what shows through
+// when the platform composites per-pixel alpha
+type clearTheme struct {
+	fyne.Theme
+}
+
+func (t *clearTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	if name == theme.ColorNameBackground {
+		return color.Transparent
+	}
+	return t.Theme.Color(name, variant)
+}
+
+// setClearBackground switches the app theme between its normal and
+// transparent window background
+func setClearBackground(app fyne.App, clear bool) {
+	current := app.Settings().Theme()
+	ct, isClear := current.(*clearTheme)
+	switch {
+	case clear && !isClear:
+		app.Settings().SetTheme(&clearTheme{Theme: current})
+	case !clear && isClear:
+		app.Settings().SetTheme(ct.Theme)
+	}
+}
+
+// withBackground puts the theme background behind content. Windows other
+// than the overlay use it so they stay opaque while the app theme's own
+// background is transparent (see setClearBackground).
+func withBackground(app fyne.App, content fyne.CanvasObject) fyne.CanvasObject {
+	t := app.Settings().Theme()
+	if ct, ok := t.(*clearTheme); ok {
+		t = ct.Theme
+	}
+	bg := canvas.NewRectangle(t.Color(theme.ColorNameBackground, app.Settings().ThemeVariant()))
+	return container.NewStack(bg, content)
+}
+
+// fadeColor scales the alpha of c by opacity
+func fadeColor(c color.Color, opacity float64) color.Color {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	n.A = uint8(float64(n.A) * opacity)
+	return n
+}
+
+// softwareBackground is the overlay background for software opacity, used
+// where the window can't be made translucent, e.g. over Remote Desktop. The
+// window stays opaque and its background is blended with the average color
+// of the desktop around it, which looks much like the real thing.
+func (o *OverlayWindow) softwareBackground() color.Color {
+	bg := color.NRGBAModel.Convert(activeTheme.Colors.Background).(color.NRGBA)
+	if o.backdrop == nil {
+		return bg
+	}
+	return blend(bg, *o.backdrop, o.opacity()*float64(bg.A)/255)
+}
+
+// tintBackground remembers the desktop color around the overlay and, with
+// software opacity, redraws the background when it changed visibly. Call on
+// the UI thread.
+func (o *OverlayWindow) tintBackground(behind color.NRGBA) {
+	if o.backdrop != nil && colorDistance(*o.backdrop, behind) < tintThreshold {
+		return
+	}
+	o.backdrop = &behind
+	if o.software.Load() && o.initialized {
+		o.applyLayout()
+	}
+}
+
+// tintThreshold is how far the backdrop color must move, summed over the
+// channels, before the software background is redrawn
+const tintThreshold = 24
+
+// blend mixes a over b with weight alpha, returning an opaque color
+func blend(a, b color.NRGBA, alpha float64) color.NRGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(alpha*float64(x) + (1-alpha)*float64(y))
+	}
+	return color.NRGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 255}
+}
+
+func colorDistance(a, b color.NRGBA) int {
+	d := func(x, y uint8) int {
+		return max(int(x)-int(y), int(y)-int(x))
+	}
+	return d(a.R, b.R) + d(a.G, b.G) + d(a.B, b.B)
+}
+
+// SoftwareOpacity reports whether the overlay draws its opacity in software,
+// which needs the desktop around it sampled. Safe to call from any goroutine.
+func (o *OverlayWindow) SoftwareOpacity() bool {
+	return o.software.Load()
+}
@@ -1,18 +1,27 @@
 package ui
 
 import (
+	"fmt"
 	"image/color"
 	"log"
+	"math"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 
+	"claudebar/internal/alert"
 	"claudebar/internal/api"
+	"claudebar/internal/budget"
+	"claudebar/internal/claudecode"
 	"claudebar/internal/config"
+	"claudebar/internal/history"
 	"claudebar/internal/platform"
+	"claudebar/pkg/widgets"
 )
 
 const (
@@ -20,6 +29,7 @@ const (
 	verticalWidth    = 420
 	horizontalWidth  = 700
 	horizontalHeight = 55
+	bannerHeight     = 3 // severity strip along the top edge
 )
 
 // OverlayWindow manages the floating usage overlay
@@ -34,24 +44,111 @@ type OverlayWindow struct {
 	isVertical bool
 
 	// Vertical layout widgets (Claude website style)
-	sessionRow      *UsageRow
-	weeklyRow       *UsageRow
-	sessionResetText *canvas.Text // session reset countdown
-	weeklyResetText  *canvas.Text // weekly reset countdown
+	sessionRow       *widgets.UsageRow
+	weeklyRow        *widgets.UsageRow
+	sessionResetText *canvas.Text      // session reset countdown
+	weeklyResetText  *canvas.Text      // weekly reset countdown
+	budgetText       *canvas.Text      // weekly plan pace
+	requestsText     *canvas.Text      // estimated requests left in the session
+	costText         *canvas.Text      // estimated dollar value of the session and week
+	opusRow          *widgets.UsageRow // models page
+	sonnetRow        *widgets.UsageRow
+
+	// Trend lines under the session and weekly rows and their points,
+	// re-applied when the widgets are recreated; see SetSparklines
+	sessionSpark  *Sparkline
+	weeklySpark   *Sparkline
+	sessionPoints []SparkPoint
+	weeklyPoints  []SparkPoint
+	sparkEnd      time.Time
+
+	// Page of the vertical layout (see pageUsage) and the weeks the trends
+	// page lists
+	page   int
+	trends []history.Cycle
 
 	// Horizontal (compact) layout widgets
-	compactSession *CompactUsageRow
-	compactWeekly  *CompactUsageRow
-	compactReset   *canvas.Text
+	compactSession *widgets.CompactUsageRow
+	compactWeekly  *widgets.CompactUsageRow
+	compactBudget  *canvas.Text
+
+	// Reset countdowns of the top bar, fixed width so it doesn't jitter
+	compactSessionReset *resetSegment
+	compactWeeklyReset  *resetSegment
 
 	// Status text (loading / error)
 	statusText *canvas.Text
 
+	// "Since you last looked" summary, shown briefly when the overlay comes back
+	diffText  *canvas.Text
+	seenUsage *api.UsageData // last data shown before the overlay was hidden
+	seenAt    time.Time
+
+	// "Updated 14:32 · next in 45s" footer; clicking it refreshes
+	footer        *tappableText
+	compactFooter *tappableText
+	fetchedAt     time.Time
+	nextFetch     time.Time
+	onRefresh     func()
+
+	// Thin line along the bottom edge counting down to the next poll
+	countdown    *CountdownLine
+	pollInterval time.Duration
+
+	// Last data shown, re-applied when the window is recreated
+	lastUsage *api.UsageData
+
+	// What the current content was built for; nil after the widgets were
+	// recreated. See UpdateUsage.
+	laidOut    *layoutKey
+	laidOutMin fyne.Size
+
+	// Weekly figures the widgets show, nil after they were recreated
+	weeklyShown *weeklyView
+
+	// Weekly budget plan, nil when the planner is off
+	budget *budget.Plan
+
+	// Usage of the accounts besides the active one and their lines in both
+	// layouts, see SetOtherAccounts
+	accounts        []AccountUsage
+	accountTexts    []*canvas.Text
+	compactAccounts *canvas.Text
+
+	// Claude Code usage from the local logs and its lines in both layouts,
+	// see SetClaudeCode
+	claudeCode        *claudecode.Summary
+	claudeCodeTexts   []*canvas.Text
+	compactClaudeCode *canvas.Text
+
+	// Outline pulsed by FlashBorder, and the flash in progress
+	outline  *canvas.Rectangle
+	flashGen int
+
 	// State
 	mu           sync.RWMutex
 	visible      bool
 	initialized  bool
 	windowHandle platform.WindowHandle
+	handleGen    uint64 // bumped on every show, hide and recreate; see acquireHandle
+
+	// verticalHeight is the window height in the vertical layout last
+	// snapped, for placing it while in the horizontal one; see Dodge
+	verticalHeight int
+
+	// backgroundOnly is set while opacity is applied to the background
+	// rectangle rather than the whole window
+	backgroundOnly bool
+
+	// clickThrough lets mouse events pass to the windows below
+	clickThrough bool
+
+	// software is set while opacity is drawn into the background instead
+	// (see softwareBackground), backdrop is the desktop color it is blended
+	// with, and layeredFailed records that window transparency didn't work
+	software      atomic.Bool // read by the backdrop sampling goroutine
+	backdrop      *color.NRGBA
+	layeredFailed bool
 }
 
 // NewOverlayWindow creates a new overlay window
@@ -92,11 +189,35 @@ func (o *OverlayWindow) Setup() error {
 
 // applyLayout sets the window content and resizes to fit content exactly.
 func (o *OverlayWindow) applyLayout() {
-	bg := canvas.NewRectangle(color.RGBA{32, 33, 35, 240})
+	content, size := o.buildContent()
+	o.window.SetContent(content)
+	o.window.Resize(size)
+	key := o.currentLayoutKey()
+	o.laidOut = &key
+	o.laidOutMin = content.MinSize()
+	if o.isVertical {
+		log.Printf("Layout applied: vertical (%.0fx%.0f)", size.Width, size.Height)
+	} else {
+		log.Printf("Layout applied: horizontal (%.0fx%.0f)", size.Width, size.Height)
+	}
+}
+
+// buildContent builds the content for the current layout and returns it with
+// the window size that fits it.
+func (o *OverlayWindow) buildContent() (fyne.CanvasObject, fyne.Size) {
+	var bgColor color.Color = activeTheme.Colors.Background
+	switch {
+	case o.software.Load():
+		bgColor = o.softwareBackground()
+	case o.backgroundOnly:
+		bgColor = fadeColor(bgColor, o.opacity())
+	}
+	bg := canvas.NewRectangle(bgColor)
+	bg.CornerRadius = activeTheme.Radii.Window
 
 	if o.isVertical {
-		o.buildVerticalContent(bg)
-		minSize := o.window.Content().MinSize()
+		content := o.buildVerticalContent(bg)
+		minSize := content.MinSize()
 		w := minSize.Width
 		if w < float32(verticalWidth) {
 			w = float32(verticalWidth)
@@ -105,66 +226,146 @@ func (o *OverlayWindow) applyLayout() {
 		if h < 50 {
 			h = 200
 		}
-		o.window.Resize(fyne.NewSize(w, h))
-		log.Printf("Layout applied: vertical (%.0fx%.0f)", w, h)
-	} else {
-		o.buildHorizontalContent(bg)
-		minSize := o.window.Content().MinSize()
-		// Use content min size — compact widgets have proper MinSize set
-		w := minSize.Width
-		h := minSize.Height
-		if h < 30 {
-			h = 30
-		}
-		o.window.Resize(fyne.NewSize(w, h))
-		log.Printf("Layout applied: horizontal (%.0fx%.0f)", w, h)
+		return content, fyne.NewSize(w, h)
 	}
+
+	content := o.buildHorizontalContent(bg)
+	minSize := content.MinSize()
+	// Use content min size — compact widgets have proper MinSize set
+	h := minSize.Height
+	if h < 30 {
+		h = 30
+	}
+	return content, fyne.NewSize(minSize.Width, h)
 }
 
 // createVerticalWidgets creates the full Claude-style vertical layout
 func (o *OverlayWindow) createVerticalWidgets() {
-	o.sessionRow = NewUsageRow("Current session")
-	o.weeklyRow = NewUsageRow("All models")
-	o.sessionResetText = canvas.NewText("", colorGray)
-	o.sessionResetText.TextSize = 13
+	style := widgets.ParseBarStyle(o.config.BarStyle, false)
+	o.sessionRow = widgets.NewUsageRow(o.label(labelSession), widgets.MetricSession, style)
+	o.weeklyRow = widgets.NewUsageRow(o.label(labelWeekly), widgets.MetricWeekly, style)
+	o.opusRow = widgets.NewUsageRow("Opus", widgets.MetricOpus, style)
+	o.sonnetRow = widgets.NewUsageRow("Sonnet", widgets.MetricSonnet, style)
+	for _, row := range []*widgets.UsageRow{o.sessionRow, o.weeklyRow, o.opusRow, o.sonnetRow} {
+		row.SetPrecision(o.config.Decimals())
+	}
+	o.sessionSpark = NewSparkline(widgets.MetricSession, SessionSparkSpan)
+	o.sessionSpark.SetPoints(o.sessionPoints, o.sparkEnd)
+	o.weeklySpark = NewSparkline(widgets.MetricWeekly, WeeklySparkSpan)
+	o.weeklySpark.SetPoints(o.weeklyPoints, o.sparkEnd)
+	o.sessionResetText = canvas.NewText("", activeTheme.Colors.Subtext)
+	o.sessionResetText.TextSize = activeTheme.TextSizes.Body
 	o.sessionResetText.TextStyle = fyne.TextStyle{Bold: true}
-	o.weeklyResetText = canvas.NewText("", colorGray)
-	o.weeklyResetText.TextSize = 12
-	o.statusText = canvas.NewText("Loading...", colorGray)
-	o.statusText.TextSize = 13
+	o.weeklyResetText = canvas.NewText("", activeTheme.Colors.Subtext)
+	o.weeklyResetText.TextSize = activeTheme.TextSizes.Caption
+	o.budgetText = canvas.NewText("", activeTheme.Colors.Subtext)
+	o.budgetText.TextSize = activeTheme.TextSizes.Caption
+	o.requestsText = canvas.NewText("", activeTheme.Colors.Subtext)
+	o.requestsText.TextSize = activeTheme.TextSizes.Caption
+	o.costText = canvas.NewText("", activeTheme.Colors.Subtext)
+	o.costText.TextSize = activeTheme.TextSizes.Caption
+	o.statusText = canvas.NewText("Loading...", activeTheme.Colors.Subtext)
+	o.statusText.TextSize = activeTheme.TextSizes.Body
 	o.statusText.Alignment = fyne.TextAlignCenter
+	o.diffText = canvas.NewText("", activeTheme.Colors.Text)
+	o.diffText.TextSize = activeTheme.TextSizes.Caption
+	o.diffText.Alignment = fyne.TextAlignCenter
+	o.footer = newTappableText(activeTheme.TextSizes.Caption, o.refreshClicked)
+	o.countdown = NewCountdownLine()
+	o.updateFooter(time.Now())
+	o.accountTexts = nil
+	o.showAccounts()
+	o.claudeCodeTexts = nil
+	o.showClaudeCode()
+	o.laidOut, o.weeklyShown = nil, nil
 }
 
 // createCompactWidgets creates the minimal horizontal layout
 func (o *OverlayWindow) createCompactWidgets() {
-	o.compactSession = NewCompactUsageRow("Session")
-	o.compactWeekly = NewCompactUsageRow("Weekly")
-	o.compactReset = canvas.NewText("", colorGray)
-	o.compactReset.TextSize = 10
-	o.compactReset.SetMinSize(fyne.NewSize(260, 12)) // ensure space for "Session Xh Xm | Weekly Xd Xh"
+	style := widgets.ParseBarStyle(o.config.CompactBarStyle, true)
+	o.compactSession = widgets.NewCompactUsageRow(o.label(labelSessionShort), widgets.MetricSession, style)
+	o.compactWeekly = widgets.NewCompactUsageRow(o.label(labelWeeklyShort), widgets.MetricWeekly, style)
+	o.compactSessionReset = newResetSegment(o.label(labelSessionShort))
+	o.compactWeeklyReset = newResetSegment(o.label(labelWeeklyShort))
+	o.compactBudget = canvas.NewText("", activeTheme.Colors.Subtext)
+	o.compactBudget.TextSize = activeTheme.TextSizes.CompactCaption
+	o.compactAccounts = canvas.NewText("", activeTheme.Colors.Subtext)
+	o.compactAccounts.TextSize = activeTheme.TextSizes.CompactCaption
+	o.compactClaudeCode = canvas.NewText("", activeTheme.Colors.Subtext)
+	o.compactClaudeCode.TextSize = activeTheme.TextSizes.CompactCaption
+	o.compactFooter = newTappableText(activeTheme.TextSizes.CompactCaption, o.refreshClicked)
+	o.updateFooter(time.Now())
+	o.showAccounts()
+	o.showClaudeCode()
+	o.laidOut, o.weeklyShown = nil, nil
 }
 
 // buildVerticalContent builds the full Claude-style layout
-func (o *OverlayWindow) buildVerticalContent(bg *canvas.Rectangle) {
+func (o *OverlayWindow) buildVerticalContent(bg *canvas.Rectangle) fyne.CanvasObject {
 	items := []fyne.CanvasObject{}
 
 	// Show status text if visible (loading/error state)
 	if o.statusText != nil && o.statusText.Text != "" {
 		items = append(items, o.statusText)
 	}
+	if o.diffText != nil && o.diffText.Text != "" {
+		items = append(items, o.diffText)
+	}
+	items = append(items, o.pageItems()...)
+	items = append(items, o.pageIndicator(), o.footer)
+
+	content := container.NewVBox(items...)
+	padded := container.NewPadded(content)
+	return container.NewStack(bg, container.NewBorder(o.severityBanner(), o.countdownFooter(), nil, nil, padded), o.outlineLayer())
+}
+
+// usageItems lists the session and weekly usage, the first page
+func (o *OverlayWindow) usageItems() []fyne.CanvasObject {
+	var items []fyne.CanvasObject
 
 	// Current session section
 	if o.config.IsStatVisible("session") {
 		items = append(items, o.sessionRow.GetContainer())
+		if o.showSpark(o.sessionSpark) {
+			items = append(items, o.sessionSpark)
+		}
+		if o.requestsText.Text != "" {
+			items = append(items, o.requestsText)
+		}
 		items = append(items, Separator())
 	}
 
 	// Weekly limits section header
 	if o.config.IsStatVisible("weekly") {
-		weeklyHeader := SectionHeader("Weekly limits")
+		weeklyHeader := SectionHeader(o.label(labelWeeklyHeader))
 		items = append(items, weeklyHeader)
 		items = append(items, canvas.NewRectangle(color.Transparent)) // small spacer
 		items = append(items, o.weeklyRow.GetContainer())
+		if o.showSpark(o.weeklySpark) {
+			items = append(items, o.weeklySpark)
+		}
+		if o.budget != nil {
+			items = append(items, o.budgetText)
+		}
+	}
+	if o.costText.Text != "" {
+		items = append(items, o.costText)
+	}
+
+	// Other accounts
+	if len(o.accountTexts) > 0 {
+		items = append(items, Separator())
+		for _, t := range o.accountTexts {
+			items = append(items, t)
+		}
+	}
+
+	// Claude Code on this machine
+	if len(o.claudeCodeTexts) > 0 {
+		items = append(items, Separator(), SectionHeader("Claude Code"))
+		for _, t := range o.claudeCodeTexts {
+			items = append(items, t)
+		}
 	}
 
 	// Reset timers
@@ -177,37 +378,124 @@ func (o *OverlayWindow) buildVerticalContent(bg *canvas.Rectangle) {
 			items = append(items, o.weeklyResetText)
 		}
 	}
-
-	content := container.NewVBox(items...)
-	padded := container.NewPadded(content)
-	stack := container.NewStack(bg, padded)
-	o.window.SetContent(stack)
+	return items
 }
 
 // buildHorizontalContent builds the compact top-bar layout
-func (o *OverlayWindow) buildHorizontalContent(bg *canvas.Rectangle) {
+func (o *OverlayWindow) buildHorizontalContent(bg *canvas.Rectangle) fyne.CanvasObject {
 	items := []fyne.CanvasObject{}
 
 	// Show status text if visible (loading/error state)
 	if o.statusText != nil && o.statusText.Text != "" {
 		items = append(items, o.statusText)
 	}
+	if o.diffText != nil && o.diffText.Text != "" {
+		items = append(items, o.diffText)
+	}
 
 	if o.config.IsStatVisible("session") {
 		items = append(items, o.compactSession.GetContainer())
 	}
 	if o.config.IsStatVisible("weekly") {
 		items = append(items, o.compactWeekly.GetContainer())
+		if o.budget != nil {
+			items = append(items, o.compactBudget)
+		}
+	}
+	if len(o.accounts) > 0 {
+		items = append(items, o.compactAccounts)
+	}
+	if o.claudeCode != nil {
+		items = append(items, o.compactClaudeCode)
 	}
 	if o.config.IsStatVisible("reset") {
-		items = append(items, o.compactReset)
+		items = append(items, o.compactResets())
 	}
+	items = append(items, o.compactFooter)
 
 	row := container.NewHBox(items...)
 	centered := container.NewCenter(row)
 	padded := container.NewPadded(centered)
-	stack := container.NewStack(bg, padded)
-	o.window.SetContent(stack)
+	return container.NewStack(bg, container.NewBorder(o.severityBanner(), o.countdownFooter(), nil, nil, padded), o.outlineLayer())
+}
+
+// severity classifies the highest of the session and weekly usage shown
+func (o *OverlayWindow) severity() alert.Severity {
+	if o.lastUsage == nil {
+		return alert.None
+	}
+	return alertLevels.Classify(math.Max(o.lastUsage.FiveHour.Utilization, o.lastUsage.SevenDay.Utilization))
+}
+
+// severityBanner returns a strip in the warning or critical color while the
+// highest usage is at that level, or nil
+func (o *OverlayWindow) severityBanner() fyne.CanvasObject {
+	if o.lastUsage == nil {
+		return nil
+	}
+
+	var c color.Color
+	switch o.severity() {
+	case alert.Critical:
+		c = activeTheme.Colors.BarCritical
+	case alert.Warning:
+		c = activeTheme.Colors.BarWarn
+	default:
+		return nil
+	}
+
+	banner := canvas.NewRectangle(c)
+	banner.SetMinSize(fyne.NewSize(0, bannerHeight))
+	return banner
+}
+
+// Recreate replaces the native window with a fresh one, keeping position,
+// visibility and the last displayed data. Used to recover from a window that
+// stopped responding or ended up in a broken state.
+func (o *OverlayWindow) Recreate() {
+	o.mu.Lock()
+	old := o.window
+	visible := o.visible
+	o.initialized = false
+	o.windowHandle = 0
+	o.handleGen++
+	o.mu.Unlock()
+
+	if err := o.Setup(); err != nil {
+		log.Printf("Failed to recreate overlay window: %v", err)
+		return
+	}
+	if old != nil {
+		old.Close()
+	}
+
+	if o.lastUsage != nil {
+		o.UpdateUsage(o.lastUsage)
+	}
+	if visible {
+		o.Show()
+	}
+	log.Println("Overlay window recreated")
+}
+
+// Restyle rebuilds the widgets with the current theme (see SetTheme) and
+// re-applies the last displayed data
+func (o *OverlayWindow) Restyle() {
+	if !o.initialized {
+		return
+	}
+	status := o.statusText.Text
+
+	o.createVerticalWidgets()
+	o.createCompactWidgets()
+	o.statusText.Text = status
+
+	if o.lastUsage != nil {
+		o.UpdateUsage(o.lastUsage)
+		return
+	}
+	o.applyLayout()
+	o.snapToPosition(o.position)
 }
 
 // Show displays the overlay window
@@ -219,27 +507,29 @@ func (o *OverlayWindow) Show() {
 		return
 	}
 
+	if !o.visible && o.prepareDiff() {
+		o.applyLayout()
+	}
 	o.window.Show()
 	o.visible = true
 
-	// Apply Windows-specific features and snap to position after window is shown
-	go func() {
-		time.Sleep(200 * time.Millisecond)
-		o.applyWindowFeatures()
-		// Snap to configured position on first show
-		time.Sleep(100 * time.Millisecond)
-		fyne.Do(func() {
-			o.snapToPosition(o.position)
-		})
-	}()
+	// Apply platform features and snap to position once the native window
+	// can be found
+	o.handleGen++
+	go o.acquireHandle(o.handleGen)
 }
 
 // Hide hides the overlay window
 func (o *OverlayWindow) Hide() {
 	o.mu.Lock()
 	defer o.mu.Unlock()
+	if o.visible && o.lastUsage != nil {
+		o.seenUsage = o.lastUsage
+		o.seenAt = time.Now()
+	}
 	o.window.Hide()
 	o.visible = false
+	o.handleGen++
 }
 
 // Toggle toggles overlay visibility
@@ -262,32 +552,109 @@ func (o *OverlayWindow) IsVisible() bool {
 	return o.visible
 }
 
-// applyWindowFeatures applies Windows-specific features
-func (o *OverlayWindow) applyWindowFeatures() {
-	handle, err := platform.GetWindowHandle(overlayTitle)
-	if err != nil {
-		log.Printf("Failed to get window handle: %v", err)
-		return
+// SetClickThrough lets mouse clicks pass through the overlay to the windows
+// below it (true), or makes it take them again. It applies to the native
+// window as soon as there is one.
+func (o *OverlayWindow) SetClickThrough(on bool) error {
+	o.mu.Lock()
+	o.clickThrough = on
+	handle := o.windowHandle
+	o.mu.Unlock()
+	if handle == 0 {
+		return nil
 	}
-	o.windowHandle = handle
+	return o.platform.SetClickThrough(handle, on)
+}
 
+// ClickThrough reports whether clicks pass through the overlay
+func (o *OverlayWindow) ClickThrough() bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.clickThrough
+}
+
+// applyWindowFeatures applies always-on-top, click-through and opacity to
+// the native window
+func (o *OverlayWindow) applyWindowFeatures(handle platform.WindowHandle) {
 	if err := o.platform.SetAlwaysOnTop(handle, true); err != nil {
 		log.Printf("Failed to set always on top: %v", err)
 	}
+	if o.ClickThrough() {
+		if err := o.platform.SetClickThrough(handle, true); err != nil {
+			log.Printf("Failed to set click-through: %v", err)
+		}
+	}
+
+	o.applyOpacity()
 
-	opacity := o.config.OverlayOpacity
+	log.Printf("Window features applied (handle: %v, opacity: %.2f)", handle, o.opacity())
+}
+
+// opacity returns the configured opacity for the current position, or the
+// default when it is out of range
+func (o *OverlayWindow) opacity() float64 {
+	opacity := o.config.OpacityFor(string(o.position))
 	if opacity <= 0 || opacity > 1 {
 		opacity = 0.85
 	}
-	if err := o.platform.SetTransparency(handle, opacity); err != nil {
-		log.Printf("Failed to set transparency: %v", err)
+	return opacity
+}
+
+// applyOpacity fades the whole window, or in background mode only the
+// background rectangle. Platforms that can't composite per-pixel alpha fall
+// back to fading the whole window. Over Remote Desktop, when asked to, or
+// once window transparency failed, the fade is drawn in software instead.
+func (o *OverlayWindow) applyOpacity() {
+	software := o.config.OverlayOpacityMode == OpacitySoftware || o.layeredFailed || o.platform.RemoteSession()
+	backgroundOnly := false
+	if !software && o.config.OverlayOpacityMode == OpacityBackground {
+		if err := o.platform.SetPerPixelAlpha(o.windowHandle, true); err != nil {
+			log.Printf("Background-only opacity unavailable, fading the whole window: %v", err)
+		} else {
+			backgroundOnly = true
+		}
+	} else if o.backgroundOnly {
+		if err := o.platform.SetPerPixelAlpha(o.windowHandle, false); err != nil {
+			log.Printf("Failed to disable per-pixel alpha: %v", err)
+		}
 	}
 
-	log.Printf("Window features applied (handle: %v, opacity: %.2f)", handle, opacity)
+	if !backgroundOnly {
+		opacity := o.opacity()
+		if software {
+			opacity = 1
+		}
+		if err := o.platform.SetTransparency(o.windowHandle, opacity); err != nil && !software {
+			log.Printf("Window transparency failed, drawing opacity in software: %v", err)
+			o.layeredFailed = true
+			software = true
+		}
+	}
+	if software && !o.software.Load() {
+		log.Println("Overlay opacity: software (remote session, setting, or no window transparency)")
+	}
+
+	// The background rectangle carries the opacity in background and
+	// software mode, so the content is rebuilt whenever the mode or the
+	// value changes
+	if backgroundOnly || o.backgroundOnly || software || o.software.Load() {
+		o.backgroundOnly = backgroundOnly
+		o.software.Store(software)
+		setClearBackground(o.app, backgroundOnly)
+		o.applyLayout()
+	}
 }
 
-// SnapTo snaps the overlay to a screen position
+// SnapTo snaps the overlay to a screen position, switching to that
+// position's opacity
 func (o *OverlayWindow) SnapTo(pos platform.SnapPosition) {
+	o.moveTo(pos)
+	o.config.SetOverlayPosition(string(pos))
+}
+
+// moveTo snaps the overlay to pos like SnapTo, without saving the position
+func (o *OverlayWindow) moveTo(pos platform.SnapPosition) {
+	oldOpacity := o.opacity()
 	o.mu.Lock()
 	o.position = pos
 	o.mu.Unlock()
@@ -299,7 +666,9 @@ func (o *OverlayWindow) SnapTo(pos platform.SnapPosition) {
 	o.applyLayout()
 
 	o.snapToPosition(pos)
-	o.config.SetOverlayPosition(string(pos))
+	if o.windowHandle != 0 && o.opacity() != oldOpacity {
+		o.applyOpacity()
+	}
 }
 
 // windowSize returns the actual window frame size via platform API.
@@ -327,60 +696,116 @@ func (o *OverlayWindow) windowSize() (int, int) {
 
 // snapToPosition moves the window to the snap position using Windows API
 func (o *OverlayWindow) snapToPosition(pos platform.SnapPosition) {
-	workX, workY, workW, workH := o.platform.GetWorkArea()
-	cw, ch := o.windowSize()
+	w, h := o.windowSize()
+	if o.isVertical {
+		o.verticalHeight = h
+	}
+	x, y, ok := snapOrigin(pos, o.workArea(), w, h)
+	if !ok {
+		x, y = o.validFloatingPosition(w, h)
+	}
+
+	log.Printf("Snapping to %s at (%d, %d) size %dx%d", pos, x, y, w, h)
+
+	// Actually move the window using Windows API
+	if o.windowHandle != 0 {
+		if err := o.platform.MoveAndResizeWindow(o.windowHandle, x, y, w, h); err != nil {
+			log.Printf("Failed to move window: %v", err)
+		}
+	}
+	o.config.SetOverlayCoords(x, y)
+}
 
-	var x, y, w, h int
+// workArea returns the primary work area, where the overlay snaps
+func (o *OverlayWindow) workArea() platform.Rect {
+	x, y, w, h := o.platform.GetWorkArea()
+	return platform.Rect{X: x, Y: y, Width: w, Height: h}
+}
 
+// snapOrigin returns where a w×h window snapped to pos goes in work, and
+// false for a floating window
+func snapOrigin(pos platform.SnapPosition, work platform.Rect, w, h int) (x, y int, ok bool) {
+	workX, workY, workW, workH := work.X, work.Y, work.Width, work.Height
 	switch pos {
 	case platform.SnapLeft:
-		w, h = cw, ch
-		x = workX
-		y = workY + (workH-h)/2
+		return workX, workY + (workH-h)/2, true
 	case platform.SnapRight:
-		w, h = cw, ch
-		x = workX + workW - w
-		y = workY + (workH-h)/2
+		return workX + workW - w, workY + (workH-h)/2, true
 	case platform.SnapTop:
-		w, h = cw, ch
-		x = workX + (workW-w)/2
-		y = workY
+		return workX + (workW-w)/2, workY, true
 	case platform.SnapTopLeft:
-		w, h = cw, ch
-		x = workX
-		y = workY
+		return workX, workY, true
 	case platform.SnapTopRight:
-		w, h = cw, ch
-		x = workX + workW - w
-		y = workY
+		return workX + workW - w, workY, true
 	case platform.SnapBottomLeft:
-		w, h = cw, ch
-		x = workX
-		y = workY + workH - h
+		return workX, workY + workH - h, true
 	case platform.SnapBottomRight:
-		w, h = cw, ch
-		x = workX + workW - w
-		y = workY + workH - h
-	default:
-		w, h = cw, ch
-		if o.config.OverlayX >= 0 && o.config.OverlayY >= 0 {
-			x = o.config.OverlayX
-			y = o.config.OverlayY
-		} else {
-			x = workX + (workW-w)/2
-			y = workY + (workH-h)/2
-		}
+		return workX + workW - w, workY + workH - h, true
 	}
+	return 0, 0, false
+}
 
-	log.Printf("Snapping to %s at (%d, %d) size %dx%d", pos, x, y, w, h)
+// validFloatingPosition returns the saved floating position, pulled fully onto
+// the monitor it is on. Positions that are unset or not on any connected
+// display (e.g. after undocking a laptop) are re-centered on the primary work area.
+func (o *OverlayWindow) validFloatingPosition(w, h int) (int, int) {
+	workX, workY, workW, workH := o.platform.GetWorkArea()
+	centerX, centerY := workX+(workW-w)/2, workY+(workH-h)/2
 
-	// Actually move the window using Windows API
-	if o.windowHandle != 0 {
-		if err := o.platform.MoveAndResizeWindow(o.windowHandle, x, y, w, h); err != nil {
-			log.Printf("Failed to move window: %v", err)
+	x, y := o.config.OverlayX, o.config.OverlayY
+	if x == -1 && y == -1 {
+		return centerX, centerY
+	}
+
+	// Use the middle of the top edge: that's where the user grabs the window
+	for _, m := range o.platform.GetMonitorWorkAreas() {
+		if m.Contains(x+w/2, y) {
+			return clamp(x, m.X, m.X+m.Width-w), clamp(y, m.Y, m.Y+m.Height-h)
 		}
 	}
-	o.config.SetOverlayCoords(x, y)
+
+	log.Printf("Saved overlay position (%d, %d) is off-screen, re-centering", x, y)
+	return centerX, centerY
+}
+
+// IsFloating returns whether the overlay is floating rather than snapped
+func (o *OverlayWindow) IsFloating() bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.position == platform.SnapNone
+}
+
+// Nudge moves the floating overlay by dx, dy pixels, keeping it on its
+// monitor. Does nothing while the overlay is snapped.
+func (o *OverlayWindow) Nudge(dx, dy int) {
+	if !o.IsFloating() {
+		return
+	}
+	x, y := o.validFloatingPosition(o.windowSize())
+	o.config.OverlayX, o.config.OverlayY = x+dx, y+dy
+	o.snapToPosition(platform.SnapNone)
+}
+
+// ResetPosition forgets the saved position and snaps back to the default top
+// position. Manual escape hatch for an overlay that can't be found.
+func (o *OverlayWindow) ResetPosition() {
+	o.config.OverlayX = -1
+	o.config.OverlayY = -1
+	o.SnapTo(platform.SnapPosition(config.Default().OverlayPosition))
+	if !o.IsVisible() {
+		o.Show()
+	}
+}
+
+// clamp limits v to [lo, hi], preferring lo when the range is empty
+func clamp(v, lo, hi int) int {
+	if v > hi {
+		v = hi
+	}
+	if v < lo {
+		v = lo
+	}
+	return v
 }
 
 // SetStatus updates the status text shown in the overlay (e.g. "Loading...", "Auth failed")
@@ -402,7 +827,66 @@ func (o *OverlayWindow) UpdateUsage(data *api.UsageData) {
 	if data == nil || !o.initialized {
 		return
 	}
+	o.lastUsage = data
+	o.setUsage(data)
+
+	// The widgets repaint themselves; the window is only rebuilt when rows
+	// appear or disappear or the content no longer fits, which saves the
+	// work and the flicker on most polls
+	if o.laidOut != nil && *o.laidOut == o.currentLayoutKey() && o.window.Content().MinSize() == o.laidOutMin {
+		return
+	}
+	o.applyLayout()
+	o.snapToPosition(o.position)
+}
+
+// layoutKey is what decides which rows the overlay content has
+type layoutKey struct {
+	vertical bool
+	page     int
+	stats    config.VisibleStats
+	severity alert.Severity
+	status   bool
+	diff     bool
+	requests bool
+	cost     bool
+	budget   bool
+	accounts int
+	code     int
+	models   bool
+	trends   int
+	sparks   [2]bool
+}
+
+func (o *OverlayWindow) currentLayoutKey() layoutKey {
+	return layoutKey{
+		vertical: o.isVertical,
+		page:     o.page,
+		stats:    o.config.VisibleStats,
+		severity: o.severity(),
+		status:   o.statusText != nil && o.statusText.Text != "",
+		diff:     o.diffText != nil && o.diffText.Text != "",
+		requests: o.requestsText != nil && o.requestsText.Text != "",
+		cost:     o.costText != nil && o.costText.Text != "",
+		budget:   o.budget != nil,
+		accounts: len(o.accounts),
+		code:     len(claudeCodeLines(o.claudeCode)),
+		models:   hasModelLimits(o.lastUsage),
+		trends:   len(o.trends),
+		sparks:   [2]bool{o.showSpark(o.sessionSpark), o.showSpark(o.weeklySpark)},
+	}
+}
 
+// weeklyView is what the weekly widgets show. Weekly numbers change slowly,
+// so the widgets are only updated when it changes.
+type weeklyView struct {
+	weekly, opus, sonnet api.UsageStat
+	resetsIn             string
+	label, short         string
+}
+
+// setUsage pushes data into the widgets of both layouts without touching the window
+func (o *OverlayWindow) setUsage(data *api.UsageData) {
 	// Clear loading/status text once we have data
 	if o.statusText != nil && o.statusText.Text != "" {
 		o.statusText.Text = ""
@@ -411,58 +895,195 @@ func (o *OverlayWindow) UpdateUsage(data *api.UsageData) {
 
 	// Update vertical layout widgets (Utilization is already 0-100)
 	if o.sessionRow != nil {
-		o.sessionRow.Update("Current session", data.FiveHour.Utilization, data.FiveHour.ResetsAt)
-	}
-	if o.weeklyRow != nil {
-		o.weeklyRow.Update("All models", data.SevenDay.Utilization, time.Time{})
-		o.weeklyRow.UpdateResetAbsolute(data.SevenDay.ResetsAt)
+		o.sessionRow.Update(o.label(labelSession), data.FiveHour.Utilization, data.FiveHour.ResetsAt)
 	}
 	// Update reset timers
 	if o.sessionResetText != nil && !data.FiveHour.ResetsAt.IsZero() {
-		o.sessionResetText.Text = "Session resets in " + api.TimeUntilReset(data.FiveHour.ResetsAt)
+		o.sessionResetText.Text = o.label(labelSessionShort) + " resets in " + api.TimeUntilReset(data.FiveHour.ResetsAt)
 		o.sessionResetText.Refresh()
 	}
-	if o.weeklyResetText != nil && !data.SevenDay.ResetsAt.IsZero() {
-		o.weeklyResetText.Text = "Weekly resets in " + api.TimeUntilReset(data.SevenDay.ResetsAt)
-		o.weeklyResetText.Refresh()
-	}
 
 	// Update compact layout widgets
 	if o.compactSession != nil {
 		o.compactSession.Update(data.FiveHour.Utilization)
 	}
+	o.setWeekly(data)
+	if o.compactSessionReset != nil {
+		o.compactSessionReset.Set(o.label(labelSessionShort), data.FiveHour.ResetsAt)
+		o.compactWeeklyReset.Set(o.label(labelWeeklyShort), data.SevenDay.ResetsAt)
+	}
+
+	if o.budget != nil {
+		// Highlight the plan once usage runs ahead of it
+		c := activeTheme.Colors.Subtext
+		if o.budget.Over() >= 0.5 {
+			c = activeTheme.Colors.BarWarn
+		}
+		o.budgetText.Text = o.budget.String()
+		o.budgetText.Color = c
+		o.budgetText.Refresh()
+		o.compactBudget.Text = fmt.Sprintf("Plan %+.0f%%", o.budget.Over())
+		o.compactBudget.Color = c
+		o.compactBudget.Refresh()
+	}
+}
+
+// setWeekly pushes the weekly figures into the widgets of both layouts when
+// they differ from those shown
+func (o *OverlayWindow) setWeekly(data *api.UsageData) {
+	view := weeklyView{
+		weekly: data.SevenDay, opus: data.SevenDayOpus, sonnet: data.SevenDaySonnet,
+		label: o.label(labelWeekly), short: o.label(labelWeeklyShort),
+	}
+	if !data.SevenDay.ResetsAt.IsZero() {
+		view.resetsIn = api.TimeUntilReset(data.SevenDay.ResetsAt)
+	}
+	if o.weeklyShown != nil && sameWeekly(*o.weeklyShown, view) {
+		return
+	}
+	o.weeklyShown = &view
+
+	if o.weeklyRow != nil {
+		o.weeklyRow.Update(view.label, data.SevenDay.Utilization, time.Time{})
+		o.weeklyRow.UpdateResetAbsolute(data.SevenDay.ResetsAt)
+	}
+	if o.opusRow != nil {
+		o.opusRow.Update("Opus", data.SevenDayOpus.Utilization, time.Time{})
+		o.opusRow.UpdateResetAbsolute(data.SevenDayOpus.ResetsAt)
+		o.sonnetRow.Update("Sonnet", data.SevenDaySonnet.Utilization, time.Time{})
+		o.sonnetRow.UpdateResetAbsolute(data.SevenDaySonnet.ResetsAt)
+	}
+	if o.weeklyResetText != nil && view.resetsIn != "" {
+		o.weeklyResetText.Text = view.short + " resets in " + view.resetsIn
+		o.weeklyResetText.Refresh()
+	}
 	if o.compactWeekly != nil {
 		o.compactWeekly.Update(data.SevenDay.Utilization)
 	}
-	if o.compactReset != nil {
-		resetText := ""
-		if !data.FiveHour.ResetsAt.IsZero() {
-			resetText = "Session " + api.TimeUntilReset(data.FiveHour.ResetsAt)
+}
+
+// sameWeekly reports whether two weekly views show the same; reset times are
+// compared as instants
+func sameWeekly(a, b weeklyView) bool {
+	same := func(x, y api.UsageStat) bool {
+		return x.Utilization == y.Utilization && x.ResetsAt.Equal(y.ResetsAt)
+	}
+	return same(a.weekly, b.weekly) && same(a.opus, b.opus) && same(a.sonnet, b.sonnet) &&
+		a.resetsIn == b.resetsIn && a.label == b.label && a.short == b.short
+}
+
+// SetRequestsLeft shows the estimated number of requests of a size
+// ("typical" or "heavy") left in the session; a negative n hides it. Takes
+// effect with the next UpdateUsage.
+func (o *OverlayWindow) SetRequestsLeft(n int, size string) {
+	text := ""
+	if n >= 0 {
+		text = fmt.Sprintf("≈ %d more %s requests this session (estimate)", n, size)
+	}
+	o.requestsText.Text = text
+	o.requestsText.Refresh()
+}
+
+// SetCost shows the estimated dollar value of the session and week; nil
+// hides it. Takes effect with the next UpdateUsage.
+func (o *OverlayWindow) SetCost(c *budget.Cost) {
+	text := ""
+	if c != nil {
+		text = c.String()
+	}
+	o.costText.Text = text
+	o.costText.Refresh()
+}
+
+// SetBudget sets the weekly plan shown with the weekly usage (nil hides it).
+// Takes effect with the next UpdateUsage.
+func (o *OverlayWindow) SetBudget(p *budget.Plan) {
+	o.budget = p
+}
+
+// AccountUsage is the usage of an account besides the active one, see
+// SetOtherAccounts. Usage is nil when it couldn't be fetched.
+type AccountUsage struct {
+	Name  string
+	Usage *api.UsageData
+}
+
+// SetOtherAccounts lists the session and weekly usage of other accounts
+// below the active one's, one line each; nil hides them. Takes effect with
+// the next UpdateUsage.
+func (o *OverlayWindow) SetOtherAccounts(accounts []AccountUsage) {
+	o.accounts = accounts
+	o.showAccounts()
+}
+
+// showAccounts pushes the other accounts' usage into the widgets of both layouts
+func (o *OverlayWindow) showAccounts() {
+	percent := func(u *api.UsageData, stat func(*api.UsageData) api.UsageStat) string {
+		if u == nil {
+			return "--"
 		}
-		if !data.SevenDay.ResetsAt.IsZero() {
-			if resetText != "" {
-				resetText += " | "
+		return o.config.FormatPercent(stat(u).Utilization)
+	}
+	session := func(u *api.UsageData) api.UsageStat { return u.FiveHour }
+	weekly := func(u *api.UsageData) api.UsageStat { return u.SevenDay }
+
+	if o.sessionRow != nil {
+		if len(o.accountTexts) != len(o.accounts) {
+			o.accountTexts = make([]*canvas.Text, len(o.accounts))
+			for i := range o.accountTexts {
+				o.accountTexts[i] = SectionSubtext("")
 			}
-			resetText += "Weekly " + api.TimeUntilReset(data.SevenDay.ResetsAt)
 		}
-		o.compactReset.Text = resetText
-		o.compactReset.Refresh()
+		for i, a := range o.accounts {
+			o.accountTexts[i].Text = fmt.Sprintf("%s: %s %s · %s %s", a.Name,
+				o.label(labelSessionShort), percent(a.Usage, session),
+				o.label(labelWeeklyShort), percent(a.Usage, weekly))
+			o.accountTexts[i].Refresh()
+		}
+	}
+	if o.compactAccounts != nil {
+		var parts []string
+		for _, a := range o.accounts {
+			parts = append(parts, fmt.Sprintf("%s %s · %s", a.Name, percent(a.Usage, session), percent(a.Usage, weekly)))
+		}
+		o.compactAccounts.Text = strings.Join(parts, "  ")
+		o.compactAccounts.Refresh()
 	}
-
-	// Rebuild layout so window resizes to fit new content
-	o.applyLayout()
-	o.snapToPosition(o.position)
 }
 
-// SetOpacity updates the overlay transparency
+// SetOpacity changes the opacity at the current position: the position's
+// own entry in position_opacity if it has one, the global opacity otherwise
 func (o *OverlayWindow) SetOpacity(opacity float64) {
-	o.config.OverlayOpacity = opacity
+	pos := string(o.position)
+	if _, ok := o.config.PositionOpacity[pos]; ok {
+		o.config.PositionOpacity[pos] = opacity
+	} else {
+		o.config.OverlayOpacity = opacity
+	}
 	o.config.Save()
+	o.ApplyOpacity()
+}
+
+// ApplyOpacity re-applies the configured opacity and opacity mode, e.g.
+// after the settings changed
+func (o *OverlayWindow) ApplyOpacity() {
 	if o.windowHandle != 0 {
-		o.platform.SetTransparency(o.windowHandle, opacity)
+		o.applyOpacity()
 	}
 }
 
+// label returns the display text for a label ID, including config overrides
+func (o *OverlayWindow) label(id string) string {
+	return resolveLabel(o.config, id)
+}
+
+// WindowHandle returns the native window handle, 0 while it isn't known
+func (o *OverlayWindow) WindowHandle() platform.WindowHandle {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.windowHandle
+}
+
 // GetWindow returns the underlying Fyne window
 func (o *OverlayWindow) GetWindow() fyne.Window {
 	return o.window
@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"claudebar/internal/api"
+	"claudebar/internal/history"
+	"claudebar/pkg/widgets"
+)
+
+// Pages of the vertical overlay
+const (
+	pageUsage  = iota // session and weekly usage
+	pageModels        // per-model weekly limits
+	pageTrends        // recent weeks from the history
+	pageCount
+)
+
+// trendWeeks is how many past weeks the trends page lists
+const trendWeeks = 4
+
+// pageDotSize is the diameter of a page indicator dot
+const pageDotSize = 6
+
+// pageDot is a dot of the page indicator that shows its page when clicked
+type pageDot struct {
+	widget.BaseWidget
+	circle *canvas.Circle
+	onTap  func()
+}
+
+func newPageDot(current bool, onTap func()) *pageDot {
+	c := activeTheme.Colors.BarTrack
+	if current {
+		c = activeTheme.Colors.Subtext
+	}
+	d := &pageDot{circle: canvas.NewCircle(c), onTap: onTap}
+	d.ExtendBaseWidget(d)
+	return d
+}
+
+func (d *pageDot) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(d.circle)
+}
+
+// MinSize keeps the dot small; the circle stretches to fill it
+func (d *pageDot) MinSize() fyne.Size {
+	return fyne.NewSize(pageDotSize, pageDotSize)
+}
+
+// Tapped implements fyne.Tappable
+func (d *pageDot) Tapped(*fyne.PointEvent) {
+	d.onTap()
+}
+
+// Cursor implements desktop.Cursorable, hinting that the dot is clickable
+func (d *pageDot) Cursor() desktop.Cursor {
+	return desktop.PointerCursor
+}
+
+// pageIndicator is a centered row of dots, one per page, the current one
+// highlighted
+func (o *OverlayWindow) pageIndicator() fyne.CanvasObject {
+	row := container.NewHBox(layout.NewSpacer())
+	for page := range pageCount {
+		row.Add(newPageDot(page == o.page, func() { o.SetPage(page) }))
+	}
+	row.Add(layout.NewSpacer())
+	return row
+}
+
+// NextPage shows the next page of the vertical overlay, wrapping around
+func (o *OverlayWindow) NextPage() {
+	o.SetPage((o.page + 1) % pageCount)
+}
+
+// SetPage shows a page of the vertical overlay. The top bar has no pages.
+func (o *OverlayWindow) SetPage(page int) {
+	if page == o.page || !o.isVertical || !o.initialized {
+		return
+	}
+	o.page = page
+	o.applyLayout()
+	o.snapToPosition(o.position)
+}
+
+// SetTrends sets the completed weekly cycles, oldest first, whose latest
+// weeks the trends page shows
+func (o *OverlayWindow) SetTrends(cycles []history.Cycle) {
+	var measured []history.Cycle
+	for _, c := range cycles {
+		if c.Measured() {
+			measured = append(measured, c)
+		}
+	}
+	o.trends = measured[max(0, len(measured)-trendWeeks):]
+	if o.initialized && o.page == pageTrends {
+		o.applyLayout()
+		o.snapToPosition(o.position)
+	}
+}
+
+// pageItems returns the rows of the current page
+func (o *OverlayWindow) pageItems() []fyne.CanvasObject {
+	switch o.page {
+	case pageModels:
+		return o.modelItems()
+	case pageTrends:
+		return o.trendItems()
+	}
+	return o.usageItems()
+}
+
+// modelItems lists the per-model weekly limits
+func (o *OverlayWindow) modelItems() []fyne.CanvasObject {
+	items := []fyne.CanvasObject{SectionHeader("Weekly limits by model")}
+	if !hasModelLimits(o.lastUsage) {
+		return append(items, SectionSubtext("No per-model limits on this plan"))
+	}
+	return append(items, o.opusRow.GetContainer(), Separator(), o.sonnetRow.GetContainer())
+}
+
+// hasModelLimits reports whether the account has per-model weekly limits
+func hasModelLimits(u *api.UsageData) bool {
+	return u != nil && (!u.SevenDayOpus.ResetsAt.IsZero() || !u.SevenDaySonnet.ResetsAt.IsZero() ||
+		u.SevenDayOpus.Utilization > 0 || u.SevenDaySonnet.Utilization > 0)
+}
+
+// trendItems lists the peaks of the latest weeks, newest first
+func (o *OverlayWindow) trendItems() []fyne.CanvasObject {
+	items := []fyne.CanvasObject{SectionHeader("Recent weeks")}
+	if len(o.trends) == 0 {
+		return append(items, SectionSubtext("Weekly peaks appear here after the first reset"))
+	}
+	style := widgets.ParseBarStyle(o.config.CompactBarStyle, true)
+	for i := len(o.trends) - 1; i >= 0; i-- {
+		c := o.trends[i]
+		row := widgets.NewCompactUsageRow(c.End.Local().Format("Jan 2"), widgets.MetricWeekly, style)
+		row.Update(c.PeakUtilization)
+		items = append(items, row.GetContainer())
+	}
+	return items
+}
@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ShowReauthPrompt opens a small window saying ClaudeBar lost its sign-in
+// (status, e.g. "Session expired"), with a Fix now button that calls onFix
+func ShowReauthPrompt(app fyne.App, status string, onFix func()) {
+	window := app.NewWindow("ClaudeBar: " + status)
+	window.Resize(fyne.NewSize(380, 140))
+
+	text := widget.NewLabel("ClaudeBar couldn't sign in again with your browser's claude.ai session. Log in to claude.ai in your browser or paste a session key in Settings; ClaudeBar keeps trying in the background.")
+	text.Wrapping = fyne.TextWrapWord
+
+	fixBtn := widget.NewButton("Fix now", func() {
+		window.Close()
+		onFix()
+	})
+	fixBtn.Importance = widget.HighImportance
+	laterBtn := widget.NewButton("Later", func() {
+		window.Close()
+	})
+
+	buttons := container.NewHBox(layout.NewSpacer(), fixBtn, laterBtn)
+	window.SetContent(withBackground(app, container.NewPadded(container.NewBorder(nil, buttons, nil, nil, text))))
+	window.Show()
+}
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"net/url"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ShowReport opens a problem report for review before it is sent: it can
+// be opened as a pre-filled GitHub issue at link, or saved to a file to
+// attach by hand
+func ShowReport(app fyne.App, text, link string) {
+	window := app.NewWindow("Report a Problem")
+	window.Resize(fyne.NewSize(560, 480))
+
+	intro := widget.NewLabel("This goes into the report. Secrets are masked; check it for anything else you'd rather not share.")
+	intro.Wrapping = fyne.TextWrapWord
+
+	body := widget.NewMultiLineEntry()
+	body.SetText(text)
+	body.Wrapping = fyne.TextWrapWord
+	body.Disable()
+
+	openBtn := widget.NewButton("Open GitHub Issue", func() {
+		u, err := url.Parse(link)
+		if err == nil {
+			err = app.OpenURL(u)
+		}
+		if err != nil {
+			dialog.ShowError(err, window)
+		}
+	})
+	openBtn.Importance = widget.HighImportance
+	saveBtn := widget.NewButton("Save to File...", func() {
+		save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil || w == nil {
+				return
+			}
+			defer w.Close()
+			if _, err := w.Write([]byte(text)); err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			dialog.ShowInformation("Saved", "Report saved to\n"+w.URI().Path(), window)
+		}, window)
+		save.SetFileName("claudebar-report.md")
+		save.Show()
+	})
+	closeBtn := widget.NewButton("Close", func() {
+		window.Close()
+	})
+
+	buttons := container.NewHBox(layout.NewSpacer(), openBtn, saveBtn, closeBtn, layout.NewSpacer())
+	content := container.NewBorder(intro, buttons, nil, nil, body)
+	window.SetContent(withBackground(app, container.NewPadded(content)))
+	window.Show()
+}
@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/software"
+
+	"claudebar/internal/api"
+	"claudebar/internal/config"
+)
+
+// allStats shows every stat the overlay supports
+var allStats = config.VisibleStats{
+	SessionUsage: true,
+	DailyUsage:   true,
+	WeeklyUsage:  true,
+	ResetTime:    true,
+	Sparklines:   true,
+}
+
+// screenshotLayouts are the overlay variants written by RenderScreenshots.
+// "mini" is the top-bar layout trimmed down to the session meter.
+var screenshotLayouts = []struct {
+	name     string
+	vertical bool
+	stats    config.VisibleStats
+}{
+	{"vertical", true, allStats},
+	{"horizontal", false, allStats},
+	{"mini", false, config.VisibleStats{SessionUsage: true}},
+}
+
+// RenderScreenshots renders each overlay layout with data and writes one PNG
+// per layout into dir. The overlay content is built exactly as for the live
+// window, then painted offscreen so no window is shown and the output does not
+// depend on the desktop behind the overlay.
+func RenderScreenshots(app fyne.App, data *api.UsageData, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, l := range screenshotLayouts {
+		cfg := config.Default()
+		cfg.VisibleStats = l.stats
+
+		img := renderLayout(app, cfg, l.vertical, data)
+		path := filepath.Join(dir, l.name+".png")
+		if err := writePNG(path, img); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		log.Printf("Screenshot: wrote %s (%dx%d)", path, img.Bounds().Dx(), img.Bounds().Dy())
+	}
+	return nil
+}
+
+// renderLayout builds the overlay content for one layout and paints it with
+// the software renderer at the size the overlay window would use
+func renderLayout(app fyne.App, cfg *config.Config, vertical bool, data *api.UsageData) image.Image {
+	o := NewOverlayWindow(app)
+	o.config = cfg
+	o.isVertical = vertical
+	o.createVerticalWidgets()
+	o.createCompactWidgets()
+	o.lastUsage = data
+	o.setUsage(data)
+
+	content, size := o.buildContent()
+	c := software.NewCanvas()
+	c.SetPadded(false)
+	c.SetContent(content)
+	c.Resize(size)
+	return c.Capture()
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -3,10 +3,24 @@ package ui
 import (
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 
+	"claudebar/internal/autostart"
+	"claudebar/internal/backup"
+	"claudebar/internal/budget"
 	"claudebar/internal/config"
+	"claudebar/internal/history"
+	"claudebar/internal/platform"
+	"claudebar/internal/server"
+	"claudebar/internal/themes"
+	"claudebar/pkg/widgets"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
@@ -14,6 +28,43 @@ import (
 	"fyne.io/fyne/v2/widget"
 )
 
+// builtinThemeLabel is the theme picker entry for the compiled-in theme
+const builtinThemeLabel = "Built-in"
+
+// Tray text options, labels and the config values they map to
+var (
+	trayTitleLabels = []string{"Off", "Session %", "Weekly %", "Highest %"}
+	trayTitleValues = []string{TrayTitleOff, TrayTitleSession, TrayTitleWeekly, TrayTitleHighest}
+)
+
+// Tray icon click options, labels and the config values they map to
+var (
+	trayClickLabels = []string{"Open the menu", "Show or hide the overlay", "Open settings", "Refresh now"}
+	trayClickValues = []string{TrayClickMenu, TrayClickOverlay, TrayClickSettings, TrayClickRefresh}
+)
+
+// Requests left estimate options, labels and the config values they map to
+var (
+	requestEstimateLabels = []string{"Off", "Typical requests", "Heavy requests"}
+	requestEstimateValues = []string{"", budget.RequestTypical, budget.RequestHeavy}
+)
+
+// Cost estimate sources, labels and the config values they map to
+var (
+	costLabels = []string{"Off", "Claude Code logs (API prices)", "Share of a full session and week"}
+	costValues = []string{"", budget.CostLogs, budget.CostUtilization}
+)
+
+// Double-press options for the toggle hotkey, labels and the actions they
+// map to; the first action needs click-through support
+var (
+	doublePressLabels = []string{"Nothing", "Toggle click-through", "Show or hide the overlay", "Next overlay page", "Refresh now"}
+	doublePressValues = []string{"", "click_through", "overlay", "next_page", "refresh"}
+)
+
+// precisionLabels are the precision options by number of decimals
+var precisionLabels = []string{"42%", "42.4%", "42.37%"}
+
 // SettingsDialog manages the settings window
 type SettingsDialog struct {
 	app              fyne.App
@@ -22,6 +73,12 @@ type SettingsDialog struct {
 	onSessionKeySet  func(string) error
 	onRefreshBrowser func() error
 	onSave           func()
+	onImported       func()
+	history          backup.HistoryRestorer
+	onOrgSet         func(string) error
+	onReset          func(history bool) (string, error)
+	onAccountSwitch  func(name string)
+	onAccountsEdit   func()
 }
 
 // NewSettingsDialog creates a new settings dialog
@@ -44,22 +101,47 @@ func (s *SettingsDialog) SetCallbacks(
 	s.onSave = onSave
 }
 
+// SetImportCallback sets the function called after a settings bundle was imported
+func (s *SettingsDialog) SetImportCallback(onImported func()) {
+	s.onImported = onImported
+}
+
+// SetHistoryRestorer sets what replaces the usage history when an imported
+// bundle includes it
+func (s *SettingsDialog) SetHistoryRestorer(history backup.HistoryRestorer) {
+	s.history = history
+}
+
+// SetOrganizationCallback sets the function that validates and applies an
+// organization override, "" for automatic detection
+func (s *SettingsDialog) SetOrganizationCallback(onOrgSet func(string) error) {
+	s.onOrgSet = onOrgSet
+}
+
+// SetResetCallback sets the function that backs up the config directory,
+// clears credentials, window position and optionally the history, and starts
+// sign-in over. It returns the path of the backup.
+func (s *SettingsDialog) SetResetCallback(onReset func(history bool) (string, error)) {
+	s.onReset = onReset
+}
+
+// SetAccountCallbacks sets the functions called to switch to another
+// account, and after accounts were added, renamed or removed
+func (s *SettingsDialog) SetAccountCallbacks(onSwitch func(name string), onEdit func()) {
+	s.onAccountSwitch = onSwitch
+	s.onAccountsEdit = onEdit
+}
+
 // Show displays the settings dialog
 func (s *SettingsDialog) Show() {
 	window := s.app.NewWindow("ClaudeBar Settings")
-	window.Resize(fyne.NewSize(380, 430))
+	window.Resize(fyne.NewSize(400, 700))
 
 	// --- Authentication ---
 	authLabel := widget.NewLabel("Authentication")
 	authLabel.TextStyle = fyne.TextStyle{Bold: true}
 
-	authStatus := widget.NewLabel("")
-	if s.config.SessionKey != "" {
-		keyPreview := s.config.SessionKey[:min(16, len(s.config.SessionKey))]
-		authStatus.SetText(fmt.Sprintf("Active (%s...)", keyPreview))
-	} else {
-		authStatus.SetText("Not connected")
-	}
+	authStatus := widget.NewLabel(s.authStatusText())
 	authStatus.Wrapping = fyne.TextWrapOff
 
 	sessionKeyEntry := widget.NewPasswordEntry()
@@ -111,11 +193,54 @@ func (s *SettingsDialog) Show() {
 		)
 	})
 
+	// Organization override, for when automatic detection fails
+	orgEntry := widget.NewEntry()
+	orgEntry.SetPlaceHolder("Automatic")
+	orgEntry.SetText(s.config.OrganizationOverride)
+	setOrgBtn := widget.NewButton("Set Organization", nil)
+	setOrgBtn.OnTapped = func() {
+		if s.onOrgSet == nil {
+			return
+		}
+		id := orgEntry.Text
+		setOrgBtn.Disable()
+		orgEntry.Disable()
+		go func() {
+			err := s.onOrgSet(id)
+			fyne.Do(func() {
+				setOrgBtn.Enable()
+				orgEntry.Enable()
+				switch {
+				case err != nil:
+					dialog.ShowError(fmt.Errorf("organization not changed: %w", err), window)
+				case strings.TrimSpace(id) == "":
+					dialog.ShowInformation("Organization", "Organization is detected automatically again", window)
+				default:
+					dialog.ShowInformation("Organization", "Usage fetched, organization saved", window)
+				}
+			})
+		}()
+	}
+	orgAdvanced := widget.NewAccordion(widget.NewAccordionItem("Organization ID",
+		container.NewVBox(
+			widget.NewLabel("Overrides the detected organization.\nLeave empty for automatic."),
+			orgEntry,
+			setOrgBtn,
+		)))
+
+	accounts := s.accountsSection(window, func() {
+		authStatus.SetText(s.authStatusText())
+		sessionKeyEntry.SetText("")
+		orgEntry.SetText(s.config.OrganizationOverride)
+	})
+
 	authSection := container.NewVBox(
 		authLabel,
 		authStatus,
 		sessionKeyEntry,
 		container.NewHBox(setKeyBtn, helpBtn),
+		orgAdvanced,
+		accounts,
 	)
 
 	// --- Display ---
@@ -144,12 +269,98 @@ func (s *SettingsDialog) Show() {
 		intervalValueLabel.SetText(fmt.Sprintf("%.0fs", v))
 	}))
 
+	lightPollingCheck := widget.NewCheck("Poll less while the overlay is hidden", nil)
+	lightPollingCheck.SetChecked(s.config.LightPolling)
+
+	spikeCheck := widget.NewCheck(fmt.Sprintf("Ignore single-poll jumps over %.0f%%", s.config.SpikeThreshold), func(checked bool) {
+		s.config.SpikeFilterEnabled = checked
+	})
+	spikeCheck.SetChecked(s.config.SpikeFilterEnabled)
+
+	// Theme files from the themes folder, edits to the active one apply live
+	themeOptions := []string{builtinThemeLabel}
+	if names, err := themes.List(); err == nil {
+		themeOptions = append(themeOptions, names...)
+	}
+	themeSelect := widget.NewSelect(themeOptions, nil)
+	if s.config.Theme == "" {
+		themeSelect.SetSelected(builtinThemeLabel)
+	} else {
+		themeSelect.SetSelected(s.config.Theme)
+	}
+
+	barStyleSelect := widget.NewSelect(barStyleNames(false), nil)
+	barStyleSelect.SetSelected(string(widgets.ParseBarStyle(s.config.BarStyle, false)))
+	compactBarStyleSelect := widget.NewSelect(barStyleNames(true), nil)
+	compactBarStyleSelect.SetSelected(string(widgets.ParseBarStyle(s.config.CompactBarStyle, true)))
+
+	trayTitleSelect := widget.NewSelect(trayTitleLabels, nil)
+	trayTitleSelect.SetSelected(trayTitleLabels[0])
+	for i, v := range trayTitleValues {
+		if v == s.config.TrayTitle {
+			trayTitleSelect.SetSelected(trayTitleLabels[i])
+		}
+	}
+
+	trayClickSelect := widget.NewSelect(trayClickLabels, nil)
+	trayClickSelect.SetSelected(trayClickLabels[0])
+	for i, v := range trayClickValues {
+		if v == s.config.TrayClick {
+			trayClickSelect.SetSelected(trayClickLabels[i])
+		}
+	}
+
+	requestEstimateSelect := widget.NewSelect(requestEstimateLabels, nil)
+	requestEstimateSelect.SetSelected(requestEstimateLabels[0])
+	for i, v := range requestEstimateValues {
+		if v == s.config.RequestEstimate {
+			requestEstimateSelect.SetSelected(requestEstimateLabels[i])
+		}
+	}
+
+	precisionSelect := widget.NewSelect(precisionLabels, nil)
+	precisionSelect.SetSelected(precisionLabels[s.config.Decimals()])
+
+	backgroundOnlyCheck := widget.NewCheck("Fade background only (keep text opaque)", nil)
+	backgroundOnlyCheck.SetChecked(s.config.OverlayOpacityMode == OpacityBackground)
+
+	adaptiveTextCheck := widget.NewCheck("Adapt text color to the desktop behind", nil)
+	adaptiveTextCheck.SetChecked(s.config.AdaptiveText)
+
+	clickThroughCheck := widget.NewCheck("Let clicks pass through the overlay (Ctrl+Alt+Home to interact)", nil)
+	clickThroughCheck.SetChecked(s.config.ClickThrough)
+
+	dodgeCheck := widget.NewCheck("Move out of the way of other always-on-top windows", nil)
+	dodgeCheck.SetChecked(s.config.DodgeTopmost)
+
+	autoStartCheck := widget.NewCheck("Start on login", nil)
+	autoStartCheck.SetChecked(autostart.IsEnabled())
+	autoStartInitial := autoStartCheck.Checked
+
+	opacityHeader := container.NewHBox(widget.NewLabel("Opacity"), layout.NewSpacer(), opacityValueLabel)
+
 	displaySection := container.NewVBox(
 		displayLabel,
-		container.NewHBox(widget.NewLabel("Opacity"), layout.NewSpacer(), opacityValueLabel),
+		autoStartCheck,
+		opacityHeader,
 		opacitySlider,
+		backgroundOnlyCheck,
+		adaptiveTextCheck,
+		clickThroughCheck,
+		dodgeCheck,
 		container.NewHBox(widget.NewLabel("Refresh interval"), layout.NewSpacer(), intervalValueLabel),
 		intervalSlider,
+		lightPollingCheck,
+		spikeCheck,
+		container.NewBorder(nil, nil, widget.NewLabel("Theme"), nil, themeSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("Tray text"), nil, trayTitleSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("Tray click"), nil, trayClickSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("Requests left"), nil, requestEstimateSelect),
+		container.NewBorder(nil, nil, widget.NewLabel("Precision"), nil, precisionSelect),
+		container.NewGridWithColumns(2,
+			container.NewBorder(nil, nil, widget.NewLabel("Bars"), nil, barStyleSelect),
+			container.NewBorder(nil, nil, widget.NewLabel("Top bar"), nil, compactBarStyleSelect),
+		),
 	)
 
 	// --- Visible Stats ---
@@ -171,9 +382,20 @@ func (s *SettingsDialog) Show() {
 	})
 	resetCheck.SetChecked(s.config.VisibleStats.ResetTime)
 
+	trendsCheck := widget.NewCheck("Trend Lines", func(checked bool) {
+		s.config.VisibleStats.Sparklines = checked
+	})
+	trendsCheck.SetChecked(s.config.VisibleStats.Sparklines)
+
+	claudeCodeCheck := widget.NewCheck("Claude Code tokens and cost from this machine's logs", func(checked bool) {
+		s.config.ClaudeCodeEnabled = checked
+	})
+	claudeCodeCheck.SetChecked(s.config.ClaudeCodeEnabled)
+
 	visSection := container.NewVBox(
 		visLabel,
-		container.NewGridWithColumns(3, sessionCheck, weeklyCheck, resetCheck),
+		container.NewGridWithColumns(2, sessionCheck, weeklyCheck, resetCheck, trendsCheck),
+		claudeCodeCheck,
 	)
 
 	// --- Notifications ---
@@ -200,10 +422,280 @@ func (s *SettingsDialog) Show() {
 	})
 	thresh90.SetChecked(s.config.HasAlertThreshold(90))
 
+	warningEntry := widget.NewEntry()
+	warningEntry.SetText(fmt.Sprintf("%.0f", s.config.WarningThreshold))
+	criticalEntry := widget.NewEntry()
+	criticalEntry.SetText(fmt.Sprintf("%.0f", s.config.CriticalThreshold))
+
+	soundCheck := widget.NewCheck("Play a sound for warning and critical alerts", nil)
+	soundCheck.SetChecked(s.config.AlertSound)
+
+	flashBorderCheck := widget.NewCheck("Flash the overlay border", nil)
+	flashBorderCheck.SetChecked(s.config.FlashBorder)
+	flashTaskbarCheck := widget.NewCheck("Flash the taskbar button", nil)
+	flashTaskbarCheck.SetChecked(s.config.FlashTaskbar)
+
+	statsCheck := widget.NewCheck("Show streaks in History and a monthly summary", nil)
+	statsCheck.SetChecked(s.config.StatsEnabled)
+
+	dailySummaryCheck := widget.NewCheck("Sum up the day at", nil)
+	dailySummaryCheck.SetChecked(s.config.DailySummaryTime != "")
+	dailySummaryEntry := widget.NewEntry()
+	dailySummaryEntry.SetPlaceHolder("18:00")
+	dailySummaryEntry.SetText(s.config.DailySummaryTime)
+
 	notifSection := container.NewVBox(
 		notifLabel,
 		notifCheck,
 		container.NewHBox(widget.NewLabel("Alert at:"), thresh50, thresh75, thresh90),
+		container.NewGridWithColumns(2,
+			container.NewBorder(nil, nil, widget.NewLabel("Warning"), widget.NewLabel("%"), warningEntry),
+			container.NewBorder(nil, nil, widget.NewLabel("Critical"), widget.NewLabel("%"), criticalEntry),
+		),
+		soundCheck,
+		widget.NewLabel("At critical usage, even with alerts off:"),
+		container.NewGridWithColumns(2, flashBorderCheck, flashTaskbarCheck),
+		statsCheck,
+		container.NewBorder(nil, nil, dailySummaryCheck, nil, dailySummaryEntry),
+	)
+
+	// --- Hotkeys ---
+	hotkeysLabel := widget.NewLabel("Hotkeys")
+	hotkeysLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	excludedAppsEntry := widget.NewEntry()
+	excludedAppsEntry.SetPlaceHolder("idea64.exe, code")
+	excludedAppsEntry.SetText(strings.Join(s.config.HotkeyExcludedApps, ", "))
+
+	hotkeyStatus := widget.NewLabel(hotkeyStatusText(platform.Features.HotkeyHealth()))
+	hotkeyStatus.Wrapping = fyne.TextWrapWord
+
+	// Settings for features this system can't provide would silently do nothing
+	caps := platform.Features.Capabilities()
+
+	pressLabels, pressValues := doublePressLabels, doublePressValues
+	if !caps.ClickThrough {
+		pressLabels = slices.Delete(slices.Clone(pressLabels), 1, 2)
+		pressValues = slices.Delete(slices.Clone(pressValues), 1, 2)
+	}
+	doublePressSelect := widget.NewSelect(pressLabels, nil)
+	if i := slices.Index(pressValues, s.config.DoublePress["toggle_overlay"]); i >= 0 {
+		doublePressSelect.SetSelected(pressLabels[i])
+	}
+
+	hotkeysSection := container.NewVBox(
+		hotkeysLabel,
+		hotkeyStatus,
+		container.NewBorder(nil, nil, widget.NewLabel("Press Ctrl+Alt+. twice to"), nil, doublePressSelect),
+		widget.NewLabel("Pause hotkeys while these apps are focused:"),
+		excludedAppsEntry,
+	)
+
+	if !caps.Transparency {
+		opacityHeader.Hide()
+		opacitySlider.Hide()
+	}
+	if !caps.PerPixelAlpha {
+		backgroundOnlyCheck.Hide()
+	}
+	if !caps.ClickThrough {
+		clickThroughCheck.Hide()
+	}
+	if !caps.WindowList {
+		dodgeCheck.Hide()
+	}
+	if !caps.Hotkeys {
+		hotkeysSection.Hide()
+	}
+
+	// --- Integrations ---
+	integrationsLabel := widget.NewLabel("Integrations")
+	integrationsLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	serverCheck := widget.NewCheck("Local API for launchers (PowerToys Run, command palettes)", nil)
+	serverCheck.SetChecked(s.config.ServerEnabled)
+	serverPortEntry := widget.NewEntry()
+	serverPortEntry.SetText(strconv.Itoa(s.config.ServerPort))
+	serverBindEntry := widget.NewEntry()
+	serverBindEntry.SetPlaceHolder("Automatic")
+	serverBindEntry.SetText(s.config.ServerBind)
+	serverTLSCheck := widget.NewCheck("Serve over HTTPS (self-signed certificate)", nil)
+	serverTLSCheck.SetChecked(s.config.ServerTLS)
+
+	// Without an API token only this machine may call the API
+	serverTokenEntry := widget.NewEntry()
+	serverTokenEntry.SetPlaceHolder("None (localhost only)")
+	serverTokenEntry.SetText(s.config.ServerToken)
+	generateTokenBtn := widget.NewButton("Generate", func() {
+		serverTokenEntry.SetText(server.NewToken())
+	})
+
+	// The dashboard link carries its token, generated the first time the
+	// dashboard is turned on and kept so bookmarks on the phone keep working
+	dashboardToken := s.config.DashboardToken
+	dashboardLink := widget.NewLabel("")
+	dashboardLink.Wrapping = fyne.TextWrapBreak
+	updateDashboardLink := func(enabled bool) {
+		if !enabled {
+			dashboardLink.SetText("")
+			return
+		}
+		dashboardLink.SetText(dashboardURL(serverTLSCheck.Checked, strings.TrimSpace(serverBindEntry.Text),
+			strings.TrimSpace(serverPortEntry.Text), dashboardToken))
+	}
+	dashboardCheck := widget.NewCheck("LAN dashboard for phones on this network", func(checked bool) {
+		if checked && dashboardToken == "" {
+			dashboardToken = server.NewToken()
+		}
+		updateDashboardLink(checked)
+	})
+	dashboardCheck.SetChecked(s.config.DashboardEnabled)
+	updateDashboardLink(dashboardCheck.Checked)
+	onAddressChanged := func(string) {
+		updateDashboardLink(dashboardCheck.Checked)
+	}
+	serverPortEntry.OnChanged = onAddressChanged
+	serverBindEntry.OnChanged = onAddressChanged
+	serverTLSCheck.OnChanged = func(bool) {
+		updateDashboardLink(dashboardCheck.Checked)
+	}
+	copyLinkBtn := widget.NewButton("Copy link", func() {
+		if dashboardLink.Text != "" {
+			s.app.Clipboard().SetContent(dashboardLink.Text)
+		}
+	})
+
+	metricsCheck := widget.NewCheck("Prometheus metrics at /metrics", nil)
+	metricsCheck.SetChecked(s.config.MetricsEnabled)
+
+	integrationsSection := container.NewVBox(
+		integrationsLabel,
+		serverCheck,
+		metricsCheck,
+		container.NewBorder(nil, nil, widget.NewLabel("Port"), nil, serverPortEntry),
+		container.NewBorder(nil, nil, widget.NewLabel("Bind address"), nil, serverBindEntry),
+		container.NewBorder(nil, nil, widget.NewLabel("API token"), generateTokenBtn, serverTokenEntry),
+		serverTLSCheck,
+		dashboardCheck,
+		container.NewBorder(nil, nil, nil, copyLinkBtn, dashboardLink),
+	)
+
+	// --- Weekly Plan ---
+	planLabel := widget.NewLabel("Weekly Plan")
+	planLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	planCheck := widget.NewCheck("Pace weekly usage toward a target", nil)
+	planCheck.SetChecked(s.config.BudgetEnabled)
+
+	planTargetEntry := widget.NewEntry()
+	planTargetEntry.SetText(fmt.Sprintf("%.0f", s.config.BudgetTarget))
+
+	planDaySelect := widget.NewSelect(weekdayNames(), nil)
+	if day, err := budget.ParseDay(s.config.BudgetDay); err == nil {
+		planDaySelect.SetSelected(day.String())
+	}
+
+	planLockCheck := widget.NewCheck("Ask before opening claude.ai when over plan", nil)
+	planLockCheck.SetChecked(s.config.BudgetLock)
+
+	planSection := container.NewVBox(
+		planLabel,
+		planCheck,
+		container.NewGridWithColumns(2,
+			container.NewBorder(nil, nil, widget.NewLabel("At most"), widget.NewLabel("%"), planTargetEntry),
+			container.NewBorder(nil, nil, widget.NewLabel("by end of"), nil, planDaySelect),
+		),
+		planLockCheck,
+	)
+
+	// --- Cost Estimate ---
+	costLabel := widget.NewLabel("Cost Estimate")
+	costLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	costSelect := widget.NewSelect(costLabels, nil)
+	costSelect.SetSelected(costLabels[0])
+	for i, v := range costValues {
+		if v == s.config.CostEstimate {
+			costSelect.SetSelected(costLabels[i])
+		}
+	}
+
+	costSessionEntry := widget.NewEntry()
+	costSessionEntry.SetText(strconv.FormatFloat(s.config.CostSession, 'f', -1, 64))
+	costWeeklyEntry := widget.NewEntry()
+	costWeeklyEntry.SetText(strconv.FormatFloat(s.config.CostWeekly, 'f', -1, 64))
+
+	costSection := container.NewVBox(
+		costLabel,
+		container.NewBorder(nil, nil, widget.NewLabel("Estimate from"), nil, costSelect),
+		container.NewGridWithColumns(2,
+			container.NewBorder(nil, nil, widget.NewLabel("Full session $"), nil, costSessionEntry),
+			container.NewBorder(nil, nil, widget.NewLabel("Full week $"), nil, costWeeklyEntry),
+		),
+	)
+
+	// --- Sync ---
+	syncLabel := widget.NewLabel("Sync")
+	syncLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	syncCheck := widget.NewCheck("Sync settings and session key across devices", nil)
+	syncCheck.SetChecked(s.config.SyncEnabled)
+
+	syncFolderEntry := widget.NewEntry()
+	syncFolderEntry.SetPlaceHolder("Shared folder (Dropbox, OneDrive, Syncthing...)")
+	syncFolderEntry.SetText(s.config.SyncFolder)
+
+	browseBtn := widget.NewButton("Browse...", func() {
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil || uri == nil {
+				return
+			}
+			syncFolderEntry.SetText(uri.Path())
+		}, window)
+	})
+
+	syncPassEntry := widget.NewPasswordEntry()
+	syncPassEntry.SetPlaceHolder("Passphrase (same on every device)")
+	syncPassEntry.SetText(s.config.SyncPassphrase)
+
+	syncSection := container.NewVBox(
+		syncLabel,
+		syncCheck,
+		container.NewBorder(nil, nil, nil, browseBtn, syncFolderEntry),
+		syncPassEntry,
+	)
+
+	// --- Backup ---
+	backupLabel := widget.NewLabel("Backup")
+	backupLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	exportBtn := widget.NewButton("Export settings...", func() {
+		s.showExport(window)
+	})
+	importBtn := widget.NewButton("Import settings...", func() {
+		s.showImport(window)
+	})
+
+	backupSection := container.NewVBox(
+		backupLabel,
+		container.NewHBox(exportBtn, importBtn),
+	)
+
+	// --- Advanced ---
+	advancedLabel := widget.NewLabel("Advanced")
+	advancedLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	resetBtn := widget.NewButton("Reset everything...", func() {
+		s.showReset(window)
+	})
+	resetBtn.Importance = widget.DangerImportance
+	if s.onReset == nil {
+		resetBtn.Disable()
+	}
+
+	advancedSection := container.NewVBox(
+		advancedLabel,
+		container.NewHBox(resetBtn),
 	)
 
 	// --- Buttons ---
@@ -211,8 +703,136 @@ func (s *SettingsDialog) Show() {
 		opacity, _ := opacityBinding.Get()
 		interval, _ := intervalBinding.Get()
 
+		if syncCheck.Checked && (syncFolderEntry.Text == "" || syncPassEntry.Text == "") {
+			dialog.ShowError(errors.New("sync needs both a folder and a passphrase"), window)
+			return
+		}
+
+		warning, errW := strconv.ParseFloat(strings.TrimSpace(warningEntry.Text), 64)
+		critical, errC := strconv.ParseFloat(strings.TrimSpace(criticalEntry.Text), 64)
+		if errW != nil || errC != nil || warning <= 0 || critical > 100 || warning >= critical {
+			dialog.ShowError(errors.New("warning must be below critical, both between 1 and 100"), window)
+			return
+		}
+
+		planTarget, err := strconv.ParseFloat(strings.TrimSpace(planTargetEntry.Text), 64)
+		if planCheck.Checked && (err != nil || planTarget <= 0 || planTarget > 100) {
+			dialog.ShowError(errors.New("weekly plan target must be a percentage between 1 and 100"), window)
+			return
+		}
+
+		costSource := costValues[costSelect.SelectedIndex()]
+		costSession, errSession := strconv.ParseFloat(strings.TrimSpace(costSessionEntry.Text), 64)
+		costWeekly, errWeek := strconv.ParseFloat(strings.TrimSpace(costWeeklyEntry.Text), 64)
+		if costSource == budget.CostUtilization && (errSession != nil || errWeek != nil || costSession <= 0 || costWeekly <= 0) {
+			dialog.ShowError(errors.New("the cost estimate needs the dollar value of a full session and week"), window)
+			return
+		}
+
+		dailySummary := strings.TrimSpace(dailySummaryEntry.Text)
+		if dailySummary == "" {
+			dailySummary = dailySummaryEntry.PlaceHolder
+		}
+		if _, _, err := history.ParseClock(dailySummary); dailySummaryCheck.Checked && err != nil {
+			dialog.ShowError(errors.New("daily summary time must be a 24-hour time like 18:00"), window)
+			return
+		}
+
+		serverPort, err := strconv.Atoi(strings.TrimSpace(serverPortEntry.Text))
+		if (serverCheck.Checked || dashboardCheck.Checked || metricsCheck.Checked) && (err != nil || serverPort < 1024 || serverPort > 65535) {
+			dialog.ShowError(errors.New("server port must be between 1024 and 65535"), window)
+			return
+		}
+		serverBind := strings.TrimSpace(serverBindEntry.Text)
+		if serverBind != "" && net.ParseIP(serverBind) == nil {
+			dialog.ShowError(errors.New("bind address must be an IP address, e.g. 0.0.0.0 or 192.168.1.20"), window)
+			return
+		}
+
 		s.config.OverlayOpacity = opacity
+		// Software opacity is only set in the config file, keep it
+		if s.config.OverlayOpacityMode != OpacitySoftware {
+			s.config.OverlayOpacityMode = OpacityWindow
+			if backgroundOnlyCheck.Checked {
+				s.config.OverlayOpacityMode = OpacityBackground
+			}
+		}
+		s.config.AdaptiveText = adaptiveTextCheck.Checked
+		s.config.DodgeTopmost = dodgeCheck.Checked
+		s.config.ClickThrough = clickThroughCheck.Checked
 		s.config.RefreshInterval = int(interval)
+		s.config.LightPolling = lightPollingCheck.Checked
+		s.config.SyncEnabled = syncCheck.Checked
+		s.config.SyncFolder = syncFolderEntry.Text
+		s.config.SyncPassphrase = syncPassEntry.Text
+		s.config.AutoStart = autoStartCheck.Checked
+		s.config.WarningThreshold = warning
+		s.config.CriticalThreshold = critical
+		s.config.AlertSound = soundCheck.Checked
+		s.config.FlashBorder = flashBorderCheck.Checked
+		s.config.FlashTaskbar = flashTaskbarCheck.Checked
+		s.config.StatsEnabled = statsCheck.Checked
+		s.config.DailySummaryTime = ""
+		if dailySummaryCheck.Checked {
+			s.config.DailySummaryTime = dailySummary
+		}
+		s.config.ServerEnabled = serverCheck.Checked
+		s.config.ServerBind = serverBind
+		s.config.ServerToken = strings.TrimSpace(serverTokenEntry.Text)
+		s.config.ServerTLS = serverTLSCheck.Checked
+		s.config.DashboardEnabled = dashboardCheck.Checked
+		s.config.MetricsEnabled = metricsCheck.Checked
+		s.config.DashboardToken = dashboardToken
+		if serverCheck.Checked || dashboardCheck.Checked || metricsCheck.Checked {
+			s.config.ServerPort = serverPort
+		}
+		s.config.BudgetEnabled = planCheck.Checked
+		if planCheck.Checked {
+			s.config.BudgetTarget = planTarget
+			s.config.BudgetDay = strings.ToLower(planDaySelect.Selected)
+		}
+		s.config.BudgetLock = planLockCheck.Checked
+		s.config.BarStyle = barStyleSelect.Selected
+		s.config.CompactBarStyle = compactBarStyleSelect.Selected
+		s.config.TrayTitle = trayTitleValues[trayTitleSelect.SelectedIndex()]
+		s.config.TrayClick = trayClickValues[trayClickSelect.SelectedIndex()]
+		s.config.RequestEstimate = requestEstimateValues[requestEstimateSelect.SelectedIndex()]
+		s.config.CostEstimate = costSource
+		if errSession == nil && errWeek == nil && costSession >= 0 && costWeekly >= 0 {
+			s.config.CostSession, s.config.CostWeekly = costSession, costWeekly
+		}
+		s.config.Precision = precisionSelect.SelectedIndex()
+		// Left alone when the config names an action the picker doesn't offer
+		if i := doublePressSelect.SelectedIndex(); i >= 0 {
+			if pressValues[i] == "" {
+				delete(s.config.DoublePress, "toggle_overlay")
+			} else {
+				if s.config.DoublePress == nil {
+					s.config.DoublePress = map[string]string{}
+				}
+				s.config.DoublePress["toggle_overlay"] = pressValues[i]
+			}
+		}
+		s.config.HotkeyExcludedApps = nil
+		for _, app := range strings.Split(excludedAppsEntry.Text, ",") {
+			if app = strings.TrimSpace(app); app != "" {
+				s.config.HotkeyExcludedApps = append(s.config.HotkeyExcludedApps, app)
+			}
+		}
+		s.config.Theme = themeSelect.Selected
+		if s.config.Theme == builtinThemeLabel {
+			s.config.Theme = ""
+		}
+
+		// Only touch the OS registration when the user changed it, so an
+		// installer-created entry isn't removed by an unrelated save
+		if autoStartCheck.Checked != autoStartInitial {
+			if err := autostart.Apply(autoStartCheck.Checked); err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			autoStartInitial = autoStartCheck.Checked
+		}
 
 		if err := s.config.Save(); err != nil {
 			dialog.ShowError(err, window)
@@ -243,9 +863,296 @@ func (s *SettingsDialog) Show() {
 		widget.NewSeparator(),
 		notifSection,
 		widget.NewSeparator(),
-		buttons,
+		hotkeysSection,
+		widget.NewSeparator(),
+		integrationsSection,
+		widget.NewSeparator(),
+		planSection,
+		widget.NewSeparator(),
+		costSection,
+		widget.NewSeparator(),
+		syncSection,
+		widget.NewSeparator(),
+		backupSection,
+		widget.NewSeparator(),
+		advancedSection,
 	)
 
-	window.SetContent(container.NewPadded(content))
+	// Scroll the sections and keep Save/Close in view
+	window.SetContent(withBackground(s.app, container.NewBorder(nil, container.NewPadded(buttons), nil, nil,
+		container.NewVScroll(container.NewPadded(content)))))
 	window.Show()
 }
+
+// authStatusText describes the active account's session key
+func (s *SettingsDialog) authStatusText() string {
+	if s.config.SessionKey == "" {
+		return "Not connected"
+	}
+	keyPreview := s.config.SessionKey[:min(16, len(s.config.SessionKey))]
+	if s.config.SessionKeyStorage == config.StorageKeychain {
+		return fmt.Sprintf("Active (%s..., in the keychain)", keyPreview)
+	}
+	return fmt.Sprintf("Active (%s...)", keyPreview)
+}
+
+// accountsSection lists the accounts to switch between, with ways to add,
+// rename and remove them. The session key and organization above belong to
+// the active account; onSwitched updates them after a switch.
+func (s *SettingsDialog) accountsSection(window fyne.Window, onSwitched func()) fyne.CanvasObject {
+	accountSelect := widget.NewSelect(nil, nil)
+	accountSelect.PlaceHolder = "Only one account"
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Account name")
+	switchBtn := widget.NewButton("Switch", nil)
+	renameBtn := widget.NewButton("Rename", nil)
+	removeBtn := widget.NewButton("Remove", nil)
+
+	update := func() {
+		names := s.config.AccountNames()
+		accountSelect.SetOptions(names)
+		accountSelect.Selected = ""
+		accountSelect.SetSelected(s.config.ActiveAccount)
+		for _, w := range []fyne.Disableable{accountSelect, switchBtn, renameBtn, removeBtn} {
+			if len(names) == 0 {
+				w.Disable()
+			} else {
+				w.Enable()
+			}
+		}
+	}
+	update()
+
+	// edited reports a failed change, or updates the list and the tray
+	edited := func(err error) bool {
+		if err != nil {
+			dialog.ShowError(err, window)
+			return false
+		}
+		nameEntry.SetText("")
+		update()
+		if s.onAccountsEdit != nil {
+			s.onAccountsEdit()
+		}
+		return true
+	}
+	switchTo := func(name string) {
+		if s.onAccountSwitch != nil {
+			s.onAccountSwitch(name)
+		}
+		update()
+		onSwitched()
+	}
+
+	addBtn := widget.NewButton("Add and Switch", func() {
+		if edited(s.config.AddAccount(nameEntry.Text)) {
+			names := s.config.AccountNames()
+			switchTo(names[len(names)-1])
+		}
+	})
+	switchBtn.OnTapped = func() {
+		if accountSelect.Selected != s.config.ActiveAccount {
+			switchTo(accountSelect.Selected)
+		}
+	}
+	renameBtn.OnTapped = func() {
+		edited(s.config.RenameAccount(accountSelect.Selected, nameEntry.Text))
+	}
+	removeBtn.OnTapped = func() {
+		name := accountSelect.Selected
+		dialog.ShowConfirm("Remove account?",
+			fmt.Sprintf("Forgets the session key and organization of %q.", name),
+			func(ok bool) {
+				if ok {
+					edited(s.config.RemoveAccount(name))
+				}
+			}, window)
+	}
+
+	overlayCheck := widget.NewCheck("Show the other accounts' usage in the overlay", func(checked bool) {
+		s.config.OverlayAllAccounts = checked
+	})
+	overlayCheck.SetChecked(s.config.OverlayAllAccounts)
+
+	explain := widget.NewLabel("Personal and work accounts, or one account in several\n" +
+		"organizations. Switch from the tray's Accounts menu.")
+	return widget.NewAccordion(widget.NewAccordionItem("Accounts",
+		container.NewVBox(
+			explain,
+			accountSelect,
+			container.NewHBox(switchBtn, renameBtn, removeBtn),
+			nameEntry,
+			container.NewHBox(addBtn),
+			overlayCheck,
+		)))
+}
+
+// showExport asks for a passphrase and saves an encrypted settings bundle
+func (s *SettingsDialog) showExport(window fyne.Window) {
+	passEntry := widget.NewPasswordEntry()
+	historyCheck := widget.NewCheck("Include usage history", nil)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Passphrase", passEntry),
+		widget.NewFormItem("", historyCheck),
+	}
+	dialog.ShowForm("Export settings", "Export", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		if passEntry.Text == "" {
+			dialog.ShowError(errors.New("a passphrase is required"), window)
+			return
+		}
+
+		data, err := backup.Export(passEntry.Text, historyCheck.Checked)
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+
+		save := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+			if err != nil || w == nil {
+				return
+			}
+			defer w.Close()
+			if _, err := w.Write(data); err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			dialog.ShowInformation("Exported", "Settings exported to\n"+w.URI().Path(), window)
+		}, window)
+		save.SetFileName("claudebar-settings" + backup.Extension)
+		save.Show()
+	}, window)
+}
+
+// showImport picks a bundle, asks for its passphrase and restores it.
+// The settings window is closed afterwards so stale values can't be saved over
+// the imported ones.
+func (s *SettingsDialog) showImport(window fyne.Window) {
+	dialog.ShowFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil || r == nil {
+			return
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+
+		passEntry := widget.NewPasswordEntry()
+		note := widget.NewLabel("Usage history in the bundle replaces the history recorded here.")
+		note.Wrapping = fyne.TextWrapWord
+		items := []*widget.FormItem{
+			widget.NewFormItem("Passphrase", passEntry),
+			widget.NewFormItem("", note),
+		}
+		dialog.ShowForm("Import settings", "Import", "Cancel", items, func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := backup.Import(data, passEntry.Text, s.history); err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			if s.onImported != nil {
+				s.onImported()
+			}
+			s.app.SendNotification(fyne.NewNotification("ClaudeBar", "Settings imported"))
+			window.Close()
+		}, window)
+	}, window)
+}
+
+// showReset confirms and runs a reset of the saved state. The settings
+// window is closed afterwards so stale values can't be saved over the reset.
+func (s *SettingsDialog) showReset(window fyne.Window) {
+	explain := widget.NewLabel("Signs out and forgets the session key, organization and overlay position, " +
+		"then looks for a session again as on first start. Other settings are kept, " +
+		"and a backup of everything is saved first.")
+	explain.Wrapping = fyne.TextWrapWord
+	historyCheck := widget.NewCheck("Also delete usage history", nil)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("", explain),
+		widget.NewFormItem("", historyCheck),
+	}
+	form := dialog.NewForm("Reset everything?", "Reset", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		path, err := s.onReset(historyCheck.Checked)
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		window.Close()
+		s.app.SendNotification(fyne.NewNotification("ClaudeBar reset", "A backup was saved to "+path))
+	}, window)
+	form.Resize(fyne.NewSize(360, 240))
+	form.Show()
+}
+
+// barStyleNames lists the bar styles offered for a layout as select options
+func barStyleNames(compact bool) []string {
+	var names []string
+	for _, style := range widgets.BarStyles(compact) {
+		names = append(names, string(style))
+	}
+	return names
+}
+
+// weekdayNames lists the days for the weekly plan deadline, Monday first
+func weekdayNames() []string {
+	var names []string
+	for i := 1; i <= 7; i++ {
+		names = append(names, time.Weekday(i%7).String())
+	}
+	return names
+}
+
+// dashboardURL builds the link to open the LAN dashboard on another device,
+// using the bind address if it is a specific one and otherwise the first
+// network address of this machine
+func dashboardURL(https bool, bind, port, token string) string {
+	host := bind
+	if ip := net.ParseIP(bind); ip == nil || ip.IsUnspecified() {
+		host = "localhost"
+		if addrs := server.LANAddresses(); len(addrs) > 0 {
+			host = addrs[0]
+		}
+	}
+	scheme := "http"
+	if https {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/?token=%s", scheme, net.JoinHostPort(host, port), token)
+}
+
+// hotkeyStatusText describes the hotkey listener's health, e.g. "Status:
+// working, restarted 2 times (last error at 14:05: GetMessage failed: ...)"
+func hotkeyStatusText(h platform.HotkeyHealth) string {
+	var lastErr string
+	if h.LastError != nil {
+		lastErr = fmt.Sprintf("at %s: %v", h.LastErrorAt.Format("15:04"), h.LastError)
+	}
+	switch {
+	case h.Suspended:
+		return "Status: paused"
+	case h.Restarting:
+		return "Status: restarting after an error " + lastErr
+	case !h.Running && lastErr != "":
+		return "Status: not working, error " + lastErr
+	case !h.Running:
+		return "Status: not running"
+	case h.Restarts == 1:
+		return fmt.Sprintf("Status: working, restarted once (error %s)", lastErr)
+	case h.Restarts > 1:
+		return fmt.Sprintf("Status: working, restarted %d times (last error %s)", h.Restarts, lastErr)
+	case lastErr != "":
+		return "Status: working, last error " + lastErr
+	}
+	return "Status: working"
+}
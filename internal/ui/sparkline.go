@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// sparklineHeight is the height of the trend line under a usage row, px
+	sparklineHeight = 16
+	// sparkGaps is how many gaps of span/sparkGaps fit in a chart; points
+	// further apart, e.g. while ClaudeBar wasn't running, break the line
+	sparkGaps = 12
+)
+
+// Spans of the trend lines under the session and weekly rows
+const (
+	SessionSparkSpan = 5 * time.Hour
+	WeeklySparkSpan  = 7 * 24 * time.Hour
+)
+
+// SparkPoint is a utilization (0-100) at a point in time
+type SparkPoint struct {
+	At    time.Time
+	Value float64
+}
+
+// Sparkline is a small line chart of how a metric's utilization moved over
+// a fixed span, e.g. the last five hours, drawn in the metric's bar color
+type Sparkline struct {
+	widget.BaseWidget
+	metric string
+	span   time.Duration
+	points []SparkPoint
+	end    time.Time // right edge of the chart
+}
+
+// NewSparkline creates an empty chart of the named metric covering span
+func NewSparkline(metric string, span time.Duration) *Sparkline {
+	s := &Sparkline{metric: metric, span: span}
+	s.ExtendBaseWidget(s)
+	return s
+}
+
+// SetPoints sets the points to draw, oldest first, in a chart ending at end
+func (s *Sparkline) SetPoints(points []SparkPoint, end time.Time) {
+	s.points, s.end = points, end
+	s.Refresh()
+}
+
+// Empty reports whether there are too few points to draw a line
+func (s *Sparkline) Empty() bool {
+	return len(s.points) < 2
+}
+
+func (s *Sparkline) CreateRenderer() fyne.WidgetRenderer {
+	return &sparklineRenderer{chart: s, baseline: canvas.NewRectangle(activeTheme.Colors.BarTrack)}
+}
+
+type sparklineRenderer struct {
+	chart    *Sparkline
+	baseline *canvas.Rectangle
+	lines    []*canvas.Line // one per segment, extra ones hidden
+	size     fyne.Size
+}
+
+func (r *sparklineRenderer) Layout(size fyne.Size) {
+	r.size = size
+	r.baseline.Move(fyne.NewPos(0, size.Height-1))
+	r.baseline.Resize(fyne.NewSize(size.Width, 1))
+
+	segments := sparkSegments(r.chart.points, r.chart.end, r.chart.span)
+	for len(r.lines) < len(segments) {
+		line := canvas.NewLine(sparkColor(r.chart.metric))
+		line.StrokeWidth = 1.5
+		r.lines = append(r.lines, line)
+	}
+	// y is flipped: 100% at the top, 0% on the baseline
+	for i, line := range r.lines {
+		if i >= len(segments) {
+			line.Hide()
+			continue
+		}
+		seg := segments[i]
+		line.Position1 = fyne.NewPos(seg[0]*size.Width, (1-seg[1])*(size.Height-1))
+		line.Position2 = fyne.NewPos(seg[2]*size.Width, (1-seg[3])*(size.Height-1))
+		line.Show()
+	}
+}
+
+func (r *sparklineRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(0, sparklineHeight)
+}
+
+func (r *sparklineRenderer) Refresh() {
+	r.baseline.FillColor = activeTheme.Colors.BarTrack
+	for _, line := range r.lines {
+		line.StrokeColor = sparkColor(r.chart.metric)
+	}
+	r.Layout(r.size)
+	for _, o := range r.Objects() {
+		o.Refresh()
+	}
+}
+
+func (r *sparklineRenderer) Objects() []fyne.CanvasObject {
+	objects := []fyne.CanvasObject{r.baseline}
+	for _, line := range r.lines {
+		objects = append(objects, line)
+	}
+	return objects
+}
+
+func (r *sparklineRenderer) Destroy() {}
+
+// sparkColor is the bar color of metric, without warning or critical
+// colors: the line shows the trend, the bar above it the level
+func sparkColor(metric string) color.Color {
+	if c, ok := activeTheme.Colors.Metrics[metric]; ok {
+		return c
+	}
+	return activeTheme.Colors.BarFill
+}
+
+// sparkSegments returns the line segments joining points as x1, y1, x2, y2
+// scaled to 0-1: x across the span ending at end, y from 0% to 100%.
+// Segments across a gap longer than span/sparkGaps or entirely before the
+// span are left out.
+func sparkSegments(points []SparkPoint, end time.Time, span time.Duration) [][4]float32 {
+	if span <= 0 {
+		return nil
+	}
+	start := end.Add(-span)
+	scale := func(p SparkPoint) (x, y float32) {
+		x = float32(p.At.Sub(start)) / float32(span)
+		y = float32(p.Value / 100)
+		return min(max(x, 0), 1), min(max(y, 0), 1)
+	}
+
+	var segments [][4]float32
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		if !b.At.After(start) || b.At.Sub(a.At) > span/sparkGaps {
+			continue
+		}
+		x1, y1 := scale(a)
+		x2, y2 := scale(b)
+		segments = append(segments, [4]float32{x1, y1, x2, y2})
+	}
+	return segments
+}
+
+// SetSparklines sets the session utilization of the last five hours and the
+// weekly utilization of the last seven days, oldest first and ending at end,
+// drawn as trend lines under their rows. Rows get a line once there are two
+// points to join.
+func (o *OverlayWindow) SetSparklines(session, weekly []SparkPoint, end time.Time) {
+	o.sessionPoints, o.weeklyPoints, o.sparkEnd = session, weekly, end
+	if o.sessionSpark == nil {
+		return
+	}
+	o.sessionSpark.SetPoints(session, end)
+	o.weeklySpark.SetPoints(weekly, end)
+	if o.initialized && o.laidOut != nil && *o.laidOut != o.currentLayoutKey() {
+		o.applyLayout()
+		o.snapToPosition(o.position)
+	}
+}
+
+// showSpark reports whether the trend line s goes into the layout
+func (o *OverlayWindow) showSpark(s *Sparkline) bool {
+	return s != nil && !s.Empty() && o.config.IsStatVisible("sparklines")
+}
@@ -1,33 +1,102 @@
 package ui
 
 import (
+	"claudebar/internal/alert"
 	"claudebar/internal/api"
 	"claudebar/internal/assets"
+	"claudebar/internal/budget"
+	"claudebar/internal/config"
+	"claudebar/internal/platform"
 	"fmt"
 	"log"
+	"math"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/systray"
 )
 
-// TrayManager handles the system tray icon and menu
+// Tray title metrics (config.TrayTitle)
+const (
+	TrayTitleOff     = ""
+	TrayTitleSession = "session"
+	TrayTitleWeekly  = "weekly"
+	TrayTitleHighest = "highest"
+)
+
+// Tray icon left-click actions (config.TrayClick)
+const (
+	TrayClickMenu     = ""
+	TrayClickOverlay  = "overlay"
+	TrayClickSettings = "settings"
+	TrayClickRefresh  = "refresh"
+)
+
+// trayPositions are the snap positions offered in the Position submenu
+var trayPositions = []struct {
+	label string
+	pos   platform.SnapPosition
+}{
+	{"Top", platform.SnapTop},
+	{"Top Left", platform.SnapTopLeft},
+	{"Top Right", platform.SnapTopRight},
+	{"Left", platform.SnapLeft},
+	{"Right", platform.SnapRight},
+	{"Bottom Left", platform.SnapBottomLeft},
+	{"Bottom Right", platform.SnapBottomRight},
+	{"Floating", platform.SnapNone},
+}
+
+// trayOpacities are the presets offered in the Opacity submenu
+var trayOpacities = []float64{1, 0.85, 0.7, 0.55, 0.4}
+
+// TrayManager handles the system tray icon and menu. The menu is declared by
+// model and rebuilt with Refresh whenever the state it shows changes.
 type TrayManager struct {
-	app           fyne.App
-	menu          *fyne.Menu
-	usageItems    []*fyne.MenuItem
-	onShowOverlay func()
-	onHideOverlay func()
-	onSettings    func()
-	onRefresh     func()
-	onQuit        func()
+	app    fyne.App
+	config *config.Config
+	menu   *fyne.Menu
+	caps   platform.Capabilities
+
+	onShowOverlay  func()
+	onHideOverlay  func()
+	onSettings     func()
+	onRefresh      func()
+	onQuit         func()
+	onResetPos     func()
+	onDiagnostics  func()
+	onWhatsNew     func()
+	onReport       func()
+	onHistory      func()
+	onPauseHotkey  func(paused bool) error
+	onProfile      func(name string)
+	onSnap         func(pos platform.SnapPosition)
+	onOpacity      func(opacity float64)
+	onReloadAuth   func()
+	onAccount      func(name string)
+	onOpenClaude   func()
+	onClickThrough func()
+
+	usage         *api.UsageData
+	cost          string // estimated value of the session and week, "" when off
 	overlayShown  bool
+	hotkeysPaused bool
+	account       string
+	signedIn      bool
+	lightTheme    bool
+	severity      alert.Severity
+	click         *string // the TrayClick applied, nil before the first
 }
 
 // NewTrayManager creates a new tray manager
 func NewTrayManager(app fyne.App) *TrayManager {
 	return &TrayManager{
 		app:          app,
+		config:       config.Get(),
 		overlayShown: true,
+		account:      "Signing in...",
 	}
 }
 
@@ -42,62 +111,397 @@ func (t *TrayManager) SetCallbacks(
 	t.onQuit = onQuit
 }
 
+// SetClickThroughCallback sets the callback for the "Click-through overlay"
+// item, which is hidden while none is set
+func (t *TrayManager) SetClickThroughCallback(onClickThrough func()) {
+	t.onClickThrough = onClickThrough
+}
+
+// SetResetPositionCallback sets the callback for the "Reset position" item
+func (t *TrayManager) SetResetPositionCallback(onResetPos func()) {
+	t.onResetPos = onResetPos
+}
+
+// SetDiagnosticsCallback sets the callback for the "Diagnostics" item
+func (t *TrayManager) SetDiagnosticsCallback(onDiagnostics func()) {
+	t.onDiagnostics = onDiagnostics
+}
+
+// SetHelpCallbacks sets the callbacks for "What's New" and "Report a Problem"
+// in the "Help" submenu
+func (t *TrayManager) SetHelpCallbacks(onWhatsNew, onReport func()) {
+	t.onWhatsNew = onWhatsNew
+	t.onReport = onReport
+}
+
+// SetHistoryCallback sets the callback for the "History" item, which is
+// hidden until one is set
+func (t *TrayManager) SetHistoryCallback(onHistory func()) {
+	t.onHistory = onHistory
+}
+
+// SetPauseHotkeysCallback sets the callback for the "Pause Hotkeys" item,
+// which releases the global hotkeys to other apps until unchecked
+func (t *TrayManager) SetPauseHotkeysCallback(onPause func(paused bool) error) {
+	t.onPauseHotkey = onPause
+}
+
+// SetAlertProfileCallback sets the callback for the profiles in the "Alerts"
+// submenu, called with the picked profile name or "" for automatic
+func (t *TrayManager) SetAlertProfileCallback(onProfile func(name string)) {
+	t.onProfile = onProfile
+}
+
+// SetPositionCallback sets the callback for the "Position" submenu
+func (t *TrayManager) SetPositionCallback(onSnap func(pos platform.SnapPosition)) {
+	t.onSnap = onSnap
+}
+
+// SetOpacityCallback sets the callback for the "Opacity" submenu
+func (t *TrayManager) SetOpacityCallback(onOpacity func(opacity float64)) {
+	t.onOpacity = onOpacity
+}
+
+// SetReloadAccountCallback sets the callback for "Reload From Browser" in the
+// "Accounts" submenu. The item is disabled while no callback is set.
+func (t *TrayManager) SetReloadAccountCallback(onReload func()) {
+	t.onReloadAuth = onReload
+}
+
+// SetAccountCallback sets the callback for the accounts listed in the
+// "Accounts" submenu, called with the name of the account to switch to
+func (t *TrayManager) SetAccountCallback(onAccount func(name string)) {
+	t.onAccount = onAccount
+}
+
+// SetOpenClaudeCallback sets the callback for the "Open claude.ai" item,
+// which is hidden until one is set
+func (t *TrayManager) SetOpenClaudeCallback(onOpen func()) {
+	t.onOpenClaude = onOpen
+}
+
 // Setup initializes the system tray
 func (t *TrayManager) Setup() error {
-	if desk, ok := t.app.(desktop.App); ok {
-		// Create menu items
-		toggleItem := fyne.NewMenuItem("Hide Overlay", t.toggleOverlay)
-
-		// Usage display items (will be updated)
-		t.usageItems = []*fyne.MenuItem{
-			fyne.NewMenuItem("Session: --", nil),
-			fyne.NewMenuItem("Weekly: --", nil),
-		}
-		// Disable clicking on info items
-		for _, item := range t.usageItems {
-			item.Disabled = true
-		}
+	desk, ok := t.app.(desktop.App)
+	if !ok {
+		return fmt.Errorf("system tray not supported on this platform")
+	}
+
+	t.caps = platform.Features.Capabilities()
+	t.menu = fyne.NewMenu("ClaudeBar", buildMenuItems(t.model())...)
 
-		separator := fyne.NewMenuItemSeparator()
+	desk.SetSystemTrayMenu(t.menu)
+	desk.SetSystemTrayIcon(assets.TrayIconForSeverity(t.lightTheme, t.severity))
+	t.applyClick()
+	log.Println("System tray initialized")
+	return nil
+}
 
-		refreshItem := fyne.NewMenuItem("Refresh Now", func() {
-			if t.onRefresh != nil {
+// Refresh rebuilds the menu from the current config and state, e.g. after
+// the visible stats were changed in the settings
+func (t *TrayManager) Refresh() {
+	if t.menu == nil {
+		return
+	}
+	t.menu.Items = buildMenuItems(t.model())
+	t.menu.Refresh()
+	t.applyClick()
+}
+
+// applyClick sets what a left click on the tray icon does when TrayClick
+// changed. Without an action systray opens the menu on Windows and macOS.
+// Linux trays read whether the icon only has a menu when it is registered,
+// so there switching to or from the menu takes effect after a restart.
+func (t *TrayManager) applyClick() {
+	click := t.config.TrayClick
+	if t.click != nil && *t.click == click {
+		return
+	}
+	t.click = &click
+
+	var action func()
+	switch click {
+	case TrayClickOverlay:
+		action = t.toggleOverlay
+	case TrayClickSettings:
+		action = call(t.onSettings)
+	case TrayClickRefresh:
+		action = func() {
+			if t.signedIn && t.onRefresh != nil {
 				t.onRefresh()
 			}
+		}
+	default:
+		systray.SetOnTapped(nil)
+		return
+	}
+	// systray calls back on its own goroutine
+	systray.SetOnTapped(func() { fyne.Do(action) })
+}
+
+// model declares the whole tray menu
+func (t *TrayManager) model() []trayEntry {
+	toggle := "Hide Overlay"
+	if !t.overlayShown {
+		toggle = "Show Overlay"
+	}
+
+	entries := []trayEntry{
+		{Label: toggle, Action: t.toggleOverlay},
+		traySeparator(),
+	}
+	entries = append(entries, t.usageEntries()...)
+	return append(entries,
+		traySeparator(),
+		trayEntry{Label: "Refresh Now", Action: call(t.onRefresh), Disabled: !t.signedIn},
+		trayEntry{Label: "Open claude.ai", Action: call(t.onOpenClaude), Hidden: t.onOpenClaude == nil},
+		trayEntry{Label: "Position", Children: t.positionEntries()},
+		trayEntry{Label: "Opacity", Children: t.opacityEntries(), Hidden: !t.caps.Transparency},
+		trayEntry{Label: "Click-through overlay", Action: call(t.onClickThrough), Checked: t.config.ClickThrough, Hidden: !t.caps.ClickThrough || t.onClickThrough == nil},
+		trayEntry{Label: "Alerts", Children: t.alertEntries()},
+		trayEntry{Label: "Accounts", Children: t.accountEntries()},
+		trayEntry{Label: "Pause Hotkeys", Action: t.togglePauseHotkeys, Checked: t.hotkeysPaused, Hidden: !t.caps.Hotkeys},
+		traySeparator(),
+		trayEntry{Label: "History...", Action: call(t.onHistory), Hidden: t.onHistory == nil},
+		trayEntry{Label: "Settings...", Action: call(t.onSettings)},
+		trayEntry{Label: "Help", Children: []trayEntry{
+			{Label: "What's New...", Action: call(t.onWhatsNew)},
+			{Label: "Report a Problem...", Action: call(t.onReport)},
+			{Label: "Diagnostics...", Action: call(t.onDiagnostics)},
+		}},
+		traySeparator(),
+		trayEntry{Label: "Quit", Action: call(t.onQuit)},
+	)
+}
+
+// usageEntries lists one line per metric enabled in the visible stats and
+// when they were fetched. The per-model weekly limits only appear for
+// accounts that have them.
+func (t *TrayManager) usageEntries() []trayEntry {
+	var u api.UsageData
+	if t.usage != nil {
+		u = *t.usage
+	}
+	vis := t.config.VisibleStats
+
+	metrics := []struct {
+		label string
+		stat  api.UsageStat
+		shown bool
+	}{
+		{"Session", u.FiveHour, vis.SessionUsage},
+		{"Weekly", u.SevenDay, vis.WeeklyUsage},
+		{"Weekly Opus", u.SevenDayOpus, vis.WeeklyUsage && !u.SevenDayOpus.ResetsAt.IsZero()},
+		{"Weekly Sonnet", u.SevenDaySonnet, vis.WeeklyUsage && !u.SevenDaySonnet.ResetsAt.IsZero()},
+	}
+
+	var entries []trayEntry
+	for _, m := range metrics {
+		line := trayInfo(t.usageLine(m.label, m.stat))
+		line.Hidden = !m.shown
+		entries = append(entries, line)
+	}
+	cost := trayInfo(t.cost)
+	cost.Hidden = t.cost == ""
+	entries = append(entries, cost)
+	updated := trayInfo("Updated " + u.LastUpdated.Format("15:04"))
+	updated.Hidden = u.LastUpdated.IsZero()
+	return append(entries, updated)
+}
+
+// usageLine formats one metric as a text gauge and percentage, with a ⚠
+// prefix from the warning level on and the reset timer in brackets when reset
+// times are visible. Plain text, so it reads the same in every tray.
+func (t *TrayManager) usageLine(label string, stat api.UsageStat) string {
+	if t.usage == nil {
+		return label + ": --"
+	}
+	line := fmt.Sprintf("%s: %s %s", label, textGauge(stat.Utilization), t.config.FormatPercent(stat.Utilization))
+	if t.config.AlertLevels().Classify(stat.Utilization) != alert.None {
+		line = "⚠ " + line
+	}
+	if t.config.VisibleStats.ResetTime {
+		line += fmt.Sprintf(" (resets %s)", api.TimeUntilReset(stat.ResetsAt))
+	}
+	return line
+}
+
+// textGaugeCells is the width of the tray menu gauges
+const textGaugeCells = 5
+
+// textGauge draws a percentage as filled and empty cells, e.g. ▰▰▰▱▱ for 60%
+func textGauge(pct float64) string {
+	filled := min(max(int(math.Round(pct/(100/textGaugeCells))), 0), textGaugeCells)
+	return strings.Repeat("▰", filled) + strings.Repeat("▱", textGaugeCells-filled)
+}
+
+// positionEntries lists the snap positions, checking the current one
+func (t *TrayManager) positionEntries() []trayEntry {
+	var entries []trayEntry
+	for _, p := range trayPositions {
+		pos := p.pos
+		entries = append(entries, trayEntry{
+			Label:   p.label,
+			Checked: t.config.OverlayPosition == string(pos),
+			Action: func() {
+				if t.onSnap != nil {
+					t.onSnap(pos)
+				}
+				t.Refresh()
+			},
 		})
+	}
+	return append(entries,
+		traySeparator(),
+		trayEntry{Label: "Reset Position", Action: call(t.onResetPos)},
+	)
+}
 
-		settingsItem := fyne.NewMenuItem("Settings...", func() {
-			if t.onSettings != nil {
-				t.onSettings()
-			}
+// opacityEntries lists the opacity presets, checking the one in use at the
+// current position
+func (t *TrayManager) opacityEntries() []trayEntry {
+	var entries []trayEntry
+	for _, opacity := range trayOpacities {
+		entries = append(entries, trayEntry{
+			Label:   fmt.Sprintf("%.0f%%", opacity*100),
+			Checked: math.Abs(t.config.OpacityFor(t.config.OverlayPosition)-opacity) < 0.01,
+			Action: func() {
+				if t.onOpacity != nil {
+					t.onOpacity(opacity)
+				}
+				t.Refresh()
+			},
 		})
+	}
+	return entries
+}
+
+// alertEntries lists the notification switch and the alert profiles,
+// checking the picked profile and naming the scheduled one in effect
+func (t *TrayManager) alertEntries() []trayEntry {
+	entries := []trayEntry{
+		{Label: "Notifications", Checked: t.config.NotificationsEnabled, Action: t.toggleNotifications},
+		traySeparator(),
+	}
+
+	auto := "Automatic"
+	if t.config.AlertProfile == "" {
+		if p := t.config.ActiveAlertProfile(time.Now()); p != nil {
+			auto = fmt.Sprintf("Automatic (%s)", p.Name)
+		}
+	}
+	entries = append(entries, t.profileEntry(auto, ""))
+
+	if len(t.config.AlertProfiles) == 0 {
+		return append(entries, trayInfo("No profiles in config"))
+	}
+	entries = append(entries, traySeparator())
+	for _, p := range t.config.AlertProfiles {
+		entries = append(entries, t.profileEntry(p.Name, p.Name))
+	}
+	return entries
+}
 
-		quitItem := fyne.NewMenuItem("Quit", func() {
-			if t.onQuit != nil {
-				t.onQuit()
+func (t *TrayManager) profileEntry(label, name string) trayEntry {
+	return trayEntry{
+		Label:   label,
+		Checked: t.config.AlertProfile == name,
+		Action: func() {
+			if t.onProfile != nil {
+				t.onProfile(name)
 			}
+			t.Refresh()
+		},
+	}
+}
+
+// accountEntries shows the sign-in state, the accounts to switch between
+// with the active one checked, and ways to get a new session key
+func (t *TrayManager) accountEntries() []trayEntry {
+	entries := []trayEntry{trayInfo(t.account), traySeparator()}
+	for _, name := range t.config.AccountNames() {
+		entries = append(entries, trayEntry{
+			Label:   name,
+			Checked: name == t.config.ActiveAccount,
+			Action: func() {
+				if t.onAccount != nil && name != t.config.ActiveAccount {
+					t.onAccount(name)
+				}
+				t.Refresh()
+			},
 		})
+	}
+	return append(entries,
+		traySeparator(),
+		trayEntry{Label: "Reload From Browser", Action: call(t.onReloadAuth), Disabled: t.onReloadAuth == nil},
+		trayEntry{Label: "Session Key...", Action: call(t.onSettings)},
+	)
+}
+
+// SetAccountStatus updates the sign-in line of the "Accounts" submenu.
+// "Refresh Now" is disabled while signed out.
+func (t *TrayManager) SetAccountStatus(status string, signedIn bool) {
+	if status == t.account && signedIn == t.signedIn {
+		return
+	}
+	t.account = status
+	t.signedIn = signedIn
+	t.Refresh()
+}
+
+// toggleNotifications switches usage alerts on or off
+func (t *TrayManager) toggleNotifications() {
+	t.config.NotificationsEnabled = !t.config.NotificationsEnabled
+	if err := t.config.Save(); err != nil {
+		log.Printf("Failed to save notification setting: %v", err)
+	}
+	t.Refresh()
+}
 
-		// Build menu
-		t.menu = fyne.NewMenu("ClaudeBar",
-			toggleItem,
-			separator,
-			t.usageItems[0],
-			t.usageItems[1],
-			separator,
-			refreshItem,
-			settingsItem,
-			separator,
-			quitItem,
-		)
+// togglePauseHotkeys releases or re-registers the global hotkeys
+func (t *TrayManager) togglePauseHotkeys() {
+	if t.onPauseHotkey == nil {
+		return
+	}
+	// Some hotkeys may fail to come back (taken by another app in the
+	// meantime); the state still flips, so the item does too
+	paused := !t.hotkeysPaused
+	if err := t.onPauseHotkey(paused); err != nil {
+		log.Printf("Pause hotkeys (%v): %v", paused, err)
+	}
+	t.hotkeysPaused = paused
+	t.Refresh()
+}
+
+// call returns an action running fn, or doing nothing when fn is nil
+func call(fn func()) func() {
+	return func() {
+		if fn != nil {
+			fn()
+		}
+	}
+}
+
+// SetLightTheme switches the tray icon to the variant matching the taskbar theme
+func (t *TrayManager) SetLightTheme(light bool) {
+	t.lightTheme = light
+	t.refreshIcon()
+}
 
-		desk.SetSystemTrayMenu(t.menu)
-		desk.SetSystemTrayIcon(assets.TrayIcon())
-		log.Println("System tray initialized")
-		return nil
+// setSeverity marks the tray icon with the alert state of the highest usage
+func (t *TrayManager) setSeverity(severity alert.Severity) {
+	if severity == t.severity {
+		return
 	}
+	t.severity = severity
+	t.refreshIcon()
+}
 
-	return fmt.Errorf("system tray not supported on this platform")
+// refreshIcon shows the icon for the current taskbar theme and severity
+func (t *TrayManager) refreshIcon() {
+	if desk, ok := t.app.(desktop.App); ok && t.menu != nil {
+		desk.SetSystemTrayIcon(assets.TrayIconForSeverity(t.lightTheme, t.severity))
+	}
 }
 
 // toggleOverlay handles the show/hide toggle
@@ -106,57 +510,67 @@ func (t *TrayManager) toggleOverlay() {
 		if t.onHideOverlay != nil {
 			t.onHideOverlay()
 		}
-		t.overlayShown = false
-		// Update menu item text
-		if t.menu != nil && len(t.menu.Items) > 0 {
-			t.menu.Items[0].Label = "Show Overlay"
-			t.menu.Refresh()
-		}
-	} else {
-		if t.onShowOverlay != nil {
-			t.onShowOverlay()
-		}
-		t.overlayShown = true
-		if t.menu != nil && len(t.menu.Items) > 0 {
-			t.menu.Items[0].Label = "Hide Overlay"
-			t.menu.Refresh()
-		}
+	} else if t.onShowOverlay != nil {
+		t.onShowOverlay()
 	}
+	t.overlayShown = !t.overlayShown
+	t.Refresh()
 }
 
 // UpdateUsage updates the usage display in the tray menu
 func (t *TrayManager) UpdateUsage(data *api.UsageData) {
-	if data == nil || t.usageItems == nil {
+	if data == nil {
 		return
 	}
 
-	// Update session usage with reset timer in brackets
-	if t.usageItems[0] != nil {
-		reset := api.TimeUntilReset(data.FiveHour.ResetsAt)
-		t.usageItems[0].Label = fmt.Sprintf("Session: %.0f%% (resets %s)", data.FiveHour.Utilization, reset)
+	t.usage = data
+	t.Refresh()
+	t.updateTitle(data)
+	t.setSeverity(t.config.AlertLevels().Classify(math.Max(data.FiveHour.Utilization, data.SevenDay.Utilization)))
+}
+
+// SetCost sets the estimated value of the session and week listed with the
+// usage; nil hides it
+func (t *TrayManager) SetCost(c *budget.Cost) {
+	text := ""
+	if c != nil {
+		text = c.String()
 	}
+	if text != t.cost {
+		t.cost = text
+		t.Refresh()
+	}
+}
 
-	// Update weekly usage with reset timer in brackets
-	if t.usageItems[1] != nil {
-		reset := api.TimeUntilReset(data.SevenDay.ResetsAt)
-		t.usageItems[1].Label = fmt.Sprintf("Weekly: %.0f%% (resets %s)", data.SevenDay.Utilization, reset)
+// updateTitle shows the configured metric as text next to the tray icon.
+// systray displays it as the menu bar title on macOS and publishes it as the
+// StatusNotifierItem title on Linux; Windows tray icons can't carry text, so
+// the tooltip is used there instead.
+func (t *TrayManager) updateTitle(data *api.UsageData) {
+	if t.menu == nil {
+		return
 	}
 
-	// Refresh the menu
-	if t.menu != nil {
-		t.menu.Refresh()
+	var pct float64
+	switch t.config.TrayTitle {
+	case TrayTitleSession:
+		pct = data.FiveHour.Utilization
+	case TrayTitleWeekly:
+		pct = data.SevenDay.Utilization
+	case TrayTitleHighest:
+		pct = math.Max(data.FiveHour.Utilization, data.SevenDay.Utilization)
+	default:
+		systray.SetTitle("")
+		systray.SetTooltip("ClaudeBar")
+		return
 	}
+
+	systray.SetTitle(fmt.Sprintf("%.0f%%", pct))
+	systray.SetTooltip("ClaudeBar - " + t.config.FormatPercent(pct))
 }
 
 // SetOverlayState updates the tray to reflect overlay visibility
 func (t *TrayManager) SetOverlayState(shown bool) {
 	t.overlayShown = shown
-	if t.menu != nil && len(t.menu.Items) > 0 {
-		if shown {
-			t.menu.Items[0].Label = "Hide Overlay"
-		} else {
-			t.menu.Items[0].Label = "Show Overlay"
-		}
-		t.menu.Refresh()
-	}
+	t.Refresh()
 }
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+)
+
+// trayEntry declares one item of the tray menu. The menu is rebuilt from its
+// entries whenever the state behind them changes, so items can come and go or
+// change label, check mark or enabled state without keeping item pointers.
+type trayEntry struct {
+	Label     string
+	Action    func()
+	Checked   bool
+	Disabled  bool
+	Hidden    bool
+	Separator bool
+	Children  []trayEntry // entries of a submenu; Action is ignored
+}
+
+// traySeparator is a separator line between groups of entries
+func traySeparator() trayEntry {
+	return trayEntry{Separator: true}
+}
+
+// trayInfo is a disabled line showing information rather than an action
+func trayInfo(label string) trayEntry {
+	return trayEntry{Label: label, Disabled: true}
+}
+
+// buildMenuItems turns entries into menu items. Hidden entries and submenus
+// left empty are dropped, as are separators that would end up leading,
+// trailing or next to each other once items are gone.
+func buildMenuItems(entries []trayEntry) []*fyne.MenuItem {
+	var items []*fyne.MenuItem
+	pendingSeparator := false
+	for _, e := range entries {
+		if e.Hidden {
+			continue
+		}
+		if e.Separator {
+			pendingSeparator = len(items) > 0
+			continue
+		}
+
+		item := fyne.NewMenuItem(e.Label, e.Action)
+		item.Checked = e.Checked
+		item.Disabled = e.Disabled
+		if e.Children != nil {
+			children := buildMenuItems(e.Children)
+			if len(children) == 0 {
+				continue
+			}
+			item.Action = nil
+			item.ChildMenu = fyne.NewMenu("", children...)
+		}
+
+		if pendingSeparator {
+			items = append(items, fyne.NewMenuItemSeparator())
+			pendingSeparator = false
+		}
+		items = append(items, item)
+	}
+	return items
+}
@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"claudebar/internal/version"
+)
+
+// ShowWhatsNew opens a window with release notes, Markdown as in the
+// embedded changelog
+func ShowWhatsNew(app fyne.App, notes string) {
+	window := app.NewWindow("What's New in ClaudeBar " + version.Version)
+	window.Resize(fyne.NewSize(480, 420))
+
+	text := widget.NewRichTextFromMarkdown(notes)
+	text.Wrapping = fyne.TextWrapWord
+
+	closeBtn := widget.NewButton("Close", func() {
+		window.Close()
+	})
+
+	content := container.NewBorder(nil,
+		container.NewHBox(layout.NewSpacer(), closeBtn, layout.NewSpacer()), nil, nil,
+		container.NewVScroll(text))
+	window.SetContent(withBackground(app, container.NewPadded(content)))
+	window.Show()
+}
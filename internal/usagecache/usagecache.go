@@ -0,0 +1,81 @@
+// Package usagecache keeps the latest usage the app fetched in a small file
+// in the config directory, so shell prompts and status lines can show it
+// without signing in or fetching
+package usagecache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"claudebar/internal/api"
+	"claudebar/internal/config"
+)
+
+// FileName is the cache file in the config directory
+const FileName = "usage-cache.json"
+
+var (
+	ErrNoCache = errors.New("no cached usage, is ClaudeBar running?")
+	ErrStale   = errors.New("cached usage is out of date, is ClaudeBar running?")
+)
+
+// Entry is the content of the cache file
+type Entry struct {
+	Fetched time.Time      `json:"fetched"`
+	Usage   *api.UsageData `json:"usage"`
+}
+
+// path returns the cache file
+func path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, FileName), nil
+}
+
+// Write replaces the cache with usage fetched at fetched. The file is
+// replaced in one step so readers never see half of it.
+func Write(usage *api.UsageData, fetched time.Time) error {
+	p, err := path()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(Entry{Fetched: fetched, Usage: usage})
+	if err != nil {
+		return err
+	}
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+// Read returns the cached usage, or ErrNoCache when the app hasn't fetched
+// any yet
+func Read() (*Entry, error) {
+	p, err := path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoCache
+	}
+	if err != nil {
+		return nil, err
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("%s: %w", FileName, err)
+	}
+	if e.Usage == nil {
+		return nil, ErrNoCache
+	}
+	return &e, nil
+}
@@ -0,0 +1,31 @@
+package usagecache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"claudebar/internal/api"
+	"claudebar/internal/config"
+)
+
+func TestWriteRead(t *testing.T) {
+	config.SetDir(t.TempDir())
+	t.Cleanup(func() { config.SetDir("") })
+
+	if _, err := Read(); !errors.Is(err, ErrNoCache) {
+		t.Fatalf("Read before Write = %v, want ErrNoCache", err)
+	}
+
+	fetched := time.Date(2026, 3, 2, 14, 32, 0, 0, time.UTC)
+	if err := Write(&api.UsageData{FiveHour: api.UsageStat{Utilization: 42}}, fetched); err != nil {
+		t.Fatal(err)
+	}
+	e, err := Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Fetched.Equal(fetched) || e.Usage.FiveHour.Utilization != 42 {
+		t.Errorf("Read = %+v, want session at 42%% fetched %v", e, fetched)
+	}
+}
@@ -0,0 +1,106 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// Sealed blob layout:
+//
+//	magic (4) | salt (16) | nonce (12) | AES-256-GCM ciphertext
+//
+// The key is derived from the passphrase with PBKDF2-HMAC-SHA256.
+const (
+	saltSize   = 16
+	nonceSize  = 12
+	keySize    = 32
+	iterations = 600_000
+)
+
+var magic = []byte("CBV1")
+
+var (
+	ErrEmptyPassphrase = errors.New("passphrase must not be empty")
+	ErrInvalidFormat   = errors.New("not a ClaudeBar encrypted file")
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupted file")
+)
+
+// Seal encrypts plaintext with a key derived from passphrase
+func Seal(passphrase string, plaintext []byte) ([]byte, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	header := append(append([]byte{}, magic...), salt...)
+
+	out := make([]byte, 0, len(header)+nonceSize+len(plaintext)+gcm.Overhead())
+	out = append(out, header...)
+	out = append(out, nonce...)
+	// Authenticate the header too so the salt can't be swapped between files
+	return gcm.Seal(out, nonce, plaintext, header), nil
+}
+
+// Open decrypts data produced by Seal
+func Open(passphrase string, sealed []byte) ([]byte, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+	if len(sealed) < len(magic)+saltSize+nonceSize || !bytes.Equal(sealed[:len(magic)], magic) {
+		return nil, ErrInvalidFormat
+	}
+
+	header := sealed[:len(magic)+saltSize]
+	salt := sealed[len(magic) : len(magic)+saltSize]
+	nonce := sealed[len(magic)+saltSize : len(magic)+saltSize+nonceSize]
+	ciphertext := sealed[len(magic)+saltSize+nonceSize:]
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+// newGCM derives the AES key for passphrase+salt and wraps it in GCM
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("key derivation failed: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
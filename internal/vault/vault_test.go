@@ -0,0 +1,74 @@
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	for _, plaintext := range [][]byte{nil, []byte("x"), bytes.Repeat([]byte("config"), 1000)} {
+		sealed, err := Seal("passphrase", plaintext)
+		if err != nil {
+			t.Fatalf("Seal: %v", err)
+		}
+		if len(plaintext) > 0 && bytes.Contains(sealed, plaintext) {
+			t.Error("sealed data contains the plaintext")
+		}
+		got, err := Open("passphrase", sealed)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("Open returned %d bytes, want %d", len(got), len(plaintext))
+		}
+	}
+}
+
+func TestOpenRejects(t *testing.T) {
+	sealed, err := Seal("passphrase", []byte("settings"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := Seal("passphrase", []byte("settings"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	flip := func(i int) []byte {
+		b := bytes.Clone(sealed)
+		b[i] ^= 1
+		return b
+	}
+	swapSalt := bytes.Clone(sealed)
+	copy(swapSalt[len(magic):len(magic)+saltSize], other[len(magic):len(magic)+saltSize])
+
+	tests := []struct {
+		name       string
+		passphrase string
+		data       []byte
+		want       error
+	}{
+		{"empty passphrase", "", sealed, ErrEmptyPassphrase},
+		{"wrong passphrase", "Passphrase", sealed, ErrWrongPassphrase},
+		{"empty data", "passphrase", nil, ErrInvalidFormat},
+		{"truncated header", "passphrase", sealed[:len(magic)+saltSize], ErrInvalidFormat},
+		{"bad magic", "passphrase", flip(0), ErrInvalidFormat},
+		{"tampered salt", "passphrase", flip(len(magic)), ErrWrongPassphrase},
+		{"swapped salt", "passphrase", swapSalt, ErrWrongPassphrase},
+		{"tampered nonce", "passphrase", flip(len(magic) + saltSize), ErrWrongPassphrase},
+		{"tampered ciphertext", "passphrase", flip(len(magic) + saltSize + nonceSize), ErrWrongPassphrase},
+		{"tampered tag", "passphrase", flip(len(sealed) - 1), ErrWrongPassphrase},
+		{"truncated ciphertext", "passphrase", sealed[:len(sealed)-1], ErrWrongPassphrase},
+	}
+	for _, tt := range tests {
+		if _, err := Open(tt.passphrase, tt.data); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Open error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestSealEmptyPassphrase(t *testing.T) {
+	if _, err := Seal("", []byte("data")); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Errorf("Seal error = %v, want %v", err, ErrEmptyPassphrase)
+	}
+}
@@ -0,0 +1,30 @@
+package version
+
+import (
+	_ "embed"
+	"strings"
+)
+
+// Changelog is the release notes in Markdown, newest release first, each
+// under a "## <version>" heading
+//
+//go:embed CHANGELOG.md
+var Changelog string
+
+// Homepage is the project page, where issues are reported
+const Homepage = "https://github.com/bbmumford/ClaudeBar"
+
+// Notes returns the changelog sections newer than release since, as
+// Markdown, or "" when there are none. An unknown since returns every
+// section.
+func Notes(since string) string {
+	var sections []string
+	for _, section := range strings.Split(Changelog, "\n## ")[1:] {
+		heading, _, _ := strings.Cut(section, "\n")
+		if strings.TrimSpace(heading) == since {
+			break
+		}
+		sections = append(sections, "## "+strings.TrimSpace(section))
+	}
+	return strings.Join(sections, "\n\n")
+}
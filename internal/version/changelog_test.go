@@ -0,0 +1,22 @@
+package version
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNotes(t *testing.T) {
+	saved := Changelog
+	t.Cleanup(func() { Changelog = saved })
+	Changelog = "# Changelog\n\n## 1.2.0\n\n- Two\n\n## 1.1.0\n\n- One\n\n## 1.0.0\n\n- First\n"
+
+	if got, want := Notes("1.1.0"), "## 1.2.0\n\n- Two"; got != want {
+		t.Errorf("Notes(1.1.0) = %q, want %q", got, want)
+	}
+	if got := Notes("1.2.0"); got != "" {
+		t.Errorf("Notes(1.2.0) = %q, want nothing", got)
+	}
+	if got := Notes("0.9.0"); strings.Count(got, "## ") != 3 {
+		t.Errorf("Notes(0.9.0) = %q, want every section", got)
+	}
+}
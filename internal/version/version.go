@@ -0,0 +1,6 @@
+package version
+
+// Version is the application version, overridden at build time:
+//
+//	go build -ldflags "-X claudebar/internal/version.Version=1.2.0"
+var Version = "1.0.0"
@@ -1,12 +1,93 @@
 package main
 
 import (
-	"claudebar/internal/app"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"os"
+
+	"claudebar/internal/app"
+	"claudebar/internal/autostart"
+	"claudebar/internal/cli"
+	"claudebar/internal/config"
+	"claudebar/internal/redact"
+	"claudebar/internal/report"
 )
 
 func main() {
-	if err := app.Run(); err != nil {
+	// Everything logged goes through the redaction layer, and the recent
+	// lines are kept for problem reports
+	log.SetOutput(redact.Writer(io.MultiWriter(os.Stderr, report.Log)))
+
+	configDir := flag.String("config-dir", "", "keep config, history and themes in this directory instead of the default (also $"+config.EnvDir+")")
+	uninstall := flag.Bool("uninstall", false, "remove the auto-start registration and exit (used by the installer)")
+	purge := flag.Bool("purge", false, "with --uninstall, also delete the config directory")
+	demo := flag.Bool("demo", false, "show generated usage data instead of connecting to Claude")
+	// Internal: renders each overlay layout with demo data into a directory
+	screenshots := flag.String("screenshots", "", "")
+	flag.Usage = usage
+	flag.Parse()
+
+	if *configDir != "" {
+		config.SetDir(*configDir)
+	}
+
+	// Headless subcommands such as "claudebar check" run without the GUI
+	if code, ok := cli.Run(flag.Args()); ok {
+		os.Exit(code)
+	}
+
+	if *screenshots != "" {
+		if err := app.RunScreenshots(*screenshots); err != nil {
+			log.Fatalf("Screenshot rendering failed: %v", err)
+		}
+		return
+	}
+
+	if *uninstall {
+		runUninstall(*purge)
+		return
+	}
+
+	if err := app.Run(app.Options{Demo: *demo}); err != nil {
 		log.Fatalf("Application error: %v", err)
 	}
 }
+
+// usage prints the documented flags; flags without a usage string are internal
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+	fmt.Fprintf(out, "  %s check [-metric session] [-warn 75] [-crit 90]\n    \tprint a monitoring plugin status line and exit 0/1/2\n", os.Args[0])
+	fmt.Fprintf(out, "  %s status [-format text|json]\n    \tprint the usage of each limit with its reset time and exit, 1 when not signed in\n", os.Args[0])
+	fmt.Fprintf(out, "  %s prompt [-format '⚡{session}|{weekly}'] [-max-age 1h]\n    \tprint a short usage segment for shell prompts from the app's cache, without fetching\n", os.Args[0])
+	fmt.Fprintf(out, "  %s bar [-format waybar|polybar|i3blocks] [-interval 30s] [-fetch]\n    \tprint usage for a status bar on every interval and on SIGUSR1\n", os.Args[0])
+	fmt.Fprintf(out, "  %s watch [-format jsonl] [-interval 60]\n    \tpoll without the GUI, writing one JSON line per fetch\n", os.Args[0])
+	fmt.Fprintf(out, "  %s doctor\n    \tcheck each way of finding the claude.ai session and report why sign-in fails\n", os.Args[0])
+	fmt.Fprintf(out, "  %s export [-format influx|sqlite] [-o file]\n    \twrite the weekly history as InfluxDB line protocol or a SQLite database for Grafana\n", os.Args[0])
+	flag.VisitAll(func(f *flag.Flag) {
+		if f.Usage != "" {
+			fmt.Fprintf(out, "  -%s\n    \t%s\n", f.Name, f.Usage)
+		}
+	})
+}
+
+// runUninstall cleans up per-user state the installer can't see
+func runUninstall(purge bool) {
+	if err := autostart.Disable(); err != nil && err != autostart.ErrManagedByPackage {
+		log.Printf("Warning: failed to remove auto-start entry: %v", err)
+	}
+
+	if !purge {
+		return
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		log.Fatalf("Failed to locate config directory: %v", err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		log.Fatalf("Failed to remove config directory: %v", err)
+	}
+	log.Printf("Removed %s", dir)
+}
@@ -0,0 +1,244 @@
+// Package claudeusage fetches Claude plan usage (session and weekly limits)
+// the way ClaudeBar does, for other Go programs:
+//
+//	client := claudeusage.New(claudeusage.WithSessionKey(key))
+//	usage, err := client.Usage(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Printf("session %.0f%%, resets %s\n", usage.Session.Utilization, usage.Session.ResetsAt)
+//
+// Without a session key the client looks for a claude.ai sessionKey cookie
+// in the installed browsers, like ClaudeBar's sign-in. Nothing is saved, and
+// ClaudeBar's own config is neither read nor created; behind a proxy, pass
+// WithHTTPHeaders, WithRootCAs or WithCertificatePins.
+package claudeusage
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+
+	"claudebar/internal/api"
+	"claudebar/internal/browser"
+)
+
+// Errors returned by the client; test for them with errors.Is
+var (
+	ErrNoSessionKey   = api.ErrNoSessionKey   // no key given and none found in browsers
+	ErrUnauthorized   = api.ErrUnauthorized   // the session key was rejected
+	ErrSessionExpired = api.ErrSessionExpired // the session key has expired
+	ErrRateLimited    = api.ErrRateLimited    // back off before fetching again
+	ErrUnavailable    = api.ErrAPIUnavailable // claude.ai is having problems
+	ErrNoOrganization = errors.New("no organization found")
+)
+
+// Limit is the usage of one limit
+type Limit struct {
+	Utilization float64   // percent used, 0-100
+	ResetsAt    time.Time // zero when the account has no such limit
+}
+
+// Usage is the usage of an account at one point in time
+type Usage struct {
+	Session   Limit // five-hour session limit
+	Weekly    Limit // seven-day limit
+	Opus      Limit // seven-day Opus limit, on plans that have one
+	Sonnet    Limit // seven-day Sonnet limit, on plans that have one
+	FetchedAt time.Time
+}
+
+// Organization is an organization the session key belongs to
+type Organization struct {
+	ID   string
+	Name string
+}
+
+// Option configures a Client
+type Option func(*options)
+
+type options struct {
+	sessionKey   string
+	organization string
+	baseURL      string
+	browsers     bool
+	api          api.Options
+}
+
+// WithSessionKey uses the given claude.ai sessionKey cookie instead of
+// searching the browsers
+func WithSessionKey(key string) Option {
+	return func(o *options) { o.sessionKey = key }
+}
+
+// WithOrganization fetches usage for the organization with this UUID
+// instead of the session's first one
+func WithOrganization(id string) Option {
+	return func(o *options) { o.organization = id }
+}
+
+// WithBaseURL talks to another server than https://claude.ai, e.g. a test
+// server
+func WithBaseURL(url string) Option {
+	return func(o *options) { o.baseURL = url }
+}
+
+// WithHTTPHeaders adds or replaces request headers, e.g. for a proxy that
+// wants its own; an empty value removes the header. The session cookie
+// can't be replaced.
+func WithHTTPHeaders(headers map[string]string) Option {
+	return func(o *options) { o.api.Headers = headers }
+}
+
+// WithRootCAs trusts pool instead of the system's certificate roots, e.g.
+// the system roots plus the CA of a proxy that inspects HTTPS
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(o *options) { o.api.RootCAs = pool }
+}
+
+// WithCertificatePins refuses servers whose certificate chain contains
+// none of pins, base64 SHA-256 hashes of public keys
+func WithCertificatePins(pins ...string) Option {
+	return func(o *options) { o.api.Pins = pins }
+}
+
+// WithoutBrowsers turns off the browser cookie search, so a missing session
+// key fails with ErrNoSessionKey
+func WithoutBrowsers() Option {
+	return func(o *options) { o.browsers = false }
+}
+
+// Client fetches usage for one account. It signs in on first use and is
+// safe for concurrent use.
+type Client struct {
+	opts options
+	api  *api.Client
+
+	mu     sync.Mutex // serializes signing in
+	signed bool
+}
+
+// New creates a client
+func New(opts ...Option) *Client {
+	o := options{browsers: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if u, err := url.Parse(o.baseURL); err == nil && o.baseURL != "" {
+		o.api.Host = u.Hostname() // where the pins apply
+	}
+	c := &Client{opts: o, api: api.NewClient(o.api)}
+	if o.baseURL != "" {
+		c.api.SetBaseURL(o.baseURL)
+	}
+	c.api.SetSessionKey(o.sessionKey)
+	c.api.SetOrganizationID(o.organization)
+	return c
+}
+
+// Usage fetches the current usage
+func (c *Client) Usage(ctx context.Context) (*Usage, error) {
+	if err := c.signIn(ctx); err != nil {
+		return nil, err
+	}
+	u, err := c.api.FetchUsageContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return fromUsageData(u), nil
+}
+
+// Organizations lists the organizations of the session key
+func (c *Client) Organizations(ctx context.Context) ([]Organization, error) {
+	if err := c.signIn(ctx); err != nil {
+		return nil, err
+	}
+	infos, err := c.api.FetchOrganizationsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orgs := make([]Organization, len(infos))
+	for i, info := range infos {
+		orgs[i] = Organization{ID: info.ID, Name: info.Name}
+	}
+	return orgs, nil
+}
+
+// signIn finds a working session key and the organization, once
+func (c *Client) signIn(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.signed {
+		return nil
+	}
+
+	if c.api.GetSessionKey() == "" {
+		if err := c.browserSessionKey(ctx); err != nil {
+			return err
+		}
+	}
+	if c.api.GetOrganizationID() == "" {
+		orgs, err := c.api.FetchOrganizationsContext(ctx)
+		if err != nil {
+			return err
+		}
+		if len(orgs) == 0 {
+			return ErrNoOrganization
+		}
+		c.api.SetOrganizationID(orgs[0].ID)
+	}
+	c.signed = true
+	return nil
+}
+
+// browserSessionKey makes the first browser session key that authenticates
+// the client's key
+func (c *Client) browserSessionKey(ctx context.Context) error {
+	if !c.opts.browsers {
+		return ErrNoSessionKey
+	}
+	extractor := browser.NewCookieExtractor()
+	extractor.AddDomain(c.api.Host())
+	keys, err := extractor.SessionKeys()
+	if err != nil {
+		return errors.Join(ErrNoSessionKey, err)
+	}
+
+	var lastErr error
+	for _, key := range keys {
+		c.api.SetSessionKey(key)
+		orgs, err := c.api.FetchOrganizationsContext(ctx)
+		if err == nil && len(orgs) > 0 {
+			if c.api.GetOrganizationID() == "" {
+				c.api.SetOrganizationID(orgs[0].ID)
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		lastErr = err
+	}
+	c.api.SetSessionKey("")
+	if lastErr == nil {
+		lastErr = ErrNoOrganization
+	}
+	return lastErr
+}
+
+// fromUsageData converts the internal usage model
+func fromUsageData(u *api.UsageData) *Usage {
+	limit := func(s api.UsageStat) Limit {
+		return Limit{Utilization: s.Utilization, ResetsAt: s.ResetsAt}
+	}
+	return &Usage{
+		Session:   limit(u.FiveHour),
+		Weekly:    limit(u.SevenDay),
+		Opus:      limit(u.SevenDayOpus),
+		Sonnet:    limit(u.SevenDaySonnet),
+		FetchedAt: u.LastUpdated,
+	}
+}
@@ -0,0 +1,82 @@
+package claudeusage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"claudebar/internal/api/testserver"
+	"claudebar/internal/config"
+	"claudebar/internal/config/configtest"
+)
+
+func newTestClient(t *testing.T, opts ...Option) (*Client, *testserver.Server) {
+	t.Helper()
+	srv := testserver.New()
+	t.Cleanup(srv.Close)
+	opts = append([]Option{WithBaseURL(srv.URL), WithoutBrowsers()}, opts...)
+	return New(opts...), srv
+}
+
+func TestUsage(t *testing.T) {
+	configtest.Use(t)
+	c, srv := newTestClient(t, WithSessionKey(testserver.DefaultSessionKey))
+	reset := time.Now().UTC().Truncate(time.Second).Add(2 * time.Hour)
+	srv.SetUsage(testserver.Usage{Utilization: 63, ResetsAt: reset}, testserver.Usage{Utilization: 20})
+
+	usage, err := c.Usage(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Session.Utilization != 63 || !usage.Session.ResetsAt.Equal(reset) {
+		t.Errorf("Session = %+v, want 63%% resetting at %v", usage.Session, reset)
+	}
+	if usage.Weekly.Utilization != 20 {
+		t.Errorf("Weekly = %+v, want 20%%", usage.Weekly)
+	}
+
+	// The organization is looked up once
+	if _, err := c.Usage(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.Requests("organizations"); n != 1 {
+		t.Errorf("organizations requested %d times, want 1", n)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	configtest.Use(t)
+	c, _ := newTestClient(t)
+	if _, err := c.Usage(context.Background()); !errors.Is(err, ErrNoSessionKey) {
+		t.Errorf("without a session key: err = %v, want ErrNoSessionKey", err)
+	}
+
+	c, _ = newTestClient(t, WithSessionKey("sk-ant-sid01-old"))
+	if _, err := c.Usage(context.Background()); !errors.Is(err, ErrSessionExpired) {
+		t.Errorf("with a stale key: err = %v, want ErrSessionExpired", err)
+	}
+
+	c, _ = newTestClient(t, WithSessionKey(testserver.DefaultSessionKey), WithOrganization(testserver.DefaultOrgID))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Usage(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("with a canceled context: err = %v, want context.Canceled", err)
+	}
+}
+
+func TestLeavesConfigAlone(t *testing.T) {
+	configtest.Use(t)
+	dir := filepath.Join(t.TempDir(), "claudebar")
+	config.SetDir(dir)
+
+	c, _ := newTestClient(t, WithSessionKey(testserver.DefaultSessionKey), WithHTTPHeaders(map[string]string{"X-Proxy": "acme"}))
+	if _, err := c.Usage(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the client created ClaudeBar's config directory: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package widgets
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ProgressBar is a custom progress bar matching Claude's design. The style
+// picks how it is drawn (see BarStyle).
+type ProgressBar struct {
+	widget.BaseWidget
+	percentage float64
+	style      BarStyle
+	metric     string // picks the metric's color from Style.MetricFill, if any
+}
+
+// NewProgressBar creates a progress bar for the named metric ("" for none)
+func NewProgressBar(style BarStyle, metric string) *ProgressBar {
+	p := &ProgressBar{style: style, metric: metric}
+	p.ExtendBaseWidget(p)
+	return p
+}
+
+// SetValue sets the bar percentage (0-100)
+func (p *ProgressBar) SetValue(pct float64) {
+	p.percentage = pct
+	p.Refresh()
+}
+
+func (p *ProgressBar) CreateRenderer() fyne.WidgetRenderer {
+	switch p.style {
+	case BarSegmented:
+		return newSegmentedBarRenderer(p)
+	case BarLine:
+		return newLineBarRenderer(p)
+	case BarRadial:
+		return newRadialBarRenderer(p)
+	}
+
+	track := canvas.NewRectangle(current.BarTrack)
+	track.CornerRadius = current.BarRadius
+
+	fill := canvas.NewRectangle(barColor(p.metric, p.percentage))
+	fill.CornerRadius = current.BarRadius
+
+	return &progressBarRenderer{bar: p, track: track, fill: fill}
+}
+
+type progressBarRenderer struct {
+	bar   *ProgressBar
+	track *canvas.Rectangle
+	fill  *canvas.Rectangle
+}
+
+func (r *progressBarRenderer) Layout(size fyne.Size) {
+	r.track.Resize(size)
+	r.track.Move(fyne.NewPos(0, 0))
+
+	r.fill.Resize(fyne.NewSize(fillWidth(size.Width, r.bar.percentage), size.Height))
+	r.fill.Move(fyne.NewPos(0, 0))
+}
+
+func (r *progressBarRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(80, 10)
+}
+
+func (r *progressBarRenderer) Refresh() {
+	r.fill.FillColor = barColor(r.bar.metric, r.bar.percentage)
+	// Recalculate fill width on refresh (fixes bars not filling after data update)
+	size := r.track.Size()
+	if size.Width > 0 {
+		r.fill.Resize(fyne.NewSize(fillWidth(size.Width, r.bar.percentage), size.Height))
+	}
+	r.fill.Refresh()
+	r.track.Refresh()
+}
+
+func (r *progressBarRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{r.track, r.fill}
+}
+
+func (r *progressBarRenderer) Destroy() {}
+
+// fillWidth returns the filled part of a bar of the given width, clamped to it
+func fillWidth(width float32, pct float64) float32 {
+	fillW := width * float32(pct/100)
+	if fillW < 0 {
+		fillW = 0
+	}
+	if fillW > width {
+		fillW = width
+	}
+	return fillW
+}
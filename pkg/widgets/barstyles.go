@@ -0,0 +1,207 @@
+package widgets
+
+import (
+	"image/color"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+)
+
+// BarStyle selects how a ProgressBar is drawn
+type BarStyle string
+
+const (
+	BarSolid     BarStyle = "solid"     // rounded bar (default)
+	BarSegmented BarStyle = "segmented" // row of blocks, one per 10%
+	BarLine      BarStyle = "line"      // thin 2px line
+	BarRadial    BarStyle = "radial"    // small ring gauge, compact layout only
+)
+
+// Bar style geometry
+const (
+	barSegments     = 10
+	barSegmentGap   = 2
+	barLineHeight   = 2
+	radialGaugeSize = 14
+	radialThickness = 3
+)
+
+// BarStyles returns the styles offered for a layout, the radial gauge only
+// being available in the compact one
+func BarStyles(compact bool) []BarStyle {
+	styles := []BarStyle{BarSolid, BarSegmented, BarLine}
+	if compact {
+		styles = append(styles, BarRadial)
+	}
+	return styles
+}
+
+// ParseBarStyle returns the style named in the config, falling back to the
+// solid bar for unknown names or styles the layout doesn't offer
+func ParseBarStyle(name string, compact bool) BarStyle {
+	for _, s := range BarStyles(compact) {
+		if string(s) == name {
+			return s
+		}
+	}
+	return BarSolid
+}
+
+// segmentedBarRenderer draws the bar as a row of blocks
+type segmentedBarRenderer struct {
+	bar    *ProgressBar
+	blocks []*canvas.Rectangle
+}
+
+func newSegmentedBarRenderer(bar *ProgressBar) *segmentedBarRenderer {
+	r := &segmentedBarRenderer{bar: bar}
+	for i := 0; i < barSegments; i++ {
+		block := canvas.NewRectangle(current.BarTrack)
+		block.CornerRadius = fyne.Min(current.BarRadius, 2)
+		r.blocks = append(r.blocks, block)
+	}
+	r.Refresh()
+	return r
+}
+
+func (r *segmentedBarRenderer) Layout(size fyne.Size) {
+	w := (size.Width - barSegmentGap*(barSegments-1)) / barSegments
+	for i, block := range r.blocks {
+		block.Resize(fyne.NewSize(w, size.Height))
+		block.Move(fyne.NewPos(float32(i)*(w+barSegmentGap), 0))
+	}
+}
+
+func (r *segmentedBarRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(barSegments*(4+barSegmentGap), 6)
+}
+
+func (r *segmentedBarRenderer) Refresh() {
+	// A block lights up once usage reaches its midpoint
+	lit := int(math.Round(r.bar.percentage / (100 / barSegments)))
+	fill := barColor(r.bar.metric, r.bar.percentage)
+	for i, block := range r.blocks {
+		if i < lit {
+			block.FillColor = fill
+		} else {
+			block.FillColor = current.BarTrack
+		}
+		block.Refresh()
+	}
+}
+
+func (r *segmentedBarRenderer) Objects() []fyne.CanvasObject {
+	objects := make([]fyne.CanvasObject, len(r.blocks))
+	for i, block := range r.blocks {
+		objects[i] = block
+	}
+	return objects
+}
+
+func (r *segmentedBarRenderer) Destroy() {}
+
+// lineBarRenderer draws a thin line centered in the space given to the bar
+type lineBarRenderer struct {
+	bar   *ProgressBar
+	track *canvas.Rectangle
+	fill  *canvas.Rectangle
+}
+
+func newLineBarRenderer(bar *ProgressBar) *lineBarRenderer {
+	return &lineBarRenderer{
+		bar:   bar,
+		track: canvas.NewRectangle(current.BarTrack),
+		fill:  canvas.NewRectangle(barColor(bar.metric, bar.percentage)),
+	}
+}
+
+func (r *lineBarRenderer) Layout(size fyne.Size) {
+	y := (size.Height - barLineHeight) / 2
+	r.track.Resize(fyne.NewSize(size.Width, barLineHeight))
+	r.track.Move(fyne.NewPos(0, y))
+	r.fill.Resize(fyne.NewSize(fillWidth(size.Width, r.bar.percentage), barLineHeight))
+	r.fill.Move(fyne.NewPos(0, y))
+}
+
+func (r *lineBarRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(40, barLineHeight)
+}
+
+func (r *lineBarRenderer) Refresh() {
+	r.fill.FillColor = barColor(r.bar.metric, r.bar.percentage)
+	if size := r.track.Size(); size.Width > 0 {
+		r.fill.Resize(fyne.NewSize(fillWidth(size.Width, r.bar.percentage), barLineHeight))
+	}
+	r.fill.Refresh()
+	r.track.Refresh()
+}
+
+func (r *lineBarRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{r.track, r.fill}
+}
+
+func (r *lineBarRenderer) Destroy() {}
+
+// radialBarRenderer draws a ring that fills clockwise from 12 o'clock
+type radialBarRenderer struct {
+	bar    *ProgressBar
+	raster *canvas.Raster
+}
+
+func newRadialBarRenderer(bar *ProgressBar) *radialBarRenderer {
+	r := &radialBarRenderer{bar: bar}
+	r.raster = canvas.NewRasterWithPixels(r.pixel)
+	return r
+}
+
+// pixel colors one pixel of the gauge; edges are softened by coverage so the
+// ring doesn't look jagged at this size
+func (r *radialBarRenderer) pixel(x, y, w, h int) color.Color {
+	size := math.Min(float64(w), float64(h))
+	outer := size / 2
+	inner := outer - radialThickness*size/radialGaugeSize
+
+	dx := float64(x) + 0.5 - float64(w)/2
+	dy := float64(y) + 0.5 - float64(h)/2
+	dist := math.Hypot(dx, dy)
+
+	coverage := math.Min(outer-dist+0.5, dist-inner+0.5)
+	if coverage <= 0 {
+		return color.Transparent
+	}
+	coverage = math.Min(coverage, 1)
+
+	// Angle measured clockwise from the top, 0..1
+	angle := math.Atan2(dx, -dy) / (2 * math.Pi)
+	if angle < 0 {
+		angle++
+	}
+
+	var base color.Color = current.BarTrack
+	if angle*100 < r.bar.percentage {
+		base = barColor(r.bar.metric, r.bar.percentage)
+	}
+	c := color.NRGBAModel.Convert(base).(color.NRGBA)
+	c.A = uint8(float64(c.A) * coverage)
+	return c
+}
+
+func (r *radialBarRenderer) Layout(size fyne.Size) {
+	r.raster.Resize(size)
+	r.raster.Move(fyne.NewPos(0, 0))
+}
+
+func (r *radialBarRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(radialGaugeSize, radialGaugeSize)
+}
+
+func (r *radialBarRenderer) Refresh() {
+	r.raster.Refresh()
+}
+
+func (r *radialBarRenderer) Objects() []fyne.CanvasObject {
+	return []fyne.CanvasObject{r.raster}
+}
+
+func (r *radialBarRenderer) Destroy() {}
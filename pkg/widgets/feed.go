@@ -0,0 +1,51 @@
+package widgets
+
+import (
+	"fmt"
+	"time"
+)
+
+// Metric names used by ClaudeBar's usage sources
+const (
+	MetricSession = "session" // five-hour session limit
+	MetricWeekly  = "weekly"  // seven-day limit
+	MetricOpus    = "opus"    // seven-day Opus limit
+	MetricSonnet  = "sonnet"  // seven-day Sonnet limit
+)
+
+// Reading is one usage figure
+type Reading struct {
+	Utilization float64   // percent of the limit used, 0-100
+	ResetsAt    time.Time // when the limit resets, zero if unknown
+}
+
+// Feed supplies the latest usage by metric name. ok is false for metrics the
+// source doesn't know about.
+type Feed interface {
+	Reading(metric string) (r Reading, ok bool)
+}
+
+// FeedFunc adapts a function to the Feed interface
+type FeedFunc func(metric string) (Reading, bool)
+
+// Reading calls f
+func (f FeedFunc) Reading(metric string) (Reading, bool) {
+	return f(metric)
+}
+
+// untilReset formats the time left until resetAt, e.g. "2h 15m" or "3d 4h"
+func untilReset(resetAt time.Time) string {
+	d := time.Until(resetAt)
+	if d < 0 {
+		return "Now"
+	}
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	switch {
+	case hours > 24:
+		return fmt.Sprintf("%dd %dh", hours/24, hours%24)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
@@ -0,0 +1,219 @@
+package widgets
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+)
+
+// UsageRow displays a single usage metric matching Claude's website layout:
+//
+//	**Label**              [====bar====]   XX% used
+//	Resets in X hr Y min
+type UsageRow struct {
+	container *fyne.Container
+
+	headerText *canvas.Text
+	resetText  *canvas.Text
+	pctText    *canvas.Text
+	bar        *ProgressBar
+	metric     string
+	decimals   int
+}
+
+// NewUsageRow creates a usage row for the named metric
+func NewUsageRow(label, metric string, style BarStyle) *UsageRow {
+	u := &UsageRow{metric: metric}
+
+	// Bold header
+	u.headerText = canvas.NewText(label, current.Text)
+	u.headerText.TextSize = current.HeaderSize
+	u.headerText.TextStyle = fyne.TextStyle{Bold: true}
+
+	// Reset subtitle
+	u.resetText = canvas.NewText("", current.Subtext)
+	u.resetText.TextSize = current.CaptionSize
+
+	// Percentage label
+	u.pctText = canvas.NewText("0% used", current.Percentage)
+	u.pctText.TextSize = current.BodySize
+
+	// Progress bar
+	u.bar = NewProgressBar(style, metric)
+
+	// Layout: header row with bar and percentage, then reset text below
+	// Top row: [header] [spacer] [bar] [pct]
+	barContainer := container.New(&fixedHeightLayout{height: current.BarHeight}, u.bar)
+	topRow := container.NewHBox(
+		u.headerText,
+		layout.NewSpacer(),
+		container.New(&fixedWidthLayout{width: current.BarWidth}, barContainer),
+		u.pctText,
+	)
+
+	u.container = container.NewVBox(
+		topRow,
+		u.resetText,
+	)
+
+	return u
+}
+
+// SetPrecision sets how many decimals (0-2) the percentage is shown with
+// from the next Update
+func (u *UsageRow) SetPrecision(decimals int) {
+	u.decimals = min(max(decimals, 0), 2)
+}
+
+// Update refreshes the row with new data
+func (u *UsageRow) Update(label string, pct float64, resetAt time.Time) {
+	u.headerText.Text = label
+	u.headerText.Refresh()
+
+	u.pctText.Text = fmt.Sprintf("%.*f%% used", u.decimals, pct)
+	u.pctText.Refresh()
+
+	u.bar.SetValue(pct)
+
+	if !resetAt.IsZero() {
+		u.resetText.Text = "Resets in " + untilReset(resetAt)
+		u.resetText.Refresh()
+	}
+}
+
+// Load updates the row from its metric's reading in f, keeping the current
+// label. It returns false, leaving the row as it was, when f has no reading.
+func (u *UsageRow) Load(f Feed) bool {
+	r, ok := f.Reading(u.metric)
+	if ok {
+		u.Update(u.headerText.Text, r.Utilization, r.ResetsAt)
+	}
+	return ok
+}
+
+// UpdateResetAbsolute sets the reset text to an absolute time
+func (u *UsageRow) UpdateResetAbsolute(resetAt time.Time) {
+	if resetAt.IsZero() {
+		u.resetText.Text = ""
+	} else {
+		u.resetText.Text = "Resets " + resetAt.Format("Mon 3:04 PM")
+	}
+	u.resetText.Refresh()
+}
+
+// GetContainer returns the renderable container
+func (u *UsageRow) GetContainer() *fyne.Container {
+	return u.container
+}
+
+// fixedWidthLayout forces children to a fixed width
+type fixedWidthLayout struct {
+	width float32
+}
+
+func (l *fixedWidthLayout) MinSize(_ []fyne.CanvasObject) fyne.Size {
+	return fyne.NewSize(l.width, 10)
+}
+
+func (l *fixedWidthLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	for _, o := range objects {
+		o.Resize(fyne.NewSize(l.width, size.Height))
+		o.Move(fyne.NewPos(0, 0))
+	}
+}
+
+// fixedHeightLayout forces children to a fixed height (centered vertically)
+type fixedHeightLayout struct {
+	height float32
+}
+
+func (l *fixedHeightLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
+	w := float32(0)
+	for _, o := range objects {
+		w = fyne.Max(w, o.MinSize().Width)
+	}
+	return fyne.NewSize(w, l.height)
+}
+
+func (l *fixedHeightLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	for _, o := range objects {
+		o.Resize(fyne.NewSize(size.Width, l.height))
+		yOff := (size.Height - l.height) / 2
+		o.Move(fyne.NewPos(0, yOff))
+	}
+}
+
+// CompactUsageRow is a smaller version for horizontal/top snap
+type CompactUsageRow struct {
+	container *fyne.Container
+	label     *canvas.Text
+	pct       *canvas.Text
+	bar       *ProgressBar
+	metric    string
+}
+
+// NewCompactUsageRow creates a compact usage row for horizontal layout
+func NewCompactUsageRow(labelStr, metric string, style BarStyle) *CompactUsageRow {
+	c := &CompactUsageRow{metric: metric}
+
+	c.label = canvas.NewText(labelStr, current.Text)
+	c.label.TextSize = current.CompactSize
+	c.label.TextStyle = fyne.TextStyle{Bold: true}
+	c.label.SetMinSize(fyne.NewSize(50, 14))
+
+	c.pct = canvas.NewText("0%", current.Percentage)
+	c.pct.TextSize = current.CompactSize
+	c.pct.Alignment = fyne.TextAlignTrailing
+
+	// Room for "100%" whatever the value, so the row keeps its width
+	widest := canvas.NewText("100%", nil)
+	widest.TextSize = current.CompactSize
+	pctWidth := widest.MinSize().Width
+
+	c.bar = NewProgressBar(style, metric)
+
+	// The radial gauge is a square the height of the row; other styles get
+	// the usual short bar
+	var barContainer *fyne.Container
+	if style == BarRadial {
+		barContainer = container.New(&fixedWidthLayout{width: radialGaugeSize},
+			container.New(&fixedHeightLayout{height: radialGaugeSize}, c.bar))
+	} else {
+		barContainer = container.New(&fixedWidthLayout{width: current.CompactBarWidth},
+			container.New(&fixedHeightLayout{height: current.CompactBarHeight}, c.bar))
+	}
+
+	c.container = container.NewHBox(
+		c.label,
+		barContainer,
+		container.New(&fixedWidthLayout{width: pctWidth}, c.pct),
+	)
+
+	return c
+}
+
+// Update refreshes the compact row
+func (c *CompactUsageRow) Update(pct float64) {
+	c.pct.Text = fmt.Sprintf("%.0f%%", pct)
+	c.pct.Refresh()
+	c.bar.SetValue(pct)
+}
+
+// Load updates the row from its metric's reading in f. It returns false,
+// leaving the row as it was, when f has no reading.
+func (c *CompactUsageRow) Load(f Feed) bool {
+	r, ok := f.Reading(c.metric)
+	if ok {
+		c.Update(r.Utilization)
+	}
+	return ok
+}
+
+// GetContainer returns the renderable container
+func (c *CompactUsageRow) GetContainer() *fyne.Container {
+	return c.container
+}
@@ -0,0 +1,120 @@
+// Package widgets provides ClaudeBar's usage widgets for embedding in other
+// Fyne apps: a progress bar in several styles, the full-size usage row of the
+// overlay and the compact row of its top bar.
+//
+// Rows are fed either directly with Update or from any usage source through
+// the Feed interface:
+//
+//	feed := widgets.FeedFunc(func(metric string) (widgets.Reading, bool) {
+//		if metric != widgets.MetricSession {
+//			return widgets.Reading{}, false
+//		}
+//		return widgets.Reading{Utilization: 42, ResetsAt: reset}, true
+//	})
+//	row := widgets.NewUsageRow("Session", widgets.MetricSession, widgets.BarSolid)
+//	row.Load(feed)
+//	window.SetContent(row.GetContainer())
+//
+// Widgets take their colors and sizes from the current Style when they are
+// created, so set it with SetStyle before building them. Style and levels are
+// read on the Fyne thread and must only be changed there.
+package widgets
+
+import (
+	"image/color"
+
+	"claudebar/internal/palette"
+)
+
+// Style holds the colors and sizes the widgets are drawn with
+type Style struct {
+	BarTrack    color.Color // empty part of a bar
+	BarFill     color.Color // bar below the warning level
+	BarWarn     color.Color // bar at the warning level and above
+	BarCritical color.Color // bar at the critical level and above
+	Text        color.Color // row labels
+	Subtext     color.Color // reset times
+	Percentage  color.Color // "42% used"
+
+	// MetricFill overrides BarFill per metric name; warning and critical
+	// colors still win
+	MetricFill map[string]color.Color
+
+	BarRadius float32 // bar corner radius, px
+
+	HeaderSize  float32 // row label, pt
+	BodySize    float32 // percentage, pt
+	CaptionSize float32 // reset time, pt
+	CompactSize float32 // compact row text, pt
+
+	BarWidth         float32 // px
+	BarHeight        float32
+	CompactBarWidth  float32
+	CompactBarHeight float32
+}
+
+// DefaultStyle returns ClaudeBar's built-in look (Claude website palette)
+func DefaultStyle() Style {
+	return Style{
+		BarTrack:         palette.Track,
+		BarFill:          palette.Accent,
+		BarWarn:          palette.Warning,
+		BarCritical:      palette.Critical,
+		Text:             palette.Text,
+		Subtext:          palette.Subtext,
+		Percentage:       palette.Percentage,
+		BarRadius:        5,
+		HeaderSize:       14,
+		BodySize:         13,
+		CaptionSize:      12,
+		CompactSize:      11,
+		BarWidth:         180,
+		BarHeight:        10,
+		CompactBarWidth:  60,
+		CompactBarHeight: 8,
+	}
+}
+
+// Levels are the usage percentages at which bars switch to the warning and
+// critical colors
+type Levels struct {
+	Warning  float64
+	Critical float64
+}
+
+// DefaultLevels returns the built-in levels, 75% and 90%
+func DefaultLevels() Levels {
+	return Levels{Warning: 75, Critical: 90}
+}
+
+var (
+	current = DefaultStyle()
+	levels  = DefaultLevels()
+)
+
+// SetStyle replaces the style used for newly created widgets. Existing bars
+// pick up the new bar colors on their next refresh.
+func SetStyle(s Style) {
+	current = s
+}
+
+// SetLevels sets the warning and critical levels
+func SetLevels(l Levels) {
+	levels = l
+}
+
+// barColor returns the fill color for a metric's bar: the warning or
+// critical color once usage reaches those levels, otherwise the style's color
+// for the metric, falling back to BarFill
+func barColor(metric string, pct float64) color.Color {
+	switch {
+	case pct >= levels.Critical:
+		return current.BarCritical
+	case pct >= levels.Warning:
+		return current.BarWarn
+	}
+	if c, ok := current.MetricFill[metric]; ok {
+		return c
+	}
+	return current.BarFill
+}
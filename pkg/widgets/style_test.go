@@ -0,0 +1,31 @@
+package widgets
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestBarColor(t *testing.T) {
+	defer SetStyle(DefaultStyle())
+	s := DefaultStyle()
+	purple := color.RGBA{168, 85, 247, 255}
+	s.MetricFill = map[string]color.Color{MetricOpus: purple}
+	SetStyle(s)
+
+	tests := []struct {
+		metric string
+		pct    float64
+		want   color.Color
+	}{
+		{MetricSession, 10, s.BarFill},
+		{MetricOpus, 10, purple},
+		{MetricOpus, 80, s.BarWarn},
+		{MetricOpus, 95, s.BarCritical},
+		{"", 50, s.BarFill},
+	}
+	for _, tt := range tests {
+		if got := barColor(tt.metric, tt.pct); got != tt.want {
+			t.Errorf("barColor(%q, %v) = %v, want %v", tt.metric, tt.pct, got, tt.want)
+		}
+	}
+}